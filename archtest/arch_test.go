@@ -0,0 +1,89 @@
+package archtest
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/violations.golden with the current violations")
+
+const snapshot = "testdata/violations.golden"
+
+// TestArchitecture fails on any violation missing from the snapshot, so
+// new coupling cannot slip in, and on snapshot entries that are gone, so
+// the snapshot only ever shrinks. The snapshot holds the coupling that
+// predates the test; fix an entry and remove it rather than adding more.
+func TestArchitecture(t *testing.T) {
+	modules, err := LoadWorkspace("..")
+	if err != nil {
+		t.Fatalf("load workspace: %v", err)
+	}
+
+	pkgs, err := LoadPackages(modules)
+	if err != nil {
+		t.Fatalf("load packages: %v", err)
+	}
+
+	var current []string
+	for _, v := range Check(modules, pkgs) {
+		current = append(current, v.String())
+	}
+
+	if *update {
+		data := strings.Join(current, "\n")
+		if data != "" {
+			data += "\n"
+		}
+		if err := os.WriteFile(snapshot, []byte(data), 0o644); err != nil {
+			t.Fatalf("write snapshot: %v", err)
+		}
+		return
+	}
+
+	data, err := os.ReadFile(snapshot)
+	if err != nil {
+		t.Fatalf("read snapshot: %v", err)
+	}
+
+	known := make(map[string]bool)
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			known[line] = true
+		}
+	}
+
+	for _, v := range current {
+		if !known[v] {
+			t.Errorf("new import breaks the architecture: %s", v)
+		}
+		delete(known, v)
+	}
+
+	for v := range known {
+		t.Errorf("%s no longer happens: remove it from %s", v, filepath.ToSlash(snapshot))
+	}
+}
+
+func TestLayerOf(t *testing.T) {
+	cases := map[string]Layer{
+		"cmd":                           LayerCmd,
+		"internal/transport/grpc":       LayerTransport,
+		"transport/kafka":               LayerTransport,
+		"internal/service":              LayerService,
+		"internal/repository/sqlc":      LayerRepository,
+		"internal/domain":               LayerDomain,
+		"internal/worker":               LayerOther,
+		"tests":                         LayerOther,
+		".":                             LayerOther,
+		"internal/infrastructure/email": LayerOther,
+	}
+
+	for rel, want := range cases {
+		if got := layerOf(rel); got != want {
+			t.Errorf("layerOf(%q) = %s, want %s", rel, got, want)
+		}
+	}
+}
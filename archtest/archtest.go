@@ -0,0 +1,299 @@
+// Package archtest checks the import graph of the monorepo against the
+// layering every service follows: transport calls service, service calls
+// repository, domain depends on none of them, and services talk to each
+// other over gRPC and Kafka rather than by importing each other's code.
+//
+// The compiler already stops one module from importing another's internal
+// packages, but not a service from importing another's exported packages,
+// pkg from importing a service, or a handler from reaching past its service
+// into the repository.
+package archtest
+
+import (
+	"bufio"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Layer is where a package sits within its service.
+type Layer string
+
+const (
+	LayerCmd        Layer = "cmd"
+	LayerTransport  Layer = "transport"
+	LayerService    Layer = "service"
+	LayerRepository Layer = "repository"
+	LayerDomain     Layer = "domain"
+	// LayerOther is everything the layering says nothing about: tests,
+	// workers, infrastructure and the service's own helpers.
+	LayerOther Layer = "other"
+)
+
+// forbidden lists, per layer, the layers of the same service it must not
+// import.
+var forbidden = map[Layer][]Layer{
+	LayerTransport:  {LayerRepository},
+	LayerService:    {LayerTransport, LayerCmd},
+	LayerRepository: {LayerService, LayerTransport, LayerCmd},
+	LayerDomain:     {LayerService, LayerRepository, LayerTransport, LayerCmd},
+}
+
+// Module is one module of the workspace.
+type Module struct {
+	Path string
+	Dir  string
+	// Service is set for the modules under services/.
+	Service bool
+}
+
+// Package is a directory of Go files and everything its files import,
+// tests included.
+type Package struct {
+	Path    string
+	Module  *Module
+	Layer   Layer
+	Imports []string
+}
+
+// Violation is one package importing another it should not.
+type Violation struct {
+	From string
+	To   string
+	Rule string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s -> %s (%s)", v.From, v.To, v.Rule)
+}
+
+// LoadWorkspace reads the modules go.work in root uses.
+func LoadWorkspace(root string) ([]*Module, error) {
+	dirs, err := workspaceDirs(filepath.Join(root, "go.work"))
+	if err != nil {
+		return nil, err
+	}
+
+	modules := make([]*Module, 0, len(dirs))
+	for _, dir := range dirs {
+		modPath, err := modulePath(filepath.Join(root, dir, "go.mod"))
+		if err != nil {
+			return nil, err
+		}
+
+		modules = append(modules, &Module{
+			Path:    modPath,
+			Dir:     filepath.Join(root, dir),
+			Service: strings.HasPrefix(filepath.ToSlash(filepath.Clean(dir)), "services/"),
+		})
+	}
+
+	return modules, nil
+}
+
+func workspaceDirs(goWork string) ([]string, error) {
+	f, err := os.Open(goWork)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		dirs  []string
+		inUse bool
+	)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		switch {
+		case line == "use (":
+			inUse = true
+		case inUse && line == ")":
+			inUse = false
+		case inUse && line != "":
+			dirs = append(dirs, line)
+		case strings.HasPrefix(line, "use "):
+			dirs = append(dirs, strings.TrimSpace(strings.TrimPrefix(line, "use ")))
+		}
+	}
+
+	return dirs, sc.Err()
+}
+
+func modulePath(goMod string) (string, error) {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return "", err
+	}
+
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.TrimSpace(rest), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s: no module line", goMod)
+}
+
+// LoadPackages parses the imports of every Go file in the modules.
+// Directories holding another module, testdata and vendor are skipped.
+func LoadPackages(modules []*Module) ([]*Package, error) {
+	var pkgs []*Package
+	fset := token.NewFileSet()
+
+	for _, m := range modules {
+		byDir := make(map[string]*Package)
+		seen := make(map[string]map[string]bool)
+
+		err := filepath.WalkDir(m.Dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != m.Dir {
+					name := d.Name()
+					if name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") {
+						return filepath.SkipDir
+					}
+					if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+						return filepath.SkipDir
+					}
+				}
+				return nil
+			}
+			if !strings.HasSuffix(p, ".go") {
+				return nil
+			}
+
+			f, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+			if err != nil {
+				return err
+			}
+
+			dir := filepath.Dir(p)
+			pkg, ok := byDir[dir]
+			if !ok {
+				rel, err := filepath.Rel(m.Dir, dir)
+				if err != nil {
+					return err
+				}
+				rel = filepath.ToSlash(rel)
+
+				pkg = &Package{Path: path.Join(m.Path, rel), Module: m, Layer: layerOf(rel)}
+				byDir[dir] = pkg
+				seen[dir] = make(map[string]bool)
+				pkgs = append(pkgs, pkg)
+			}
+
+			for _, spec := range f.Imports {
+				imp, err := strconv.Unquote(spec.Path.Value)
+				if err != nil {
+					return err
+				}
+				if !seen[dir][imp] {
+					seen[dir][imp] = true
+					pkg.Imports = append(pkg.Imports, imp)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return pkgs, nil
+}
+
+// layerOf places a package by its directory within the module. Notification
+// keeps its transport outside internal/, so both spellings count.
+func layerOf(rel string) Layer {
+	parts := strings.Split(rel, "/")
+	if parts[0] == "internal" {
+		parts = parts[1:]
+	}
+	if len(parts) == 0 {
+		return LayerOther
+	}
+
+	switch parts[0] {
+	case "cmd":
+		return LayerCmd
+	case "transport":
+		return LayerTransport
+	case "service":
+		return LayerService
+	case "repository":
+		return LayerRepository
+	case "domain":
+		return LayerDomain
+	}
+
+	return LayerOther
+}
+
+// Check returns the imports among pkgs that break the rules, sorted.
+func Check(modules []*Module, pkgs []*Package) []Violation {
+	layers := make(map[string]Layer, len(pkgs))
+	for _, p := range pkgs {
+		layers[p.Path] = p.Layer
+	}
+
+	var violations []Violation
+	for _, p := range pkgs {
+		for _, imp := range p.Imports {
+			owner := moduleOf(modules, imp)
+
+			switch {
+			case owner == nil:
+			case owner == p.Module:
+				if rule, ok := layerRule(p.Layer, layers[imp]); ok {
+					violations = append(violations, Violation{From: p.Path, To: imp, Rule: rule})
+				}
+			case owner.Service && p.Module.Service:
+				violations = append(violations, Violation{From: p.Path, To: imp, Rule: "service imports another service"})
+			case owner.Service:
+				violations = append(violations, Violation{From: p.Path, To: imp, Rule: "shared module imports a service"})
+			}
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		return violations[i].String() < violations[j].String()
+	})
+
+	return violations
+}
+
+func layerRule(from, to Layer) (string, bool) {
+	for _, l := range forbidden[from] {
+		if l == to {
+			return fmt.Sprintf("%s imports %s", from, to), true
+		}
+	}
+
+	return "", false
+}
+
+// moduleOf returns the module an import path belongs to, the longest match
+// winning, or nil for the standard library and third-party code.
+func moduleOf(modules []*Module, imp string) *Module {
+	var best *Module
+	for _, m := range modules {
+		if imp == m.Path || strings.HasPrefix(imp, m.Path+"/") {
+			if best == nil || len(m.Path) > len(best.Path) {
+				best = m
+			}
+		}
+	}
+
+	return best
+}
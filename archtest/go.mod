@@ -0,0 +1,3 @@
+module github.com/sakashimaa/go-pet-project/archtest
+
+go 1.25.4
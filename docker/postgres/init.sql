@@ -2,4 +2,5 @@ CREATE DATABASE auth_db;
 CREATE DATABASE product_db;
 CREATE DATABASE notification_db;
 CREATE DATABASE payment_db;
-CREATE DATABASE order_db;
\ No newline at end of file
+CREATE DATABASE order_db;
+CREATE DATABASE analytics_db;
\ No newline at end of file
@@ -0,0 +1,180 @@
+// Load scenario for the gateway: anonymous-looking browsing plus shoppers
+// who log in and place orders. Thresholds are the latency budgets; k6 exits
+// non-zero when one is missed, so this doubles as a regression check.
+//
+//   k6 run loadtest/k6/shop.js
+//   BASE_URL=http://gateway:3000 BROWSE_RATE=200 k6 run loadtest/k6/shop.js
+//
+// Shoppers are registered in setup(), which needs auth-service running with
+// EXPOSE_ACTIVATION_TOKEN=true. Seed products first (product seed) so there
+// is something to list and buy. Set PARTNER_KEY to one of the gateway's
+// BOT_GUARD_PARTNER_KEYS, or the bot guard will challenge the browsers.
+
+import http from 'k6/http';
+import { check, fail, sleep } from 'k6';
+
+const BASE_URL = __ENV.BASE_URL || 'http://localhost:3000';
+const DURATION = __ENV.DURATION || '2m';
+const BROWSE_RATE = Number(__ENV.BROWSE_RATE || 50);
+const SHOPPERS = Number(__ENV.SHOPPERS || 20);
+const PASSWORD = 'load-test-password-1';
+
+export const options = {
+  scenarios: {
+    browse: {
+      executor: 'constant-arrival-rate',
+      exec: 'browse',
+      rate: BROWSE_RATE,
+      timeUnit: '1s',
+      duration: DURATION,
+      preAllocatedVUs: Math.ceil(BROWSE_RATE / 2),
+      maxVUs: BROWSE_RATE * 4,
+    },
+    checkout: {
+      executor: 'ramping-vus',
+      exec: 'checkout',
+      startVUs: 1,
+      stages: [
+        { duration: '30s', target: SHOPPERS },
+        { duration: DURATION, target: SHOPPERS },
+        { duration: '15s', target: 0 },
+      ],
+    },
+  },
+  thresholds: {
+    'http_req_failed{scenario:browse}': ['rate<0.01'],
+    'http_req_failed{scenario:checkout}': ['rate<0.02'],
+    'http_req_duration{name:list_products}': ['p(95)<150'],
+    'http_req_duration{name:get_product}': ['p(95)<100'],
+    'http_req_duration{name:me}': ['p(95)<100'],
+    'http_req_duration{name:create_order}': ['p(95)<300'],
+  },
+};
+
+const categories = ['Electronics', 'Audio', 'Books', 'Clothing', 'Home', 'Toys', 'Sports'];
+const sorts = ['', 'newest', 'price_asc', 'price_desc', 'rating'];
+
+function pick(items) {
+  return items[Math.floor(Math.random() * items.length)];
+}
+
+function headers(token) {
+  const h = { 'Content-Type': 'application/json' };
+  if (token) {
+    h.Authorization = `Bearer ${token}`;
+  }
+  if (__ENV.PARTNER_KEY) {
+    h['X-API-Key'] = __ENV.PARTNER_KEY;
+  }
+  return h;
+}
+
+export function setup() {
+  const run = Date.now();
+  const tokens = [];
+  const addresses = [];
+
+  for (let i = 0; i < SHOPPERS; i++) {
+    const email = `load.${run}.${i}@loadtest.example`;
+
+    const registered = http.post(`${BASE_URL}/auth/register`, JSON.stringify({ email, password: PASSWORD }), {
+      headers: headers(),
+    });
+    if (registered.status !== 201 && registered.status !== 200) {
+      fail(`register failed: ${registered.status} ${registered.body}`);
+    }
+
+    const activationToken = registered.json('activation_token');
+    if (!activationToken) {
+      fail('register returned no activation_token: run auth-service with EXPOSE_ACTIVATION_TOKEN=true');
+    }
+    http.get(`${BASE_URL}/auth/activate?token=${encodeURIComponent(activationToken)}`, { headers: headers() });
+
+    const login = http.post(`${BASE_URL}/auth/login`, JSON.stringify({ email, password: PASSWORD }), {
+      headers: headers(),
+    });
+    if (login.status !== 200) {
+      fail(`login failed: ${login.status} ${login.body}`);
+    }
+    const token = login.json('access_token');
+
+    const address = http.post(
+      `${BASE_URL}/api/me/addresses`,
+      JSON.stringify({
+        label: 'Home',
+        recipient: `Load Shopper ${i}`,
+        line1: `${i + 1} Test Street`,
+        city: 'Berlin',
+        postal_code: '10115',
+        country: 'DE',
+      }),
+      { headers: headers(token) },
+    );
+    if (address.status !== 201) {
+      fail(`add address failed: ${address.status} ${address.body}`);
+    }
+
+    tokens.push(token);
+    addresses.push(address.json('id'));
+  }
+
+  const list = http.get(`${BASE_URL}/api/products?limit=100&in_stock=true`, {
+    headers: headers(tokens[0]),
+  });
+  const products = list.json('products') || [];
+  if (products.length === 0) {
+    fail('no products in stock: seed product-service first');
+  }
+
+  return { tokens, addresses, products };
+}
+
+export function browse(data) {
+  const token = pick(data.tokens);
+  const params = [`limit=20`, `offset=${20 * Math.floor(Math.random() * 5)}`];
+  if (Math.random() < 0.5) {
+    params.push(`category=${pick(categories)}`);
+  }
+  const sort = pick(sorts);
+  if (sort) {
+    params.push(`sort=${sort}`);
+  }
+
+  const list = http.get(`${BASE_URL}/api/products?${params.join('&')}`, {
+    headers: headers(token),
+    tags: { name: 'list_products' },
+  });
+  check(list, { 'list 200': (r) => r.status === 200 });
+
+  const product = pick(data.products);
+  const one = http.get(`${BASE_URL}/api/products/${product.id}`, {
+    headers: headers(token),
+    tags: { name: 'get_product' },
+  });
+  check(one, { 'product 200': (r) => r.status === 200 });
+}
+
+export function checkout(data) {
+  const shopper = (__VU - 1) % data.tokens.length;
+  const token = data.tokens[shopper];
+
+  const me = http.get(`${BASE_URL}/api/me`, { headers: headers(token), tags: { name: 'me' } });
+  check(me, { 'me 200': (r) => r.status === 200 });
+
+  // Most baskets hold one or two products.
+  const lines = Math.random() < 0.6 ? 1 : 1 + Math.ceil(Math.random() * 3);
+  const items = [];
+  for (let i = 0; i < lines; i++) {
+    const product = pick(data.products);
+    items.push({ product_id: product.id, name: product.name, price: product.price, quantity: 1 });
+  }
+
+  const order = http.post(`${BASE_URL}/api/orders`, JSON.stringify({ items, shipping_address_id: data.addresses[shopper] }), {
+    headers: headers(token),
+    tags: { name: 'create_order' },
+  });
+  check(order, { 'order created': (r) => r.status === 200 || r.status === 201 });
+
+  // Think time between orders.
+  sleep(1 + Math.random() * 2);
+}
@@ -0,0 +1,196 @@
+// Package app runs a service's long-lived components and shuts them down in
+// a fixed order, so mains do not hand-roll their own shutdown sequence.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+const defaultStopTimeout = 5 * time.Second
+
+// Component is one piece of a service with a lifecycle.
+//
+// Start, if set, runs in its own goroutine and should block until the
+// component is done: a server's Serve, a consumer loop, a ticker job. Its
+// context is cancelled when the component is stopped.
+//
+// Stop, if set, is called on shutdown before that context is cancelled. A
+// component without Start (a pool, a producer, a tracer) only has Stop.
+type Component struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+	// Timeout bounds Stop plus waiting for Start to return. Zero uses the
+	// runner's default.
+	Timeout time.Duration
+}
+
+type Runner struct {
+	logger      *zap.Logger
+	stopTimeout time.Duration
+	components  []Component
+}
+
+type Option func(*Runner)
+
+// WithStopTimeout sets the default per-component stop timeout.
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(r *Runner) {
+		r.stopTimeout = timeout
+	}
+}
+
+func NewRunner(logger *zap.Logger, opts ...Option) *Runner {
+	r := &Runner{
+		logger:      logger,
+		stopTimeout: defaultStopTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Add registers a component. Components are started in the order they are
+// added and stopped in reverse, so dependencies go first: the pool before the
+// consumer that writes to it.
+func (r *Runner) Add(c Component) {
+	r.components = append(r.components, c)
+}
+
+type running struct {
+	Component
+	cancel context.CancelFunc
+	done   chan error
+}
+
+// Run starts every component and blocks until ctx is cancelled or a
+// component's Start fails, then stops them all in reverse order. It returns
+// the Start failure, if any, joined with the errors from stopping.
+func (r *Runner) Run(ctx context.Context) error {
+	failed := make(chan error, len(r.components))
+	started := make([]*running, 0, len(r.components))
+
+	for _, c := range r.components {
+		rc := &running{Component: c}
+		started = append(started, rc)
+
+		if c.Start == nil {
+			continue
+		}
+
+		var compCtx context.Context
+		compCtx, rc.cancel = context.WithCancel(context.WithoutCancel(ctx))
+		rc.done = make(chan error, 1)
+
+		go func() {
+			err := rc.Start(compCtx)
+			if err != nil && compCtx.Err() == nil {
+				failed <- fmt.Errorf("%s: %w", rc.Name, err)
+			}
+			rc.done <- err
+		}()
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		mylogger.Info(context.WithoutCancel(ctx), r.logger, "Shutting down gracefully...")
+	case runErr = <-failed:
+		mylogger.Error(ctx, r.logger, "Component failed, shutting down", zap.Error(runErr))
+	}
+
+	errs := []error{runErr}
+	for i := len(started) - 1; i >= 0; i-- {
+		if err := r.stop(context.WithoutCancel(ctx), started[i]); err != nil {
+			errs = append(errs, fmt.Errorf("stop %s: %w", started[i].Name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Runner) stop(ctx context.Context, c *running) error {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = r.stopTimeout
+	}
+
+	stopCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	stopped := make(chan error, 1)
+	go func() {
+		var err error
+		if c.Stop != nil {
+			err = c.Stop(stopCtx)
+		}
+		if c.cancel != nil {
+			c.cancel()
+			<-c.done
+		}
+		stopped <- err
+	}()
+
+	var err error
+	select {
+	case err = <-stopped:
+	case <-stopCtx.Done():
+		err = stopCtx.Err()
+	}
+
+	if err != nil {
+		mylogger.Warn(ctx, r.logger, "Component did not stop cleanly", zap.String("component", c.Name), zap.Error(err))
+	} else {
+		mylogger.Info(ctx, r.logger, "Component stopped", zap.String("component", c.Name))
+	}
+
+	return err
+}
+
+// Closer adapts a Close method to a Stop func.
+func Closer(close func() error) func(context.Context) error {
+	return func(context.Context) error {
+		return close()
+	}
+}
+
+// Func adapts a Close method without an error to a Stop func.
+func Func(fn func()) func(context.Context) error {
+	return func(context.Context) error {
+		fn()
+		return nil
+	}
+}
+
+// Loop adapts a blocking loop that runs until its context is cancelled to a
+// Start func.
+func Loop(fn func(ctx context.Context)) func(context.Context) error {
+	return func(ctx context.Context) error {
+		fn(ctx)
+		return nil
+	}
+}
+
+// HTTPServer runs srv as a component, shutting it down gracefully on stop.
+func HTTPServer(name string, srv *http.Server) Component {
+	return Component{
+		Name: name,
+		Start: func(context.Context) error {
+			if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+		Stop: srv.Shutdown,
+	}
+}
@@ -0,0 +1,131 @@
+// Package authctx carries the authenticated caller of a request.
+//
+// The gateway validates the token once, keeps the claims on the request
+// context and forwards them to the services as gRPC metadata. Services read
+// them back with the same helpers instead of parsing tokens themselves.
+package authctx
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// MetadataUserID is the gRPC metadata key carrying the caller's user id.
+	MetadataUserID = "x-user-id"
+	// MetadataActivated carries whether the caller confirmed their email.
+	MetadataActivated = "x-user-activated"
+	// MetadataRole carries the caller's role.
+	MetadataRole = "x-user-role"
+)
+
+var (
+	ErrUnauthenticated = errors.New("caller is not authenticated")
+	ErrNotActivated    = errors.New("account is not activated")
+	ErrForbidden       = errors.New("insufficient role")
+)
+
+// Claims is what the auth service vouched for about the caller.
+type Claims struct {
+	UserID      int64
+	IsActivated bool
+	Role        string
+}
+
+type ctxKey struct{}
+
+func WithClaims(ctx context.Context, claims Claims) context.Context {
+	return context.WithValue(ctx, ctxKey{}, claims)
+}
+
+// FromContext returns the claims put on ctx by WithClaims or, in a gRPC
+// service, forwarded in the incoming metadata.
+func FromContext(ctx context.Context) (Claims, bool) {
+	if claims, ok := ctx.Value(ctxKey{}).(Claims); ok {
+		return claims, true
+	}
+
+	return fromIncomingMetadata(ctx)
+}
+
+// UserIDFromContext returns the authenticated user, if any.
+func UserIDFromContext(ctx context.Context) (int64, bool) {
+	claims, ok := FromContext(ctx)
+	if !ok {
+		return 0, false
+	}
+
+	return claims.UserID, true
+}
+
+// RequireActivated fails with ErrUnauthenticated or ErrNotActivated unless
+// the caller confirmed their email.
+func RequireActivated(ctx context.Context) error {
+	claims, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	if !claims.IsActivated {
+		return ErrNotActivated
+	}
+
+	return nil
+}
+
+// RequireRole fails with ErrUnauthenticated or ErrForbidden unless the caller
+// has role.
+func RequireRole(ctx context.Context, role string) error {
+	claims, ok := FromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+
+	if claims.Role != role {
+		return ErrForbidden
+	}
+
+	return nil
+}
+
+// AppendToOutgoing forwards claims to the services called with ctx.
+func AppendToOutgoing(ctx context.Context, claims Claims) context.Context {
+	return metadata.AppendToOutgoingContext(
+		ctx,
+		MetadataUserID, strconv.FormatInt(claims.UserID, 10),
+		MetadataActivated, strconv.FormatBool(claims.IsActivated),
+		MetadataRole, claims.Role,
+	)
+}
+
+// fromIncomingMetadata trusts the metadata: services are only reachable
+// through the gateway, which overwrites whatever the client sent.
+func fromIncomingMetadata(ctx context.Context) (Claims, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return Claims{}, false
+	}
+
+	values := md.Get(MetadataUserID)
+	if len(values) == 0 {
+		return Claims{}, false
+	}
+
+	userID, err := strconv.ParseInt(values[0], 10, 64)
+	if err != nil || userID <= 0 {
+		return Claims{}, false
+	}
+
+	claims := Claims{UserID: userID}
+	if values := md.Get(MetadataActivated); len(values) > 0 {
+		claims.IsActivated, _ = strconv.ParseBool(values[0])
+	}
+	if values := md.Get(MetadataRole); len(values) > 0 {
+		claims.Role = values[0]
+	}
+
+	return claims, true
+}
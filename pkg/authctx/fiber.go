@@ -0,0 +1,92 @@
+package authctx
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	pb "github.com/sakashimaa/go-pet-project/proto/auth"
+)
+
+const validateTimeout = time.Second
+
+type FiberConfig struct {
+	Client pb.AuthServiceClient
+	// Cookie, when set, names a cookie holding the access token for
+	// requests without an Authorization header. Cookie sessions need CSRF
+	// protection in front of this middleware.
+	Cookie string
+}
+
+// NewFiberMiddleware validates the bearer token with the auth service, puts
+// the claims on the request's user context and forwards them to the
+// services called with it.
+func NewFiberMiddleware(cfg FiberConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var token string
+
+		authHeader := c.Get("Authorization")
+		switch {
+		case authHeader != "":
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized: Invalid header format"})
+			}
+			token = parts[1]
+		case cfg.Cookie != "" && c.Cookies(cfg.Cookie) != "":
+			token = c.Cookies(cfg.Cookie)
+		default:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized: missed header"})
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), validateTimeout)
+		defer cancel()
+
+		res, err := cfg.Client.ValidateUser(ctx, &pb.ValidateRequest{Token: token})
+		if err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized: Invalid token"})
+		}
+
+		claims := Claims{
+			UserID:      res.UserId,
+			IsActivated: res.IsActivated,
+			Role:        res.Role,
+		}
+
+		// Upstream services take the caller from here instead of trusting
+		// the user ids in request bodies.
+		c.SetUserContext(AppendToOutgoing(WithClaims(c.UserContext(), claims), claims))
+
+		return c.Next()
+	}
+}
+
+func NewFiberRequireActivated() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch RequireActivated(c.UserContext()) {
+		case nil:
+			return c.Next()
+		case ErrNotActivated:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Account not activated",
+				"code":  "EMAIL_NOT_VERIFIED",
+			})
+		default:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized: missed user"})
+		}
+	}
+}
+
+func NewFiberRequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch RequireRole(c.UserContext(), role) {
+		case nil:
+			return c.Next()
+		case ErrForbidden:
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "Forbidden: insufficient role"})
+		default:
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized: missed user"})
+		}
+	}
+}
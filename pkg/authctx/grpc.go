@@ -0,0 +1,97 @@
+package authctx
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryServerInterceptor reads the forwarded claims once and keeps them on
+// the context. Calls without a caller go through; handlers that need one
+// check with RequireActivated, RequireRole or UserIDFromContext.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if claims, ok := fromIncomingMetadata(ctx); ok {
+			ctx = WithClaims(ctx, claims)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RequireUnary rejects calls to the given full method names, e.g.
+// "/order.OrderService/CreateOrder", unless check passes.
+func RequireUnary(check func(ctx context.Context) error, methods ...string) grpc.UnaryServerInterceptor {
+	guarded := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		guarded[method] = struct{}{}
+	}
+
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := guarded[info.FullMethod]; ok {
+			if err := check(ctx); err != nil {
+				return nil, Status(err)
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RequireStream is RequireUnary for streaming calls.
+func RequireStream(check func(ctx context.Context) error, methods ...string) grpc.StreamServerInterceptor {
+	guarded := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		guarded[method] = struct{}{}
+	}
+
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := guarded[info.FullMethod]; ok {
+			if err := check(ss.Context()); err != nil {
+				return Status(err)
+			}
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// BindCaller replaces the user id of a request with the forwarded caller, so
+// ownership checks never rely on what the client put in the body. A request
+// naming somebody else is rejected rather than silently rewritten. checks,
+// such as RequireActivated for actions that spend money, run afterwards. The
+// error is a gRPC status.
+func BindCaller(ctx context.Context, userID *int64, checks ...func(ctx context.Context) error) error {
+	callerID, ok := UserIDFromContext(ctx)
+	if !ok {
+		return Status(ErrUnauthenticated)
+	}
+
+	if *userID != 0 && *userID != callerID {
+		return status.Error(codes.PermissionDenied, "user id does not match the caller")
+	}
+
+	for _, check := range checks {
+		if err := check(ctx); err != nil {
+			return Status(err)
+		}
+	}
+
+	*userID = callerID
+	return nil
+}
+
+// Status converts the errors of this package to gRPC statuses.
+func Status(err error) error {
+	switch {
+	case errors.Is(err, ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, ErrNotActivated), errors.Is(err, ErrForbidden):
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	return err
+}
@@ -0,0 +1,109 @@
+// Package cli builds the command line every service binary shares: serve by
+// default, plus the operational subcommands that otherwise need psql or the
+// Kafka scripts.
+package cli
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/seed"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ConsumerGroup is a Kafka consumer group a service reads with.
+type ConsumerGroup struct {
+	ID     string
+	Topics []string
+}
+
+type Service struct {
+	// Name is the service name, e.g. "payment-service".
+	Name  string
+	Serve func(ctx context.Context) error
+	// Outbox adds `outbox requeue` for services that publish via the outbox.
+	Outbox bool
+	// ConsumerGroups adds `consumer reset-offsets` for these groups.
+	ConsumerGroups []ConsumerGroup
+	// Seed adds `seed`, which fills the service with generated data.
+	Seed func(ctx context.Context, opts seed.Options) error
+	// Import adds `import`, which creates records moved over from another
+	// system.
+	Import func(ctx context.Context, opts ImportOptions) error
+}
+
+// Execute loads .env, runs the command line until SIGINT/SIGTERM and exits
+// non-zero on failure.
+func Execute(svc Service) {
+	if err := godotenv.Load(); err != nil {
+		log.Println(".env not found, using system envs")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+
+	err := NewRootCommand(svc).ExecuteContext(ctx)
+	stop()
+	if err != nil {
+		os.Exit(1)
+	}
+}
+
+// NewRootCommand builds the service's command tree. Running it without a
+// subcommand serves, so existing deployments keep working.
+func NewRootCommand(svc Service) *cobra.Command {
+	serve := func(cmd *cobra.Command, _ []string) error {
+		return svc.Serve(cmd.Context())
+	}
+
+	root := &cobra.Command{
+		Use:          strings.TrimSuffix(svc.Name, "-service"),
+		Short:        fmt.Sprintf("Run and operate %s", svc.Name),
+		Args:         cobra.NoArgs,
+		RunE:         serve,
+		SilenceUsage: true,
+	}
+
+	root.AddCommand(&cobra.Command{
+		Use:   "serve",
+		Short: "Start the service",
+		Args:  cobra.NoArgs,
+		RunE:  serve,
+	})
+	root.AddCommand(newMigrateCommand())
+
+	if svc.Outbox {
+		root.AddCommand(newOutboxCommand())
+	}
+
+	if len(svc.ConsumerGroups) > 0 {
+		root.AddCommand(newConsumerCommand(svc.Name, svc.ConsumerGroups))
+	}
+
+	if svc.Seed != nil {
+		root.AddCommand(newSeedCommand(svc.Name, svc.Seed))
+	}
+
+	if svc.Import != nil {
+		root.AddCommand(newImportCommand(svc.Name, svc.Import))
+	}
+
+	return root
+}
+
+func connect() (*pgxpool.Pool, error) {
+	url := utils.ParseWithFallback("DB_URL", "")
+	if url == "" {
+		return nil, fmt.Errorf("DB_URL is required")
+	}
+
+	return db.NewPostgresDB(url)
+}
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/spf13/cobra"
+)
+
+func newConsumerCommand(name string, groups []ConsumerGroup) *cobra.Command {
+	var (
+		groupID string
+		topics  []string
+		to      string
+		brokers []string
+		dryRun  bool
+	)
+
+	ids := make([]string, 0, len(groups))
+	for _, g := range groups {
+		ids = append(ids, g.ID)
+	}
+
+	reset := &cobra.Command{
+		Use:   "reset-offsets",
+		Short: "Move a stopped consumer group to the earliest, latest or a timestamped offset",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			group, err := pickGroup(groups, groupID)
+			if err != nil {
+				return err
+			}
+
+			if len(topics) == 0 {
+				topics = group.Topics
+			}
+			for _, topic := range topics {
+				if !slices.Contains(group.Topics, topic) {
+					return fmt.Errorf("group %s does not read topic %s", group.ID, topic)
+				}
+			}
+
+			target, err := parseTarget(to)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := kafka.LoadConfig(name + "-cli")
+			if err != nil {
+				return err
+			}
+			if len(brokers) > 0 {
+				cfg.Brokers = brokers
+			}
+
+			return resetOffsets(cmd.OutOrStdout(), cfg, group.ID, topics, target, dryRun)
+		},
+	}
+	reset.Flags().StringVar(&groupID, "group", "", "consumer group, one of: "+strings.Join(ids, ", "))
+	reset.Flags().StringSliceVar(&topics, "topic", nil, "topics to reset (default: every topic of the group)")
+	reset.Flags().StringVar(&to, "to", "", "earliest, latest or an RFC 3339 time")
+	reset.Flags().StringSliceVar(&brokers, "brokers", nil, "kafka brokers (default: KAFKA_URL)")
+	reset.Flags().BoolVar(&dryRun, "dry-run", false, "print the new offsets without committing them")
+	_ = reset.MarkFlagRequired("to")
+
+	cmd := &cobra.Command{
+		Use:   "consumer",
+		Short: "Operate the service's Kafka consumer groups",
+	}
+	cmd.AddCommand(reset)
+
+	return cmd
+}
+
+func pickGroup(groups []ConsumerGroup, id string) (ConsumerGroup, error) {
+	if id == "" {
+		if len(groups) == 1 {
+			return groups[0], nil
+		}
+		return ConsumerGroup{}, fmt.Errorf("--group is required")
+	}
+
+	for _, g := range groups {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+
+	return ConsumerGroup{}, fmt.Errorf("unknown consumer group %s", id)
+}
+
+// parseTarget turns --to into the time argument of Client.GetOffset.
+func parseTarget(to string) (int64, error) {
+	switch to {
+	case "earliest":
+		return sarama.OffsetOldest, nil
+	case "latest":
+		return sarama.OffsetNewest, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, to)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --to %q: want earliest, latest or an RFC 3339 time", to)
+	}
+
+	return t.UnixMilli(), nil
+}
+
+func resetOffsets(out io.Writer, cfg kafka.Config, groupID string, topics []string, target int64, dryRun bool) error {
+	config, err := cfg.Sarama()
+	if err != nil {
+		return err
+	}
+	config.Version = sarama.V3_0_0_0
+	config.Consumer.Offsets.AutoCommit.Enable = false
+
+	client, err := sarama.NewClient(cfg.BrokerList(), config)
+	if err != nil {
+		return fmt.Errorf("failed to connect to kafka: %w", err)
+	}
+	defer client.Close()
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		return fmt.Errorf("failed to create kafka admin: %w", err)
+	}
+
+	// Committing under a live group would be overwritten by its members on
+	// their next commit, so insist the consumers are stopped first.
+	descriptions, err := admin.DescribeConsumerGroups([]string{groupID})
+	if err != nil {
+		return fmt.Errorf("failed to describe group %s: %w", groupID, err)
+	}
+	for _, d := range descriptions {
+		if d.State != "Empty" && d.State != "Dead" {
+			return fmt.Errorf("group %s is %s: stop its consumers first", groupID, d.State)
+		}
+	}
+
+	offsets, err := sarama.NewOffsetManagerFromClient(groupID, client)
+	if err != nil {
+		return fmt.Errorf("failed to create offset manager: %w", err)
+	}
+	defer offsets.Close()
+
+	for _, topic := range topics {
+		partitions, err := client.Partitions(topic)
+		if err != nil {
+			return fmt.Errorf("failed to list partitions of %s: %w", topic, err)
+		}
+
+		for _, partition := range partitions {
+			offset, err := client.GetOffset(topic, partition, target)
+			if err != nil {
+				return fmt.Errorf("failed to resolve offset for %s/%d: %w", topic, partition, err)
+			}
+			// No message at or after the timestamp: start from the end.
+			if offset == -1 {
+				if offset, err = client.GetOffset(topic, partition, sarama.OffsetNewest); err != nil {
+					return fmt.Errorf("failed to resolve offset for %s/%d: %w", topic, partition, err)
+				}
+			}
+
+			pom, err := offsets.ManagePartition(topic, partition)
+			if err != nil {
+				return fmt.Errorf("failed to manage %s/%d: %w", topic, partition, err)
+			}
+
+			current, _ := pom.NextOffset()
+			fmt.Fprintf(out, "%s/%d: %d -> %d\n", topic, partition, current, offset)
+
+			if !dryRun {
+				pom.ResetOffset(offset, "")
+			}
+			pom.AsyncClose()
+		}
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	offsets.Commit()
+	return nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ImportOptions are what `import` was run with.
+type ImportOptions struct {
+	// In holds one JSON record per line.
+	In io.Reader
+	// BatchSize records are created per transaction, with Pause between
+	// batches so consumers of the events they publish keep up.
+	BatchSize int
+	Pause     time.Duration
+	Out       io.Writer
+}
+
+func newImportCommand(name string, run func(ctx context.Context, opts ImportOptions) error) *cobra.Command {
+	var (
+		batchSize int
+		pause     time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: fmt.Sprintf("Create records exported from another system in %s", name),
+		Long: "Reads JSON Lines from FILE, or from stdin if FILE is -, and creates the records " +
+			"through the service's own domain logic. Their outbox events are published once the " +
+			"service runs.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if batchSize <= 0 {
+				return fmt.Errorf("--batch-size must be positive")
+			}
+			if pause < 0 {
+				return fmt.Errorf("--pause must not be negative")
+			}
+
+			in := cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+
+				in = f
+			}
+
+			return run(cmd.Context(), ImportOptions{
+				In:        in,
+				BatchSize: batchSize,
+				Pause:     pause,
+				Out:       cmd.OutOrStdout(),
+			})
+		},
+	}
+	cmd.Flags().IntVar(&batchSize, "batch-size", 100, "how many records to create per transaction")
+	cmd.Flags().DurationVar(&pause, "pause", time.Second, "how long to wait between batches")
+
+	return cmd
+}
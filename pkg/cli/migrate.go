@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand() *cobra.Command {
+	var dir string
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply or roll back database migrations",
+	}
+	cmd.PersistentFlags().StringVar(&dir, "dir", "migrations", "migrations directory")
+
+	run := func(fn func(ctx context.Context, db *sql.DB, dir string, opts ...goose.OptionsFunc) error) func(*cobra.Command, []string) error {
+		return func(cmd *cobra.Command, _ []string) error {
+			pool, err := connect()
+			if err != nil {
+				return err
+			}
+			defer pool.Close()
+
+			db := stdlib.OpenDBFromPool(pool)
+			defer db.Close()
+
+			if err := goose.SetDialect("postgres"); err != nil {
+				return err
+			}
+
+			return fn(cmd.Context(), db, dir)
+		}
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply all pending migrations",
+			Args:  cobra.NoArgs,
+			RunE:  run(goose.UpContext),
+		},
+		&cobra.Command{
+			Use:   "down",
+			Short: "Roll back the latest migration",
+			Args:  cobra.NoArgs,
+			RunE:  run(goose.DownContext),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "Show which migrations are applied",
+			Args:  cobra.NoArgs,
+			RunE:  run(goose.StatusContext),
+		},
+	)
+
+	return cmd
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/spf13/cobra"
+)
+
+func newOutboxCommand() *cobra.Command {
+	var (
+		ids              []int64
+		eventType        string
+		since            string
+		includePublished bool
+	)
+
+	requeue := &cobra.Command{
+		Use:   "requeue",
+		Short: "Hand events that ran out of attempts back to the outbox worker",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			filter := repository.RequeueFilter{
+				IDs:              ids,
+				EventType:        eventType,
+				IncludePublished: includePublished,
+			}
+
+			if since != "" {
+				t, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				filter.Since = t
+			}
+
+			pool, err := connect()
+			if err != nil {
+				return err
+			}
+			defer pool.Close()
+
+			n, err := repository.Requeue(cmd.Context(), pool, filter)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "requeued %d events\n", n)
+			return nil
+		},
+	}
+	requeue.Flags().Int64SliceVar(&ids, "id", nil, "only requeue these event ids")
+	requeue.Flags().StringVar(&eventType, "event-type", "", "only requeue events of this type")
+	requeue.Flags().StringVar(&since, "since", "", "only requeue events created since a duration ago (24h) or an RFC 3339 time")
+	requeue.Flags().BoolVar(&includePublished, "include-published", false, "also requeue published events to replay them")
+
+	cmd := &cobra.Command{
+		Use:   "outbox",
+		Short: "Inspect and repair the transactional outbox",
+	}
+	cmd.AddCommand(requeue)
+
+	return cmd
+}
+
+// parseSince accepts either a duration back from now or an absolute time.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d).UTC(), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: want a duration or an RFC 3339 time", value)
+	}
+
+	return t.UTC(), nil
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/seed"
+	"github.com/spf13/cobra"
+)
+
+func newSeedCommand(name string, run func(ctx context.Context, opts seed.Options) error) *cobra.Command {
+	var (
+		count       int
+		seedValue   uint64
+		concurrency int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "seed",
+		Short: fmt.Sprintf("Fill %s with generated data for load tests and local development", name),
+		Long: "Generates records and creates them through the service's own domain logic, so " +
+			"they are validated and their outbox events are published once the service runs. " +
+			"Never run it against production.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if count < 0 {
+				return fmt.Errorf("--count must not be negative")
+			}
+			if !cmd.Flags().Changed("seed") {
+				seedValue = uint64(time.Now().UnixNano())
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "seeding %s with seed %d\n", name, seedValue)
+
+			return run(cmd.Context(), seed.Options{
+				Count:       count,
+				Seed:        seedValue,
+				Concurrency: concurrency,
+				Out:         cmd.OutOrStdout(),
+			})
+		},
+	}
+	cmd.Flags().IntVar(&count, "count", 100, "how many records to create")
+	cmd.Flags().Uint64Var(&seedValue, "seed", 0, "random seed, to repeat a run (default: random)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", 8, "how many records to create at once")
+
+	return cmd
+}
@@ -6,17 +6,18 @@ import (
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/sakashimaa/go-pet-project/pkg/redis"
 	"github.com/sakashimaa/go-pet-project/pkg/utils"
 )
 
 type Config struct {
-	Env      string   `yaml:"env" env:"ENV" env-default:"local"`
-	HTTP     HTTP     `yaml:"http"`
-	GRPC     GRPC     `yaml:"grpc"`
-	Postgres PG       `yaml:"postgres"`
-	Redis    Redis    `yaml:"redis"`
-	Services Services `yaml:"services"`
-	Limiter  Limiter  `yaml:"limiter"`
+	Env      string       `yaml:"env" env:"ENV" env-default:"local"`
+	HTTP     HTTP         `yaml:"http"`
+	GRPC     GRPC         `yaml:"grpc"`
+	Postgres PG           `yaml:"postgres"`
+	Redis    redis.Config `yaml:"redis"`
+	Services Services     `yaml:"services"`
+	Limiter  Limiter      `yaml:"limiter"`
 }
 
 type HTTP struct {
@@ -33,10 +34,6 @@ type PG struct {
 	URL string `yaml:"url" env:"DB_URL"`
 }
 
-type Redis struct {
-	Addr string `yaml:"addr" env:"REDIS_ADDR" env-default:"localhost:6379"`
-}
-
 type Services struct {
 	AuthRPC    string `yaml:"auth_rpc" env:"AUTH_RPC_URL"`
 	ProductRPC string `yaml:"product_rpc" env:"PRODUCT_RPC_URL"`
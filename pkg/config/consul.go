@@ -0,0 +1,74 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+const consulTimeout = 5 * time.Second
+
+// ConsulSource reads a YAML document stored under a Consul KV key. It talks
+// to the HTTP API directly; the key is small and polled, so the client
+// library would buy nothing.
+type ConsulSource struct {
+	addr   string
+	key    string
+	token  string
+	client *http.Client
+}
+
+func NewConsulSource(addr, key, token string) *ConsulSource {
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+
+	return &ConsulSource{
+		addr:   strings.TrimSuffix(addr, "/"),
+		key:    strings.TrimPrefix(key, "/"),
+		token:  token,
+		client: &http.Client{Timeout: consulTimeout},
+	}
+}
+
+func (s *ConsulSource) Name() string { return "consul " + s.key }
+
+// Load treats a missing key like a missing file: nothing to overlay.
+func (s *ConsulSource) Load(ctx context.Context, t *Tunables) error {
+	endpoint := s.addr + "/v1/kv/" + (&url.URL{Path: s.key}).EscapedPath() + "?raw"
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	if s.token != "" {
+		req.Header.Set("X-Consul-Token", s.token)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+
+	return cleanenv.ParseYAML(bytes.NewReader(body), t)
+}
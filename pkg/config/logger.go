@@ -1,30 +1,185 @@
 package config
 
 import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Log formats. Env picks one unless Format overrides it.
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
 )
 
 type LoggerConfig struct {
-	Level string
-	Env   string
+	Level string `env:"LOG_LEVEL" env-default:"info"`
+	// Env "prod" or "production" logs JSON with stack traces from errors
+	// up; anything else logs colored console lines with stack traces from
+	// warnings up.
+	Env string `env:"ENV" env-default:"dev"`
+	// Format is "console" or "json" regardless of Env, e.g. JSON from a
+	// dev build shipped to a log collector.
+	Format string `env:"LOG_FORMAT"`
+	// StacktraceLevel overrides the level stack traces start at.
+	StacktraceLevel string `env:"LOG_STACKTRACE_LEVEL"`
+	DisableCaller   bool   `env:"LOG_DISABLE_CALLER" env-default:"false"`
+	// RedactKeys are the field keys whose values are masked before they are
+	// written; empty means mylogger.DefaultSensitiveKeys.
+	RedactKeys []string `env:"LOG_REDACT_KEYS" env-separator:","`
+
+	File LogFileConfig
+}
+
+// LogFileConfig writes the log to a file as well as stderr, rotated by size.
+// Files are always uncolored.
+type LogFileConfig struct {
+	// Path turns file output on.
+	Path       string `env:"LOG_FILE"`
+	MaxSizeMB  int    `env:"LOG_FILE_MAX_SIZE_MB" env-default:"100"`
+	MaxBackups int    `env:"LOG_FILE_MAX_BACKUPS" env-default:"5"`
+	MaxAgeDays int    `env:"LOG_FILE_MAX_AGE_DAYS" env-default:"30"`
+	Compress   bool   `env:"LOG_FILE_COMPRESS" env-default:"true"`
+}
+
+// LoadLoggerConfig reads the LOG_* variables and ENV.
+func LoadLoggerConfig() (LoggerConfig, error) {
+	var cfg LoggerConfig
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return LoggerConfig{}, fmt.Errorf("error reading logger config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c LoggerConfig) isProd() bool {
+	return isProdEnv(c.Env)
+}
+
+func isProdEnv(env string) bool {
+	return env == "prod" || env == "production"
+}
+
+func (c LoggerConfig) format() (string, error) {
+	switch c.Format {
+	case "":
+		if c.isProd() {
+			return LogFormatJSON, nil
+		}
+		return LogFormatConsole, nil
+	case LogFormatConsole, LogFormatJSON:
+		return c.Format, nil
+	default:
+		return "", fmt.Errorf("unknown LOG_FORMAT %q: want %s or %s", c.Format, LogFormatConsole, LogFormatJSON)
+	}
+}
+
+func (c LoggerConfig) stacktraceLevel() (zapcore.Level, error) {
+	if c.StacktraceLevel != "" {
+		return zapcore.ParseLevel(c.StacktraceLevel)
+	}
+	if c.isProd() {
+		return zapcore.ErrorLevel, nil
+	}
+
+	return zapcore.WarnLevel, nil
 }
 
 func NewLogger(cfg LoggerConfig) (*zap.Logger, error) {
-	var zapCfg zap.Config
+	logger, _, err := NewLeveledLogger(cfg)
+	return logger, err
+}
 
-	if cfg.Env == "prod" {
-		zapCfg = zap.NewProductionConfig()
-	} else {
-		zapCfg = zap.NewDevelopmentConfig()
+// NewLeveledLogger also returns the logger's level so it can be changed
+// while the service runs, see SetLogLevel.
+func NewLeveledLogger(cfg LoggerConfig) (*zap.Logger, zap.AtomicLevel, error) {
+	parsed, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, zap.AtomicLevel{}, err
+	}
+	level := zap.NewAtomicLevelAt(parsed)
+
+	format, err := cfg.format()
+	if err != nil {
+		return nil, zap.AtomicLevel{}, err
 	}
 
-	level, err := zapcore.ParseLevel(cfg.Level)
+	stacktrace, err := cfg.stacktraceLevel()
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, fmt.Errorf("invalid LOG_STACKTRACE_LEVEL: %w", err)
+	}
+
+	encoderCfg := zap.NewDevelopmentEncoderConfig()
+	if cfg.isProd() {
+		encoderCfg = zap.NewProductionEncoderConfig()
+	}
+
+	stderrCfg := encoderCfg
+	if format == LogFormatConsole {
+		stderrCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+	core := zapcore.NewCore(newEncoder(format, stderrCfg), zapcore.Lock(os.Stderr), level)
+
+	if cfg.File.Path != "" {
+		file := &lumberjack.Logger{
+			Filename:   cfg.File.Path,
+			MaxSize:    cfg.File.MaxSizeMB,
+			MaxBackups: cfg.File.MaxBackups,
+			MaxAge:     cfg.File.MaxAgeDays,
+			Compress:   cfg.File.Compress,
+		}
+		core = zapcore.NewTee(core, zapcore.NewCore(newEncoder(format, encoderCfg), zapcore.AddSync(file), level))
+	}
+
+	// Redaction goes under the samplers: they hand entries to the writing
+	// core directly.
+	redactKeys := cfg.RedactKeys
+	if len(redactKeys) == 0 {
+		redactKeys = mylogger.DefaultSensitiveKeys
 	}
+	core = mylogger.NewRedactCore(core, redactKeys...)
 
-	zapCfg.Level = zap.NewAtomicLevelAt(level)
+	opts := []zap.Option{
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.AddStacktrace(stacktrace),
+	}
+	if !cfg.DisableCaller {
+		opts = append(opts, zap.AddCaller())
+	}
+
+	if cfg.isProd() {
+		// The sampling of zap.NewProductionConfig: after the first 100
+		// entries with the same message in a second, every 100th. Entries
+		// of sampled traces are all kept.
+		core = newTraceCore(core, zapcore.NewSamplerWithOptions(core, time.Second, 100, 100))
+	} else {
+		core = newTraceCore(core, core)
+		opts = append(opts, zap.Development())
+	}
+
+	return zap.New(core, opts...), level, nil
+}
+
+func newEncoder(format string, cfg zapcore.EncoderConfig) zapcore.Encoder {
+	if format == LogFormatJSON {
+		return zapcore.NewJSONEncoder(cfg)
+	}
 
-	return zapCfg.Build()
+	return zapcore.NewConsoleEncoder(cfg)
+}
+
+// SetLogLevel returns a Watcher subscriber that applies the log level.
+func SetLogLevel(level zap.AtomicLevel) func(Tunables) {
+	return func(t Tunables) {
+		// Tunables are validated before they reach subscribers.
+		if parsed, err := zapcore.ParseLevel(t.LogLevel); err == nil {
+			level.SetLevel(parsed)
+		}
+	}
 }
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"google.golang.org/grpc/reflection"
+)
+
+var ErrReflectionInProduction = errors.New("gRPC reflection cannot be enabled in production")
+
+// ReflectionConfig decides whether a gRPC server serves the reflection API,
+// which lets grpcurl and evans list and call its methods without the protos.
+type ReflectionConfig struct {
+	// Env "dev" or "local" turns reflection on; anything else leaves it off.
+	Env string `env:"ENV" env-default:"dev"`
+	// Reflection is "true" or "false" regardless of Env, e.g. to explore a
+	// staging deployment. Production refuses "true".
+	Reflection string `env:"GRPC_REFLECTION"`
+}
+
+// LoadReflectionConfig reads GRPC_REFLECTION and ENV.
+func LoadReflectionConfig() (ReflectionConfig, error) {
+	var cfg ReflectionConfig
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return ReflectionConfig{}, fmt.Errorf("error reading reflection config: %w", err)
+	}
+
+	switch cfg.Reflection {
+	case "", "false":
+	case "true":
+		if isProdEnv(cfg.Env) {
+			return ReflectionConfig{}, ErrReflectionInProduction
+		}
+	default:
+		return ReflectionConfig{}, fmt.Errorf("invalid GRPC_REFLECTION %q: want true or false", cfg.Reflection)
+	}
+
+	return cfg, nil
+}
+
+func (c ReflectionConfig) Enabled() bool {
+	if isProdEnv(c.Env) {
+		return false
+	}
+
+	if c.Reflection != "" {
+		return c.Reflection == "true"
+	}
+
+	return c.Env == "dev" || c.Env == "local"
+}
+
+// RegisterReflection registers the reflection service on s if cfg enables
+// it and reports whether it did. It lists whatever s serves when asked, so it
+// can be called before or after the other services are registered.
+func RegisterReflection(s reflection.GRPCServer, cfg ReflectionConfig) bool {
+	if !cfg.Enabled() {
+		return false
+	}
+
+	reflection.Register(s)
+
+	return true
+}
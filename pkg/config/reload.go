@@ -0,0 +1,243 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var ErrInvalidTunables = errors.New("invalid tunables")
+
+// Tunables are the settings a running service picks up without a restart.
+// Everything else is read once at startup.
+type Tunables struct {
+	LogLevel       string          `yaml:"log_level" env:"LOG_LEVEL" env-default:"info"`
+	RateLimit      RateLimitTuning `yaml:"rate_limit"`
+	Breaker        BreakerTuning   `yaml:"breaker"`
+	OutboxInterval time.Duration   `yaml:"outbox_interval" env:"OUTBOX_INTERVAL" env-default:"1s"`
+}
+
+type RateLimitTuning struct {
+	Max    int           `yaml:"max" env:"RATE_LIMIT_MAX" env-default:"20"`
+	Window time.Duration `yaml:"window" env:"RATE_LIMIT_WINDOW" env-default:"5s"`
+}
+
+// BreakerTuning decides when a circuit breaker trips: after MinRequests
+// requests in its interval with at least FailureRatio of them failed.
+type BreakerTuning struct {
+	MinRequests  uint32  `yaml:"min_requests" env:"BREAKER_MIN_REQUESTS" env-default:"5"`
+	FailureRatio float64 `yaml:"failure_ratio" env:"BREAKER_FAILURE_RATIO" env-default:"0.6"`
+}
+
+func (t Tunables) Validate() error {
+	if _, err := zapcore.ParseLevel(t.LogLevel); err != nil {
+		return fmt.Errorf("%w: log_level: %v", ErrInvalidTunables, err)
+	}
+	if t.RateLimit.Max <= 0 || t.RateLimit.Window < time.Second {
+		return fmt.Errorf("%w: rate_limit needs max > 0 and window >= 1s", ErrInvalidTunables)
+	}
+	if t.Breaker.MinRequests == 0 || t.Breaker.FailureRatio <= 0 || t.Breaker.FailureRatio > 1 {
+		return fmt.Errorf("%w: breaker needs min_requests > 0 and failure_ratio in (0, 1]", ErrInvalidTunables)
+	}
+	if t.OutboxInterval <= 0 {
+		return fmt.Errorf("%w: outbox_interval must be positive", ErrInvalidTunables)
+	}
+
+	return nil
+}
+
+// ReloadConfig says where tunables come from. The environment is always
+// read first; the file and the Consul key, when set, override it in that
+// order.
+type ReloadConfig struct {
+	File        string        `env:"CONFIG_RELOAD_FILE"`
+	Interval    time.Duration `env:"CONFIG_RELOAD_INTERVAL" env-default:"10s"`
+	ConsulAddr  string        `env:"CONSUL_ADDR"`
+	ConsulKey   string        `env:"CONSUL_KEY"`
+	ConsulToken string        `env:"CONSUL_TOKEN"`
+}
+
+func LoadReloadConfig() (ReloadConfig, error) {
+	var cfg ReloadConfig
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return ReloadConfig{}, err
+	}
+
+	if cfg.Interval <= 0 {
+		return ReloadConfig{}, fmt.Errorf("%w: CONFIG_RELOAD_INTERVAL must be positive", ErrInvalidTunables)
+	}
+	if (cfg.ConsulAddr == "") != (cfg.ConsulKey == "") {
+		return ReloadConfig{}, fmt.Errorf("%w: CONSUL_ADDR and CONSUL_KEY go together", ErrInvalidTunables)
+	}
+
+	return cfg, nil
+}
+
+// Source overlays its settings onto t. Fields it does not mention keep
+// the value of the sources before it.
+type Source interface {
+	Name() string
+	Load(ctx context.Context, t *Tunables) error
+}
+
+// Watcher polls its sources and tells subscribers when the merged tunables
+// change. SIGHUP forces a reload before the next tick.
+type Watcher struct {
+	sources  []Source
+	interval time.Duration
+	logger   *zap.Logger
+
+	mu          sync.Mutex
+	current     Tunables
+	subscribers []func(Tunables)
+}
+
+func NewWatcher(cfg ReloadConfig, logger *zap.Logger) *Watcher {
+	sources := []Source{EnvSource{}}
+	if cfg.File != "" {
+		sources = append(sources, FileSource{Path: cfg.File})
+	}
+	if cfg.ConsulAddr != "" {
+		sources = append(sources, NewConsulSource(cfg.ConsulAddr, cfg.ConsulKey, cfg.ConsulToken))
+	}
+
+	return &Watcher{
+		sources:  sources,
+		interval: cfg.Interval,
+		logger:   logger,
+	}
+}
+
+// Load reads the sources once. Call it before Subscribe so components start
+// from the configured values rather than the defaults.
+func (w *Watcher) Load(ctx context.Context) error {
+	t, err := w.read(ctx)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	w.current = t
+	w.mu.Unlock()
+
+	return nil
+}
+
+func (w *Watcher) Current() Tunables {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.current
+}
+
+// Subscribe calls fn with the current tunables and again after every change.
+// fn runs on the watcher goroutine and must not block.
+func (w *Watcher) Subscribe(fn func(Tunables)) {
+	w.mu.Lock()
+	w.subscribers = append(w.subscribers, fn)
+	current := w.current
+	w.mu.Unlock()
+
+	fn(current)
+}
+
+func (w *Watcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-hup:
+			mylogger.Info(ctx, w.logger, "Reloading config on SIGHUP")
+		}
+
+		w.reload(ctx)
+	}
+}
+
+func (w *Watcher) reload(ctx context.Context) {
+	next, err := w.read(ctx)
+	if err != nil {
+		// Keep running on the last good settings; a typo in the file must
+		// not take the service down.
+		mylogger.Warn(ctx, w.logger, "Config reload failed", zap.Error(err))
+		return
+	}
+
+	w.mu.Lock()
+	if next == w.current {
+		w.mu.Unlock()
+		return
+	}
+	w.current = next
+	subscribers := append([]func(Tunables){}, w.subscribers...)
+	w.mu.Unlock()
+
+	mylogger.Info(ctx, w.logger, "Config reloaded", zap.Any("tunables", next))
+
+	for _, fn := range subscribers {
+		fn(next)
+	}
+}
+
+func (w *Watcher) read(ctx context.Context) (Tunables, error) {
+	var t Tunables
+	for _, source := range w.sources {
+		if err := source.Load(ctx, &t); err != nil {
+			return Tunables{}, fmt.Errorf("%s: %w", source.Name(), err)
+		}
+	}
+
+	if err := t.Validate(); err != nil {
+		return Tunables{}, err
+	}
+
+	return t, nil
+}
+
+// EnvSource reads the process environment and fills in the defaults.
+type EnvSource struct{}
+
+func (EnvSource) Name() string { return "env" }
+
+func (EnvSource) Load(_ context.Context, t *Tunables) error {
+	return cleanenv.ReadEnv(t)
+}
+
+// FileSource reads a YAML file. A missing file is not an error, so it can
+// be created once tuning is needed.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Name() string { return "file " + s.Path }
+
+func (s FileSource) Load(_ context.Context, t *Tunables) error {
+	f, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return cleanenv.ParseYAML(f, t)
+}
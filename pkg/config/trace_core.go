@@ -0,0 +1,111 @@
+package config
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap/zapcore"
+)
+
+var logErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "log_errors_total",
+	Help: "Entries logged at error level or above. Entries of sampled traces carry the trace ID as an exemplar.",
+}, []string{"level"})
+
+// RegisterLogMetrics exposes log_errors_total on reg. Serve reg with
+// MetricsHandler so the exemplars are scraped.
+func RegisterLogMetrics(reg prometheus.Registerer) error {
+	return reg.Register(logErrors)
+}
+
+// MetricsHandler serves reg. It speaks OpenMetrics when the scraper asks for
+// it, the only format that carries the trace exemplars of log_errors_total.
+func MetricsHandler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{
+		Registry:          reg,
+		EnableOpenMetrics: true,
+	})
+}
+
+// traceCore keeps every entry of a sampled trace and samples the rest, so an
+// error whose trace Tempo kept is never dropped from the log, and counts
+// errors with the trace as an exemplar to jump from the graph to the trace.
+type traceCore struct {
+	// sampled and full are the same core with and without sampling.
+	sampled zapcore.Core
+	full    zapcore.Core
+	// traceID is set once With saw the fields of a sampled trace.
+	traceID string
+}
+
+func newTraceCore(full, sampled zapcore.Core) zapcore.Core {
+	return &traceCore{sampled: sampled, full: full}
+}
+
+func (c *traceCore) Enabled(level zapcore.Level) bool {
+	return c.full.Enabled(level)
+}
+
+func (c *traceCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := &traceCore{
+		sampled: c.sampled.With(fields),
+		full:    c.full.With(fields),
+		traceID: c.traceID,
+	}
+	if id, ok := sampledTraceID(fields); ok {
+		clone.traceID = id
+	}
+
+	return clone
+}
+
+func (c *traceCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level >= zapcore.ErrorLevel && c.full.Enabled(entry.Level) {
+		c.countError(entry.Level)
+	}
+
+	if c.traceID != "" {
+		return c.full.Check(entry, checked)
+	}
+
+	return c.sampled.Check(entry, checked)
+}
+
+// Write is only reached through Check, which hands entries to the inner
+// cores directly.
+func (c *traceCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.full.Write(entry, fields)
+}
+
+func (c *traceCore) Sync() error {
+	return c.full.Sync()
+}
+
+func (c *traceCore) countError(level zapcore.Level) {
+	counter := logErrors.WithLabelValues(level.String())
+	if adder, ok := counter.(prometheus.ExemplarAdder); ok && c.traceID != "" {
+		adder.AddWithExemplar(1, prometheus.Labels{mylogger.TraceIDKey: c.traceID})
+		return
+	}
+
+	counter.Inc()
+}
+
+func sampledTraceID(fields []zapcore.Field) (string, bool) {
+	var (
+		id      string
+		sampled bool
+	)
+	for _, field := range fields {
+		switch {
+		case field.Key == mylogger.TraceIDKey && field.Type == zapcore.StringType:
+			id = field.String
+		case field.Key == mylogger.TraceSampledKey && field.Type == zapcore.BoolType:
+			sampled = field.Integer == 1
+		}
+	}
+
+	return id, sampled && id != ""
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Beginner starts transactions. *pgxpool.Pool and *pgx.Conn start a real
+// one; pgx.Tx starts a savepoint, so WithTx nests.
+type Beginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// WithTx runs fn in a transaction begun on db. It commits when fn returns
+// nil and rolls back when fn fails or panics. fn must not commit or roll
+// back tx itself.
+//
+// The rollback runs even if ctx is already cancelled, so a request that
+// times out does not leave its connection holding locks.
+func WithTx(ctx context.Context, db Beginner, fn func(tx pgx.Tx) error) (err error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	committed := false
+	defer func() {
+		if committed {
+			return
+		}
+
+		rollbackErr := tx.Rollback(context.WithoutCancel(ctx))
+		if rollbackErr != nil && !errors.Is(rollbackErr, pgx.ErrTxClosed) {
+			err = errors.Join(err, fmt.Errorf("failed to rollback transaction: %w", rollbackErr))
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	committed = true
+
+	return nil
+}
@@ -0,0 +1,158 @@
+// Package debug mounts pprof and expvar on a service's admin HTTP port,
+// behind basic auth and an IP allowlist, so profiles can be captured outside
+// of local runs.
+package debug
+
+import (
+	"crypto/subtle"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"net/netip"
+	"strings"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+var ErrInvalidConfig = errors.New("invalid debug config")
+
+type Config struct {
+	Enabled  bool   `yaml:"enabled" env:"DEBUG_ENDPOINTS_ENABLED" env-default:"false"`
+	Username string `yaml:"username" env:"DEBUG_USERNAME" env-default:"debug"`
+	Password string `yaml:"password" env:"DEBUG_PASSWORD"`
+	// Allowlist holds IPs or CIDRs matched against the connection's remote
+	// address. Forwarding headers are not trusted.
+	Allowlist []string `yaml:"allowlist" env:"DEBUG_ALLOWLIST" env-separator:","`
+}
+
+// LoadFromEnv reads DEBUG_* variables and validates the result.
+func LoadFromEnv() (Config, error) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// Validate refuses to enable the endpoints without a password or allowlist:
+// a CPU profile or /debug/vars is not something to hand to anyone.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	allowlist, err := parseAllowlist(c.Allowlist)
+	if err != nil {
+		return err
+	}
+
+	if c.Password == "" && len(allowlist) == 0 {
+		return fmt.Errorf("%w: DEBUG_PASSWORD or DEBUG_ALLOWLIST is required", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Mount registers /debug/pprof/ and /debug/vars on mux. It is a no-op when
+// the endpoints are disabled.
+func Mount(mux *http.ServeMux, cfg Config) error {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	allowlist, err := parseAllowlist(cfg.Allowlist)
+	if err != nil {
+		return err
+	}
+
+	guard := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(allowlist) > 0 && !allowed(allowlist, r.RemoteAddr) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			if cfg.Password != "" {
+				user, pass, ok := r.BasicAuth()
+				if !ok ||
+					subtle.ConstantTimeCompare([]byte(user), []byte(cfg.Username)) != 1 ||
+					subtle.ConstantTimeCompare([]byte(pass), []byte(cfg.Password)) != 1 {
+					w.Header().Set("WWW-Authenticate", `Basic realm="debug"`)
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+					return
+				}
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+
+	mux.Handle("/debug/pprof/", guard(http.HandlerFunc(pprof.Index)))
+	mux.Handle("/debug/pprof/cmdline", guard(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("/debug/pprof/profile", guard(http.HandlerFunc(pprof.Profile)))
+	mux.Handle("/debug/pprof/symbol", guard(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("/debug/pprof/trace", guard(http.HandlerFunc(pprof.Trace)))
+	mux.Handle("/debug/vars", guard(expvar.Handler()))
+
+	return nil
+}
+
+func parseAllowlist(entries []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("%w: allowlist entry %q: %v", ErrInvalidConfig, entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("%w: allowlist entry %q: %v", ErrInvalidConfig, entry, err)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+
+	return prefixes, nil
+}
+
+func allowed(allowlist []netip.Prefix, remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+
+	for _, prefix := range allowlist {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -34,6 +34,38 @@ type OrderCancelledEvent struct {
 	Items   []OrderItem `json:"items"`
 }
 
+// OrderPaidEvent is an order that was paid for, with the items the payment
+// covered.
+type OrderPaidEvent struct {
+	OrderID int64       `json:"order_id"`
+	UserID  int64       `json:"user_id"`
+	Items   []OrderItem `json:"items"`
+	PaidAt  time.Time   `json:"paid_at"`
+}
+
+// ReturnApprovedEvent is a customer return support accepted. Product puts
+// the items back in stock and payment refunds Amount.
+type ReturnApprovedEvent struct {
+	ReturnID   int64       `json:"return_id"`
+	OrderID    int64       `json:"order_id"`
+	UserID     int64       `json:"user_id"`
+	Items      []OrderItem `json:"items"`
+	Amount     int64       `json:"amount"`
+	ApprovedAt time.Time   `json:"approved_at"`
+}
+
+// RefundIssuedEvent is the money payment sent back for an approved return.
+// Amount can be less than the return asked for when earlier refunds left
+// less of the payment.
+type RefundIssuedEvent struct {
+	RefundID   int64     `json:"refund_id"`
+	ReturnID   int64     `json:"return_id"`
+	OrderID    int64     `json:"order_id"`
+	PaymentID  int64     `json:"payment_id"`
+	Amount     int64     `json:"amount"`
+	RefundedAt time.Time `json:"refunded_at"`
+}
+
 func (i *OrderItem) ToPB() *pb.OrderItem {
 	return &pb.OrderItem{
 		ProductId: i.ProductID,
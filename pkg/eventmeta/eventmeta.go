@@ -0,0 +1,109 @@
+// Package eventmeta carries event envelope metadata across services.
+//
+// Every outbox event gets a UUID event_id. correlation_id is shared by all
+// events of one business flow (the first event's id), and causation_id points
+// at the event whose handling produced this one. Consumers put the incoming
+// event on the context so events saved while handling it are chained.
+// tenant_id names the storefront the event belongs to.
+package eventmeta
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	HeaderEventID       = "event_id"
+	HeaderEventType     = "event_type"
+	HeaderOccurredAt    = "occurred_at"
+	HeaderProducer      = "producer"
+	HeaderCorrelationID = "correlation_id"
+	HeaderCausationID   = "causation_id"
+	HeaderTenantID      = "tenant_id"
+)
+
+type Metadata struct {
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+	Producer      string    `json:"producer,omitempty"`
+	CorrelationID string    `json:"correlation_id,omitempty"`
+	CausationID   string    `json:"causation_id,omitempty"`
+	TenantID      string    `json:"tenant_id,omitempty"`
+}
+
+// Headers renders metadata as Kafka header values.
+func (m Metadata) Headers() map[string]string {
+	headers := map[string]string{
+		HeaderEventID:    m.EventID,
+		HeaderOccurredAt: m.OccurredAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	optional := map[string]string{
+		HeaderEventType:     m.EventType,
+		HeaderProducer:      m.Producer,
+		HeaderCorrelationID: m.CorrelationID,
+		HeaderCausationID:   m.CausationID,
+		HeaderTenantID:      m.TenantID,
+	}
+	for k, v := range optional {
+		if v != "" {
+			headers[k] = v
+		}
+	}
+
+	return headers
+}
+
+// FromHeaders is the inverse of Headers. Missing values stay empty.
+func FromHeaders(headers map[string]string) Metadata {
+	m := Metadata{
+		EventID:       headers[HeaderEventID],
+		EventType:     headers[HeaderEventType],
+		Producer:      headers[HeaderProducer],
+		CorrelationID: headers[HeaderCorrelationID],
+		CausationID:   headers[HeaderCausationID],
+		TenantID:      headers[HeaderTenantID],
+	}
+
+	if raw := headers[HeaderOccurredAt]; raw != "" {
+		if t, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+			m.OccurredAt = t
+		}
+	}
+
+	return m
+}
+
+type ctxKey struct{}
+
+// WithIncoming records the event being handled on ctx.
+func WithIncoming(ctx context.Context, m Metadata) context.Context {
+	if m.EventID == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, ctxKey{}, m)
+}
+
+// Incoming returns the event being handled, if any.
+func Incoming(ctx context.Context) (Metadata, bool) {
+	m, ok := ctx.Value(ctxKey{}).(Metadata)
+	return m, ok
+}
+
+// Chain returns the correlation and causation ids for an event emitted under
+// ctx. Without an incoming event, the new event starts its own correlation.
+func Chain(ctx context.Context, eventID string) (correlationID, causationID string) {
+	incoming, ok := Incoming(ctx)
+	if !ok {
+		return eventID, ""
+	}
+
+	correlationID = incoming.CorrelationID
+	if correlationID == "" {
+		correlationID = incoming.EventID
+	}
+
+	return correlationID, incoming.EventID
+}
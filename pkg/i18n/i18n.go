@@ -0,0 +1,203 @@
+// Package i18n is a small translation bundle: JSON message files per language,
+// text/template placeholders, and Accept-Language negotiation with fallback
+// to the bundle's default language.
+//
+// Message files follow the go-i18n "flat" layout, one object per language
+// named <lang>.json:
+//
+//	{"email.activation.subject": "Welcome! Activate your account."}
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+
+	"golang.org/x/text/language"
+)
+
+// Bundle holds the messages of every supported language.
+type Bundle struct {
+	defaultLang language.Tag
+
+	mu        sync.RWMutex
+	tags      []language.Tag
+	messages  map[language.Tag]map[string]string
+	templates map[string]*template.Template
+	matcher   language.Matcher
+}
+
+func NewBundle(defaultLang language.Tag) *Bundle {
+	b := &Bundle{
+		defaultLang: defaultLang,
+		messages:    make(map[language.Tag]map[string]string),
+		templates:   make(map[string]*template.Template),
+	}
+	b.addTag(defaultLang)
+
+	return b
+}
+
+// AddMessages registers messages for lang, overriding existing ids.
+func (b *Bundle) AddMessages(lang language.Tag, messages map[string]string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.addTag(lang)
+	for id, text := range messages {
+		b.messages[lang][id] = text
+	}
+}
+
+// LoadFS reads every <lang>.json file in dir.
+func (b *Bundle) LoadFS(fsys fs.FS, dir string) error {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		lang, err := language.Parse(strings.TrimSuffix(path.Base(file), ".json"))
+		if err != nil {
+			return fmt.Errorf("i18n: %s: %w", file, err)
+		}
+
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return fmt.Errorf("i18n: %s: %w", file, err)
+		}
+
+		b.AddMessages(lang, messages)
+	}
+
+	return nil
+}
+
+// MustLoadFS is LoadFS for embedded files that are known to be valid.
+func (b *Bundle) MustLoadFS(fsys fs.FS, dir string) *Bundle {
+	if err := b.LoadFS(fsys, dir); err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+func (b *Bundle) addTag(lang language.Tag) {
+	if _, ok := b.messages[lang]; ok {
+		return
+	}
+
+	b.messages[lang] = make(map[string]string)
+	b.tags = append(b.tags, lang)
+	b.matcher = language.NewMatcher(b.tags)
+}
+
+// Localizer picks the best supported language for prefs, which may be
+// Accept-Language headers or plain tags like "ru". Empty or unknown
+// preferences resolve to the default language.
+func (b *Bundle) Localizer(prefs ...string) *Localizer {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var desired []language.Tag
+	for _, pref := range prefs {
+		tags, _, err := language.ParseAcceptLanguage(pref)
+		if err == nil {
+			desired = append(desired, tags...)
+		}
+	}
+
+	lang := b.defaultLang
+	if len(desired) > 0 {
+		_, idx, confidence := b.matcher.Match(desired...)
+		if confidence != language.No {
+			lang = b.tags[idx]
+		}
+	}
+
+	return &Localizer{bundle: b, lang: lang}
+}
+
+func (b *Bundle) lookup(lang language.Tag, id string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if text, ok := b.messages[lang][id]; ok {
+		return text, true
+	}
+
+	text, ok := b.messages[b.defaultLang][id]
+	return text, ok
+}
+
+func (b *Bundle) template(key, text string) (*template.Template, error) {
+	b.mu.RLock()
+	tmpl, ok := b.templates[key]
+	b.mu.RUnlock()
+	if ok {
+		return tmpl, nil
+	}
+
+	tmpl, err := template.New(key).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.templates[key] = tmpl
+	b.mu.Unlock()
+
+	return tmpl, nil
+}
+
+// Localizer translates messages into one language.
+type Localizer struct {
+	bundle *Bundle
+	lang   language.Tag
+}
+
+// Lang is the negotiated language, e.g. "ru".
+func (l *Localizer) Lang() string {
+	return l.lang.String()
+}
+
+// Has reports whether id is translated in the bundle at all.
+func (l *Localizer) Has(id string) bool {
+	_, ok := l.bundle.lookup(l.lang, id)
+	return ok
+}
+
+// T translates id, executing it as a template with data. Unknown ids are
+// returned unchanged so a missing translation never blanks a message.
+func (l *Localizer) T(id string, data any) string {
+	text, ok := l.bundle.lookup(l.lang, id)
+	if !ok {
+		return id
+	}
+
+	if data == nil || !strings.Contains(text, "{{") {
+		return text
+	}
+
+	tmpl, err := l.bundle.template(l.lang.String()+"/"+id, text)
+	if err != nil {
+		return text
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return text
+	}
+
+	return buf.String()
+}
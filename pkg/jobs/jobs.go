@@ -0,0 +1,234 @@
+// Package jobs runs a service's scheduled background work. Jobs run on cron
+// schedules, at most one replica at a time when the scheduler has a locker,
+// and a panicking run fails that run alone.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sakashimaa/go-pet-project/pkg/lock"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+// ErrPanic is returned for a run that panicked.
+var ErrPanic = errors.New("job panicked")
+
+// lockTTL bounds how long a run goes on after its replica lost the lock.
+const lockTTL = 30 * time.Second
+
+// Job is a named piece of work run on a schedule.
+type Job struct {
+	Name string
+	// Schedule is a five-field cron expression such as "0 6 * * *", or a
+	// descriptor: "@hourly", "@daily", "@every 15m".
+	Schedule string
+	Run      func(ctx context.Context) error
+	// RunOnStart also runs the job when the scheduler starts, rather than
+	// waiting for its first scheduled time.
+	RunOnStart bool
+	// Timeout bounds one run. Zero leaves it to the job.
+	Timeout time.Duration
+	// Local runs the job on every replica, without the lock, for work that
+	// belongs to the replica itself.
+	Local bool
+}
+
+type scheduled struct {
+	Job
+	schedule cron.Schedule
+}
+
+// Scheduler runs its jobs until the context of Start is cancelled.
+type Scheduler struct {
+	locker   *lock.Locker
+	location *time.Location
+	logger   *zap.Logger
+	jobs     []scheduled
+}
+
+type Option func(*Scheduler)
+
+// WithLocks takes a lock per job for the length of a run, so replicas
+// sharing locker's backend do not run a job at the same time. A replica
+// that finds the lock taken skips that run, and a run whose lock is lost
+// has its context cancelled. The lock is not held between runs: a replica
+// whose clock lags can still run a job again right after another finished
+// it, so jobs have to tolerate a repeated run.
+func WithLocks(locker *lock.Locker) Option {
+	return func(s *Scheduler) {
+		s.locker = locker
+	}
+}
+
+// WithLocation sets the time zone cron expressions are read in; UTC by
+// default.
+func WithLocation(location *time.Location) Option {
+	return func(s *Scheduler) {
+		s.location = location
+	}
+}
+
+func New(logger *zap.Logger, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		location: time.UTC,
+		logger:   logger,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Every is the schedule of a job that runs once per interval.
+func Every(interval time.Duration) string {
+	return "@every " + interval.String()
+}
+
+// Add registers a job. It fails for a schedule that does not parse or a
+// name that is empty or taken, so mistakes surface at startup.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return errors.New("job needs a name and a Run func")
+	}
+
+	for _, j := range s.jobs {
+		if j.Name == job.Name {
+			return fmt.Errorf("job %q is already scheduled", job.Name)
+		}
+	}
+
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q of job %q: %w", job.Schedule, job.Name, err)
+	}
+
+	s.jobs = append(s.jobs, scheduled{Job: job, schedule: schedule})
+
+	return nil
+}
+
+// Start runs every job on its schedule and blocks until ctx is cancelled
+// and the runs in progress have returned. Each job runs in its own
+// goroutine, and a run that is still going when the next one is due makes
+// that one wait rather than overlap.
+func (s *Scheduler) Start(ctx context.Context) {
+	mylogger.Info(ctx, s.logger, "Starting job scheduler", zap.Int("jobs", len(s.jobs)))
+
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, job)
+		}()
+	}
+
+	wg.Wait()
+	mylogger.Info(context.WithoutCancel(ctx), s.logger, "Job scheduler stopped")
+}
+
+func (s *Scheduler) loop(ctx context.Context, job scheduled) {
+	if job.RunOnStart {
+		s.run(ctx, job.Job)
+	}
+
+	for {
+		next := job.schedule.Next(time.Now().In(s.location))
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.run(ctx, job.Job)
+		}
+	}
+}
+
+// run runs job once and records how it went. It never panics.
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	var lost <-chan struct{}
+	if s.locker != nil && !job.Local {
+		held, err := s.locker.Acquire(ctx, "job:"+job.Name, lockTTL)
+		if errors.Is(err, lock.ErrNotAcquired) {
+			runs.WithLabelValues(job.Name, resultSkipped).Inc()
+			mylogger.Debug(ctx, s.logger, "Job is running on another replica, skipping", zap.String("job", job.Name))
+			return
+		}
+		if err != nil {
+			runs.WithLabelValues(job.Name, resultFailure).Inc()
+			mylogger.Error(ctx, s.logger, "Failed to lock job", zap.String("job", job.Name), zap.Error(err))
+			return
+		}
+		defer held.Release(ctx)
+
+		lost = held.Lost()
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if job.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		runCtx, cancelTimeout = context.WithTimeout(runCtx, job.Timeout)
+		defer cancelTimeout()
+	}
+
+	if lost != nil {
+		// Another replica may take the job over; stop this run.
+		go func() {
+			select {
+			case <-lost:
+				cancel()
+			case <-runCtx.Done():
+			}
+		}()
+	}
+
+	started := time.Now()
+	err := s.safeRun(runCtx, job)
+	elapsed := time.Since(started)
+
+	duration.WithLabelValues(job.Name).Observe(elapsed.Seconds())
+
+	switch {
+	case err == nil:
+		runs.WithLabelValues(job.Name, resultSuccess).Inc()
+		lastSuccess.WithLabelValues(job.Name).SetToCurrentTime()
+		mylogger.Debug(ctx, s.logger, "Job finished", zap.String("job", job.Name), zap.Duration("elapsed", elapsed))
+	case errors.Is(err, ErrPanic):
+		runs.WithLabelValues(job.Name, resultPanic).Inc()
+	default:
+		runs.WithLabelValues(job.Name, resultFailure).Inc()
+		mylogger.Error(ctx, s.logger, "Job failed", zap.String("job", job.Name), zap.Duration("elapsed", elapsed), zap.Error(err))
+	}
+}
+
+func (s *Scheduler) safeRun(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			mylogger.Error(
+				ctx,
+				s.logger,
+				"Job panicked",
+				zap.String("job", job.Name),
+				zap.Any("panic", r),
+				zap.ByteString("stack", debug.Stack()),
+			)
+
+			err = fmt.Errorf("%w: %v", ErrPanic, r)
+		}
+	}()
+
+	return job.Run(ctx)
+}
@@ -0,0 +1,39 @@
+package jobs
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Results of a run, the result label of jobs_runs_total.
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+	resultPanic   = "panic"
+	// resultSkipped is a run left to the replica holding the lock.
+	resultSkipped = "skipped"
+)
+
+var (
+	runs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "jobs_runs_total",
+		Help: "Scheduled job runs by result: success, failure, panic, or skipped because another replica held the lock.",
+	}, []string{"job", "result"})
+	duration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "jobs_run_duration_seconds",
+		Help:    "How long scheduled job runs took, failed ones included.",
+		Buckets: []float64{0.01, 0.1, 0.5, 1, 5, 15, 60, 300, 900},
+	}, []string{"job"})
+	lastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "jobs_last_success_timestamp_seconds",
+		Help: "Unix time of the last successful run of each job on this replica.",
+	}, []string{"job"})
+)
+
+// RegisterMetrics exposes the job metrics on reg.
+func RegisterMetrics(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{runs, duration, lastSuccess} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+)
+
+type MessageFormat string
+
+const (
+	// FormatEnvelope is the in-house {"event": ..., "payload": ...} shape.
+	FormatEnvelope MessageFormat = "envelope"
+	// FormatCloudEvents is CloudEvents 1.0 structured-mode JSON.
+	FormatCloudEvents MessageFormat = "cloudevents"
+
+	CloudEventsContentType = "application/cloudevents+json"
+	CloudEventsSpecVersion = "1.0"
+	HeaderContentType      = "content-type"
+
+	// CloudEventTypePrefix namespaces event types in reverse-DNS form; the
+	// last dot-separated segment is the in-house event name.
+	CloudEventTypePrefix = "com.github.sakashimaa.gopetproject."
+)
+
+func ParseMessageFormat(raw string) (MessageFormat, error) {
+	switch MessageFormat(strings.ToLower(strings.TrimSpace(raw))) {
+	case "", FormatEnvelope:
+		return FormatEnvelope, nil
+	case FormatCloudEvents:
+		return FormatCloudEvents, nil
+	case FormatProtobuf:
+		return FormatProtobuf, nil
+	default:
+		return "", fmt.Errorf("unknown kafka message format %q", raw)
+	}
+}
+
+// CloudEvent is a structured-mode CloudEvents 1.0 message. correlationid,
+// causationid and tenantid are extension attributes mirroring the envelope
+// metadata.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+	CorrelationID   string          `json:"correlationid,omitempty"`
+	CausationID     string          `json:"causationid,omitempty"`
+	TenantID        string          `json:"tenantid,omitempty"`
+}
+
+func NewCloudEvent(meta eventmeta.Metadata, subject string, data json.RawMessage) CloudEvent {
+	return CloudEvent{
+		SpecVersion:     CloudEventsSpecVersion,
+		ID:              meta.EventID,
+		Source:          "/" + meta.Producer,
+		Type:            CloudEventTypePrefix + meta.EventType,
+		Subject:         subject,
+		Time:            meta.OccurredAt.UTC(),
+		DataContentType: "application/json",
+		Data:            data,
+		CorrelationID:   meta.CorrelationID,
+		CausationID:     meta.CausationID,
+		TenantID:        meta.TenantID,
+	}
+}
+
+// EventName returns the in-house event name carried in Type.
+func (e CloudEvent) EventName() string {
+	return e.Type[strings.LastIndex(e.Type, ".")+1:]
+}
+
+// ToEnvelope converts a structured CloudEvent back into the in-house
+// envelope so existing consumers can handle either format.
+func (e CloudEvent) ToEnvelope() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"event":                       e.EventName(),
+		"payload":                     e.Data,
+		eventmeta.HeaderEventID:       e.ID,
+		eventmeta.HeaderOccurredAt:    e.Time,
+		eventmeta.HeaderProducer:      strings.TrimPrefix(e.Source, "/"),
+		eventmeta.HeaderCorrelationID: e.CorrelationID,
+		eventmeta.HeaderCausationID:   e.CausationID,
+		eventmeta.HeaderTenantID:      e.TenantID,
+	})
+}
+
+func isCloudEvent(headers map[string]string) bool {
+	return strings.HasPrefix(headers[HeaderContentType], CloudEventsContentType)
+}
@@ -0,0 +1,173 @@
+package kafka
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+// inFlightPerWorker bounds how far a claim reads ahead of its oldest
+// unfinished message. One slow message holds back the committed offset, not
+// the other workers, until the window is full.
+const inFlightPerWorker = 16
+
+type ConsumerConfig struct {
+	// Concurrency is the number of messages of one partition handled at a
+	// time. Messages with the same key are still handled in order.
+	Concurrency int `env:"KAFKA_CONSUMER_CONCURRENCY" env-default:"1"`
+	// InboxRetention is how long a PostgresInbox remembers an event. It has
+	// to outlast the topic retention for redeliveries to be skipped.
+	InboxRetention time.Duration `env:"KAFKA_INBOX_RETENTION" env-default:"168h"`
+}
+
+func LoadConsumerConfig() (ConsumerConfig, error) {
+	var cfg ConsumerConfig
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return ConsumerConfig{}, fmt.Errorf("error reading kafka consumer config: %w", err)
+	}
+
+	if cfg.Concurrency < 1 {
+		return ConsumerConfig{}, fmt.Errorf("KAFKA_CONSUMER_CONCURRENCY must be at least 1, got %d", cfg.Concurrency)
+	}
+
+	return cfg, nil
+}
+
+// WithConcurrency handles up to workers messages of each claimed partition
+// in parallel. Messages are routed to workers by key, so per-key ordering
+// holds; offsets are still marked in partition order, so a crash redelivers
+// rather than skips unfinished messages. Handlers must be safe to call
+// concurrently.
+func WithConcurrency(workers int) ConsumerOption {
+	return func(c *ConsumerGroup) {
+		c.concurrency = max(workers, 1)
+	}
+}
+
+type claimResult struct {
+	msg *sarama.ConsumerMessage
+	err error
+}
+
+// inFlight is a message handed to a worker, in partition order.
+type inFlight struct {
+	msg      *sarama.ConsumerMessage
+	finished bool
+	err      error
+}
+
+// consumeConcurrently is ConsumeClaim for concurrency > 1.
+func (h *saramaHandler) consumeConcurrently(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	maxInFlight := h.concurrency * inFlightPerWorker
+
+	// Buffers of maxInFlight keep dispatching and reporting from blocking
+	// while the window has room.
+	queues := make([]chan *sarama.ConsumerMessage, h.concurrency)
+	results := make(chan claimResult, maxInFlight)
+
+	var wg sync.WaitGroup
+	for i := range queues {
+		queues[i] = make(chan *sarama.ConsumerMessage, maxInFlight)
+
+		wg.Add(1)
+		go func(queue <-chan *sarama.ConsumerMessage) {
+			defer wg.Done()
+
+			for msg := range queue {
+				ctx := h.extractTracing(session.Context(), msg)
+				results <- claimResult{msg: msg, err: h.handle(ctx, msg)}
+			}
+		}(queues[i])
+	}
+
+	var (
+		pending    []*inFlight
+		byOffset   = make(map[int64]*inFlight, maxInFlight)
+		roundRobin int
+	)
+
+	finish := func(r claimResult) {
+		entry := byOffset[r.msg.Offset]
+		entry.finished = true
+		entry.err = r.err
+		delete(byOffset, r.msg.Offset)
+
+		if r.err != nil {
+			mylogger.Error(
+				session.Context(),
+				h.logger,
+				"Failed to process message",
+				zap.String("topic", r.msg.Topic),
+				zap.Int32("partition", r.msg.Partition),
+				zap.Int64("offset", r.msg.Offset),
+				zap.Error(r.err),
+			)
+		}
+
+		// Like the sequential path, a failed message is not marked itself
+		// but does not hold back the ones after it.
+		for len(pending) > 0 && pending[0].finished {
+			if pending[0].err == nil {
+				session.MarkMessage(pending[0].msg, "")
+			}
+			pending = pending[1:]
+		}
+	}
+
+	messages := claim.Messages()
+	for messages != nil {
+		in := messages
+		if len(pending) >= maxInFlight {
+			in = nil
+		}
+
+		select {
+		case msg, ok := <-in:
+			if !ok {
+				messages = nil
+				continue
+			}
+
+			entry := &inFlight{msg: msg}
+			pending = append(pending, entry)
+			byOffset[msg.Offset] = entry
+
+			worker := roundRobin % len(queues)
+			if len(msg.Key) > 0 {
+				hash := fnv.New32a()
+				_, _ = hash.Write(msg.Key)
+				worker = int(hash.Sum32() % uint32(len(queues)))
+			} else {
+				roundRobin++
+			}
+			queues[worker] <- msg
+		case r := <-results:
+			finish(r)
+		case <-session.Context().Done():
+			messages = nil
+		}
+	}
+
+	for _, queue := range queues {
+		close(queue)
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Let handlers already running finish and mark what completed in order;
+	// their context is the session's, so they see the rebalance.
+	for r := range results {
+		finish(r)
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/IBM/sarama"
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/xdg-go/scram"
+)
+
+// SASL mechanisms Config accepts.
+const (
+	SASLPlain       = sarama.SASLTypePlaintext
+	SASLScramSHA256 = sarama.SASLTypeSCRAMSHA256
+	SASLScramSHA512 = sarama.SASLTypeSCRAMSHA512
+)
+
+const defaultBroker = "localhost:9092"
+
+// Config is how a service reaches the cluster. Producers and consumer groups
+// of one service share it.
+type Config struct {
+	// Brokers are the seed brokers, from a comma-separated KAFKA_URL. The
+	// older KAFKA_HOST is read when KAFKA_URL is unset.
+	Brokers []string `env:"KAFKA_URL" env-separator:","`
+	// ClientID names the service in broker logs, quotas and ACLs.
+	ClientID string `env:"KAFKA_CLIENT_ID"`
+
+	SASL SASLConfig
+	TLS  TLSConfig
+}
+
+type SASLConfig struct {
+	// Mechanism is PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512; empty turns
+	// SASL off.
+	Mechanism string `env:"KAFKA_SASL_MECHANISM"`
+	User      string `env:"KAFKA_SASL_USER"`
+	Password  string `env:"KAFKA_SASL_PASSWORD"`
+}
+
+type TLSConfig struct {
+	Enabled bool `env:"KAFKA_TLS_ENABLED"`
+	// CAFile verifies the brokers against a private CA instead of the
+	// system roots.
+	CAFile string `env:"KAFKA_TLS_CA_FILE"`
+	// CertFile and KeyFile are the client certificate for mutual TLS.
+	CertFile string `env:"KAFKA_TLS_CERT_FILE"`
+	KeyFile  string `env:"KAFKA_TLS_KEY_FILE"`
+}
+
+// LoadConfig reads the connection settings from the environment. clientID
+// is used unless KAFKA_CLIENT_ID overrides it.
+func LoadConfig(clientID string) (Config, error) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return Config{}, fmt.Errorf("error reading kafka config: %w", err)
+	}
+
+	if len(cfg.Brokers) == 0 {
+		cfg.Brokers = splitBrokers(os.Getenv("KAFKA_HOST"))
+	}
+	if len(cfg.Brokers) == 0 {
+		cfg.Brokers = []string{defaultBroker}
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = clientID
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+func splitBrokers(list string) []string {
+	var brokers []string
+	for _, broker := range strings.Split(list, ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+
+	return brokers
+}
+
+func (c Config) validate() error {
+	switch c.SASL.Mechanism {
+	case "":
+	case SASLPlain, SASLScramSHA256, SASLScramSHA512:
+		if c.SASL.User == "" || c.SASL.Password == "" {
+			return fmt.Errorf("KAFKA_SASL_MECHANISM %s needs KAFKA_SASL_USER and KAFKA_SASL_PASSWORD", c.SASL.Mechanism)
+		}
+	default:
+		return fmt.Errorf("unknown KAFKA_SASL_MECHANISM %q: want %s, %s or %s", c.SASL.Mechanism, SASLPlain, SASLScramSHA256, SASLScramSHA512)
+	}
+
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return errors.New("KAFKA_TLS_CERT_FILE and KAFKA_TLS_KEY_FILE must be set together")
+	}
+
+	return nil
+}
+
+// BrokerList is Brokers without the blanks a hand-built Config may contain.
+func (c Config) BrokerList() []string {
+	return splitBrokers(strings.Join(c.Brokers, ","))
+}
+
+// Sarama starts a sarama config with the connection settings applied, for
+// the producer, consumer groups and tools that talk to the cluster directly.
+func (c Config) Sarama() (*sarama.Config, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+
+	config := sarama.NewConfig()
+	if c.ClientID != "" {
+		config.ClientID = c.ClientID
+	}
+
+	if c.SASL.Mechanism != "" {
+		config.Net.SASL.Enable = true
+		config.Net.SASL.Mechanism = sarama.SASLMechanism(c.SASL.Mechanism)
+		config.Net.SASL.User = c.SASL.User
+		config.Net.SASL.Password = c.SASL.Password
+
+		switch c.SASL.Mechanism {
+		case SASLScramSHA256:
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hash: scram.SHA256}
+			}
+		case SASLScramSHA512:
+			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+				return &scramClient{hash: scram.SHA512}
+			}
+		}
+	}
+
+	if c.TLS.Enabled {
+		tlsConfig, err := c.TLS.build()
+		if err != nil {
+			return nil, err
+		}
+
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+	}
+
+	return config, nil
+}
+
+func (c TLSConfig) build() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading kafka CA file: %w", err)
+		}
+
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in kafka CA file %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error loading kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// scramClient runs one SCRAM conversation for sarama.
+type scramClient struct {
+	hash         scram.HashGeneratorFcn
+	conversation *scram.ClientConversation
+}
+
+func (s *scramClient) Begin(user, password, authzID string) error {
+	client, err := s.hash.NewClient(user, password, authzID)
+	if err != nil {
+		return err
+	}
+
+	s.conversation = client.NewConversation()
+	return nil
+}
+
+func (s *scramClient) Step(challenge string) (string, error) {
+	return s.conversation.Step(challenge)
+}
+
+func (s *scramClient) Done() bool {
+	return s.conversation.Done()
+}
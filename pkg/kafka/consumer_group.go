@@ -2,50 +2,90 @@ package kafka
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
 
 	"github.com/IBM/sarama"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
-	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
 type HandlerFunc func(ctx context.Context, msg *sarama.ConsumerMessage) error
 
 type ConsumerGroup struct {
-	brokers     []string
+	config      Config
 	groupID     string
 	topics      []string
 	handlerFunc HandlerFunc
 	logger      *zap.Logger
+	keyring     *Keyring
+	concurrency int
+	middleware  []Middleware
+	onSession   func(active bool)
+}
+
+type ConsumerOption func(*ConsumerGroup)
+
+// WithDecryption decrypts messages encrypted by an outbox configured with
+// the same keyring before handlers see them. A nil keyring is allowed and
+// leaves encrypted messages failing.
+func WithDecryption(keyring *Keyring) ConsumerOption {
+	return func(c *ConsumerGroup) {
+		c.keyring = keyring
+	}
+}
+
+// WithSessionState calls fn with true when the group starts a session and
+// with false when the session ends, on a rebalance or when the brokers are
+// lost. A consumer that cannot join the group never reports true.
+func WithSessionState(fn func(active bool)) ConsumerOption {
+	return func(c *ConsumerGroup) {
+		c.onSession = fn
+	}
 }
 
 func NewConsumerGroup(
-	brokers []string,
+	config Config,
 	groupID string,
 	topics []string,
 	handlerFunc HandlerFunc,
 	logger *zap.Logger,
+	opts ...ConsumerOption,
 ) *ConsumerGroup {
-	return &ConsumerGroup{
-		brokers:     brokers,
+	c := &ConsumerGroup{
+		config:      config,
 		groupID:     groupID,
 		topics:      topics,
 		handlerFunc: handlerFunc,
 		logger:      logger,
+		concurrency: 1,
+	}
+
+	for _, opt := range opts {
+		opt(c)
 	}
+
+	return c
 }
 
 func (c *ConsumerGroup) Run(ctx context.Context) {
-	config := sarama.NewConfig()
+	config, err := c.config.Sarama()
+	if err != nil {
+		log.Fatalf("Error configuring consumer group: %v", err)
+	}
 	config.Version = sarama.V3_0_0_0
 	config.Consumer.Return.Errors = true
 	config.Consumer.Offsets.Initial = sarama.OffsetOldest
+	// Skip messages of aborted transactions; plain producers are unaffected.
+	config.Consumer.IsolationLevel = sarama.ReadCommitted
 	config.Consumer.Group.Rebalance.GroupStrategies = []sarama.BalanceStrategy{sarama.BalanceStrategyRoundRobin}
 
-	group, err := sarama.NewConsumerGroup(c.brokers, c.groupID, config)
+	group, err := sarama.NewConsumerGroup(c.config.BrokerList(), c.groupID, config)
 	if err != nil {
 		log.Fatalf("Error creating new consumer group")
 	}
@@ -56,9 +96,15 @@ func (c *ConsumerGroup) Run(ctx context.Context) {
 		}
 	}()
 
+	// Tracing is outermost so a recovered panic still ends up on the span.
+	handler := Chain(c.handlerFunc, append([]Middleware{Tracing(), Recover(c.logger)}, c.middleware...)...)
+
 	consumer := &saramaHandler{
-		handler: c.handlerFunc,
-		logger:  c.logger,
+		handler:     handler,
+		logger:      c.logger,
+		keyring:     c.keyring,
+		concurrency: c.concurrency,
+		onSession:   c.onSession,
 	}
 
 	for {
@@ -75,18 +121,36 @@ func (c *ConsumerGroup) Run(ctx context.Context) {
 }
 
 type saramaHandler struct {
-	handler HandlerFunc
-	logger  *zap.Logger
+	handler     HandlerFunc
+	logger      *zap.Logger
+	keyring     *Keyring
+	concurrency int
+	onSession   func(active bool)
+}
+
+func (h *saramaHandler) Setup(_ sarama.ConsumerGroupSession) error {
+	if h.onSession != nil {
+		h.onSession(true)
+	}
+	return nil
 }
 
-func (h *saramaHandler) Setup(_ sarama.ConsumerGroupSession) error   { return nil }
-func (h *saramaHandler) Cleanup(_ sarama.ConsumerGroupSession) error { return nil }
+func (h *saramaHandler) Cleanup(_ sarama.ConsumerGroupSession) error {
+	if h.onSession != nil {
+		h.onSession(false)
+	}
+	return nil
+}
 
 func (h *saramaHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	if h.concurrency > 1 {
+		return h.consumeConcurrently(session, claim)
+	}
+
 	for msg := range claim.Messages() {
 		ctx := h.extractTracing(session.Context(), msg)
 
-		err := h.handler(ctx, msg)
+		err := h.handle(ctx, msg)
 		if err == nil {
 			session.MarkMessage(msg, "")
 		} else {
@@ -105,6 +169,58 @@ func (h *saramaHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim
 	return nil
 }
 
+// handle hands the message to the consumer, decrypting it and translating
+// CloudEvents and protobuf payloads into the in-house envelope first so
+// handlers only ever see one format.
+func (h *saramaHandler) handle(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[string(header.Key)] = string(header.Value)
+	}
+
+	if isEncrypted(headers) {
+		plaintext, err := h.keyring.Decrypt(headers, msg.Value)
+		if err != nil {
+			return err
+		}
+
+		decrypted := *msg
+		decrypted.Value = plaintext
+		msg = &decrypted
+	}
+
+	if isProtobuf(headers) {
+		envelope, err := protobufToEnvelope(headers, msg.Value)
+		if err != nil {
+			return err
+		}
+
+		translated := *msg
+		translated.Value = envelope
+
+		return h.handler(ctx, &translated)
+	}
+
+	if !isCloudEvent(headers) {
+		return h.handler(ctx, msg)
+	}
+
+	var event CloudEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return fmt.Errorf("invalid cloudevent: %w", err)
+	}
+
+	envelope, err := event.ToEnvelope()
+	if err != nil {
+		return err
+	}
+
+	translated := *msg
+	translated.Value = envelope
+
+	return h.handler(ctx, &translated)
+}
+
 func (h *saramaHandler) extractTracing(ctx context.Context, msg *sarama.ConsumerMessage) context.Context {
 	carrier := propagation.MapCarrier{}
 	for _, header := range msg.Headers {
@@ -113,11 +229,9 @@ func (h *saramaHandler) extractTracing(ctx context.Context, msg *sarama.Consumer
 
 	propagator := otel.GetTextMapPropagator()
 	ctx = propagator.Extract(ctx, carrier)
+	meta := eventmeta.FromHeaders(carrier)
+	ctx = eventmeta.WithIncoming(ctx, meta)
+	ctx = tenant.WithID(ctx, meta.TenantID)
 
-	tracer := otel.Tracer("pkg/kafka/consumer")
-	ctx, _ = tracer.Start(ctx, "kafka_process",
-		trace.WithSpanKind(trace.SpanKindConsumer),
-		trace.WithAttributes(),
-	)
 	return ctx
 }
@@ -0,0 +1,47 @@
+package kafka
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Results of handling a message, the result label of
+// kafka_consumer_messages_total.
+const (
+	resultSuccess = "success"
+	resultFailure = "failure"
+	resultPanic   = "panic"
+)
+
+var (
+	consumed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_messages_total",
+		Help: "Messages handled by the Metrics middleware, by topic, event type and result: success, failure or panic.",
+	}, []string{"topic", "event_type", "result"})
+	consumeDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kafka_consumer_handle_duration_seconds",
+		Help:    "How long handling a message took, retries and failures included.",
+		Buckets: []float64{0.005, 0.025, 0.1, 0.5, 1, 5, 30, 120},
+	}, []string{"topic", "event_type"})
+	duplicates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_consumer_duplicates_total",
+		Help: "Redelivered messages the Deduplicate middleware skipped.",
+	}, []string{"topic"})
+	unknownEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_router_unknown_events_total",
+		Help: "Messages a Router skipped because no route handles their event type.",
+	}, []string{"topic", "event_type"})
+	malformedEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_router_malformed_events_total",
+		Help: "Messages a Router could not decode, failed or skipped depending on StrictPayloads.",
+	}, []string{"topic", "event_type"})
+)
+
+// RegisterConsumerMetrics exposes the metrics of the consumer middleware and
+// Router on reg.
+func RegisterConsumerMetrics(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{consumed, consumeDuration, duplicates, unknownEvents, malformedEvents} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,212 @@
+package kafka
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+const (
+	// HeaderEncryption names the cipher of an encrypted payload. Producers
+	// leave it unset on plaintext messages.
+	HeaderEncryption = "encryption"
+	// HeaderEncryptionKeyID names the keyring key that wraps the data key.
+	HeaderEncryptionKeyID = "encryption-key-id"
+	// HeaderEncryptedDataKey carries the wrapped per-message data key.
+	HeaderEncryptedDataKey = "encrypted-data-key"
+
+	EncryptionAES256GCM = "aes-256-gcm"
+)
+
+var ErrNoDecryptionKey = errors.New("no key to decrypt message")
+
+// RawMessage is sent as is instead of being encoded as JSON, e.g. an
+// encrypted payload.
+type RawMessage []byte
+
+// EncryptionConfig is read from the secrets the deployment injects into the
+// environment.
+type EncryptionConfig struct {
+	// Keys is a comma-separated list of id:base64 AES-256 keys. Keep retired
+	// keys listed until no message encrypted with them is left in Kafka.
+	Keys string `env:"KAFKA_ENCRYPTION_KEYS"`
+	// PrimaryKey is the id of the key new messages are encrypted with.
+	PrimaryKey string `env:"KAFKA_ENCRYPTION_PRIMARY_KEY"`
+	// Topics are the topics the outbox encrypts, e.g. the ones with PII.
+	Topics []string `env:"KAFKA_ENCRYPTED_TOPICS" env-separator:","`
+}
+
+func LoadEncryptionConfig() (EncryptionConfig, error) {
+	var cfg EncryptionConfig
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return EncryptionConfig{}, fmt.Errorf("error reading kafka encryption config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// Keyring parses Keys. It returns nil when no keys are configured.
+func (c EncryptionConfig) Keyring() (*Keyring, error) {
+	if strings.TrimSpace(c.Keys) == "" {
+		if len(c.Topics) > 0 {
+			return nil, errors.New("KAFKA_ENCRYPTED_TOPICS is set but KAFKA_ENCRYPTION_KEYS is empty")
+		}
+		return nil, nil
+	}
+
+	keys := make(map[string][]byte)
+	for _, entry := range strings.Split(c.Keys, ",") {
+		id, encoded, ok := strings.Cut(strings.TrimSpace(entry), ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("invalid kafka encryption key entry %q: want id:base64", entry)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid kafka encryption key %s: %w", id, err)
+		}
+		keys[id] = key
+	}
+
+	return NewKeyring(c.PrimaryKey, keys)
+}
+
+// Encrypts reports whether topic is one of the configured topics.
+func (c EncryptionConfig) Encrypts(topic string) bool {
+	return slices.Contains(c.Topics, topic)
+}
+
+// Keyring holds the key-encryption keys. Each message gets its own data key,
+// which is wrapped with the primary key and travels in the headers, so
+// rotating the primary key never requires re-encrypting stored messages.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+func NewKeyring(primary string, keys map[string][]byte) (*Keyring, error) {
+	if _, ok := keys[primary]; !ok {
+		return nil, fmt.Errorf("primary kafka encryption key %q is not in the keyring", primary)
+	}
+
+	k := &Keyring{primary: primary, keys: make(map[string]cipher.AEAD, len(keys))}
+	for id, key := range keys {
+		if len(key) != 32 {
+			return nil, fmt.Errorf("kafka encryption key %s must be 32 bytes, got %d", id, len(key))
+		}
+
+		aead, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		k.keys[id] = aead
+	}
+
+	return k, nil
+}
+
+// Encrypt seals value under a fresh data key and returns the ciphertext with
+// the headers needed to decrypt it.
+func (k *Keyring) Encrypt(value []byte) ([]byte, map[string]string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, nil, fmt.Errorf("error generating data key: %w", err)
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ciphertext, err := seal(aead, value)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wrapped, err := seal(k.keys[k.primary], dataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ciphertext, map[string]string{
+		HeaderEncryption:       EncryptionAES256GCM,
+		HeaderEncryptionKeyID:  k.primary,
+		HeaderEncryptedDataKey: base64.StdEncoding.EncodeToString(wrapped),
+	}, nil
+}
+
+// Decrypt reverses Encrypt using the key named in headers.
+func (k *Keyring) Decrypt(headers map[string]string, value []byte) ([]byte, error) {
+	if alg := headers[HeaderEncryption]; alg != EncryptionAES256GCM {
+		return nil, fmt.Errorf("unsupported message encryption %q", alg)
+	}
+
+	var kek cipher.AEAD
+	if k != nil {
+		kek = k.keys[headers[HeaderEncryptionKeyID]]
+	}
+	if kek == nil {
+		return nil, fmt.Errorf("%w: key %q", ErrNoDecryptionKey, headers[HeaderEncryptionKeyID])
+	}
+
+	wrapped, err := base64.StdEncoding.DecodeString(headers[HeaderEncryptedDataKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted data key: %w", err)
+	}
+
+	dataKey, err := open(kek, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("error unwrapping data key: %w", err)
+	}
+
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := open(aead, value)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting message: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func isEncrypted(headers map[string]string) bool {
+	return headers[HeaderEncryption] != ""
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// seal prefixes the ciphertext with its random nonce.
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
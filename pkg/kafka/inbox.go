@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresInbox is an Inbox kept in a table of the service's database with
+// an event_id TEXT primary key and a processed_at timestamp to purge by.
+type PostgresInbox struct {
+	pool  *pgxpool.Pool
+	seen  string
+	mark  string
+	table string
+}
+
+func NewPostgresInbox(pool *pgxpool.Pool, table string) *PostgresInbox {
+	ident := pgx.Identifier{table}.Sanitize()
+
+	return &PostgresInbox{
+		pool:  pool,
+		seen:  fmt.Sprintf("SELECT EXISTS (SELECT 1 FROM %s WHERE event_id = $1)", ident),
+		mark:  fmt.Sprintf("INSERT INTO %s (event_id) VALUES ($1) ON CONFLICT (event_id) DO NOTHING", ident),
+		table: table,
+	}
+}
+
+func (i *PostgresInbox) Seen(ctx context.Context, eventID string) (bool, error) {
+	var seen bool
+	if err := i.pool.QueryRow(ctx, i.seen, eventID).Scan(&seen); err != nil {
+		return false, fmt.Errorf("error reading %s: %w", i.table, err)
+	}
+
+	return seen, nil
+}
+
+func (i *PostgresInbox) Mark(ctx context.Context, eventID string) error {
+	if _, err := i.pool.Exec(ctx, i.mark, eventID); err != nil {
+		return fmt.Errorf("error writing %s: %w", i.table, err)
+	}
+
+	return nil
+}
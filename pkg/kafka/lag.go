@@ -0,0 +1,93 @@
+package kafka
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/IBM/sarama"
+)
+
+// PartitionLag is how far a consumer group is behind on one partition.
+type PartitionLag struct {
+	Topic     string
+	Partition int32
+	// Committed is -1 when the group has not committed on the partition
+	// yet; the whole partition then counts as lag.
+	Committed int64
+	Newest    int64
+	Lag       int64
+}
+
+// GroupLag reads the committed offsets of groupID on topics and compares them
+// with the newest offsets. It only reads, so it is safe while the group runs.
+func GroupLag(cfg Config, groupID string, topics []string) ([]PartitionLag, error) {
+	config, err := cfg.Sarama()
+	if err != nil {
+		return nil, err
+	}
+	config.Version = sarama.V3_0_0_0
+
+	client, err := sarama.NewClient(cfg.BrokerList(), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to kafka: %w", err)
+	}
+	defer client.Close()
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka admin: %w", err)
+	}
+
+	partitions := make(map[string][]int32, len(topics))
+	for _, topic := range topics {
+		ids, err := client.Partitions(topic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list partitions of %s: %w", topic, err)
+		}
+		partitions[topic] = ids
+	}
+
+	committed, err := admin.ListConsumerGroupOffsets(groupID, partitions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offsets of group %s: %w", groupID, err)
+	}
+	if committed.Err != sarama.ErrNoError {
+		return nil, fmt.Errorf("failed to read offsets of group %s: %w", groupID, committed.Err)
+	}
+
+	var lags []PartitionLag
+	for topic, ids := range partitions {
+		for _, partition := range ids {
+			newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read newest offset of %s/%d: %w", topic, partition, err)
+			}
+
+			lag := PartitionLag{Topic: topic, Partition: partition, Committed: -1, Newest: newest}
+			if block := committed.GetBlock(topic, partition); block != nil && block.Offset >= 0 {
+				lag.Committed = block.Offset
+			}
+
+			if lag.Committed >= 0 {
+				lag.Lag = max(newest-lag.Committed, 0)
+			} else {
+				oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+				if err != nil {
+					return nil, fmt.Errorf("failed to read oldest offset of %s/%d: %w", topic, partition, err)
+				}
+				lag.Lag = newest - oldest
+			}
+
+			lags = append(lags, lag)
+		}
+	}
+
+	sort.Slice(lags, func(i, j int) bool {
+		if lags[i].Topic != lags[j].Topic {
+			return lags[i].Topic < lags[j].Topic
+		}
+		return lags[i].Partition < lags[j].Partition
+	})
+
+	return lags, nil
+}
@@ -0,0 +1,243 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ErrHandlerPanic is returned for a message whose handler panicked.
+var ErrHandlerPanic = errors.New("message handler panicked")
+
+// Middleware wraps a HandlerFunc with behaviour shared by consumers, such as
+// logging, metrics or retries.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Chain wraps h in mws, the first of them outermost.
+func Chain(h HandlerFunc, mws ...Middleware) HandlerFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+
+	return h
+}
+
+// WithMiddleware runs every message through mws, the first of them
+// outermost, on its way to the handler. Tracing and panic recovery are
+// always applied outside of them.
+func WithMiddleware(mws ...Middleware) ConsumerOption {
+	return func(c *ConsumerGroup) {
+		c.middleware = append(c.middleware, mws...)
+	}
+}
+
+// Header returns the value of the header key of msg, or "".
+func Header(msg *sarama.ConsumerMessage, key string) string {
+	for _, h := range msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+
+	return ""
+}
+
+// eventType names the event msg carries, for span names and labels.
+func eventType(msg *sarama.ConsumerMessage) string {
+	if t := Header(msg, eventmeta.HeaderEventType); t != "" {
+		return t
+	}
+
+	return "unknown"
+}
+
+// Tracing handles every message in a consumer span named after its event
+// type, a child of the producer's span when the message carries one.
+func Tracing() Middleware {
+	tracer := otel.Tracer("pkg/kafka/consumer")
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+			ctx, span := tracer.Start(ctx, "process "+eventType(msg),
+				trace.WithSpanKind(trace.SpanKindConsumer),
+				trace.WithAttributes(
+					attribute.String("messaging.system", "kafka"),
+					attribute.String("messaging.destination.name", msg.Topic),
+					attribute.Int("messaging.kafka.partition", int(msg.Partition)),
+					attribute.Int64("messaging.kafka.offset", msg.Offset),
+					attribute.String("messaging.message.id", Header(msg, eventmeta.HeaderEventID)),
+				),
+			)
+			defer span.End()
+
+			err := next(ctx, msg)
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+
+			return err
+		}
+	}
+}
+
+// Recover turns a panicking handler into ErrHandlerPanic, so the message is
+// left for redelivery instead of taking the consumer down.
+func Recover(logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg *sarama.ConsumerMessage) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					mylogger.Error(
+						ctx,
+						logger,
+						"Message handler panicked",
+						zap.String("topic", msg.Topic),
+						zap.Int64("offset", msg.Offset),
+						zap.Any("panic", r),
+						zap.ByteString("stack", debug.Stack()),
+					)
+
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Logging logs every message as it is picked up and how long it took.
+// Failures are logged by the consumer group itself.
+func Logging(logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+			fields := []zap.Field{
+				zap.String("topic", msg.Topic),
+				zap.Int32("partition", msg.Partition),
+				zap.Int64("offset", msg.Offset),
+				zap.String("event_type", eventType(msg)),
+			}
+
+			mylogger.Info(ctx, logger, "Processing message", fields...)
+
+			started := time.Now()
+			err := next(ctx, msg)
+			if err == nil {
+				mylogger.Debug(ctx, logger, "Processed message", append(fields, zap.Duration("elapsed", time.Since(started)))...)
+			}
+
+			return err
+		}
+	}
+}
+
+// Metrics counts messages by topic, event type and result, and times their
+// handling. See RegisterConsumerMetrics.
+func Metrics() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+			started := time.Now()
+			err := next(ctx, msg)
+
+			event := eventType(msg)
+			consumeDuration.WithLabelValues(msg.Topic, event).Observe(time.Since(started).Seconds())
+
+			result := resultSuccess
+			switch {
+			case errors.Is(err, ErrHandlerPanic):
+				result = resultPanic
+			case err != nil:
+				result = resultFailure
+			}
+			consumed.WithLabelValues(msg.Topic, event, result).Inc()
+
+			return err
+		}
+	}
+}
+
+// Retry calls the handler again after a failure, as policy allows, before
+// the message goes back to the consumer. A policy without OnRetry logs each
+// retry.
+func Retry(policy retry.Policy, logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+			p := policy
+			if p.OnRetry == nil {
+				p.OnRetry = func(attempt int, err error, wait time.Duration) {
+					mylogger.Warn(
+						ctx,
+						logger,
+						"Retrying message",
+						zap.String("topic", msg.Topic),
+						zap.Int64("offset", msg.Offset),
+						zap.Int("attempt", attempt),
+						zap.Duration("wait", wait),
+						zap.Error(err),
+					)
+				}
+			}
+
+			return retry.Do(ctx, p, func(ctx context.Context) error {
+				return next(ctx, msg)
+			})
+		}
+	}
+}
+
+// Inbox remembers which events a consumer has handled.
+type Inbox interface {
+	Seen(ctx context.Context, eventID string) (bool, error)
+	Mark(ctx context.Context, eventID string) error
+}
+
+// Deduplicate skips messages whose event_id header inbox has already seen,
+// and records each one the handler succeeded with. Checking and recording
+// are not atomic with the handler, so a crash in between still redelivers
+// and handlers stay idempotent; this only keeps redeliveries from doing the
+// work again. Messages without an event_id always reach the handler.
+func Deduplicate(inbox Inbox, logger *zap.Logger) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+			eventID := Header(msg, eventmeta.HeaderEventID)
+			if eventID == "" {
+				return next(ctx, msg)
+			}
+
+			seen, err := inbox.Seen(ctx, eventID)
+			if err != nil {
+				return fmt.Errorf("error checking inbox: %w", err)
+			}
+			if seen {
+				duplicates.WithLabelValues(msg.Topic).Inc()
+				mylogger.Info(ctx, logger, "Event already processed, skipping", zap.String("event_id", eventID))
+
+				return nil
+			}
+
+			if err := next(ctx, msg); err != nil {
+				return err
+			}
+
+			// The work is done; failing now would only redeliver it.
+			if err := inbox.Mark(ctx, eventID); err != nil {
+				mylogger.Warn(ctx, logger, "Failed to record processed event", zap.String("event_id", eventID), zap.Error(err))
+			}
+
+			return nil
+		}
+	}
+}
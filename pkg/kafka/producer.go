@@ -3,31 +3,167 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"time"
 
 	"github.com/IBM/sarama"
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 )
 
 type Producer interface {
 	ProduceMessage(ctx context.Context, topic string, message interface{}) error
+	// ProduceMessageWithHeaders sends message keyed by key (empty for no key)
+	// with extra headers on top of the trace context.
+	ProduceMessageWithHeaders(ctx context.Context, topic, key string, message interface{}, headers map[string]string) error
+	ProduceMessages(ctx context.Context, topic string, messages []Message) error
 	Close() error
 }
 
+// Message is one record of a ProduceMessages batch.
+type Message struct {
+	// Key picks the partition; empty for no key.
+	Key     string
+	Value   interface{}
+	Headers map[string]string
+	// Partition and Offset are where the message was written. ProduceMessages
+	// sets them on the messages it sent and leaves the rest alone.
+	Partition int32
+	Offset    int64
+}
+
+// BatchError lists the messages of a batch that were not written, by index.
+// The rest of the batch was.
+type BatchError struct {
+	Failed map[int]error
+}
+
+func (e *BatchError) Error() string {
+	first := -1
+	for i := range e.Failed {
+		if first == -1 || i < first {
+			first = i
+		}
+	}
+
+	return fmt.Sprintf("%d message(s) of the batch failed, first #%d: %v", len(e.Failed), first, e.Failed[first])
+}
+
+// TransactionalProducer publishes the messages sent between BeginTxn and
+// CommitTxn atomically, across topics: consumers reading committed data see
+// all of them or, after AbortTxn, none. A transaction is per producer, so
+// one producer must not run transactions from several goroutines at once.
+type TransactionalProducer interface {
+	Producer
+	BeginTxn() error
+	CommitTxn() error
+	AbortTxn() error
+}
+
+var ErrTransactionalIDRequired = errors.New("transactional producer needs a transactional id")
+
 type producer struct {
 	syncProducer sarama.SyncProducer
 }
 
-func NewProducer(brokers []string) (Producer, error) {
-	config := sarama.NewConfig()
+type ProducerOption func(*sarama.Config)
+
+// DefaultProducerRetry is how the producer retries sends the brokers reject
+// as transient, e.g. during a leader election.
+func DefaultProducerRetry() retry.Policy {
+	return retry.Policy{
+		MaxAttempts: 6,
+		Initial:     100 * time.Millisecond,
+		Max:         2 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// WithRetry replaces the producer's retry policy. sarama does the retrying,
+// as it knows which messages of a batch to resend; the policy's classifier
+// is not used.
+func WithRetry(policy retry.Policy) ProducerOption {
+	return func(config *sarama.Config) {
+		config.Producer.Retry.Max = max(policy.MaxAttempts, 1) - 1
+		config.Producer.Retry.BackoffFunc = func(retries, _ int) time.Duration {
+			return policy.Backoff(retries)
+		}
+	}
+}
+
+// IsRetryable reports whether a failed send may succeed if tried again:
+// the cluster was unreachable or moving a partition, not the message at
+// fault.
+func IsRetryable(err error) bool {
+	var producerErrs sarama.ProducerErrors
+	if errors.As(err, &producerErrs) {
+		for _, pe := range producerErrs {
+			if IsRetryable(pe.Err) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var kerr sarama.KError
+	if errors.As(err, &kerr) {
+		switch kerr {
+		case sarama.ErrUnknownTopicOrPartition,
+			sarama.ErrLeaderNotAvailable,
+			sarama.ErrNotLeaderForPartition,
+			sarama.ErrRequestTimedOut,
+			sarama.ErrBrokerNotAvailable,
+			sarama.ErrNetworkException,
+			sarama.ErrNotEnoughReplicas,
+			sarama.ErrNotEnoughReplicasAfterAppend,
+			sarama.ErrKafkaStorageError:
+			return true
+		}
+
+		return false
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return errors.Is(err, sarama.ErrOutOfBrokers) || errors.Is(err, sarama.ErrNotConnected)
+}
+
+// WithIdempotence makes retries after a lost ack not write the message twice.
+// It needs brokers of Kafka 0.11 or later.
+func WithIdempotence() ProducerOption {
+	return func(config *sarama.Config) {
+		config.Version = sarama.V3_0_0_0
+		config.Producer.Idempotent = true
+		// The broker can only order retries of one in-flight request.
+		config.Net.MaxOpenRequests = 1
+	}
+}
+
+func NewProducer(cfg Config, opts ...ProducerOption) (Producer, error) {
+	config, err := cfg.Sarama()
+	if err != nil {
+		return nil, err
+	}
 	config.Producer.Return.Successes = true
 	config.Producer.RequiredAcks = sarama.WaitForAll
-	config.Producer.Retry.Max = 5
+	WithRetry(DefaultProducerRetry())(config)
 
-	p, err := sarama.NewSyncProducer(brokers, config)
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	p, err := sarama.NewSyncProducer(cfg.BrokerList(), config)
 	if err != nil {
 		return nil, fmt.Errorf("error creating producer: %v", err)
 	}
@@ -35,8 +171,76 @@ func NewProducer(brokers []string) (Producer, error) {
 	return &producer{syncProducer: p}, nil
 }
 
+// NewTransactionalProducer creates an idempotent producer with the given
+// transactional id. The id must be stable across restarts of the same
+// instance and unique between instances: a new producer with the same id
+// fences off the old one and aborts its open transaction.
+func NewTransactionalProducer(cfg Config, transactionalID string, opts ...ProducerOption) (TransactionalProducer, error) {
+	if transactionalID == "" {
+		return nil, ErrTransactionalIDRequired
+	}
+
+	opts = append([]ProducerOption{
+		WithIdempotence(),
+		func(config *sarama.Config) {
+			config.Producer.Transaction.ID = transactionalID
+		},
+	}, opts...)
+
+	p, err := NewProducer(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.(*producer), nil
+}
+
+// InTxn runs fn in a transaction of p, committing if it returns nil and
+// aborting otherwise.
+func InTxn(p TransactionalProducer, fn func() error) error {
+	if err := p.BeginTxn(); err != nil {
+		return fmt.Errorf("error beginning kafka transaction: %w", err)
+	}
+
+	if err := fn(); err != nil {
+		if abortErr := p.AbortTxn(); abortErr != nil {
+			return errors.Join(err, fmt.Errorf("error aborting kafka transaction: %w", abortErr))
+		}
+
+		return err
+	}
+
+	if err := p.CommitTxn(); err != nil {
+		// A failed commit leaves the transaction open; abort so the
+		// producer can start the next one.
+		if abortErr := p.AbortTxn(); abortErr != nil {
+			return errors.Join(fmt.Errorf("error committing kafka transaction: %w", err), abortErr)
+		}
+
+		return fmt.Errorf("error committing kafka transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (p *producer) BeginTxn() error {
+	return p.syncProducer.BeginTxn()
+}
+
+func (p *producer) CommitTxn() error {
+	return p.syncProducer.CommitTxn()
+}
+
+func (p *producer) AbortTxn() error {
+	return p.syncProducer.AbortTxn()
+}
+
 func (p *producer) ProduceMessage(ctx context.Context, topic string, message interface{}) error {
-	jsonMsg, err := json.Marshal(message)
+	return p.ProduceMessageWithHeaders(ctx, topic, "", message, nil)
+}
+
+func (p *producer) ProduceMessageWithHeaders(ctx context.Context, topic, key string, message interface{}, extra map[string]string) error {
+	value, err := EncodeMessage(message)
 	if err != nil {
 		return err
 	}
@@ -59,27 +263,110 @@ func (p *producer) ProduceMessage(ctx context.Context, topic string, message int
 		}
 	}
 
-	var headers []sarama.RecordHeader
+	msg := newProducerMessage(topic, key, value, carrier, extra)
+
+	partition, offset, err := p.syncProducer.SendMessage(msg)
+	if err != nil {
+		return fmt.Errorf("error sending message: %v", err)
+	}
+
+	log.Printf("✅ Message sent to topic %s (Partition: %d, Offset: %d)\n", topic, partition, offset)
+	return nil
+}
+
+// ProduceMessages sends messages to topic in as few requests as the
+// producer's batching allows. Messages that could not be encoded are not
+// sent; with some failed the error is a *BatchError naming them.
+func (p *producer) ProduceMessages(ctx context.Context, topic string, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	batchErr := &BatchError{Failed: make(map[int]error)}
+
+	batch := make([]*sarama.ProducerMessage, 0, len(messages))
+	for i, message := range messages {
+		value, err := EncodeMessage(message.Value)
+		if err != nil {
+			batchErr.Failed[i] = err
+			continue
+		}
+
+		msg := newProducerMessage(topic, message.Key, value, carrier, message.Headers)
+		msg.Metadata = i
+		batch = append(batch, msg)
+	}
+
+	if len(batch) == 0 {
+		return batchErr
+	}
+
+	if err := p.syncProducer.SendMessages(batch); err != nil {
+		var producerErrs sarama.ProducerErrors
+		if !errors.As(err, &producerErrs) {
+			return fmt.Errorf("error sending messages: %w", err)
+		}
+
+		for _, pe := range producerErrs {
+			batchErr.Failed[pe.Msg.Metadata.(int)] = pe.Err
+		}
+	}
+
+	for _, msg := range batch {
+		i := msg.Metadata.(int)
+		if _, ok := batchErr.Failed[i]; !ok {
+			messages[i].Partition, messages[i].Offset = msg.Partition, msg.Offset
+		}
+	}
+
+	if len(batchErr.Failed) > 0 {
+		return batchErr
+	}
+
+	return nil
+}
+
+func newProducerMessage(topic, key string, value []byte, carrier propagation.MapCarrier, extra map[string]string) *sarama.ProducerMessage {
+	headers := make([]sarama.RecordHeader, 0, len(carrier)+len(extra))
 	for k, v := range carrier {
 		headers = append(headers, sarama.RecordHeader{
 			Key:   []byte(k),
 			Value: []byte(v),
 		})
 	}
+	for k, v := range extra {
+		headers = append(headers, sarama.RecordHeader{
+			Key:   []byte(k),
+			Value: []byte(v),
+		})
+	}
 
 	msg := &sarama.ProducerMessage{
 		Topic:   topic,
-		Value:   sarama.StringEncoder(jsonMsg),
+		Value:   sarama.ByteEncoder(value),
 		Headers: headers,
 	}
+	if key != "" {
+		msg.Key = sarama.StringEncoder(key)
+	}
 
-	partition, offset, err := p.syncProducer.SendMessage(msg)
-	if err != nil {
-		return fmt.Errorf("error sending message: %v", err)
+	return msg
+}
+
+// EncodeMessage writes protobuf messages in their binary form, RawMessage as
+// is and everything else as JSON.
+func EncodeMessage(message interface{}) ([]byte, error) {
+	switch msg := message.(type) {
+	case proto.Message:
+		return proto.Marshal(msg)
+	case RawMessage:
+		return msg, nil
 	}
 
-	log.Printf("✅ Message sent to topic %s (Partition: %d, Offset: %d)\n", topic, partition, offset)
-	return nil
+	return json.Marshal(message)
 }
 
 func (p *producer) Close() error {
@@ -0,0 +1,130 @@
+package kafka
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+	_ "github.com/sakashimaa/go-pet-project/proto/events"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// FormatProtobuf encodes payloads with the schemas in proto/events and
+	// falls back to the envelope for events that have no schema yet.
+	FormatProtobuf MessageFormat = "protobuf"
+
+	ProtobufContentType = "application/x-protobuf"
+	// HeaderProtoMessage carries the fully-qualified message name of a
+	// protobuf payload.
+	HeaderProtoMessage = "proto-message"
+
+	protoEventsPackage = "events"
+)
+
+// EncodeProtobuf converts a JSON event payload into its protobuf message.
+// ok is false when the event has no schema in proto/events.
+func EncodeProtobuf(eventType string, payload []byte) (msg proto.Message, ok bool, err error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(protoEventsPackage + "." + eventType))
+	if err != nil {
+		return nil, false, nil
+	}
+
+	msg = mt.New().Interface()
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(payload, msg); err != nil {
+		return nil, false, fmt.Errorf("error encoding %s as protobuf: %w", eventType, err)
+	}
+
+	return msg, true, nil
+}
+
+// protobufToEnvelope decodes a protobuf payload into the in-house envelope,
+// taking the metadata the envelope normally carries from the headers.
+func protobufToEnvelope(headers map[string]string, value []byte) ([]byte, error) {
+	name := headers[HeaderProtoMessage]
+	if name == "" {
+		name = protoEventsPackage + "." + headers[eventmeta.HeaderEventType]
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("unknown protobuf message %q: %w", name, err)
+	}
+
+	msg := mt.New()
+	if err := proto.Unmarshal(value, msg.Interface()); err != nil {
+		return nil, fmt.Errorf("invalid protobuf payload: %w", err)
+	}
+
+	meta := eventmeta.FromHeaders(headers)
+
+	return json.Marshal(map[string]any{
+		"event":                       string(mt.Descriptor().Name()),
+		"payload":                     protoToMap(msg),
+		eventmeta.HeaderEventID:       meta.EventID,
+		eventmeta.HeaderOccurredAt:    meta.OccurredAt,
+		eventmeta.HeaderProducer:      meta.Producer,
+		eventmeta.HeaderCorrelationID: meta.CorrelationID,
+		eventmeta.HeaderCausationID:   meta.CausationID,
+		eventmeta.HeaderTenantID:      meta.TenantID,
+	})
+}
+
+// protoToMap mirrors the JSON the services produced before protobuf: proto
+// field names as keys, int64 as numbers and timestamps as RFC 3339 times.
+// protojson is not used here because it quotes 64-bit integers.
+func protoToMap(m protoreflect.Message) map[string]any {
+	out := make(map[string]any)
+
+	fields := m.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		out[string(fd.Name())] = protoValue(m, fd)
+	}
+
+	return out
+}
+
+func protoValue(m protoreflect.Message, fd protoreflect.FieldDescriptor) any {
+	if fd.IsList() {
+		list := m.Get(fd).List()
+		values := make([]any, list.Len())
+		for i := range values {
+			values[i] = protoScalar(fd, list.Get(i))
+		}
+		return values
+	}
+
+	if fd.Message() != nil && !m.Has(fd) {
+		return nil
+	}
+
+	return protoScalar(fd, m.Get(fd))
+}
+
+func protoScalar(fd protoreflect.FieldDescriptor, v protoreflect.Value) any {
+	switch fd.Kind() {
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		if ts, ok := v.Message().Interface().(*timestamppb.Timestamp); ok {
+			return ts.AsTime().Format(time.RFC3339Nano)
+		}
+		return protoToMap(v.Message())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	default:
+		return v.Interface()
+	}
+}
+
+func isProtobuf(headers map[string]string) bool {
+	return strings.HasPrefix(headers[HeaderContentType], ProtobufContentType)
+}
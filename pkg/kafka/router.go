@@ -0,0 +1,162 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
+	"go.uber.org/zap"
+)
+
+// ErrMalformedEvent is returned by a strict Router for a message whose
+// envelope or payload does not decode. It is permanent, so Retry does not
+// try the message again.
+var ErrMalformedEvent = errors.New("malformed event")
+
+// Route hands one event type, decoded, to its handler. Build it with On or
+// OnMessage.
+type Route struct {
+	event  string
+	handle func(ctx context.Context, msg *sarama.ConsumerMessage, payload json.RawMessage) error
+}
+
+// On routes event to handle with its payload decoded into a T.
+func On[T any](event string, handle func(ctx context.Context, event *T) error) Route {
+	return OnMessage(event, func(ctx context.Context, _ *sarama.ConsumerMessage, e *T) error {
+		return handle(ctx, e)
+	})
+}
+
+// OnMessage is On for handlers that also need the message itself, e.g. its
+// timestamp.
+func OnMessage[T any](event string, handle func(ctx context.Context, msg *sarama.ConsumerMessage, event *T) error) Route {
+	return Route{
+		event: event,
+		handle: func(ctx context.Context, msg *sarama.ConsumerMessage, payload json.RawMessage) error {
+			var e T
+			if err := json.Unmarshal(payload, &e); err != nil {
+				return fmt.Errorf("%w: %s payload: %v", ErrMalformedEvent, event, err)
+			}
+
+			return handle(ctx, msg, &e)
+		},
+	}
+}
+
+// Router dispatches envelope messages to the Route registered for their
+// event type. Its Route method is the HandlerFunc of a consumer.
+type Router struct {
+	routes map[string]Route
+	strict bool
+	logger *zap.Logger
+}
+
+type RouterOption func(*Router)
+
+// StrictPayloads fails messages that do not decode with ErrMalformedEvent,
+// leaving them to the consumer's retry and dead-letter handling. Without it
+// they are logged, counted and skipped.
+func StrictPayloads() RouterOption {
+	return func(r *Router) {
+		r.strict = true
+	}
+}
+
+func NewRouter(logger *zap.Logger, opts ...RouterOption) *Router {
+	r := &Router{
+		routes: make(map[string]Route),
+		logger: logger,
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
+}
+
+// Register adds routes. It panics on an event type that is already routed,
+// which is a programming error.
+func (r *Router) Register(routes ...Route) *Router {
+	for _, route := range routes {
+		if _, ok := r.routes[route.event]; ok {
+			panic(fmt.Sprintf("kafka: event %q is already routed", route.event))
+		}
+
+		r.routes[route.event] = route
+	}
+
+	return r
+}
+
+// Ignore drops events the service reads but has nothing to do with, such as
+// its own, without counting them as unknown.
+func (r *Router) Ignore(events ...string) *Router {
+	for _, event := range events {
+		r.Register(Route{event: event})
+	}
+
+	return r
+}
+
+// Route decodes the envelope of msg and calls the handler of its event
+// type. Unknown event types are logged, counted and skipped.
+func (r *Router) Route(ctx context.Context, msg *sarama.ConsumerMessage) error {
+	var envelope struct {
+		Event   string          `json:"event"`
+		Payload json.RawMessage `json:"payload"`
+	}
+
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		return r.malformed(ctx, msg, eventType(msg), fmt.Errorf("%w: envelope: %v", ErrMalformedEvent, err))
+	}
+
+	event := envelope.Event
+	if event == "" {
+		event = Header(msg, eventmeta.HeaderEventType)
+	}
+
+	route, ok := r.routes[event]
+	if !ok {
+		unknownEvents.WithLabelValues(msg.Topic, event).Inc()
+		mylogger.Info(ctx, r.logger, "Ignored event type", zap.String("topic", msg.Topic), zap.String("event_type", event))
+
+		return nil
+	}
+
+	if route.handle == nil {
+		return nil
+	}
+
+	err := route.handle(ctx, msg, envelope.Payload)
+	if errors.Is(err, ErrMalformedEvent) {
+		return r.malformed(ctx, msg, event, err)
+	}
+
+	return err
+}
+
+func (r *Router) malformed(ctx context.Context, msg *sarama.ConsumerMessage, event string, err error) error {
+	malformedEvents.WithLabelValues(msg.Topic, event).Inc()
+
+	if r.strict {
+		return retry.Permanent(err)
+	}
+
+	mylogger.Warn(
+		ctx,
+		r.logger,
+		"Skipping malformed event",
+		zap.String("topic", msg.Topic),
+		zap.Int64("offset", msg.Offset),
+		zap.String("event_type", event),
+		zap.Error(err),
+	)
+
+	return nil
+}
@@ -0,0 +1,155 @@
+// Package lock lets replicas of a service take named locks from each other,
+// backed by Postgres advisory locks or by Redis. A held lock renews itself
+// until it is released, and reports through Lost when renewal fails so the
+// holder can stop the work the lock guarded.
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+// ErrNotAcquired is returned by Acquire for a lock somebody else holds.
+var ErrNotAcquired = errors.New("lock is held by someone else")
+
+// errLost is returned by refresh once the lock is gone for good, as opposed
+// to a failed attempt that may still be retried.
+var errLost = errors.New("lock lost")
+
+type backend interface {
+	// acquire takes the lock or reports that it is taken; it never waits.
+	acquire(ctx context.Context, name string, ttl time.Duration) (held, bool, error)
+}
+
+type held interface {
+	// refresh extends the lock by ttl.
+	refresh(ctx context.Context, ttl time.Duration) error
+	release(ctx context.Context) error
+}
+
+// Locker hands out locks that replicas sharing its backend exclude each
+// other from.
+type Locker struct {
+	backend backend
+	logger  *zap.Logger
+}
+
+// Lock is a held lock. It is renewed every third of its TTL until Release.
+type Lock struct {
+	name   string
+	ttl    time.Duration
+	held   held
+	logger *zap.Logger
+
+	acquiredAt time.Time
+	lost       chan struct{}
+	stop       chan struct{}
+	done       chan struct{}
+	once       sync.Once
+}
+
+// Acquire takes the lock called name, or returns ErrNotAcquired if another
+// holder has it. ttl is how long the lock outlives a holder that stops
+// renewing it, and how soon the holder notices that it was lost.
+func (l *Locker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	if ttl <= 0 {
+		return nil, errors.New("lock ttl must be positive")
+	}
+
+	h, acquired, err := l.backend.acquire(ctx, name, ttl)
+	if err != nil {
+		acquires.WithLabelValues(name, resultError).Inc()
+		return nil, err
+	}
+	if !acquired {
+		acquires.WithLabelValues(name, resultBusy).Inc()
+		return nil, ErrNotAcquired
+	}
+
+	acquires.WithLabelValues(name, resultAcquired).Inc()
+	holding.WithLabelValues(name).Set(1)
+
+	lock := &Lock{
+		name:       name,
+		ttl:        ttl,
+		held:       h,
+		logger:     l.logger,
+		acquiredAt: time.Now(),
+		lost:       make(chan struct{}),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+
+	// Renewal outlives the caller's context; Release stops it.
+	go lock.renew(context.WithoutCancel(ctx))
+
+	return lock, nil
+}
+
+// Lost is closed when the lock could not be renewed and may now be held by
+// somebody else.
+func (l *Lock) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release stops renewal and gives the lock up. It is safe to call more than
+// once and after the lock was lost, and it runs even if ctx is cancelled.
+func (l *Lock) Release(ctx context.Context) {
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+
+		ctx := context.WithoutCancel(ctx)
+		if err := l.held.release(ctx); err != nil {
+			mylogger.Warn(ctx, l.logger, "Failed to release lock", zap.String("lock", l.name), zap.Error(err))
+		}
+
+		holding.WithLabelValues(l.name).Set(0)
+		holdDuration.WithLabelValues(l.name).Observe(time.Since(l.acquiredAt).Seconds())
+	})
+}
+
+// renew refreshes the lock until Release. A failed refresh is retried on the
+// next tick, until the lock has gone unrenewed for its whole TTL.
+func (l *Lock) renew(ctx context.Context) {
+	defer close(l.done)
+
+	interval := l.ttl / 3
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		refreshCtx, cancel := context.WithTimeout(ctx, interval)
+		err := l.held.refresh(refreshCtx, l.ttl)
+		cancel()
+
+		if err == nil {
+			renewed = time.Now()
+			continue
+		}
+
+		if !errors.Is(err, errLost) && time.Since(renewed)+interval < l.ttl {
+			mylogger.Warn(ctx, l.logger, "Failed to renew lock, retrying", zap.String("lock", l.name), zap.Error(err))
+			continue
+		}
+
+		losses.WithLabelValues(l.name).Inc()
+		holding.WithLabelValues(l.name).Set(0)
+		mylogger.Error(ctx, l.logger, "Lost lock", zap.String("lock", l.name), zap.Error(err))
+		close(l.lost)
+
+		return
+	}
+}
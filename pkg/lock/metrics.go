@@ -0,0 +1,42 @@
+package lock
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Results of an Acquire, the result label of lock_acquire_total.
+const (
+	resultAcquired = "acquired"
+	// resultBusy is a lock somebody else held.
+	resultBusy  = "busy"
+	resultError = "error"
+)
+
+var (
+	acquires = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lock_acquire_total",
+		Help: "Attempts to take a lock by result: acquired, busy because someone else held it, or error.",
+	}, []string{"lock", "result"})
+	holding = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "lock_held",
+		Help: "Whether this replica holds the lock right now.",
+	}, []string{"lock"})
+	holdDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "lock_hold_duration_seconds",
+		Help:    "How long this replica held a lock before releasing it.",
+		Buckets: []float64{0.01, 0.1, 1, 10, 60, 300, 900, 3600, 6 * 3600},
+	}, []string{"lock"})
+	losses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "lock_lost_total",
+		Help: "Locks this replica lost because it could not renew them.",
+	}, []string{"lock"})
+)
+
+// RegisterMetrics exposes the lock metrics on reg.
+func RegisterMetrics(reg prometheus.Registerer) error {
+	for _, c := range []prometheus.Collector{acquires, holding, holdDuration, losses} {
+		if err := reg.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
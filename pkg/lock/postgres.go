@@ -0,0 +1,97 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+)
+
+// NewPostgres takes session-level advisory locks among the replicas that
+// share pool's database. Each held lock keeps a connection of its own; if
+// the holder dies its session ends and Postgres drops the lock at once, so
+// the TTL only sets how often the holder checks that its session is alive.
+func NewPostgres(pool *pgxpool.Pool, logger *zap.Logger) *Locker {
+	return &Locker{backend: postgresBackend{pool: pool}, logger: logger}
+}
+
+type postgresBackend struct {
+	pool *pgxpool.Pool
+}
+
+type postgresLock struct {
+	conn *pgxpool.Conn
+	key  int64
+}
+
+func (b postgresBackend) acquire(ctx context.Context, name string, _ time.Duration) (held, bool, error) {
+	conn, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("error acquiring connection for lock: %w", err)
+	}
+
+	key := lockKey(name)
+
+	var acquired bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&acquired); err != nil {
+		conn.Release()
+		return nil, false, fmt.Errorf("error trying advisory lock: %w", err)
+	}
+
+	if !acquired {
+		conn.Release()
+		return nil, false, nil
+	}
+
+	return &postgresLock{conn: conn, key: key}, true, nil
+}
+
+func (l *postgresLock) refresh(ctx context.Context, _ time.Duration) error {
+	if l.conn == nil {
+		return errLost
+	}
+
+	if err := l.conn.Ping(ctx); err != nil {
+		// The session is gone and the lock with it; never hand this
+		// connection back to the pool.
+		l.close(ctx)
+		return fmt.Errorf("%w: %v", errLost, err)
+	}
+
+	return nil
+}
+
+func (l *postgresLock) release(ctx context.Context) error {
+	if l.conn == nil {
+		return nil
+	}
+
+	var released bool
+	err := l.conn.QueryRow(ctx, "SELECT pg_advisory_unlock($1)", l.key).Scan(&released)
+	if err != nil || !released {
+		// Closing the session releases the lock either way.
+		l.close(ctx)
+		return err
+	}
+
+	l.conn.Release()
+	l.conn = nil
+
+	return nil
+}
+
+func (l *postgresLock) close(ctx context.Context) {
+	_ = l.conn.Conn().Close(ctx)
+	l.conn.Release()
+	l.conn = nil
+}
+
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(name))
+
+	return int64(h.Sum64())
+}
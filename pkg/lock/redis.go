@@ -0,0 +1,83 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Only the holder's token may extend or delete the key, so a holder whose
+// lock expired cannot touch the lock of the replica that took it over.
+var (
+	refreshScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseScript = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+// NewRedis takes locks as Redis keys that expire after the TTL, for services
+// whose replicas share Redis rather than a database. A holder that dies
+// keeps the lock until its key expires.
+func NewRedis(client goredis.UniversalClient, logger *zap.Logger) *Locker {
+	return &Locker{backend: redisBackend{client: client}, logger: logger}
+}
+
+type redisBackend struct {
+	client goredis.UniversalClient
+}
+
+type redisLock struct {
+	client goredis.UniversalClient
+	key    string
+	token  string
+}
+
+func (b redisBackend) acquire(ctx context.Context, name string, ttl time.Duration) (held, bool, error) {
+	var buf [16]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, false, fmt.Errorf("error generating lock token: %w", err)
+	}
+
+	l := &redisLock{client: b.client, key: "lock:" + name, token: hex.EncodeToString(buf[:])}
+
+	acquired, err := b.client.SetNX(ctx, l.key, l.token, ttl).Result()
+	if err != nil {
+		return nil, false, fmt.Errorf("error trying redis lock: %w", err)
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	return l, true, nil
+}
+
+func (l *redisLock) refresh(ctx context.Context, ttl time.Duration) error {
+	extended, err := refreshScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("error renewing redis lock: %w", err)
+	}
+	if extended == 0 {
+		return errLost
+	}
+
+	return nil
+}
+
+func (l *redisLock) release(ctx context.Context) error {
+	if err := releaseScript.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("error releasing redis lock: %w", err)
+	}
+
+	return nil
+}
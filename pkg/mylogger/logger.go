@@ -7,54 +7,45 @@ import (
 	"go.uber.org/zap"
 )
 
-func Info(ctx context.Context, logger *zap.Logger, msg string, fields ...zap.Field) {
-	spanCtx := trace.SpanFromContext(ctx).SpanContext()
-
-	if spanCtx.IsValid() {
-		fields = append(fields,
-			zap.String("trace_id", spanCtx.TraceID().String()),
-			zap.String("span_id", spanCtx.SpanID().String()),
-		)
-	}
+// Field keys of the span a context carries. They are added with
+// zap.Logger.With so the core of config.NewLogger sees them before it
+// decides whether to sample an entry.
+const (
+	TraceIDKey      = "trace_id"
+	SpanIDKey       = "span_id"
+	TraceSampledKey = "trace_sampled"
+)
 
-	logger.WithOptions(zap.AddCallerSkip(1)).Info(msg, fields...)
+func Info(ctx context.Context, logger *zap.Logger, msg string, fields ...zap.Field) {
+	withTrace(ctx, logger).Info(msg, fields...)
 }
 
 func Error(ctx context.Context, logger *zap.Logger, msg string, fields ...zap.Field) {
-	spanCtx := trace.SpanFromContext(ctx).SpanContext()
-
-	if spanCtx.IsValid() {
-		fields = append(fields,
-			zap.String("trace_id", spanCtx.TraceID().String()),
-			zap.String("span_id", spanCtx.SpanID().String()),
-		)
-	}
-
-	logger.WithOptions(zap.AddCallerSkip(1)).Error(msg, fields...)
+	withTrace(ctx, logger).Error(msg, fields...)
 }
 
 func Warn(ctx context.Context, logger *zap.Logger, msg string, fields ...zap.Field) {
-	spanCtx := trace.SpanFromContext(ctx).SpanContext()
-
-	if spanCtx.IsValid() {
-		fields = append(fields,
-			zap.String("trace_id", spanCtx.TraceID().String()),
-			zap.String("span_id", spanCtx.SpanID().String()),
-		)
-	}
-
-	logger.WithOptions(zap.AddCallerSkip(1)).Warn(msg, fields...)
+	withTrace(ctx, logger).Warn(msg, fields...)
 }
 
 func Debug(ctx context.Context, logger *zap.Logger, msg string, fields ...zap.Field) {
-	spanCtx := trace.SpanFromContext(ctx).SpanContext()
+	withTrace(ctx, logger).Debug(msg, fields...)
+}
 
-	if spanCtx.IsValid() {
-		fields = append(fields,
-			zap.String("trace_id", spanCtx.TraceID().String()),
-			zap.String("span_id", spanCtx.SpanID().String()),
-		)
+// withTrace adds the span of ctx with With rather than as entry fields: the
+// core of NewLogger only sees With fields before it decides whether
+// to sample an entry, and keeps every entry of a sampled trace.
+func withTrace(ctx context.Context, logger *zap.Logger) *zap.Logger {
+	logger = logger.WithOptions(zap.AddCallerSkip(1))
+
+	spanCtx := trace.SpanFromContext(ctx).SpanContext()
+	if !spanCtx.IsValid() {
+		return logger
 	}
 
-	logger.WithOptions(zap.AddCallerSkip(1)).Debug(msg, fields...)
+	return logger.With(
+		zap.String(TraceIDKey, spanCtx.TraceID().String()),
+		zap.String(SpanIDKey, spanCtx.SpanID().String()),
+		zap.Bool(TraceSampledKey, spanCtx.IsSampled()),
+	)
 }
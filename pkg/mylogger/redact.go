@@ -0,0 +1,119 @@
+package mylogger
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// DefaultSensitiveKeys are the field keys masked when no others are
+// configured.
+var DefaultSensitiveKeys = []string{
+	"email",
+	"token",
+	"access_token",
+	"refresh_token",
+	"reset_token",
+	"jwt",
+	"authorization",
+	"password",
+	"secret",
+	"api_key",
+}
+
+// Mask hides value while keeping it useful for following one user or token
+// through the logs: an email keeps its first letter and domain, anything
+// else becomes a short hash that is the same wherever it is logged.
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	if at := strings.LastIndexByte(value, '@'); at > 0 {
+		return value[:1] + "***" + value[at:]
+	}
+
+	sum := sha256.Sum256([]byte(value))
+	return "sha256:" + hex.EncodeToString(sum[:8])
+}
+
+// Sensitive is zap.String with value masked, for secrets logged under a key
+// the logger does not know is sensitive.
+func Sensitive(key, value string) zap.Field {
+	return zap.String(key, Mask(value))
+}
+
+// WithRedaction masks the fields under keys, matched case-insensitively,
+// before any entry is written. Values that are not strings cannot be masked
+// and are replaced altogether.
+func WithRedaction(keys ...string) zap.Option {
+	return zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		return NewRedactCore(core, keys...)
+	})
+}
+
+// NewRedactCore is WithRedaction for building cores by hand. Wrap the cores
+// that write, not the samplers around them: a wrapping core's Check hands
+// entries straight to the cores it wraps.
+func NewRedactCore(core zapcore.Core, keys ...string) zapcore.Core {
+	sensitive := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		sensitive[strings.ToLower(key)] = struct{}{}
+	}
+
+	return &redactCore{Core: core, keys: sensitive}
+}
+
+type redactCore struct {
+	zapcore.Core
+	keys map[string]struct{}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redact(fields)), keys: c.keys}
+}
+
+func (c *redactCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+
+	return checked
+}
+
+func (c *redactCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, c.redact(fields))
+}
+
+// redact returns fields with the sensitive ones masked, copying only when
+// there is something to mask.
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	var out []zapcore.Field
+	for i, field := range fields {
+		if _, ok := c.keys[strings.ToLower(field.Key)]; !ok {
+			continue
+		}
+
+		if out == nil {
+			out = append([]zapcore.Field(nil), fields...)
+		}
+
+		switch field.Type {
+		case zapcore.StringType:
+			out[i] = zap.String(field.Key, Mask(field.String))
+		case zapcore.ByteStringType:
+			out[i] = zap.String(field.Key, Mask(string(field.Interface.([]byte))))
+		default:
+			out[i] = zap.String(field.Key, "[redacted]")
+		}
+	}
+
+	if out == nil {
+		return fields
+	}
+
+	return out
+}
@@ -0,0 +1,126 @@
+// Package objectstore keeps generated documents outside the database. The
+// S3 backend talks to any S3-compatible service (AWS, MinIO); the file
+// backend is meant for local runs and tests.
+package objectstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ilyakaznacheev/cleanenv"
+)
+
+var (
+	ErrNotFound      = errors.New("object not found")
+	ErrInvalidConfig = errors.New("invalid object store config")
+)
+
+type Store interface {
+	Put(ctx context.Context, key string, body []byte, contentType string) error
+	Get(ctx context.Context, key string) ([]byte, error)
+}
+
+// Backend selects where objects are written.
+type Backend string
+
+const (
+	BackendFile Backend = "file"
+	BackendS3   Backend = "s3"
+)
+
+type Config struct {
+	Backend Backend `yaml:"backend" env:"OBJECT_STORE_BACKEND" env-default:"file"`
+	// Dir is the root directory of the file backend.
+	Dir string `yaml:"dir" env:"OBJECT_STORE_DIR" env-default:"./data/objects"`
+	S3  S3     `yaml:"s3"`
+}
+
+type S3 struct {
+	// Endpoint is the base URL, e.g. https://s3.eu-central-1.amazonaws.com
+	// or http://localhost:9000 for MinIO. Buckets are addressed path-style.
+	Endpoint  string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region    string `yaml:"region" env:"S3_REGION" env-default:"us-east-1"`
+	Bucket    string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKey string `yaml:"access_key" env:"S3_ACCESS_KEY"`
+	SecretKey string `yaml:"secret_key" env:"S3_SECRET_KEY"`
+}
+
+// LoadFromEnv reads OBJECT_STORE_* and S3_* variables.
+func LoadFromEnv() (Config, error) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// New builds the store for the configured backend.
+func New(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case BackendFile:
+		return NewFileStore(cfg.Dir), nil
+	case BackendS3:
+		return NewS3Store(cfg.S3)
+	default:
+		return nil, fmt.Errorf("%w: unknown backend %q", ErrInvalidConfig, cfg.Backend)
+	}
+}
+
+type fileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) Store {
+	return &fileStore{dir: dir}
+}
+
+func (s *fileStore) Put(_ context.Context, key string, body []byte, _ string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// Write then rename, so readers never see a half-written object.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (s *fileStore) Get(_ context.Context, key string) ([]byte, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+
+	return body, nil
+}
+
+// path maps a key to a file below the root and refuses keys escaping it.
+func (s *fileStore) path(key string) (string, error) {
+	clean := filepath.Clean("/" + key)
+	if clean == "/" || strings.Contains(key, "..") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(clean)), nil
+}
@@ -0,0 +1,182 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type s3Store struct {
+	cfg    S3
+	client *http.Client
+}
+
+// NewS3Store signs requests with AWS Signature Version 4 using the standard
+// library only; nothing beyond PUT and GET of whole objects is needed.
+func NewS3Store(cfg S3) (Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" {
+		return nil, fmt.Errorf("%w: S3_ENDPOINT and S3_BUCKET are required", ErrInvalidConfig)
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("%w: S3_ACCESS_KEY and S3_SECRET_KEY are required", ErrInvalidConfig)
+	}
+	if _, err := url.Parse(cfg.Endpoint); err != nil {
+		return nil, fmt.Errorf("%w: bad S3_ENDPOINT: %v", ErrInvalidConfig, err)
+	}
+
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+
+	return &s3Store{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *s3Store) Put(ctx context.Context, key string, body []byte, contentType string) error {
+	res, err := s.do(ctx, http.MethodPut, key, body, contentType)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return s.unexpected(res)
+	}
+
+	return nil
+}
+
+func (s *s3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	res, err := s.do(ctx, http.MethodGet, key, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	switch res.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(res.Body)
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, s.unexpected(res)
+	}
+}
+
+func (s *s3Store) do(ctx context.Context, method, key string, body []byte, contentType string) (*http.Response, error) {
+	objectPath := escapePath("/" + s.cfg.Bucket + "/" + strings.TrimLeft(key, "/"))
+
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.Endpoint+objectPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build s3 request: %w", err)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	s.sign(req, objectPath, body, time.Now().UTC())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s failed: %w", method, key, err)
+	}
+
+	return res, nil
+}
+
+func (s *s3Store) unexpected(res *http.Response) error {
+	detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+	return fmt.Errorf("s3 returned %s: %s", res.Status, strings.TrimSpace(string(detail)))
+}
+
+func (s *s3Store) sign(req *http.Request, canonicalURI string, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	headerValues := map[string]string{
+		"host":                 req.URL.Host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	if ct := req.Header.Get("Content-Type"); ct != "" {
+		signedHeaders = []string{"content-type", "host", "x-amz-content-sha256", "x-amz-date"}
+		headerValues["content-type"] = ct
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range signedHeaders {
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(headerValues[h]) + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		"",
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + s.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), day)
+	key = hmacSHA256(key, s.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.cfg.AccessKey,
+		scope,
+		strings.Join(signedHeaders, ";"),
+		signature,
+	))
+}
+
+// escapePath encodes every byte outside the RFC 3986 unreserved set, which
+// is what SigV4 expects in the canonical URI. Slashes separate segments.
+func escapePath(p string) string {
+	var b strings.Builder
+	for i := 0; i < len(p); i++ {
+		c := p[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -5,6 +5,15 @@ import (
 	"time"
 )
 
+// Statuses of an outbox event. The worker polls pending events only.
+const (
+	StatusPending   = "pending"
+	StatusPublished = "published"
+	// StatusFailed events ran out of attempts and wait for a requeue.
+	StatusFailed     = "failed"
+	StatusSuperseded = "superseded"
+)
+
 type OutboxEvent struct {
 	Id            int64           `db:"id"`
 	AggregateType string          `db:"aggregate_type"`
@@ -15,6 +24,22 @@ type OutboxEvent struct {
 	CreatedAt     time.Time       `db:"created_at"`
 	PublishedAt   *time.Time      `db:"published_at"`
 	Attempts      int64           `db:"attempts"`
+	Status        string          `db:"status"`
 	LastError     *string         `db:"last_error"`
 	Topic         string          `db:"topic"`
+	EventID       string          `db:"event_id"`
+	OccurredAt    time.Time       `db:"occurred_at"`
+	Producer      string          `db:"producer"`
+	CorrelationID string          `db:"correlation_id"`
+	CausationID   string          `db:"causation_id"`
+	TenantID      string          `db:"tenant_id"`
+	// TraceID is the trace the event was saved in, empty outside one.
+	TraceID string `db:"trace_id"`
+	// KafkaPartition and KafkaOffset are where the event was published,
+	// nil until then.
+	KafkaPartition *int32 `db:"kafka_partition"`
+	KafkaOffset    *int64 `db:"kafka_offset"`
+	// SupersededBy is the later event this one was compacted into, never
+	// published itself.
+	SupersededBy *int64 `db:"superseded_by"`
 }
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ageQueryTimeout keeps a slow database from stalling the whole scrape.
+const ageQueryTimeout = 2 * time.Second
+
+var (
+	eventsExhausted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_events_exhausted_total",
+		Help: "Outbox events that failed MaxAttempts times and wait for a requeue.",
+	}, []string{"service", "topic"})
+	eventsSuperseded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_events_superseded_total",
+		Help: "Outbox events skipped because a later event of the same aggregate replaced them.",
+	}, []string{"service", "topic"})
+
+	oldestUnpublishedDesc = prometheus.NewDesc(
+		"outbox_oldest_unpublished_age_seconds",
+		"Age of the oldest outbox event still waiting to be published.",
+		[]string{"service", "topic"},
+		nil,
+	)
+	ageScrapeErrorsDesc = prometheus.NewDesc(
+		"outbox_age_scrape_errors",
+		"1 if the last read of the outbox age failed.",
+		[]string{"service"},
+		nil,
+	)
+)
+
+// RegisterMetrics exposes the outbox metrics of service on reg. The age is
+// read from pool on every scrape, so it keeps growing while the worker is
+// stuck, even if the worker itself no longer runs. Topics with nothing
+// waiting have no age series.
+func RegisterMetrics(reg prometheus.Registerer, pool *pgxpool.Pool, service string) error {
+	if err := reg.Register(eventsExhausted); err != nil {
+		return err
+	}
+	if err := reg.Register(eventsSuperseded); err != nil {
+		return err
+	}
+
+	return reg.Register(&ageCollector{pool: pool, service: service})
+}
+
+type ageCollector struct {
+	pool    *pgxpool.Pool
+	service string
+}
+
+func (c *ageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- oldestUnpublishedDesc
+	ch <- ageScrapeErrorsDesc
+}
+
+// Collect leaves out events that ran out of attempts: they are counted by
+// outbox_events_exhausted_total and would otherwise keep the age high until
+// someone requeues them.
+func (c *ageCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), ageQueryTimeout)
+	defer cancel()
+
+	query := `
+		SELECT topic, EXTRACT(EPOCH FROM NOW() - MIN(created_at))::float8
+		FROM outbox
+		WHERE status = 'pending'
+		GROUP BY topic
+	`
+
+	failed := 0.0
+	defer func() {
+		ch <- prometheus.MustNewConstMetric(ageScrapeErrorsDesc, prometheus.GaugeValue, failed, c.service)
+	}()
+
+	rows, err := c.pool.Query(ctx, query)
+	if err != nil {
+		failed = 1
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			topic string
+			age   float64
+		)
+		if err := rows.Scan(&topic, &age); err != nil {
+			failed = 1
+			return
+		}
+
+		ch <- prometheus.MustNewConstMetric(oldestUnpublishedDesc, prometheus.GaugeValue, age, c.service, topic)
+	}
+
+	if rows.Err() != nil {
+		failed = 1
+	}
+}
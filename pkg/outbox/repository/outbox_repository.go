@@ -2,12 +2,17 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
 	"github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -15,16 +20,20 @@ import (
 )
 
 type outboxRepo struct {
-	pool   *pgxpool.Pool
-	tracer trace.Tracer
-	logger *zap.Logger
+	pool     *pgxpool.Pool
+	tracer   trace.Tracer
+	logger   *zap.Logger
+	producer string
 }
 
-func NewOutboxRepository(pool *pgxpool.Pool, logger *zap.Logger) worker.OutboxRepository {
+// NewOutboxRepository creates an outbox repository. producer names the
+// owning service and is stamped on every saved event.
+func NewOutboxRepository(pool *pgxpool.Pool, logger *zap.Logger, producer string) worker.OutboxRepository {
 	return &outboxRepo{
-		pool:   pool,
-		tracer: otel.Tracer("contract/outbox_repo"),
-		logger: logger,
+		pool:     pool,
+		tracer:   otel.Tracer("contract/outbox_repo"),
+		logger:   logger,
+		producer: producer,
 	}
 }
 
@@ -41,33 +50,55 @@ func (r *outboxRepo) MarkEventFailed(ctx context.Context, tx pgx.Tx, eventID int
 		UPDATE outbox
 		SET published_at = NULL,
 			last_error = $1,
-			attempts = attempts + 1
-		WHERE id = $2;
+			attempts = attempts + 1,
+			status = CASE WHEN attempts + 1 >= $3 THEN 'failed' ELSE 'pending' END
+		WHERE id = $2
+		RETURNING attempts, topic;
 	`
 
-	_, err := tx.Exec(ctx, query, errMsg, eventID)
+	var (
+		attempts int
+		topic    string
+	)
+	err := tx.QueryRow(ctx, query, errMsg, eventID, MaxAttempts).Scan(&attempts, &topic)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
 	if err != nil {
 		span.RecordError(err)
+		return err
 	}
 
-	return err
+	// Counted before the batch commits, so a batch that is rolled back and
+	// retried can count an event twice. Alerts only care that it moved.
+	if attempts == MaxAttempts {
+		eventsExhausted.WithLabelValues(r.producer, topic).Inc()
+	}
+
+	return nil
 }
 
-func (r *outboxRepo) MarkEventPublished(ctx context.Context, tx pgx.Tx, eventID int64) error {
+// MarkEventPublished records that the event was written to partition at
+// offset; an offset of -1 leaves where unknown.
+func (r *outboxRepo) MarkEventPublished(ctx context.Context, tx pgx.Tx, eventID int64, partition int32, offset int64) error {
 	ctx, span := r.tracer.Start(ctx, "OutboxRepository.MarkEventPublished")
 	defer span.End()
 
 	span.SetAttributes(
 		attribute.Int64("event_id", eventID),
+		attribute.Int64("kafka_partition", int64(partition)),
+		attribute.Int64("kafka_offset", offset),
 	)
 
 	query := `
 		UPDATE outbox
-		SET published_at = NOW(), last_error = NULL
+		SET published_at = NOW(), last_error = NULL, status = 'published',
+			kafka_partition = CASE WHEN $3::bigint >= 0 THEN $2::int END,
+			kafka_offset = NULLIF($3::bigint, -1)
 		WHERE id = $1;
 	`
 
-	_, err := tx.Exec(ctx, query, eventID)
+	_, err := tx.Exec(ctx, query, eventID, partition, offset)
 
 	if err != nil {
 		span.RecordError(err)
@@ -82,7 +113,7 @@ func (r *outboxRepo) MarkEventUnpublished(ctx context.Context, tx pgx.Tx, eventI
 
 	query := `
 		UPDATE outbox
-		SET published_at = NULL, last_error = NULL
+		SET published_at = NULL, last_error = NULL, status = 'pending'
 		WHERE id = $1
 	`
 
@@ -99,14 +130,41 @@ func (r *outboxRepo) SaveOutboxEvent(ctx context.Context, tx pgx.Tx, event *doma
 	ctx, span := r.tracer.Start(ctx, "OutboxRepository.SaveOutboxEvent")
 	defer span.End()
 
+	if event.EventID == "" {
+		event.EventID = uuid.NewString()
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+	if event.Producer == "" {
+		event.Producer = r.producer
+	}
+	if event.CorrelationID == "" {
+		event.CorrelationID, event.CausationID = eventmeta.Chain(ctx, event.EventID)
+	}
+	if event.TenantID == "" {
+		event.TenantID = tenant.FromContext(ctx)
+	}
+	if event.TraceID == "" {
+		if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+			event.TraceID = sc.TraceID().String()
+		}
+	}
+
 	span.SetAttributes(
 		attribute.String("aggregate_id", event.AggregateID),
 		attribute.String("aggregate_type", event.AggregateType),
+		attribute.String("event_id", event.EventID),
+		attribute.String("correlation_id", event.CorrelationID),
+		attribute.String("tenant_id", event.TenantID),
 	)
 
 	query := `
-		INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, topic)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO outbox (
+			aggregate_type, aggregate_id, event_type, payload, topic,
+			event_id, occurred_at, producer, correlation_id, causation_id, tenant_id, trace_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, '')::uuid, $11, NULLIF($12, ''))
 	`
 
 	_, err := tx.Exec(
@@ -117,6 +175,13 @@ func (r *outboxRepo) SaveOutboxEvent(ctx context.Context, tx pgx.Tx, event *doma
 		event.EventType,
 		event.Payload,
 		event.Topic,
+		event.EventID,
+		event.OccurredAt,
+		event.Producer,
+		event.CorrelationID,
+		event.CausationID,
+		event.TenantID,
+		event.TraceID,
 	)
 
 	if err != nil {
@@ -126,6 +191,77 @@ func (r *outboxRepo) SaveOutboxEvent(ctx context.Context, tx pgx.Tx, event *doma
 	return err
 }
 
+func (r *outboxRepo) SupersedeEvents(ctx context.Context, tx pgx.Tx, eventIDs []int64) ([]int64, error) {
+	ctx, span := r.tracer.Start(ctx, "OutboxRepository.SupersedeEvents")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("candidate_count", len(eventIDs)),
+	)
+
+	// The replacement must still be publishable: an event that ran out of
+	// attempts would leave the aggregate with nothing published at all.
+	query := `
+		WITH latest AS (
+			SELECT o.id, (
+				SELECT n.id
+				FROM outbox n
+				WHERE n.aggregate_type = o.aggregate_type
+					AND n.aggregate_id = o.aggregate_id
+					AND n.event_type = o.event_type
+					AND n.topic = o.topic
+					AND n.tenant_id = o.tenant_id
+					AND n.id > o.id
+					AND n.status = 'pending'
+				ORDER BY n.id DESC
+				LIMIT 1
+			) AS newer_id
+			FROM outbox o
+			WHERE o.id = ANY($1)
+		)
+		UPDATE outbox o
+		SET superseded_by = latest.newer_id,
+			published_at = NOW(),
+			last_error = NULL,
+			status = 'superseded'
+		FROM latest
+		WHERE o.id = latest.id AND latest.newer_id IS NOT NULL
+		RETURNING o.id, o.topic
+	`
+
+	rows, err := tx.Query(ctx, query, eventIDs)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to supersede outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var superseded []int64
+	for rows.Next() {
+		var (
+			id    int64
+			topic string
+		)
+		if err := rows.Scan(&id, &topic); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("error scanning superseded event: %w", err)
+		}
+
+		superseded = append(superseded, id)
+		eventsSuperseded.WithLabelValues(r.producer, topic).Inc()
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to supersede outbox events: %w", err)
+	}
+
+	span.SetAttributes(
+		attribute.Int("superseded_count", len(superseded)),
+	)
+
+	return superseded, nil
+}
+
 func (r *outboxRepo) GetUnpublishedEvents(ctx context.Context, tx pgx.Tx, batchSize int) ([]*domain.OutboxEvent, error) {
 	ctx, span := r.tracer.Start(ctx, "OutboxRepository.GetUnpublishedEvents")
 	defer span.End()
@@ -135,10 +271,12 @@ func (r *outboxRepo) GetUnpublishedEvents(ctx context.Context, tx pgx.Tx, batchS
 	)
 
 	query := `
-		SELECT id, aggregate_type, aggregate_id, event_type, payload, headers, created_at, topic
+		SELECT id, aggregate_type, aggregate_id, event_type, payload, headers, created_at, topic,
+			event_id::text, occurred_at, producer, COALESCE(correlation_id::text, ''), COALESCE(causation_id::text, ''),
+			tenant_id
 		FROM outbox
-		WHERE published_at IS NULL AND attempts < 10
-		ORDER BY created_at ASC
+		WHERE status = 'pending'
+		ORDER BY created_at ASC, id ASC
 		LIMIT $1
 		FOR UPDATE SKIP LOCKED
 	`
@@ -167,6 +305,12 @@ func (r *outboxRepo) GetUnpublishedEvents(ctx context.Context, tx pgx.Tx, batchS
 			&e.Headers,
 			&e.CreatedAt,
 			&e.Topic,
+			&e.EventID,
+			&e.OccurredAt,
+			&e.Producer,
+			&e.CorrelationID,
+			&e.CausationID,
+			&e.TenantID,
 		); err != nil {
 			span.RecordError(err)
 
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MaxAttempts is how many times the worker tries an event before leaving it
+// for an operator to requeue.
+const MaxAttempts = 10
+
+// RequeueFilter selects the outbox events to hand back to the worker. Zero
+// fields do not filter.
+type RequeueFilter struct {
+	IDs       []int64
+	EventType string
+	Since     time.Time
+	// IncludePublished also requeues events that were already published, to
+	// replay them to consumers. Superseded events were never published and
+	// stay skipped.
+	IncludePublished bool
+}
+
+// Requeue resets the matching events that ran out of attempts, and published
+// ones if asked to, so the worker picks them up again. It returns the number
+// of events requeued.
+func Requeue(ctx context.Context, pool *pgxpool.Pool, filter RequeueFilter) (int64, error) {
+	// A nil slice is sent as NULL, which cardinality() does not treat as empty.
+	ids := filter.IDs
+	if ids == nil {
+		ids = []int64{}
+	}
+
+	var since *time.Time
+	if !filter.Since.IsZero() {
+		since = &filter.Since
+	}
+
+	query := `
+		UPDATE outbox
+		SET published_at = NULL,
+			attempts = 0,
+			last_error = NULL,
+			status = 'pending'
+		WHERE (cardinality($1::bigint[]) = 0 OR id = ANY($1))
+			AND ($2 = '' OR event_type = $2)
+			AND ($3::timestamp IS NULL OR created_at >= $3)
+			AND (
+				status = 'failed'
+				OR ($4 AND status = 'published')
+			)
+	`
+
+	tag, err := pool.Exec(ctx, query, ids, filter.EventType, since, filter.IncludePublished)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue outbox events: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
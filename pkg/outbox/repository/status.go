@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TopicBacklog is what one topic has waiting in the outbox.
+type TopicBacklog struct {
+	Topic   string
+	Pending int64
+	Failed  int64
+	// OldestPending is zero when nothing is pending.
+	OldestPending time.Duration
+}
+
+// FailedEvent is an event that ran out of attempts.
+type FailedEvent struct {
+	ID          int64
+	Topic       string
+	EventType   string
+	AggregateID string
+	Attempts    int64
+	LastError   string
+	CreatedAt   time.Time
+}
+
+// Backlog summarises the outbox per topic, with the newest failedLimit
+// failed events for a closer look. Topics with nothing pending or failed are
+// left out.
+func Backlog(ctx context.Context, pool *pgxpool.Pool, failedLimit int) ([]TopicBacklog, []FailedEvent, error) {
+	query := `
+		SELECT topic,
+			COUNT(*) FILTER (WHERE status = 'pending'),
+			COUNT(*) FILTER (WHERE status = 'failed'),
+			COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(created_at) FILTER (WHERE status = 'pending')), 0)::float8
+		FROM outbox
+		WHERE status IN ('pending', 'failed')
+		GROUP BY topic
+		ORDER BY topic
+	`
+
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read outbox backlog: %w", err)
+	}
+	defer rows.Close()
+
+	var topics []TopicBacklog
+	for rows.Next() {
+		var (
+			topic TopicBacklog
+			age   float64
+		)
+		if err := rows.Scan(&topic.Topic, &topic.Pending, &topic.Failed, &age); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan outbox backlog: %w", err)
+		}
+		topic.OldestPending = time.Duration(age * float64(time.Second))
+		topics = append(topics, topic)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read outbox backlog: %w", err)
+	}
+
+	failedQuery := `
+		SELECT id, topic, event_type, aggregate_id, attempts, COALESCE(last_error, ''), created_at
+		FROM outbox
+		WHERE status = 'failed'
+		ORDER BY created_at DESC, id DESC
+		LIMIT $1
+	`
+
+	rows, err = pool.Query(ctx, failedQuery, failedLimit)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read failed outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var failed []FailedEvent
+	for rows.Next() {
+		var event FailedEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.Topic,
+			&event.EventType,
+			&event.AggregateID,
+			&event.Attempts,
+			&event.LastError,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan failed outbox event: %w", err)
+		}
+		failed = append(failed, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read failed outbox events: %w", err)
+	}
+
+	return topics, failed, nil
+}
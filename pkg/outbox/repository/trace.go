@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
+)
+
+// Trace returns up to limit events of one flow, oldest first: those saved
+// for the aggregate and those sharing one of correlationIDs. Either filter
+// may be empty.
+func Trace(ctx context.Context, pool *pgxpool.Pool, aggregateType, aggregateID string, correlationIDs []string, limit int) ([]domain.OutboxEvent, error) {
+	query := `
+		SELECT id, aggregate_type, aggregate_id, event_type, topic, status, attempts, last_error,
+			created_at, published_at, event_id::text, producer,
+			COALESCE(correlation_id::text, ''), COALESCE(causation_id::text, ''), tenant_id,
+			COALESCE(trace_id, ''), kafka_partition, kafka_offset
+		FROM outbox
+		WHERE (aggregate_id <> '' AND aggregate_type = $1 AND aggregate_id = $2)
+			OR correlation_id = ANY($3::uuid[])
+		ORDER BY created_at, id
+		LIMIT $4
+	`
+
+	rows, err := pool.Query(ctx, query, aggregateType, aggregateID, correlationIDs, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trace outbox events: %w", err)
+	}
+
+	events, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.OutboxEvent, error) {
+		var e domain.OutboxEvent
+		err := row.Scan(
+			&e.Id,
+			&e.AggregateType,
+			&e.AggregateID,
+			&e.EventType,
+			&e.Topic,
+			&e.Status,
+			&e.Attempts,
+			&e.LastError,
+			&e.CreatedAt,
+			&e.PublishedAt,
+			&e.EventID,
+			&e.Producer,
+			&e.CorrelationID,
+			&e.CausationID,
+			&e.TenantID,
+			&e.TraceID,
+			&e.KafkaPartition,
+			&e.KafkaOffset,
+		)
+
+		return e, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan traced outbox event: %w", err)
+	}
+
+	return events, nil
+}
@@ -9,11 +9,25 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
+// errAlreadyProcessed rolls back the transaction whose insert hit the
+// unique key; the event itself counts as handled.
+var errAlreadyProcessed = errors.New("event already processed")
+
+// actionRetry gives a failing action three tries, half a second apart,
+// before the message goes back to the consumer.
+var actionRetry = retry.Policy{
+	MaxAttempts: 3,
+	Initial:     500 * time.Millisecond,
+	Multiplier:  1,
+}
+
 func ProcessWithDeduplication(
 	ctx context.Context,
 	pool *pgxpool.Pool,
@@ -23,79 +37,48 @@ func ProcessWithDeduplication(
 ) error {
 	span := trace.SpanFromContext(ctx)
 
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return err
-	}
-
-	defer func() {
-		shutdownCtx := context.WithoutCancel(ctx)
-
-		err = tx.Rollback(shutdownCtx)
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			mylogger.Error(
-				shutdownCtx,
-				logger,
-				"Error rolling back transaction",
-				zap.Error(err),
-			)
+	err := db.WithTx(ctx, pool, func(tx pgx.Tx) error {
+		query := `
+			INSERT INTO processed_events (event_id)
+			VALUES ($1)
+		`
+
+		_, err := tx.Exec(ctx, query, eventID)
+		if err != nil {
+			var pgError *pgconn.PgError
+			if errors.As(err, &pgError) && pgError.Code == "23505" {
+				mylogger.Info(
+					ctx,
+					logger,
+					"Event already processed, skipping",
+					zap.Int64("event_id", eventID),
+					zap.Error(err),
+				)
+
+				return errAlreadyProcessed
+			}
+
+			span.RecordError(err)
+			return err
 		}
-	}()
-
-	query := `
-		INSERT INTO processed_events (event_id)
-		VALUES ($1)
-	`
 
-	_, err = tx.Exec(ctx, query, eventID)
-	if err != nil {
-		var pgError *pgconn.PgError
-		if errors.As(err, &pgError) && pgError.Code == "23505" {
-			mylogger.Info(
-				ctx,
-				logger,
-				"Event already processed, skipping",
-				zap.Int64("event_id", eventID),
-				zap.Error(err),
-			)
+		err = retry.Do(ctx, actionRetry, func(context.Context) error {
+			return action()
+		})
+		if err != nil {
+			mylogger.Error(ctx, logger, "Failed to sent after retries", zap.Error(err))
 
-			return nil
+			return fmt.Errorf("failed to sent: %w", err)
 		}
 
-		span.RecordError(err)
-		return err
+		return nil
+	})
+	if errors.Is(err, errAlreadyProcessed) {
+		return nil
 	}
-
-	sent := false
-	for i := 0; i < 3; i++ {
-		err = action()
-		if err == nil {
-			sent = true
-			break
-		}
-
-		if i < 2 {
-			time.Sleep(500 * time.Millisecond)
-		}
-	}
-
-	if !sent {
-		mylogger.Error(ctx, logger, "Failed to sent after retries", zap.Error(err))
-
-		return fmt.Errorf("failed to sent: %w", err)
-	}
-
-	if err := tx.Commit(ctx); err != nil {
+	if err != nil {
 		span.RecordError(err)
-
-		mylogger.Error(
-			ctx,
-			logger,
-			"Failed to commit transaction",
-			zap.Error(err),
-		)
-
-		return fmt.Errorf("failed to sent: %w", err)
+		return err
 	}
 
 	return nil
@@ -0,0 +1,93 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/lock"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+// leaderTTL is how soon a leader notices it lost its lock, and with Redis
+// how long a dead leader blocks failover.
+const leaderTTL = 15 * time.Second
+
+// LeaderElector decides which replica publishes the outbox.
+type LeaderElector interface {
+	// Lead reports whether this replica leads right now, taking leadership
+	// when nobody holds it.
+	Lead(ctx context.Context) (bool, error)
+	// Resign gives leadership up so another replica can take over at once.
+	Resign(ctx context.Context)
+}
+
+// LockElector elects as leader the replica holding the outbox lock of name.
+// If the leader dies its lock goes with it and the next replica to call
+// Lead takes over, so failover takes at most one polling interval after
+// the lock is gone.
+type LockElector struct {
+	locker *lock.Locker
+	name   string
+	logger *zap.Logger
+
+	mu   sync.Mutex
+	held *lock.Lock
+}
+
+// NewLockElector elects among the replicas that share locker's backend and
+// name, e.g. "product-service".
+func NewLockElector(locker *lock.Locker, name string, logger *zap.Logger) *LockElector {
+	return &LockElector{
+		locker: locker,
+		name:   name,
+		logger: logger,
+	}
+}
+
+func (e *LockElector) Lead(ctx context.Context) (bool, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.held != nil {
+		select {
+		case <-e.held.Lost():
+			mylogger.Warn(ctx, e.logger, "Lost outbox leadership", zap.String("name", e.name))
+
+			e.held.Release(ctx)
+			e.held = nil
+		default:
+			return true, nil
+		}
+	}
+
+	held, err := e.locker.Acquire(ctx, "outbox:"+e.name, leaderTTL)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("error trying outbox leader lock: %w", err)
+	}
+
+	e.held = held
+	mylogger.Info(ctx, e.logger, "Became outbox leader", zap.String("name", e.name))
+
+	return true, nil
+}
+
+func (e *LockElector) Resign(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.held == nil {
+		return
+	}
+
+	e.held.Release(ctx)
+	e.held = nil
+
+	mylogger.Info(ctx, e.logger, "Resigned outbox leadership", zap.String("name", e.name))
+}
@@ -4,28 +4,39 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+	"github.com/sakashimaa/go-pet-project/pkg/kafka"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	"github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 )
 
 type OutboxRepository interface {
 	SaveOutboxEvent(ctx context.Context, tx pgx.Tx, event *domain.OutboxEvent) error
 	GetUnpublishedEvents(ctx context.Context, tx pgx.Tx, batchSize int) ([]*domain.OutboxEvent, error)
 	MarkEventUnpublished(ctx context.Context, tx pgx.Tx, eventID int64) error
-	MarkEventPublished(ctx context.Context, tx pgx.Tx, eventID int64) error
+	MarkEventPublished(ctx context.Context, tx pgx.Tx, eventID int64, partition int32, offset int64) error
 	MarkEventFailed(ctx context.Context, tx pgx.Tx, eventID int64, error string) error
+	// SupersedeEvents marks those of eventIDs that have a later unpublished
+	// event of the same type for the same aggregate as superseded by it, and
+	// returns the ids it marked.
+	SupersedeEvents(ctx context.Context, tx pgx.Tx, eventIDs []int64) ([]int64, error)
 }
 
 type KafkaProducer interface {
-	ProduceMessage(ctx context.Context, topic string, message interface{}) error
+	ProduceMessages(ctx context.Context, topic string, messages []kafka.Message) error
 }
 
 type OutboxProcessor struct {
@@ -34,8 +45,62 @@ type OutboxProcessor struct {
 	kafkaProducer KafkaProducer
 	logger        *zap.Logger
 	batchSize     int
-	interval      time.Duration
-	tracer        trace.Tracer
+	interval      atomic.Int64
+	// intervalChanged wakes Start to reset its ticker after SetInterval.
+	intervalChanged chan struct{}
+	format          kafka.MessageFormat
+	leader          LeaderElector
+	keyring         *kafka.Keyring
+	encrypted       []string
+	retry           retry.Policy
+	compacted       []string
+	tracer          trace.Tracer
+}
+
+type Option func(*OutboxProcessor)
+
+// WithMessageFormat selects the wire format of published events. The
+// default is the in-house envelope.
+func WithMessageFormat(format kafka.MessageFormat) Option {
+	return func(p *OutboxProcessor) {
+		p.format = format
+	}
+}
+
+// WithLeaderElection makes only the elected replica publish. Without it every
+// replica polls and SKIP LOCKED keeps them from publishing the same row.
+func WithLeaderElection(elector LeaderElector) Option {
+	return func(p *OutboxProcessor) {
+		p.leader = elector
+	}
+}
+
+// WithEncryption encrypts the payload of events bound for topics, e.g. the
+// ones carrying emails or tokens. Consumers decrypt with kafka.WithDecryption.
+func WithEncryption(keyring *kafka.Keyring, topics []string) Option {
+	return func(p *OutboxProcessor) {
+		p.keyring = keyring
+		p.encrypted = topics
+	}
+}
+
+// WithCompaction publishes only the latest pending event of eventTypes per
+// aggregate; earlier ones are marked superseded instead. Use it for events
+// that carry the aggregate's whole state, e.g. ProductUpdated after a bulk
+// import, never for ones consumers must see one by one.
+func WithCompaction(eventTypes ...string) Option {
+	return func(p *OutboxProcessor) {
+		p.compacted = eventTypes
+	}
+}
+
+// WithPublishRetry changes how events that failed to publish for a transient
+// reason are resent within a batch before they count as a failed attempt.
+// The policy's classifier picks the errors to resend; nil resends all.
+func WithPublishRetry(policy retry.Policy) Option {
+	return func(p *OutboxProcessor) {
+		p.retry = policy
+	}
 }
 
 func NewOutboxProcessor(
@@ -43,15 +108,44 @@ func NewOutboxProcessor(
 	repo OutboxRepository,
 	producer KafkaProducer,
 	logger *zap.Logger,
+	opts ...Option,
 ) *OutboxProcessor {
-	return &OutboxProcessor{
-		pool:          pool,
-		repo:          repo,
-		kafkaProducer: producer,
-		logger:        logger,
-		batchSize:     50,
-		interval:      time.Second,
-		tracer:        otel.Tracer("outbox-worker"),
+	p := &OutboxProcessor{
+		pool:            pool,
+		repo:            repo,
+		kafkaProducer:   producer,
+		logger:          logger,
+		batchSize:       50,
+		intervalChanged: make(chan struct{}, 1),
+		format:          kafka.FormatEnvelope,
+		retry: retry.Policy{
+			MaxAttempts: 3,
+			Initial:     200 * time.Millisecond,
+			Multiplier:  2,
+			Jitter:      0.2,
+			Retryable:   kafka.IsRetryable,
+		},
+		tracer: otel.Tracer("outbox-worker"),
+	}
+	p.interval.Store(int64(time.Second))
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// SetInterval changes how often the outbox is polled. A running processor
+// picks it up on its next tick; non-positive values are ignored.
+func (p *OutboxProcessor) SetInterval(interval time.Duration) {
+	if interval <= 0 || p.interval.Swap(int64(interval)) == int64(interval) {
+		return
+	}
+
+	select {
+	case p.intervalChanged <- struct{}{}:
+	default:
 	}
 }
 
@@ -62,11 +156,16 @@ func (p *OutboxProcessor) Start(ctx context.Context) {
 		"Starting outbox processor",
 	)
 
-	ticker := time.NewTicker(p.interval)
+	ticker := time.NewTicker(time.Duration(p.interval.Load()))
 	defer ticker.Stop()
 
 	for {
 		select {
+		case <-p.intervalChanged:
+			interval := time.Duration(p.interval.Load())
+			ticker.Reset(interval)
+
+			mylogger.Info(ctx, p.logger, "Outbox poll interval changed", zap.Duration("interval", interval))
 		case <-ctx.Done():
 			mylogger.Info(
 				ctx,
@@ -74,9 +173,25 @@ func (p *OutboxProcessor) Start(ctx context.Context) {
 				"Outbox processor stopping",
 			)
 
+			if p.leader != nil {
+				resignCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Second)
+				p.leader.Resign(resignCtx)
+				cancel()
+			}
+
 			return
 		case <-ticker.C:
-			if err := p.processBatch(ctx); err != nil {
+			if p.leader != nil {
+				leading, err := p.leader.Lead(ctx)
+				if err != nil {
+					mylogger.Warn(ctx, p.logger, "Outbox leader election failed", zap.Error(err))
+				}
+				if !leading {
+					continue
+				}
+			}
+
+			if err := p.ProcessBatch(ctx); err != nil {
 				mylogger.Error(
 					ctx,
 					p.logger,
@@ -88,112 +203,334 @@ func (p *OutboxProcessor) Start(ctx context.Context) {
 	}
 }
 
-func (p *OutboxProcessor) processBatch(ctx context.Context) error {
+// ProcessBatch publishes up to one batch of pending events. Start calls it
+// on every tick; benchmarks call it directly.
+func (p *OutboxProcessor) ProcessBatch(ctx context.Context) error {
 	ctx, span := p.tracer.Start(ctx, "OutboxProcessor.processBatch")
 	defer span.End()
 
-	tx, err := p.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Error(
+	return db.WithTx(ctx, p.pool, func(tx pgx.Tx) error {
+		events, err := p.repo.GetUnpublishedEvents(ctx, tx, p.batchSize)
+		if err != nil {
+			return err
+		}
+
+		events, err = p.compact(ctx, tx, events)
+		if err != nil {
+			return err
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+
+		mylogger.Info(
 			ctx,
 			p.logger,
-			"outbox worker failed to begin transaction",
-			zap.Error(err),
+			"Processing outbox events",
+			zap.Int("count", len(events)),
 		)
 
-		return fmt.Errorf("error beginning transaction: %w", err)
+		// Events go out in one batch per topic, topics in the order they first
+		// appear, so a busy outbox costs a round trip per topic, not per event.
+		var topics []string
+		batches := make(map[string][]outgoing)
+
+		for _, event := range events {
+			var payloadMap map[string]any
+			if err := json.Unmarshal(event.Payload, &payloadMap); err != nil {
+				mylogger.Error(
+					ctx,
+					p.logger,
+					"outbox worker unmarshal event payload failed",
+					zap.Int64("id", event.Id),
+					zap.Error(err),
+				)
+
+				_ = p.repo.MarkEventFailed(ctx, tx, event.Id, err.Error())
+				continue
+			}
+
+			message, headers := p.buildMessage(ctx, event, payloadMap)
+
+			if p.keyring != nil && slices.Contains(p.encrypted, event.Topic) {
+				message, err = p.encrypt(message, headers)
+				if err != nil {
+					mylogger.Error(
+						ctx,
+						p.logger,
+						"outbox worker encrypt event failed",
+						zap.Int64("id", event.Id),
+						zap.Error(err),
+					)
+
+					_ = p.repo.MarkEventFailed(ctx, tx, event.Id, err.Error())
+					continue
+				}
+			}
+
+			if _, ok := batches[event.Topic]; !ok {
+				topics = append(topics, event.Topic)
+			}
+			batches[event.Topic] = append(batches[event.Topic], outgoing{
+				event: event,
+				// The offset stays -1 unless the producer reports one.
+				message: kafka.Message{Key: event.AggregateID, Value: message, Headers: headers, Offset: -1},
+			})
+		}
+
+		for _, topic := range topics {
+			if err := p.publish(ctx, tx, topic, batches[topic]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// compact drops the events a later pending event replaces, see
+// WithCompaction.
+func (p *OutboxProcessor) compact(ctx context.Context, tx pgx.Tx, events []*domain.OutboxEvent) ([]*domain.OutboxEvent, error) {
+	if len(p.compacted) == 0 {
+		return events, nil
 	}
-	defer func() {
-		cleanupCtx := context.WithoutCancel(ctx)
 
-		err := tx.Rollback(cleanupCtx)
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			mylogger.Error(
-				cleanupCtx,
-				p.logger,
-				"Outbox worker failed to rollback transaction",
-				zap.Error(err),
-				zap.String("method_name", "processBatch"),
-			)
+	var candidates []int64
+	for _, event := range events {
+		if slices.Contains(p.compacted, event.EventType) {
+			candidates = append(candidates, event.Id)
 		}
-	}()
+	}
+	if len(candidates) == 0 {
+		return events, nil
+	}
 
-	events, err := p.repo.GetUnpublishedEvents(ctx, tx, p.batchSize)
+	superseded, err := p.repo.SupersedeEvents(ctx, tx, candidates)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	if len(events) == 0 {
-		return nil
+	if len(superseded) == 0 {
+		return events, nil
 	}
 
-	mylogger.Info(
-		ctx,
-		p.logger,
-		"Processing outbox events",
-		zap.Int("count", len(events)),
-	)
+	mylogger.Info(ctx, p.logger, "Skipping superseded outbox events", zap.Int("count", len(superseded)))
 
-	for _, event := range events {
-		var payloadMap map[string]any
-		if err := json.Unmarshal(event.Payload, &payloadMap); err != nil {
-			mylogger.Error(
-				ctx,
-				p.logger,
-				"outbox worker unmarshal event payload failed",
-				zap.Int64("id", event.Id),
-				zap.Error(err),
-			)
+	return slices.DeleteFunc(events, func(event *domain.OutboxEvent) bool {
+		return slices.Contains(superseded, event.Id)
+	}), nil
+}
 
-			_ = p.repo.MarkEventFailed(ctx, tx, event.Id, err.Error())
-			continue
-		}
+type outgoing struct {
+	event   *domain.OutboxEvent
+	message kafka.Message
+}
 
-		payloadMap["event_id"] = event.Id
+// publish sends one topic's batch and records the outcome of every event.
+func (p *OutboxProcessor) publish(ctx context.Context, tx pgx.Tx, topic string, batch []outgoing) error {
+	messages := make([]kafka.Message, len(batch))
+	for i, out := range batch {
+		messages[i] = out.message
+	}
 
-		err = p.kafkaProducer.ProduceMessage(
-			ctx,
-			event.Topic,
-			payloadMap,
-		)
-		if err != nil {
+	failed := p.produce(ctx, topic, messages)
+
+	for i, out := range batch {
+		if produceErr, ok := failed[i]; ok {
 			mylogger.Error(
 				ctx,
 				p.logger,
 				"outbox worker produce message failed",
-				zap.Int64("id", event.Id),
-				zap.Error(err),
+				zap.Int64("id", out.event.Id),
+				zap.Error(produceErr),
 			)
-			if dbErr := p.repo.MarkEventFailed(ctx, tx, event.Id, err.Error()); dbErr != nil {
+			if dbErr := p.repo.MarkEventFailed(ctx, tx, out.event.Id, produceErr.Error()); dbErr != nil {
 				mylogger.Error(
 					ctx,
 					p.logger,
 					"outbox worker mark event failed failed",
-					zap.Int64("id", event.Id),
-					zap.Error(err),
+					zap.Int64("id", out.event.Id),
+					zap.Error(dbErr),
 				)
 			}
-		} else {
-			if dbErr := p.repo.MarkEventPublished(ctx, tx, event.Id); dbErr != nil {
-				mylogger.Error(
-					ctx,
-					p.logger,
-					"Outbox worker event publishing failed",
-					zap.Int64("id", event.Id),
-					zap.Error(err),
-				)
+			continue
+		}
 
-				return err
+		if dbErr := p.repo.MarkEventPublished(ctx, tx, out.event.Id, messages[i].Partition, messages[i].Offset); dbErr != nil {
+			mylogger.Error(
+				ctx,
+				p.logger,
+				"Outbox worker event publishing failed",
+				zap.Int64("id", out.event.Id),
+				zap.Error(dbErr),
+			)
+
+			return dbErr
+		}
+
+		mylogger.Debug(
+			ctx,
+			p.logger,
+			"outbox worker event published successfully",
+			zap.Int64("id", out.event.Id),
+		)
+	}
+
+	return nil
+}
+
+// produce sends messages, resending the ones that failed for a transient
+// reason as the retry policy allows, and returns why the rest failed by
+// index.
+func (p *OutboxProcessor) produce(ctx context.Context, topic string, messages []kafka.Message) map[int]error {
+	failed := make(map[int]error)
+
+	pending := make([]int, len(messages))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	policy := p.retry
+	policy.OnRetry = func(attempt int, err error, wait time.Duration) {
+		mylogger.Warn(
+			ctx,
+			p.logger,
+			"Resending outbox events",
+			zap.String("topic", topic),
+			zap.Int("count", len(pending)),
+			zap.Int("attempt", attempt),
+			zap.Duration("wait", wait),
+			zap.Error(err),
+		)
+	}
+
+	_ = retry.Do(ctx, policy, func(ctx context.Context) error {
+		attempt := make([]kafka.Message, len(pending))
+		for j, i := range pending {
+			attempt[j] = messages[i]
+			delete(failed, i)
+		}
+
+		err := p.kafkaProducer.ProduceMessages(ctx, topic, attempt)
+		for j, i := range pending {
+			messages[i] = attempt[j]
+		}
+
+		var batchErr *kafka.BatchError
+		if errors.As(err, &batchErr) {
+			for j, produceErr := range batchErr.Failed {
+				failed[pending[j]] = produceErr
+			}
+		} else if err != nil {
+			for _, i := range pending {
+				failed[i] = err
+			}
+		}
+
+		var (
+			resend   []int
+			firstErr error
+		)
+		for _, i := range pending {
+			produceErr, ok := failed[i]
+			if !ok || (p.retry.Retryable != nil && !p.retry.Retryable(produceErr)) {
+				continue
+			}
+
+			resend = append(resend, i)
+			if firstErr == nil {
+				firstErr = produceErr
 			}
+		}
+
+		pending = resend
+
+		return firstErr
+	})
+
+	return failed
+}
+
+// encrypt replaces message with its ciphertext and adds the decryption
+// headers. Content-type and metadata headers stay readable so consumers can
+// route the message before decrypting it.
+func (p *OutboxProcessor) encrypt(message any, headers map[string]string) (any, error) {
+	value, err := kafka.EncodeMessage(message)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, encHeaders, err := p.keyring.Encrypt(value)
+	if err != nil {
+		return nil, err
+	}
+
+	maps.Copy(headers, encHeaders)
+
+	return kafka.RawMessage(ciphertext), nil
+}
+
+// buildMessage renders an outbox row in the configured wire format.
+func (p *OutboxProcessor) buildMessage(ctx context.Context, event *domain.OutboxEvent, envelope map[string]any) (any, map[string]string) {
+	meta := eventmeta.Metadata{
+		EventID:       event.EventID,
+		EventType:     event.EventType,
+		OccurredAt:    event.OccurredAt,
+		Producer:      event.Producer,
+		CorrelationID: event.CorrelationID,
+		CausationID:   event.CausationID,
+		TenantID:      event.TenantID,
+	}
 
-			mylogger.Debug(
+	// Older rows predate event_type; the envelope always names the event.
+	if meta.EventType == "" {
+		meta.EventType, _ = envelope["event"].(string)
+	}
+
+	headers := meta.Headers()
+
+	if p.format == kafka.FormatProtobuf {
+		payload, _ := json.Marshal(envelope["payload"])
+
+		msg, ok, err := kafka.EncodeProtobuf(meta.EventType, payload)
+		if err != nil {
+			mylogger.Warn(
 				ctx,
 				p.logger,
-				"outbox worker event published successfully",
-				zap.Int64("id", event.Id),
+				"Falling back to JSON envelope",
+				zap.Int64("event_id", event.Id),
+				zap.Error(err),
 			)
 		}
+		if ok {
+			headers[kafka.HeaderContentType] = kafka.ProtobufContentType
+			headers[kafka.HeaderProtoMessage] = string(proto.MessageName(msg))
+			return msg, headers
+		}
+	}
+
+	if p.format == kafka.FormatCloudEvents {
+		data, _ := json.Marshal(envelope["payload"])
+
+		subject := ""
+		if event.AggregateType != "" && event.AggregateID != "" {
+			subject = event.AggregateType + "/" + event.AggregateID
+		}
+
+		headers[kafka.HeaderContentType] = kafka.CloudEventsContentType
+		return kafka.NewCloudEvent(meta, subject, data), headers
+	}
+
+	envelope[eventmeta.HeaderEventID] = meta.EventID
+	envelope[eventmeta.HeaderOccurredAt] = meta.OccurredAt.UTC()
+	envelope[eventmeta.HeaderProducer] = meta.Producer
+	envelope[eventmeta.HeaderCorrelationID] = meta.CorrelationID
+	if meta.CausationID != "" {
+		envelope[eventmeta.HeaderCausationID] = meta.CausationID
+	}
+	if meta.TenantID != "" {
+		envelope[eventmeta.HeaderTenantID] = meta.TenantID
 	}
 
-	return tx.Commit(ctx)
+	return envelope, headers
 }
@@ -0,0 +1,141 @@
+// Package query builds parameterized SQL for list endpoints: pagination,
+// allowlisted sorting, keyset cursors and filter expressions. Values only
+// ever reach the database as bind arguments; identifiers come from code.
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Builder collects bind arguments and hands out their $n placeholders.
+type Builder struct {
+	args []any
+}
+
+// Arg registers v and returns its placeholder.
+func (b *Builder) Arg(v any) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+func (b *Builder) Args() []any {
+	return b.args
+}
+
+// Where renders the filter as a WHERE clause, or "" when it is empty.
+func (b *Builder) Where(filter Expr) string {
+	sql := filter.build(b)
+	if sql == "" {
+		return ""
+	}
+
+	return " WHERE " + sql
+}
+
+// Page renders LIMIT/OFFSET for p.
+func (b *Builder) Page(p Page) string {
+	if p.Offset == 0 {
+		return " LIMIT " + b.Arg(p.Limit)
+	}
+
+	return fmt.Sprintf(" LIMIT %s OFFSET %s", b.Arg(p.Limit), b.Arg(p.Offset))
+}
+
+// Expr is a node of a filter tree.
+type Expr interface {
+	build(b *Builder) string
+}
+
+type exprFunc func(b *Builder) string
+
+func (f exprFunc) build(b *Builder) string {
+	return f(b)
+}
+
+func compare(column, op string, value any) Expr {
+	return exprFunc(func(b *Builder) string {
+		return column + " " + op + " " + b.Arg(value)
+	})
+}
+
+func Eq(column string, value any) Expr  { return compare(column, "=", value) }
+func Gt(column string, value any) Expr  { return compare(column, ">", value) }
+func Gte(column string, value any) Expr { return compare(column, ">=", value) }
+func Lt(column string, value any) Expr  { return compare(column, "<", value) }
+func Lte(column string, value any) Expr { return compare(column, "<=", value) }
+
+// In matches any element of values, which must be a slice pgx can encode.
+func In(column string, values any) Expr {
+	return exprFunc(func(b *Builder) string {
+		return column + " = ANY(" + b.Arg(values) + ")"
+	})
+}
+
+// Contains is a case-insensitive substring match.
+func Contains(column, substr string) Expr {
+	return compare(column, "ILIKE", "%"+escapeLike(substr)+"%")
+}
+
+// JSONContains matches jsonb columns containing the given document.
+func JSONContains(column, document string) Expr {
+	return exprFunc(func(b *Builder) string {
+		return column + " @> " + b.Arg(document) + "::jsonb"
+	})
+}
+
+// Cond is a fixed condition such as "deleted_at IS NULL". It must not contain
+// user input.
+func Cond(sql string) Expr {
+	return exprFunc(func(*Builder) string {
+		return sql
+	})
+}
+
+func And(exprs ...Expr) Expr {
+	return join(" AND ", exprs)
+}
+
+func Or(exprs ...Expr) Expr {
+	return join(" OR ", exprs)
+}
+
+// join skips nil and empty operands, so optional filters can be passed
+// unconditionally.
+func join(sep string, exprs []Expr) Expr {
+	return exprFunc(func(b *Builder) string {
+		parts := make([]string, 0, len(exprs))
+		for _, e := range exprs {
+			if e == nil {
+				continue
+			}
+			if sql := e.build(b); sql != "" {
+				parts = append(parts, sql)
+			}
+		}
+
+		switch len(parts) {
+		case 0:
+			return ""
+		case 1:
+			return parts[0]
+		default:
+			return "(" + strings.Join(parts, sep) + ")"
+		}
+	})
+}
+
+// When returns e if ok and nil otherwise.
+func When(ok bool, e Expr) Expr {
+	if !ok {
+		return nil
+	}
+
+	return e
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
@@ -0,0 +1,47 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns the keyset position after the last row of a page into
+// an opaque token for the client.
+func EncodeCursor(position any) (string, error) {
+	b, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// DecodeCursor reverses EncodeCursor. An empty token leaves position
+// untouched and reports false.
+func DecodeCursor(token string, position any) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return false, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(b, position); err != nil {
+		return false, ErrInvalidCursor
+	}
+
+	return true, nil
+}
+
+// After is the keyset condition for rows following (value, id) in
+// descending order of (column, idColumn).
+func After(column, idColumn string, value any, id int64) Expr {
+	return exprFunc(func(b *Builder) string {
+		return "(" + column + ", " + idColumn + ") < (" + b.Arg(value) + ", " + b.Arg(id) + ")"
+	})
+}
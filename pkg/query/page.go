@@ -0,0 +1,46 @@
+package query
+
+import (
+	"errors"
+	"strconv"
+)
+
+var ErrInvalidPage = errors.New("invalid pagination")
+
+type Page struct {
+	Limit  int64
+	Offset int64
+}
+
+// Clamp applies the default to a missing limit and caps it at maxLimit.
+func (p Page) Clamp(defaultLimit, maxLimit int64) Page {
+	if p.Limit <= 0 {
+		p.Limit = defaultLimit
+	}
+	p.Limit = min(p.Limit, maxLimit)
+	p.Offset = max(p.Offset, 0)
+
+	return p
+}
+
+// ParsePage reads limit and offset query values; empty strings are zero.
+func ParsePage(limit, offset string) (Page, error) {
+	var (
+		p   Page
+		err error
+	)
+
+	if limit != "" {
+		if p.Limit, err = strconv.ParseInt(limit, 10, 64); err != nil || p.Limit < 0 {
+			return Page{}, ErrInvalidPage
+		}
+	}
+
+	if offset != "" {
+		if p.Offset, err = strconv.ParseInt(offset, 10, 64); err != nil || p.Offset < 0 {
+			return Page{}, ErrInvalidPage
+		}
+	}
+
+	return p, nil
+}
@@ -0,0 +1,67 @@
+package query
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+// SortFields maps public sort names to columns. Only names in the map can
+// reach ORDER BY.
+type SortFields map[string]string
+
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// ParseSort reads "price,-created_at"; a leading "-" means descending.
+func ParseSort(s string) []Sort {
+	var sorts []Sort
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(part, "-")
+		sorts = append(sorts, Sort{Field: strings.TrimPrefix(part, "-"), Desc: desc})
+	}
+
+	return sorts
+}
+
+// OrderBy renders " ORDER BY ..." for sorts, falling back to fallback when
+// empty. tieBreaker, usually the primary key, is appended so pages are stable.
+func (f SortFields) OrderBy(sorts []Sort, fallback Sort, tieBreaker string) (string, error) {
+	if len(sorts) == 0 {
+		sorts = []Sort{fallback}
+	}
+
+	parts := make([]string, 0, len(sorts)+1)
+	lastDesc := false
+	for _, s := range sorts {
+		column, ok := f[s.Field]
+		if !ok {
+			return "", ErrInvalidSort
+		}
+
+		parts = append(parts, column+direction(s.Desc))
+		lastDesc = s.Desc
+	}
+
+	if tieBreaker != "" {
+		parts = append(parts, tieBreaker+direction(lastDesc))
+	}
+
+	return " ORDER BY " + strings.Join(parts, ", "), nil
+}
+
+func direction(desc bool) string {
+	if desc {
+		return " DESC"
+	}
+
+	return " ASC"
+}
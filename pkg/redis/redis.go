@@ -0,0 +1,177 @@
+// Package redis builds go-redis clients from configuration, so services do
+// not hard-code addresses or topology.
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Mode is the Redis deployment topology.
+type Mode string
+
+const (
+	ModeStandalone Mode = "standalone"
+	ModeSentinel   Mode = "sentinel"
+	ModeCluster    Mode = "cluster"
+)
+
+var ErrInvalidConfig = errors.New("invalid redis config")
+
+type Config struct {
+	Mode Mode `yaml:"mode" env:"REDIS_MODE" env-default:"standalone"`
+	// Addrs is the server address in standalone mode, the sentinels in
+	// sentinel mode and the seed nodes in cluster mode.
+	Addrs      []string `yaml:"addrs" env:"REDIS_ADDRS" env-separator:"," env-default:"localhost:6379"`
+	MasterName string   `yaml:"master_name" env:"REDIS_MASTER_NAME"`
+	Username   string   `yaml:"username" env:"REDIS_USERNAME"`
+	Password   string   `yaml:"password" env:"REDIS_PASSWORD"`
+	// SentinelPassword authenticates against the sentinels themselves.
+	SentinelPassword string `yaml:"sentinel_password" env:"REDIS_SENTINEL_PASSWORD"`
+	// DB is ignored in cluster mode, which only has database 0.
+	DB   int  `yaml:"db" env:"REDIS_DB" env-default:"0"`
+	TLS  TLS  `yaml:"tls"`
+	Pool Pool `yaml:"pool"`
+}
+
+type TLS struct {
+	Enabled            bool   `yaml:"enabled" env:"REDIS_TLS_ENABLED" env-default:"false"`
+	ServerName         string `yaml:"server_name" env:"REDIS_TLS_SERVER_NAME"`
+	CAFile             string `yaml:"ca_file" env:"REDIS_TLS_CA_FILE"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" env:"REDIS_TLS_INSECURE_SKIP_VERIFY" env-default:"false"`
+}
+
+// Pool tunes the connection pool. Zero values keep the go-redis defaults.
+type Pool struct {
+	Size            int           `yaml:"size" env:"REDIS_POOL_SIZE"`
+	MinIdleConns    int           `yaml:"min_idle_conns" env:"REDIS_MIN_IDLE_CONNS"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"REDIS_MAX_IDLE_CONNS"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"REDIS_CONN_MAX_IDLE_TIME"`
+	PoolTimeout     time.Duration `yaml:"pool_timeout" env:"REDIS_POOL_TIMEOUT"`
+	DialTimeout     time.Duration `yaml:"dial_timeout" env:"REDIS_DIAL_TIMEOUT" env-default:"5s"`
+	ReadTimeout     time.Duration `yaml:"read_timeout" env:"REDIS_READ_TIMEOUT" env-default:"3s"`
+	WriteTimeout    time.Duration `yaml:"write_timeout" env:"REDIS_WRITE_TIMEOUT" env-default:"3s"`
+}
+
+// LoadFromEnv reads the REDIS_* environment variables.
+func LoadFromEnv() (Config, error) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return Config{}, fmt.Errorf("error reading redis config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func (c Config) Validate() error {
+	if len(c.Addrs) == 0 {
+		return fmt.Errorf("%w: no addresses", ErrInvalidConfig)
+	}
+
+	switch c.Mode {
+	case ModeStandalone:
+		if len(c.Addrs) != 1 {
+			return fmt.Errorf("%w: standalone mode takes exactly one address, got %d", ErrInvalidConfig, len(c.Addrs))
+		}
+	case ModeSentinel:
+		if c.MasterName == "" {
+			return fmt.Errorf("%w: sentinel mode requires a master name", ErrInvalidConfig)
+		}
+	case ModeCluster:
+		if c.DB != 0 {
+			return fmt.Errorf("%w: cluster mode only supports db 0", ErrInvalidConfig)
+		}
+	default:
+		return fmt.Errorf("%w: unknown mode %q", ErrInvalidConfig, c.Mode)
+	}
+
+	return nil
+}
+
+func (c Config) options() (*goredis.UniversalOptions, error) {
+	opts := &goredis.UniversalOptions{
+		Addrs:            c.Addrs,
+		MasterName:       c.MasterName,
+		Username:         c.Username,
+		Password:         c.Password,
+		SentinelPassword: c.SentinelPassword,
+		DB:               c.DB,
+		PoolSize:         c.Pool.Size,
+		MinIdleConns:     c.Pool.MinIdleConns,
+		MaxIdleConns:     c.Pool.MaxIdleConns,
+		ConnMaxIdleTime:  c.Pool.ConnMaxIdleTime,
+		PoolTimeout:      c.Pool.PoolTimeout,
+		DialTimeout:      c.Pool.DialTimeout,
+		ReadTimeout:      c.Pool.ReadTimeout,
+		WriteTimeout:     c.Pool.WriteTimeout,
+	}
+
+	if !c.TLS.Enabled {
+		return opts, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.TLS.ServerName,
+		InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+	}
+
+	if c.TLS.CAFile != "" {
+		pem, err := os.ReadFile(c.TLS.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading redis ca file: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificates in %s", ErrInvalidConfig, c.TLS.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	opts.TLSConfig = tlsConfig
+
+	return opts, nil
+}
+
+// New builds a client for the configured mode and checks that it can reach
+// the server. The mode is explicit rather than guessed from the number of
+// addresses, so a one-node cluster still gets a cluster client.
+func New(ctx context.Context, cfg Config) (goredis.UniversalClient, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	opts, err := cfg.options()
+	if err != nil {
+		return nil, err
+	}
+
+	var client goredis.UniversalClient
+	switch cfg.Mode {
+	case ModeSentinel:
+		client = goredis.NewFailoverClient(opts.Failover())
+	case ModeCluster:
+		client = goredis.NewClusterClient(opts.Cluster())
+	default:
+		client = goredis.NewClient(opts.Simple())
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, cfg.Pool.DialTimeout+time.Second)
+	defer cancel()
+
+	if err := client.Ping(pingCtx).Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("redis ping failed: %w", err)
+	}
+
+	return client, nil
+}
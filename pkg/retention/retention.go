@@ -0,0 +1,115 @@
+// Package retention removes rows that outlived their purpose, so
+// bookkeeping tables do not grow forever.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+// deleteBatchSize bounds the rows one DELETE touches, so a large backlog does
+// not hold locks or bloat WAL in a single statement.
+const deleteBatchSize = 1000
+
+var (
+	rowsRemoved = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_rows_removed_total",
+		Help: "Rows removed or cleared by retention policies.",
+	}, []string{"policy"})
+	runFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "retention_failures_total",
+		Help: "Retention policy runs that failed.",
+	}, []string{"policy"})
+)
+
+// RegisterMetrics exposes the retention metrics on reg.
+func RegisterMetrics(reg prometheus.Registerer) error {
+	if err := reg.Register(rowsRemoved); err != nil {
+		return err
+	}
+
+	return reg.Register(runFailures)
+}
+
+// PurgeFunc removes the rows older than cutoff and returns how many it
+// removed.
+type PurgeFunc func(ctx context.Context, cutoff time.Time) (int64, error)
+
+type Policy struct {
+	Name string
+	// Retention is how long rows are kept past their timestamp.
+	Retention time.Duration
+	Purge     PurgeFunc
+}
+
+// Job applies its policies; schedule its Run with pkg/jobs.
+type Job struct {
+	policies []Policy
+	logger   *zap.Logger
+}
+
+func NewJob(logger *zap.Logger, policies ...Policy) *Job {
+	return &Job{
+		policies: policies,
+		logger:   logger,
+	}
+}
+
+// Run applies every policy once. A failing policy does not stop the rest;
+// their errors are returned together.
+func (j *Job) Run(ctx context.Context) error {
+	var errs []error
+	for _, p := range j.policies {
+		cutoff := time.Now().Add(-p.Retention)
+
+		n, err := p.Purge(ctx, cutoff)
+		rowsRemoved.WithLabelValues(p.Name).Add(float64(n))
+		if err != nil {
+			runFailures.WithLabelValues(p.Name).Inc()
+			errs = append(errs, fmt.Errorf("policy %s: %w", p.Name, err))
+			continue
+		}
+
+		if n > 0 {
+			mylogger.Info(ctx, j.logger, "Retention policy applied", zap.String("policy", p.Name), zap.Int64("rows", n))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// DeleteBefore deletes the rows of table whose column is before the cutoff,
+// in batches. table and column are trusted identifiers, never user input.
+func DeleteBefore(pool *pgxpool.Pool, table, column string) PurgeFunc {
+	query := fmt.Sprintf(`
+		DELETE FROM %[1]s
+		WHERE ctid IN (
+			SELECT ctid FROM %[1]s
+			WHERE %[2]s < $1
+			LIMIT $2
+		)
+	`, pgx.Identifier{table}.Sanitize(), pgx.Identifier{column}.Sanitize())
+
+	return func(ctx context.Context, cutoff time.Time) (int64, error) {
+		var total int64
+		for {
+			tag, err := pool.Exec(ctx, query, cutoff, deleteBatchSize)
+			if err != nil {
+				return total, fmt.Errorf("error purging %s: %w", table, err)
+			}
+
+			total += tag.RowsAffected()
+			if tag.RowsAffected() < deleteBatchSize {
+				return total, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,159 @@
+// Package retry runs operations again after transient failures, waiting an
+// exponentially growing, jittered delay between attempts and giving up when
+// the context ends.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy describes how often and how patiently to retry.
+type Policy struct {
+	// MaxAttempts counts the first call; values below 1 mean a single call.
+	MaxAttempts int
+	// Initial is the wait after the first failure. It grows by Multiplier
+	// with every further failure, up to Max when that is set.
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	// Jitter spreads each wait over ±Jitter of it, e.g. 0.2 for ±20%, so
+	// clients failing together do not retry together.
+	Jitter float64
+	// Retryable decides whether an error is worth another attempt. Nil
+	// retries everything except Permanent errors and the context's own.
+	Retryable func(error) bool
+	// OnRetry, when set, is told about every failure that will be retried.
+	OnRetry func(attempt int, err error, wait time.Duration)
+}
+
+// Default suits calls to a nearby dependency: three attempts within about a
+// second.
+func Default() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		Initial:     200 * time.Millisecond,
+		Max:         2 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
+
+// Backoff is the wait after the given failed attempt, starting at 1.
+func (p Policy) Backoff(attempt int) time.Duration {
+	return p.jittered(p.base(attempt))
+}
+
+func (p Policy) base(attempt int) time.Duration {
+	wait := float64(p.Initial)
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	for i := 1; i < attempt; i++ {
+		wait *= multiplier
+		if p.Max > 0 && wait >= float64(p.Max) {
+			return p.Max
+		}
+	}
+
+	if p.Max > 0 && wait > float64(p.Max) {
+		return p.Max
+	}
+
+	return time.Duration(wait)
+}
+
+func (p Policy) jittered(wait time.Duration) time.Duration {
+	if p.Jitter <= 0 || wait <= 0 {
+		return wait
+	}
+
+	spread := float64(wait) * min(p.Jitter, 1)
+
+	return time.Duration(float64(wait) - spread + rand.Float64()*2*spread)
+}
+
+func (p Policy) retryable(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if p.Retryable == nil {
+		return true
+	}
+
+	return p.Retryable(err)
+}
+
+// Do calls fn until it succeeds, fails with an error that is not retryable,
+// runs out of attempts or ctx ends. It returns fn's last error, or ctx's when
+// the context ended while waiting.
+func Do(ctx context.Context, p Policy, fn func(ctx context.Context) error) error {
+	_, err := DoValue(ctx, p, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+
+	return err
+}
+
+// DoValue is Do for operations that return a value.
+func DoValue[T any](ctx context.Context, p Policy, fn func(ctx context.Context) (T, error)) (T, error) {
+	attempts := max(p.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		value, err := fn(ctx)
+		if err == nil {
+			return value, nil
+		}
+
+		if attempt == attempts || !p.retryable(err) {
+			return value, unwrapPermanent(err)
+		}
+
+		wait := p.Backoff(attempt)
+		if p.OnRetry != nil {
+			p.OnRetry(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return value, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying whatever the policy's classifier
+// says. Do returns err itself, not the wrapper.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+func unwrapPermanent(err error) error {
+	if permanent, ok := err.(*permanentError); ok {
+		return permanent.err
+	}
+
+	return err
+}
@@ -0,0 +1,261 @@
+// Package seed generates fake but plausible data for load tests and local
+// development. The services feed what it generates through their own domain
+// services, so the rows are valid and the outbox events go out like in
+// production.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Options are what `seed` is called with.
+type Options struct {
+	// Count is how many records to create.
+	Count int
+	// Seed makes a run reproducible. Runs with the same seed generate the same
+	// records, although concurrent ones may insert them in another order.
+	Seed uint64
+	// Concurrency is how many records are created at once.
+	Concurrency int
+	// Out receives progress.
+	Out io.Writer
+}
+
+// Rand draws from the distributions real shops tend to show. It is not safe
+// for concurrent use: draw everything up front, or one Rand per record with
+// ForRecord.
+type Rand struct {
+	*rand.Rand
+}
+
+func NewRand(seed uint64) *Rand {
+	return &Rand{rand.New(rand.NewPCG(seed, 0x5eed))}
+}
+
+// ForRecord returns the Rand for the i-th record of a run, so that record
+// comes out the same whatever order the workers pick records up in.
+func ForRecord(seed uint64, i int) *Rand {
+	return &Rand{rand.New(rand.NewPCG(seed, uint64(i)))}
+}
+
+// LogNormal draws around median with most values within a factor of
+// e^sigma of it and a long tail upwards, like prices and order totals.
+func (r *Rand) LogNormal(median, sigma float64) float64 {
+	return median * math.Exp(r.NormFloat64()*sigma)
+}
+
+// Geometric draws a count from 1 up with the given mean: mostly one or two,
+// now and then many. Basket sizes and quantities look like this.
+func (r *Rand) Geometric(mean float64) int {
+	if mean <= 1 {
+		return 1
+	}
+
+	p := 1 / mean
+	return 1 + int(math.Floor(math.Log(1-r.Float64())/math.Log(1-p)))
+}
+
+// Chance reports true with probability p.
+func (r *Rand) Chance(p float64) bool {
+	return r.Float64() < p
+}
+
+// Pick returns a random element of items.
+func Pick[T any](r *Rand, items []T) T {
+	return items[r.IntN(len(items))]
+}
+
+// Weighted picks items in proportion to their weights.
+type Weighted[T any] struct {
+	items []T
+	cum   []float64
+}
+
+func NewWeighted[T any](items []T, weights []float64) *Weighted[T] {
+	cum := make([]float64, len(weights))
+	total := 0.0
+	for i, w := range weights {
+		total += w
+		cum[i] = total
+	}
+
+	return &Weighted[T]{items: items, cum: cum}
+}
+
+func (w *Weighted[T]) Pick(r *Rand) T {
+	x := r.Float64() * w.cum[len(w.cum)-1]
+	for i, c := range w.cum {
+		if x < c {
+			return w.items[i]
+		}
+	}
+
+	return w.items[len(w.items)-1]
+}
+
+// Zipf picks indexes in [0, n) so that a few come up most of the time: a
+// handful of best sellers and heavy buyers, then a long tail. s > 1 sets
+// how steep the head is.
+type Zipf struct {
+	cum []float64
+}
+
+func NewZipf(n int, s float64) *Zipf {
+	cum := make([]float64, n)
+	total := 0.0
+	for k := range n {
+		total += 1 / math.Pow(float64(k+1), s)
+		cum[k] = total
+	}
+
+	return &Zipf{cum: cum}
+}
+
+func (z *Zipf) Pick(r *Rand) int {
+	x := r.Float64() * z.cum[len(z.cum)-1]
+
+	lo, hi := 0, len(z.cum)-1
+	for lo < hi {
+		mid := (lo + hi) / 2
+		if z.cum[mid] <= x {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	return lo
+}
+
+// Person returns a first and a last name.
+func (r *Rand) Person() (string, string) {
+	return Pick(r, firstNames), Pick(r, lastNames)
+}
+
+// Email builds an address for a person that stays unique across runs with
+// different seeds: i is the record index.
+func (r *Rand) Email(first, last string, seed uint64, i int) string {
+	domain := emailDomains.Pick(r)
+	return fmt.Sprintf("%s.%s.%d.%d@%s", strings.ToLower(first), strings.ToLower(last), seed, i, domain)
+}
+
+// Address returns a street line, a city, a postal code and the city's ISO
+// country code.
+func (r *Rand) Address() (line1, city, postalCode, country string) {
+	c := cities.Pick(r)
+	line1 = fmt.Sprintf("%d %s", 1+r.IntN(250), Pick(r, streets))
+	postalCode = fmt.Sprintf("%0*d", c.postalDigits, r.IntN(int(math.Pow10(c.postalDigits))))
+
+	return line1, c.name, postalCode, c.country
+}
+
+type city struct {
+	name         string
+	country      string
+	postalDigits int
+}
+
+var (
+	firstNames = []string{
+		"Anna", "Boris", "Chloe", "Daniel", "Elena", "Felix", "Grace", "Hugo",
+		"Irina", "James", "Kira", "Leo", "Maria", "Nikita", "Olga", "Pavel",
+		"Quinn", "Rosa", "Sergei", "Tanya", "Umar", "Vera", "William", "Yana",
+	}
+	lastNames = []string{
+		"Smith", "Ivanova", "Garcia", "Petrov", "Muller", "Kuznetsova", "Brown",
+		"Sokolov", "Rossi", "Popova", "Martin", "Volkov", "Novak", "Lebedeva",
+	}
+	streets = []string{
+		"Main Street", "Station Road", "Lenina Street", "High Street", "Park Avenue",
+		"Garden Lane", "Sadovaya Street", "Church Road", "Mira Avenue", "Mill Lane",
+	}
+	// Customers cluster in the big cities.
+	cities = NewWeighted(
+		[]city{
+			{"Moscow", "RU", 6}, {"Saint Petersburg", "RU", 6}, {"Berlin", "DE", 5},
+			{"London", "GB", 5}, {"Madrid", "ES", 5}, {"Kazan", "RU", 6}, {"Milan", "IT", 5},
+		},
+		[]float64{30, 15, 15, 15, 10, 8, 7},
+	)
+	// Free mail dominates sign-ups; the rest is a long tail of work domains.
+	emailDomains = NewWeighted(
+		[]string{"gmail.example", "yandex.example", "mail.example", "outlook.example", "corp.example"},
+		[]float64{45, 25, 15, 10, 5},
+	)
+)
+
+// Run creates opts.Count records with up to opts.Concurrency of create
+// running at once and reports progress to opts.Out. A failed record is
+// reported and skipped, so one conflict does not throw away a long run; Run
+// fails only when ctx is done or nothing could be created.
+func Run(ctx context.Context, opts Options, what string, create func(ctx context.Context, i int) error) error {
+	concurrency := max(opts.Concurrency, 1)
+	start := time.Now()
+
+	var (
+		next            atomic.Int64
+		created, failed atomic.Int64
+		firstErr        error
+		errOnce         sync.Once
+		wg              sync.WaitGroup
+	)
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(2 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fmt.Fprintf(opts.Out, "%s: %d/%d created, %d failed\n", what, created.Load(), opts.Count, failed.Load())
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1)) - 1
+				if i >= opts.Count {
+					return
+				}
+
+				if err := create(ctx, i); err != nil {
+					failed.Add(1)
+					errOnce.Do(func() { firstErr = err })
+					continue
+				}
+				created.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(done)
+
+	fmt.Fprintf(opts.Out, "%s: %d created, %d failed in %s\n", what, created.Load(), failed.Load(), time.Since(start).Round(time.Millisecond))
+	if firstErr != nil {
+		fmt.Fprintf(opts.Out, "%s: first failure: %v\n", what, firstErr)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if created.Load() == 0 && opts.Count > 0 {
+		return fmt.Errorf("no %s created: %w", what, firstErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+// Package servicestatus implements the internal status RPCs every service
+// registers on its gRPC server. They report the outbox backlog, the lag of
+// the service's consumer groups and the outbox events of one flow for the
+// gateway's admin endpoints.
+package servicestatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	pb "github.com/sakashimaa/go-pet-project/proto/status"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultFailedLimit = 20
+	maxFailedLimit     = 100
+
+	// traceLimit caps the events TraceEvents returns; a saga has a dozen.
+	traceLimit = 200
+)
+
+// ConsumerGroup is a consumer group whose lag is reported.
+type ConsumerGroup struct {
+	ID     string
+	Topics []string
+}
+
+type Server struct {
+	pb.UnimplementedStatusServiceServer
+
+	service string
+	logger  *zap.Logger
+	pool    *pgxpool.Pool
+	kafka   kafka.Config
+	groups  []ConsumerGroup
+}
+
+type Option func(*Server)
+
+// WithOutbox reports the backlog of, and traces events in, the outbox table
+// in pool.
+func WithOutbox(pool *pgxpool.Pool) Option {
+	return func(s *Server) {
+		s.pool = pool
+	}
+}
+
+// WithConsumerGroups reports the lag of groups on the cluster of cfg.
+func WithConsumerGroups(cfg kafka.Config, groups ...ConsumerGroup) Option {
+	return func(s *Server) {
+		s.kafka = cfg
+		s.groups = append(s.groups, groups...)
+	}
+}
+
+func New(service string, logger *zap.Logger, opts ...Option) *Server {
+	s := &Server{
+		service: service,
+		logger:  logger,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// GetStatus never fails as a whole: a part that cannot be read carries its
+// error, so one broken dependency does not hide the rest.
+func (s *Server) GetStatus(ctx context.Context, req *pb.GetStatusRequest) (*pb.GetStatusResponse, error) {
+	res := &pb.GetStatusResponse{Service: s.service}
+
+	if s.pool != nil {
+		res.Outbox = s.outboxStatus(ctx, failedLimit(req.FailedLimit))
+	}
+
+	for _, group := range s.groups {
+		res.ConsumerGroups = append(res.ConsumerGroups, s.groupStatus(ctx, group))
+	}
+
+	return res, nil
+}
+
+func failedLimit(limit int32) int {
+	switch {
+	case limit <= 0:
+		return defaultFailedLimit
+	case limit > maxFailedLimit:
+		return maxFailedLimit
+	default:
+		return int(limit)
+	}
+}
+
+func (s *Server) outboxStatus(ctx context.Context, limit int) *pb.OutboxStatus {
+	topics, failed, err := repository.Backlog(ctx, s.pool, limit)
+	if err != nil {
+		mylogger.Warn(ctx, s.logger, "Failed to read outbox status", zap.Error(err))
+		return &pb.OutboxStatus{Error: err.Error()}
+	}
+
+	status := &pb.OutboxStatus{}
+	for _, topic := range topics {
+		status.Topics = append(status.Topics, &pb.OutboxTopic{
+			Topic:                   topic.Topic,
+			Pending:                 topic.Pending,
+			Failed:                  topic.Failed,
+			OldestPendingAgeSeconds: topic.OldestPending.Seconds(),
+		})
+	}
+	for _, event := range failed {
+		status.RecentFailures = append(status.RecentFailures, &pb.FailedEvent{
+			Id:          event.ID,
+			Topic:       event.Topic,
+			EventType:   event.EventType,
+			AggregateId: event.AggregateID,
+			Attempts:    int32(event.Attempts),
+			LastError:   event.LastError,
+			CreatedAt:   event.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return status
+}
+
+func (s *Server) groupStatus(ctx context.Context, group ConsumerGroup) *pb.ConsumerGroupStatus {
+	status := &pb.ConsumerGroupStatus{GroupId: group.ID}
+
+	lags, err := kafka.GroupLag(s.kafka, group.ID, group.Topics)
+	if err != nil {
+		mylogger.Warn(ctx, s.logger, "Failed to read consumer lag", zap.String("group_id", group.ID), zap.Error(err))
+		status.Error = err.Error()
+		return status
+	}
+
+	for _, lag := range lags {
+		status.TotalLag += lag.Lag
+		status.Partitions = append(status.Partitions, &pb.PartitionLag{
+			Topic:     lag.Topic,
+			Partition: lag.Partition,
+			Committed: lag.Committed,
+			Newest:    lag.Newest,
+			Lag:       lag.Lag,
+		})
+	}
+
+	return status
+}
+
+// TraceEvents reads the outbox events of the aggregate and correlations in
+// req. A service without an outbox answers with no events.
+func (s *Server) TraceEvents(ctx context.Context, req *pb.TraceEventsRequest) (*pb.TraceEventsResponse, error) {
+	res := &pb.TraceEventsResponse{Service: s.service}
+	if s.pool == nil {
+		return res, nil
+	}
+
+	for _, id := range req.CorrelationIds {
+		if _, err := uuid.Parse(id); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "correlation id %q is not a UUID", id)
+		}
+	}
+
+	events, err := repository.Trace(ctx, s.pool, req.AggregateType, req.AggregateId, req.CorrelationIds, traceLimit+1)
+	if err != nil {
+		mylogger.Warn(ctx, s.logger, "Failed to trace outbox events", zap.String("aggregate_id", req.AggregateId), zap.Error(err))
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if len(events) > traceLimit {
+		events, res.Truncated = events[:traceLimit], true
+	}
+
+	for _, event := range events {
+		traced := &pb.TracedEvent{
+			Id:            event.Id,
+			EventId:       event.EventID,
+			EventType:     event.EventType,
+			Topic:         event.Topic,
+			AggregateType: event.AggregateType,
+			AggregateId:   event.AggregateID,
+			Status:        event.Status,
+			Attempts:      int32(event.Attempts),
+			CreatedAt:     event.CreatedAt.Format(time.RFC3339Nano),
+			Producer:      event.Producer,
+			CorrelationId: event.CorrelationID,
+			CausationId:   event.CausationID,
+			TenantId:      event.TenantID,
+			TraceId:       event.TraceID,
+			Offset:        -1,
+		}
+		if event.LastError != nil {
+			traced.LastError = *event.LastError
+		}
+		if event.PublishedAt != nil {
+			traced.PublishedAt = event.PublishedAt.Format(time.RFC3339Nano)
+		}
+		if event.KafkaPartition != nil && event.KafkaOffset != nil {
+			traced.Partition, traced.Offset = *event.KafkaPartition, *event.KafkaOffset
+		}
+
+		res.Events = append(res.Events, traced)
+	}
+
+	return res, nil
+}
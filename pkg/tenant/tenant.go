@@ -0,0 +1,128 @@
+// Package tenant carries the storefront a request or event belongs to.
+//
+// The gateway resolves the tenant once per request and forwards it to the
+// services as gRPC metadata. Services keep it on the context, repositories
+// scope every query by it and the outbox stamps it on emitted events, so a
+// consumer handles an event in the tenant that produced it.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// DefaultID is the tenant of deployments that run a single storefront
+	// and of data created before tenants existed.
+	DefaultID = "default"
+
+	// MetadataKey is the gRPC metadata key carrying the tenant.
+	MetadataKey = "x-tenant-id"
+)
+
+var ErrInvalidID = errors.New("invalid tenant id")
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Validate accepts lowercase slugs, e.g. "default" or "shop-eu".
+func Validate(id string) error {
+	if !idPattern.MatchString(id) {
+		return ErrInvalidID
+	}
+
+	return nil
+}
+
+type ctxKey struct{}
+
+// WithID records the tenant on ctx. An empty id leaves ctx unchanged.
+func WithID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// FromContext returns the tenant on ctx, falling back to DefaultID.
+func FromContext(ctx context.Context) string {
+	if id, ok := ctx.Value(ctxKey{}).(string); ok && id != "" {
+		return id
+	}
+
+	return DefaultID
+}
+
+// UnaryServerInterceptor moves the tenant from incoming metadata onto the
+// context. Calls without one run in DefaultID.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, ok := metadata.FromIncomingContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		values := md.Get(MetadataKey)
+		if len(values) == 0 {
+			return handler(ctx, req)
+		}
+
+		if err := Validate(values[0]); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return handler(WithID(ctx, values[0]), req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming calls.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		md, ok := metadata.FromIncomingContext(ss.Context())
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		values := md.Get(MetadataKey)
+		if len(values) == 0 {
+			return handler(srv, ss)
+		}
+
+		if err := Validate(values[0]); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: WithID(ss.Context(), values[0])})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// UnaryClientInterceptor forwards the tenant on ctx to the called service.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, FromContext(ctx))
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streaming calls.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx = metadata.AppendToOutgoingContext(ctx, MetadataKey, FromContext(ctx))
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
@@ -0,0 +1,98 @@
+package testsuite
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// leakPatterns match secrets that must never reach a log unmasked, under
+// whatever key or in whatever message they end up.
+var leakPatterns = map[string]*regexp.Regexp{
+	"jwt":   regexp.MustCompile(`eyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]+`),
+	"email": regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`),
+}
+
+// logCapture holds what one test logged and the values it must not have.
+type logCapture struct {
+	mu        sync.Mutex
+	buf       bytes.Buffer
+	forbidden []string
+}
+
+func (c *logCapture) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.buf.Write(p)
+}
+
+func (c *logCapture) Sync() error {
+	return nil
+}
+
+// Logger returns a logger for the current test that masks the same keys as
+// the services' loggers and fails the test if a raw secret still reaches
+// its output: anything that looks like a JWT or an email, or a value passed
+// to ForbidInLogs. Call it from SetupTest.
+func (s *BaseSuite) Logger() *zap.Logger {
+	capture := &logCapture{}
+	s.logs = capture
+
+	core := zapcore.NewCore(
+		zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()),
+		capture,
+		zapcore.DebugLevel,
+	)
+
+	t := s.T()
+	t.Cleanup(func() {
+		checkLogs(t, capture)
+	})
+
+	return zap.New(mylogger.NewRedactCore(core, mylogger.DefaultSensitiveKeys...))
+}
+
+// ForbidInLogs fails the current test if any of values is logged, for
+// secrets no pattern recognizes such as opaque refresh tokens.
+func (s *BaseSuite) ForbidInLogs(values ...string) {
+	if s.logs == nil {
+		return
+	}
+
+	s.logs.mu.Lock()
+	defer s.logs.mu.Unlock()
+
+	for _, value := range values {
+		if value != "" {
+			s.logs.forbidden = append(s.logs.forbidden, value)
+		}
+	}
+}
+
+// checkLogs reports to t, not s.T(): by the time cleanups run the suite has
+// moved on to the next test.
+func checkLogs(t *testing.T, capture *logCapture) {
+	capture.mu.Lock()
+	defer capture.mu.Unlock()
+
+	for _, line := range strings.Split(capture.buf.String(), "\n") {
+		for kind, pattern := range leakPatterns {
+			if match := pattern.FindString(line); match != "" {
+				t.Errorf("raw %s %q logged: %s", kind, match, line)
+			}
+		}
+
+		for _, value := range capture.forbidden {
+			if strings.Contains(line, value) {
+				t.Errorf("forbidden value %q logged: %s", value, line)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package testsuite
+
+import (
+	"flag"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Latencies are the durations of repeated runs of one operation.
+type Latencies []time.Duration
+
+// Measure runs fn warmup times unmeasured, to fill caches and connection
+// pools, then runs more times and records how long each run took.
+func Measure(warmup, runs int, fn func() error) (Latencies, error) {
+	for range warmup {
+		if err := fn(); err != nil {
+			return nil, err
+		}
+	}
+
+	latencies := make(Latencies, 0, runs)
+	for range runs {
+		start := time.Now()
+		if err := fn(); err != nil {
+			return nil, err
+		}
+		latencies = append(latencies, time.Since(start))
+	}
+
+	return latencies, nil
+}
+
+// Percentile returns the duration p percent of the runs did not exceed,
+// using the nearest rank.
+func (l Latencies) Percentile(p float64) time.Duration {
+	if len(l) == 0 {
+		return 0
+	}
+
+	sorted := slices.Clone(l)
+	slices.Sort(sorted)
+
+	rank := int(p/100*float64(len(sorted))+0.5) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// BudgetScale stretches every latency budget by PERF_BUDGET_SCALE, for
+// machines slower than the ones the budgets were set on. It defaults to 1.
+func BudgetScale() float64 {
+	scale, err := strconv.ParseFloat(os.Getenv("PERF_BUDGET_SCALE"), 64)
+	if err != nil || scale <= 0 {
+		return 1
+	}
+
+	return scale
+}
+
+// RequireP95 fails the test when the 95th percentile of runs of fn goes over
+// budget. It is skipped with -short, since latency on a loaded machine says
+// little.
+func (s *BaseSuite) RequireP95(name string, budget time.Duration, runs int, fn func() error) {
+	if testing.Short() {
+		s.T().Skipf("%s: latency budgets are not checked with -short", name)
+	}
+
+	latencies, err := Measure(max(runs/10, 1), runs, fn)
+	s.Require().NoError(err)
+
+	limit := time.Duration(float64(budget) * BudgetScale())
+	p95 := latencies.Percentile(95)
+
+	s.T().Logf("%s: p50=%s p95=%s p99=%s over %d runs, budget %s",
+		name, latencies.Percentile(50), p95, latencies.Percentile(99), runs, limit)
+	s.Require().LessOrEqualf(p95, limit, "%s: p95 latency %s is over the %s budget", name, p95, limit)
+}
+
+// Benchmark runs fn as a Go benchmark against the suite's infrastructure and
+// logs the result like `go test -bench` would. It only runs when go test is
+// given -bench, so plain test runs stay fast:
+//
+//	go test ./tests -run TestIntegrationSuite/TestPerf -bench . -v
+func (s *BaseSuite) Benchmark(name string, fn func(b *testing.B)) {
+	if f := flag.Lookup("test.bench"); f == nil || f.Value.String() == "" {
+		return
+	}
+
+	result := testing.Benchmark(fn)
+	s.T().Logf("Benchmark%s\t%s\t%s", name, result, result.MemString())
+}
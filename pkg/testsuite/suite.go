@@ -2,13 +2,21 @@ package testsuite
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
+	"net/url"
+	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
-	"github.com/golang-migrate/migrate/v4"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/pressly/goose/v3"
 	"github.com/stretchr/testify/suite"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/kafka"
@@ -16,64 +24,200 @@ import (
 	"github.com/testcontainers/testcontainers-go/wait"
 )
 
+// Names of the containers TESTSUITE_REUSE shares between packages.
+const (
+	reusedPostgresName = "go-pet-project-test-postgres"
+	reusedKafkaName    = "go-pet-project-test-kafka"
+)
+
 type BaseSuite struct {
 	suite.Suite
-	PgContainer    *postgres.PostgresContainer
+	PgContainer *postgres.PostgresContainer
+	// KafkaContainer is nil when the suite uses TESTSUITE_KAFKA_BROKERS.
 	KafkaContainer *kafka.KafkaContainer
 	DbPool         *pgxpool.Pool
 	KafkaBrokers   []string
 	Ctx            context.Context
+
+	logs *logCapture
+	// reused containers belong to the whole test run; Ryuk removes them
+	// once the last package is done.
+	reused bool
 }
 
+// SetupInfrastructure starts Postgres, migrated with migrationsRelPath, and
+// Kafka side by side. Two environment variables make a test run cheaper:
+//
+//   - TESTSUITE_REUSE=true shares one Postgres and one Kafka container
+//     between the packages of a go test invocation, each package getting a
+//     database of its own. Run the suites in one invocation to benefit, as
+//     `make test-all` does.
+//   - TESTSUITE_KAFKA_BROKERS=host:port,... uses a Kafka that is already
+//     running, e.g. the docker-compose one, instead of starting one.
+//
+// Suites only produce to Kafka, so sharing it between them is safe.
 func (s *BaseSuite) SetupInfrastructure(migrationsRelPath string) {
 	s.Ctx = context.Background()
+	s.reused = os.Getenv("TESTSUITE_REUSE") == "true"
 
-	var err error
-	s.PgContainer, err = postgres.Run(
-		s.Ctx,
-		"postgres:17-alpine",
+	absPath, err := filepath.Abs(migrationsRelPath)
+	s.Require().NoError(err)
+
+	var (
+		wg                 sync.WaitGroup
+		connStr            string
+		pgErr, kafkaErr    error
+		sharedKafkaBrokers = os.Getenv("TESTSUITE_KAFKA_BROKERS")
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		connStr, pgErr = s.startPostgres(absPath)
+	}()
+
+	if sharedKafkaBrokers != "" {
+		s.KafkaBrokers = strings.Split(sharedKafkaBrokers, ",")
+	} else {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			kafkaErr = s.startKafka()
+		}()
+	}
+
+	wg.Wait()
+	s.Require().NoError(pgErr, "failed to start postgres")
+	s.Require().NoError(kafkaErr, "failed to start kafka")
+
+	s.DbPool, err = pgxpool.New(s.Ctx, connStr)
+	s.Require().NoError(err)
+}
+
+// startPostgres starts Postgres, gives the suite a fresh database when the
+// container is shared and migrates it. It returns the database's URL.
+func (s *BaseSuite) startPostgres(migrationsPath string) (string, error) {
+	opts := []testcontainers.ContainerCustomizer{
 		postgres.WithDatabase("test_db"),
 		postgres.WithUsername("test_user"),
 		postgres.WithPassword("test_password"),
 		testcontainers.WithWaitStrategy(
 			wait.ForLog("database system is ready to accept connections").
 				WithOccurrence(2).
-				WithStartupTimeout(5*time.Second),
+				WithStartupTimeout(5 * time.Second),
 		),
-	)
-	s.Require().NoError(err)
+	}
+	if s.reused {
+		opts = append(opts, testcontainers.WithReuseByName(reusedPostgresName))
+	}
+
+	var err error
+	s.PgContainer, err = postgres.Run(s.Ctx, "postgres:17-alpine", opts...)
+	if err != nil {
+		return "", err
+	}
 
 	connStr, err := s.PgContainer.ConnectionString(s.Ctx, "sslmode=disable")
-	s.Require().NoError(err)
+	if err != nil {
+		return "", err
+	}
 
-	s.KafkaContainer, err = kafka.Run(
-		s.Ctx,
-		"confluentinc/cp-kafka:7.5.0",
-		kafka.WithClusterID("test-cluster"),
-	)
-	s.Require().NoError(err)
+	if s.reused {
+		connStr, err = s.createDatabase(connStr, suiteDatabaseName(migrationsPath))
+		if err != nil {
+			return "", err
+		}
+	}
+
+	log.Printf("🔨 Running migrations from: %s", migrationsPath)
+	if err := migrateUp(s.Ctx, connStr, migrationsPath); err != nil {
+		return "", fmt.Errorf("failed to migrate: %w", err)
+	}
+
+	return connStr, nil
+}
+
+// migrateUp applies the migrations with goose, like `migrate up` does in
+// production, so the suites see the same schema.
+func migrateUp(ctx context.Context, connStr, dir string) error {
+	db, err := sql.Open("pgx", connStr)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	provider, err := goose.NewProvider(goose.DialectPostgres, db, os.DirFS(dir))
+	if err != nil {
+		return err
+	}
+
+	_, err = provider.Up(ctx)
+
+	return err
+}
+
+func (s *BaseSuite) startKafka() error {
+	opts := []testcontainers.ContainerCustomizer{kafka.WithClusterID("test-cluster")}
+	if s.reused {
+		opts = append(opts, testcontainers.WithReuseByName(reusedKafkaName))
+	}
+
+	var err error
+	s.KafkaContainer, err = kafka.Run(s.Ctx, "confluentinc/cp-kafka:7.5.0", opts...)
+	if err != nil {
+		return err
+	}
 
 	s.KafkaBrokers, err = s.KafkaContainer.Brokers(s.Ctx)
-	s.Require().NoError(err)
 
-	absPath, err := filepath.Abs(migrationsRelPath)
-	s.Require().NoError(err)
+	return err
+}
 
-	sourceURL := "file://" + absPath
-	log.Printf("🔨 Running migrations from: %s", sourceURL)
+var unsafeDatabaseChars = regexp.MustCompile(`[^a-z0-9_]+`)
 
-	m, err := migrate.New(sourceURL, connStr)
-	s.Require().NoError(err)
-	s.Require().NoError(m.Up())
+// suiteDatabaseName names the database of the suite migrated with
+// migrationsPath after its service, e.g. auth_test for
+// services/auth/migrations.
+func suiteDatabaseName(migrationsPath string) string {
+	service := strings.ToLower(filepath.Base(filepath.Dir(migrationsPath)))
 
-	s.DbPool, err = pgxpool.New(s.Ctx, connStr)
-	s.Require().NoError(err)
+	return unsafeDatabaseChars.ReplaceAllString(service, "_") + "_test"
+}
+
+// createDatabase drops what an earlier package of the run left in the
+// database called name, creates it afresh and returns its URL.
+func (s *BaseSuite) createDatabase(connStr, name string) (string, error) {
+	conn, err := pgx.Connect(s.Ctx, connStr)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close(s.Ctx)
+
+	ident := pgx.Identifier{name}.Sanitize()
+	if _, err := conn.Exec(s.Ctx, "DROP DATABASE IF EXISTS "+ident+" WITH (FORCE)"); err != nil {
+		return "", fmt.Errorf("failed to drop database %s: %w", name, err)
+	}
+	if _, err := conn.Exec(s.Ctx, "CREATE DATABASE "+ident); err != nil {
+		return "", fmt.Errorf("failed to create database %s: %w", name, err)
+	}
+
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return "", err
+	}
+	u.Path = "/" + name
+
+	return u.String(), nil
 }
 
 func (s *BaseSuite) TearDownInfrastructure() {
 	if s.DbPool != nil {
 		s.DbPool.Close()
 	}
+	if s.reused {
+		return
+	}
+
 	if s.PgContainer != nil {
 		if err := s.PgContainer.Terminate(s.Ctx); err != nil {
 			log.Printf("Failed to terminate postgres container: %v", err)
@@ -21,6 +21,8 @@ func FormatValidationError(err error) map[string]string {
 			errors[field] = fmt.Sprintf("%s must be greater than %s", field, err.Param())
 		case "gte":
 			errors[field] = fmt.Sprintf("%s must be greater than or equal to %s", field, err.Param())
+		case "lte":
+			errors[field] = fmt.Sprintf("%s must be less than or equal to %s", field, err.Param())
 		case "url":
 			errors[field] = fmt.Sprintf("%s must be a valid URL", field)
 		default:
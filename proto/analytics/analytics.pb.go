@@ -0,0 +1,665 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.2
+// source: proto/analytics/analytics.proto
+
+package analytics
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Dates are YYYY-MM-DD in UTC, both ends inclusive.
+type DateRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DateRange) Reset() {
+	*x = DateRange{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DateRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DateRange) ProtoMessage() {}
+
+func (x *DateRange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DateRange.ProtoReflect.Descriptor instead.
+func (*DateRange) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DateRange) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *DateRange) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type GetOrderVolumeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Range         *DateRange             `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderVolumeRequest) Reset() {
+	*x = GetOrderVolumeRequest{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderVolumeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderVolumeRequest) ProtoMessage() {}
+
+func (x *GetOrderVolumeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderVolumeRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderVolumeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetOrderVolumeRequest) GetRange() *DateRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+type DailyVolume struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Day             string                 `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Orders          int64                  `protobuf:"varint,2,opt,name=orders,proto3" json:"orders,omitempty"`
+	PaidOrders      int64                  `protobuf:"varint,3,opt,name=paid_orders,json=paidOrders,proto3" json:"paid_orders,omitempty"`
+	CancelledOrders int64                  `protobuf:"varint,4,opt,name=cancelled_orders,json=cancelledOrders,proto3" json:"cancelled_orders,omitempty"`
+	Revenue         int64                  `protobuf:"varint,5,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	FailedPayments  int64                  `protobuf:"varint,6,opt,name=failed_payments,json=failedPayments,proto3" json:"failed_payments,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DailyVolume) Reset() {
+	*x = DailyVolume{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyVolume) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyVolume) ProtoMessage() {}
+
+func (x *DailyVolume) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyVolume.ProtoReflect.Descriptor instead.
+func (*DailyVolume) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DailyVolume) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *DailyVolume) GetOrders() int64 {
+	if x != nil {
+		return x.Orders
+	}
+	return 0
+}
+
+func (x *DailyVolume) GetPaidOrders() int64 {
+	if x != nil {
+		return x.PaidOrders
+	}
+	return 0
+}
+
+func (x *DailyVolume) GetCancelledOrders() int64 {
+	if x != nil {
+		return x.CancelledOrders
+	}
+	return 0
+}
+
+func (x *DailyVolume) GetRevenue() int64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+func (x *DailyVolume) GetFailedPayments() int64 {
+	if x != nil {
+		return x.FailedPayments
+	}
+	return 0
+}
+
+type GetOrderVolumeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Days          []*DailyVolume         `protobuf:"bytes,1,rep,name=days,proto3" json:"days,omitempty"`
+	TotalOrders   int64                  `protobuf:"varint,2,opt,name=total_orders,json=totalOrders,proto3" json:"total_orders,omitempty"`
+	TotalRevenue  int64                  `protobuf:"varint,3,opt,name=total_revenue,json=totalRevenue,proto3" json:"total_revenue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderVolumeResponse) Reset() {
+	*x = GetOrderVolumeResponse{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderVolumeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderVolumeResponse) ProtoMessage() {}
+
+func (x *GetOrderVolumeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderVolumeResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderVolumeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetOrderVolumeResponse) GetDays() []*DailyVolume {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *GetOrderVolumeResponse) GetTotalOrders() int64 {
+	if x != nil {
+		return x.TotalOrders
+	}
+	return 0
+}
+
+func (x *GetOrderVolumeResponse) GetTotalRevenue() int64 {
+	if x != nil {
+		return x.TotalRevenue
+	}
+	return 0
+}
+
+type GetFunnelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Range         *DateRange             `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFunnelRequest) Reset() {
+	*x = GetFunnelRequest{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFunnelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFunnelRequest) ProtoMessage() {}
+
+func (x *GetFunnelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFunnelRequest.ProtoReflect.Descriptor instead.
+func (*GetFunnelRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetFunnelRequest) GetRange() *DateRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+type GetFunnelResponse struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RegisteredUsers int64                  `protobuf:"varint,1,opt,name=registered_users,json=registeredUsers,proto3" json:"registered_users,omitempty"`
+	OrderingUsers   int64                  `protobuf:"varint,2,opt,name=ordering_users,json=orderingUsers,proto3" json:"ordering_users,omitempty"`
+	OrdersCreated   int64                  `protobuf:"varint,3,opt,name=orders_created,json=ordersCreated,proto3" json:"orders_created,omitempty"`
+	OrdersReserved  int64                  `protobuf:"varint,4,opt,name=orders_reserved,json=ordersReserved,proto3" json:"orders_reserved,omitempty"`
+	OrdersPaid      int64                  `protobuf:"varint,5,opt,name=orders_paid,json=ordersPaid,proto3" json:"orders_paid,omitempty"`
+	ConversionRate  float64                `protobuf:"fixed64,6,opt,name=conversion_rate,json=conversionRate,proto3" json:"conversion_rate,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetFunnelResponse) Reset() {
+	*x = GetFunnelResponse{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFunnelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFunnelResponse) ProtoMessage() {}
+
+func (x *GetFunnelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFunnelResponse.ProtoReflect.Descriptor instead.
+func (*GetFunnelResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetFunnelResponse) GetRegisteredUsers() int64 {
+	if x != nil {
+		return x.RegisteredUsers
+	}
+	return 0
+}
+
+func (x *GetFunnelResponse) GetOrderingUsers() int64 {
+	if x != nil {
+		return x.OrderingUsers
+	}
+	return 0
+}
+
+func (x *GetFunnelResponse) GetOrdersCreated() int64 {
+	if x != nil {
+		return x.OrdersCreated
+	}
+	return 0
+}
+
+func (x *GetFunnelResponse) GetOrdersReserved() int64 {
+	if x != nil {
+		return x.OrdersReserved
+	}
+	return 0
+}
+
+func (x *GetFunnelResponse) GetOrdersPaid() int64 {
+	if x != nil {
+		return x.OrdersPaid
+	}
+	return 0
+}
+
+func (x *GetFunnelResponse) GetConversionRate() float64 {
+	if x != nil {
+		return x.ConversionRate
+	}
+	return 0
+}
+
+type GetTopProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Range         *DateRange             `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopProductsRequest) Reset() {
+	*x = GetTopProductsRequest{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopProductsRequest) ProtoMessage() {}
+
+func (x *GetTopProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopProductsRequest.ProtoReflect.Descriptor instead.
+func (*GetTopProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetTopProductsRequest) GetRange() *DateRange {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+func (x *GetTopProductsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type TopProduct struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Units         int64                  `protobuf:"varint,2,opt,name=units,proto3" json:"units,omitempty"`
+	Orders        int64                  `protobuf:"varint,3,opt,name=orders,proto3" json:"orders,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopProduct) Reset() {
+	*x = TopProduct{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopProduct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopProduct) ProtoMessage() {}
+
+func (x *TopProduct) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopProduct.ProtoReflect.Descriptor instead.
+func (*TopProduct) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TopProduct) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *TopProduct) GetUnits() int64 {
+	if x != nil {
+		return x.Units
+	}
+	return 0
+}
+
+func (x *TopProduct) GetOrders() int64 {
+	if x != nil {
+		return x.Orders
+	}
+	return 0
+}
+
+type GetTopProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*TopProduct          `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTopProductsResponse) Reset() {
+	*x = GetTopProductsResponse{}
+	mi := &file_proto_analytics_analytics_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTopProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTopProductsResponse) ProtoMessage() {}
+
+func (x *GetTopProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_analytics_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTopProductsResponse.ProtoReflect.Descriptor instead.
+func (*GetTopProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_analytics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetTopProductsResponse) GetProducts() []*TopProduct {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+var File_proto_analytics_analytics_proto protoreflect.FileDescriptor
+
+const file_proto_analytics_analytics_proto_rawDesc = "" +
+	"\n" +
+	"\x1fproto/analytics/analytics.proto\"/\n" +
+	"\tDateRange\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\"9\n" +
+	"\x15GetOrderVolumeRequest\x12 \n" +
+	"\x05range\x18\x01 \x01(\v2\n" +
+	".DateRangeR\x05range\"\xc6\x01\n" +
+	"\vDailyVolume\x12\x10\n" +
+	"\x03day\x18\x01 \x01(\tR\x03day\x12\x16\n" +
+	"\x06orders\x18\x02 \x01(\x03R\x06orders\x12\x1f\n" +
+	"\vpaid_orders\x18\x03 \x01(\x03R\n" +
+	"paidOrders\x12)\n" +
+	"\x10cancelled_orders\x18\x04 \x01(\x03R\x0fcancelledOrders\x12\x18\n" +
+	"\arevenue\x18\x05 \x01(\x03R\arevenue\x12'\n" +
+	"\x0ffailed_payments\x18\x06 \x01(\x03R\x0efailedPayments\"\x82\x01\n" +
+	"\x16GetOrderVolumeResponse\x12 \n" +
+	"\x04days\x18\x01 \x03(\v2\f.DailyVolumeR\x04days\x12!\n" +
+	"\ftotal_orders\x18\x02 \x01(\x03R\vtotalOrders\x12#\n" +
+	"\rtotal_revenue\x18\x03 \x01(\x03R\ftotalRevenue\"4\n" +
+	"\x10GetFunnelRequest\x12 \n" +
+	"\x05range\x18\x01 \x01(\v2\n" +
+	".DateRangeR\x05range\"\xff\x01\n" +
+	"\x11GetFunnelResponse\x12)\n" +
+	"\x10registered_users\x18\x01 \x01(\x03R\x0fregisteredUsers\x12%\n" +
+	"\x0eordering_users\x18\x02 \x01(\x03R\rorderingUsers\x12%\n" +
+	"\x0eorders_created\x18\x03 \x01(\x03R\rordersCreated\x12'\n" +
+	"\x0forders_reserved\x18\x04 \x01(\x03R\x0eordersReserved\x12\x1f\n" +
+	"\vorders_paid\x18\x05 \x01(\x03R\n" +
+	"ordersPaid\x12'\n" +
+	"\x0fconversion_rate\x18\x06 \x01(\x01R\x0econversionRate\"O\n" +
+	"\x15GetTopProductsRequest\x12 \n" +
+	"\x05range\x18\x01 \x01(\v2\n" +
+	".DateRangeR\x05range\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\"Y\n" +
+	"\n" +
+	"TopProduct\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x14\n" +
+	"\x05units\x18\x02 \x01(\x03R\x05units\x12\x16\n" +
+	"\x06orders\x18\x03 \x01(\x03R\x06orders\"A\n" +
+	"\x16GetTopProductsResponse\x12'\n" +
+	"\bproducts\x18\x01 \x03(\v2\v.TopProductR\bproducts2\xcc\x01\n" +
+	"\x10AnalyticsService\x12A\n" +
+	"\x0eGetOrderVolume\x12\x16.GetOrderVolumeRequest\x1a\x17.GetOrderVolumeResponse\x122\n" +
+	"\tGetFunnel\x12\x11.GetFunnelRequest\x1a\x12.GetFunnelResponse\x12A\n" +
+	"\x0eGetTopProducts\x12\x16.GetTopProductsRequest\x1a\x17.GetTopProductsResponseB6Z4github.com/sakashimaa/go-pet-project/proto/analyticsb\x06proto3"
+
+var (
+	file_proto_analytics_analytics_proto_rawDescOnce sync.Once
+	file_proto_analytics_analytics_proto_rawDescData []byte
+)
+
+func file_proto_analytics_analytics_proto_rawDescGZIP() []byte {
+	file_proto_analytics_analytics_proto_rawDescOnce.Do(func() {
+		file_proto_analytics_analytics_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_analytics_analytics_proto_rawDesc), len(file_proto_analytics_analytics_proto_rawDesc)))
+	})
+	return file_proto_analytics_analytics_proto_rawDescData
+}
+
+var file_proto_analytics_analytics_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_proto_analytics_analytics_proto_goTypes = []any{
+	(*DateRange)(nil),              // 0: DateRange
+	(*GetOrderVolumeRequest)(nil),  // 1: GetOrderVolumeRequest
+	(*DailyVolume)(nil),            // 2: DailyVolume
+	(*GetOrderVolumeResponse)(nil), // 3: GetOrderVolumeResponse
+	(*GetFunnelRequest)(nil),       // 4: GetFunnelRequest
+	(*GetFunnelResponse)(nil),      // 5: GetFunnelResponse
+	(*GetTopProductsRequest)(nil),  // 6: GetTopProductsRequest
+	(*TopProduct)(nil),             // 7: TopProduct
+	(*GetTopProductsResponse)(nil), // 8: GetTopProductsResponse
+}
+var file_proto_analytics_analytics_proto_depIdxs = []int32{
+	0, // 0: GetOrderVolumeRequest.range:type_name -> DateRange
+	2, // 1: GetOrderVolumeResponse.days:type_name -> DailyVolume
+	0, // 2: GetFunnelRequest.range:type_name -> DateRange
+	0, // 3: GetTopProductsRequest.range:type_name -> DateRange
+	7, // 4: GetTopProductsResponse.products:type_name -> TopProduct
+	1, // 5: AnalyticsService.GetOrderVolume:input_type -> GetOrderVolumeRequest
+	4, // 6: AnalyticsService.GetFunnel:input_type -> GetFunnelRequest
+	6, // 7: AnalyticsService.GetTopProducts:input_type -> GetTopProductsRequest
+	3, // 8: AnalyticsService.GetOrderVolume:output_type -> GetOrderVolumeResponse
+	5, // 9: AnalyticsService.GetFunnel:output_type -> GetFunnelResponse
+	8, // 10: AnalyticsService.GetTopProducts:output_type -> GetTopProductsResponse
+	8, // [8:11] is the sub-list for method output_type
+	5, // [5:8] is the sub-list for method input_type
+	5, // [5:5] is the sub-list for extension type_name
+	5, // [5:5] is the sub-list for extension extendee
+	0, // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_proto_analytics_analytics_proto_init() }
+func file_proto_analytics_analytics_proto_init() {
+	if File_proto_analytics_analytics_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_analytics_analytics_proto_rawDesc), len(file_proto_analytics_analytics_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_analytics_analytics_proto_goTypes,
+		DependencyIndexes: file_proto_analytics_analytics_proto_depIdxs,
+		MessageInfos:      file_proto_analytics_analytics_proto_msgTypes,
+	}.Build()
+	File_proto_analytics_analytics_proto = out.File
+	file_proto_analytics_analytics_proto_goTypes = nil
+	file_proto_analytics_analytics_proto_depIdxs = nil
+}
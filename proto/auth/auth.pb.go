@@ -70,6 +70,8 @@ type UserInfoResponse struct {
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
 	IsActivated   bool                   `protobuf:"varint,3,opt,name=is_activated,json=isActivated,proto3" json:"is_activated,omitempty"`
+	Role          string                 `protobuf:"bytes,4,opt,name=role,proto3" json:"role,omitempty"`
+	Locale        string                 `protobuf:"bytes,5,opt,name=locale,proto3" json:"locale,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,10 +127,25 @@ func (x *UserInfoResponse) GetIsActivated() bool {
 	return false
 }
 
+func (x *UserInfoResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+func (x *UserInfoResponse) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
 type RegisterRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Email         string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
 	Password      string                 `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
+	Locale        string                 `protobuf:"bytes,3,opt,name=locale,proto3" json:"locale,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -177,13 +194,22 @@ func (x *RegisterRequest) GetPassword() string {
 	return ""
 }
 
+func (x *RegisterRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
 type RegisterResponse struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Email           string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
-	CreatedAt       string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
-	UpdatedAt       string                 `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
-	ActivationToken string                 `protobuf:"bytes,5,opt,name=activation_token,json=activationToken,proto3" json:"activation_token,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email     string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	CreatedAt string                 `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt string                 `protobuf:"bytes,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// activation_token is empty unless the auth service runs with
+	// EXPOSE_ACTIVATION_TOKEN=true, which is meant for test environments only.
+	ActivationToken string `protobuf:"bytes,5,opt,name=activation_token,json=activationToken,proto3" json:"activation_token,omitempty"`
 	unknownFields   protoimpl.UnknownFields
 	sizeCache       protoimpl.SizeCache
 }
@@ -405,6 +431,7 @@ type ValidateResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	IsActivated   bool                   `protobuf:"varint,2,opt,name=is_activated,json=isActivated,proto3" json:"is_activated,omitempty"`
+	Role          string                 `protobuf:"bytes,3,opt,name=role,proto3" json:"role,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -453,6 +480,13 @@ func (x *ValidateResponse) GetIsActivated() bool {
 	return false
 }
 
+func (x *ValidateResponse) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
 type RefreshRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	RefreshToken  string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
@@ -917,20 +951,427 @@ func (x *ResetPasswordResponse) GetSuccess() bool {
 	return false
 }
 
+type ChangeUserRoleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          string                 `protobuf:"bytes,2,opt,name=role,proto3" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeUserRoleRequest) Reset() {
+	*x = ChangeUserRoleRequest{}
+	mi := &file_proto_auth_auth_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeUserRoleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeUserRoleRequest) ProtoMessage() {}
+
+func (x *ChangeUserRoleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_auth_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeUserRoleRequest.ProtoReflect.Descriptor instead.
+func (*ChangeUserRoleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_auth_auth_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ChangeUserRoleRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ChangeUserRoleRequest) GetRole() string {
+	if x != nil {
+		return x.Role
+	}
+	return ""
+}
+
+type ChangeUserRoleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeUserRoleResponse) Reset() {
+	*x = ChangeUserRoleResponse{}
+	mi := &file_proto_auth_auth_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeUserRoleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeUserRoleResponse) ProtoMessage() {}
+
+func (x *ChangeUserRoleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_auth_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeUserRoleResponse.ProtoReflect.Descriptor instead.
+func (*ChangeUserRoleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_auth_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ChangeUserRoleResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type UpdateLocaleRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Locale        string                 `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLocaleRequest) Reset() {
+	*x = UpdateLocaleRequest{}
+	mi := &file_proto_auth_auth_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLocaleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLocaleRequest) ProtoMessage() {}
+
+func (x *UpdateLocaleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_auth_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLocaleRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLocaleRequest) Descriptor() ([]byte, []int) {
+	return file_proto_auth_auth_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UpdateLocaleRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *UpdateLocaleRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+type UpdateLocaleResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLocaleResponse) Reset() {
+	*x = UpdateLocaleResponse{}
+	mi := &file_proto_auth_auth_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLocaleResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLocaleResponse) ProtoMessage() {}
+
+func (x *UpdateLocaleResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_auth_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLocaleResponse.ProtoReflect.Descriptor instead.
+func (*UpdateLocaleResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_auth_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UpdateLocaleResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type ImportUserRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Email string                 `protobuf:"bytes,1,opt,name=email,proto3" json:"email,omitempty"`
+	// password_hash as the old system stored it, e.g. $2a$... or
+	// $argon2id$v=19$....
+	PasswordHash string `protobuf:"bytes,2,opt,name=password_hash,json=passwordHash,proto3" json:"password_hash,omitempty"`
+	IsActivated  bool   `protobuf:"varint,3,opt,name=is_activated,json=isActivated,proto3" json:"is_activated,omitempty"`
+	// Empty means en.
+	Locale        string `protobuf:"bytes,4,opt,name=locale,proto3" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportUserRecord) Reset() {
+	*x = ImportUserRecord{}
+	mi := &file_proto_auth_auth_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportUserRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportUserRecord) ProtoMessage() {}
+
+func (x *ImportUserRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_auth_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportUserRecord.ProtoReflect.Descriptor instead.
+func (*ImportUserRecord) Descriptor() ([]byte, []int) {
+	return file_proto_auth_auth_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ImportUserRecord) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ImportUserRecord) GetPasswordHash() string {
+	if x != nil {
+		return x.PasswordHash
+	}
+	return ""
+}
+
+func (x *ImportUserRecord) GetIsActivated() bool {
+	if x != nil {
+		return x.IsActivated
+	}
+	return false
+}
+
+func (x *ImportUserRecord) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+// ImportFailure is a record that was not imported. index counts the records
+// of the stream from 0.
+type ImportFailure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int64                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	Email         string                 `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportFailure) Reset() {
+	*x = ImportFailure{}
+	mi := &file_proto_auth_auth_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportFailure) ProtoMessage() {}
+
+func (x *ImportFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_auth_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportFailure.ProtoReflect.Descriptor instead.
+func (*ImportFailure) Descriptor() ([]byte, []int) {
+	return file_proto_auth_auth_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ImportFailure) GetIndex() int64 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ImportFailure) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *ImportFailure) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ImportUsersResponse struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Imported int64                  `protobuf:"varint,1,opt,name=imported,proto3" json:"imported,omitempty"`
+	// Of the imported users, those whose hash was dropped.
+	ResetRequired int64 `protobuf:"varint,2,opt,name=reset_required,json=resetRequired,proto3" json:"reset_required,omitempty"`
+	// Records whose email is already registered.
+	Skipped int64 `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// Invalid records, listed in failures up to the first 100.
+	Failed        int64            `protobuf:"varint,4,opt,name=failed,proto3" json:"failed,omitempty"`
+	Failures      []*ImportFailure `protobuf:"bytes,5,rep,name=failures,proto3" json:"failures,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportUsersResponse) Reset() {
+	*x = ImportUsersResponse{}
+	mi := &file_proto_auth_auth_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportUsersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportUsersResponse) ProtoMessage() {}
+
+func (x *ImportUsersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_auth_auth_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportUsersResponse.ProtoReflect.Descriptor instead.
+func (*ImportUsersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_auth_auth_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ImportUsersResponse) GetImported() int64 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportUsersResponse) GetResetRequired() int64 {
+	if x != nil {
+		return x.ResetRequired
+	}
+	return 0
+}
+
+func (x *ImportUsersResponse) GetSkipped() int64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *ImportUsersResponse) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *ImportUsersResponse) GetFailures() []*ImportFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
 var File_proto_auth_auth_proto protoreflect.FileDescriptor
 
 const file_proto_auth_auth_proto_rawDesc = "" +
 	"\n" +
 	"\x15proto/auth/auth.proto\x12\x04auth\"*\n" +
 	"\x0fUserInfoRequest\x12\x17\n" +
-	"\auser_id\x18\x01 \x01(\x03R\x06userId\"c\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"\x8f\x01\n" +
 	"\x10UserInfoResponse\x12\x16\n" +
 	"\x06status\x18\x01 \x01(\tR\x06status\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12!\n" +
-	"\fis_activated\x18\x03 \x01(\bR\visActivated\"C\n" +
+	"\fis_activated\x18\x03 \x01(\bR\visActivated\x12\x12\n" +
+	"\x04role\x18\x04 \x01(\tR\x04role\x12\x16\n" +
+	"\x06locale\x18\x05 \x01(\tR\x06locale\"[\n" +
 	"\x0fRegisterRequest\x12\x14\n" +
 	"\x05email\x18\x01 \x01(\tR\x05email\x12\x1a\n" +
-	"\bpassword\x18\x02 \x01(\tR\bpassword\"\xa1\x01\n" +
+	"\bpassword\x18\x02 \x01(\tR\bpassword\x12\x16\n" +
+	"\x06locale\x18\x03 \x01(\tR\x06locale\"\xa1\x01\n" +
 	"\x10RegisterResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12\x1d\n" +
@@ -946,10 +1387,11 @@ const file_proto_auth_auth_proto_rawDesc = "" +
 	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12#\n" +
 	"\rrefresh_token\x18\x02 \x01(\tR\frefreshToken\"'\n" +
 	"\x0fValidateRequest\x12\x14\n" +
-	"\x05token\x18\x01 \x01(\tR\x05token\"N\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"b\n" +
 	"\x10ValidateResponse\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12!\n" +
-	"\fis_activated\x18\x02 \x01(\bR\visActivated\"5\n" +
+	"\fis_activated\x18\x02 \x01(\bR\visActivated\x12\x12\n" +
+	"\x04role\x18\x03 \x01(\tR\x04role\"5\n" +
 	"\x0eRefreshRequest\x12#\n" +
 	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\"Y\n" +
 	"\x0fRefreshResponse\x12!\n" +
@@ -972,7 +1414,32 @@ const file_proto_auth_auth_proto_rawDesc = "" +
 	"\x05token\x18\x01 \x01(\tR\x05token\x12\x1a\n" +
 	"\bpassword\x18\x02 \x01(\tR\bpassword\"1\n" +
 	"\x15ResetPasswordResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xb8\x04\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"D\n" +
+	"\x15ChangeUserRoleRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x12\n" +
+	"\x04role\x18\x02 \x01(\tR\x04role\"2\n" +
+	"\x16ChangeUserRoleResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"F\n" +
+	"\x13UpdateLocaleRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x16\n" +
+	"\x06locale\x18\x02 \x01(\tR\x06locale\"0\n" +
+	"\x14UpdateLocaleResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x88\x01\n" +
+	"\x10ImportUserRecord\x12\x14\n" +
+	"\x05email\x18\x01 \x01(\tR\x05email\x12#\n" +
+	"\rpassword_hash\x18\x02 \x01(\tR\fpasswordHash\x12!\n" +
+	"\fis_activated\x18\x03 \x01(\bR\visActivated\x12\x16\n" +
+	"\x06locale\x18\x04 \x01(\tR\x06locale\"S\n" +
+	"\rImportFailure\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x03R\x05index\x12\x14\n" +
+	"\x05email\x18\x02 \x01(\tR\x05email\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\xbb\x01\n" +
+	"\x13ImportUsersResponse\x12\x1a\n" +
+	"\bimported\x18\x01 \x01(\x03R\bimported\x12%\n" +
+	"\x0ereset_required\x18\x02 \x01(\x03R\rresetRequired\x12\x18\n" +
+	"\askipped\x18\x03 \x01(\x03R\askipped\x12\x16\n" +
+	"\x06failed\x18\x04 \x01(\x03R\x06failed\x12/\n" +
+	"\bfailures\x18\x05 \x03(\v2\x13.auth.ImportFailureR\bfailures2\x90\x06\n" +
 	"\vAuthService\x12<\n" +
 	"\vGetUserInfo\x12\x15.auth.UserInfoRequest\x1a\x16.auth.UserInfoResponse\x129\n" +
 	"\bRegister\x12\x15.auth.RegisterRequest\x1a\x16.auth.RegisterResponse\x120\n" +
@@ -983,7 +1450,10 @@ const file_proto_auth_auth_proto_rawDesc = "" +
 	"\n" +
 	"VerifyUser\x12\x13.auth.VerifyRequest\x1a\x14.auth.VerifyResponse\x12K\n" +
 	"\x0eForgotPassword\x12\x1b.auth.ForgotPasswordRequest\x1a\x1c.auth.ForgotPasswordResponse\x12H\n" +
-	"\rResetPassword\x12\x1a.auth.ResetPasswordRequest\x1a\x1b.auth.ResetPasswordResponseB1Z/github.com/sakashimaa/go-pet-project/proto/authb\x06proto3"
+	"\rResetPassword\x12\x1a.auth.ResetPasswordRequest\x1a\x1b.auth.ResetPasswordResponse\x12K\n" +
+	"\x0eChangeUserRole\x12\x1b.auth.ChangeUserRoleRequest\x1a\x1c.auth.ChangeUserRoleResponse\x12E\n" +
+	"\fUpdateLocale\x12\x19.auth.UpdateLocaleRequest\x1a\x1a.auth.UpdateLocaleResponse\x12B\n" +
+	"\vImportUsers\x12\x16.auth.ImportUserRecord\x1a\x19.auth.ImportUsersResponse(\x01B1Z/github.com/sakashimaa/go-pet-project/proto/authb\x06proto3"
 
 var (
 	file_proto_auth_auth_proto_rawDescOnce sync.Once
@@ -997,7 +1467,7 @@ func file_proto_auth_auth_proto_rawDescGZIP() []byte {
 	return file_proto_auth_auth_proto_rawDescData
 }
 
-var file_proto_auth_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_proto_auth_auth_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_proto_auth_auth_proto_goTypes = []any{
 	(*UserInfoRequest)(nil),        // 0: auth.UserInfoRequest
 	(*UserInfoResponse)(nil),       // 1: auth.UserInfoResponse
@@ -1017,31 +1487,45 @@ var file_proto_auth_auth_proto_goTypes = []any{
 	(*ForgotPasswordResponse)(nil), // 15: auth.ForgotPasswordResponse
 	(*ResetPasswordRequest)(nil),   // 16: auth.ResetPasswordRequest
 	(*ResetPasswordResponse)(nil),  // 17: auth.ResetPasswordResponse
+	(*ChangeUserRoleRequest)(nil),  // 18: auth.ChangeUserRoleRequest
+	(*ChangeUserRoleResponse)(nil), // 19: auth.ChangeUserRoleResponse
+	(*UpdateLocaleRequest)(nil),    // 20: auth.UpdateLocaleRequest
+	(*UpdateLocaleResponse)(nil),   // 21: auth.UpdateLocaleResponse
+	(*ImportUserRecord)(nil),       // 22: auth.ImportUserRecord
+	(*ImportFailure)(nil),          // 23: auth.ImportFailure
+	(*ImportUsersResponse)(nil),    // 24: auth.ImportUsersResponse
 }
 var file_proto_auth_auth_proto_depIdxs = []int32{
-	0,  // 0: auth.AuthService.GetUserInfo:input_type -> auth.UserInfoRequest
-	2,  // 1: auth.AuthService.Register:input_type -> auth.RegisterRequest
-	4,  // 2: auth.AuthService.Login:input_type -> auth.LoginRequest
-	6,  // 3: auth.AuthService.ValidateUser:input_type -> auth.ValidateRequest
-	8,  // 4: auth.AuthService.RefreshUser:input_type -> auth.RefreshRequest
-	10, // 5: auth.AuthService.Logout:input_type -> auth.LogoutRequest
-	12, // 6: auth.AuthService.VerifyUser:input_type -> auth.VerifyRequest
-	14, // 7: auth.AuthService.ForgotPassword:input_type -> auth.ForgotPasswordRequest
-	16, // 8: auth.AuthService.ResetPassword:input_type -> auth.ResetPasswordRequest
-	1,  // 9: auth.AuthService.GetUserInfo:output_type -> auth.UserInfoResponse
-	3,  // 10: auth.AuthService.Register:output_type -> auth.RegisterResponse
-	5,  // 11: auth.AuthService.Login:output_type -> auth.LoginResponse
-	7,  // 12: auth.AuthService.ValidateUser:output_type -> auth.ValidateResponse
-	9,  // 13: auth.AuthService.RefreshUser:output_type -> auth.RefreshResponse
-	11, // 14: auth.AuthService.Logout:output_type -> auth.LogoutResponse
-	13, // 15: auth.AuthService.VerifyUser:output_type -> auth.VerifyResponse
-	15, // 16: auth.AuthService.ForgotPassword:output_type -> auth.ForgotPasswordResponse
-	17, // 17: auth.AuthService.ResetPassword:output_type -> auth.ResetPasswordResponse
-	9,  // [9:18] is the sub-list for method output_type
-	0,  // [0:9] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
+	23, // 0: auth.ImportUsersResponse.failures:type_name -> auth.ImportFailure
+	0,  // 1: auth.AuthService.GetUserInfo:input_type -> auth.UserInfoRequest
+	2,  // 2: auth.AuthService.Register:input_type -> auth.RegisterRequest
+	4,  // 3: auth.AuthService.Login:input_type -> auth.LoginRequest
+	6,  // 4: auth.AuthService.ValidateUser:input_type -> auth.ValidateRequest
+	8,  // 5: auth.AuthService.RefreshUser:input_type -> auth.RefreshRequest
+	10, // 6: auth.AuthService.Logout:input_type -> auth.LogoutRequest
+	12, // 7: auth.AuthService.VerifyUser:input_type -> auth.VerifyRequest
+	14, // 8: auth.AuthService.ForgotPassword:input_type -> auth.ForgotPasswordRequest
+	16, // 9: auth.AuthService.ResetPassword:input_type -> auth.ResetPasswordRequest
+	18, // 10: auth.AuthService.ChangeUserRole:input_type -> auth.ChangeUserRoleRequest
+	20, // 11: auth.AuthService.UpdateLocale:input_type -> auth.UpdateLocaleRequest
+	22, // 12: auth.AuthService.ImportUsers:input_type -> auth.ImportUserRecord
+	1,  // 13: auth.AuthService.GetUserInfo:output_type -> auth.UserInfoResponse
+	3,  // 14: auth.AuthService.Register:output_type -> auth.RegisterResponse
+	5,  // 15: auth.AuthService.Login:output_type -> auth.LoginResponse
+	7,  // 16: auth.AuthService.ValidateUser:output_type -> auth.ValidateResponse
+	9,  // 17: auth.AuthService.RefreshUser:output_type -> auth.RefreshResponse
+	11, // 18: auth.AuthService.Logout:output_type -> auth.LogoutResponse
+	13, // 19: auth.AuthService.VerifyUser:output_type -> auth.VerifyResponse
+	15, // 20: auth.AuthService.ForgotPassword:output_type -> auth.ForgotPasswordResponse
+	17, // 21: auth.AuthService.ResetPassword:output_type -> auth.ResetPasswordResponse
+	19, // 22: auth.AuthService.ChangeUserRole:output_type -> auth.ChangeUserRoleResponse
+	21, // 23: auth.AuthService.UpdateLocale:output_type -> auth.UpdateLocaleResponse
+	24, // 24: auth.AuthService.ImportUsers:output_type -> auth.ImportUsersResponse
+	13, // [13:25] is the sub-list for method output_type
+	1,  // [1:13] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_proto_auth_auth_proto_init() }
@@ -1055,7 +1539,7 @@ func file_proto_auth_auth_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_auth_auth_proto_rawDesc), len(file_proto_auth_auth_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   18,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
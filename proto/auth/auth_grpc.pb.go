@@ -28,6 +28,9 @@ const (
 	AuthService_VerifyUser_FullMethodName     = "/auth.AuthService/VerifyUser"
 	AuthService_ForgotPassword_FullMethodName = "/auth.AuthService/ForgotPassword"
 	AuthService_ResetPassword_FullMethodName  = "/auth.AuthService/ResetPassword"
+	AuthService_ChangeUserRole_FullMethodName = "/auth.AuthService/ChangeUserRole"
+	AuthService_UpdateLocale_FullMethodName   = "/auth.AuthService/UpdateLocale"
+	AuthService_ImportUsers_FullMethodName    = "/auth.AuthService/ImportUsers"
 )
 
 // AuthServiceClient is the client API for AuthService service.
@@ -43,6 +46,14 @@ type AuthServiceClient interface {
 	VerifyUser(ctx context.Context, in *VerifyRequest, opts ...grpc.CallOption) (*VerifyResponse, error)
 	ForgotPassword(ctx context.Context, in *ForgotPasswordRequest, opts ...grpc.CallOption) (*ForgotPasswordResponse, error)
 	ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...grpc.CallOption) (*ResetPasswordResponse, error)
+	ChangeUserRole(ctx context.Context, in *ChangeUserRoleRequest, opts ...grpc.CallOption) (*ChangeUserRoleResponse, error)
+	UpdateLocale(ctx context.Context, in *UpdateLocaleRequest, opts ...grpc.CallOption) (*UpdateLocaleResponse, error)
+	// ImportUsers creates users moved over from another system. A bcrypt or
+	// argon2id hash is kept, so the user logs in with their old password; any
+	// other hash is dropped and the user has to reset their password. Emails
+	// already registered are skipped, so an interrupted import can be resent.
+	// For operators only, the gateway does not expose it.
+	ImportUsers(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportUserRecord, ImportUsersResponse], error)
 }
 
 type authServiceClient struct {
@@ -143,6 +154,39 @@ func (c *authServiceClient) ResetPassword(ctx context.Context, in *ResetPassword
 	return out, nil
 }
 
+func (c *authServiceClient) ChangeUserRole(ctx context.Context, in *ChangeUserRoleRequest, opts ...grpc.CallOption) (*ChangeUserRoleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChangeUserRoleResponse)
+	err := c.cc.Invoke(ctx, AuthService_ChangeUserRole_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) UpdateLocale(ctx context.Context, in *UpdateLocaleRequest, opts ...grpc.CallOption) (*UpdateLocaleResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateLocaleResponse)
+	err := c.cc.Invoke(ctx, AuthService_UpdateLocale_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *authServiceClient) ImportUsers(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportUserRecord, ImportUsersResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AuthService_ServiceDesc.Streams[0], AuthService_ImportUsers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportUserRecord, ImportUsersResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthService_ImportUsersClient = grpc.ClientStreamingClient[ImportUserRecord, ImportUsersResponse]
+
 // AuthServiceServer is the server API for AuthService service.
 // All implementations must embed UnimplementedAuthServiceServer
 // for forward compatibility.
@@ -156,6 +200,14 @@ type AuthServiceServer interface {
 	VerifyUser(context.Context, *VerifyRequest) (*VerifyResponse, error)
 	ForgotPassword(context.Context, *ForgotPasswordRequest) (*ForgotPasswordResponse, error)
 	ResetPassword(context.Context, *ResetPasswordRequest) (*ResetPasswordResponse, error)
+	ChangeUserRole(context.Context, *ChangeUserRoleRequest) (*ChangeUserRoleResponse, error)
+	UpdateLocale(context.Context, *UpdateLocaleRequest) (*UpdateLocaleResponse, error)
+	// ImportUsers creates users moved over from another system. A bcrypt or
+	// argon2id hash is kept, so the user logs in with their old password; any
+	// other hash is dropped and the user has to reset their password. Emails
+	// already registered are skipped, so an interrupted import can be resent.
+	// For operators only, the gateway does not expose it.
+	ImportUsers(grpc.ClientStreamingServer[ImportUserRecord, ImportUsersResponse]) error
 	mustEmbedUnimplementedAuthServiceServer()
 }
 
@@ -193,6 +245,15 @@ func (UnimplementedAuthServiceServer) ForgotPassword(context.Context, *ForgotPas
 func (UnimplementedAuthServiceServer) ResetPassword(context.Context, *ResetPasswordRequest) (*ResetPasswordResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method ResetPassword not implemented")
 }
+func (UnimplementedAuthServiceServer) ChangeUserRole(context.Context, *ChangeUserRoleRequest) (*ChangeUserRoleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ChangeUserRole not implemented")
+}
+func (UnimplementedAuthServiceServer) UpdateLocale(context.Context, *UpdateLocaleRequest) (*UpdateLocaleResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateLocale not implemented")
+}
+func (UnimplementedAuthServiceServer) ImportUsers(grpc.ClientStreamingServer[ImportUserRecord, ImportUsersResponse]) error {
+	return status.Error(codes.Unimplemented, "method ImportUsers not implemented")
+}
 func (UnimplementedAuthServiceServer) mustEmbedUnimplementedAuthServiceServer() {}
 func (UnimplementedAuthServiceServer) testEmbeddedByValue()                     {}
 
@@ -376,6 +437,49 @@ func _AuthService_ResetPassword_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AuthService_ChangeUserRole_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChangeUserRoleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).ChangeUserRole(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_ChangeUserRole_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).ChangeUserRole(ctx, req.(*ChangeUserRoleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_UpdateLocale_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateLocaleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthServiceServer).UpdateLocale(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AuthService_UpdateLocale_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthServiceServer).UpdateLocale(ctx, req.(*UpdateLocaleRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AuthService_ImportUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AuthServiceServer).ImportUsers(&grpc.GenericServerStream[ImportUserRecord, ImportUsersResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AuthService_ImportUsersServer = grpc.ClientStreamingServer[ImportUserRecord, ImportUsersResponse]
+
 // AuthService_ServiceDesc is the grpc.ServiceDesc for AuthService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -419,7 +523,21 @@ var AuthService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ResetPassword",
 			Handler:    _AuthService_ResetPassword_Handler,
 		},
+		{
+			MethodName: "ChangeUserRole",
+			Handler:    _AuthService_ChangeUserRole_Handler,
+		},
+		{
+			MethodName: "UpdateLocale",
+			Handler:    _AuthService_UpdateLocale_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportUsers",
+			Handler:       _AuthService_ImportUsers_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/auth/auth.proto",
 }
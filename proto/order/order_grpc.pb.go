@@ -19,7 +19,28 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	OrderService_CreateOrder_FullMethodName = "/OrderService/CreateOrder"
+	OrderService_CreateOrder_FullMethodName               = "/OrderService/CreateOrder"
+	OrderService_ResolvePartialReservation_FullMethodName = "/OrderService/ResolvePartialReservation"
+	OrderService_GetOrderTimeline_FullMethodName          = "/OrderService/GetOrderTimeline"
+	OrderService_ListUserOrders_FullMethodName            = "/OrderService/ListUserOrders"
+	OrderService_CancelOrder_FullMethodName               = "/OrderService/CancelOrder"
+	OrderService_GetInvoice_FullMethodName                = "/OrderService/GetInvoice"
+	OrderService_ExportOrders_FullMethodName              = "/OrderService/ExportOrders"
+	OrderService_ForceOrderStatus_FullMethodName          = "/OrderService/ForceOrderStatus"
+	OrderService_CreateManualOrder_FullMethodName         = "/OrderService/CreateManualOrder"
+	OrderService_AddAddress_FullMethodName                = "/OrderService/AddAddress"
+	OrderService_ListAddresses_FullMethodName             = "/OrderService/ListAddresses"
+	OrderService_UpdateAddress_FullMethodName             = "/OrderService/UpdateAddress"
+	OrderService_DeleteAddress_FullMethodName             = "/OrderService/DeleteAddress"
+	OrderService_SetPurchaseCap_FullMethodName            = "/OrderService/SetPurchaseCap"
+	OrderService_ListPurchaseCaps_FullMethodName          = "/OrderService/ListPurchaseCaps"
+	OrderService_RequestReturn_FullMethodName             = "/OrderService/RequestReturn"
+	OrderService_ReviewReturn_FullMethodName              = "/OrderService/ReviewReturn"
+	OrderService_ListReturns_FullMethodName               = "/OrderService/ListReturns"
+	OrderService_AddOrderNote_FullMethodName              = "/OrderService/AddOrderNote"
+	OrderService_SetOrderFlag_FullMethodName              = "/OrderService/SetOrderFlag"
+	OrderService_ClearOrderFlag_FullMethodName            = "/OrderService/ClearOrderFlag"
+	OrderService_ListOrderAnnotations_FullMethodName      = "/OrderService/ListOrderAnnotations"
 )
 
 // OrderServiceClient is the client API for OrderService service.
@@ -27,6 +48,49 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type OrderServiceClient interface {
 	CreateOrder(ctx context.Context, in *CreateOrderRequest, opts ...grpc.CallOption) (*CreateOrderResponse, error)
+	ResolvePartialReservation(ctx context.Context, in *ResolvePartialReservationRequest, opts ...grpc.CallOption) (*ResolvePartialReservationResponse, error)
+	GetOrderTimeline(ctx context.Context, in *GetOrderTimelineRequest, opts ...grpc.CallOption) (*GetOrderTimelineResponse, error)
+	ListUserOrders(ctx context.Context, in *ListUserOrdersRequest, opts ...grpc.CallOption) (*ListUserOrdersResponse, error)
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	GetInvoice(ctx context.Context, in *GetInvoiceRequest, opts ...grpc.CallOption) (*GetInvoiceResponse, error)
+	// ExportOrders streams the orders created in a date range as CSV for
+	// accounting. Admin only.
+	ExportOrders(ctx context.Context, in *ExportOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportOrdersChunk], error)
+	// ForceOrderStatus moves a stuck order to another status by hand, e.g.
+	// when the payment event was lost. Admin only; only some transitions can
+	// be forced.
+	ForceOrderStatus(ctx context.Context, in *ForceOrderStatusRequest, opts ...grpc.CallOption) (*ForceOrderStatusResponse, error)
+	// CreateManualOrder places an order on a customer's behalf, e.g. taken by
+	// phone, with prices and a discount set by support. Admin only; every
+	// change to a price needs a reason code and is kept in the audit trail.
+	CreateManualOrder(ctx context.Context, in *CreateManualOrderRequest, opts ...grpc.CallOption) (*CreateOrderResponse, error)
+	AddAddress(ctx context.Context, in *AddAddressRequest, opts ...grpc.CallOption) (*Address, error)
+	ListAddresses(ctx context.Context, in *ListAddressesRequest, opts ...grpc.CallOption) (*ListAddressesResponse, error)
+	UpdateAddress(ctx context.Context, in *UpdateAddressRequest, opts ...grpc.CallOption) (*Address, error)
+	DeleteAddress(ctx context.Context, in *DeleteAddressRequest, opts ...grpc.CallOption) (*DeleteAddressResponse, error)
+	// SetPurchaseCap limits how many units of a product one customer may buy
+	// across all their orders, for limited releases. A max_per_user of 0
+	// lifts the cap. Admin only.
+	SetPurchaseCap(ctx context.Context, in *SetPurchaseCapRequest, opts ...grpc.CallOption) (*PurchaseCap, error)
+	ListPurchaseCaps(ctx context.Context, in *ListPurchaseCapsRequest, opts ...grpc.CallOption) (*ListPurchaseCapsResponse, error)
+	// RequestReturn asks to send back items of a paid or shipped order within
+	// 30 days of placing it. Support reviews the request.
+	RequestReturn(ctx context.Context, in *RequestReturnRequest, opts ...grpc.CallOption) (*Return, error)
+	// ReviewReturn approves or rejects a requested return. Approving it puts
+	// the items back in stock and refunds them. Admin only.
+	ReviewReturn(ctx context.Context, in *ReviewReturnRequest, opts ...grpc.CallOption) (*Return, error)
+	// ListReturns lists the caller's returns, or everyone's for admins with
+	// all_users, newest first.
+	ListReturns(ctx context.Context, in *ListReturnsRequest, opts ...grpc.CallOption) (*ListReturnsResponse, error)
+	// AddOrderNote, SetOrderFlag and ClearOrderFlag annotate an order for
+	// support. Annotations are never shown to the customer and every change is
+	// recorded on the internal timeline. Admin only.
+	AddOrderNote(ctx context.Context, in *AddOrderNoteRequest, opts ...grpc.CallOption) (*OrderAnnotation, error)
+	SetOrderFlag(ctx context.Context, in *SetOrderFlagRequest, opts ...grpc.CallOption) (*OrderAnnotation, error)
+	ClearOrderFlag(ctx context.Context, in *ClearOrderFlagRequest, opts ...grpc.CallOption) (*OrderAnnotation, error)
+	// ListOrderAnnotations lists the notes and flags of an order, newest
+	// first, cleared flags included. Admin only.
+	ListOrderAnnotations(ctx context.Context, in *ListOrderAnnotationsRequest, opts ...grpc.CallOption) (*ListOrderAnnotationsResponse, error)
 }
 
 type orderServiceClient struct {
@@ -47,11 +111,273 @@ func (c *orderServiceClient) CreateOrder(ctx context.Context, in *CreateOrderReq
 	return out, nil
 }
 
+func (c *orderServiceClient) ResolvePartialReservation(ctx context.Context, in *ResolvePartialReservationRequest, opts ...grpc.CallOption) (*ResolvePartialReservationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResolvePartialReservationResponse)
+	err := c.cc.Invoke(ctx, OrderService_ResolvePartialReservation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetOrderTimeline(ctx context.Context, in *GetOrderTimelineRequest, opts ...grpc.CallOption) (*GetOrderTimelineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrderTimelineResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetOrderTimeline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListUserOrders(ctx context.Context, in *ListUserOrdersRequest, opts ...grpc.CallOption) (*ListUserOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUserOrdersResponse)
+	err := c.cc.Invoke(ctx, OrderService_ListUserOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelOrderResponse)
+	err := c.cc.Invoke(ctx, OrderService_CancelOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) GetInvoice(ctx context.Context, in *GetInvoiceRequest, opts ...grpc.CallOption) (*GetInvoiceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetInvoiceResponse)
+	err := c.cc.Invoke(ctx, OrderService_GetInvoice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ExportOrders(ctx context.Context, in *ExportOrdersRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportOrdersChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderService_ServiceDesc.Streams[0], OrderService_ExportOrders_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportOrdersRequest, ExportOrdersChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderService_ExportOrdersClient = grpc.ServerStreamingClient[ExportOrdersChunk]
+
+func (c *orderServiceClient) ForceOrderStatus(ctx context.Context, in *ForceOrderStatusRequest, opts ...grpc.CallOption) (*ForceOrderStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ForceOrderStatusResponse)
+	err := c.cc.Invoke(ctx, OrderService_ForceOrderStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) CreateManualOrder(ctx context.Context, in *CreateManualOrderRequest, opts ...grpc.CallOption) (*CreateOrderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateOrderResponse)
+	err := c.cc.Invoke(ctx, OrderService_CreateManualOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) AddAddress(ctx context.Context, in *AddAddressRequest, opts ...grpc.CallOption) (*Address, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Address)
+	err := c.cc.Invoke(ctx, OrderService_AddAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListAddresses(ctx context.Context, in *ListAddressesRequest, opts ...grpc.CallOption) (*ListAddressesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAddressesResponse)
+	err := c.cc.Invoke(ctx, OrderService_ListAddresses_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) UpdateAddress(ctx context.Context, in *UpdateAddressRequest, opts ...grpc.CallOption) (*Address, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Address)
+	err := c.cc.Invoke(ctx, OrderService_UpdateAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) DeleteAddress(ctx context.Context, in *DeleteAddressRequest, opts ...grpc.CallOption) (*DeleteAddressResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAddressResponse)
+	err := c.cc.Invoke(ctx, OrderService_DeleteAddress_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) SetPurchaseCap(ctx context.Context, in *SetPurchaseCapRequest, opts ...grpc.CallOption) (*PurchaseCap, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurchaseCap)
+	err := c.cc.Invoke(ctx, OrderService_SetPurchaseCap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListPurchaseCaps(ctx context.Context, in *ListPurchaseCapsRequest, opts ...grpc.CallOption) (*ListPurchaseCapsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPurchaseCapsResponse)
+	err := c.cc.Invoke(ctx, OrderService_ListPurchaseCaps_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) RequestReturn(ctx context.Context, in *RequestReturnRequest, opts ...grpc.CallOption) (*Return, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Return)
+	err := c.cc.Invoke(ctx, OrderService_RequestReturn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ReviewReturn(ctx context.Context, in *ReviewReturnRequest, opts ...grpc.CallOption) (*Return, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Return)
+	err := c.cc.Invoke(ctx, OrderService_ReviewReturn_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListReturns(ctx context.Context, in *ListReturnsRequest, opts ...grpc.CallOption) (*ListReturnsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReturnsResponse)
+	err := c.cc.Invoke(ctx, OrderService_ListReturns_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) AddOrderNote(ctx context.Context, in *AddOrderNoteRequest, opts ...grpc.CallOption) (*OrderAnnotation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderAnnotation)
+	err := c.cc.Invoke(ctx, OrderService_AddOrderNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) SetOrderFlag(ctx context.Context, in *SetOrderFlagRequest, opts ...grpc.CallOption) (*OrderAnnotation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderAnnotation)
+	err := c.cc.Invoke(ctx, OrderService_SetOrderFlag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ClearOrderFlag(ctx context.Context, in *ClearOrderFlagRequest, opts ...grpc.CallOption) (*OrderAnnotation, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(OrderAnnotation)
+	err := c.cc.Invoke(ctx, OrderService_ClearOrderFlag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderServiceClient) ListOrderAnnotations(ctx context.Context, in *ListOrderAnnotationsRequest, opts ...grpc.CallOption) (*ListOrderAnnotationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOrderAnnotationsResponse)
+	err := c.cc.Invoke(ctx, OrderService_ListOrderAnnotations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // OrderServiceServer is the server API for OrderService service.
 // All implementations must embed UnimplementedOrderServiceServer
 // for forward compatibility.
 type OrderServiceServer interface {
 	CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error)
+	ResolvePartialReservation(context.Context, *ResolvePartialReservationRequest) (*ResolvePartialReservationResponse, error)
+	GetOrderTimeline(context.Context, *GetOrderTimelineRequest) (*GetOrderTimelineResponse, error)
+	ListUserOrders(context.Context, *ListUserOrdersRequest) (*ListUserOrdersResponse, error)
+	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
+	GetInvoice(context.Context, *GetInvoiceRequest) (*GetInvoiceResponse, error)
+	// ExportOrders streams the orders created in a date range as CSV for
+	// accounting. Admin only.
+	ExportOrders(*ExportOrdersRequest, grpc.ServerStreamingServer[ExportOrdersChunk]) error
+	// ForceOrderStatus moves a stuck order to another status by hand, e.g.
+	// when the payment event was lost. Admin only; only some transitions can
+	// be forced.
+	ForceOrderStatus(context.Context, *ForceOrderStatusRequest) (*ForceOrderStatusResponse, error)
+	// CreateManualOrder places an order on a customer's behalf, e.g. taken by
+	// phone, with prices and a discount set by support. Admin only; every
+	// change to a price needs a reason code and is kept in the audit trail.
+	CreateManualOrder(context.Context, *CreateManualOrderRequest) (*CreateOrderResponse, error)
+	AddAddress(context.Context, *AddAddressRequest) (*Address, error)
+	ListAddresses(context.Context, *ListAddressesRequest) (*ListAddressesResponse, error)
+	UpdateAddress(context.Context, *UpdateAddressRequest) (*Address, error)
+	DeleteAddress(context.Context, *DeleteAddressRequest) (*DeleteAddressResponse, error)
+	// SetPurchaseCap limits how many units of a product one customer may buy
+	// across all their orders, for limited releases. A max_per_user of 0
+	// lifts the cap. Admin only.
+	SetPurchaseCap(context.Context, *SetPurchaseCapRequest) (*PurchaseCap, error)
+	ListPurchaseCaps(context.Context, *ListPurchaseCapsRequest) (*ListPurchaseCapsResponse, error)
+	// RequestReturn asks to send back items of a paid or shipped order within
+	// 30 days of placing it. Support reviews the request.
+	RequestReturn(context.Context, *RequestReturnRequest) (*Return, error)
+	// ReviewReturn approves or rejects a requested return. Approving it puts
+	// the items back in stock and refunds them. Admin only.
+	ReviewReturn(context.Context, *ReviewReturnRequest) (*Return, error)
+	// ListReturns lists the caller's returns, or everyone's for admins with
+	// all_users, newest first.
+	ListReturns(context.Context, *ListReturnsRequest) (*ListReturnsResponse, error)
+	// AddOrderNote, SetOrderFlag and ClearOrderFlag annotate an order for
+	// support. Annotations are never shown to the customer and every change is
+	// recorded on the internal timeline. Admin only.
+	AddOrderNote(context.Context, *AddOrderNoteRequest) (*OrderAnnotation, error)
+	SetOrderFlag(context.Context, *SetOrderFlagRequest) (*OrderAnnotation, error)
+	ClearOrderFlag(context.Context, *ClearOrderFlagRequest) (*OrderAnnotation, error)
+	// ListOrderAnnotations lists the notes and flags of an order, newest
+	// first, cleared flags included. Admin only.
+	ListOrderAnnotations(context.Context, *ListOrderAnnotationsRequest) (*ListOrderAnnotationsResponse, error)
 	mustEmbedUnimplementedOrderServiceServer()
 }
 
@@ -65,6 +391,69 @@ type UnimplementedOrderServiceServer struct{}
 func (UnimplementedOrderServiceServer) CreateOrder(context.Context, *CreateOrderRequest) (*CreateOrderResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CreateOrder not implemented")
 }
+func (UnimplementedOrderServiceServer) ResolvePartialReservation(context.Context, *ResolvePartialReservationRequest) (*ResolvePartialReservationResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResolvePartialReservation not implemented")
+}
+func (UnimplementedOrderServiceServer) GetOrderTimeline(context.Context, *GetOrderTimelineRequest) (*GetOrderTimelineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetOrderTimeline not implemented")
+}
+func (UnimplementedOrderServiceServer) ListUserOrders(context.Context, *ListUserOrdersRequest) (*ListUserOrdersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListUserOrders not implemented")
+}
+func (UnimplementedOrderServiceServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) GetInvoice(context.Context, *GetInvoiceRequest) (*GetInvoiceResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetInvoice not implemented")
+}
+func (UnimplementedOrderServiceServer) ExportOrders(*ExportOrdersRequest, grpc.ServerStreamingServer[ExportOrdersChunk]) error {
+	return status.Error(codes.Unimplemented, "method ExportOrders not implemented")
+}
+func (UnimplementedOrderServiceServer) ForceOrderStatus(context.Context, *ForceOrderStatusRequest) (*ForceOrderStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ForceOrderStatus not implemented")
+}
+func (UnimplementedOrderServiceServer) CreateManualOrder(context.Context, *CreateManualOrderRequest) (*CreateOrderResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CreateManualOrder not implemented")
+}
+func (UnimplementedOrderServiceServer) AddAddress(context.Context, *AddAddressRequest) (*Address, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddAddress not implemented")
+}
+func (UnimplementedOrderServiceServer) ListAddresses(context.Context, *ListAddressesRequest) (*ListAddressesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListAddresses not implemented")
+}
+func (UnimplementedOrderServiceServer) UpdateAddress(context.Context, *UpdateAddressRequest) (*Address, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateAddress not implemented")
+}
+func (UnimplementedOrderServiceServer) DeleteAddress(context.Context, *DeleteAddressRequest) (*DeleteAddressResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method DeleteAddress not implemented")
+}
+func (UnimplementedOrderServiceServer) SetPurchaseCap(context.Context, *SetPurchaseCapRequest) (*PurchaseCap, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetPurchaseCap not implemented")
+}
+func (UnimplementedOrderServiceServer) ListPurchaseCaps(context.Context, *ListPurchaseCapsRequest) (*ListPurchaseCapsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPurchaseCaps not implemented")
+}
+func (UnimplementedOrderServiceServer) RequestReturn(context.Context, *RequestReturnRequest) (*Return, error) {
+	return nil, status.Error(codes.Unimplemented, "method RequestReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) ReviewReturn(context.Context, *ReviewReturnRequest) (*Return, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReviewReturn not implemented")
+}
+func (UnimplementedOrderServiceServer) ListReturns(context.Context, *ListReturnsRequest) (*ListReturnsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListReturns not implemented")
+}
+func (UnimplementedOrderServiceServer) AddOrderNote(context.Context, *AddOrderNoteRequest) (*OrderAnnotation, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddOrderNote not implemented")
+}
+func (UnimplementedOrderServiceServer) SetOrderFlag(context.Context, *SetOrderFlagRequest) (*OrderAnnotation, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetOrderFlag not implemented")
+}
+func (UnimplementedOrderServiceServer) ClearOrderFlag(context.Context, *ClearOrderFlagRequest) (*OrderAnnotation, error) {
+	return nil, status.Error(codes.Unimplemented, "method ClearOrderFlag not implemented")
+}
+func (UnimplementedOrderServiceServer) ListOrderAnnotations(context.Context, *ListOrderAnnotationsRequest) (*ListOrderAnnotationsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListOrderAnnotations not implemented")
+}
 func (UnimplementedOrderServiceServer) mustEmbedUnimplementedOrderServiceServer() {}
 func (UnimplementedOrderServiceServer) testEmbeddedByValue()                      {}
 
@@ -104,6 +493,377 @@ func _OrderService_CreateOrder_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _OrderService_ResolvePartialReservation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolvePartialReservationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ResolvePartialReservation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ResolvePartialReservation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ResolvePartialReservation(ctx, req.(*ResolvePartialReservationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetOrderTimeline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderTimelineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetOrderTimeline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetOrderTimeline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetOrderTimeline(ctx, req.(*GetOrderTimelineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListUserOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUserOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListUserOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ListUserOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListUserOrders(ctx, req.(*ListUserOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_GetInvoice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInvoiceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).GetInvoice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_GetInvoice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).GetInvoice(ctx, req.(*GetInvoiceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ExportOrders_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportOrdersRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderServiceServer).ExportOrders(m, &grpc.GenericServerStream[ExportOrdersRequest, ExportOrdersChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderService_ExportOrdersServer = grpc.ServerStreamingServer[ExportOrdersChunk]
+
+func _OrderService_ForceOrderStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForceOrderStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ForceOrderStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ForceOrderStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ForceOrderStatus(ctx, req.(*ForceOrderStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_CreateManualOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateManualOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).CreateManualOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_CreateManualOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).CreateManualOrder(ctx, req.(*CreateManualOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_AddAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).AddAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_AddAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).AddAddress(ctx, req.(*AddAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListAddresses_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAddressesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListAddresses(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ListAddresses_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListAddresses(ctx, req.(*ListAddressesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_UpdateAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).UpdateAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_UpdateAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).UpdateAddress(ctx, req.(*UpdateAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_DeleteAddress_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAddressRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).DeleteAddress(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_DeleteAddress_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).DeleteAddress(ctx, req.(*DeleteAddressRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_SetPurchaseCap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetPurchaseCapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).SetPurchaseCap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_SetPurchaseCap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).SetPurchaseCap(ctx, req.(*SetPurchaseCapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListPurchaseCaps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPurchaseCapsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListPurchaseCaps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ListPurchaseCaps_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListPurchaseCaps(ctx, req.(*ListPurchaseCapsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_RequestReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).RequestReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_RequestReturn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).RequestReturn(ctx, req.(*RequestReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ReviewReturn_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReviewReturnRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ReviewReturn(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ReviewReturn_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ReviewReturn(ctx, req.(*ReviewReturnRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListReturns_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReturnsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListReturns(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ListReturns_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListReturns(ctx, req.(*ListReturnsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_AddOrderNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddOrderNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).AddOrderNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_AddOrderNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).AddOrderNote(ctx, req.(*AddOrderNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_SetOrderFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetOrderFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).SetOrderFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_SetOrderFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).SetOrderFlag(ctx, req.(*SetOrderFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ClearOrderFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearOrderFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ClearOrderFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ClearOrderFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ClearOrderFlag(ctx, req.(*ClearOrderFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderService_ListOrderAnnotations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListOrderAnnotationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderServiceServer).ListOrderAnnotations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderService_ListOrderAnnotations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderServiceServer).ListOrderAnnotations(ctx, req.(*ListOrderAnnotationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // OrderService_ServiceDesc is the grpc.ServiceDesc for OrderService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +875,93 @@ var OrderService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CreateOrder",
 			Handler:    _OrderService_CreateOrder_Handler,
 		},
+		{
+			MethodName: "ResolvePartialReservation",
+			Handler:    _OrderService_ResolvePartialReservation_Handler,
+		},
+		{
+			MethodName: "GetOrderTimeline",
+			Handler:    _OrderService_GetOrderTimeline_Handler,
+		},
+		{
+			MethodName: "ListUserOrders",
+			Handler:    _OrderService_ListUserOrders_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _OrderService_CancelOrder_Handler,
+		},
+		{
+			MethodName: "GetInvoice",
+			Handler:    _OrderService_GetInvoice_Handler,
+		},
+		{
+			MethodName: "ForceOrderStatus",
+			Handler:    _OrderService_ForceOrderStatus_Handler,
+		},
+		{
+			MethodName: "CreateManualOrder",
+			Handler:    _OrderService_CreateManualOrder_Handler,
+		},
+		{
+			MethodName: "AddAddress",
+			Handler:    _OrderService_AddAddress_Handler,
+		},
+		{
+			MethodName: "ListAddresses",
+			Handler:    _OrderService_ListAddresses_Handler,
+		},
+		{
+			MethodName: "UpdateAddress",
+			Handler:    _OrderService_UpdateAddress_Handler,
+		},
+		{
+			MethodName: "DeleteAddress",
+			Handler:    _OrderService_DeleteAddress_Handler,
+		},
+		{
+			MethodName: "SetPurchaseCap",
+			Handler:    _OrderService_SetPurchaseCap_Handler,
+		},
+		{
+			MethodName: "ListPurchaseCaps",
+			Handler:    _OrderService_ListPurchaseCaps_Handler,
+		},
+		{
+			MethodName: "RequestReturn",
+			Handler:    _OrderService_RequestReturn_Handler,
+		},
+		{
+			MethodName: "ReviewReturn",
+			Handler:    _OrderService_ReviewReturn_Handler,
+		},
+		{
+			MethodName: "ListReturns",
+			Handler:    _OrderService_ListReturns_Handler,
+		},
+		{
+			MethodName: "AddOrderNote",
+			Handler:    _OrderService_AddOrderNote_Handler,
+		},
+		{
+			MethodName: "SetOrderFlag",
+			Handler:    _OrderService_SetOrderFlag_Handler,
+		},
+		{
+			MethodName: "ClearOrderFlag",
+			Handler:    _OrderService_ClearOrderFlag_Handler,
+		},
+		{
+			MethodName: "ListOrderAnnotations",
+			Handler:    _OrderService_ListOrderAnnotations_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportOrders",
+			Handler:       _OrderService_ExportOrders_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/order/order.proto",
 }
@@ -0,0 +1,1209 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.2
+// source: proto/payment/payment.proto
+
+package payment
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Dates are YYYY-MM-DD in UTC, both ends inclusive. Empty bounds are open.
+// Not named DateRange: analytics.proto has one in the same (empty) proto
+// package, and the gateway links both.
+type BalanceSheetPeriod struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BalanceSheetPeriod) Reset() {
+	*x = BalanceSheetPeriod{}
+	mi := &file_proto_payment_payment_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BalanceSheetPeriod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BalanceSheetPeriod) ProtoMessage() {}
+
+func (x *BalanceSheetPeriod) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BalanceSheetPeriod.ProtoReflect.Descriptor instead.
+func (*BalanceSheetPeriod) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BalanceSheetPeriod) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *BalanceSheetPeriod) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+type GetBalanceSheetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Range         *BalanceSheetPeriod    `protobuf:"bytes,1,opt,name=range,proto3" json:"range,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceSheetRequest) Reset() {
+	*x = GetBalanceSheetRequest{}
+	mi := &file_proto_payment_payment_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceSheetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceSheetRequest) ProtoMessage() {}
+
+func (x *GetBalanceSheetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceSheetRequest.ProtoReflect.Descriptor instead.
+func (*GetBalanceSheetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetBalanceSheetRequest) GetRange() *BalanceSheetPeriod {
+	if x != nil {
+		return x.Range
+	}
+	return nil
+}
+
+type AccountBalance struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Account string                 `protobuf:"bytes,1,opt,name=account,proto3" json:"account,omitempty"`
+	Debits  int64                  `protobuf:"varint,2,opt,name=debits,proto3" json:"debits,omitempty"`
+	Credits int64                  `protobuf:"varint,3,opt,name=credits,proto3" json:"credits,omitempty"`
+	// Debits minus credits.
+	Balance       int64 `protobuf:"varint,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccountBalance) Reset() {
+	*x = AccountBalance{}
+	mi := &file_proto_payment_payment_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccountBalance) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccountBalance) ProtoMessage() {}
+
+func (x *AccountBalance) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccountBalance.ProtoReflect.Descriptor instead.
+func (*AccountBalance) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AccountBalance) GetAccount() string {
+	if x != nil {
+		return x.Account
+	}
+	return ""
+}
+
+func (x *AccountBalance) GetDebits() int64 {
+	if x != nil {
+		return x.Debits
+	}
+	return 0
+}
+
+func (x *AccountBalance) GetCredits() int64 {
+	if x != nil {
+		return x.Credits
+	}
+	return 0
+}
+
+func (x *AccountBalance) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+type GetBalanceSheetResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Accounts      []*AccountBalance      `protobuf:"bytes,1,rep,name=accounts,proto3" json:"accounts,omitempty"`
+	TotalDebits   int64                  `protobuf:"varint,2,opt,name=total_debits,json=totalDebits,proto3" json:"total_debits,omitempty"`
+	TotalCredits  int64                  `protobuf:"varint,3,opt,name=total_credits,json=totalCredits,proto3" json:"total_credits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBalanceSheetResponse) Reset() {
+	*x = GetBalanceSheetResponse{}
+	mi := &file_proto_payment_payment_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBalanceSheetResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBalanceSheetResponse) ProtoMessage() {}
+
+func (x *GetBalanceSheetResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBalanceSheetResponse.ProtoReflect.Descriptor instead.
+func (*GetBalanceSheetResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetBalanceSheetResponse) GetAccounts() []*AccountBalance {
+	if x != nil {
+		return x.Accounts
+	}
+	return nil
+}
+
+func (x *GetBalanceSheetResponse) GetTotalDebits() int64 {
+	if x != nil {
+		return x.TotalDebits
+	}
+	return 0
+}
+
+func (x *GetBalanceSheetResponse) GetTotalCredits() int64 {
+	if x != nil {
+		return x.TotalCredits
+	}
+	return 0
+}
+
+// PaymentMethod is a card saved at the payment provider. Only the provider
+// token is stored, and it is never returned to clients.
+type PaymentMethod struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Provider      string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	Brand         string                 `protobuf:"bytes,4,opt,name=brand,proto3" json:"brand,omitempty"`
+	Last4         string                 `protobuf:"bytes,5,opt,name=last4,proto3" json:"last4,omitempty"`
+	ExpMonth      int32                  `protobuf:"varint,6,opt,name=exp_month,json=expMonth,proto3" json:"exp_month,omitempty"`
+	ExpYear       int32                  `protobuf:"varint,7,opt,name=exp_year,json=expYear,proto3" json:"exp_year,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PaymentMethod) Reset() {
+	*x = PaymentMethod{}
+	mi := &file_proto_payment_payment_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PaymentMethod) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PaymentMethod) ProtoMessage() {}
+
+func (x *PaymentMethod) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PaymentMethod.ProtoReflect.Descriptor instead.
+func (*PaymentMethod) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PaymentMethod) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *PaymentMethod) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *PaymentMethod) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *PaymentMethod) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *PaymentMethod) GetLast4() string {
+	if x != nil {
+		return x.Last4
+	}
+	return ""
+}
+
+func (x *PaymentMethod) GetExpMonth() int32 {
+	if x != nil {
+		return x.ExpMonth
+	}
+	return 0
+}
+
+func (x *PaymentMethod) GetExpYear() int32 {
+	if x != nil {
+		return x.ExpYear
+	}
+	return 0
+}
+
+func (x *PaymentMethod) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type AddPaymentMethodRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
+	Token         string                 `protobuf:"bytes,3,opt,name=token,proto3" json:"token,omitempty"`
+	Brand         string                 `protobuf:"bytes,4,opt,name=brand,proto3" json:"brand,omitempty"`
+	Last4         string                 `protobuf:"bytes,5,opt,name=last4,proto3" json:"last4,omitempty"`
+	ExpMonth      int32                  `protobuf:"varint,6,opt,name=exp_month,json=expMonth,proto3" json:"exp_month,omitempty"`
+	ExpYear       int32                  `protobuf:"varint,7,opt,name=exp_year,json=expYear,proto3" json:"exp_year,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddPaymentMethodRequest) Reset() {
+	*x = AddPaymentMethodRequest{}
+	mi := &file_proto_payment_payment_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddPaymentMethodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddPaymentMethodRequest) ProtoMessage() {}
+
+func (x *AddPaymentMethodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddPaymentMethodRequest.ProtoReflect.Descriptor instead.
+func (*AddPaymentMethodRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *AddPaymentMethodRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *AddPaymentMethodRequest) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *AddPaymentMethodRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *AddPaymentMethodRequest) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *AddPaymentMethodRequest) GetLast4() string {
+	if x != nil {
+		return x.Last4
+	}
+	return ""
+}
+
+func (x *AddPaymentMethodRequest) GetExpMonth() int32 {
+	if x != nil {
+		return x.ExpMonth
+	}
+	return 0
+}
+
+func (x *AddPaymentMethodRequest) GetExpYear() int32 {
+	if x != nil {
+		return x.ExpYear
+	}
+	return 0
+}
+
+type ListPaymentMethodsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPaymentMethodsRequest) Reset() {
+	*x = ListPaymentMethodsRequest{}
+	mi := &file_proto_payment_payment_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentMethodsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentMethodsRequest) ProtoMessage() {}
+
+func (x *ListPaymentMethodsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentMethodsRequest.ProtoReflect.Descriptor instead.
+func (*ListPaymentMethodsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListPaymentMethodsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type ListPaymentMethodsResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	PaymentMethods []*PaymentMethod       `protobuf:"bytes,1,rep,name=payment_methods,json=paymentMethods,proto3" json:"payment_methods,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListPaymentMethodsResponse) Reset() {
+	*x = ListPaymentMethodsResponse{}
+	mi := &file_proto_payment_payment_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPaymentMethodsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPaymentMethodsResponse) ProtoMessage() {}
+
+func (x *ListPaymentMethodsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPaymentMethodsResponse.ProtoReflect.Descriptor instead.
+func (*ListPaymentMethodsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListPaymentMethodsResponse) GetPaymentMethods() []*PaymentMethod {
+	if x != nil {
+		return x.PaymentMethods
+	}
+	return nil
+}
+
+type DeletePaymentMethodRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePaymentMethodRequest) Reset() {
+	*x = DeletePaymentMethodRequest{}
+	mi := &file_proto_payment_payment_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePaymentMethodRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePaymentMethodRequest) ProtoMessage() {}
+
+func (x *DeletePaymentMethodRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePaymentMethodRequest.ProtoReflect.Descriptor instead.
+func (*DeletePaymentMethodRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeletePaymentMethodRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeletePaymentMethodRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type DeletePaymentMethodResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePaymentMethodResponse) Reset() {
+	*x = DeletePaymentMethodResponse{}
+	mi := &file_proto_payment_payment_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePaymentMethodResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePaymentMethodResponse) ProtoMessage() {}
+
+func (x *DeletePaymentMethodResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePaymentMethodResponse.ProtoReflect.Descriptor instead.
+func (*DeletePaymentMethodResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *DeletePaymentMethodResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+// GiftCard is store credit. Claimed cards pay for orders before the
+// provider is charged. The code is only returned when the card is issued.
+type GiftCard struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Last4         string                 `protobuf:"bytes,2,opt,name=last4,proto3" json:"last4,omitempty"`
+	InitialAmount int64                  `protobuf:"varint,3,opt,name=initial_amount,json=initialAmount,proto3" json:"initial_amount,omitempty"`
+	Balance       int64                  `protobuf:"varint,4,opt,name=balance,proto3" json:"balance,omitempty"`
+	// Timestamps are RFC 3339; claimed_at and voided_at are empty if unset.
+	ExpiresAt     string `protobuf:"bytes,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	ClaimedAt     string `protobuf:"bytes,6,opt,name=claimed_at,json=claimedAt,proto3" json:"claimed_at,omitempty"`
+	VoidedAt      string `protobuf:"bytes,7,opt,name=voided_at,json=voidedAt,proto3" json:"voided_at,omitempty"`
+	CreatedAt     string `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GiftCard) Reset() {
+	*x = GiftCard{}
+	mi := &file_proto_payment_payment_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GiftCard) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GiftCard) ProtoMessage() {}
+
+func (x *GiftCard) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GiftCard.ProtoReflect.Descriptor instead.
+func (*GiftCard) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GiftCard) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *GiftCard) GetLast4() string {
+	if x != nil {
+		return x.Last4
+	}
+	return ""
+}
+
+func (x *GiftCard) GetInitialAmount() int64 {
+	if x != nil {
+		return x.InitialAmount
+	}
+	return 0
+}
+
+func (x *GiftCard) GetBalance() int64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *GiftCard) GetExpiresAt() string {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return ""
+}
+
+func (x *GiftCard) GetClaimedAt() string {
+	if x != nil {
+		return x.ClaimedAt
+	}
+	return ""
+}
+
+func (x *GiftCard) GetVoidedAt() string {
+	if x != nil {
+		return x.VoidedAt
+	}
+	return ""
+}
+
+func (x *GiftCard) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type IssueGiftCardRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	AdminId int64                  `protobuf:"varint,1,opt,name=admin_id,json=adminId,proto3" json:"admin_id,omitempty"`
+	// At most 100000000.
+	Amount int64 `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	// Days the card can be claimed and spent, at most 1825.
+	ValidDays     int32 `protobuf:"varint,3,opt,name=valid_days,json=validDays,proto3" json:"valid_days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueGiftCardRequest) Reset() {
+	*x = IssueGiftCardRequest{}
+	mi := &file_proto_payment_payment_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueGiftCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueGiftCardRequest) ProtoMessage() {}
+
+func (x *IssueGiftCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueGiftCardRequest.ProtoReflect.Descriptor instead.
+func (*IssueGiftCardRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *IssueGiftCardRequest) GetAdminId() int64 {
+	if x != nil {
+		return x.AdminId
+	}
+	return 0
+}
+
+func (x *IssueGiftCardRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *IssueGiftCardRequest) GetValidDays() int32 {
+	if x != nil {
+		return x.ValidDays
+	}
+	return 0
+}
+
+type IssueGiftCardResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GiftCard      *GiftCard              `protobuf:"bytes,1,opt,name=gift_card,json=giftCard,proto3" json:"gift_card,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssueGiftCardResponse) Reset() {
+	*x = IssueGiftCardResponse{}
+	mi := &file_proto_payment_payment_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssueGiftCardResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssueGiftCardResponse) ProtoMessage() {}
+
+func (x *IssueGiftCardResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssueGiftCardResponse.ProtoReflect.Descriptor instead.
+func (*IssueGiftCardResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *IssueGiftCardResponse) GetGiftCard() *GiftCard {
+	if x != nil {
+		return x.GiftCard
+	}
+	return nil
+}
+
+func (x *IssueGiftCardResponse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type VoidGiftCardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AdminId       int64                  `protobuf:"varint,2,opt,name=admin_id,json=adminId,proto3" json:"admin_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *VoidGiftCardRequest) Reset() {
+	*x = VoidGiftCardRequest{}
+	mi := &file_proto_payment_payment_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VoidGiftCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoidGiftCardRequest) ProtoMessage() {}
+
+func (x *VoidGiftCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoidGiftCardRequest.ProtoReflect.Descriptor instead.
+func (*VoidGiftCardRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *VoidGiftCardRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *VoidGiftCardRequest) GetAdminId() int64 {
+	if x != nil {
+		return x.AdminId
+	}
+	return 0
+}
+
+type ClaimGiftCardRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClaimGiftCardRequest) Reset() {
+	*x = ClaimGiftCardRequest{}
+	mi := &file_proto_payment_payment_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClaimGiftCardRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClaimGiftCardRequest) ProtoMessage() {}
+
+func (x *ClaimGiftCardRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClaimGiftCardRequest.ProtoReflect.Descriptor instead.
+func (*ClaimGiftCardRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ClaimGiftCardRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *ClaimGiftCardRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type ListGiftCardsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGiftCardsRequest) Reset() {
+	*x = ListGiftCardsRequest{}
+	mi := &file_proto_payment_payment_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGiftCardsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGiftCardsRequest) ProtoMessage() {}
+
+func (x *ListGiftCardsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGiftCardsRequest.ProtoReflect.Descriptor instead.
+func (*ListGiftCardsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListGiftCardsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+type ListGiftCardsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GiftCards     []*GiftCard            `protobuf:"bytes,1,rep,name=gift_cards,json=giftCards,proto3" json:"gift_cards,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListGiftCardsResponse) Reset() {
+	*x = ListGiftCardsResponse{}
+	mi := &file_proto_payment_payment_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListGiftCardsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListGiftCardsResponse) ProtoMessage() {}
+
+func (x *ListGiftCardsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_payment_payment_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListGiftCardsResponse.ProtoReflect.Descriptor instead.
+func (*ListGiftCardsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_payment_payment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListGiftCardsResponse) GetGiftCards() []*GiftCard {
+	if x != nil {
+		return x.GiftCards
+	}
+	return nil
+}
+
+var File_proto_payment_payment_proto protoreflect.FileDescriptor
+
+const file_proto_payment_payment_proto_rawDesc = "" +
+	"\n" +
+	"\x1bproto/payment/payment.proto\"8\n" +
+	"\x12BalanceSheetPeriod\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\"C\n" +
+	"\x16GetBalanceSheetRequest\x12)\n" +
+	"\x05range\x18\x01 \x01(\v2\x13.BalanceSheetPeriodR\x05range\"v\n" +
+	"\x0eAccountBalance\x12\x18\n" +
+	"\aaccount\x18\x01 \x01(\tR\aaccount\x12\x16\n" +
+	"\x06debits\x18\x02 \x01(\x03R\x06debits\x12\x18\n" +
+	"\acredits\x18\x03 \x01(\x03R\acredits\x12\x18\n" +
+	"\abalance\x18\x04 \x01(\x03R\abalance\"\x8e\x01\n" +
+	"\x17GetBalanceSheetResponse\x12+\n" +
+	"\baccounts\x18\x01 \x03(\v2\x0f.AccountBalanceR\baccounts\x12!\n" +
+	"\ftotal_debits\x18\x02 \x01(\x03R\vtotalDebits\x12#\n" +
+	"\rtotal_credits\x18\x03 \x01(\x03R\ftotalCredits\"\xd7\x01\n" +
+	"\rPaymentMethod\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x1a\n" +
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12\x14\n" +
+	"\x05brand\x18\x04 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05last4\x18\x05 \x01(\tR\x05last4\x12\x1b\n" +
+	"\texp_month\x18\x06 \x01(\x05R\bexpMonth\x12\x19\n" +
+	"\bexp_year\x18\a \x01(\x05R\aexpYear\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\"\xc8\x01\n" +
+	"\x17AddPaymentMethodRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x1a\n" +
+	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x14\n" +
+	"\x05token\x18\x03 \x01(\tR\x05token\x12\x14\n" +
+	"\x05brand\x18\x04 \x01(\tR\x05brand\x12\x14\n" +
+	"\x05last4\x18\x05 \x01(\tR\x05last4\x12\x1b\n" +
+	"\texp_month\x18\x06 \x01(\x05R\bexpMonth\x12\x19\n" +
+	"\bexp_year\x18\a \x01(\x05R\aexpYear\"4\n" +
+	"\x19ListPaymentMethodsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"U\n" +
+	"\x1aListPaymentMethodsResponse\x127\n" +
+	"\x0fpayment_methods\x18\x01 \x03(\v2\x0e.PaymentMethodR\x0epaymentMethods\"E\n" +
+	"\x1aDeletePaymentMethodRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\"7\n" +
+	"\x1bDeletePaymentMethodResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\xeb\x01\n" +
+	"\bGiftCard\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05last4\x18\x02 \x01(\tR\x05last4\x12%\n" +
+	"\x0einitial_amount\x18\x03 \x01(\x03R\rinitialAmount\x12\x18\n" +
+	"\abalance\x18\x04 \x01(\x03R\abalance\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\tR\texpiresAt\x12\x1d\n" +
+	"\n" +
+	"claimed_at\x18\x06 \x01(\tR\tclaimedAt\x12\x1b\n" +
+	"\tvoided_at\x18\a \x01(\tR\bvoidedAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\"h\n" +
+	"\x14IssueGiftCardRequest\x12\x19\n" +
+	"\badmin_id\x18\x01 \x01(\x03R\aadminId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1d\n" +
+	"\n" +
+	"valid_days\x18\x03 \x01(\x05R\tvalidDays\"S\n" +
+	"\x15IssueGiftCardResponse\x12&\n" +
+	"\tgift_card\x18\x01 \x01(\v2\t.GiftCardR\bgiftCard\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"@\n" +
+	"\x13VoidGiftCardRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\badmin_id\x18\x02 \x01(\x03R\aadminId\"C\n" +
+	"\x14ClaimGiftCardRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\"/\n" +
+	"\x14ListGiftCardsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\"A\n" +
+	"\x15ListGiftCardsResponse\x12(\n" +
+	"\n" +
+	"gift_cards\x18\x01 \x03(\v2\t.GiftCardR\tgiftCards2\x99\x04\n" +
+	"\x0ePaymentService\x12D\n" +
+	"\x0fGetBalanceSheet\x12\x17.GetBalanceSheetRequest\x1a\x18.GetBalanceSheetResponse\x12<\n" +
+	"\x10AddPaymentMethod\x12\x18.AddPaymentMethodRequest\x1a\x0e.PaymentMethod\x12M\n" +
+	"\x12ListPaymentMethods\x12\x1a.ListPaymentMethodsRequest\x1a\x1b.ListPaymentMethodsResponse\x12P\n" +
+	"\x13DeletePaymentMethod\x12\x1b.DeletePaymentMethodRequest\x1a\x1c.DeletePaymentMethodResponse\x12>\n" +
+	"\rIssueGiftCard\x12\x15.IssueGiftCardRequest\x1a\x16.IssueGiftCardResponse\x12/\n" +
+	"\fVoidGiftCard\x12\x14.VoidGiftCardRequest\x1a\t.GiftCard\x121\n" +
+	"\rClaimGiftCard\x12\x15.ClaimGiftCardRequest\x1a\t.GiftCard\x12>\n" +
+	"\rListGiftCards\x12\x15.ListGiftCardsRequest\x1a\x16.ListGiftCardsResponseB4Z2github.com/sakashimaa/go-pet-project/proto/paymentb\x06proto3"
+
+var (
+	file_proto_payment_payment_proto_rawDescOnce sync.Once
+	file_proto_payment_payment_proto_rawDescData []byte
+)
+
+func file_proto_payment_payment_proto_rawDescGZIP() []byte {
+	file_proto_payment_payment_proto_rawDescOnce.Do(func() {
+		file_proto_payment_payment_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_payment_payment_proto_rawDesc), len(file_proto_payment_payment_proto_rawDesc)))
+	})
+	return file_proto_payment_payment_proto_rawDescData
+}
+
+var file_proto_payment_payment_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_proto_payment_payment_proto_goTypes = []any{
+	(*BalanceSheetPeriod)(nil),          // 0: BalanceSheetPeriod
+	(*GetBalanceSheetRequest)(nil),      // 1: GetBalanceSheetRequest
+	(*AccountBalance)(nil),              // 2: AccountBalance
+	(*GetBalanceSheetResponse)(nil),     // 3: GetBalanceSheetResponse
+	(*PaymentMethod)(nil),               // 4: PaymentMethod
+	(*AddPaymentMethodRequest)(nil),     // 5: AddPaymentMethodRequest
+	(*ListPaymentMethodsRequest)(nil),   // 6: ListPaymentMethodsRequest
+	(*ListPaymentMethodsResponse)(nil),  // 7: ListPaymentMethodsResponse
+	(*DeletePaymentMethodRequest)(nil),  // 8: DeletePaymentMethodRequest
+	(*DeletePaymentMethodResponse)(nil), // 9: DeletePaymentMethodResponse
+	(*GiftCard)(nil),                    // 10: GiftCard
+	(*IssueGiftCardRequest)(nil),        // 11: IssueGiftCardRequest
+	(*IssueGiftCardResponse)(nil),       // 12: IssueGiftCardResponse
+	(*VoidGiftCardRequest)(nil),         // 13: VoidGiftCardRequest
+	(*ClaimGiftCardRequest)(nil),        // 14: ClaimGiftCardRequest
+	(*ListGiftCardsRequest)(nil),        // 15: ListGiftCardsRequest
+	(*ListGiftCardsResponse)(nil),       // 16: ListGiftCardsResponse
+}
+var file_proto_payment_payment_proto_depIdxs = []int32{
+	0,  // 0: GetBalanceSheetRequest.range:type_name -> BalanceSheetPeriod
+	2,  // 1: GetBalanceSheetResponse.accounts:type_name -> AccountBalance
+	4,  // 2: ListPaymentMethodsResponse.payment_methods:type_name -> PaymentMethod
+	10, // 3: IssueGiftCardResponse.gift_card:type_name -> GiftCard
+	10, // 4: ListGiftCardsResponse.gift_cards:type_name -> GiftCard
+	1,  // 5: PaymentService.GetBalanceSheet:input_type -> GetBalanceSheetRequest
+	5,  // 6: PaymentService.AddPaymentMethod:input_type -> AddPaymentMethodRequest
+	6,  // 7: PaymentService.ListPaymentMethods:input_type -> ListPaymentMethodsRequest
+	8,  // 8: PaymentService.DeletePaymentMethod:input_type -> DeletePaymentMethodRequest
+	11, // 9: PaymentService.IssueGiftCard:input_type -> IssueGiftCardRequest
+	13, // 10: PaymentService.VoidGiftCard:input_type -> VoidGiftCardRequest
+	14, // 11: PaymentService.ClaimGiftCard:input_type -> ClaimGiftCardRequest
+	15, // 12: PaymentService.ListGiftCards:input_type -> ListGiftCardsRequest
+	3,  // 13: PaymentService.GetBalanceSheet:output_type -> GetBalanceSheetResponse
+	4,  // 14: PaymentService.AddPaymentMethod:output_type -> PaymentMethod
+	7,  // 15: PaymentService.ListPaymentMethods:output_type -> ListPaymentMethodsResponse
+	9,  // 16: PaymentService.DeletePaymentMethod:output_type -> DeletePaymentMethodResponse
+	12, // 17: PaymentService.IssueGiftCard:output_type -> IssueGiftCardResponse
+	10, // 18: PaymentService.VoidGiftCard:output_type -> GiftCard
+	10, // 19: PaymentService.ClaimGiftCard:output_type -> GiftCard
+	16, // 20: PaymentService.ListGiftCards:output_type -> ListGiftCardsResponse
+	13, // [13:21] is the sub-list for method output_type
+	5,  // [5:13] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_proto_payment_payment_proto_init() }
+func file_proto_payment_payment_proto_init() {
+	if File_proto_payment_payment_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_payment_payment_proto_rawDesc), len(file_proto_payment_payment_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_payment_payment_proto_goTypes,
+		DependencyIndexes: file_proto_payment_payment_proto_depIdxs,
+		MessageInfos:      file_proto_payment_payment_proto_msgTypes,
+	}.Build()
+	File_proto_payment_payment_proto = out.File
+	file_proto_payment_payment_proto_goTypes = nil
+	file_proto_payment_payment_proto_depIdxs = nil
+}
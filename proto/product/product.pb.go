@@ -21,6 +21,64 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type ProductSort int32
+
+const (
+	ProductSort_PRODUCT_SORT_UNSPECIFIED ProductSort = 0
+	ProductSort_PRODUCT_SORT_NEWEST      ProductSort = 1
+	ProductSort_PRODUCT_SORT_PRICE_ASC   ProductSort = 2
+	ProductSort_PRODUCT_SORT_PRICE_DESC  ProductSort = 3
+	ProductSort_PRODUCT_SORT_RATING      ProductSort = 4
+	ProductSort_PRODUCT_SORT_STOCK_ASC   ProductSort = 5
+)
+
+// Enum value maps for ProductSort.
+var (
+	ProductSort_name = map[int32]string{
+		0: "PRODUCT_SORT_UNSPECIFIED",
+		1: "PRODUCT_SORT_NEWEST",
+		2: "PRODUCT_SORT_PRICE_ASC",
+		3: "PRODUCT_SORT_PRICE_DESC",
+		4: "PRODUCT_SORT_RATING",
+		5: "PRODUCT_SORT_STOCK_ASC",
+	}
+	ProductSort_value = map[string]int32{
+		"PRODUCT_SORT_UNSPECIFIED": 0,
+		"PRODUCT_SORT_NEWEST":      1,
+		"PRODUCT_SORT_PRICE_ASC":   2,
+		"PRODUCT_SORT_PRICE_DESC":  3,
+		"PRODUCT_SORT_RATING":      4,
+		"PRODUCT_SORT_STOCK_ASC":   5,
+	}
+)
+
+func (x ProductSort) Enum() *ProductSort {
+	p := new(ProductSort)
+	*p = x
+	return p
+}
+
+func (x ProductSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ProductSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_product_product_proto_enumTypes[0].Descriptor()
+}
+
+func (ProductSort) Type() protoreflect.EnumType {
+	return &file_proto_product_product_proto_enumTypes[0]
+}
+
+func (x ProductSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ProductSort.Descriptor instead.
+func (ProductSort) EnumDescriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{0}
+}
+
 type Product struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -30,8 +88,18 @@ type Product struct {
 	StockQuantity int64                  `protobuf:"varint,5,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
 	ImageUrl      string                 `protobuf:"bytes,6,opt,name=image_url,json=imageUrl,proto3" json:"image_url,omitempty"`
 	Category      string                 `protobuf:"bytes,7,opt,name=category,proto3" json:"category,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	Sku           string                 `protobuf:"bytes,8,opt,name=sku,proto3" json:"sku,omitempty"`
+	Ean           string                 `protobuf:"bytes,9,opt,name=ean,proto3" json:"ean,omitempty"`
+	Attributes    map[string]string      `protobuf:"bytes,10,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Rating        float64                `protobuf:"fixed64,11,opt,name=rating,proto3" json:"rating,omitempty"`
+	// deleted_at is set only for soft-deleted products.
+	DeletedAt string `protobuf:"bytes,12,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	// effective_price is what the product sells for now: price less the
+	// discount of its promotion, if any.
+	EffectivePrice int64             `protobuf:"varint,13,opt,name=effective_price,json=effectivePrice,proto3" json:"effective_price,omitempty"`
+	Promotion      *ProductPromotion `protobuf:"bytes,14,opt,name=promotion,proto3" json:"promotion,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *Product) Reset() {
@@ -113,6 +181,126 @@ func (x *Product) GetCategory() string {
 	return ""
 }
 
+func (x *Product) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *Product) GetEan() string {
+	if x != nil {
+		return x.Ean
+	}
+	return ""
+}
+
+func (x *Product) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *Product) GetRating() float64 {
+	if x != nil {
+		return x.Rating
+	}
+	return 0
+}
+
+func (x *Product) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+func (x *Product) GetEffectivePrice() int64 {
+	if x != nil {
+		return x.EffectivePrice
+	}
+	return 0
+}
+
+func (x *Product) GetPromotion() *ProductPromotion {
+	if x != nil {
+		return x.Promotion
+	}
+	return nil
+}
+
+// ProductPromotion is the campaign discounting a product. When several
+// campaigns overlap, the biggest discount wins.
+type ProductPromotion struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	CampaignId      int64                  `protobuf:"varint,1,opt,name=campaign_id,json=campaignId,proto3" json:"campaign_id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DiscountPercent int32                  `protobuf:"varint,3,opt,name=discount_percent,json=discountPercent,proto3" json:"discount_percent,omitempty"`
+	// ends_at is RFC 3339.
+	EndsAt        string `protobuf:"bytes,4,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductPromotion) Reset() {
+	*x = ProductPromotion{}
+	mi := &file_proto_product_product_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductPromotion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductPromotion) ProtoMessage() {}
+
+func (x *ProductPromotion) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductPromotion.ProtoReflect.Descriptor instead.
+func (*ProductPromotion) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProductPromotion) GetCampaignId() int64 {
+	if x != nil {
+		return x.CampaignId
+	}
+	return 0
+}
+
+func (x *ProductPromotion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ProductPromotion) GetDiscountPercent() int32 {
+	if x != nil {
+		return x.DiscountPercent
+	}
+	return 0
+}
+
+func (x *ProductPromotion) GetEndsAt() string {
+	if x != nil {
+		return x.EndsAt
+	}
+	return ""
+}
+
 type CreateProductRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
@@ -120,13 +308,16 @@ type CreateProductRequest struct {
 	Price         int64                  `protobuf:"varint,3,opt,name=price,proto3" json:"price,omitempty"`
 	StockQuantity int64                  `protobuf:"varint,4,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
 	Category      string                 `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`
+	Sku           string                 `protobuf:"bytes,6,opt,name=sku,proto3" json:"sku,omitempty"`
+	Ean           string                 `protobuf:"bytes,7,opt,name=ean,proto3" json:"ean,omitempty"`
+	Attributes    map[string]string      `protobuf:"bytes,8,rep,name=attributes,proto3" json:"attributes,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateProductRequest) Reset() {
 	*x = CreateProductRequest{}
-	mi := &file_proto_product_product_proto_msgTypes[1]
+	mi := &file_proto_product_product_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -138,7 +329,7 @@ func (x *CreateProductRequest) String() string {
 func (*CreateProductRequest) ProtoMessage() {}
 
 func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[1]
+	mi := &file_proto_product_product_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -151,7 +342,7 @@ func (x *CreateProductRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateProductRequest.ProtoReflect.Descriptor instead.
 func (*CreateProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{1}
+	return file_proto_product_product_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *CreateProductRequest) GetName() string {
@@ -189,6 +380,27 @@ func (x *CreateProductRequest) GetCategory() string {
 	return ""
 }
 
+func (x *CreateProductRequest) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *CreateProductRequest) GetEan() string {
+	if x != nil {
+		return x.Ean
+	}
+	return ""
+}
+
+func (x *CreateProductRequest) GetAttributes() map[string]string {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
 type CreateProductResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
@@ -198,7 +410,7 @@ type CreateProductResponse struct {
 
 func (x *CreateProductResponse) Reset() {
 	*x = CreateProductResponse{}
-	mi := &file_proto_product_product_proto_msgTypes[2]
+	mi := &file_proto_product_product_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -210,7 +422,7 @@ func (x *CreateProductResponse) String() string {
 func (*CreateProductResponse) ProtoMessage() {}
 
 func (x *CreateProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[2]
+	mi := &file_proto_product_product_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -223,7 +435,7 @@ func (x *CreateProductResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateProductResponse.ProtoReflect.Descriptor instead.
 func (*CreateProductResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{2}
+	return file_proto_product_product_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CreateProductResponse) GetId() int64 {
@@ -242,7 +454,7 @@ type GetProductRequest struct {
 
 func (x *GetProductRequest) Reset() {
 	*x = GetProductRequest{}
-	mi := &file_proto_product_product_proto_msgTypes[3]
+	mi := &file_proto_product_product_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -254,7 +466,7 @@ func (x *GetProductRequest) String() string {
 func (*GetProductRequest) ProtoMessage() {}
 
 func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[3]
+	mi := &file_proto_product_product_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -267,7 +479,7 @@ func (x *GetProductRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetProductRequest.ProtoReflect.Descriptor instead.
 func (*GetProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{3}
+	return file_proto_product_product_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GetProductRequest) GetId() int64 {
@@ -277,28 +489,28 @@ func (x *GetProductRequest) GetId() int64 {
 	return 0
 }
 
-type GetProductResponse struct {
+type GetProductBySKURequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
+	Sku           string                 `protobuf:"bytes,1,opt,name=sku,proto3" json:"sku,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetProductResponse) Reset() {
-	*x = GetProductResponse{}
-	mi := &file_proto_product_product_proto_msgTypes[4]
+func (x *GetProductBySKURequest) Reset() {
+	*x = GetProductBySKURequest{}
+	mi := &file_proto_product_product_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetProductResponse) String() string {
+func (x *GetProductBySKURequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetProductResponse) ProtoMessage() {}
+func (*GetProductBySKURequest) ProtoMessage() {}
 
-func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[4]
+func (x *GetProductBySKURequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -309,42 +521,40 @@ func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetProductResponse.ProtoReflect.Descriptor instead.
-func (*GetProductResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use GetProductBySKURequest.ProtoReflect.Descriptor instead.
+func (*GetProductBySKURequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *GetProductResponse) GetProduct() *Product {
+func (x *GetProductBySKURequest) GetSku() string {
 	if x != nil {
-		return x.Product
+		return x.Sku
 	}
-	return nil
+	return ""
 }
 
-type ListProductsRequest struct {
+type GetProductResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Offset        int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
-	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
-	Search        string                 `protobuf:"bytes,3,opt,name=search,proto3" json:"search,omitempty"`
+	Product       *Product               `protobuf:"bytes,1,opt,name=product,proto3" json:"product,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListProductsRequest) Reset() {
-	*x = ListProductsRequest{}
-	mi := &file_proto_product_product_proto_msgTypes[5]
+func (x *GetProductResponse) Reset() {
+	*x = GetProductResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListProductsRequest) String() string {
+func (x *GetProductResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListProductsRequest) ProtoMessage() {}
+func (*GetProductResponse) ProtoMessage() {}
 
-func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[5]
+func (x *GetProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -355,55 +565,41 @@ func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
-func (*ListProductsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *ListProductsRequest) GetOffset() int64 {
-	if x != nil {
-		return x.Offset
-	}
-	return 0
-}
-
-func (x *ListProductsRequest) GetLimit() int64 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
+// Deprecated: Use GetProductResponse.ProtoReflect.Descriptor instead.
+func (*GetProductResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ListProductsRequest) GetSearch() string {
+func (x *GetProductResponse) GetProduct() *Product {
 	if x != nil {
-		return x.Search
+		return x.Product
 	}
-	return ""
+	return nil
 }
 
-type ListProductsResponse struct {
+type AttributeFilter struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
-	TotalCount    int64                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Values        []string               `protobuf:"bytes,2,rep,name=values,proto3" json:"values,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListProductsResponse) Reset() {
-	*x = ListProductsResponse{}
-	mi := &file_proto_product_product_proto_msgTypes[6]
+func (x *AttributeFilter) Reset() {
+	*x = AttributeFilter{}
+	mi := &file_proto_product_product_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListProductsResponse) String() string {
+func (x *AttributeFilter) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListProductsResponse) ProtoMessage() {}
+func (*AttributeFilter) ProtoMessage() {}
 
-func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[6]
+func (x *AttributeFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -414,48 +610,58 @@ func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
-func (*ListProductsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use AttributeFilter.ProtoReflect.Descriptor instead.
+func (*AttributeFilter) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *ListProductsResponse) GetProducts() []*Product {
+func (x *AttributeFilter) GetKey() string {
 	if x != nil {
-		return x.Products
+		return x.Key
 	}
-	return nil
+	return ""
 }
 
-func (x *ListProductsResponse) GetTotalCount() int64 {
+func (x *AttributeFilter) GetValues() []string {
 	if x != nil {
-		return x.TotalCount
+		return x.Values
 	}
-	return 0
+	return nil
 }
 
-type DecreaseStockRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
-	Quantity      int64                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+type ListProductsRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Offset      int64                  `protobuf:"varint,1,opt,name=offset,proto3" json:"offset,omitempty"`
+	Limit       int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Search      string                 `protobuf:"bytes,3,opt,name=search,proto3" json:"search,omitempty"`
+	Categories  []string               `protobuf:"bytes,4,rep,name=categories,proto3" json:"categories,omitempty"`
+	PriceMin    int64                  `protobuf:"varint,5,opt,name=price_min,json=priceMin,proto3" json:"price_min,omitempty"`
+	PriceMax    int64                  `protobuf:"varint,6,opt,name=price_max,json=priceMax,proto3" json:"price_max,omitempty"`
+	InStockOnly bool                   `protobuf:"varint,7,opt,name=in_stock_only,json=inStockOnly,proto3" json:"in_stock_only,omitempty"`
+	Attributes  []*AttributeFilter     `protobuf:"bytes,8,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	Sort        ProductSort            `protobuf:"varint,9,opt,name=sort,proto3,enum=ProductSort" json:"sort,omitempty"`
+	// stock_max keeps products with at most that many units left; 0 means no
+	// limit.
+	StockMax      int64 `protobuf:"varint,10,opt,name=stock_max,json=stockMax,proto3" json:"stock_max,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DecreaseStockRequest) Reset() {
-	*x = DecreaseStockRequest{}
-	mi := &file_proto_product_product_proto_msgTypes[7]
+func (x *ListProductsRequest) Reset() {
+	*x = ListProductsRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DecreaseStockRequest) String() string {
+func (x *ListProductsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DecreaseStockRequest) ProtoMessage() {}
+func (*ListProductsRequest) ProtoMessage() {}
 
-func (x *DecreaseStockRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[7]
+func (x *ListProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -466,48 +672,1799 @@ func (x *DecreaseStockRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DecreaseStockRequest.ProtoReflect.Descriptor instead.
-func (*DecreaseStockRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use ListProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *DecreaseStockRequest) GetProductId() int64 {
+func (x *ListProductsRequest) GetOffset() int64 {
 	if x != nil {
-		return x.ProductId
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ListProductsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListProductsRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListProductsRequest) GetCategories() []string {
+	if x != nil {
+		return x.Categories
+	}
+	return nil
+}
+
+func (x *ListProductsRequest) GetPriceMin() int64 {
+	if x != nil {
+		return x.PriceMin
+	}
+	return 0
+}
+
+func (x *ListProductsRequest) GetPriceMax() int64 {
+	if x != nil {
+		return x.PriceMax
+	}
+	return 0
+}
+
+func (x *ListProductsRequest) GetInStockOnly() bool {
+	if x != nil {
+		return x.InStockOnly
+	}
+	return false
+}
+
+func (x *ListProductsRequest) GetAttributes() []*AttributeFilter {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *ListProductsRequest) GetSort() ProductSort {
+	if x != nil {
+		return x.Sort
+	}
+	return ProductSort_PRODUCT_SORT_UNSPECIFIED
+}
+
+func (x *ListProductsRequest) GetStockMax() int64 {
+	if x != nil {
+		return x.StockMax
+	}
+	return 0
+}
+
+type ListProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	TotalCount    int64                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProductsResponse) Reset() {
+	*x = ListProductsResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProductsResponse) ProtoMessage() {}
+
+func (x *ListProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProductsResponse.ProtoReflect.Descriptor instead.
+func (*ListProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ListProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+func (x *ListProductsResponse) GetTotalCount() int64 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+type DecreaseStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Quantity      int64                  `protobuf:"varint,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecreaseStockRequest) Reset() {
+	*x = DecreaseStockRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecreaseStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecreaseStockRequest) ProtoMessage() {}
+
+func (x *DecreaseStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecreaseStockRequest.ProtoReflect.Descriptor instead.
+func (*DecreaseStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *DecreaseStockRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
 	}
 	return 0
 }
 
 func (x *DecreaseStockRequest) GetQuantity() int64 {
 	if x != nil {
-		return x.Quantity
+		return x.Quantity
+	}
+	return 0
+}
+
+type DecreaseStockResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Success bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	Message string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Units left after the decrease.
+	StockQuantity int64 `protobuf:"varint,3,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DecreaseStockResponse) Reset() {
+	*x = DecreaseStockResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecreaseStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecreaseStockResponse) ProtoMessage() {}
+
+func (x *DecreaseStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecreaseStockResponse.ProtoReflect.Descriptor instead.
+func (*DecreaseStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DecreaseStockResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *DecreaseStockResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *DecreaseStockResponse) GetStockQuantity() int64 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+type DeleteProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductRequest) Reset() {
+	*x = DeleteProductRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductRequest) ProtoMessage() {}
+
+func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
+func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteProductRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteProductResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteProductResponse) Reset() {
+	*x = DeleteProductResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteProductResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteProductResponse) ProtoMessage() {}
+
+func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
+func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteProductResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type RestoreProductRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreProductRequest) Reset() {
+	*x = RestoreProductRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreProductRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreProductRequest) ProtoMessage() {}
+
+func (x *RestoreProductRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreProductRequest.ProtoReflect.Descriptor instead.
+func (*RestoreProductRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RestoreProductRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListDeletedProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int64                  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDeletedProductsRequest) Reset() {
+	*x = ListDeletedProductsRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDeletedProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDeletedProductsRequest) ProtoMessage() {}
+
+func (x *ListDeletedProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDeletedProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListDeletedProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListDeletedProductsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListDeletedProductsRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type GetProductHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductHistoryRequest) Reset() {
+	*x = GetProductHistoryRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductHistoryRequest) ProtoMessage() {}
+
+func (x *GetProductHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetProductHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetProductHistoryRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *GetProductHistoryRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetProductHistoryRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ProductRevision struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	ProductId int64                  `protobuf:"varint,2,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Action    string                 `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	Actor     string                 `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	// changes is a JSON object of field name to {"from": ..., "to": ...}.
+	Changes       string `protobuf:"bytes,5,opt,name=changes,proto3" json:"changes,omitempty"`
+	CreatedAt     string `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProductRevision) Reset() {
+	*x = ProductRevision{}
+	mi := &file_proto_product_product_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProductRevision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProductRevision) ProtoMessage() {}
+
+func (x *ProductRevision) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProductRevision.ProtoReflect.Descriptor instead.
+func (*ProductRevision) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ProductRevision) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ProductRevision) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *ProductRevision) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ProductRevision) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ProductRevision) GetChanges() string {
+	if x != nil {
+		return x.Changes
+	}
+	return ""
+}
+
+func (x *ProductRevision) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type GetProductHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revisions     []*ProductRevision     `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductHistoryResponse) Reset() {
+	*x = GetProductHistoryResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductHistoryResponse) ProtoMessage() {}
+
+func (x *GetProductHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetProductHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *GetProductHistoryResponse) GetRevisions() []*ProductRevision {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+type Warehouse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Code  string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Name  string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	// latitude and longitude are meaningful only when has_location is set.
+	HasLocation   bool    `protobuf:"varint,4,opt,name=has_location,json=hasLocation,proto3" json:"has_location,omitempty"`
+	Latitude      float64 `protobuf:"fixed64,5,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64 `protobuf:"fixed64,6,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Priority      int32   `protobuf:"varint,7,opt,name=priority,proto3" json:"priority,omitempty"`
+	IsActive      bool    `protobuf:"varint,8,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Warehouse) Reset() {
+	*x = Warehouse{}
+	mi := &file_proto_product_product_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Warehouse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Warehouse) ProtoMessage() {}
+
+func (x *Warehouse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Warehouse.ProtoReflect.Descriptor instead.
+func (*Warehouse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Warehouse) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Warehouse) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Warehouse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Warehouse) GetHasLocation() bool {
+	if x != nil {
+		return x.HasLocation
+	}
+	return false
+}
+
+func (x *Warehouse) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Warehouse) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Warehouse) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Warehouse) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+type CreateWarehouseRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	HasLocation   bool                   `protobuf:"varint,3,opt,name=has_location,json=hasLocation,proto3" json:"has_location,omitempty"`
+	Latitude      float64                `protobuf:"fixed64,4,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,5,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Priority      int32                  `protobuf:"varint,6,opt,name=priority,proto3" json:"priority,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateWarehouseRequest) Reset() {
+	*x = CreateWarehouseRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateWarehouseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateWarehouseRequest) ProtoMessage() {}
+
+func (x *CreateWarehouseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateWarehouseRequest.ProtoReflect.Descriptor instead.
+func (*CreateWarehouseRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *CreateWarehouseRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CreateWarehouseRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateWarehouseRequest) GetHasLocation() bool {
+	if x != nil {
+		return x.HasLocation
+	}
+	return false
+}
+
+func (x *CreateWarehouseRequest) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *CreateWarehouseRequest) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *CreateWarehouseRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+type ListWarehousesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWarehousesRequest) Reset() {
+	*x = ListWarehousesRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWarehousesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWarehousesRequest) ProtoMessage() {}
+
+func (x *ListWarehousesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWarehousesRequest.ProtoReflect.Descriptor instead.
+func (*ListWarehousesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{21}
+}
+
+type ListWarehousesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Warehouses    []*Warehouse           `protobuf:"bytes,1,rep,name=warehouses,proto3" json:"warehouses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWarehousesResponse) Reset() {
+	*x = ListWarehousesResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWarehousesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWarehousesResponse) ProtoMessage() {}
+
+func (x *ListWarehousesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWarehousesResponse.ProtoReflect.Descriptor instead.
+func (*ListWarehousesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ListWarehousesResponse) GetWarehouses() []*Warehouse {
+	if x != nil {
+		return x.Warehouses
+	}
+	return nil
+}
+
+type GetProductStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductStockRequest) Reset() {
+	*x = GetProductStockRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductStockRequest) ProtoMessage() {}
+
+func (x *GetProductStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductStockRequest.ProtoReflect.Descriptor instead.
+func (*GetProductStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetProductStockRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+type WarehouseStock struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	WarehouseId   int64                  `protobuf:"varint,1,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"`
+	WarehouseCode string                 `protobuf:"bytes,2,opt,name=warehouse_code,json=warehouseCode,proto3" json:"warehouse_code,omitempty"`
+	Quantity      int64                  `protobuf:"varint,3,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WarehouseStock) Reset() {
+	*x = WarehouseStock{}
+	mi := &file_proto_product_product_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WarehouseStock) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WarehouseStock) ProtoMessage() {}
+
+func (x *WarehouseStock) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WarehouseStock.ProtoReflect.Descriptor instead.
+func (*WarehouseStock) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *WarehouseStock) GetWarehouseId() int64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
+func (x *WarehouseStock) GetWarehouseCode() string {
+	if x != nil {
+		return x.WarehouseCode
+	}
+	return ""
+}
+
+func (x *WarehouseStock) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type GetProductStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Stock         []*WarehouseStock      `protobuf:"bytes,1,rep,name=stock,proto3" json:"stock,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProductStockResponse) Reset() {
+	*x = GetProductStockResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProductStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProductStockResponse) ProtoMessage() {}
+
+func (x *GetProductStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProductStockResponse.ProtoReflect.Descriptor instead.
+func (*GetProductStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetProductStockResponse) GetStock() []*WarehouseStock {
+	if x != nil {
+		return x.Stock
+	}
+	return nil
+}
+
+type TransferStockRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ProductId       int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	FromWarehouseId int64                  `protobuf:"varint,2,opt,name=from_warehouse_id,json=fromWarehouseId,proto3" json:"from_warehouse_id,omitempty"`
+	ToWarehouseId   int64                  `protobuf:"varint,3,opt,name=to_warehouse_id,json=toWarehouseId,proto3" json:"to_warehouse_id,omitempty"`
+	Quantity        int64                  `protobuf:"varint,4,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransferStockRequest) Reset() {
+	*x = TransferStockRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferStockRequest) ProtoMessage() {}
+
+func (x *TransferStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferStockRequest.ProtoReflect.Descriptor instead.
+func (*TransferStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *TransferStockRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *TransferStockRequest) GetFromWarehouseId() int64 {
+	if x != nil {
+		return x.FromWarehouseId
+	}
+	return 0
+}
+
+func (x *TransferStockRequest) GetToWarehouseId() int64 {
+	if x != nil {
+		return x.ToWarehouseId
+	}
+	return 0
+}
+
+func (x *TransferStockRequest) GetQuantity() int64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+type TransferStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TransferStockResponse) Reset() {
+	*x = TransferStockResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransferStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransferStockResponse) ProtoMessage() {}
+
+func (x *TransferStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransferStockResponse.ProtoReflect.Descriptor instead.
+func (*TransferStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *TransferStockResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+type AdjustStockRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	WarehouseId   int64                  `protobuf:"varint,2,opt,name=warehouse_id,json=warehouseId,proto3" json:"warehouse_id,omitempty"`
+	Delta         int64                  `protobuf:"varint,3,opt,name=delta,proto3" json:"delta,omitempty"`
+	Reason        string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Note          string                 `protobuf:"bytes,5,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustStockRequest) Reset() {
+	*x = AdjustStockRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustStockRequest) ProtoMessage() {}
+
+func (x *AdjustStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustStockRequest.ProtoReflect.Descriptor instead.
+func (*AdjustStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *AdjustStockRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetWarehouseId() int64 {
+	if x != nil {
+		return x.WarehouseId
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetDelta() int64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *AdjustStockRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *AdjustStockRequest) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+type AdjustStockResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	StockQuantity int64                  `protobuf:"varint,1,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdjustStockResponse) Reset() {
+	*x = AdjustStockResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdjustStockResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdjustStockResponse) ProtoMessage() {}
+
+func (x *AdjustStockResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdjustStockResponse.ProtoReflect.Descriptor instead.
+func (*AdjustStockResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *AdjustStockResponse) GetStockQuantity() int64 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+type WatchStockRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Up to 100 products.
+	ProductIds    []int64 `protobuf:"varint,1,rep,packed,name=product_ids,json=productIds,proto3" json:"product_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchStockRequest) Reset() {
+	*x = WatchStockRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchStockRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchStockRequest) ProtoMessage() {}
+
+func (x *WatchStockRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchStockRequest.ProtoReflect.Descriptor instead.
+func (*WatchStockRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *WatchStockRequest) GetProductIds() []int64 {
+	if x != nil {
+		return x.ProductIds
+	}
+	return nil
+}
+
+// StockUpdate is a product's whole stock level, not a delta.
+type StockUpdate struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	StockQuantity int64                  `protobuf:"varint,2,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	Warehouses    []*WarehouseStock      `protobuf:"bytes,3,rep,name=warehouses,proto3" json:"warehouses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StockUpdate) Reset() {
+	*x = StockUpdate{}
+	mi := &file_proto_product_product_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StockUpdate) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StockUpdate) ProtoMessage() {}
+
+func (x *StockUpdate) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StockUpdate.ProtoReflect.Descriptor instead.
+func (*StockUpdate) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *StockUpdate) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *StockUpdate) GetStockQuantity() int64 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+func (x *StockUpdate) GetWarehouses() []*WarehouseStock {
+	if x != nil {
+		return x.Warehouses
+	}
+	return nil
+}
+
+type GetRelatedProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProductId     int64                  `protobuf:"varint,1,opt,name=product_id,json=productId,proto3" json:"product_id,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRelatedProductsRequest) Reset() {
+	*x = GetRelatedProductsRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRelatedProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRelatedProductsRequest) ProtoMessage() {}
+
+func (x *GetRelatedProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRelatedProductsRequest.ProtoReflect.Descriptor instead.
+func (*GetRelatedProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetRelatedProductsRequest) GetProductId() int64 {
+	if x != nil {
+		return x.ProductId
+	}
+	return 0
+}
+
+func (x *GetRelatedProductsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetRelatedProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRelatedProductsResponse) Reset() {
+	*x = GetRelatedProductsResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRelatedProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRelatedProductsResponse) ProtoMessage() {}
+
+func (x *GetRelatedProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRelatedProductsResponse.ProtoReflect.Descriptor instead.
+func (*GetRelatedProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetRelatedProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type GetPersonalizedProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        int64                  `protobuf:"varint,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonalizedProductsRequest) Reset() {
+	*x = GetPersonalizedProductsRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonalizedProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonalizedProductsRequest) ProtoMessage() {}
+
+func (x *GetPersonalizedProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonalizedProductsRequest.ProtoReflect.Descriptor instead.
+func (*GetPersonalizedProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *GetPersonalizedProductsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetPersonalizedProductsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetPersonalizedProductsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Products      []*Product             `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPersonalizedProductsResponse) Reset() {
+	*x = GetPersonalizedProductsResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPersonalizedProductsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPersonalizedProductsResponse) ProtoMessage() {}
+
+func (x *GetPersonalizedProductsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPersonalizedProductsResponse.ProtoReflect.Descriptor instead.
+func (*GetPersonalizedProductsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *GetPersonalizedProductsResponse) GetProducts() []*Product {
+	if x != nil {
+		return x.Products
+	}
+	return nil
+}
+
+type ExportProductsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// category keeps only products in that category; empty exports all of
+	// them.
+	Category       string `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"`
+	IncludeDeleted bool   `protobuf:"varint,2,opt,name=include_deleted,json=includeDeleted,proto3" json:"include_deleted,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExportProductsRequest) Reset() {
+	*x = ExportProductsRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportProductsRequest) ProtoMessage() {}
+
+func (x *ExportProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportProductsRequest.ProtoReflect.Descriptor instead.
+func (*ExportProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ExportProductsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ExportProductsRequest) GetIncludeDeleted() bool {
+	if x != nil {
+		return x.IncludeDeleted
+	}
+	return false
+}
+
+// ExportedProduct is one catalog row. Prices are in minor units.
+type ExportedProduct struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Id       int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Sku      string                 `protobuf:"bytes,2,opt,name=sku,proto3" json:"sku,omitempty"`
+	Ean      string                 `protobuf:"bytes,3,opt,name=ean,proto3" json:"ean,omitempty"`
+	Name     string                 `protobuf:"bytes,4,opt,name=name,proto3" json:"name,omitempty"`
+	Category string                 `protobuf:"bytes,5,opt,name=category,proto3" json:"category,omitempty"`
+	// category_path is the category split into its levels, top first.
+	CategoryPath  []string `protobuf:"bytes,6,rep,name=category_path,json=categoryPath,proto3" json:"category_path,omitempty"`
+	Price         int64    `protobuf:"varint,7,opt,name=price,proto3" json:"price,omitempty"`
+	StockQuantity int64    `protobuf:"varint,8,opt,name=stock_quantity,json=stockQuantity,proto3" json:"stock_quantity,omitempty"`
+	// warehouse_stock is the stock per warehouse code.
+	WarehouseStock map[string]int64 `protobuf:"bytes,9,rep,name=warehouse_stock,json=warehouseStock,proto3" json:"warehouse_stock,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	// The price history covers every price the product had, the current one
+	// included. price_changed_at is empty until the price first changes.
+	MinPrice       int64  `protobuf:"varint,10,opt,name=min_price,json=minPrice,proto3" json:"min_price,omitempty"`
+	MaxPrice       int64  `protobuf:"varint,11,opt,name=max_price,json=maxPrice,proto3" json:"max_price,omitempty"`
+	PriceChanges   int64  `protobuf:"varint,12,opt,name=price_changes,json=priceChanges,proto3" json:"price_changes,omitempty"`
+	PriceChangedAt string `protobuf:"bytes,13,opt,name=price_changed_at,json=priceChangedAt,proto3" json:"price_changed_at,omitempty"`
+	CreatedAt      string `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	DeletedAt      string `protobuf:"bytes,15,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ExportedProduct) Reset() {
+	*x = ExportedProduct{}
+	mi := &file_proto_product_product_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportedProduct) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportedProduct) ProtoMessage() {}
+
+func (x *ExportedProduct) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportedProduct.ProtoReflect.Descriptor instead.
+func (*ExportedProduct) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ExportedProduct) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetSku() string {
+	if x != nil {
+		return x.Sku
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetEan() string {
+	if x != nil {
+		return x.Ean
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetCategoryPath() []string {
+	if x != nil {
+		return x.CategoryPath
+	}
+	return nil
+}
+
+func (x *ExportedProduct) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetStockQuantity() int64 {
+	if x != nil {
+		return x.StockQuantity
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetWarehouseStock() map[string]int64 {
+	if x != nil {
+		return x.WarehouseStock
+	}
+	return nil
+}
+
+func (x *ExportedProduct) GetMinPrice() int64 {
+	if x != nil {
+		return x.MinPrice
 	}
 	return 0
 }
 
-type DecreaseStockResponse struct {
+func (x *ExportedProduct) GetMaxPrice() int64 {
+	if x != nil {
+		return x.MaxPrice
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetPriceChanges() int64 {
+	if x != nil {
+		return x.PriceChanges
+	}
+	return 0
+}
+
+func (x *ExportedProduct) GetPriceChangedAt() string {
+	if x != nil {
+		return x.PriceChangedAt
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *ExportedProduct) GetDeletedAt() string {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return ""
+}
+
+type ExportProductsChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Products      []*ExportedProduct     `protobuf:"bytes,1,rep,name=products,proto3" json:"products,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DecreaseStockResponse) Reset() {
-	*x = DecreaseStockResponse{}
-	mi := &file_proto_product_product_proto_msgTypes[8]
+func (x *ExportProductsChunk) Reset() {
+	*x = ExportProductsChunk{}
+	mi := &file_proto_product_product_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DecreaseStockResponse) String() string {
+func (x *ExportProductsChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DecreaseStockResponse) ProtoMessage() {}
+func (*ExportProductsChunk) ProtoMessage() {}
 
-func (x *DecreaseStockResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[8]
+func (x *ExportProductsChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -518,47 +2475,147 @@ func (x *DecreaseStockResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DecreaseStockResponse.ProtoReflect.Descriptor instead.
-func (*DecreaseStockResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use ExportProductsChunk.ProtoReflect.Descriptor instead.
+func (*ExportProductsChunk) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{38}
 }
 
-func (x *DecreaseStockResponse) GetSuccess() bool {
+func (x *ExportProductsChunk) GetProducts() []*ExportedProduct {
 	if x != nil {
-		return x.Success
+		return x.Products
 	}
-	return false
+	return nil
 }
 
-func (x *DecreaseStockResponse) GetMessage() string {
+type Campaign struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DiscountPercent int32                  `protobuf:"varint,3,opt,name=discount_percent,json=discountPercent,proto3" json:"discount_percent,omitempty"`
+	// starts_at and ends_at are RFC 3339.
+	StartsAt string `protobuf:"bytes,4,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt   string `protobuf:"bytes,5,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	// status is "scheduled", "active" or "ended".
+	Status        string  `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	ProductIds    []int64 `protobuf:"varint,7,rep,packed,name=product_ids,json=productIds,proto3" json:"product_ids,omitempty"`
+	CreatedAt     string  `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Campaign) Reset() {
+	*x = Campaign{}
+	mi := &file_proto_product_product_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Campaign) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Campaign) ProtoMessage() {}
+
+func (x *Campaign) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[39]
 	if x != nil {
-		return x.Message
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Campaign.ProtoReflect.Descriptor instead.
+func (*Campaign) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *Campaign) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Campaign) GetName() string {
+	if x != nil {
+		return x.Name
 	}
 	return ""
 }
 
-type DeleteProductRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+func (x *Campaign) GetDiscountPercent() int32 {
+	if x != nil {
+		return x.DiscountPercent
+	}
+	return 0
+}
+
+func (x *Campaign) GetStartsAt() string {
+	if x != nil {
+		return x.StartsAt
+	}
+	return ""
+}
+
+func (x *Campaign) GetEndsAt() string {
+	if x != nil {
+		return x.EndsAt
+	}
+	return ""
+}
+
+func (x *Campaign) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Campaign) GetProductIds() []int64 {
+	if x != nil {
+		return x.ProductIds
+	}
+	return nil
+}
+
+func (x *Campaign) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type CreateCampaignRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Name            string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DiscountPercent int32                  `protobuf:"varint,2,opt,name=discount_percent,json=discountPercent,proto3" json:"discount_percent,omitempty"`
+	// starts_at and ends_at are RFC 3339.
+	StartsAt      string  `protobuf:"bytes,3,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt        string  `protobuf:"bytes,4,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	ProductIds    []int64 `protobuf:"varint,5,rep,packed,name=product_ids,json=productIds,proto3" json:"product_ids,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProductRequest) Reset() {
-	*x = DeleteProductRequest{}
-	mi := &file_proto_product_product_proto_msgTypes[9]
+func (x *CreateCampaignRequest) Reset() {
+	*x = CreateCampaignRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProductRequest) String() string {
+func (x *CreateCampaignRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProductRequest) ProtoMessage() {}
+func (*CreateCampaignRequest) ProtoMessage() {}
 
-func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[9]
+func (x *CreateCampaignRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -569,40 +2626,70 @@ func (x *DeleteProductRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteProductRequest.ProtoReflect.Descriptor instead.
-func (*DeleteProductRequest) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use CreateCampaignRequest.ProtoReflect.Descriptor instead.
+func (*CreateCampaignRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{40}
 }
 
-func (x *DeleteProductRequest) GetId() int64 {
+func (x *CreateCampaignRequest) GetName() string {
 	if x != nil {
-		return x.Id
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetDiscountPercent() int32 {
+	if x != nil {
+		return x.DiscountPercent
 	}
 	return 0
 }
 
-type DeleteProductResponse struct {
+func (x *CreateCampaignRequest) GetStartsAt() string {
+	if x != nil {
+		return x.StartsAt
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetEndsAt() string {
+	if x != nil {
+		return x.EndsAt
+	}
+	return ""
+}
+
+func (x *CreateCampaignRequest) GetProductIds() []int64 {
+	if x != nil {
+		return x.ProductIds
+	}
+	return nil
+}
+
+type ListCampaignsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Success       bool                   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
+	IncludeEnded  bool                   `protobuf:"varint,1,opt,name=include_ended,json=includeEnded,proto3" json:"include_ended,omitempty"`
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteProductResponse) Reset() {
-	*x = DeleteProductResponse{}
-	mi := &file_proto_product_product_proto_msgTypes[10]
+func (x *ListCampaignsRequest) Reset() {
+	*x = ListCampaignsRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteProductResponse) String() string {
+func (x *ListCampaignsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteProductResponse) ProtoMessage() {}
+func (*ListCampaignsRequest) ProtoMessage() {}
 
-func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_product_product_proto_msgTypes[10]
+func (x *ListCampaignsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -613,23 +2700,125 @@ func (x *DeleteProductResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteProductResponse.ProtoReflect.Descriptor instead.
-func (*DeleteProductResponse) Descriptor() ([]byte, []int) {
-	return file_proto_product_product_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use ListCampaignsRequest.ProtoReflect.Descriptor instead.
+func (*ListCampaignsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *DeleteProductResponse) GetSuccess() bool {
+func (x *ListCampaignsRequest) GetIncludeEnded() bool {
 	if x != nil {
-		return x.Success
+		return x.IncludeEnded
 	}
 	return false
 }
 
+func (x *ListCampaignsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *ListCampaignsRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListCampaignsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Campaigns     []*Campaign            `protobuf:"bytes,1,rep,name=campaigns,proto3" json:"campaigns,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCampaignsResponse) Reset() {
+	*x = ListCampaignsResponse{}
+	mi := &file_proto_product_product_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCampaignsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCampaignsResponse) ProtoMessage() {}
+
+func (x *ListCampaignsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCampaignsResponse.ProtoReflect.Descriptor instead.
+func (*ListCampaignsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ListCampaignsResponse) GetCampaigns() []*Campaign {
+	if x != nil {
+		return x.Campaigns
+	}
+	return nil
+}
+
+type ListPromotedProductsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPromotedProductsRequest) Reset() {
+	*x = ListPromotedProductsRequest{}
+	mi := &file_proto_product_product_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPromotedProductsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPromotedProductsRequest) ProtoMessage() {}
+
+func (x *ListPromotedProductsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_product_product_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPromotedProductsRequest.ProtoReflect.Descriptor instead.
+func (*ListPromotedProductsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_product_product_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ListPromotedProductsRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
 var File_proto_product_product_proto protoreflect.FileDescriptor
 
 const file_proto_product_product_proto_rawDesc = "" +
 	"\n" +
-	"\x1bproto/product/product.proto\"\xc5\x01\n" +
+	"\x1bproto/product/product.proto\"\xf3\x03\n" +
 	"\aProduct\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
@@ -637,23 +2826,68 @@ const file_proto_product_product_proto_rawDesc = "" +
 	"\x05price\x18\x04 \x01(\x03R\x05price\x12%\n" +
 	"\x0estock_quantity\x18\x05 \x01(\x03R\rstockQuantity\x12\x1b\n" +
 	"\timage_url\x18\x06 \x01(\tR\bimageUrl\x12\x1a\n" +
-	"\bcategory\x18\a \x01(\tR\bcategory\"\xa5\x01\n" +
+	"\bcategory\x18\a \x01(\tR\bcategory\x12\x10\n" +
+	"\x03sku\x18\b \x01(\tR\x03sku\x12\x10\n" +
+	"\x03ean\x18\t \x01(\tR\x03ean\x128\n" +
+	"\n" +
+	"attributes\x18\n" +
+	" \x03(\v2\x18.Product.AttributesEntryR\n" +
+	"attributes\x12\x16\n" +
+	"\x06rating\x18\v \x01(\x01R\x06rating\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\f \x01(\tR\tdeletedAt\x12'\n" +
+	"\x0feffective_price\x18\r \x01(\x03R\x0eeffectivePrice\x12/\n" +
+	"\tpromotion\x18\x0e \x01(\v2\x11.ProductPromotionR\tpromotion\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x8b\x01\n" +
+	"\x10ProductPromotion\x12\x1f\n" +
+	"\vcampaign_id\x18\x01 \x01(\x03R\n" +
+	"campaignId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12)\n" +
+	"\x10discount_percent\x18\x03 \x01(\x05R\x0fdiscountPercent\x12\x17\n" +
+	"\aends_at\x18\x04 \x01(\tR\x06endsAt\"\xcf\x02\n" +
 	"\x14CreateProductRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x14\n" +
 	"\x05price\x18\x03 \x01(\x03R\x05price\x12%\n" +
 	"\x0estock_quantity\x18\x04 \x01(\x03R\rstockQuantity\x12\x1a\n" +
-	"\bcategory\x18\x05 \x01(\tR\bcategory\"'\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\x12\x10\n" +
+	"\x03sku\x18\x06 \x01(\tR\x03sku\x12\x10\n" +
+	"\x03ean\x18\a \x01(\tR\x03ean\x12E\n" +
+	"\n" +
+	"attributes\x18\b \x03(\v2%.CreateProductRequest.AttributesEntryR\n" +
+	"attributes\x1a=\n" +
+	"\x0fAttributesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"'\n" +
 	"\x15CreateProductResponse\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"#\n" +
 	"\x11GetProductRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"8\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"*\n" +
+	"\x16GetProductBySKURequest\x12\x10\n" +
+	"\x03sku\x18\x01 \x01(\tR\x03sku\"8\n" +
 	"\x12GetProductResponse\x12\"\n" +
-	"\aproduct\x18\x01 \x01(\v2\b.ProductR\aproduct\"[\n" +
+	"\aproduct\x18\x01 \x01(\v2\b.ProductR\aproduct\";\n" +
+	"\x0fAttributeFilter\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x16\n" +
+	"\x06values\x18\x02 \x03(\tR\x06values\"\xca\x02\n" +
 	"\x13ListProductsRequest\x12\x16\n" +
 	"\x06offset\x18\x01 \x01(\x03R\x06offset\x12\x14\n" +
 	"\x05limit\x18\x02 \x01(\x03R\x05limit\x12\x16\n" +
-	"\x06search\x18\x03 \x01(\tR\x06search\"]\n" +
+	"\x06search\x18\x03 \x01(\tR\x06search\x12\x1e\n" +
+	"\n" +
+	"categories\x18\x04 \x03(\tR\n" +
+	"categories\x12\x1b\n" +
+	"\tprice_min\x18\x05 \x01(\x03R\bpriceMin\x12\x1b\n" +
+	"\tprice_max\x18\x06 \x01(\x03R\bpriceMax\x12\"\n" +
+	"\rin_stock_only\x18\a \x01(\bR\vinStockOnly\x120\n" +
+	"\n" +
+	"attributes\x18\b \x03(\v2\x10.AttributeFilterR\n" +
+	"attributes\x12 \n" +
+	"\x04sort\x18\t \x01(\x0e2\f.ProductSortR\x04sort\x12\x1b\n" +
+	"\tstock_max\x18\n" +
+	" \x01(\x03R\bstockMax\"]\n" +
 	"\x14ListProductsResponse\x12$\n" +
 	"\bproducts\x18\x01 \x03(\v2\b.ProductR\bproducts\x12\x1f\n" +
 	"\vtotal_count\x18\x02 \x01(\x03R\n" +
@@ -661,21 +2895,191 @@ const file_proto_product_product_proto_rawDesc = "" +
 	"\x14DecreaseStockRequest\x12\x1d\n" +
 	"\n" +
 	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x1a\n" +
-	"\bquantity\x18\x02 \x01(\x03R\bquantity\"K\n" +
+	"\bquantity\x18\x02 \x01(\x03R\bquantity\"r\n" +
 	"\x15DecreaseStockResponse\x12\x18\n" +
 	"\asuccess\x18\x01 \x01(\bR\asuccess\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"&\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12%\n" +
+	"\x0estock_quantity\x18\x03 \x01(\x03R\rstockQuantity\"&\n" +
 	"\x14DeleteProductRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"1\n" +
 	"\x15DeleteProductResponse\x12\x18\n" +
-	"\asuccess\x18\x01 \x01(\bR\asuccess2\xc4\x02\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"'\n" +
+	"\x15RestoreProductRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"J\n" +
+	"\x1aListDeletedProductsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\"g\n" +
+	"\x18GetProductHistoryRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\"\xa7\x01\n" +
+	"\x0fProductRevision\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x02 \x01(\x03R\tproductId\x12\x16\n" +
+	"\x06action\x18\x03 \x01(\tR\x06action\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\x12\x18\n" +
+	"\achanges\x18\x05 \x01(\tR\achanges\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\"K\n" +
+	"\x19GetProductHistoryResponse\x12.\n" +
+	"\trevisions\x18\x01 \x03(\v2\x10.ProductRevisionR\trevisions\"\xd9\x01\n" +
+	"\tWarehouse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12!\n" +
+	"\fhas_location\x18\x04 \x01(\bR\vhasLocation\x12\x1a\n" +
+	"\blatitude\x18\x05 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x06 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\bpriority\x18\a \x01(\x05R\bpriority\x12\x1b\n" +
+	"\tis_active\x18\b \x01(\bR\bisActive\"\xb9\x01\n" +
+	"\x16CreateWarehouseRequest\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fhas_location\x18\x03 \x01(\bR\vhasLocation\x12\x1a\n" +
+	"\blatitude\x18\x04 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x05 \x01(\x01R\tlongitude\x12\x1a\n" +
+	"\bpriority\x18\x06 \x01(\x05R\bpriority\"\x17\n" +
+	"\x15ListWarehousesRequest\"D\n" +
+	"\x16ListWarehousesResponse\x12*\n" +
+	"\n" +
+	"warehouses\x18\x01 \x03(\v2\n" +
+	".WarehouseR\n" +
+	"warehouses\"7\n" +
+	"\x16GetProductStockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\"v\n" +
+	"\x0eWarehouseStock\x12!\n" +
+	"\fwarehouse_id\x18\x01 \x01(\x03R\vwarehouseId\x12%\n" +
+	"\x0ewarehouse_code\x18\x02 \x01(\tR\rwarehouseCode\x12\x1a\n" +
+	"\bquantity\x18\x03 \x01(\x03R\bquantity\"@\n" +
+	"\x17GetProductStockResponse\x12%\n" +
+	"\x05stock\x18\x01 \x03(\v2\x0f.WarehouseStockR\x05stock\"\xa5\x01\n" +
+	"\x14TransferStockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12*\n" +
+	"\x11from_warehouse_id\x18\x02 \x01(\x03R\x0ffromWarehouseId\x12&\n" +
+	"\x0fto_warehouse_id\x18\x03 \x01(\x03R\rtoWarehouseId\x12\x1a\n" +
+	"\bquantity\x18\x04 \x01(\x03R\bquantity\"1\n" +
+	"\x15TransferStockResponse\x12\x18\n" +
+	"\asuccess\x18\x01 \x01(\bR\asuccess\"\x98\x01\n" +
+	"\x12AdjustStockRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12!\n" +
+	"\fwarehouse_id\x18\x02 \x01(\x03R\vwarehouseId\x12\x14\n" +
+	"\x05delta\x18\x03 \x01(\x03R\x05delta\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x12\n" +
+	"\x04note\x18\x05 \x01(\tR\x04note\"<\n" +
+	"\x13AdjustStockResponse\x12%\n" +
+	"\x0estock_quantity\x18\x01 \x01(\x03R\rstockQuantity\"4\n" +
+	"\x11WatchStockRequest\x12\x1f\n" +
+	"\vproduct_ids\x18\x01 \x03(\x03R\n" +
+	"productIds\"\x84\x01\n" +
+	"\vStockUpdate\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12%\n" +
+	"\x0estock_quantity\x18\x02 \x01(\x03R\rstockQuantity\x12/\n" +
+	"\n" +
+	"warehouses\x18\x03 \x03(\v2\x0f.WarehouseStockR\n" +
+	"warehouses\"P\n" +
+	"\x19GetRelatedProductsRequest\x12\x1d\n" +
+	"\n" +
+	"product_id\x18\x01 \x01(\x03R\tproductId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\"B\n" +
+	"\x1aGetRelatedProductsResponse\x12$\n" +
+	"\bproducts\x18\x01 \x03(\v2\b.ProductR\bproducts\"O\n" +
+	"\x1eGetPersonalizedProductsRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\x03R\x06userId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\"G\n" +
+	"\x1fGetPersonalizedProductsResponse\x12$\n" +
+	"\bproducts\x18\x01 \x03(\v2\b.ProductR\bproducts\"\\\n" +
+	"\x15ExportProductsRequest\x12\x1a\n" +
+	"\bcategory\x18\x01 \x01(\tR\bcategory\x12'\n" +
+	"\x0finclude_deleted\x18\x02 \x01(\bR\x0eincludeDeleted\"\xb0\x04\n" +
+	"\x0fExportedProduct\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x10\n" +
+	"\x03sku\x18\x02 \x01(\tR\x03sku\x12\x10\n" +
+	"\x03ean\x18\x03 \x01(\tR\x03ean\x12\x12\n" +
+	"\x04name\x18\x04 \x01(\tR\x04name\x12\x1a\n" +
+	"\bcategory\x18\x05 \x01(\tR\bcategory\x12#\n" +
+	"\rcategory_path\x18\x06 \x03(\tR\fcategoryPath\x12\x14\n" +
+	"\x05price\x18\a \x01(\x03R\x05price\x12%\n" +
+	"\x0estock_quantity\x18\b \x01(\x03R\rstockQuantity\x12M\n" +
+	"\x0fwarehouse_stock\x18\t \x03(\v2$.ExportedProduct.WarehouseStockEntryR\x0ewarehouseStock\x12\x1b\n" +
+	"\tmin_price\x18\n" +
+	" \x01(\x03R\bminPrice\x12\x1b\n" +
+	"\tmax_price\x18\v \x01(\x03R\bmaxPrice\x12#\n" +
+	"\rprice_changes\x18\f \x01(\x03R\fpriceChanges\x12(\n" +
+	"\x10price_changed_at\x18\r \x01(\tR\x0epriceChangedAt\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\x0f \x01(\tR\tdeletedAt\x1aA\n" +
+	"\x13WarehouseStockEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"C\n" +
+	"\x13ExportProductsChunk\x12,\n" +
+	"\bproducts\x18\x01 \x03(\v2\x10.ExportedProductR\bproducts\"\xe7\x01\n" +
+	"\bCampaign\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12)\n" +
+	"\x10discount_percent\x18\x03 \x01(\x05R\x0fdiscountPercent\x12\x1b\n" +
+	"\tstarts_at\x18\x04 \x01(\tR\bstartsAt\x12\x17\n" +
+	"\aends_at\x18\x05 \x01(\tR\x06endsAt\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\x12\x1f\n" +
+	"\vproduct_ids\x18\a \x03(\x03R\n" +
+	"productIds\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\b \x01(\tR\tcreatedAt\"\xad\x01\n" +
+	"\x15CreateCampaignRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12)\n" +
+	"\x10discount_percent\x18\x02 \x01(\x05R\x0fdiscountPercent\x12\x1b\n" +
+	"\tstarts_at\x18\x03 \x01(\tR\bstartsAt\x12\x17\n" +
+	"\aends_at\x18\x04 \x01(\tR\x06endsAt\x12\x1f\n" +
+	"\vproduct_ids\x18\x05 \x03(\x03R\n" +
+	"productIds\"i\n" +
+	"\x14ListCampaignsRequest\x12#\n" +
+	"\rinclude_ended\x18\x01 \x01(\bR\fincludeEnded\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x03R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\"@\n" +
+	"\x15ListCampaignsResponse\x12'\n" +
+	"\tcampaigns\x18\x01 \x03(\v2\t.CampaignR\tcampaigns\"3\n" +
+	"\x1bListPromotedProductsRequest\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x03R\x05limit*\xb2\x01\n" +
+	"\vProductSort\x12\x1c\n" +
+	"\x18PRODUCT_SORT_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13PRODUCT_SORT_NEWEST\x10\x01\x12\x1a\n" +
+	"\x16PRODUCT_SORT_PRICE_ASC\x10\x02\x12\x1b\n" +
+	"\x17PRODUCT_SORT_PRICE_DESC\x10\x03\x12\x17\n" +
+	"\x13PRODUCT_SORT_RATING\x10\x04\x12\x1a\n" +
+	"\x16PRODUCT_SORT_STOCK_ASC\x10\x052\xf9\n" +
+	"\n" +
 	"\x0eProductService\x12>\n" +
 	"\rCreateProduct\x12\x15.CreateProductRequest\x1a\x16.CreateProductResponse\x125\n" +
 	"\n" +
-	"GetProduct\x12\x12.GetProductRequest\x1a\x13.GetProductResponse\x12;\n" +
-	"\fListProducts\x12\x14.ListProductsRequest\x1a\x15.ListProductsResponse\x12>\n" +
+	"GetProduct\x12\x12.GetProductRequest\x1a\x13.GetProductResponse\x12?\n" +
+	"\x0fGetProductBySKU\x12\x17.GetProductBySKURequest\x1a\x13.GetProductResponse\x12;\n" +
+	"\fListProducts\x12\x14.ListProductsRequest\x1a\x15.ListProductsResponse\x12M\n" +
+	"\x12GetRelatedProducts\x12\x1a.GetRelatedProductsRequest\x1a\x1b.GetRelatedProductsResponse\x12\\\n" +
+	"\x17GetPersonalizedProducts\x12\x1f.GetPersonalizedProductsRequest\x1a .GetPersonalizedProductsResponse\x12>\n" +
 	"\rDecreaseStock\x12\x15.DecreaseStockRequest\x1a\x16.DecreaseStockResponse\x12>\n" +
-	"\rDeleteProduct\x12\x15.DeleteProductRequest\x1a\x16.DeleteProductResponseB4Z2github.com/sakashimaa/go-pet-project/proto/productb\x06proto3"
+	"\rDeleteProduct\x12\x15.DeleteProductRequest\x1a\x16.DeleteProductResponse\x12=\n" +
+	"\x0eRestoreProduct\x12\x16.RestoreProductRequest\x1a\x13.GetProductResponse\x12I\n" +
+	"\x13ListDeletedProducts\x12\x1b.ListDeletedProductsRequest\x1a\x15.ListProductsResponse\x12J\n" +
+	"\x11GetProductHistory\x12\x19.GetProductHistoryRequest\x1a\x1a.GetProductHistoryResponse\x126\n" +
+	"\x0fCreateWarehouse\x12\x17.CreateWarehouseRequest\x1a\n" +
+	".Warehouse\x12A\n" +
+	"\x0eListWarehouses\x12\x16.ListWarehousesRequest\x1a\x17.ListWarehousesResponse\x12D\n" +
+	"\x0fGetProductStock\x12\x17.GetProductStockRequest\x1a\x18.GetProductStockResponse\x12>\n" +
+	"\rTransferStock\x12\x15.TransferStockRequest\x1a\x16.TransferStockResponse\x128\n" +
+	"\vAdjustStock\x12\x13.AdjustStockRequest\x1a\x14.AdjustStockResponse\x120\n" +
+	"\n" +
+	"WatchStock\x12\x12.WatchStockRequest\x1a\f.StockUpdate0\x01\x12@\n" +
+	"\x0eExportProducts\x12\x16.ExportProductsRequest\x1a\x14.ExportProductsChunk0\x01\x123\n" +
+	"\x0eCreateCampaign\x12\x16.CreateCampaignRequest\x1a\t.Campaign\x12>\n" +
+	"\rListCampaigns\x12\x15.ListCampaignsRequest\x1a\x16.ListCampaignsResponse\x12K\n" +
+	"\x14ListPromotedProducts\x12\x1c.ListPromotedProductsRequest\x1a\x15.ListProductsResponseB4Z2github.com/sakashimaa/go-pet-project/proto/productb\x06proto3"
 
 var (
 	file_proto_product_product_proto_rawDescOnce sync.Once
@@ -689,38 +3093,122 @@ func file_proto_product_product_proto_rawDescGZIP() []byte {
 	return file_proto_product_product_proto_rawDescData
 }
 
-var file_proto_product_product_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_proto_product_product_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_product_product_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_proto_product_product_proto_goTypes = []any{
-	(*Product)(nil),               // 0: Product
-	(*CreateProductRequest)(nil),  // 1: CreateProductRequest
-	(*CreateProductResponse)(nil), // 2: CreateProductResponse
-	(*GetProductRequest)(nil),     // 3: GetProductRequest
-	(*GetProductResponse)(nil),    // 4: GetProductResponse
-	(*ListProductsRequest)(nil),   // 5: ListProductsRequest
-	(*ListProductsResponse)(nil),  // 6: ListProductsResponse
-	(*DecreaseStockRequest)(nil),  // 7: DecreaseStockRequest
-	(*DecreaseStockResponse)(nil), // 8: DecreaseStockResponse
-	(*DeleteProductRequest)(nil),  // 9: DeleteProductRequest
-	(*DeleteProductResponse)(nil), // 10: DeleteProductResponse
+	(ProductSort)(0),                        // 0: ProductSort
+	(*Product)(nil),                         // 1: Product
+	(*ProductPromotion)(nil),                // 2: ProductPromotion
+	(*CreateProductRequest)(nil),            // 3: CreateProductRequest
+	(*CreateProductResponse)(nil),           // 4: CreateProductResponse
+	(*GetProductRequest)(nil),               // 5: GetProductRequest
+	(*GetProductBySKURequest)(nil),          // 6: GetProductBySKURequest
+	(*GetProductResponse)(nil),              // 7: GetProductResponse
+	(*AttributeFilter)(nil),                 // 8: AttributeFilter
+	(*ListProductsRequest)(nil),             // 9: ListProductsRequest
+	(*ListProductsResponse)(nil),            // 10: ListProductsResponse
+	(*DecreaseStockRequest)(nil),            // 11: DecreaseStockRequest
+	(*DecreaseStockResponse)(nil),           // 12: DecreaseStockResponse
+	(*DeleteProductRequest)(nil),            // 13: DeleteProductRequest
+	(*DeleteProductResponse)(nil),           // 14: DeleteProductResponse
+	(*RestoreProductRequest)(nil),           // 15: RestoreProductRequest
+	(*ListDeletedProductsRequest)(nil),      // 16: ListDeletedProductsRequest
+	(*GetProductHistoryRequest)(nil),        // 17: GetProductHistoryRequest
+	(*ProductRevision)(nil),                 // 18: ProductRevision
+	(*GetProductHistoryResponse)(nil),       // 19: GetProductHistoryResponse
+	(*Warehouse)(nil),                       // 20: Warehouse
+	(*CreateWarehouseRequest)(nil),          // 21: CreateWarehouseRequest
+	(*ListWarehousesRequest)(nil),           // 22: ListWarehousesRequest
+	(*ListWarehousesResponse)(nil),          // 23: ListWarehousesResponse
+	(*GetProductStockRequest)(nil),          // 24: GetProductStockRequest
+	(*WarehouseStock)(nil),                  // 25: WarehouseStock
+	(*GetProductStockResponse)(nil),         // 26: GetProductStockResponse
+	(*TransferStockRequest)(nil),            // 27: TransferStockRequest
+	(*TransferStockResponse)(nil),           // 28: TransferStockResponse
+	(*AdjustStockRequest)(nil),              // 29: AdjustStockRequest
+	(*AdjustStockResponse)(nil),             // 30: AdjustStockResponse
+	(*WatchStockRequest)(nil),               // 31: WatchStockRequest
+	(*StockUpdate)(nil),                     // 32: StockUpdate
+	(*GetRelatedProductsRequest)(nil),       // 33: GetRelatedProductsRequest
+	(*GetRelatedProductsResponse)(nil),      // 34: GetRelatedProductsResponse
+	(*GetPersonalizedProductsRequest)(nil),  // 35: GetPersonalizedProductsRequest
+	(*GetPersonalizedProductsResponse)(nil), // 36: GetPersonalizedProductsResponse
+	(*ExportProductsRequest)(nil),           // 37: ExportProductsRequest
+	(*ExportedProduct)(nil),                 // 38: ExportedProduct
+	(*ExportProductsChunk)(nil),             // 39: ExportProductsChunk
+	(*Campaign)(nil),                        // 40: Campaign
+	(*CreateCampaignRequest)(nil),           // 41: CreateCampaignRequest
+	(*ListCampaignsRequest)(nil),            // 42: ListCampaignsRequest
+	(*ListCampaignsResponse)(nil),           // 43: ListCampaignsResponse
+	(*ListPromotedProductsRequest)(nil),     // 44: ListPromotedProductsRequest
+	nil,                                     // 45: Product.AttributesEntry
+	nil,                                     // 46: CreateProductRequest.AttributesEntry
+	nil,                                     // 47: ExportedProduct.WarehouseStockEntry
 }
 var file_proto_product_product_proto_depIdxs = []int32{
-	0,  // 0: GetProductResponse.product:type_name -> Product
-	0,  // 1: ListProductsResponse.products:type_name -> Product
-	1,  // 2: ProductService.CreateProduct:input_type -> CreateProductRequest
-	3,  // 3: ProductService.GetProduct:input_type -> GetProductRequest
-	5,  // 4: ProductService.ListProducts:input_type -> ListProductsRequest
-	7,  // 5: ProductService.DecreaseStock:input_type -> DecreaseStockRequest
-	9,  // 6: ProductService.DeleteProduct:input_type -> DeleteProductRequest
-	2,  // 7: ProductService.CreateProduct:output_type -> CreateProductResponse
-	4,  // 8: ProductService.GetProduct:output_type -> GetProductResponse
-	6,  // 9: ProductService.ListProducts:output_type -> ListProductsResponse
-	8,  // 10: ProductService.DecreaseStock:output_type -> DecreaseStockResponse
-	10, // 11: ProductService.DeleteProduct:output_type -> DeleteProductResponse
-	7,  // [7:12] is the sub-list for method output_type
-	2,  // [2:7] is the sub-list for method input_type
-	2,  // [2:2] is the sub-list for extension type_name
-	2,  // [2:2] is the sub-list for extension extendee
-	0,  // [0:2] is the sub-list for field type_name
+	45, // 0: Product.attributes:type_name -> Product.AttributesEntry
+	2,  // 1: Product.promotion:type_name -> ProductPromotion
+	46, // 2: CreateProductRequest.attributes:type_name -> CreateProductRequest.AttributesEntry
+	1,  // 3: GetProductResponse.product:type_name -> Product
+	8,  // 4: ListProductsRequest.attributes:type_name -> AttributeFilter
+	0,  // 5: ListProductsRequest.sort:type_name -> ProductSort
+	1,  // 6: ListProductsResponse.products:type_name -> Product
+	18, // 7: GetProductHistoryResponse.revisions:type_name -> ProductRevision
+	20, // 8: ListWarehousesResponse.warehouses:type_name -> Warehouse
+	25, // 9: GetProductStockResponse.stock:type_name -> WarehouseStock
+	25, // 10: StockUpdate.warehouses:type_name -> WarehouseStock
+	1,  // 11: GetRelatedProductsResponse.products:type_name -> Product
+	1,  // 12: GetPersonalizedProductsResponse.products:type_name -> Product
+	47, // 13: ExportedProduct.warehouse_stock:type_name -> ExportedProduct.WarehouseStockEntry
+	38, // 14: ExportProductsChunk.products:type_name -> ExportedProduct
+	40, // 15: ListCampaignsResponse.campaigns:type_name -> Campaign
+	3,  // 16: ProductService.CreateProduct:input_type -> CreateProductRequest
+	5,  // 17: ProductService.GetProduct:input_type -> GetProductRequest
+	6,  // 18: ProductService.GetProductBySKU:input_type -> GetProductBySKURequest
+	9,  // 19: ProductService.ListProducts:input_type -> ListProductsRequest
+	33, // 20: ProductService.GetRelatedProducts:input_type -> GetRelatedProductsRequest
+	35, // 21: ProductService.GetPersonalizedProducts:input_type -> GetPersonalizedProductsRequest
+	11, // 22: ProductService.DecreaseStock:input_type -> DecreaseStockRequest
+	13, // 23: ProductService.DeleteProduct:input_type -> DeleteProductRequest
+	15, // 24: ProductService.RestoreProduct:input_type -> RestoreProductRequest
+	16, // 25: ProductService.ListDeletedProducts:input_type -> ListDeletedProductsRequest
+	17, // 26: ProductService.GetProductHistory:input_type -> GetProductHistoryRequest
+	21, // 27: ProductService.CreateWarehouse:input_type -> CreateWarehouseRequest
+	22, // 28: ProductService.ListWarehouses:input_type -> ListWarehousesRequest
+	24, // 29: ProductService.GetProductStock:input_type -> GetProductStockRequest
+	27, // 30: ProductService.TransferStock:input_type -> TransferStockRequest
+	29, // 31: ProductService.AdjustStock:input_type -> AdjustStockRequest
+	31, // 32: ProductService.WatchStock:input_type -> WatchStockRequest
+	37, // 33: ProductService.ExportProducts:input_type -> ExportProductsRequest
+	41, // 34: ProductService.CreateCampaign:input_type -> CreateCampaignRequest
+	42, // 35: ProductService.ListCampaigns:input_type -> ListCampaignsRequest
+	44, // 36: ProductService.ListPromotedProducts:input_type -> ListPromotedProductsRequest
+	4,  // 37: ProductService.CreateProduct:output_type -> CreateProductResponse
+	7,  // 38: ProductService.GetProduct:output_type -> GetProductResponse
+	7,  // 39: ProductService.GetProductBySKU:output_type -> GetProductResponse
+	10, // 40: ProductService.ListProducts:output_type -> ListProductsResponse
+	34, // 41: ProductService.GetRelatedProducts:output_type -> GetRelatedProductsResponse
+	36, // 42: ProductService.GetPersonalizedProducts:output_type -> GetPersonalizedProductsResponse
+	12, // 43: ProductService.DecreaseStock:output_type -> DecreaseStockResponse
+	14, // 44: ProductService.DeleteProduct:output_type -> DeleteProductResponse
+	7,  // 45: ProductService.RestoreProduct:output_type -> GetProductResponse
+	10, // 46: ProductService.ListDeletedProducts:output_type -> ListProductsResponse
+	19, // 47: ProductService.GetProductHistory:output_type -> GetProductHistoryResponse
+	20, // 48: ProductService.CreateWarehouse:output_type -> Warehouse
+	23, // 49: ProductService.ListWarehouses:output_type -> ListWarehousesResponse
+	26, // 50: ProductService.GetProductStock:output_type -> GetProductStockResponse
+	28, // 51: ProductService.TransferStock:output_type -> TransferStockResponse
+	30, // 52: ProductService.AdjustStock:output_type -> AdjustStockResponse
+	32, // 53: ProductService.WatchStock:output_type -> StockUpdate
+	39, // 54: ProductService.ExportProducts:output_type -> ExportProductsChunk
+	40, // 55: ProductService.CreateCampaign:output_type -> Campaign
+	43, // 56: ProductService.ListCampaigns:output_type -> ListCampaignsResponse
+	10, // 57: ProductService.ListPromotedProducts:output_type -> ListProductsResponse
+	37, // [37:58] is the sub-list for method output_type
+	16, // [16:37] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_proto_product_product_proto_init() }
@@ -733,13 +3221,14 @@ func file_proto_product_product_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_product_product_proto_rawDesc), len(file_proto_product_product_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   11,
+			NumEnums:      1,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_product_product_proto_goTypes,
 		DependencyIndexes: file_proto_product_product_proto_depIdxs,
+		EnumInfos:         file_proto_product_product_proto_enumTypes,
 		MessageInfos:      file_proto_product_product_proto_msgTypes,
 	}.Build()
 	File_proto_product_product_proto = out.File
@@ -0,0 +1,954 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v6.33.2
+// source: proto/status/status.proto
+
+// Operational status every service reports to the gateway's admin endpoints,
+// so a dashboard can show backlogs and lag without scraping Prometheus.
+
+package status
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type GetStatusRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// failed_limit caps recent_failures; 0 means the default of 20.
+	FailedLimit   int32 `protobuf:"varint,1,opt,name=failed_limit,json=failedLimit,proto3" json:"failed_limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	mi := &file_proto_status_status_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *GetStatusRequest) GetFailedLimit() int32 {
+	if x != nil {
+		return x.FailedLimit
+	}
+	return 0
+}
+
+type GetStatusResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Service string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// outbox is unset for services without one.
+	Outbox         *OutboxStatus          `protobuf:"bytes,2,opt,name=outbox,proto3" json:"outbox,omitempty"`
+	ConsumerGroups []*ConsumerGroupStatus `protobuf:"bytes,3,rep,name=consumer_groups,json=consumerGroups,proto3" json:"consumer_groups,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetStatusResponse) Reset() {
+	*x = GetStatusResponse{}
+	mi := &file_proto_status_status_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatusResponse) ProtoMessage() {}
+
+func (x *GetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GetStatusResponse) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *GetStatusResponse) GetOutbox() *OutboxStatus {
+	if x != nil {
+		return x.Outbox
+	}
+	return nil
+}
+
+func (x *GetStatusResponse) GetConsumerGroups() []*ConsumerGroupStatus {
+	if x != nil {
+		return x.ConsumerGroups
+	}
+	return nil
+}
+
+type OutboxStatus struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Topics []*OutboxTopic         `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`
+	// recent_failures are the newest events that ran out of attempts.
+	RecentFailures []*FailedEvent `protobuf:"bytes,2,rep,name=recent_failures,json=recentFailures,proto3" json:"recent_failures,omitempty"`
+	// error is set when the outbox could not be read.
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OutboxStatus) Reset() {
+	*x = OutboxStatus{}
+	mi := &file_proto_status_status_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboxStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboxStatus) ProtoMessage() {}
+
+func (x *OutboxStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboxStatus.ProtoReflect.Descriptor instead.
+func (*OutboxStatus) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *OutboxStatus) GetTopics() []*OutboxTopic {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *OutboxStatus) GetRecentFailures() []*FailedEvent {
+	if x != nil {
+		return x.RecentFailures
+	}
+	return nil
+}
+
+func (x *OutboxStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type OutboxTopic struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Topic                   string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Pending                 int64                  `protobuf:"varint,2,opt,name=pending,proto3" json:"pending,omitempty"`
+	Failed                  int64                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	OldestPendingAgeSeconds float64                `protobuf:"fixed64,4,opt,name=oldest_pending_age_seconds,json=oldestPendingAgeSeconds,proto3" json:"oldest_pending_age_seconds,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *OutboxTopic) Reset() {
+	*x = OutboxTopic{}
+	mi := &file_proto_status_status_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OutboxTopic) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutboxTopic) ProtoMessage() {}
+
+func (x *OutboxTopic) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutboxTopic.ProtoReflect.Descriptor instead.
+func (*OutboxTopic) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OutboxTopic) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *OutboxTopic) GetPending() int64 {
+	if x != nil {
+		return x.Pending
+	}
+	return 0
+}
+
+func (x *OutboxTopic) GetFailed() int64 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *OutboxTopic) GetOldestPendingAgeSeconds() float64 {
+	if x != nil {
+		return x.OldestPendingAgeSeconds
+	}
+	return 0
+}
+
+type FailedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Topic         string                 `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	EventType     string                 `protobuf:"bytes,3,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	AggregateId   string                 `protobuf:"bytes,4,opt,name=aggregate_id,json=aggregateId,proto3" json:"aggregate_id,omitempty"`
+	Attempts      int32                  `protobuf:"varint,5,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LastError     string                 `protobuf:"bytes,6,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FailedEvent) Reset() {
+	*x = FailedEvent{}
+	mi := &file_proto_status_status_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FailedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FailedEvent) ProtoMessage() {}
+
+func (x *FailedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FailedEvent.ProtoReflect.Descriptor instead.
+func (*FailedEvent) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FailedEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *FailedEvent) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *FailedEvent) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *FailedEvent) GetAggregateId() string {
+	if x != nil {
+		return x.AggregateId
+	}
+	return ""
+}
+
+func (x *FailedEvent) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *FailedEvent) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *FailedEvent) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+type ConsumerGroupStatus struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	GroupId    string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	TotalLag   int64                  `protobuf:"varint,2,opt,name=total_lag,json=totalLag,proto3" json:"total_lag,omitempty"`
+	Partitions []*PartitionLag        `protobuf:"bytes,3,rep,name=partitions,proto3" json:"partitions,omitempty"`
+	// error is set when the offsets could not be read.
+	Error         string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConsumerGroupStatus) Reset() {
+	*x = ConsumerGroupStatus{}
+	mi := &file_proto_status_status_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConsumerGroupStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConsumerGroupStatus) ProtoMessage() {}
+
+func (x *ConsumerGroupStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConsumerGroupStatus.ProtoReflect.Descriptor instead.
+func (*ConsumerGroupStatus) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ConsumerGroupStatus) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *ConsumerGroupStatus) GetTotalLag() int64 {
+	if x != nil {
+		return x.TotalLag
+	}
+	return 0
+}
+
+func (x *ConsumerGroupStatus) GetPartitions() []*PartitionLag {
+	if x != nil {
+		return x.Partitions
+	}
+	return nil
+}
+
+func (x *ConsumerGroupStatus) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PartitionLag struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Topic     string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	Partition int32                  `protobuf:"varint,2,opt,name=partition,proto3" json:"partition,omitempty"`
+	// committed is -1 when the group has not committed on the partition yet.
+	Committed     int64 `protobuf:"varint,3,opt,name=committed,proto3" json:"committed,omitempty"`
+	Newest        int64 `protobuf:"varint,4,opt,name=newest,proto3" json:"newest,omitempty"`
+	Lag           int64 `protobuf:"varint,5,opt,name=lag,proto3" json:"lag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PartitionLag) Reset() {
+	*x = PartitionLag{}
+	mi := &file_proto_status_status_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PartitionLag) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartitionLag) ProtoMessage() {}
+
+func (x *PartitionLag) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartitionLag.ProtoReflect.Descriptor instead.
+func (*PartitionLag) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *PartitionLag) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *PartitionLag) GetPartition() int32 {
+	if x != nil {
+		return x.Partition
+	}
+	return 0
+}
+
+func (x *PartitionLag) GetCommitted() int64 {
+	if x != nil {
+		return x.Committed
+	}
+	return 0
+}
+
+func (x *PartitionLag) GetNewest() int64 {
+	if x != nil {
+		return x.Newest
+	}
+	return 0
+}
+
+func (x *PartitionLag) GetLag() int64 {
+	if x != nil {
+		return x.Lag
+	}
+	return 0
+}
+
+type TraceEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// aggregate_type and aggregate_id select the events saved for one
+	// aggregate, e.g. "Order" and "1234".
+	AggregateType string `protobuf:"bytes,1,opt,name=aggregate_type,json=aggregateType,proto3" json:"aggregate_type,omitempty"`
+	AggregateId   string `protobuf:"bytes,2,opt,name=aggregate_id,json=aggregateId,proto3" json:"aggregate_id,omitempty"`
+	// correlation_ids also select every event of those flows.
+	CorrelationIds []string `protobuf:"bytes,3,rep,name=correlation_ids,json=correlationIds,proto3" json:"correlation_ids,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TraceEventsRequest) Reset() {
+	*x = TraceEventsRequest{}
+	mi := &file_proto_status_status_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraceEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceEventsRequest) ProtoMessage() {}
+
+func (x *TraceEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceEventsRequest.ProtoReflect.Descriptor instead.
+func (*TraceEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TraceEventsRequest) GetAggregateType() string {
+	if x != nil {
+		return x.AggregateType
+	}
+	return ""
+}
+
+func (x *TraceEventsRequest) GetAggregateId() string {
+	if x != nil {
+		return x.AggregateId
+	}
+	return ""
+}
+
+func (x *TraceEventsRequest) GetCorrelationIds() []string {
+	if x != nil {
+		return x.CorrelationIds
+	}
+	return nil
+}
+
+type TraceEventsResponse struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Service string                 `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	Events  []*TracedEvent         `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	// truncated is set when the flow has more events than were returned.
+	Truncated     bool `protobuf:"varint,3,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TraceEventsResponse) Reset() {
+	*x = TraceEventsResponse{}
+	mi := &file_proto_status_status_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TraceEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TraceEventsResponse) ProtoMessage() {}
+
+func (x *TraceEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TraceEventsResponse.ProtoReflect.Descriptor instead.
+func (*TraceEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *TraceEventsResponse) GetService() string {
+	if x != nil {
+		return x.Service
+	}
+	return ""
+}
+
+func (x *TraceEventsResponse) GetEvents() []*TracedEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *TraceEventsResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+type TracedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	EventId       string                 `protobuf:"bytes,2,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	EventType     string                 `protobuf:"bytes,3,opt,name=event_type,json=eventType,proto3" json:"event_type,omitempty"`
+	Topic         string                 `protobuf:"bytes,4,opt,name=topic,proto3" json:"topic,omitempty"`
+	AggregateType string                 `protobuf:"bytes,5,opt,name=aggregate_type,json=aggregateType,proto3" json:"aggregate_type,omitempty"`
+	AggregateId   string                 `protobuf:"bytes,6,opt,name=aggregate_id,json=aggregateId,proto3" json:"aggregate_id,omitempty"`
+	// status is "pending", "published", "failed" or "superseded".
+	Status    string `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	Attempts  int32  `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LastError string `protobuf:"bytes,9,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	CreatedAt string `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// published_at is empty until the event is published.
+	PublishedAt   string `protobuf:"bytes,11,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	Producer      string `protobuf:"bytes,12,opt,name=producer,proto3" json:"producer,omitempty"`
+	CorrelationId string `protobuf:"bytes,13,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	CausationId   string `protobuf:"bytes,14,opt,name=causation_id,json=causationId,proto3" json:"causation_id,omitempty"`
+	TenantId      string `protobuf:"bytes,15,opt,name=tenant_id,json=tenantId,proto3" json:"tenant_id,omitempty"`
+	// trace_id is the trace the event was saved in, empty outside one.
+	TraceId string `protobuf:"bytes,16,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"`
+	// partition and offset are where the event was published; offset is -1
+	// when that is unknown.
+	Partition     int32 `protobuf:"varint,17,opt,name=partition,proto3" json:"partition,omitempty"`
+	Offset        int64 `protobuf:"varint,18,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TracedEvent) Reset() {
+	*x = TracedEvent{}
+	mi := &file_proto_status_status_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TracedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TracedEvent) ProtoMessage() {}
+
+func (x *TracedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_status_status_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TracedEvent.ProtoReflect.Descriptor instead.
+func (*TracedEvent) Descriptor() ([]byte, []int) {
+	return file_proto_status_status_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *TracedEvent) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *TracedEvent) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetEventType() string {
+	if x != nil {
+		return x.EventType
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetAggregateType() string {
+	if x != nil {
+		return x.AggregateType
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetAggregateId() string {
+	if x != nil {
+		return x.AggregateId
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetAttempts() int32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *TracedEvent) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetPublishedAt() string {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetProducer() string {
+	if x != nil {
+		return x.Producer
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetCausationId() string {
+	if x != nil {
+		return x.CausationId
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetTenantId() string {
+	if x != nil {
+		return x.TenantId
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *TracedEvent) GetPartition() int32 {
+	if x != nil {
+		return x.Partition
+	}
+	return 0
+}
+
+func (x *TracedEvent) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+var File_proto_status_status_proto protoreflect.FileDescriptor
+
+const file_proto_status_status_proto_rawDesc = "" +
+	"\n" +
+	"\x19proto/status/status.proto\x12\x06status\"5\n" +
+	"\x10GetStatusRequest\x12!\n" +
+	"\ffailed_limit\x18\x01 \x01(\x05R\vfailedLimit\"\xa1\x01\n" +
+	"\x11GetStatusResponse\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12,\n" +
+	"\x06outbox\x18\x02 \x01(\v2\x14.status.OutboxStatusR\x06outbox\x12D\n" +
+	"\x0fconsumer_groups\x18\x03 \x03(\v2\x1b.status.ConsumerGroupStatusR\x0econsumerGroups\"\x8f\x01\n" +
+	"\fOutboxStatus\x12+\n" +
+	"\x06topics\x18\x01 \x03(\v2\x13.status.OutboxTopicR\x06topics\x12<\n" +
+	"\x0frecent_failures\x18\x02 \x03(\v2\x13.status.FailedEventR\x0erecentFailures\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x92\x01\n" +
+	"\vOutboxTopic\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x18\n" +
+	"\apending\x18\x02 \x01(\x03R\apending\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x03R\x06failed\x12;\n" +
+	"\x1aoldest_pending_age_seconds\x18\x04 \x01(\x01R\x17oldestPendingAgeSeconds\"\xcf\x01\n" +
+	"\vFailedEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x14\n" +
+	"\x05topic\x18\x02 \x01(\tR\x05topic\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x03 \x01(\tR\teventType\x12!\n" +
+	"\faggregate_id\x18\x04 \x01(\tR\vaggregateId\x12\x1a\n" +
+	"\battempts\x18\x05 \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\x06 \x01(\tR\tlastError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\tR\tcreatedAt\"\x99\x01\n" +
+	"\x13ConsumerGroupStatus\x12\x19\n" +
+	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x1b\n" +
+	"\ttotal_lag\x18\x02 \x01(\x03R\btotalLag\x124\n" +
+	"\n" +
+	"partitions\x18\x03 \x03(\v2\x14.status.PartitionLagR\n" +
+	"partitions\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x8a\x01\n" +
+	"\fPartitionLag\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12\x1c\n" +
+	"\tpartition\x18\x02 \x01(\x05R\tpartition\x12\x1c\n" +
+	"\tcommitted\x18\x03 \x01(\x03R\tcommitted\x12\x16\n" +
+	"\x06newest\x18\x04 \x01(\x03R\x06newest\x12\x10\n" +
+	"\x03lag\x18\x05 \x01(\x03R\x03lag\"\x87\x01\n" +
+	"\x12TraceEventsRequest\x12%\n" +
+	"\x0eaggregate_type\x18\x01 \x01(\tR\raggregateType\x12!\n" +
+	"\faggregate_id\x18\x02 \x01(\tR\vaggregateId\x12'\n" +
+	"\x0fcorrelation_ids\x18\x03 \x03(\tR\x0ecorrelationIds\"z\n" +
+	"\x13TraceEventsResponse\x12\x18\n" +
+	"\aservice\x18\x01 \x01(\tR\aservice\x12+\n" +
+	"\x06events\x18\x02 \x03(\v2\x13.status.TracedEventR\x06events\x12\x1c\n" +
+	"\ttruncated\x18\x03 \x01(\bR\ttruncated\"\xa0\x04\n" +
+	"\vTracedEvent\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x19\n" +
+	"\bevent_id\x18\x02 \x01(\tR\aeventId\x12\x1d\n" +
+	"\n" +
+	"event_type\x18\x03 \x01(\tR\teventType\x12\x14\n" +
+	"\x05topic\x18\x04 \x01(\tR\x05topic\x12%\n" +
+	"\x0eaggregate_type\x18\x05 \x01(\tR\raggregateType\x12!\n" +
+	"\faggregate_id\x18\x06 \x01(\tR\vaggregateId\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\x1a\n" +
+	"\battempts\x18\b \x01(\x05R\battempts\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\t \x01(\tR\tlastError\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\tR\tcreatedAt\x12!\n" +
+	"\fpublished_at\x18\v \x01(\tR\vpublishedAt\x12\x1a\n" +
+	"\bproducer\x18\f \x01(\tR\bproducer\x12%\n" +
+	"\x0ecorrelation_id\x18\r \x01(\tR\rcorrelationId\x12!\n" +
+	"\fcausation_id\x18\x0e \x01(\tR\vcausationId\x12\x1b\n" +
+	"\ttenant_id\x18\x0f \x01(\tR\btenantId\x12\x19\n" +
+	"\btrace_id\x18\x10 \x01(\tR\atraceId\x12\x1c\n" +
+	"\tpartition\x18\x11 \x01(\x05R\tpartition\x12\x16\n" +
+	"\x06offset\x18\x12 \x01(\x03R\x06offset2\x99\x01\n" +
+	"\rStatusService\x12@\n" +
+	"\tGetStatus\x12\x18.status.GetStatusRequest\x1a\x19.status.GetStatusResponse\x12F\n" +
+	"\vTraceEvents\x12\x1a.status.TraceEventsRequest\x1a\x1b.status.TraceEventsResponseB3Z1github.com/sakashimaa/go-pet-project/proto/statusb\x06proto3"
+
+var (
+	file_proto_status_status_proto_rawDescOnce sync.Once
+	file_proto_status_status_proto_rawDescData []byte
+)
+
+func file_proto_status_status_proto_rawDescGZIP() []byte {
+	file_proto_status_status_proto_rawDescOnce.Do(func() {
+		file_proto_status_status_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_status_status_proto_rawDesc), len(file_proto_status_status_proto_rawDesc)))
+	})
+	return file_proto_status_status_proto_rawDescData
+}
+
+var file_proto_status_status_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_status_status_proto_goTypes = []any{
+	(*GetStatusRequest)(nil),    // 0: status.GetStatusRequest
+	(*GetStatusResponse)(nil),   // 1: status.GetStatusResponse
+	(*OutboxStatus)(nil),        // 2: status.OutboxStatus
+	(*OutboxTopic)(nil),         // 3: status.OutboxTopic
+	(*FailedEvent)(nil),         // 4: status.FailedEvent
+	(*ConsumerGroupStatus)(nil), // 5: status.ConsumerGroupStatus
+	(*PartitionLag)(nil),        // 6: status.PartitionLag
+	(*TraceEventsRequest)(nil),  // 7: status.TraceEventsRequest
+	(*TraceEventsResponse)(nil), // 8: status.TraceEventsResponse
+	(*TracedEvent)(nil),         // 9: status.TracedEvent
+}
+var file_proto_status_status_proto_depIdxs = []int32{
+	2, // 0: status.GetStatusResponse.outbox:type_name -> status.OutboxStatus
+	5, // 1: status.GetStatusResponse.consumer_groups:type_name -> status.ConsumerGroupStatus
+	3, // 2: status.OutboxStatus.topics:type_name -> status.OutboxTopic
+	4, // 3: status.OutboxStatus.recent_failures:type_name -> status.FailedEvent
+	6, // 4: status.ConsumerGroupStatus.partitions:type_name -> status.PartitionLag
+	9, // 5: status.TraceEventsResponse.events:type_name -> status.TracedEvent
+	0, // 6: status.StatusService.GetStatus:input_type -> status.GetStatusRequest
+	7, // 7: status.StatusService.TraceEvents:input_type -> status.TraceEventsRequest
+	1, // 8: status.StatusService.GetStatus:output_type -> status.GetStatusResponse
+	8, // 9: status.StatusService.TraceEvents:output_type -> status.TraceEventsResponse
+	8, // [8:10] is the sub-list for method output_type
+	6, // [6:8] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_proto_status_status_proto_init() }
+func file_proto_status_status_proto_init() {
+	if File_proto_status_status_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_status_status_proto_rawDesc), len(file_proto_status_status_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_status_status_proto_goTypes,
+		DependencyIndexes: file_proto_status_status_proto_depIdxs,
+		MessageInfos:      file_proto_status_status_proto_msgTypes,
+	}.Build()
+	File_proto_status_status_proto = out.File
+	file_proto_status_status_proto_goTypes = nil
+	file_proto_status_status_proto_depIdxs = nil
+}
@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/sakashimaa/go-pet-project/analytics/internal/transport/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/cli"
+)
+
+func main() {
+	cli.Execute(cli.Service{
+		Name:  "analytics-service",
+		Serve: serve,
+		ConsumerGroups: []cli.ConsumerGroup{
+			{ID: kafka.GroupID, Topics: kafka.Topics},
+		},
+	})
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/analytics/internal/repository"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/service"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/transport/grpc"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/transport/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/app"
+	"github.com/sakashimaa/go-pet-project/pkg/config"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/debug"
+	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/servicestatus"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/analytics"
+	statusPb "github.com/sakashimaa/go-pet-project/proto/status"
+	googleGrpc "google.golang.org/grpc"
+)
+
+func serve(ctx context.Context) error {
+	tp, err := utils.InitTracer(ctx, "analytics-service")
+	if err != nil {
+		log.Fatalf("failed to init tracer: %v", err)
+	}
+
+	pool, err := db.NewPostgresDB(utils.ParseWithFallback("DB_URL", ""))
+	if err != nil {
+		log.Fatalf("failed to create pool: %v", err)
+	}
+
+	loggerCfg, err := config.LoadLoggerConfig()
+	if err != nil {
+		log.Fatalf("failed to load logger config: %v", err)
+	}
+	logger, err := config.NewLogger(loggerCfg)
+	if err != nil {
+		log.Fatalf("failed to create logger: %v", err)
+	}
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Fatalf("failed to sync logger: %v", err)
+		}
+	}()
+
+	runner := app.NewRunner(logger)
+	runner.Add(app.Component{Name: "telemetry", Stop: tp.Shutdown})
+	runner.Add(app.Component{Name: "postgres", Stop: app.Func(pool.Close)})
+
+	analyticsRepo := repository.NewAnalyticsRepository(pool, logger)
+	analyticsService := service.NewAnalyticsService(pool, logger, analyticsRepo)
+	analyticsHandler := grpc.NewAnalyticsHandler(analyticsService, logger)
+
+	kafkaConfig, err := kafka2.LoadConfig("analytics-service")
+	if err != nil {
+		log.Fatalf("error loading kafka config: %v", err)
+	}
+
+	encryptionConfig, err := kafka2.LoadEncryptionConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption config: %v", err)
+	}
+
+	keyring, err := encryptionConfig.Keyring()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption keys: %v", err)
+	}
+
+	consumer := kafka.NewConsumer(analyticsService, logger)
+
+	lis, err := net.Listen("tcp", ":50055")
+	if err != nil {
+		log.Fatalf("Error listening on :50055 %v", err)
+	}
+
+	s := googleGrpc.NewServer()
+	pb.RegisterAnalyticsServiceServer(s, analyticsHandler)
+	statusPb.RegisterStatusServiceServer(s, servicestatus.New(
+		"analytics-service",
+		logger,
+		servicestatus.WithConsumerGroups(kafkaConfig, servicestatus.ConsumerGroup{ID: kafka.GroupID, Topics: kafka.Topics}),
+	))
+
+	reflectionCfg, err := config.LoadReflectionConfig()
+	if err != nil {
+		log.Fatalf("Error loading reflection config: %v", err)
+	}
+	if config.RegisterReflection(s, reflectionCfg) {
+		logger.Info("gRPC reflection is enabled, do not expose this port publicly")
+	}
+
+	consumerConfig, err := kafka2.LoadConsumerConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka consumer config: %v", err)
+	}
+
+	runner.Add(app.Component{
+		Name: "kafka consumer",
+		Start: app.Loop(func(ctx context.Context) {
+			consumer.Start(ctx, kafkaConfig, kafka2.WithDecryption(keyring), kafka2.WithConcurrency(consumerConfig.Concurrency))
+		}),
+	})
+	runner.Add(app.Component{
+		Name: "grpc server",
+		Start: func(context.Context) error {
+			log.Println("gRPC server listening on 50055 🔥")
+			return s.Serve(lis)
+		},
+		Stop: app.Func(s.GracefulStop),
+	})
+
+	debugConfig, err := debug.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading debug config: %v", err)
+	}
+	if debugConfig.Enabled {
+		adminMux := http.NewServeMux()
+		if err := debug.Mount(adminMux, debugConfig); err != nil {
+			log.Fatalf("Error mounting debug endpoints: %v", err)
+		}
+
+		runner.Add(app.HTTPServer("admin server", &http.Server{
+			Addr:              utils.ParseWithFallback("ADMIN_ADDR", ":9095"),
+			Handler:           adminMux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}))
+	}
+
+	return runner.Run(ctx)
+}
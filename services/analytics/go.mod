@@ -0,0 +1,3 @@
+module github.com/sakashimaa/go-pet-project/analytics
+
+go 1.25.4
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// DateRange is half-open: From is inclusive, To is exclusive.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+type DailyVolume struct {
+	Day             time.Time
+	Orders          int64
+	PaidOrders      int64
+	CancelledOrders int64
+	FailedPayments  int64
+	Revenue         int64
+}
+
+type Funnel struct {
+	RegisteredUsers int64
+	OrderingUsers   int64
+	OrdersCreated   int64
+	OrdersReserved  int64
+	OrdersPaid      int64
+}
+
+func (f Funnel) ConversionRate() float64 {
+	if f.OrdersCreated == 0 {
+		return 0
+	}
+
+	return float64(f.OrdersPaid) / float64(f.OrdersCreated)
+}
+
+type TopProduct struct {
+	ProductID int64
+	Units     int64
+	Orders    int64
+}
@@ -0,0 +1,33 @@
+package domain
+
+type UserRegisteredEvent struct {
+	UserID int64 `json:"user_id"`
+}
+
+type OrderItemEvent struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+type OrderCreatedEvent struct {
+	OrderID int64            `json:"order_id"`
+	UserID  int64            `json:"user_id"`
+	Items   []OrderItemEvent `json:"items"`
+}
+
+// InventoryReservedEvent covers InventoryReserved, InventoryPartiallyReserved
+// and OrderConfirmed; analytics only needs the shared fields.
+type InventoryReservedEvent struct {
+	OrderID int64 `json:"order_id"`
+	UserID  int64 `json:"user_id"`
+	Amount  int64 `json:"amount"`
+}
+
+type PaymentEvent struct {
+	OrderID int64 `json:"order_id"`
+	Amount  int64 `json:"amount"`
+}
+
+type OrderCancelledEvent struct {
+	OrderID int64 `json:"order_id"`
+}
@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// OrderStage names a saga milestone column in analytics_orders.
+type OrderStage string
+
+const (
+	StageReserved  OrderStage = "reserved_at"
+	StagePaid      OrderStage = "paid_at"
+	StageFailed    OrderStage = "failed_at"
+	StageCancelled OrderStage = "cancelled_at"
+)
+
+type AnalyticsRepository interface {
+	RecordUserRegistered(ctx context.Context, userID int64, at time.Time) error
+	RecordOrderCreated(ctx context.Context, tx pgx.Tx, event *domain.OrderCreatedEvent, at time.Time) error
+	RecordOrderStage(ctx context.Context, orderID int64, stage OrderStage, amount int64, at time.Time) error
+	GetDailyVolume(ctx context.Context, period domain.DateRange) ([]domain.DailyVolume, error)
+	GetFunnel(ctx context.Context, period domain.DateRange) (*domain.Funnel, error)
+	GetTopProducts(ctx context.Context, period domain.DateRange, limit int64) ([]domain.TopProduct, error)
+}
+
+type analyticsRepo struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+func NewAnalyticsRepository(pool *pgxpool.Pool, logger *zap.Logger) AnalyticsRepository {
+	return &analyticsRepo{
+		pool:   pool,
+		logger: logger,
+		tracer: otel.Tracer("analytics_repository"),
+	}
+}
+
+func (r *analyticsRepo) RecordUserRegistered(ctx context.Context, userID int64, at time.Time) error {
+	ctx, span := r.tracer.Start(ctx, "AnalyticsRepository.RecordUserRegistered")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		INSERT INTO analytics_users (user_id, registered_at)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO NOTHING;
+	`
+
+	if _, err := r.pool.Exec(ctx, query, userID, at); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to record user registration",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to record user registration: %w", err)
+	}
+
+	return nil
+}
+
+// RecordOrderCreated upserts the order row. A later stage may have created a
+// stub first, so created_at keeps the earliest timestamp seen.
+func (r *analyticsRepo) RecordOrderCreated(ctx context.Context, tx pgx.Tx, event *domain.OrderCreatedEvent, at time.Time) error {
+	ctx, span := r.tracer.Start(ctx, "AnalyticsRepository.RecordOrderCreated")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", event.OrderID),
+		attribute.Int64("user_id", event.UserID),
+	)
+
+	query := `
+		INSERT INTO analytics_orders (order_id, user_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (order_id) DO UPDATE
+		SET user_id = EXCLUDED.user_id,
+			created_at = LEAST(analytics_orders.created_at, EXCLUDED.created_at);
+	`
+
+	if _, err := tx.Exec(ctx, query, event.OrderID, event.UserID, at); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to record order",
+			zap.Int64("order_id", event.OrderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to record order: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO analytics_order_items (order_id, product_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (order_id, product_id) DO NOTHING;
+	`
+
+	batch := &pgx.Batch{}
+	for _, item := range event.Items {
+		batch.Queue(itemQuery, event.OrderID, item.ProductID, item.Quantity)
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to record order items",
+			zap.Int64("order_id", event.OrderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to record order items: %w", err)
+	}
+
+	return nil
+}
+
+// RecordOrderStage stamps a saga milestone once. If the stage arrives before
+// OrderCreated, a stub row is created and completed later.
+func (r *analyticsRepo) RecordOrderStage(ctx context.Context, orderID int64, stage OrderStage, amount int64, at time.Time) error {
+	switch stage {
+	case StageReserved, StagePaid, StageFailed, StageCancelled:
+	default:
+		return ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "AnalyticsRepository.RecordOrderStage")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.String("stage", string(stage)),
+	)
+
+	// stage is one of the constants above, never user input.
+	query := fmt.Sprintf(`
+		INSERT INTO analytics_orders (order_id, user_id, created_at, amount, %[1]s)
+		VALUES ($1, 0, $3, $2, $3)
+		ON CONFLICT (order_id) DO UPDATE
+		SET %[1]s = COALESCE(analytics_orders.%[1]s, EXCLUDED.%[1]s),
+			amount = CASE WHEN EXCLUDED.amount > 0 THEN EXCLUDED.amount ELSE analytics_orders.amount END;
+	`, stage)
+
+	if _, err := r.pool.Exec(ctx, query, orderID, amount, at); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to record order stage",
+			zap.Int64("order_id", orderID),
+			zap.String("stage", string(stage)),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to record order stage: %w", err)
+	}
+
+	return nil
+}
+
+func (r *analyticsRepo) GetDailyVolume(ctx context.Context, period domain.DateRange) ([]domain.DailyVolume, error) {
+	ctx, span := r.tracer.Start(ctx, "AnalyticsRepository.GetDailyVolume")
+	defer span.End()
+
+	query := `
+		WITH days AS (
+			SELECT generate_series($1::date, ($2::date - INTERVAL '1 day'), INTERVAL '1 day')::date AS day
+		),
+		created AS (
+			SELECT created_at::date AS day,
+				COUNT(*) AS orders,
+				COUNT(*) FILTER (WHERE cancelled_at IS NOT NULL) AS cancelled
+			FROM analytics_orders
+			WHERE created_at >= $1 AND created_at < $2
+			GROUP BY 1
+		),
+		paid AS (
+			SELECT paid_at::date AS day, COUNT(*) AS orders, SUM(amount) AS revenue
+			FROM analytics_orders
+			WHERE paid_at >= $1 AND paid_at < $2
+			GROUP BY 1
+		),
+		failed AS (
+			SELECT failed_at::date AS day, COUNT(*) AS payments
+			FROM analytics_orders
+			WHERE failed_at >= $1 AND failed_at < $2
+			GROUP BY 1
+		)
+		SELECT d.day,
+			COALESCE(c.orders, 0),
+			COALESCE(p.orders, 0),
+			COALESCE(c.cancelled, 0),
+			COALESCE(f.payments, 0),
+			COALESCE(p.revenue, 0)
+		FROM days d
+		LEFT JOIN created c ON c.day = d.day
+		LEFT JOIN paid p ON p.day = d.day
+		LEFT JOIN failed f ON f.day = d.day
+		ORDER BY d.day;
+	`
+
+	rows, err := r.pool.Query(ctx, query, period.From, period.To)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to query daily volume", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to query daily volume: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]domain.DailyVolume, 0)
+	for rows.Next() {
+		var v domain.DailyVolume
+		if err := rows.Scan(&v.Day, &v.Orders, &v.PaidOrders, &v.CancelledOrders, &v.FailedPayments, &v.Revenue); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan daily volume: %w", err)
+		}
+
+		result = append(result, v)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *analyticsRepo) GetFunnel(ctx context.Context, period domain.DateRange) (*domain.Funnel, error) {
+	ctx, span := r.tracer.Start(ctx, "AnalyticsRepository.GetFunnel")
+	defer span.End()
+
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM analytics_users WHERE registered_at >= $1 AND registered_at < $2),
+			COUNT(DISTINCT user_id) FILTER (WHERE user_id <> 0),
+			COUNT(*),
+			COUNT(*) FILTER (WHERE reserved_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE paid_at IS NOT NULL)
+		FROM analytics_orders
+		WHERE created_at >= $1 AND created_at < $2;
+	`
+
+	var f domain.Funnel
+	if err := r.pool.QueryRow(ctx, query, period.From, period.To).Scan(
+		&f.RegisteredUsers,
+		&f.OrderingUsers,
+		&f.OrdersCreated,
+		&f.OrdersReserved,
+		&f.OrdersPaid,
+	); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to query funnel", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to query funnel: %w", err)
+	}
+
+	return &f, nil
+}
+
+func (r *analyticsRepo) GetTopProducts(ctx context.Context, period domain.DateRange, limit int64) ([]domain.TopProduct, error) {
+	ctx, span := r.tracer.Start(ctx, "AnalyticsRepository.GetTopProducts")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("limit", limit))
+
+	query := `
+		SELECT i.product_id, SUM(i.quantity) AS units, COUNT(DISTINCT i.order_id) AS orders
+		FROM analytics_order_items i
+		JOIN analytics_orders o ON o.order_id = i.order_id
+		WHERE o.created_at >= $1 AND o.created_at < $2
+			AND o.cancelled_at IS NULL
+		GROUP BY i.product_id
+		ORDER BY units DESC, orders DESC, i.product_id
+		LIMIT $3;
+	`
+
+	rows, err := r.pool.Query(ctx, query, period.From, period.To, limit)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to query top products", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to query top products: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]domain.TopProduct, 0, limit)
+	for rows.Next() {
+		var p domain.TopProduct
+		if err := rows.Scan(&p.ProductID, &p.Units, &p.Orders); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan top product: %w", err)
+		}
+
+		result = append(result, p)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
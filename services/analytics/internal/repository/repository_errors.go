@@ -0,0 +1,7 @@
+package repository
+
+import "errors"
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+)
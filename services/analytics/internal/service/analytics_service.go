@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/domain"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"go.uber.org/zap"
+)
+
+const (
+	dateLayout         = "2006-01-02"
+	defaultRangeDays   = 30
+	maxRangeDays       = 366
+	defaultTopProducts = 10
+	maxTopProducts     = 100
+)
+
+type AnalyticsService interface {
+	HandleUserRegistered(ctx context.Context, event *domain.UserRegisteredEvent, at time.Time) error
+	HandleOrderCreated(ctx context.Context, event *domain.OrderCreatedEvent, at time.Time) error
+	HandleOrderStage(ctx context.Context, orderID int64, stage repository.OrderStage, amount int64, at time.Time) error
+	GetOrderVolume(ctx context.Context, period domain.DateRange) ([]domain.DailyVolume, error)
+	GetFunnel(ctx context.Context, period domain.DateRange) (*domain.Funnel, error)
+	GetTopProducts(ctx context.Context, period domain.DateRange, limit int64) ([]domain.TopProduct, error)
+}
+
+type analyticsService struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	repo   repository.AnalyticsRepository
+}
+
+func NewAnalyticsService(pool *pgxpool.Pool, logger *zap.Logger, repo repository.AnalyticsRepository) AnalyticsService {
+	return &analyticsService{
+		pool:   pool,
+		logger: logger,
+		repo:   repo,
+	}
+}
+
+// ParseDateRange turns inclusive YYYY-MM-DD bounds into a half-open UTC
+// range. Empty bounds default to the last 30 days ending today.
+func ParseDateRange(from, to string, now time.Time) (domain.DateRange, error) {
+	today := now.UTC().Truncate(24 * time.Hour)
+
+	end := today
+	if to != "" {
+		parsed, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return domain.DateRange{}, fmt.Errorf("%w: bad 'to' date", ErrInvalidRange)
+		}
+		end = parsed
+	}
+
+	start := end.AddDate(0, 0, -(defaultRangeDays - 1))
+	if from != "" {
+		parsed, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return domain.DateRange{}, fmt.Errorf("%w: bad 'from' date", ErrInvalidRange)
+		}
+		start = parsed
+	}
+
+	if start.After(end) {
+		return domain.DateRange{}, fmt.Errorf("%w: 'from' is after 'to'", ErrInvalidRange)
+	}
+
+	period := domain.DateRange{From: start, To: end.AddDate(0, 0, 1)}
+	if period.To.Sub(period.From) > maxRangeDays*24*time.Hour {
+		return domain.DateRange{}, fmt.Errorf("%w: range exceeds %d days", ErrInvalidRange, maxRangeDays)
+	}
+
+	return period, nil
+}
+
+func (s *analyticsService) HandleUserRegistered(ctx context.Context, event *domain.UserRegisteredEvent, at time.Time) error {
+	return s.repo.RecordUserRegistered(ctx, event.UserID, at)
+}
+
+func (s *analyticsService) HandleOrderCreated(ctx context.Context, event *domain.OrderCreatedEvent, at time.Time) error {
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return s.repo.RecordOrderCreated(ctx, tx, event, at)
+	})
+}
+
+func (s *analyticsService) HandleOrderStage(ctx context.Context, orderID int64, stage repository.OrderStage, amount int64, at time.Time) error {
+	return s.repo.RecordOrderStage(ctx, orderID, stage, amount, at)
+}
+
+func (s *analyticsService) GetOrderVolume(ctx context.Context, period domain.DateRange) ([]domain.DailyVolume, error) {
+	return s.repo.GetDailyVolume(ctx, period)
+}
+
+func (s *analyticsService) GetFunnel(ctx context.Context, period domain.DateRange) (*domain.Funnel, error) {
+	return s.repo.GetFunnel(ctx, period)
+}
+
+func (s *analyticsService) GetTopProducts(ctx context.Context, period domain.DateRange, limit int64) ([]domain.TopProduct, error) {
+	if limit <= 0 {
+		limit = defaultTopProducts
+	}
+	if limit > maxTopProducts {
+		limit = maxTopProducts
+	}
+
+	return s.repo.GetTopProducts(ctx, period, limit)
+}
@@ -0,0 +1,7 @@
+package service
+
+import "errors"
+
+var (
+	ErrInvalidRange = errors.New("invalid date range")
+)
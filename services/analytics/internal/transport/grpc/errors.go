@@ -0,0 +1,17 @@
+package grpc
+
+import (
+	"errors"
+
+	"github.com/sakashimaa/go-pet-project/analytics/internal/service"
+	"google.golang.org/grpc/codes"
+)
+
+func mapErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, service.ErrInvalidRange):
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
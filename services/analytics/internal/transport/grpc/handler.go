@@ -0,0 +1,115 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/analytics/internal/domain"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/service"
+	pb "github.com/sakashimaa/go-pet-project/proto/analytics"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+type AnalyticsHandler struct {
+	pb.UnimplementedAnalyticsServiceServer
+	service service.AnalyticsService
+	logger  *zap.Logger
+}
+
+func NewAnalyticsHandler(service service.AnalyticsService, logger *zap.Logger) *AnalyticsHandler {
+	return &AnalyticsHandler{service: service, logger: logger}
+}
+
+func (h *AnalyticsHandler) GetOrderVolume(ctx context.Context, req *pb.GetOrderVolumeRequest) (*pb.GetOrderVolumeResponse, error) {
+	period, err := parseRange(req.GetRange())
+	if err != nil {
+		return nil, h.fail("GetOrderVolume", err)
+	}
+
+	days, err := h.service.GetOrderVolume(ctx, period)
+	if err != nil {
+		return nil, h.fail("GetOrderVolume", err)
+	}
+
+	res := &pb.GetOrderVolumeResponse{
+		Days: make([]*pb.DailyVolume, 0, len(days)),
+	}
+	for _, day := range days {
+		res.TotalOrders += day.Orders
+		res.TotalRevenue += day.Revenue
+		res.Days = append(res.Days, &pb.DailyVolume{
+			Day:             day.Day.Format(time.DateOnly),
+			Orders:          day.Orders,
+			PaidOrders:      day.PaidOrders,
+			CancelledOrders: day.CancelledOrders,
+			FailedPayments:  day.FailedPayments,
+			Revenue:         day.Revenue,
+		})
+	}
+
+	return res, nil
+}
+
+func (h *AnalyticsHandler) GetFunnel(ctx context.Context, req *pb.GetFunnelRequest) (*pb.GetFunnelResponse, error) {
+	period, err := parseRange(req.GetRange())
+	if err != nil {
+		return nil, h.fail("GetFunnel", err)
+	}
+
+	funnel, err := h.service.GetFunnel(ctx, period)
+	if err != nil {
+		return nil, h.fail("GetFunnel", err)
+	}
+
+	return &pb.GetFunnelResponse{
+		RegisteredUsers: funnel.RegisteredUsers,
+		OrderingUsers:   funnel.OrderingUsers,
+		OrdersCreated:   funnel.OrdersCreated,
+		OrdersReserved:  funnel.OrdersReserved,
+		OrdersPaid:      funnel.OrdersPaid,
+		ConversionRate:  funnel.ConversionRate(),
+	}, nil
+}
+
+func (h *AnalyticsHandler) GetTopProducts(ctx context.Context, req *pb.GetTopProductsRequest) (*pb.GetTopProductsResponse, error) {
+	period, err := parseRange(req.GetRange())
+	if err != nil {
+		return nil, h.fail("GetTopProducts", err)
+	}
+
+	products, err := h.service.GetTopProducts(ctx, period, req.Limit)
+	if err != nil {
+		return nil, h.fail("GetTopProducts", err)
+	}
+
+	res := &pb.GetTopProductsResponse{
+		Products: make([]*pb.TopProduct, 0, len(products)),
+	}
+	for _, p := range products {
+		res.Products = append(res.Products, &pb.TopProduct{
+			ProductId: p.ProductID,
+			Units:     p.Units,
+			Orders:    p.Orders,
+		})
+	}
+
+	return res, nil
+}
+
+func (h *AnalyticsHandler) fail(method string, err error) error {
+	code := mapErrorCode(err)
+
+	h.logger.Error(
+		"analytics query failed",
+		zap.String("method", method),
+		zap.String("status_code", code.String()),
+		zap.Error(err),
+	)
+
+	return status.Error(code, err.Error())
+}
+
+func parseRange(r *pb.DateRange) (domain.DateRange, error) {
+	return service.ParseDateRange(r.GetFrom(), r.GetTo(), time.Now())
+}
@@ -0,0 +1,92 @@
+package kafka
+
+import (
+	"context"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/domain"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/repository"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/service"
+	"github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"go.uber.org/zap"
+)
+
+// GroupID is the consumer group the service reads with.
+const GroupID = "analytics-service-group"
+
+// Topics are the topics GroupID subscribes to.
+var Topics = []string{"user_events", "order_events", "payment_events", "product_events"}
+
+type Consumer struct {
+	service service.AnalyticsService
+	router  *kafka.Router
+	logger  *zap.Logger
+}
+
+func NewConsumer(service service.AnalyticsService, logger *zap.Logger) *Consumer {
+	c := &Consumer{
+		service: service,
+		logger:  logger,
+	}
+
+	c.router = kafka.NewRouter(logger, kafka.StrictPayloads()).Register(
+		kafka.OnMessage("UserRegistered", c.userRegistered),
+		kafka.OnMessage("OrderCreated", c.orderCreated),
+		kafka.OnMessage("InventoryReserved", c.orderReserved),
+		kafka.OnMessage("InventoryPartiallyReserved", c.orderReserved),
+		kafka.OnMessage("OrderConfirmed", c.orderReserved),
+		kafka.OnMessage("PaymentSucceeded", c.paymentSettled(repository.StagePaid)),
+		kafka.OnMessage("PaymentFailed", c.paymentSettled(repository.StageFailed)),
+		kafka.OnMessage("OrderCancelled", c.orderCancelled),
+	)
+
+	return c
+}
+
+func (c *Consumer) Start(ctx context.Context, config kafka.Config, opts ...kafka.ConsumerOption) {
+	consumerGroup := kafka.NewConsumerGroup(
+		config,
+		GroupID,
+		Topics,
+		c.router.Route,
+		c.logger,
+		opts...,
+	)
+
+	consumerGroup.Run(ctx)
+}
+
+// occurredAt stands in the broker time for when the fact happened, since
+// events carry no common timestamp.
+func occurredAt(msg *sarama.ConsumerMessage) time.Time {
+	if msg.Timestamp.IsZero() {
+		return time.Now().UTC()
+	}
+
+	return msg.Timestamp.UTC()
+}
+
+func (c *Consumer) userRegistered(ctx context.Context, msg *sarama.ConsumerMessage, event *domain.UserRegisteredEvent) error {
+	return c.service.HandleUserRegistered(ctx, event, occurredAt(msg))
+}
+
+func (c *Consumer) orderCreated(ctx context.Context, msg *sarama.ConsumerMessage, event *domain.OrderCreatedEvent) error {
+	return c.service.HandleOrderCreated(ctx, event, occurredAt(msg))
+}
+
+func (c *Consumer) orderReserved(ctx context.Context, msg *sarama.ConsumerMessage, event *domain.InventoryReservedEvent) error {
+	return c.service.HandleOrderStage(ctx, event.OrderID, repository.StageReserved, event.Amount, occurredAt(msg))
+}
+
+// paymentSettled routes PaymentSucceeded or PaymentFailed, which share a
+// payload, to stage.
+func (c *Consumer) paymentSettled(stage repository.OrderStage) func(context.Context, *sarama.ConsumerMessage, *domain.PaymentEvent) error {
+	return func(ctx context.Context, msg *sarama.ConsumerMessage, event *domain.PaymentEvent) error {
+		return c.service.HandleOrderStage(ctx, event.OrderID, stage, event.Amount, occurredAt(msg))
+	}
+}
+
+func (c *Consumer) orderCancelled(ctx context.Context, msg *sarama.ConsumerMessage, event *domain.OrderCancelledEvent) error {
+	return c.service.HandleOrderStage(ctx, event.OrderID, repository.StageCancelled, 0, occurredAt(msg))
+}
@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS analytics_users (
+    user_id BIGINT PRIMARY KEY,
+    registered_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_users_registered_at
+    ON analytics_users(registered_at);
+
+-- One row per order; each saga step fills in its own timestamp so replayed
+-- events never double count.
+CREATE TABLE IF NOT EXISTS analytics_orders (
+    order_id BIGINT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    reserved_at TIMESTAMP,
+    paid_at TIMESTAMP,
+    failed_at TIMESTAMP,
+    cancelled_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_orders_created_at
+    ON analytics_orders(created_at);
+CREATE INDEX IF NOT EXISTS idx_analytics_orders_paid_at
+    ON analytics_orders(paid_at) INCLUDE (amount)
+    WHERE paid_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS analytics_order_items (
+    order_id BIGINT NOT NULL REFERENCES analytics_orders(order_id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL,
+    quantity BIGINT NOT NULL,
+    PRIMARY KEY (order_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_analytics_order_items_product
+    ON analytics_order_items(product_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS analytics_order_items;
+-- DROP TABLE IF EXISTS analytics_orders;
+-- DROP TABLE IF EXISTS analytics_users;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_analytics_orders_failed_at
+    ON analytics_orders(failed_at)
+    WHERE failed_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_analytics_orders_failed_at;
+-- +goose StatementEnd
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/analytics/internal/domain"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/repository"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/service"
+)
+
+func (s *IntegrationTestSuite) seedOrders() {
+	jan1 := s.day("2026-01-01").Add(10 * time.Hour)
+	jan2 := s.day("2026-01-02").Add(10 * time.Hour)
+
+	for userID := int64(1); userID <= 4; userID++ {
+		s.Require().NoError(s.AnalyticsService.HandleUserRegistered(s.Ctx, &domain.UserRegisteredEvent{UserID: userID}, jan1))
+	}
+
+	orders := []struct {
+		event *domain.OrderCreatedEvent
+		at    time.Time
+	}{
+		{&domain.OrderCreatedEvent{OrderID: 1, UserID: 1, Items: []domain.OrderItemEvent{{ProductID: 10, Quantity: 2}, {ProductID: 20, Quantity: 1}}}, jan1},
+		{&domain.OrderCreatedEvent{OrderID: 2, UserID: 2, Items: []domain.OrderItemEvent{{ProductID: 10, Quantity: 1}}}, jan1},
+		{&domain.OrderCreatedEvent{OrderID: 3, UserID: 2, Items: []domain.OrderItemEvent{{ProductID: 30, Quantity: 5}}}, jan2},
+	}
+	for _, o := range orders {
+		s.Require().NoError(s.AnalyticsService.HandleOrderCreated(s.Ctx, o.event, o.at))
+	}
+
+	s.Require().NoError(s.AnalyticsService.HandleOrderStage(s.Ctx, 1, repository.StageReserved, 3000, jan1))
+	s.Require().NoError(s.AnalyticsService.HandleOrderStage(s.Ctx, 1, repository.StagePaid, 3000, jan1))
+	s.Require().NoError(s.AnalyticsService.HandleOrderStage(s.Ctx, 2, repository.StageReserved, 1000, jan1))
+	s.Require().NoError(s.AnalyticsService.HandleOrderStage(s.Ctx, 2, repository.StagePaid, 1000, jan2))
+	s.Require().NoError(s.AnalyticsService.HandleOrderStage(s.Ctx, 3, repository.StageFailed, 2500, jan2))
+	s.Require().NoError(s.AnalyticsService.HandleOrderStage(s.Ctx, 3, repository.StageCancelled, 0, jan2))
+}
+
+func (s *IntegrationTestSuite) TestOrderVolume_DailyBuckets() {
+	s.seedOrders()
+
+	days, err := s.AnalyticsService.GetOrderVolume(s.Ctx, s.period("2026-01-01", "2026-01-03"))
+	s.Require().NoError(err)
+	s.Require().Len(days, 3)
+
+	s.Require().Equal(int64(2), days[0].Orders)
+	s.Require().Equal(int64(1), days[0].PaidOrders)
+	s.Require().Equal(int64(3000), days[0].Revenue)
+
+	s.Require().Equal(int64(1), days[1].Orders)
+	s.Require().Equal(int64(1), days[1].CancelledOrders)
+	s.Require().Equal(int64(1), days[1].FailedPayments)
+	s.Require().Equal(int64(1000), days[1].Revenue)
+
+	s.Require().Zero(days[2].Orders)
+}
+
+func (s *IntegrationTestSuite) TestOrderStage_ReplayIsIdempotent() {
+	s.seedOrders()
+
+	later := s.day("2026-01-03")
+	s.Require().NoError(s.AnalyticsService.HandleOrderStage(s.Ctx, 1, repository.StagePaid, 3000, later))
+	s.Require().NoError(s.AnalyticsService.HandleOrderCreated(s.Ctx, &domain.OrderCreatedEvent{
+		OrderID: 1,
+		UserID:  1,
+		Items:   []domain.OrderItemEvent{{ProductID: 10, Quantity: 2}, {ProductID: 20, Quantity: 1}},
+	}, later))
+
+	days, err := s.AnalyticsService.GetOrderVolume(s.Ctx, s.period("2026-01-01", "2026-01-03"))
+	s.Require().NoError(err)
+	s.Require().Equal(int64(3000), days[0].Revenue)
+	s.Require().Zero(days[2].Revenue)
+
+	top, err := s.AnalyticsService.GetTopProducts(s.Ctx, s.period("2026-01-01", "2026-01-03"), 1)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(3), top[0].Units)
+}
+
+func (s *IntegrationTestSuite) TestOrderStage_BeforeOrderCreated() {
+	jan5 := s.day("2026-01-05").Add(12 * time.Hour)
+
+	s.Require().NoError(s.AnalyticsService.HandleOrderStage(s.Ctx, 42, repository.StagePaid, 500, jan5))
+	s.Require().NoError(s.AnalyticsService.HandleOrderCreated(s.Ctx, &domain.OrderCreatedEvent{
+		OrderID: 42,
+		UserID:  7,
+		Items:   []domain.OrderItemEvent{{ProductID: 1, Quantity: 1}},
+	}, jan5.Add(-time.Hour)))
+
+	funnel, err := s.AnalyticsService.GetFunnel(s.Ctx, s.period("2026-01-05", "2026-01-05"))
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), funnel.OrdersCreated)
+	s.Require().Equal(int64(1), funnel.OrdersPaid)
+	s.Require().Equal(int64(1), funnel.OrderingUsers)
+}
+
+func (s *IntegrationTestSuite) TestFunnel() {
+	s.seedOrders()
+
+	funnel, err := s.AnalyticsService.GetFunnel(s.Ctx, s.period("2026-01-01", "2026-01-02"))
+	s.Require().NoError(err)
+	s.Require().Equal(int64(4), funnel.RegisteredUsers)
+	s.Require().Equal(int64(2), funnel.OrderingUsers)
+	s.Require().Equal(int64(3), funnel.OrdersCreated)
+	s.Require().Equal(int64(2), funnel.OrdersReserved)
+	s.Require().Equal(int64(2), funnel.OrdersPaid)
+	s.Require().InDelta(2.0/3.0, funnel.ConversionRate(), 0.0001)
+}
+
+func (s *IntegrationTestSuite) TestTopProducts_ExcludesCancelled() {
+	s.seedOrders()
+
+	top, err := s.AnalyticsService.GetTopProducts(s.Ctx, s.period("2026-01-01", "2026-01-02"), 10)
+	s.Require().NoError(err)
+	s.Require().Len(top, 2)
+	s.Require().Equal(int64(10), top[0].ProductID)
+	s.Require().Equal(int64(3), top[0].Units)
+	s.Require().Equal(int64(2), top[0].Orders)
+	s.Require().Equal(int64(20), top[1].ProductID)
+}
+
+func (s *IntegrationTestSuite) TestParseDateRange_Invalid() {
+	now := time.Now()
+
+	_, err := service.ParseDateRange("2026-02-01", "2026-01-01", now)
+	s.Require().ErrorIs(err, service.ErrInvalidRange)
+
+	_, err = service.ParseDateRange("yesterday", "", now)
+	s.Require().ErrorIs(err, service.ErrInvalidRange)
+
+	_, err = service.ParseDateRange("2024-01-01", "2026-01-01", now)
+	s.Require().ErrorIs(err, service.ErrInvalidRange)
+}
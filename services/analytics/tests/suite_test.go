@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/analytics/internal/domain"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/repository"
+	"github.com/sakashimaa/go-pet-project/analytics/internal/service"
+	"github.com/sakashimaa/go-pet-project/pkg/testsuite"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+type IntegrationTestSuite struct {
+	testsuite.BaseSuite
+
+	AnalyticsService service.AnalyticsService
+}
+
+func (s *IntegrationTestSuite) SetupSuite() {
+	s.BaseSuite.SetupInfrastructure("../migrations")
+}
+
+func (s *IntegrationTestSuite) TearDownSuite() {
+	s.BaseSuite.TearDownInfrastructure()
+}
+
+func (s *IntegrationTestSuite) SetupTest() {
+	s.BaseSuite.TruncateTable("analytics_order_items")
+	s.BaseSuite.TruncateTable("analytics_orders")
+	s.BaseSuite.TruncateTable("analytics_users")
+
+	logger := zap.NewNop()
+	repo := repository.NewAnalyticsRepository(s.DbPool, logger)
+	s.AnalyticsService = service.NewAnalyticsService(s.DbPool, logger, repo)
+}
+
+func (s *IntegrationTestSuite) day(value string) time.Time {
+	t, err := time.Parse(time.DateOnly, value)
+	s.Require().NoError(err)
+
+	return t
+}
+
+func (s *IntegrationTestSuite) period(from, to string) domain.DateRange {
+	period, err := service.ParseDateRange(from, to, time.Now())
+	s.Require().NoError(err)
+
+	return period
+}
+
+func TestIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(IntegrationTestSuite))
+}
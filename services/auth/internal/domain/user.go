@@ -2,12 +2,26 @@ package domain
 
 import "time"
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+const (
+	LocaleEN = "en"
+	LocaleRU = "ru"
+
+	DefaultLocale = LocaleEN
+)
+
 type User struct {
 	ID                  int64     `db:"id"`
 	Email               string    `db:"email"`
 	Password            string    `db:"password_hash"`
 	ActivationToken     string    `db:"activation_token"`
 	IsActivated         bool      `db:"is_activated"`
+	Role                string    `db:"role"`
+	Locale              string    `db:"locale"`
 	ForgotPasswordToken string    `db:"forgot_password_token"`
 	CreatedAt           time.Time `db:"created_at"`
 	UpdatedAt           time.Time `db:"updated_at"`
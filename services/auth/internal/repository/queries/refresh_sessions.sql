@@ -0,0 +1,4 @@
+-- name: GetRefreshSessionByToken :one
+SELECT id, user_id, token, expires_at, created_at
+FROM refresh_sessions
+WHERE token = $1;
@@ -0,0 +1,19 @@
+-- name: GetUserByID :one
+SELECT id, email, is_activated, role, locale
+FROM users
+WHERE id = $1 AND tenant_id = $2;
+
+-- name: GetUserByEmail :one
+SELECT id, email, is_activated, role, password_hash, locale, created_at, updated_at
+FROM users
+WHERE email = $1 AND tenant_id = $2;
+
+-- name: UpdateUserRole :execrows
+UPDATE users
+SET role = $2, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $3;
+
+-- name: UpdateUserLocale :execrows
+UPDATE users
+SET locale = $2, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $3;
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/retention"
+)
+
+// Retention sweeps run for the whole deployment, so unlike the rest of the
+// repository they are not scoped to the tenant on ctx.
+
+func (r *verifyUserRepository) PurgeExpiredSessions(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.PurgeExpiredSessions")
+	defer span.End()
+
+	n, err := retention.DeleteBefore(r.pool, "refresh_sessions", "expires_at")(ctx, cutoff)
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	return n, err
+}
+
+func (r *verifyUserRepository) ClearForgotPasswordTokens(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.ClearForgotPasswordTokens")
+	defer span.End()
+
+	// Tokens issued before issuance was recorded count as expired.
+	query := `
+		UPDATE users
+		SET forgot_password_token = NULL, forgot_password_token_issued_at = NULL
+		WHERE forgot_password_token IS NOT NULL
+		  AND forgot_password_token <> ''
+		  AND (forgot_password_token_issued_at IS NULL OR forgot_password_token_issued_at < $1);
+	`
+
+	ct, err := r.pool.Exec(ctx, query, cutoff)
+	if err != nil {
+		span.RecordError(err)
+
+		return 0, fmt.Errorf("error clearing forgot password tokens: %w", err)
+	}
+
+	return ct.RowsAffected(), nil
+}
+
+func (r *verifyUserRepository) ClearActivationTokens(ctx context.Context, cutoff time.Time) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.ClearActivationTokens")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET activation_token = NULL
+		WHERE activation_token IS NOT NULL
+		  AND activation_token <> ''
+		  AND created_at < $1;
+	`
+
+	ct, err := r.pool.Exec(ctx, query, cutoff)
+	if err != nil {
+		span.RecordError(err)
+
+		return 0, fmt.Errorf("error clearing activation tokens: %w", err)
+	}
+
+	return ct.RowsAffected(), nil
+}
@@ -4,12 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sakashimaa/go-pet-project/auth/internal/domain"
+	"github.com/sakashimaa/go-pet-project/auth/internal/repository/sqlc"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -18,6 +21,9 @@ import (
 
 type UserRepository interface {
 	Create(ctx context.Context, tx pgx.Tx, user *domain.User) (*domain.User, error)
+	// Import creates a user moved over from another system, activated or not
+	// as user says. It fails with ErrUserAlreadyExists if the email is taken.
+	Import(ctx context.Context, tx pgx.Tx, user *domain.User) error
 	GetByEmail(ctx context.Context, email string) (*domain.User, error)
 	GetByID(ctx context.Context, id int64) (*domain.User, error)
 	SaveSessionToDB(ctx context.Context, session *domain.RefreshSession) error
@@ -25,13 +31,29 @@ type UserRepository interface {
 	DeleteSessionByID(ctx context.Context, id int64) error
 	DeleteSessionByToken(ctx context.Context, token string) error
 	VerifyUser(ctx context.Context, token string) error
-	SetForgotPasswordToken(ctx context.Context, tx pgx.Tx, email string, token string) error
-	ResetPassword(ctx context.Context, tx pgx.Tx, token string, newPassword string) (string, error)
+	// SetForgotPasswordToken returns the user's locale for the reset email.
+	SetForgotPasswordToken(ctx context.Context, tx pgx.Tx, email string, token string) (string, error)
+	// ResetPassword returns the email and locale of the affected user.
+	ResetPassword(ctx context.Context, tx pgx.Tx, token string, newPassword string) (string, string, error)
 	FindUserByID(ctx context.Context, id int64) (*domain.User, error)
+	ChangeUserRole(ctx context.Context, tx pgx.Tx, id int64, role string) error
+	UpdateLocale(ctx context.Context, id int64, locale string) error
+	// RehashPassword replaces oldHash with newHash. It leaves the user alone
+	// when the password changed since oldHash was read.
+	RehashPassword(ctx context.Context, id int64, oldHash, newHash string) error
+	// PurgeExpiredSessions deletes sessions that expired before cutoff.
+	PurgeExpiredSessions(ctx context.Context, cutoff time.Time) (int64, error)
+	// ClearForgotPasswordTokens voids reset tokens issued before cutoff.
+	ClearForgotPasswordTokens(ctx context.Context, cutoff time.Time) (int64, error)
+	// ClearActivationTokens voids the activation tokens of accounts
+	// registered before cutoff and never activated.
+	ClearActivationTokens(ctx context.Context, cutoff time.Time) (int64, error)
 }
 
 type verifyUserRepository struct {
-	pool   *pgxpool.Pool
+	pool *pgxpool.Pool
+	// q holds the sqlc-generated queries from queries/*.sql.
+	q      *sqlc.Queries
 	tracer trace.Tracer
 	logger *zap.Logger
 }
@@ -39,11 +61,43 @@ type verifyUserRepository struct {
 func NewUserRepository(pool *pgxpool.Pool, logger *zap.Logger) UserRepository {
 	return &verifyUserRepository{
 		pool:   pool,
+		q:      sqlc.New(pool),
 		logger: logger,
 		tracer: otel.Tracer("repository/user_repo"),
 	}
 }
 
+func (r *verifyUserRepository) ChangeUserRole(ctx context.Context, tx pgx.Tx, id int64, role string) error {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.ChangeUserRole")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("id", id),
+		attribute.String("role", role),
+	)
+
+	affected, err := r.q.WithTx(tx).UpdateUserRole(ctx, sqlc.UpdateUserRoleParams{ID: id, Role: role, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to change user role",
+			zap.Int64("user_id", id),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("error changing user role: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
 func (r *verifyUserRepository) FindUserByID(ctx context.Context, id int64) (*domain.User, error) {
 	ctx, span := r.tracer.Start(ctx, "UserRepository.FindUserByID")
 	defer span.End()
@@ -52,15 +106,8 @@ func (r *verifyUserRepository) FindUserByID(ctx context.Context, id int64) (*dom
 		attribute.Int64("id", id),
 	)
 
-	query := `
-		SELECT id, is_activated, email
-		FROM users
-		WHERE id = $1;
-	`
-
-	var result domain.User
-	if err := r.pool.QueryRow(ctx, query, id).
-		Scan(&result.ID, &result.IsActivated, &result.Email); err != nil {
+	row, err := r.q.GetUserByID(ctx, sqlc.GetUserByIDParams{ID: id, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			span.RecordError(err)
 
@@ -80,29 +127,29 @@ func (r *verifyUserRepository) FindUserByID(ctx context.Context, id int64) (*dom
 		return nil, fmt.Errorf("error finding user: %w", err)
 	}
 
-	return &result, nil
+	return userFromIDRow(row), nil
 }
 
-func (r *verifyUserRepository) ResetPassword(ctx context.Context, tx pgx.Tx, token string, newPassword string) (string, error) {
+func (r *verifyUserRepository) ResetPassword(ctx context.Context, tx pgx.Tx, token string, newPassword string) (string, string, error) {
 	ctx, span := r.tracer.Start(ctx, "UserRepository.ResetPassword")
 	defer span.End()
 
 	query := `
 		UPDATE users
 		SET password_hash = $1, forgot_password_token = ''
-		WHERE forgot_password_token = $2
-		RETURNING email;
+		WHERE forgot_password_token = $2 AND tenant_id = $3
+		RETURNING email, locale;
 	`
 
-	var email string
+	var email, locale string
 
-	err := tx.QueryRow(ctx, query, newPassword, token).
-		Scan(&email)
+	err := tx.QueryRow(ctx, query, newPassword, token, tenant.FromContext(ctx)).
+		Scan(&email, &locale)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			span.RecordError(err)
 
-			return "", ErrUserNotFound
+			return "", "", ErrUserNotFound
 		}
 
 		span.RecordError(err)
@@ -114,13 +161,13 @@ func (r *verifyUserRepository) ResetPassword(ctx context.Context, tx pgx.Tx, tok
 			zap.Error(err),
 		)
 
-		return "", fmt.Errorf("error resetting user password: %w", err)
+		return "", "", fmt.Errorf("error resetting user password: %w", err)
 	}
 
-	return email, nil
+	return email, locale, nil
 }
 
-func (r *verifyUserRepository) SetForgotPasswordToken(ctx context.Context, tx pgx.Tx, email string, token string) error {
+func (r *verifyUserRepository) SetForgotPasswordToken(ctx context.Context, tx pgx.Tx, email string, token string) (string, error) {
 	ctx, span := r.tracer.Start(ctx, "UserRepository.SetForgotPasswordToken")
 	defer span.End()
 
@@ -130,20 +177,20 @@ func (r *verifyUserRepository) SetForgotPasswordToken(ctx context.Context, tx pg
 
 	query := `
 		UPDATE users
-		SET forgot_password_token = $1
-		WHERE email = $2
-		RETURNING id;
+		SET forgot_password_token = $1, forgot_password_token_issued_at = NOW()
+		WHERE email = $2 AND tenant_id = $3
+		RETURNING locale;
  	`
 
-	var id int64
+	var locale string
 
-	err := tx.QueryRow(ctx, query, token, email).
-		Scan(&id)
+	err := tx.QueryRow(ctx, query, token, email, tenant.FromContext(ctx)).
+		Scan(&locale)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			span.RecordError(err)
 
-			return ErrUserNotFound
+			return "", ErrUserNotFound
 		}
 
 		span.RecordError(err)
@@ -156,10 +203,10 @@ func (r *verifyUserRepository) SetForgotPasswordToken(ctx context.Context, tx pg
 			zap.Error(err),
 		)
 
-		return fmt.Errorf("error setting token for user: %w", err)
+		return "", fmt.Errorf("error setting token for user: %w", err)
 	}
 
-	return nil
+	return locale, nil
 }
 
 func (r *verifyUserRepository) VerifyUser(ctx context.Context, token string) error {
@@ -169,13 +216,13 @@ func (r *verifyUserRepository) VerifyUser(ctx context.Context, token string) err
 	query := `
 		UPDATE users
 		SET is_activated = true, activation_token = ''
-		WHERE activation_token = $1
+		WHERE activation_token = $1 AND tenant_id = $2
 		RETURNING id;
     `
 
 	var id int64
 
-	err := r.pool.QueryRow(ctx, query, token).Scan(&id)
+	err := r.pool.QueryRow(ctx, query, token, tenant.FromContext(ctx)).Scan(&id)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -269,15 +316,8 @@ func (r *verifyUserRepository) FindSessionByToken(ctx context.Context, token str
 	ctx, span := r.tracer.Start(ctx, "UserRepository.FindSessionByToken")
 	defer span.End()
 
-	query := `
-		SELECT id, user_id, token, expires_at, created_at
-		FROM refresh_sessions
-		WHERE token = $1;
-	`
-
-	var result domain.RefreshSession
-	if err := r.pool.QueryRow(ctx, query, token).
-		Scan(&result.ID, &result.UserID, &result.Token, &result.ExpiresAt, &result.CreatedAt); err != nil {
+	session, err := r.q.GetRefreshSessionByToken(ctx, token)
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			span.RecordError(err)
 
@@ -288,7 +328,13 @@ func (r *verifyUserRepository) FindSessionByToken(ctx context.Context, token str
 		return nil, fmt.Errorf("error getting session: %w", err)
 	}
 
-	return &result, nil
+	return &domain.RefreshSession{
+		ID:        session.ID,
+		UserID:    session.UserID,
+		Token:     session.Token,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}, nil
 }
 
 func (r *verifyUserRepository) Create(ctx context.Context, tx pgx.Tx, user *domain.User) (*domain.User, error) {
@@ -296,17 +342,17 @@ func (r *verifyUserRepository) Create(ctx context.Context, tx pgx.Tx, user *doma
 	defer span.End()
 
 	query := `
-		INSERT INTO users (email, password_hash, activation_token)
-		VALUES ($1, $2, $3)
-		RETURNING id, created_at, updated_at;
+		INSERT INTO users (email, password_hash, activation_token, locale, tenant_id)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, role, created_at, updated_at;
 	`
 
 	span.SetAttributes(
 		attribute.String("user.email", user.Email),
 	)
 
-	err := tx.QueryRow(ctx, query, user.Email, user.Password, user.ActivationToken).
-		Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
+	err := tx.QueryRow(ctx, query, user.Email, user.Password, user.ActivationToken, user.Locale, tenant.FromContext(ctx)).
+		Scan(&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
 	if err != nil {
 		span.RecordError(err)
 
@@ -339,23 +385,56 @@ func (r *verifyUserRepository) Create(ctx context.Context, tx pgx.Tx, user *doma
 	return user, nil
 }
 
-func (r *verifyUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	ctx, span := r.tracer.Start(ctx, "UserRepository.GetByEmail")
+func (r *verifyUserRepository) Import(ctx context.Context, tx pgx.Tx, user *domain.User) error {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.Import")
 	defer span.End()
 
 	span.SetAttributes(
-		attribute.String("email", email),
+		attribute.Bool("user.is_activated", user.IsActivated),
 	)
 
+	// Activated users have no activation token, NULL like the ones the
+	// retention job voided.
 	query := `
-		SELECT id, email, is_activated, password_hash, created_at, updated_at
-		FROM users
-		WHERE email = $1;
+		INSERT INTO users (email, password_hash, activation_token, is_activated, locale, tenant_id)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)
+		ON CONFLICT (tenant_id, email) DO NOTHING
+		RETURNING id, role, created_at, updated_at;
 	`
 
-	var user domain.User
-	if err := r.pool.QueryRow(ctx, query, email).
-		Scan(&user.ID, &user.Email, &user.IsActivated, &user.Password, &user.CreatedAt, &user.UpdatedAt); err != nil {
+	err := tx.QueryRow(ctx, query, user.Email, user.Password, user.ActivationToken, user.IsActivated, user.Locale, tenant.FromContext(ctx)).
+		Scan(&user.ID, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrUserAlreadyExists
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to import user",
+			zap.String("email", user.Email),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("error importing user: %w", err)
+	}
+
+	return nil
+}
+
+func (r *verifyUserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.GetByEmail")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("email", email),
+	)
+
+	row, err := r.q.GetUserByEmail(ctx, sqlc.GetUserByEmailParams{Email: email, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			span.RecordError(err)
 			return nil, ErrUserNotFound
@@ -374,7 +453,16 @@ func (r *verifyUserRepository) GetByEmail(ctx context.Context, email string) (*d
 		return nil, fmt.Errorf("error getting user: %w", err)
 	}
 
-	return &user, nil
+	return &domain.User{
+		ID:          row.ID,
+		Email:       row.Email,
+		Password:    row.PasswordHash,
+		IsActivated: isActivated(row.IsActivated),
+		Role:        row.Role,
+		Locale:      row.Locale,
+		CreatedAt:   row.CreatedAt,
+		UpdatedAt:   row.UpdatedAt,
+	}, nil
 }
 
 func (r *verifyUserRepository) GetByID(ctx context.Context, id int64) (*domain.User, error) {
@@ -385,15 +473,8 @@ func (r *verifyUserRepository) GetByID(ctx context.Context, id int64) (*domain.U
 		attribute.Int64("id", id),
 	)
 
-	query := `
-		SELECT id, email, is_activated
-		FROM users
-		WHERE id = $1;
- 	`
-
-	var user domain.User
-	if err := r.pool.QueryRow(ctx, query, id).
-		Scan(&user.ID, &user.Email, &user.IsActivated); err != nil {
+	row, err := r.q.GetUserByID(ctx, sqlc.GetUserByIDParams{ID: id, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			span.RecordError(err)
 			return nil, ErrUserNotFound
@@ -412,7 +493,7 @@ func (r *verifyUserRepository) GetByID(ctx context.Context, id int64) (*domain.U
 		return nil, fmt.Errorf("error getting user: %w", err)
 	}
 
-	return &user, nil
+	return userFromIDRow(row), nil
 }
 
 func (r *verifyUserRepository) SaveSessionToDB(ctx context.Context, session *domain.RefreshSession) error {
@@ -447,3 +528,59 @@ func (r *verifyUserRepository) SaveSessionToDB(ctx context.Context, session *dom
 
 	return nil
 }
+
+func (r *verifyUserRepository) UpdateLocale(ctx context.Context, id int64, locale string) error {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.UpdateLocale")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("id", id),
+		attribute.String("locale", locale),
+	)
+
+	affected, err := r.q.UpdateUserLocale(ctx, sqlc.UpdateUserLocaleParams{ID: id, Locale: locale, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("error updating user locale: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *verifyUserRepository) RehashPassword(ctx context.Context, id int64, oldHash, newHash string) error {
+	ctx, span := r.tracer.Start(ctx, "UserRepository.RehashPassword")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("id", id))
+
+	_, err := r.pool.Exec(ctx, `
+		UPDATE users
+		SET password_hash = $1
+		WHERE id = $2 AND tenant_id = $3 AND password_hash = $4;
+	`, newHash, id, tenant.FromContext(ctx), oldHash)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("error rehashing user password: %w", err)
+	}
+
+	return nil
+}
+
+func userFromIDRow(row sqlc.GetUserByIDRow) *domain.User {
+	return &domain.User{
+		ID:          row.ID,
+		Email:       row.Email,
+		IsActivated: isActivated(row.IsActivated),
+		Role:        row.Role,
+		Locale:      row.Locale,
+	}
+}
+
+// isActivated treats the legacy NULL is_activated as not activated.
+func isActivated(v *bool) bool {
+	return v != nil && *v
+}
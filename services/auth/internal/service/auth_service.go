@@ -13,19 +13,22 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sakashimaa/go-pet-project/auth/internal/domain"
 	"github.com/sakashimaa/go-pet-project/auth/internal/repository"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/password"
 	"github.com/sakashimaa/go-pet-project/auth/pkg/utils"
 	"github.com/sakashimaa/go-pet-project/auth/pkg/validator"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	pb "github.com/sakashimaa/go-pet-project/proto/auth"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
 	GetUserInfo(ctx context.Context, id int64) (*domain.User, error)
-	Register(ctx context.Context, email, password string) (*domain.User, error)
+	// Register creates a user. An empty locale means domain.DefaultLocale.
+	Register(ctx context.Context, email, password, locale string) (*domain.User, error)
 	Login(ctx context.Context, email, password string) (string, string, error)
 	Validate(ctx context.Context, token string) (*pb.ValidateResponse, error)
 	Refresh(ctx context.Context, request *pb.RefreshRequest) (*pb.RefreshResponse, error)
@@ -33,6 +36,10 @@ type AuthService interface {
 	Verify(ctx context.Context, request *pb.VerifyRequest) (*pb.VerifyResponse, error)
 	ForgotPassword(ctx context.Context, request *pb.ForgotPasswordRequest) (*pb.ForgotPasswordResponse, error)
 	ResetPassword(ctx context.Context, request *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error)
+	ChangeUserRole(ctx context.Context, userID int64, role string) error
+	UpdateLocale(ctx context.Context, userID int64, locale string) error
+	// ImportUsers creates the users next returns until it returns io.EOF.
+	ImportUsers(ctx context.Context, next func() (*pb.ImportUserRecord, error), opts ImportOptions) (*pb.ImportUsersResponse, error)
 }
 
 type authService struct {
@@ -42,6 +49,7 @@ type authService struct {
 	logger        *zap.Logger
 	pool          *pgxpool.Pool
 	validator     validator.Validator
+	hasher        password.Hasher
 }
 
 type EventProducer interface {
@@ -55,6 +63,7 @@ func NewAuthService(
 	logger *zap.Logger,
 	pool *pgxpool.Pool,
 	validator validator.Validator,
+	hasher password.Hasher,
 ) AuthService {
 	return &authService{userRepo: userRepo,
 		outboxRepo:    outboxRepo,
@@ -62,15 +71,70 @@ func NewAuthService(
 		logger:        logger,
 		pool:          pool,
 		validator:     validator,
+		hasher:        hasher,
 	}
 }
 
+func (s *authService) ChangeUserRole(ctx context.Context, userID int64, role string) error {
+	if err := s.validator.ValidateRole(role); err != nil {
+		return err
+	}
+
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		if err := s.userRepo.ChangeUserRole(ctx, tx, userID, role); err != nil {
+			mylogger.Warn(
+				ctx,
+				s.logger,
+				"Error changing user role",
+				zap.Int64("user_id", userID),
+				zap.Error(err),
+			)
+
+			return fmt.Errorf("error changing user role: %w", err)
+		}
+
+		eventEnvelope := map[string]any{
+			"event": "UserRoleChanged",
+			"payload": map[string]any{
+				"user_id": userID,
+				"role":    role,
+			},
+		}
+
+		payloadBytes, err := json.Marshal(eventEnvelope)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event envelope: %w", err)
+		}
+
+		outboxEvent := &outboxDomain.OutboxEvent{
+			AggregateType: "User",
+			AggregateID:   fmt.Sprintf("%d", userID),
+			EventType:     "UserRoleChanged",
+			Payload:       payloadBytes,
+			Topic:         "user_events",
+		}
+
+		if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
+			mylogger.Error(
+				ctx,
+				s.logger,
+				"Error saving outbox event",
+				zap.Error(err),
+			)
+
+			return fmt.Errorf("failed to save outbox event: %w", err)
+		}
+
+		return nil
+	})
+}
+
 func (s *authService) ResetPassword(ctx context.Context, request *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
 	if err := s.validator.ValidatePassword(request.Password); err != nil {
 		return nil, err
 	}
 
-	hashedPass, err := bcrypt.GenerateFromPassword([]byte(request.Password), 12)
+	hashedPass, err := s.hasher.Hash(request.Password)
 	if err != nil {
 		mylogger.Error(
 			ctx,
@@ -82,66 +146,51 @@ func (s *authService) ResetPassword(ctx context.Context, request *pb.ResetPasswo
 		return nil, fmt.Errorf("error hashing password: %w", err)
 	}
 
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error beginning transaction",
-			zap.Error(err),
-		)
-
-		return nil, err
-	}
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		email, locale, err := s.userRepo.ResetPassword(ctx, tx, request.Token, hashedPass)
+		if err != nil {
+			mylogger.Error(
+				ctx,
+				s.logger,
+				"Error resetting password",
+				zap.Error(err),
+			)
 
-	defer func() {
-		shutdownCtx := context.WithoutCancel(ctx)
-		if err := tx.Rollback(shutdownCtx); err != nil {
-			mylogger.Error(ctx, s.logger, "Error rolling back transaction", zap.Error(err))
+			return fmt.Errorf("error resetting password: %w", err)
 		}
-	}()
-
-	email, err := s.userRepo.ResetPassword(ctx, tx, request.Token, string(hashedPass))
-	if err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error resetting password",
-			zap.Error(err),
-		)
 
-		return nil, fmt.Errorf("error resetting password: %w", err)
-	}
+		eventPayload := map[string]interface{}{
+			"email":  email,
+			"locale": locale,
+			"event":  "UserResetPassword",
+		}
 
-	eventPayload := map[string]interface{}{
-		"email": email,
-		"event": "UserResetPassword",
-	}
+		payloadBytes, _ := json.Marshal(eventPayload)
+		outboxEvent := &outboxDomain.OutboxEvent{
+			AggregateType: "User",
+			AggregateID:   email,
+			EventType:     "UserResetPassword",
+			Payload:       payloadBytes,
+			Topic:         "user_events_priority",
+		}
 
-	payloadBytes, _ := json.Marshal(eventPayload)
-	outboxEvent := &outboxDomain.OutboxEvent{
-		AggregateType: "User",
-		AggregateID:   email,
-		EventType:     "UserResetPassword",
-		Payload:       payloadBytes,
-		Topic:         "user_events",
-	}
+		if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
+			mylogger.Error(
+				ctx,
+				s.logger,
+				"Error saving outbox event",
+				zap.Error(err),
+			)
 
-	if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error saving outbox event",
-			zap.Error(err),
-		)
+			return err
+		}
 
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("commit transaction failed: %w", err)
-	}
-
 	return &pb.ResetPasswordResponse{Success: true}, nil
 }
 
@@ -153,70 +202,66 @@ func (s *authService) ForgotPassword(ctx context.Context, request *pb.ForgotPass
 
 	forgotPasswordToken := base64.RawURLEncoding.EncodeToString(b)
 
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Failed to begin tx",
-			zap.Error(err),
-		)
-
-		return nil, fmt.Errorf("failed to begin tx: %w", err)
-	}
-	defer func() {
-		shutdownCtx := context.WithoutCancel(ctx)
-		if err := tx.Rollback(shutdownCtx); err != nil {
-			mylogger.Error(ctx, s.logger, "Error rolling back transaction", zap.Error(err))
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		locale, err := s.userRepo.SetForgotPasswordToken(ctx, tx, request.Email, forgotPasswordToken)
+		// Answer unknown emails exactly like known ones, so the endpoint cannot
+		// be used to find out who has an account.
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil
 		}
-	}()
+		if err != nil {
+			mylogger.Error(
+				ctx,
+				s.logger,
+				"Error saving token",
+				zap.String("method_name", "ForgotPassword"),
+				zap.Error(err),
+			)
 
-	if err := s.userRepo.SetForgotPasswordToken(ctx, tx, request.Email, forgotPasswordToken); err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error saving token",
-			zap.String("method_name", "ForgotPassword"),
-			zap.Error(err),
-		)
+			return fmt.Errorf("error saving token: %w", err)
+		}
 
-		return nil, fmt.Errorf("error saving token: %w", err)
-	}
+		eventPayload := map[string]interface{}{
+			"email":                 request.Email,
+			"forgot_password_token": forgotPasswordToken,
+			"locale":                locale,
+			"event":                 "UserForgotPassword",
+		}
 
-	eventPayload := map[string]interface{}{
-		"email":                 request.Email,
-		"forgot_password_token": forgotPasswordToken,
-		"event":                 "UserForgotPassword",
-	}
+		payloadBytes, _ := json.Marshal(eventPayload)
+		outboxEvent := &outboxDomain.OutboxEvent{
+			AggregateType: "User",
+			AggregateID:   request.Email,
+			EventType:     "UserForgotPassword",
+			Payload:       payloadBytes,
+			Topic:         "user_events_priority",
+		}
 
-	payloadBytes, _ := json.Marshal(eventPayload)
-	outboxEvent := &outboxDomain.OutboxEvent{
-		AggregateType: "User",
-		AggregateID:   request.Email,
-		EventType:     "UserForgotPassword",
-		Payload:       payloadBytes,
-		Topic:         "user_events",
-	}
+		if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
+			mylogger.Error(
+				ctx,
+				s.logger,
+				"Error saving outbox event",
+				zap.Error(err),
+			)
 
-	if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error saving outbox event",
-			zap.Error(err),
-		)
+			return err
+		}
 
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("commit transaction failed: %w", err)
-	}
+	return forgotPasswordResponse(), nil
+}
 
+func forgotPasswordResponse() *pb.ForgotPasswordResponse {
 	return &pb.ForgotPasswordResponse{
 		Success: true,
-		Message: "Reset link is sent to your email",
-	}, nil
+		Message: "If the email is registered, a reset link has been sent to it",
+	}
 }
 
 func (s *authService) Verify(ctx context.Context, request *pb.VerifyRequest) (*pb.VerifyResponse, error) {
@@ -258,7 +303,7 @@ func (s *authService) Logout(ctx context.Context, request *pb.LogoutRequest) (*p
 }
 
 func (s *authService) Refresh(ctx context.Context, request *pb.RefreshRequest) (*pb.RefreshResponse, error) {
-	_, err := utils.ValidateToken(request.RefreshToken, true)
+	claims, err := utils.ValidateToken(request.RefreshToken, true)
 	if err != nil {
 		mylogger.Error(
 			ctx,
@@ -270,6 +315,12 @@ func (s *authService) Refresh(ctx context.Context, request *pb.RefreshRequest) (
 		return nil, fmt.Errorf("error validating token: %w", err)
 	}
 
+	// Checked before the session is consumed, so a refresh sent to the wrong
+	// storefront does not log the user out of the right one.
+	if !issuedForTenant(ctx, claims) {
+		return nil, fmt.Errorf("error validating token: issued for another tenant")
+	}
+
 	session, err := s.userRepo.FindSessionByToken(ctx, request.RefreshToken)
 	if err != nil {
 		mylogger.Error(
@@ -320,7 +371,7 @@ func (s *authService) Refresh(ctx context.Context, request *pb.RefreshRequest) (
 		return nil, err
 	}
 
-	newAccess, newRefresh, err := utils.GenerateTokens(session.UserID, user.IsActivated)
+	newAccess, newRefresh, err := utils.GenerateTokens(session.UserID, user.IsActivated, user.Role, tenant.FromContext(ctx))
 	if err != nil {
 		mylogger.Error(
 			ctx,
@@ -379,18 +430,47 @@ func (s *authService) Validate(ctx context.Context, token string) (*pb.ValidateR
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
+	if !issuedForTenant(ctx, claims) {
+		mylogger.Warn(
+			ctx,
+			s.logger,
+			"Token used outside of its tenant",
+			zap.Int64("user_id", claims.UserID),
+			zap.String("token_tenant", claims.TenantID),
+		)
+
+		return nil, fmt.Errorf("invalid token: issued for another tenant")
+	}
+
+	role := claims.Role
+	if role == "" {
+		role = domain.RoleUser
+	}
+
 	return &pb.ValidateResponse{
 		UserId:      claims.UserID,
 		IsActivated: claims.IsActivated,
+		Role:        role,
 	}, nil
 }
 
-func (s *authService) Register(ctx context.Context, email, password string) (*domain.User, error) {
+func (s *authService) Register(ctx context.Context, email, password, locale string) (*domain.User, error) {
+	if err := s.validator.ValidateEmail(ctx, email); err != nil {
+		return nil, err
+	}
+
 	if err := s.validator.ValidatePassword(password); err != nil {
 		return nil, err
 	}
 
-	hashedPass, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if locale == "" {
+		locale = domain.DefaultLocale
+	}
+	if err := s.validator.ValidateLocale(locale); err != nil {
+		return nil, err
+	}
+
+	hashedPass, err := s.hasher.Hash(password)
 	if err != nil {
 		mylogger.Error(
 			ctx,
@@ -412,67 +492,61 @@ func (s *authService) Register(ctx context.Context, email, password string) (*do
 
 	user := &domain.User{
 		Email:           email,
-		Password:        string(hashedPass),
+		Password:        hashedPass,
 		ActivationToken: activationToken,
-	}
-
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error starting transaction",
-			zap.Error(err),
-		)
-
-		return nil, fmt.Errorf("error starting transaction: %w", err)
-	}
-	defer func() {
-		cleanupCtx := context.WithoutCancel(ctx)
-		err := tx.Rollback(cleanupCtx)
-
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			mylogger.Warn(
+		Locale:          locale,
+	}
+
+	var result *domain.User
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		result, err = s.userRepo.Create(ctx, tx, user)
+		if err != nil {
+			if errors.Is(err, repository.ErrUserAlreadyExists) {
+				mylogger.Info(
+					ctx,
+					s.logger,
+					"User already exists",
+					zap.String("email", email),
+				)
+
+				return err
+			}
+
+			mylogger.Error(
 				ctx,
 				s.logger,
-				"Error rolling back transaction",
+				"Error creating user",
+				zap.String("email", user.Email),
 				zap.Error(err),
-				zap.String("method_name", "Register"),
-				zap.String("service", "auth_service"),
-			)
-		}
-	}()
-
-	result, err := s.userRepo.Create(ctx, tx, user)
-
-	if err != nil {
-		if errors.Is(err, repository.ErrUserAlreadyExists) {
-			mylogger.Info(
-				ctx,
-				s.logger,
-				"User already exists",
-				zap.String("email", email),
 			)
 
-			return nil, err
+			return fmt.Errorf("error creating user: %w", err)
 		}
 
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error creating user",
-			zap.String("email", user.Email),
-			zap.Error(err),
-		)
-
-		return nil, fmt.Errorf("error creating user: %w", err)
+		return s.saveUserRegistered(ctx, tx, result, false)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return result, nil
+}
+
+// saveUserRegistered queues the UserRegistered event order, analytics and
+// notification build on. imported marks users moved over from another
+// system, who get no welcome.
+func (s *authService) saveUserRegistered(ctx context.Context, tx pgx.Tx, user *domain.User, imported bool) error {
 	userData := map[string]interface{}{
-		"user_id":          result.ID,
-		"email":            result.Email,
-		"activation_token": result.ActivationToken,
-		"event_id":         result.ID,
+		"user_id":          user.ID,
+		"email":            user.Email,
+		"activation_token": user.ActivationToken,
+		"role":             user.Role,
+		"locale":           user.Locale,
+		"event_id":         user.ID,
+	}
+	if imported {
+		userData["imported"] = true
 	}
 
 	eventEnvelope := map[string]any{
@@ -489,12 +563,12 @@ func (s *authService) Register(ctx context.Context, email, password string) (*do
 			zap.Error(err),
 		)
 
-		return nil, err
+		return err
 	}
 
 	outboxEvent := &outboxDomain.OutboxEvent{
 		AggregateType: "User",
-		AggregateID:   fmt.Sprintf("%d", result.ID),
+		AggregateID:   fmt.Sprintf("%d", user.ID),
 		EventType:     "UserRegistered",
 		Payload:       payloadBytes,
 		Topic:         "user_events",
@@ -508,14 +582,21 @@ func (s *authService) Register(ctx context.Context, email, password string) (*do
 			zap.Error(err),
 		)
 
-		return nil, fmt.Errorf("failed to save outbox event: %w", err)
+		return fmt.Errorf("failed to save outbox event: %w", err)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	return nil
+}
+
+// issuedForTenant reports whether a token belongs to the tenant of ctx.
+// Tokens issued before tenants existed belong to the default tenant.
+func issuedForTenant(ctx context.Context, claims *utils.Claims) bool {
+	tokenTenant := claims.TenantID
+	if tokenTenant == "" {
+		tokenTenant = tenant.DefaultID
 	}
 
-	return result, nil
+	return tokenTenant == tenant.FromContext(ctx)
 }
 
 func (s *authService) Login(ctx context.Context, email, password string) (string, string, error) {
@@ -532,18 +613,23 @@ func (s *authService) Login(ctx context.Context, email, password string) (string
 		return "", "", fmt.Errorf("invalid credentials")
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
-	if err != nil {
+	ok, err := s.hasher.Verify(user.Password, password)
+	if err != nil || !ok {
 		mylogger.Warn(
 			ctx,
 			s.logger,
 			"Invalid credentials",
+			zap.Error(err),
 		)
 
 		return "", "", fmt.Errorf("invalid credentials")
 	}
 
-	accessToken, refreshToken, err := utils.GenerateTokens(user.ID, user.IsActivated)
+	if s.hasher.NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, user, password)
+	}
+
+	accessToken, refreshToken, err := utils.GenerateTokens(user.ID, user.IsActivated, user.Role, tenant.FromContext(ctx))
 	if err != nil {
 		mylogger.Warn(
 			ctx,
@@ -575,3 +661,39 @@ func (s *authService) Login(ctx context.Context, email, password string) (string
 
 	return accessToken, refreshToken, nil
 }
+
+func (s *authService) UpdateLocale(ctx context.Context, userID int64, locale string) error {
+	if err := s.validator.ValidateLocale(locale); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.UpdateLocale(ctx, userID, locale); err != nil {
+		mylogger.Warn(
+			ctx,
+			s.logger,
+			"Error updating user locale",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	return nil
+}
+
+// rehashPassword moves the hash of a user who just logged in to the current
+// algorithm and parameters, the only time the plain password is at hand. It
+// is best effort: the old hash keeps working if it fails.
+func (s *authService) rehashPassword(ctx context.Context, user *domain.User, plain string) {
+	hash, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.userRepo.RehashPassword(ctx, user.ID, user.Password, hash)
+	}
+	if err != nil {
+		mylogger.Warn(ctx, s.logger, "Failed to rehash password", zap.Int64("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	mylogger.Info(ctx, s.logger, "Password rehashed", zap.Int64("user_id", user.ID))
+}
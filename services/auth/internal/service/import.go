@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/auth/internal/domain"
+	"github.com/sakashimaa/go-pet-project/auth/internal/repository"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/password"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/auth"
+	"go.uber.org/zap"
+)
+
+// maxImportFailures caps the failures an import lists; the rest are only
+// counted.
+const maxImportFailures = 100
+
+// ImportOptions throttle an import. Each batch of users is created in one
+// transaction, and Pause between batches spreads the UserRegistered events
+// so consumers and the activation emails they send keep up.
+type ImportOptions struct {
+	BatchSize int
+	Pause     time.Duration
+}
+
+func (o ImportOptions) withDefaults() ImportOptions {
+	if o.BatchSize <= 0 {
+		o.BatchSize = 100
+	}
+
+	return o
+}
+
+// importedUser is a valid record waiting for its batch.
+type importedUser struct {
+	index int64
+	user  *domain.User
+}
+
+// ImportUsers creates users moved over from another system. Hashes made by
+// an algorithm this service verifies are kept; any other hash is replaced
+// with password.Unusable so the user has to reset their password. Batches
+// are committed as they fill, so if next fails the users of earlier batches
+// stay imported and sending the same records again skips them.
+func (s *authService) ImportUsers(
+	ctx context.Context,
+	next func() (*pb.ImportUserRecord, error),
+	opts ImportOptions,
+) (*pb.ImportUsersResponse, error) {
+	opts = opts.withDefaults()
+
+	res := &pb.ImportUsersResponse{}
+	batch := make([]importedUser, 0, opts.BatchSize)
+	flushed := false
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if flushed && opts.Pause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(opts.Pause):
+			}
+		}
+		flushed = true
+
+		if err := s.importBatch(ctx, batch, res); err != nil {
+			return err
+		}
+		batch = batch[:0]
+
+		return nil
+	}
+
+	for index := int64(0); ; index++ {
+		record, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading import record %d: %w", index, err)
+		}
+
+		user, err := s.importedUser(ctx, record)
+		if err != nil {
+			res.Failed++
+			if len(res.Failures) < maxImportFailures {
+				res.Failures = append(res.Failures, &pb.ImportFailure{Index: index, Email: record.Email, Reason: err.Error()})
+			}
+
+			continue
+		}
+
+		batch = append(batch, importedUser{index: index, user: user})
+		if len(batch) == opts.BatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Users imported",
+		zap.Int64("imported", res.Imported),
+		zap.Int64("reset_required", res.ResetRequired),
+		zap.Int64("skipped", res.Skipped),
+		zap.Int64("failed", res.Failed),
+	)
+
+	return res, nil
+}
+
+// importedUser checks a record and turns it into the user to create.
+func (s *authService) importedUser(ctx context.Context, record *pb.ImportUserRecord) (*domain.User, error) {
+	if err := s.validator.ValidateEmail(ctx, record.Email); err != nil {
+		return nil, err
+	}
+
+	locale := record.Locale
+	if locale == "" {
+		locale = domain.DefaultLocale
+	}
+	if err := s.validator.ValidateLocale(locale); err != nil {
+		return nil, err
+	}
+
+	hash := record.PasswordHash
+	if _, err := password.Algorithm(hash); err != nil {
+		hash = password.Unusable
+	}
+
+	user := &domain.User{
+		Email:       record.Email,
+		Password:    hash,
+		IsActivated: record.IsActivated,
+		Locale:      locale,
+	}
+
+	if !user.IsActivated {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("error reading bytes: %w", err)
+		}
+
+		user.ActivationToken = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	return user, nil
+}
+
+// importBatch creates the users of batch with their UserRegistered events in
+// one transaction and counts them into res.
+func (s *authService) importBatch(ctx context.Context, batch []importedUser, res *pb.ImportUsersResponse) error {
+	var imported, resetRequired, skipped int64
+
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		for _, item := range batch {
+			err := s.userRepo.Import(ctx, tx, item.user)
+			if errors.Is(err, repository.ErrUserAlreadyExists) {
+				skipped++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("error importing record %d: %w", item.index, err)
+			}
+
+			if err := s.saveUserRegistered(ctx, tx, item.user, true); err != nil {
+				return err
+			}
+
+			imported++
+			if item.user.Password == password.Unusable {
+				resetRequired++
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		mylogger.Error(ctx, s.logger, "Error importing users", zap.Int("batch_size", len(batch)), zap.Error(err))
+
+		return err
+	}
+
+	res.Imported += imported
+	res.ResetRequired += resetRequired
+	res.Skipped += skipped
+
+	return nil
+}
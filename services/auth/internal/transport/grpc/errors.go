@@ -4,9 +4,16 @@ import (
 	"errors"
 
 	"github.com/sakashimaa/go-pet-project/auth/internal/repository"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/emaildomain"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
+// errorDomain is the ErrorInfo domain of the details auth attaches.
+const errorDomain = "auth"
+
 func mapErrorCode(err error) codes.Code {
 	switch {
 	case errors.Is(err, repository.ErrUserNotFound):
@@ -17,7 +24,36 @@ func mapErrorCode(err error) codes.Code {
 		return codes.FailedPrecondition
 	case errors.Is(err, repository.ErrInvalidToken):
 		return codes.InvalidArgument
+	case errors.Is(err, validator.ErrInvalidRole),
+		errors.Is(err, validator.ErrInvalidLocale):
+		return codes.InvalidArgument
+	case errors.Is(err, emaildomain.ErrDisposable),
+		errors.Is(err, emaildomain.ErrDenied),
+		errors.Is(err, emaildomain.ErrNotAllowed):
+		return codes.InvalidArgument
 	default:
 		return codes.Internal
 	}
 }
+
+// statusError is status.Error with an ErrorInfo detail for errors clients
+// are expected to explain to the user, such as a rejected email domain.
+func statusError(code codes.Code, err error) error {
+	st := status.New(code, err.Error())
+
+	var rejected *emaildomain.RejectedError
+	if !errors.As(err, &rejected) {
+		return st.Err()
+	}
+
+	detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   rejected.Reason,
+		Domain:   errorDomain,
+		Metadata: map[string]string{"email_domain": rejected.Domain},
+	})
+	if detailErr != nil {
+		return st.Err()
+	}
+
+	return detailed.Err()
+}
@@ -13,12 +13,38 @@ import (
 
 type AuthHandler struct {
 	pb.UnimplementedAuthServiceServer
-	service service.AuthService
-	logger  *zap.Logger
+	service               service.AuthService
+	logger                *zap.Logger
+	exposeActivationToken bool
+	importOptions         service.ImportOptions
 }
 
-func NewAuthHandler(service service.AuthService, logger *zap.Logger) *AuthHandler {
-	return &AuthHandler{service: service, logger: logger}
+type Option func(*AuthHandler)
+
+// WithActivationTokenExposed returns the activation token from Register so
+// end-to-end tests can activate accounts without reading email. Never enable
+// it in production: the token proves ownership of the address.
+func WithActivationTokenExposed(expose bool) Option {
+	return func(h *AuthHandler) {
+		h.exposeActivationToken = expose
+	}
+}
+
+// WithImportOptions sets the batch size and pause ImportUsers creates users
+// with.
+func WithImportOptions(opts service.ImportOptions) Option {
+	return func(h *AuthHandler) {
+		h.importOptions = opts
+	}
+}
+
+func NewAuthHandler(service service.AuthService, logger *zap.Logger, opts ...Option) *AuthHandler {
+	h := &AuthHandler{service: service, logger: logger}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 func (h *AuthHandler) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.ResetPasswordResponse, error) {
@@ -95,7 +121,7 @@ func (h *AuthHandler) VerifyUser(ctx context.Context, req *pb.VerifyRequest) (*p
 }
 
 func (h *AuthHandler) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
-	user, err := h.service.Register(ctx, req.Email, req.Password)
+	user, err := h.service.Register(ctx, req.Email, req.Password, req.Locale)
 	if err != nil {
 		code := mapErrorCode(err)
 
@@ -107,16 +133,20 @@ func (h *AuthHandler) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 			zap.Error(err),
 		)
 
-		return nil, status.Error(code, err.Error())
+		return nil, statusError(code, err)
 	}
 
-	return &pb.RegisterResponse{
-		Id:              user.ID,
-		Email:           req.Email,
-		ActivationToken: user.ActivationToken,
-		CreatedAt:       user.CreatedAt.String(),
-		UpdatedAt:       user.UpdatedAt.String(),
-	}, nil
+	res := &pb.RegisterResponse{
+		Id:        user.ID,
+		Email:     req.Email,
+		CreatedAt: user.CreatedAt.String(),
+		UpdatedAt: user.UpdatedAt.String(),
+	}
+	if h.exposeActivationToken {
+		res.ActivationToken = user.ActivationToken
+	}
+
+	return res, nil
 }
 
 func (h *AuthHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
@@ -182,6 +212,7 @@ func (h *AuthHandler) ValidateUser(ctx context.Context, req *pb.ValidateRequest)
 	return &pb.ValidateResponse{
 		UserId:      res.UserId,
 		IsActivated: res.IsActivated,
+		Role:        res.Role,
 	}, nil
 }
 
@@ -205,9 +236,29 @@ func (h *AuthHandler) GetUserInfo(ctx context.Context, req *pb.UserInfoRequest)
 	return &pb.UserInfoResponse{
 		Email:       res.Email,
 		IsActivated: res.IsActivated,
+		Role:        res.Role,
+		Locale:      res.Locale,
 	}, nil
 }
 
+func (h *AuthHandler) UpdateLocale(ctx context.Context, req *pb.UpdateLocaleRequest) (*pb.UpdateLocaleResponse, error) {
+	if err := h.service.UpdateLocale(ctx, req.UserId, req.Locale); err != nil {
+		code := mapErrorCode(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"UpdateLocale failed",
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &pb.UpdateLocaleResponse{Success: true}, nil
+}
+
 func (h *AuthHandler) RefreshUser(ctx context.Context, req *pb.RefreshRequest) (*pb.RefreshResponse, error) {
 	res, err := h.service.Refresh(ctx, req)
 	if err != nil {
@@ -250,3 +301,44 @@ func (h *AuthHandler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.Lo
 		Success: res.Success,
 	}, nil
 }
+
+func (h *AuthHandler) ChangeUserRole(ctx context.Context, req *pb.ChangeUserRoleRequest) (*pb.ChangeUserRoleResponse, error) {
+	if err := h.service.ChangeUserRole(ctx, req.UserId, req.Role); err != nil {
+		code := mapErrorCode(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"Change user role failed",
+			zap.Int64("user_id", req.UserId),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return &pb.ChangeUserRoleResponse{
+		Success: true,
+	}, nil
+}
+
+func (h *AuthHandler) ImportUsers(stream pb.AuthService_ImportUsersServer) error {
+	ctx := stream.Context()
+
+	res, err := h.service.ImportUsers(ctx, stream.Recv, h.importOptions)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"Import users failed",
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return status.Error(code, err.Error())
+	}
+
+	return stream.SendAndClose(res)
+}
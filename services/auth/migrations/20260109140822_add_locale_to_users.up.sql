@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN locale VARCHAR(16) NOT NULL DEFAULT 'en';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE users
+-- DROP COLUMN locale;
+-- +goose StatementEnd
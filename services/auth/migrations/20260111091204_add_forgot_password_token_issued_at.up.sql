@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN forgot_password_token_issued_at TIMESTAMP;
+
+CREATE INDEX refresh_sessions_expires_at_idx ON refresh_sessions(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX refresh_sessions_expires_at_idx;
+-- ALTER TABLE users
+-- DROP COLUMN forgot_password_token_issued_at;
+-- +goose StatementEnd
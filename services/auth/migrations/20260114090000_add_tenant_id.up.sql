@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Emails are unique per storefront, not across the deployment.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_tenant_email_key ON users(tenant_id, email);
+
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE outbox DROP COLUMN tenant_id;
+--
+-- DROP INDEX IF EXISTS idx_users_tenant_email_key;
+-- ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+-- ALTER TABLE users DROP COLUMN tenant_id;
+-- +goose StatementEnd
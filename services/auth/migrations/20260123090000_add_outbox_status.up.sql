@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The worker polls by status alone. The old index on published_at also
+-- covers events that ran out of attempts, which a large backlog of failures
+-- made every poll step over.
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'pending';
+
+UPDATE outbox
+SET status = CASE
+    WHEN superseded_by IS NOT NULL THEN 'superseded'
+    WHEN published_at IS NOT NULL THEN 'published'
+    WHEN attempts >= 10 THEN 'failed' -- repository.MaxAttempts
+    ELSE 'pending'
+END;
+
+ALTER TABLE outbox
+ADD CONSTRAINT outbox_status_check
+CHECK (status IN ('pending', 'published', 'failed', 'superseded'));
+
+-- Only pending rows are indexed, so the index stays as small as the
+-- backlog however much history the table keeps.
+CREATE INDEX IF NOT EXISTS idx_outbox_pending
+    ON outbox(created_at, id)
+    WHERE status = 'pending';
+
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- CREATE INDEX IF NOT EXISTS idx_outbox_unpublished
+--     ON outbox(published_at, created_at)
+--     WHERE published_at IS NULL;
+-- DROP INDEX IF EXISTS idx_outbox_pending;
+-- ALTER TABLE outbox DROP CONSTRAINT outbox_status_check;
+-- ALTER TABLE outbox DROP COLUMN status;
+-- +goose StatementEnd
@@ -0,0 +1,223 @@
+// Package emaildomain decides which email domains may sign up: never
+// disposable or denied ones, and only a tenant's own domains for tenants
+// that restrict signups to them.
+package emaildomain
+
+import (
+	"bufio"
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+//go:embed disposable.txt
+var builtinDisposable string
+
+// Reasons a domain is rejected for. They are the ErrorInfo reason of the
+// gRPC status, so clients can tell the user what to do.
+const (
+	ReasonDisposable = "EMAIL_DOMAIN_DISPOSABLE"
+	ReasonDenied     = "EMAIL_DOMAIN_DENIED"
+	ReasonNotAllowed = "EMAIL_DOMAIN_NOT_ALLOWED"
+)
+
+var (
+	ErrDisposable = errors.New("disposable email addresses are not accepted")
+	ErrDenied     = errors.New("email domain is not accepted")
+	ErrNotAllowed = errors.New("email domain is not allowed for this shop")
+)
+
+// RejectedError is the error of a rejected domain. It unwraps to one of
+// ErrDisposable, ErrDenied and ErrNotAllowed.
+type RejectedError struct {
+	Domain string
+	Reason string
+	err    error
+}
+
+func (e *RejectedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *RejectedError) Unwrap() error {
+	return e.err
+}
+
+type Config struct {
+	// DisposableFile adds domains to the built-in disposable list, one per
+	// line with # comments. It is re-read every ReloadInterval, so the list
+	// can be updated without a restart.
+	DisposableFile string        `env:"EMAIL_DISPOSABLE_DOMAINS_FILE"`
+	ReloadInterval time.Duration `env:"EMAIL_DOMAINS_RELOAD_INTERVAL" env-default:"5m"`
+	Denied         []string      `env:"EMAIL_DENIED_DOMAINS" env-separator:","`
+	// Allowed restricts the signups of tenants to their domains, e.g.
+	// "acme:acme.com|acme.io,globex:globex.com". Tenants not listed accept
+	// any domain that is not disposable or denied.
+	Allowed map[string]string `env:"EMAIL_ALLOWED_DOMAINS"`
+}
+
+// LoadConfig reads the EMAIL_*_DOMAINS variables.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return Config{}, fmt.Errorf("error reading email domain config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+type Filter struct {
+	cfg        Config
+	denied     domainSet
+	allowed    map[string]domainSet
+	disposable atomic.Pointer[domainSet]
+	logger     *zap.Logger
+}
+
+// New loads the disposable list. A DisposableFile that cannot be read is an
+// error here, and only logged on later reloads.
+func New(cfg Config, logger *zap.Logger) (*Filter, error) {
+	f := &Filter{
+		cfg:     cfg,
+		denied:  newDomainSet(cfg.Denied),
+		allowed: make(map[string]domainSet, len(cfg.Allowed)),
+		logger:  logger,
+	}
+
+	for tenantID, domains := range cfg.Allowed {
+		f.allowed[tenantID] = newDomainSet(strings.Split(domains, "|"))
+	}
+
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Reload re-reads the disposable list.
+func (f *Filter) Reload() error {
+	domains := parseList(builtinDisposable)
+
+	if f.cfg.DisposableFile != "" {
+		data, err := os.ReadFile(f.cfg.DisposableFile)
+		if err != nil {
+			return fmt.Errorf("error reading disposable domains: %w", err)
+		}
+
+		domains = append(domains, parseList(string(data))...)
+	}
+
+	set := newDomainSet(domains)
+	f.disposable.Store(&set)
+
+	return nil
+}
+
+// Start reloads the disposable list every ReloadInterval until ctx is done.
+// Without a DisposableFile there is nothing to reload and it returns at once.
+func (f *Filter) Start(ctx context.Context) {
+	if f.cfg.DisposableFile == "" || f.cfg.ReloadInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(f.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// Keep the last good list; a broken file must not open signups
+			// to every disposable domain.
+			if err := f.Reload(); err != nil {
+				mylogger.Warn(ctx, f.logger, "Failed to reload disposable domains", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Check returns a *RejectedError if email may not sign up to tenantID.
+// Addresses without a domain are left to the other validation.
+func (f *Filter) Check(tenantID, email string) error {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return nil
+	}
+	domain := normalize(email[at+1:])
+
+	if allowed, ok := f.allowed[tenantID]; ok {
+		if !allowed.matches(domain) {
+			return &RejectedError{Domain: domain, Reason: ReasonNotAllowed, err: ErrNotAllowed}
+		}
+		return nil
+	}
+
+	if f.denied.matches(domain) {
+		return &RejectedError{Domain: domain, Reason: ReasonDenied, err: ErrDenied}
+	}
+
+	if f.disposable.Load().matches(domain) {
+		return &RejectedError{Domain: domain, Reason: ReasonDisposable, err: ErrDisposable}
+	}
+
+	return nil
+}
+
+// domainSet holds domains that match themselves and their subdomains.
+type domainSet map[string]struct{}
+
+func newDomainSet(domains []string) domainSet {
+	set := make(domainSet, len(domains))
+	for _, d := range domains {
+		if d = normalize(d); d != "" {
+			set[d] = struct{}{}
+		}
+	}
+
+	return set
+}
+
+func (s domainSet) matches(domain string) bool {
+	for domain != "" {
+		if _, ok := s[domain]; ok {
+			return true
+		}
+
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			return false
+		}
+		domain = domain[dot+1:]
+	}
+
+	return false
+}
+
+func normalize(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+func parseList(data string) []string {
+	var domains []string
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if line = strings.TrimSpace(line); line != "" {
+			domains = append(domains, line)
+		}
+	}
+
+	return domains
+}
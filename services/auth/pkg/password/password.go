@@ -0,0 +1,233 @@
+// Package password hashes and verifies user passwords. Hashes carry their
+// algorithm and parameters, so hashes made with older settings keep
+// verifying and can be upgraded when their owner next logs in.
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithms new hashes can be made with.
+const (
+	Bcrypt   = "bcrypt"
+	Argon2id = "argon2id"
+)
+
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Unusable is stored instead of a hash no supported algorithm can check,
+// e.g. one brought over from another system. No password matches it, so its
+// owner has to reset their password before they can log in.
+const Unusable = "!"
+
+// Hasher makes and checks password hashes.
+type Hasher interface {
+	Hash(password string) (string, error)
+	// Verify reports whether password matches hash, whichever supported
+	// algorithm made it.
+	Verify(hash, password string) (bool, error)
+	// NeedsRehash reports whether hash was made with another algorithm or
+	// other parameters than Hash uses now.
+	NeedsRehash(hash string) bool
+}
+
+// Config picks the algorithm new hashes are made with and its cost. The
+// argon2id defaults are the OWASP minimum of 19 MiB, 2 passes and 1 lane.
+type Config struct {
+	Algorithm  string `env:"PASSWORD_HASH" env-default:"bcrypt"`
+	BcryptCost int    `env:"BCRYPT_COST" env-default:"12"`
+	Argon2     Argon2Params
+}
+
+type Argon2Params struct {
+	MemoryKiB   uint32 `env:"ARGON2_MEMORY_KIB" env-default:"19456"`
+	Iterations  uint32 `env:"ARGON2_ITERATIONS" env-default:"2"`
+	Parallelism uint8  `env:"ARGON2_PARALLELISM" env-default:"1"`
+	SaltLength  uint32 `env:"ARGON2_SALT_LENGTH" env-default:"16"`
+	KeyLength   uint32 `env:"ARGON2_KEY_LENGTH" env-default:"32"`
+}
+
+// LoadConfig reads PASSWORD_HASH, BCRYPT_COST and the ARGON2_* variables.
+func LoadConfig() (Config, error) {
+	var cfg Config
+	if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return Config{}, fmt.Errorf("error reading password hash config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+type hasher struct {
+	cfg Config
+}
+
+func New(cfg Config) (Hasher, error) {
+	switch cfg.Algorithm {
+	case Bcrypt:
+		if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("BCRYPT_COST must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case Argon2id:
+		p := cfg.Argon2
+		if p.MemoryKiB == 0 || p.Iterations == 0 || p.Parallelism == 0 || p.SaltLength < 8 || p.KeyLength < 16 {
+			return nil, fmt.Errorf("invalid argon2id parameters %+v", p)
+		}
+	default:
+		return nil, fmt.Errorf("unknown PASSWORD_HASH %q: want %s or %s", cfg.Algorithm, Bcrypt, Argon2id)
+	}
+
+	return &hasher{cfg: cfg}, nil
+}
+
+func (h *hasher) Hash(password string) (string, error) {
+	if h.cfg.Algorithm == Argon2id {
+		return hashArgon2(password, h.cfg.Argon2)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (h *hasher) Verify(hash, password string) (bool, error) {
+	if hash == Unusable {
+		return false, nil
+	}
+
+	if isArgon2(hash) {
+		p, salt, key, err := parseArgon2(hash)
+		if err != nil {
+			return false, err
+		}
+
+		derived := argon2.IDKey([]byte(password), salt, p.Iterations, p.MemoryKiB, p.Parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(derived, key) == 1, nil
+	}
+
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil {
+		return false, ErrUnknownHash
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (h *hasher) NeedsRehash(hash string) bool {
+	if isArgon2(hash) {
+		if h.cfg.Algorithm != Argon2id {
+			return true
+		}
+
+		p, salt, key, err := parseArgon2(hash)
+		want := h.cfg.Argon2
+		return err != nil ||
+			p.MemoryKiB != want.MemoryKiB ||
+			p.Iterations != want.Iterations ||
+			p.Parallelism != want.Parallelism ||
+			uint32(len(salt)) != want.SaltLength ||
+			uint32(len(key)) != want.KeyLength
+	}
+
+	if h.cfg.Algorithm != Bcrypt {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err != nil || cost != h.cfg.BcryptCost
+}
+
+// Algorithm returns the algorithm that made hash, Bcrypt or Argon2id, or
+// ErrUnknownHash if hash is not a well-formed hash of either.
+func Algorithm(hash string) (string, error) {
+	if isArgon2(hash) {
+		// argon2.IDKey panics without a pass or a lane.
+		p, _, _, err := parseArgon2(hash)
+		if err != nil || p.Iterations == 0 || p.Parallelism == 0 {
+			return "", ErrUnknownHash
+		}
+		return Argon2id, nil
+	}
+
+	// bcrypt.Cost reads only the prefix; a whole bcrypt hash is 60 bytes.
+	if _, err := bcrypt.Cost([]byte(hash)); err != nil || len(hash) != 60 {
+		return "", ErrUnknownHash
+	}
+
+	return Bcrypt, nil
+}
+
+// argon2id hashes use the PHC string format of the reference
+// implementation: $argon2id$v=19$m=<KiB>,t=<passes>,p=<lanes>$<salt>$<key>.
+const argon2Prefix = "$argon2id$"
+
+func isArgon2(hash string) bool {
+	return strings.HasPrefix(hash, argon2Prefix)
+}
+
+func hashArgon2(password string, p Argon2Params) (string, error) {
+	salt := make([]byte, p.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("error generating salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, p.Iterations, p.MemoryKiB, p.Parallelism, p.KeyLength)
+
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2Prefix,
+		argon2.Version,
+		p.MemoryKiB,
+		p.Iterations,
+		p.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func parseArgon2(hash string) (Argon2Params, []byte, []byte, error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	var p Argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.MemoryKiB, &p.Iterations, &p.Parallelism); err != nil {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return Argon2Params{}, nil, nil, ErrUnknownHash
+	}
+
+	p.SaltLength = uint32(len(salt))
+	p.KeyLength = uint32(len(key))
+
+	return p, salt, key, nil
+}
@@ -11,12 +11,14 @@ import (
 )
 
 type Claims struct {
-	UserID      int64 `json:"user_id"`
-	IsActivated bool  `json:"is_activated"`
+	UserID      int64  `json:"user_id"`
+	IsActivated bool   `json:"is_activated"`
+	Role        string `json:"role,omitempty"`
+	TenantID    string `json:"tenant_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateTokens(userID int64, isActivated bool) (string, string, error) {
+func GenerateTokens(userID int64, isActivated bool, role, tenantID string) (string, string, error) {
 	accessSecret := os.Getenv("ACCESS_SECRET")
 	refreshSecret := os.Getenv("REFRESH_SECRET")
 
@@ -27,6 +29,8 @@ func GenerateTokens(userID int64, isActivated bool) (string, string, error) {
 	accessTokenClaims := Claims{
 		UserID:      userID,
 		IsActivated: isActivated,
+		Role:        role,
+		TenantID:    tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -41,7 +45,8 @@ func GenerateTokens(userID int64, isActivated bool) (string, string, error) {
 	}
 
 	refreshTokenClaims := Claims{
-		UserID: userID,
+		UserID:   userID,
+		TenantID: tenantID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(30 * 24 * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -1,23 +1,51 @@
 package validator
 
 import (
+	"context"
 	"errors"
 	"unicode"
+
+	"github.com/sakashimaa/go-pet-project/auth/internal/domain"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/emaildomain"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 )
 
 var (
 	ErrPasswordTooShort = errors.New("password must be at least 8 characters long")
 	ErrPasswordTooWeak  = errors.New("password must contain at least one digit and one letter")
+	ErrInvalidRole      = errors.New("unknown role")
+	ErrInvalidLocale    = errors.New("unsupported locale")
 )
 
 type Validator interface {
 	ValidatePassword(password string) error
+	ValidateRole(role string) error
+	ValidateLocale(locale string) error
+	// ValidateEmail checks that email may sign up to the tenant of ctx.
+	ValidateEmail(ctx context.Context, email string) error
+}
+
+type authValidator struct {
+	emailDomains *emaildomain.Filter
 }
 
-type authValidator struct{}
+type Option func(*authValidator)
 
-func NewValidator() Validator {
-	return &authValidator{}
+// WithEmailDomains rejects the signup domains filter rejects. Without it
+// every domain is accepted.
+func WithEmailDomains(filter *emaildomain.Filter) Option {
+	return func(a *authValidator) {
+		a.emailDomains = filter
+	}
+}
+
+func NewValidator(opts ...Option) Validator {
+	a := &authValidator{}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
 }
 
 func (a *authValidator) ValidatePassword(password string) error {
@@ -41,3 +69,29 @@ func (a *authValidator) ValidatePassword(password string) error {
 
 	return nil
 }
+
+func (a *authValidator) ValidateRole(role string) error {
+	switch role {
+	case domain.RoleUser, domain.RoleAdmin:
+		return nil
+	default:
+		return ErrInvalidRole
+	}
+}
+
+func (a *authValidator) ValidateLocale(locale string) error {
+	switch locale {
+	case domain.LocaleEN, domain.LocaleRU:
+		return nil
+	default:
+		return ErrInvalidLocale
+	}
+}
+
+func (a *authValidator) ValidateEmail(ctx context.Context, email string) error {
+	if a.emailDomains == nil {
+		return nil
+	}
+
+	return a.emailDomains.Check(tenant.FromContext(ctx), email)
+}
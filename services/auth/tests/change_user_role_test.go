@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/auth/internal/domain"
+	"github.com/sakashimaa/go-pet-project/auth/internal/repository"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/validator"
+)
+
+func (s *IntegrationTestSuite) TestChangeUserRole_Success() {
+	user, err := s.AuthService.Register(s.Ctx, "role@example.com", "supersecretqwerty123", "")
+	s.Require().NoError(err)
+	s.Require().Equal(domain.RoleUser, user.Role)
+
+	err = s.AuthService.ChangeUserRole(s.Ctx, user.ID, domain.RoleAdmin)
+	s.Require().NoError(err)
+
+	info, err := s.AuthService.GetUserInfo(s.Ctx, user.ID)
+	s.Require().NoError(err)
+	s.Require().Equal(domain.RoleAdmin, info.Role)
+
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM outbox
+		WHERE event_type = 'UserRoleChanged' AND aggregate_id = $1
+	`
+
+	err = s.DbPool.QueryRow(s.Ctx, query, fmt.Sprintf("%d", user.ID)).Scan(&count)
+	s.Require().NoError(err)
+	s.Require().Equal(1, count, "Role change should be published through the outbox")
+}
+
+func (s *IntegrationTestSuite) TestChangeUserRole_Failure() {
+	user, err := s.AuthService.Register(s.Ctx, "role-fail@example.com", "supersecretqwerty123", "")
+	s.Require().NoError(err)
+
+	err = s.AuthService.ChangeUserRole(s.Ctx, user.ID, "superuser")
+	s.Require().ErrorIs(err, validator.ErrInvalidRole)
+
+	err = s.AuthService.ChangeUserRole(s.Ctx, 99999, domain.RoleAdmin)
+	s.Require().ErrorIs(err, repository.ErrUserNotFound)
+}
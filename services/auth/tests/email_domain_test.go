@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/sakashimaa/go-pet-project/auth/internal/service"
+	"github.com/sakashimaa/go-pet-project/auth/internal/transport/grpc"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/emaildomain"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/password"
+	myValidator "github.com/sakashimaa/go-pet-project/auth/pkg/validator"
+	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	pb "github.com/sakashimaa/go-pet-project/proto/auth"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// filteredAuthService is the suite's service with signups filtered by cfg.
+func (s *IntegrationTestSuite) filteredAuthService(cfg emaildomain.Config) service.AuthService {
+	logger := zap.NewNop()
+
+	filter, err := emaildomain.New(cfg, logger)
+	s.Require().NoError(err)
+
+	hasher, err := password.New(password.Config{Algorithm: password.Bcrypt, BcryptCost: testBcryptCost})
+	s.Require().NoError(err)
+
+	outboxRepo := outboxRepository.NewOutboxRepository(s.DbPool, logger, "auth-service")
+	validator := myValidator.NewValidator(myValidator.WithEmailDomains(filter))
+
+	return service.NewAuthService(s.UserRepo, outboxRepo, s.TestProducer, logger, s.DbPool, validator, hasher)
+}
+
+func (s *IntegrationTestSuite) TestRegister_RejectsFilteredEmailDomains() {
+	list := filepath.Join(s.T().TempDir(), "disposable.txt")
+	s.Require().NoError(os.WriteFile(list, []byte("# updated list\nburner.test\n"), 0o600))
+
+	authService := s.filteredAuthService(emaildomain.Config{
+		DisposableFile: list,
+		Denied:         []string{"competitor.test"},
+		Allowed:        map[string]string{"acme": "acme.test|acme-corp.test"},
+	})
+
+	pass := "supersecret123qwe"
+	shop := s.Ctx
+	acme := tenant.WithID(s.Ctx, "acme")
+
+	cases := []struct {
+		ctx   context.Context
+		email string
+		err   error
+	}{
+		{shop, "user@mailinator.com", emaildomain.ErrDisposable},
+		{shop, "user@inbox.MAILINATOR.com", emaildomain.ErrDisposable},
+		{shop, "user@burner.test", emaildomain.ErrDisposable},
+		{shop, "user@competitor.test", emaildomain.ErrDenied},
+		{acme, "user@example.com", emaildomain.ErrNotAllowed},
+		{shop, "user@example.com", nil},
+		{acme, "user@acme.test", nil},
+		{acme, "user@eu.acme-corp.test", nil},
+	}
+
+	for _, tc := range cases {
+		_, err := authService.Register(tc.ctx, tc.email, pass, "")
+		if tc.err == nil {
+			s.Require().NoError(err, tc.email)
+			continue
+		}
+
+		s.Require().ErrorIs(err, tc.err, tc.email)
+	}
+
+	var users int
+	s.Require().NoError(s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM users").Scan(&users))
+	s.Require().Equal(3, users, "Rejected signups create no user")
+}
+
+func (s *IntegrationTestSuite) TestRegister_EmailDomainRejectionCarriesReason() {
+	authService := s.filteredAuthService(emaildomain.Config{})
+	handler := grpc.NewAuthHandler(authService, zap.NewNop())
+
+	_, err := handler.Register(s.Ctx, &pb.RegisterRequest{Email: "user@yopmail.com", Password: "supersecret123qwe"})
+	s.Require().Error(err)
+
+	st, ok := status.FromError(err)
+	s.Require().True(ok)
+	s.Require().Equal(codes.InvalidArgument, st.Code())
+	s.Require().Len(st.Details(), 1)
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	s.Require().True(ok)
+	s.Require().Equal(emaildomain.ReasonDisposable, info.GetReason())
+	s.Require().Equal("yopmail.com", info.GetMetadata()["email_domain"])
+}
@@ -10,6 +10,7 @@ func (s *IntegrationTestSuite) TestGetUserInfo_Success() {
 		s.Ctx,
 		email,
 		password,
+		"",
 	)
 
 	s.Require().NoError(err)
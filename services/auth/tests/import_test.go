@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sakashimaa/go-pet-project/auth/internal/service"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/password"
+	pb "github.com/sakashimaa/go-pet-project/proto/auth"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// importFrom returns a next func for ImportUsers that yields records.
+func importFrom(records ...*pb.ImportUserRecord) func() (*pb.ImportUserRecord, error) {
+	return func() (*pb.ImportUserRecord, error) {
+		if len(records) == 0 {
+			return nil, io.EOF
+		}
+
+		r := records[0]
+		records = records[1:]
+
+		return r, nil
+	}
+}
+
+func (s *IntegrationTestSuite) TestImportUsers_KeepsKnownHashes() {
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("old-password-123"), bcrypt.MinCost)
+	s.Require().NoError(err)
+
+	_, err = s.AuthService.Register(s.Ctx, "taken@example.com", "supersecret123qwe", "")
+	s.Require().NoError(err)
+
+	res, err := s.AuthService.ImportUsers(s.Ctx, importFrom(
+		&pb.ImportUserRecord{Email: "kept@example.com", PasswordHash: string(bcryptHash), IsActivated: true, Locale: "ru"},
+		&pb.ImportUserRecord{Email: "md5@example.com", PasswordHash: "5f4dcc3b5aa765d61d8327deb882cf99"},
+		&pb.ImportUserRecord{Email: "taken@example.com", PasswordHash: string(bcryptHash)},
+		&pb.ImportUserRecord{Email: "not-an-email", PasswordHash: string(bcryptHash)},
+	), service.ImportOptions{BatchSize: 2})
+	s.Require().NoError(err)
+
+	s.Require().EqualValues(2, res.Imported)
+	s.Require().EqualValues(1, res.ResetRequired)
+	s.Require().EqualValues(1, res.Skipped, "An email already registered is skipped")
+	s.Require().EqualValues(1, res.Failed)
+	s.Require().Len(res.Failures, 1)
+	s.Require().EqualValues(3, res.Failures[0].Index)
+
+	_, _, err = s.AuthService.Login(s.Ctx, "kept@example.com", "old-password-123")
+	s.Require().NoError(err, "A bcrypt hash logs in with the old password")
+
+	user, err := s.UserRepo.GetByEmail(s.Ctx, "kept@example.com")
+	s.Require().NoError(err)
+	s.Require().True(user.IsActivated)
+	s.Require().Equal("ru", user.Locale)
+
+	s.Require().Equal(password.Unusable, s.passwordHash("md5@example.com"))
+	_, _, err = s.AuthService.Login(s.Ctx, "md5@example.com", "password")
+	s.Require().Error(err, "An unknown hash forces a password reset")
+
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM outbox
+		WHERE event_type = 'UserRegistered' AND aggregate_id = $1 AND payload->'payload'->>'imported' = 'true'
+	`
+
+	err = s.DbPool.QueryRow(s.Ctx, query, fmt.Sprintf("%d", user.ID)).Scan(&count)
+	s.Require().NoError(err)
+	s.Require().Equal(1, count, "Imported users are announced like registered ones")
+}
+
+func (s *IntegrationTestSuite) TestImportUsers_ResendSkipsImported() {
+	records := func() func() (*pb.ImportUserRecord, error) {
+		return importFrom(
+			&pb.ImportUserRecord{Email: "first@example.com"},
+			&pb.ImportUserRecord{Email: "second@example.com"},
+			&pb.ImportUserRecord{Email: "third@example.com"},
+		)
+	}
+
+	res, err := s.AuthService.ImportUsers(s.Ctx, records(), service.ImportOptions{BatchSize: 1})
+	s.Require().NoError(err)
+	s.Require().EqualValues(3, res.Imported)
+
+	res, err = s.AuthService.ImportUsers(s.Ctx, records(), service.ImportOptions{BatchSize: 1})
+	s.Require().NoError(err)
+	s.Require().EqualValues(0, res.Imported)
+	s.Require().EqualValues(3, res.Skipped)
+
+	user, err := s.UserRepo.GetByEmail(s.Ctx, "second@example.com")
+	s.Require().NoError(err)
+	s.Require().False(user.IsActivated)
+}
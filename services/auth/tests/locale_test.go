@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/auth/internal/domain"
+	"github.com/sakashimaa/go-pet-project/auth/internal/repository"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/validator"
+)
+
+func (s *IntegrationTestSuite) TestRegister_DefaultLocale() {
+	user, err := s.AuthService.Register(s.Ctx, "locale-default@example.com", "supersecretqwerty123", "")
+	s.Require().NoError(err)
+
+	info, err := s.AuthService.GetUserInfo(s.Ctx, user.ID)
+	s.Require().NoError(err)
+	s.Require().Equal(domain.DefaultLocale, info.Locale)
+}
+
+func (s *IntegrationTestSuite) TestRegister_LocaleInEvent() {
+	user, err := s.AuthService.Register(s.Ctx, "locale-ru@example.com", "supersecretqwerty123", domain.LocaleRU)
+	s.Require().NoError(err)
+
+	var payload []byte
+	query := `
+		SELECT payload
+		FROM outbox
+		WHERE event_type = 'UserRegistered' AND aggregate_id = $1
+	`
+	s.Require().NoError(s.DbPool.QueryRow(s.Ctx, query, fmt.Sprintf("%d", user.ID)).Scan(&payload))
+
+	var envelope struct {
+		Payload struct {
+			Locale string `json:"locale"`
+		} `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &envelope))
+	s.Require().Equal(domain.LocaleRU, envelope.Payload.Locale)
+}
+
+func (s *IntegrationTestSuite) TestUpdateLocale() {
+	user, err := s.AuthService.Register(s.Ctx, "locale-update@example.com", "supersecretqwerty123", "")
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.AuthService.UpdateLocale(s.Ctx, user.ID, domain.LocaleRU))
+
+	info, err := s.AuthService.GetUserInfo(s.Ctx, user.ID)
+	s.Require().NoError(err)
+	s.Require().Equal(domain.LocaleRU, info.Locale)
+
+	err = s.AuthService.UpdateLocale(s.Ctx, user.ID, "xx")
+	s.Require().ErrorIs(err, validator.ErrInvalidLocale)
+
+	err = s.AuthService.UpdateLocale(s.Ctx, 99999, domain.LocaleEN)
+	s.Require().ErrorIs(err, repository.ErrUserNotFound)
+
+	_, err = s.AuthService.Register(s.Ctx, "locale-bad@example.com", "supersecretqwerty123", "xx")
+	s.Require().ErrorIs(err, validator.ErrInvalidLocale)
+}
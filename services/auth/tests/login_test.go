@@ -15,6 +15,7 @@ func (s *IntegrationTestSuite) TestLogin_Failure() {
 		s.Ctx,
 		email,
 		password,
+		"",
 	)
 
 	s.Require().NoError(err)
@@ -59,6 +60,7 @@ func (s *IntegrationTestSuite) TestLogin_Success() {
 		s.Ctx,
 		email,
 		password,
+		"",
 	)
 
 	s.Require().NoError(err)
@@ -74,3 +76,22 @@ func (s *IntegrationTestSuite) TestLogin_Success() {
 
 	tests.ValidateTokens(s.T(), access, refresh)
 }
+
+func (s *IntegrationTestSuite) TestValidate_InvalidTokenIsNotLogged() {
+	email := "test@example.com"
+	password := "supersecret123qwe"
+
+	_, err := s.AuthService.Register(s.Ctx, email, password, "")
+	s.Require().NoError(err)
+
+	access, _, err := s.AuthService.Login(s.Ctx, email, password)
+	s.Require().NoError(err)
+
+	// A broken signature makes Validate log the token it rejected; the suite's
+	// logger fails the test if it does so unmasked.
+	tampered := access[:len(access)-2] + "xx"
+	s.ForbidInLogs(tampered)
+
+	_, err = s.AuthService.Validate(s.Ctx, tampered)
+	s.Require().Error(err)
+}
@@ -16,6 +16,7 @@ func (s *IntegrationTestSuite) TestLogoutUser_Success() {
 		s.Ctx,
 		email,
 		password,
+		"",
 	)
 
 	s.Require().NoError(err)
@@ -25,6 +26,7 @@ func (s *IntegrationTestSuite) TestLogoutUser_Success() {
 	s.Require().NoError(err)
 	s.Require().NotEmpty(access)
 	s.Require().NotEmpty(refresh)
+	s.ForbidInLogs(refresh)
 
 	tests.ValidateTokens(s.T(), access, refresh)
 
@@ -65,7 +67,7 @@ func (s *IntegrationTestSuite) TestLogoutUser_Failure() {
 	email := "test@example.com"
 	password := "qwertysuper123"
 
-	registerRes, err := s.AuthService.Register(s.Ctx, email, password)
+	registerRes, err := s.AuthService.Register(s.Ctx, email, password, "")
 
 	s.Require().NoError(err)
 	s.Require().NotNil(registerRes)
@@ -115,7 +117,7 @@ func (s *IntegrationTestSuite) TestMultiDevice_Success() {
 	email := "test@example.com"
 	password := "qwertysuper123"
 
-	res, err := s.AuthService.Register(s.Ctx, email, password)
+	res, err := s.AuthService.Register(s.Ctx, email, password, "")
 
 	s.Require().NoError(err)
 	s.Require().NotNil(res)
@@ -0,0 +1,64 @@
+package tests
+
+import (
+	"strings"
+
+	"github.com/sakashimaa/go-pet-project/auth/internal/service"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/password"
+	myValidator "github.com/sakashimaa/go-pet-project/auth/pkg/validator"
+	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"go.uber.org/zap"
+)
+
+func (s *IntegrationTestSuite) passwordHash(email string) string {
+	var hash string
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT password_hash FROM users WHERE email = $1", email).Scan(&hash)
+	s.Require().NoError(err)
+
+	return hash
+}
+
+func (s *IntegrationTestSuite) TestLogin_RehashesWhenParametersChange() {
+	email := "test@example.com"
+	pass := "supersecret123qwe"
+
+	_, err := s.AuthService.Register(s.Ctx, email, pass, "")
+	s.Require().NoError(err)
+
+	bcryptHash := s.passwordHash(email)
+	s.Require().True(strings.HasPrefix(bcryptHash, "$2"), "The suite registers with bcrypt")
+
+	hasher, err := password.New(password.Config{
+		Algorithm: password.Argon2id,
+		Argon2: password.Argon2Params{
+			MemoryKiB:   1024,
+			Iterations:  1,
+			Parallelism: 1,
+			SaltLength:  16,
+			KeyLength:   32,
+		},
+	})
+	s.Require().NoError(err)
+
+	logger := zap.NewNop()
+	outboxRepo := outboxRepository.NewOutboxRepository(s.DbPool, logger, "auth-service")
+	argonService := service.NewAuthService(s.UserRepo, outboxRepo, s.TestProducer, logger, s.DbPool, myValidator.NewValidator(), hasher)
+
+	_, _, err = argonService.Login(s.Ctx, email, "wrongpass123")
+	s.Require().Error(err)
+	s.Require().Equal(bcryptHash, s.passwordHash(email), "A failed login does not rehash")
+
+	_, _, err = argonService.Login(s.Ctx, email, pass)
+	s.Require().NoError(err, "The bcrypt hash still verifies")
+
+	argonHash := s.passwordHash(email)
+	s.Require().True(strings.HasPrefix(argonHash, "$argon2id$v=19$m=1024,t=1,p=1$"), argonHash)
+
+	_, _, err = argonService.Login(s.Ctx, email, pass)
+	s.Require().NoError(err)
+	s.Require().Equal(argonHash, s.passwordHash(email), "An up to date hash is kept")
+
+	_, _, err = s.AuthService.Login(s.Ctx, email, pass)
+	s.Require().NoError(err, "Rolling back to bcrypt still accepts argon2id hashes")
+	s.Require().True(strings.HasPrefix(s.passwordHash(email), "$2"))
+}
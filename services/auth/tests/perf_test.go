@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/auth/internal/domain"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/utils"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+)
+
+// Every authenticated gateway request validates a token, so this stays in
+// the microseconds.
+const validateTokenBudget = 200 * time.Microsecond
+
+func (s *IntegrationTestSuite) TestPerf_ValidateToken() {
+	s.T().Setenv("ACCESS_SECRET", "perf-access-secret")
+	s.T().Setenv("REFRESH_SECRET", "perf-refresh-secret")
+
+	access, _, err := utils.GenerateTokens(1, true, domain.RoleUser, tenant.DefaultID)
+	s.Require().NoError(err)
+
+	validate := func() error {
+		_, err := s.AuthService.Validate(s.Ctx, access)
+		return err
+	}
+
+	s.RequireP95("ValidateToken", validateTokenBudget, 5_000, validate)
+	s.Benchmark("ValidateToken", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if err := validate(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
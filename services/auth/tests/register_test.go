@@ -3,9 +3,6 @@ package tests
 import (
 	"fmt"
 	"time"
-
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
 func (s *IntegrationTestSuite) TestRegisterUser_Success() {
@@ -16,6 +13,7 @@ func (s *IntegrationTestSuite) TestRegisterUser_Success() {
 		s.Ctx,
 		email,
 		password,
+		"",
 	)
 
 	s.Require().NoError(err)
@@ -69,6 +67,7 @@ func (s *IntegrationTestSuite) TestRegisterUser_DuplicateEmail_Fails() {
 		s.Ctx,
 		email,
 		password,
+		"",
 	)
 
 	s.Require().NoError(err)
@@ -78,6 +77,7 @@ func (s *IntegrationTestSuite) TestRegisterUser_DuplicateEmail_Fails() {
 		s.Ctx,
 		email,
 		password,
+		"",
 	)
 
 	s.Require().Error(err)
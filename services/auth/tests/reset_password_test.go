@@ -9,7 +9,7 @@ func (s *IntegrationTestSuite) TestResetPassword_Success() {
 	email := "test@example.com"
 	password := "secretpass123qwe"
 
-	res, err := s.AuthService.Register(s.Ctx, email, password)
+	res, err := s.AuthService.Register(s.Ctx, email, password, "")
 
 	s.Require().NoError(err)
 	s.Require().NotNil(res)
@@ -33,6 +33,8 @@ func (s *IntegrationTestSuite) TestResetPassword_Success() {
 	}
 
 	newPassword := "recoverypass123"
+	s.ForbidInLogs(token, password, newPassword)
+
 	resetRes, err := s.AuthService.ResetPassword(
 		s.Ctx,
 		&pb.ResetPasswordRequest{Token: token, Password: newPassword},
@@ -63,17 +65,12 @@ func (s *IntegrationTestSuite) TestResetPassword_Failure() {
 	email := "test@example.com"
 	password := "secretpass123qwe"
 
-	res, err := s.AuthService.Register(s.Ctx, email, password)
+	res, err := s.AuthService.Register(s.Ctx, email, password, "")
 
 	s.Require().NoError(err)
 	s.Require().NotNil(res)
 
-	forgotRes, err := s.AuthService.ForgotPassword(s.Ctx, &pb.ForgotPasswordRequest{Email: "notexisting@email.com"})
-
-	s.Require().Error(err)
-	s.Require().Nil(forgotRes)
-
-	forgotRes, err = s.AuthService.ForgotPassword(s.Ctx, &pb.ForgotPasswordRequest{Email: email})
+	forgotRes, err := s.AuthService.ForgotPassword(s.Ctx, &pb.ForgotPasswordRequest{Email: email})
 
 	s.Require().NoError(err)
 	s.Require().NotNil(forgotRes)
@@ -92,7 +89,7 @@ func (s *IntegrationTestSuite) TestResetPasswordToken_Reuse_Failure() {
 	email := "test@example.com"
 	password := "secretpass123A1"
 
-	res, err := s.AuthService.Register(s.Ctx, email, password)
+	res, err := s.AuthService.Register(s.Ctx, email, password, "")
 
 	s.Require().NoError(err)
 	s.Require().NotNil(res)
@@ -143,3 +140,30 @@ func (s *IntegrationTestSuite) TestResetPasswordToken_Reuse_Failure() {
 	s.Require().Error(err)
 	s.Require().Nil(failedRes)
 }
+
+func (s *IntegrationTestSuite) TestForgotPassword_UnknownEmail_LooksLikeSuccess() {
+	email := "test@example.com"
+
+	res, err := s.AuthService.Register(s.Ctx, email, "secretpass123qwe", "")
+	s.Require().NoError(err)
+	s.Require().NotNil(res)
+
+	known, err := s.AuthService.ForgotPassword(s.Ctx, &pb.ForgotPasswordRequest{Email: email})
+	s.Require().NoError(err)
+
+	unknown, err := s.AuthService.ForgotPassword(s.Ctx, &pb.ForgotPasswordRequest{Email: "notexisting@email.com"})
+	s.Require().NoError(err)
+	s.Require().Equal(known.Success, unknown.Success)
+	s.Require().Equal(known.Message, unknown.Message)
+
+	var count int
+	query := `
+		SELECT COUNT(*)
+		FROM outbox
+		WHERE event_type = 'UserForgotPassword' AND aggregate_id = $1
+	`
+
+	err = s.DbPool.QueryRow(s.Ctx, query, "notexisting@email.com").Scan(&count)
+	s.Require().NoError(err)
+	s.Require().Zero(count, "Unknown emails must not get a reset email")
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"time"
+
+	pb "github.com/sakashimaa/go-pet-project/proto/auth"
+)
+
+func (s *IntegrationTestSuite) TestPurgeExpiredSessions() {
+	user, err := s.AuthService.Register(s.Ctx, "test@example.com", "secretpass123qwe", "")
+	s.Require().NoError(err)
+
+	_, _, err = s.AuthService.Login(s.Ctx, "test@example.com", "secretpass123qwe")
+	s.Require().NoError(err)
+
+	_, err = s.DbPool.Exec(
+		s.Ctx,
+		`INSERT INTO refresh_sessions (user_id, token, expires_at) VALUES ($1, 'expired-token', NOW() - INTERVAL '2 days')`,
+		user.ID,
+	)
+	s.Require().NoError(err)
+
+	n, err := s.UserRepo.PurgeExpiredSessions(s.Ctx, time.Now().Add(-24*time.Hour))
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, n)
+
+	var left int
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM refresh_sessions WHERE user_id = $1", user.ID).Scan(&left)
+	s.Require().NoError(err)
+	s.Require().Equal(1, left, "The live session must survive")
+}
+
+func (s *IntegrationTestSuite) TestClearForgotPasswordTokens() {
+	email := "test@example.com"
+
+	_, err := s.AuthService.Register(s.Ctx, email, "secretpass123qwe", "")
+	s.Require().NoError(err)
+
+	_, err = s.AuthService.ForgotPassword(s.Ctx, &pb.ForgotPasswordRequest{Email: email})
+	s.Require().NoError(err)
+
+	n, err := s.UserRepo.ClearForgotPasswordTokens(s.Ctx, time.Now().Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Zero(n, "A fresh token must be kept")
+
+	_, err = s.DbPool.Exec(
+		s.Ctx,
+		`UPDATE users SET forgot_password_token_issued_at = NOW() - INTERVAL '2 hours' WHERE email = $1`,
+		email,
+	)
+	s.Require().NoError(err)
+
+	n, err = s.UserRepo.ClearForgotPasswordTokens(s.Ctx, time.Now().Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, n)
+
+	var token *string
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT forgot_password_token FROM users WHERE email = $1", email).Scan(&token)
+	s.Require().NoError(err)
+	s.Require().Nil(token)
+}
+
+func (s *IntegrationTestSuite) TestClearActivationTokens() {
+	email := "test@example.com"
+
+	_, err := s.AuthService.Register(s.Ctx, email, "secretpass123qwe", "")
+	s.Require().NoError(err)
+
+	n, err := s.UserRepo.ClearActivationTokens(s.Ctx, time.Now().Add(-time.Hour))
+	s.Require().NoError(err)
+	s.Require().Zero(n, "A fresh token must be kept")
+
+	n, err = s.UserRepo.ClearActivationTokens(s.Ctx, time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+	s.Require().EqualValues(1, n)
+}
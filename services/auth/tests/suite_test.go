@@ -6,19 +6,23 @@ import (
 
 	"github.com/sakashimaa/go-pet-project/auth/internal/repository"
 	"github.com/sakashimaa/go-pet-project/auth/internal/service"
+	"github.com/sakashimaa/go-pet-project/auth/pkg/password"
 	myValidator "github.com/sakashimaa/go-pet-project/auth/pkg/validator"
 	"github.com/sakashimaa/go-pet-project/pkg/kafka"
 	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
 	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
 	"github.com/sakashimaa/go-pet-project/pkg/testsuite"
 	"github.com/stretchr/testify/suite"
-	"go.uber.org/zap"
 )
 
+// testBcryptCost is the bcrypt minimum, to keep registering users cheap.
+const testBcryptCost = 4
+
 type IntegrationTestSuite struct {
 	testsuite.BaseSuite
 
 	AuthService     service.AuthService
+	UserRepo        repository.UserRepository
 	TestProducer    kafka.Producer
 	OutboxProcessor *worker.OutboxProcessor
 	workerCancel    context.CancelFunc
@@ -35,17 +39,22 @@ func (s *IntegrationTestSuite) TearDownSuite() {
 func (s *IntegrationTestSuite) SetupTest() {
 	s.BaseSuite.TruncateTable("users")
 
-	logger := zap.NewNop()
+	// Fails the test if a raw token or email reaches the log.
+	logger := s.Logger()
 	userRepo := repository.NewUserRepository(s.DbPool, logger)
-	outboxRepo := outboxRepository.NewOutboxRepository(s.DbPool, logger)
+	s.UserRepo = userRepo
+	outboxRepo := outboxRepository.NewOutboxRepository(s.DbPool, logger, "auth-service")
 
 	var err error
-	s.TestProducer, err = kafka.NewProducer(s.KafkaBrokers)
+	s.TestProducer, err = kafka.NewProducer(kafka.Config{Brokers: s.KafkaBrokers})
 	s.Require().NoError(err, "failed to create kafka producer")
 
 	validator := myValidator.NewValidator()
 
-	s.AuthService = service.NewAuthService(userRepo, outboxRepo, s.TestProducer, logger, s.DbPool, validator)
+	hasher, err := password.New(password.Config{Algorithm: password.Bcrypt, BcryptCost: testBcryptCost})
+	s.Require().NoError(err)
+
+	s.AuthService = service.NewAuthService(userRepo, outboxRepo, s.TestProducer, logger, s.DbPool, validator, hasher)
 
 	s.OutboxProcessor = worker.NewOutboxProcessor(s.DbPool, outboxRepo, s.TestProducer, logger)
 
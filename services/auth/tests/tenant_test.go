@@ -0,0 +1,48 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+)
+
+func (s *IntegrationTestSuite) TestTenant_SameEmailInTwoTenants() {
+	email := "test@example.com"
+	password := "supersecret123qwe"
+
+	shopA := tenant.WithID(s.Ctx, "shop-a")
+	shopB := tenant.WithID(s.Ctx, "shop-b")
+
+	userA, err := s.AuthService.Register(shopA, email, password, "")
+	s.Require().NoError(err)
+
+	userB, err := s.AuthService.Register(shopB, email, "othersecret123qwe", "")
+	s.Require().NoError(err)
+	s.Require().NotEqual(userA.ID, userB.ID)
+
+	_, err = s.AuthService.Register(shopA, email, password, "")
+	s.Require().Error(err, "Email stays unique within a tenant")
+
+	_, _, err = s.AuthService.Login(shopB, email, password)
+	s.Require().Error(err, "Password of the other storefront's account must not work")
+}
+
+func (s *IntegrationTestSuite) TestTenant_TokenRejectedInOtherTenant() {
+	email := "test@example.com"
+	password := "supersecret123qwe"
+
+	shopA := tenant.WithID(s.Ctx, "shop-a")
+
+	_, err := s.AuthService.Register(shopA, email, password, "")
+	s.Require().NoError(err)
+
+	access, _, err := s.AuthService.Login(shopA, email, password)
+	s.Require().NoError(err)
+
+	_, err = s.AuthService.Validate(shopA, access)
+	s.Require().NoError(err)
+
+	_, err = s.AuthService.Validate(tenant.WithID(s.Ctx, "shop-b"), access)
+	s.Require().Error(err)
+
+	_, _, err = s.AuthService.Login(s.Ctx, email, password)
+	s.Require().Error(err, "User does not exist in the default tenant")
+}
@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log"
+	netHttp "net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
@@ -10,14 +14,97 @@ import (
 	"github.com/gofiber/contrib/otelfiber"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/ilyakaznacheev/cleanenv"
 	"github.com/joho/godotenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/sakashimaa/go-pet-project/gateway/internal/pkg/client"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/pkg/storage"
 	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http"
 	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/handler"
+	"github.com/sakashimaa/go-pet-project/gateway/middleware"
+	"github.com/sakashimaa/go-pet-project/gateway/web"
+	"github.com/sakashimaa/go-pet-project/pkg/app"
 	"github.com/sakashimaa/go-pet-project/pkg/config"
+	"github.com/sakashimaa/go-pet-project/pkg/debug"
+	"github.com/sakashimaa/go-pet-project/pkg/redis"
 	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	statusPb "github.com/sakashimaa/go-pet-project/proto/status"
 )
 
+// authThrottleConfig limits attempts at the auth endpoints that can be
+// brute-forced, on top of the global rate limiter.
+type authThrottleConfig struct {
+	Enabled                bool          `env:"AUTH_THROTTLE_ENABLED" env-default:"true"`
+	Window                 time.Duration `env:"AUTH_THROTTLE_WINDOW" env-default:"15m"`
+	ForgotPasswordPerIP    int64         `env:"FORGOT_PASSWORD_MAX_PER_IP" env-default:"10"`
+	ForgotPasswordPerEmail int64         `env:"FORGOT_PASSWORD_MAX_PER_EMAIL" env-default:"3"`
+	ActivatePerIP          int64         `env:"ACTIVATE_MAX_PER_IP" env-default:"20"`
+}
+
+// botGuardConfig flags clients that read products in bursts, the way
+// scrapers walk the listing, on top of the global rate limiter.
+// BOT_GUARD_CHALLENGE is "pow", "captcha" or "block".
+type botGuardConfig struct {
+	Enabled       bool              `env:"BOT_GUARD_ENABLED" env-default:"false"`
+	BurstWindow   time.Duration     `env:"BOT_GUARD_BURST_WINDOW" env-default:"10s"`
+	BurstMax      int64             `env:"BOT_GUARD_BURST_MAX" env-default:"30"`
+	SuspectFor    time.Duration     `env:"BOT_GUARD_SUSPECT_FOR" env-default:"10m"`
+	Challenge     string            `env:"BOT_GUARD_CHALLENGE" env-default:"pow"`
+	PoWSecret     string            `env:"BOT_GUARD_POW_SECRET" env-default:""`
+	PoWDifficulty int               `env:"BOT_GUARD_POW_DIFFICULTY" env-default:"20"`
+	CaptchaURL    string            `env:"BOT_GUARD_CAPTCHA_VERIFY_URL" env-default:"https://challenges.cloudflare.com/turnstile/v0/siteverify"`
+	CaptchaSecret string            `env:"BOT_GUARD_CAPTCHA_SECRET" env-default:""`
+	CaptchaSite   string            `env:"BOT_GUARD_CAPTCHA_SITE_KEY" env-default:""`
+	PartnerKeys   map[string]string `env:"BOT_GUARD_PARTNER_KEYS" env-default:""`
+}
+
+func (c botGuardConfig) challenge(rdb goredis.UniversalClient) (middleware.Challenge, error) {
+	switch c.Challenge {
+	case "pow":
+		if len(c.PoWSecret) < 32 {
+			return nil, errors.New("BOT_GUARD_POW_SECRET must be at least 32 characters")
+		}
+		return middleware.NewProofOfWork(rdb, c.PoWSecret, c.PoWDifficulty), nil
+	case "captcha":
+		if c.CaptchaSecret == "" || c.CaptchaSite == "" {
+			return nil, errors.New("BOT_GUARD_CAPTCHA_SECRET and BOT_GUARD_CAPTCHA_SITE_KEY are required")
+		}
+		return middleware.NewCaptcha(c.CaptchaURL, c.CaptchaSecret, c.CaptchaSite), nil
+	case "block":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unknown BOT_GUARD_CHALLENGE %q", c.Challenge)
+	}
+}
+
+// accessLogConfig controls the per-request log. Bodies are redacted before
+// they are written but still cost space, so only a sample carries them.
+type accessLogConfig struct {
+	BodySampleRate float64 `env:"ACCESS_LOG_BODY_SAMPLE_RATE" env-default:"0"`
+	MaxBodyBytes   int     `env:"ACCESS_LOG_MAX_BODY_BYTES" env-default:"2048"`
+	Buffer         int     `env:"ACCESS_LOG_BUFFER" env-default:"1024"`
+}
+
+// tenantConfig maps storefront domains onto tenants. Hosts without a
+// mapping pick their tenant with TENANT_HEADER.
+type tenantConfig struct {
+	Domains map[string]string `env:"TENANT_DOMAINS" env-default:""`
+	Header  string            `env:"TENANT_HEADER" env-default:"X-Tenant-ID"`
+	IDs     []string          `env:"TENANT_IDS" env-default:""`
+}
+
+// staticConfig lets the gateway host the front-end itself, so a small
+// deployment needs no separate web server.
+type staticConfig struct {
+	Enabled bool `env:"STATIC_ENABLED" env-default:"false"`
+	// Dir serves a build from disk instead of the one embedded at compile
+	// time, e.g. while working on the front-end.
+	Dir             string `env:"STATIC_DIR" env-default:""`
+	ImmutablePrefix string `env:"STATIC_IMMUTABLE_PREFIX" env-default:"assets/"`
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Printf(".env not found: %v\n", err)
@@ -31,18 +118,87 @@ func main() {
 		log.Fatalf("Failed to init trace: %v", err)
 	}
 
+	loggerCfg, err := config.LoadLoggerConfig()
+	if err != nil {
+		log.Fatalf("failed to load logger config: %v", err)
+	}
+
+	logger, logLevel, err := config.NewLeveledLogger(loggerCfg)
+	if err != nil {
+		log.Fatalf("Error creating logger: %v", err)
+	}
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Fatalf("error syncing logger: %v", err)
+		}
+	}()
+
+	runner := app.NewRunner(logger)
+	runner.Add(app.Component{Name: "telemetry", Stop: tp.Shutdown})
+
+	reloadConfig, err := config.LoadReloadConfig()
+	if err != nil {
+		log.Fatalf("Error loading config reload settings: %v", err)
+	}
+
+	configWatcher := config.NewWatcher(reloadConfig, logger)
+	if err := configWatcher.Load(ctx); err != nil {
+		log.Fatalf("Error loading tunables: %v", err)
+	}
+	configWatcher.Subscribe(config.SetLogLevel(logLevel))
+	configWatcher.Subscribe(func(t config.Tunables) {
+		handler.SetBreakerThresholds(t.Breaker.MinRequests, t.Breaker.FailureRatio)
+	})
+	runner.Add(app.Component{Name: "config watcher", Start: app.Loop(configWatcher.Start)})
+
 	port := utils.ParseWithFallback("PORT", ":3000")
 	authUrl := utils.ParseWithFallback("AUTH_RPC_URL", "localhost:50051")
 	productUrl := utils.ParseWithFallback("PRODUCT_RPC_URL", "localhost:50052")
 	orderUrl := utils.ParseWithFallback("ORDER_RPC_URL", "localhost:50053")
+	paymentUrl := utils.ParseWithFallback("PAYMENT_RPC_URL", "localhost:50054")
+	analyticsUrl := utils.ParseWithFallback("ANALYTICS_RPC_URL", "localhost:50055")
+	notificationUrl := utils.ParseWithFallback("NOTIFICATION_RPC_URL", "localhost:50056")
+
+	httpApp := fiber.New()
+
+	httpApp.Use(otelfiber.Middleware())
+
+	var accessLog accessLogConfig
+	if err := cleanenv.ReadEnv(&accessLog); err != nil {
+		log.Fatalf("Error loading access log config: %v", err)
+	}
+
+	accessLogger := middleware.NewAccessLogger(middleware.AccessLogConfig{
+		Logger:         logger.Named("access"),
+		BodySampleRate: accessLog.BodySampleRate,
+		MaxBodyBytes:   accessLog.MaxBodyBytes,
+		Buffer:         accessLog.Buffer,
+	})
+	// Added before the HTTP server so it is stopped after it and writes the
+	// last requests.
+	runner.Add(app.Component{Name: "access log", Start: accessLogger.Start})
+
+	httpApp.Use(accessLogger.Handler())
+
+	httpApp.Use(middleware.NewLocaleMiddleware())
 
-	app := fiber.New()
+	var tenants tenantConfig
+	if err := cleanenv.ReadEnv(&tenants); err != nil {
+		log.Fatalf("Error loading tenant config: %v", err)
+	}
+
+	tenantMiddlewareConfig := middleware.TenantConfig{
+		Domains: tenants.Domains,
+		Header:  tenants.Header,
+		Allowed: tenants.IDs,
+	}
+	if err := tenantMiddlewareConfig.Validate(); err != nil {
+		log.Fatalf("Invalid tenant config: %v", err)
+	}
 
-	app.Use(otelfiber.Middleware())
+	httpApp.Use(middleware.NewTenantMiddleware(tenantMiddlewareConfig))
 
-	app.Use(limiter.New(limiter.Config{
-		Max:        20,
-		Expiration: 5 * time.Second,
+	limiterConfig := limiter.Config{
 		KeyGenerator: func(c *fiber.Ctx) string {
 			return c.IP()
 		},
@@ -51,76 +207,208 @@ func main() {
 				"error": "Too many requests. Try again later.",
 			})
 		},
-	}))
+	}
 
-	authServiceClient, authConn := client.NewAuthClient(authUrl)
-	defer func() {
-		if err := authConn.Close(); err != nil {
-			log.Fatalf("Error closing auth connection: %v", err)
+	var throttleConfig authThrottleConfig
+	if err := cleanenv.ReadEnv(&throttleConfig); err != nil {
+		log.Fatalf("Error loading auth throttle config: %v", err)
+	}
+
+	// With several replicas the in-memory counters let each client through
+	// once per replica; "redis" shares them.
+	sharedLimits := utils.ParseWithFallback("RATE_LIMIT_STORAGE", "memory") == "redis"
+
+	var botGuard botGuardConfig
+	if err := cleanenv.ReadEnv(&botGuard); err != nil {
+		log.Fatalf("Error loading bot guard config: %v", err)
+	}
+
+	var rdb goredis.UniversalClient
+	if sharedLimits || throttleConfig.Enabled || botGuard.Enabled {
+		redisConfig, err := redis.LoadFromEnv()
+		if err != nil {
+			log.Fatalf("Error loading redis config: %v", err)
 		}
-	}()
 
-	productServiceClient, productConn := client.NewProductClient(productUrl)
-	defer func() {
-		if err := productConn.Close(); err != nil {
-			log.Fatalf("Error closing product connection: %v", err)
+		rdb, err = redis.New(ctx, redisConfig)
+		if err != nil {
+			log.Fatalf("Error connecting to redis: %v", err)
 		}
-	}()
+		runner.Add(app.Component{Name: "redis", Stop: app.Closer(rdb.Close)})
+	}
+
+	if sharedLimits {
+		limiterConfig.Storage = storage.NewRedis(rdb, "gateway:limiter:")
+	} else {
+		limiterConfig.Storage = storage.NewMemory()
+	}
+
+	rateLimiter := middleware.NewReloadableLimiter(limiterConfig)
+	configWatcher.Subscribe(func(t config.Tunables) {
+		rateLimiter.SetLimits(t.RateLimit.Max, t.RateLimit.Window)
+	})
+
+	httpApp.Use(rateLimiter.Handler())
+
+	authServiceClient, authConn := client.NewAuthClient(authUrl)
+	runner.Add(app.Component{Name: "auth client", Stop: app.Closer(authConn.Close)})
+
+	productServiceClient, productConn := client.NewProductClient(productUrl)
+	runner.Add(app.Component{Name: "product client", Stop: app.Closer(productConn.Close)})
 
 	orderServiceClient, orderConn := client.NewOrderClient(orderUrl)
-	defer func() {
-		if err := orderConn.Close(); err != nil {
-			log.Fatalf("Error closing order connection: %v", err)
-		}
-	}()
+	runner.Add(app.Component{Name: "order client", Stop: app.Closer(orderConn.Close)})
+
+	paymentServiceClient, paymentConn := client.NewPaymentClient(paymentUrl)
+	runner.Add(app.Component{Name: "payment client", Stop: app.Closer(paymentConn.Close)})
 
-	loggerCfg := config.LoggerConfig{
-		Level: "info",
-		Env:   "dev",
+	analyticsServiceClient, analyticsConn := client.NewAnalyticsClient(analyticsUrl)
+	runner.Add(app.Component{Name: "analytics client", Stop: app.Closer(analyticsConn.Close)})
+
+	notificationServiceClient, notificationConn := client.NewNotificationClient(notificationUrl)
+	runner.Add(app.Component{Name: "notification client", Stop: app.Closer(notificationConn.Close)})
+
+	logger.Info("Gateway service started!")
+
+	var authOpts []handler.AuthOption
+	if utils.ParseWithFallback("AUTH_COOKIES", "false") == "true" {
+		authOpts = append(authOpts, handler.WithSessionCookies(handler.SessionCookies{
+			AccessToken: utils.ParseWithFallback("AUTH_COOKIE_ACCESS_TOKEN", "false") == "true",
+			Domain:      utils.ParseWithFallback("AUTH_COOKIE_DOMAIN", ""),
+			Secure:      utils.ParseWithFallback("AUTH_COOKIE_SECURE", "true") == "true",
+			SameSite:    utils.ParseWithFallback("AUTH_COOKIE_SAMESITE", "Strict"),
+		}))
 	}
 
-	logger, err := config.NewLogger(loggerCfg)
-	if err != nil {
-		log.Fatalf("Error creating logger: %v", err)
+	// The feed cache is shared by the replicas whenever Redis is in use.
+	var feedCache fiber.Storage = storage.NewMemory()
+	if rdb != nil {
+		feedCache = storage.NewRedis(rdb, "gateway:feed:")
 	}
-	defer func() {
-		if err := logger.Sync(); err != nil {
-			log.Fatalf("error syncing logger: %v", err)
-		}
-	}()
 
-	logger.Info("Gateway service started!")
+	authHandler := handler.NewAuthHandler(authServiceClient, logger, authOpts...)
+	orderHandler := handler.NewOrderHandler(orderServiceClient, logger)
+	productHandler := handler.NewProductHandler(productServiceClient, logger)
+	notificationHandler := handler.NewNotificationHandler(notificationServiceClient, logger)
 
 	handlers := &http.Handlers{
-		Auth:    handler.NewAuthHandler(authServiceClient, logger),
-		Product: handler.NewProductHandler(productServiceClient, logger),
-		Order:   handler.NewOrderHandler(orderServiceClient, logger),
+		Auth:         authHandler,
+		Product:      productHandler,
+		Order:        orderHandler,
+		Analytics:    handler.NewAnalyticsHandler(analyticsServiceClient, logger),
+		Payment:      handler.NewPaymentHandler(paymentServiceClient, logger),
+		Notification: notificationHandler,
+		Dashboard:    handler.NewDashboardHandler(authHandler, orderHandler, notificationHandler, logger),
+		Feed:         handler.NewFeedHandler(productHandler, feedCache, logger),
+		Ops: handler.NewOpsHandler([]handler.StatusTarget{
+			{Name: "auth-service", Client: statusPb.NewStatusServiceClient(authConn)},
+			{Name: "product-service", Client: statusPb.NewStatusServiceClient(productConn)},
+			{Name: "order-service", Client: statusPb.NewStatusServiceClient(orderConn)},
+			{Name: "payment-service", Client: statusPb.NewStatusServiceClient(paymentConn)},
+			{Name: "analytics-service", Client: statusPb.NewStatusServiceClient(analyticsConn)},
+			{Name: "notification-service", Client: statusPb.NewStatusServiceClient(notificationConn)},
+		}, orderServiceClient, logger),
 	}
 
-	http.RegisterRoutes(app, handlers, authServiceClient)
+	var throttles http.AuthThrottles
+	if throttleConfig.Enabled {
+		throttles.ForgotPassword = middleware.NewThrottleMiddleware(middleware.ThrottleConfig{
+			Client:    rdb,
+			Name:      "forgot_password",
+			Window:    throttleConfig.Window,
+			MaxPerIP:  throttleConfig.ForgotPasswordPerIP,
+			Key:       middleware.EmailKey,
+			MaxPerKey: throttleConfig.ForgotPasswordPerEmail,
+		})
+		throttles.Activate = middleware.NewThrottleMiddleware(middleware.ThrottleConfig{
+			Client:   rdb,
+			Name:     "activate",
+			Window:   throttleConfig.Window,
+			MaxPerIP: throttleConfig.ActivatePerIP,
+		})
+	}
 
-	go func() {
-		log.Println("HTTP Service listening on: " + port)
-		if err := app.Listen(port); err != nil {
-			log.Fatalf("Error listening on HTTP port %v: %v\n", port, err)
+	var productGuard fiber.Handler
+	if botGuard.Enabled {
+		challenge, err := botGuard.challenge(rdb)
+		if err != nil {
+			log.Fatalf("Invalid bot guard config: %v", err)
 		}
-	}()
 
-	<-ctx.Done()
+		productGuard = middleware.NewBotGuardMiddleware(middleware.BotGuardConfig{
+			Client:      rdb,
+			Name:        "products",
+			BurstWindow: botGuard.BurstWindow,
+			BurstMax:    botGuard.BurstMax,
+			SuspectFor:  botGuard.SuspectFor,
+			Challenge:   challenge,
+			PartnerKeys: botGuard.PartnerKeys,
+		})
+	}
 
-	log.Println("Shutting down gracefully...")
-	shutdownContext, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	http.RegisterRoutes(httpApp, handlers, authServiceClient, throttles, productGuard)
 
-	if err := app.ShutdownWithContext(shutdownContext); err != nil {
-		log.Printf("Error shutting down HTTP app: %v\n", err)
-	} else {
-		log.Println("HTTP App stopped gracefully")
+	var static staticConfig
+	if err := cleanenv.ReadEnv(&static); err != nil {
+		log.Fatalf("Error loading static config: %v", err)
 	}
 
-	if err := tp.Shutdown(shutdownContext); err != nil {
-		log.Printf("Error shutting down telemetry: %v\n", err)
-	} else {
-		log.Println("Telemetry stopped correctly")
+	if static.Enabled {
+		files := web.Dist()
+		if static.Dir != "" {
+			files = os.DirFS(static.Dir)
+		}
+
+		httpApp.Use(middleware.NewSPAMiddleware(middleware.SPAConfig{
+			Files:           files,
+			ImmutablePrefix: static.ImmutablePrefix,
+			Exclude:         []string{"/api", "/auth", "/unsubscribe"},
+		}))
+	}
+
+	runner.Add(app.Component{
+		Name: "http server",
+		Start: func(context.Context) error {
+			log.Println("HTTP Service listening on: " + port)
+			return httpApp.Listen(port)
+		},
+		Stop: httpApp.ShutdownWithContext,
+	})
+
+	debugConfig, err := debug.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading debug config: %v", err)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector())
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if err := config.RegisterLogMetrics(reg); err != nil {
+		log.Fatalf("Error registering log metrics: %v", err)
+	}
+	if err := middleware.RegisterThrottleMetrics(reg); err != nil {
+		log.Fatalf("Error registering throttle metrics: %v", err)
+	}
+	if err := middleware.RegisterBotGuardMetrics(reg); err != nil {
+		log.Fatalf("Error registering bot guard metrics: %v", err)
+	}
+	if err := middleware.RegisterAccessLogMetrics(reg); err != nil {
+		log.Fatalf("Error registering access log metrics: %v", err)
+	}
+
+	adminMux := netHttp.NewServeMux()
+	adminMux.Handle("/metrics", config.MetricsHandler(reg))
+	if err := debug.Mount(adminMux, debugConfig); err != nil {
+		log.Fatalf("Error mounting debug endpoints: %v", err)
+	}
+
+	runner.Add(app.HTTPServer("admin server", &netHttp.Server{
+		Addr:              utils.ParseWithFallback("ADMIN_ADDR", ":9097"),
+		Handler:           adminMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}))
+
+	if err := runner.Run(ctx); err != nil {
+		log.Fatalf("Shutdown finished with errors: %v", err)
 	}
 }
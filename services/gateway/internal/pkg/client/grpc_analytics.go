@@ -0,0 +1,24 @@
+package client
+
+import (
+	"log"
+
+	pb "github.com/sakashimaa/go-pet-project/proto/analytics"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func NewAnalyticsClient(url string) (pb.AnalyticsServiceClient, *grpc.ClientConn) {
+	conn, err := grpc.NewClient(
+		url,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithUnaryInterceptor(retryInterceptor()),
+	)
+	if err != nil {
+		log.Fatalf("Error creating gRPC client: %v\n", err)
+	}
+
+	return pb.NewAnalyticsServiceClient(conn), conn
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"log"
 
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	pb "github.com/sakashimaa/go-pet-project/proto/auth"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
@@ -14,6 +15,7 @@ func NewAuthClient(url string) (pb.AuthServiceClient, *grpc.ClientConn) {
 		url,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
 		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(tenant.UnaryClientInterceptor(), retryInterceptor()),
 	)
 	if err != nil {
 		log.Fatalf("Error creating gRPC client: %v\n", err)
@@ -0,0 +1,25 @@
+package client
+
+import (
+	"log"
+
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	pb "github.com/sakashimaa/go-pet-project/proto/payment"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func NewPaymentClient(url string) (pb.PaymentServiceClient, *grpc.ClientConn) {
+	conn, err := grpc.NewClient(
+		url,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		grpc.WithChainUnaryInterceptor(tenant.UnaryClientInterceptor(), retryInterceptor()),
+	)
+	if err != nil {
+		log.Fatalf("Error creating gRPC client: %v\n", err)
+	}
+
+	return pb.NewPaymentServiceClient(conn), conn
+}
@@ -3,13 +3,19 @@ package client
 import (
 	"log"
 
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	pb "github.com/sakashimaa/go-pet-project/proto/product"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
 func NewProductClient(url string) (pb.ProductServiceClient, *grpc.ClientConn) {
-	conn, err := grpc.NewClient(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient(
+		url,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(tenant.UnaryClientInterceptor(), retryInterceptor()),
+		grpc.WithStreamInterceptor(tenant.StreamClientInterceptor()),
+	)
 	if err != nil {
 		log.Fatalf("Error creating gRPC client: %v\n", err)
 	}
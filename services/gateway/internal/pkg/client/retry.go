@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// unavailable reports whether a call failed because the service could not
+// be reached, e.g. while it restarts. Any other error is the service's
+// answer and is passed on as is.
+func unavailable(err error) bool {
+	return status.Code(err) == codes.Unavailable
+}
+
+// retryInterceptor resends calls that found the service unavailable, within
+// the caller's deadline.
+func retryInterceptor() grpc.UnaryClientInterceptor {
+	policy := retry.Default()
+	policy.Retryable = unavailable
+
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := retry.Do(ctx, policy, func(ctx context.Context) error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+		if _, ok := status.FromError(err); !ok {
+			// The deadline passed between attempts; answer like gRPC would.
+			return status.FromContextError(err).Err()
+		}
+
+		return err
+	}
+}
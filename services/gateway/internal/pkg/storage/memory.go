@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"sync"
+	"time"
+)
+
+const memorySweepEvery = time.Minute
+
+type memoryEntry struct {
+	val       []byte
+	expiresAt time.Time
+}
+
+// Memory implements fiber.Storage in process. Unlike the storage fiber
+// creates for a limiter by default it starts no goroutine, so one instance
+// can back limiters that are rebuilt on config reloads and keep their
+// counters. Expired keys are swept while writing.
+type Memory struct {
+	mu        sync.Mutex
+	data      map[string]memoryEntry
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{data: make(map[string]memoryEntry), lastSweep: time.Now()}
+}
+
+func (s *Memory) Get(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.data[key]
+	if !ok || entry.expired(time.Now()) {
+		return nil, nil
+	}
+
+	return entry.val, nil
+}
+
+func (s *Memory) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := memoryEntry{val: append([]byte(nil), val...)}
+	if exp > 0 {
+		entry.expiresAt = now.Add(exp)
+	}
+	s.data[key] = entry
+
+	if now.Sub(s.lastSweep) >= memorySweepEvery {
+		for k, e := range s.data {
+			if e.expired(now) {
+				delete(s.data, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	return nil
+}
+
+func (s *Memory) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.data, key)
+
+	return nil
+}
+
+func (s *Memory) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.data = make(map[string]memoryEntry)
+
+	return nil
+}
+
+func (s *Memory) Close() error {
+	return nil
+}
+
+func (e memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
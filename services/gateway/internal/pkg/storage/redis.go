@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const opTimeout = time.Second
+
+// Redis implements fiber.Storage on top of a shared Redis client, so limits
+// hold across every gateway replica instead of per process.
+type Redis struct {
+	client goredis.UniversalClient
+	prefix string
+}
+
+// NewRedis namespaces keys with prefix. The client stays owned by the caller:
+// Close does not close it.
+func NewRedis(client goredis.UniversalClient, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+func (s *Redis) Get(key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	val, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+
+	return val, err
+}
+
+func (s *Redis) Set(key string, val []byte, exp time.Duration) error {
+	if key == "" || len(val) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	return s.client.Set(ctx, s.prefix+key, val, exp).Err()
+}
+
+func (s *Redis) Delete(key string) error {
+	if key == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), opTimeout)
+	defer cancel()
+
+	return s.client.Del(ctx, s.prefix+key).Err()
+}
+
+// Reset deletes every key under the prefix, on each master in cluster mode.
+func (s *Redis) Reset() error {
+	ctx := context.Background()
+
+	if cluster, ok := s.client.(*goredis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, node *goredis.Client) error {
+			return s.deletePrefixed(ctx, node)
+		})
+	}
+
+	return s.deletePrefixed(ctx, s.client)
+}
+
+func (s *Redis) deletePrefixed(ctx context.Context, client goredis.Cmdable) error {
+	iter := client.Scan(ctx, 0, s.prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := client.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+
+	return iter.Err()
+}
+
+func (s *Redis) Close() error {
+	return nil
+}
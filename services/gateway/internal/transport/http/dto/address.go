@@ -0,0 +1,50 @@
+package dto
+
+import (
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+type Address struct {
+	ID         int64  `json:"id"`
+	Label      string `json:"label"`
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2"`
+	City       string `json:"city"`
+	Region     string `json:"region"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type AddressListResponse struct {
+	Addresses []Address `json:"addresses"`
+}
+
+func AddressFromProto(a *pb.Address) Address {
+	return Address{
+		ID:         a.GetId(),
+		Label:      a.GetLabel(),
+		Recipient:  a.GetRecipient(),
+		Phone:      a.GetPhone(),
+		Line1:      a.GetLine1(),
+		Line2:      a.GetLine2(),
+		City:       a.GetCity(),
+		Region:     a.GetRegion(),
+		PostalCode: a.GetPostalCode(),
+		Country:    a.GetCountry(),
+		CreatedAt:  a.GetCreatedAt(),
+		UpdatedAt:  a.GetUpdatedAt(),
+	}
+}
+
+func AddressListFromProto(res *pb.ListAddressesResponse) AddressListResponse {
+	addresses := make([]Address, 0, len(res.GetAddresses()))
+	for _, a := range res.GetAddresses() {
+		addresses = append(addresses, AddressFromProto(a))
+	}
+
+	return AddressListResponse{Addresses: addresses}
+}
@@ -0,0 +1,83 @@
+package dto
+
+import (
+	pb "github.com/sakashimaa/go-pet-project/proto/analytics"
+)
+
+type DailyVolume struct {
+	Day             string `json:"day"`
+	Orders          int64  `json:"orders"`
+	PaidOrders      int64  `json:"paid_orders"`
+	CancelledOrders int64  `json:"cancelled_orders"`
+	Revenue         int64  `json:"revenue"`
+	FailedPayments  int64  `json:"failed_payments"`
+}
+
+type OrderVolumeResponse struct {
+	Days         []DailyVolume `json:"days"`
+	TotalOrders  int64         `json:"total_orders"`
+	TotalRevenue int64         `json:"total_revenue"`
+}
+
+type FunnelResponse struct {
+	RegisteredUsers int64   `json:"registered_users"`
+	OrderingUsers   int64   `json:"ordering_users"`
+	OrdersCreated   int64   `json:"orders_created"`
+	OrdersReserved  int64   `json:"orders_reserved"`
+	OrdersPaid      int64   `json:"orders_paid"`
+	ConversionRate  float64 `json:"conversion_rate"`
+}
+
+type TopProduct struct {
+	ProductID int64 `json:"product_id"`
+	Units     int64 `json:"units"`
+	Orders    int64 `json:"orders"`
+}
+
+type TopProductsResponse struct {
+	Products []TopProduct `json:"products"`
+}
+
+func OrderVolumeFromProto(res *pb.GetOrderVolumeResponse) OrderVolumeResponse {
+	days := make([]DailyVolume, 0, len(res.GetDays()))
+	for _, d := range res.GetDays() {
+		days = append(days, DailyVolume{
+			Day:             d.GetDay(),
+			Orders:          d.GetOrders(),
+			PaidOrders:      d.GetPaidOrders(),
+			CancelledOrders: d.GetCancelledOrders(),
+			Revenue:         d.GetRevenue(),
+			FailedPayments:  d.GetFailedPayments(),
+		})
+	}
+
+	return OrderVolumeResponse{
+		Days:         days,
+		TotalOrders:  res.GetTotalOrders(),
+		TotalRevenue: res.GetTotalRevenue(),
+	}
+}
+
+func FunnelFromProto(res *pb.GetFunnelResponse) FunnelResponse {
+	return FunnelResponse{
+		RegisteredUsers: res.GetRegisteredUsers(),
+		OrderingUsers:   res.GetOrderingUsers(),
+		OrdersCreated:   res.GetOrdersCreated(),
+		OrdersReserved:  res.GetOrdersReserved(),
+		OrdersPaid:      res.GetOrdersPaid(),
+		ConversionRate:  res.GetConversionRate(),
+	}
+}
+
+func TopProductsFromProto(res *pb.GetTopProductsResponse) TopProductsResponse {
+	products := make([]TopProduct, 0, len(res.GetProducts()))
+	for _, p := range res.GetProducts() {
+		products = append(products, TopProduct{
+			ProductID: p.GetProductId(),
+			Units:     p.GetUnits(),
+			Orders:    p.GetOrders(),
+		})
+	}
+
+	return TopProductsResponse{Products: products}
+}
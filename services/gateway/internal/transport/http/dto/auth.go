@@ -0,0 +1,53 @@
+package dto
+
+import (
+	pb "github.com/sakashimaa/go-pet-project/proto/auth"
+)
+
+type RegisterResponse struct {
+	ID        int64  `json:"id"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+	// ActivationToken is delivered by email. Auth only returns it when it
+	// runs with EXPOSE_ACTIVATION_TOKEN in test environments.
+	ActivationToken string `json:"activation_token,omitempty"`
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// CookieSessionResponse answers a login or refresh in cookie mode. The
+// access token is only present when it is not delivered as a cookie.
+type CookieSessionResponse struct {
+	AccessToken string `json:"access_token,omitempty"`
+	CSRFToken   string `json:"csrf_token"`
+}
+
+type User struct {
+	ID          int64  `json:"id"`
+	Email       string `json:"email"`
+	IsActivated bool   `json:"is_activated"`
+	Locale      string `json:"locale"`
+}
+
+func RegisterResponseFromProto(res *pb.RegisterResponse) RegisterResponse {
+	return RegisterResponse{
+		ID:              res.GetId(),
+		Email:           res.GetEmail(),
+		CreatedAt:       res.GetCreatedAt(),
+		UpdatedAt:       res.GetUpdatedAt(),
+		ActivationToken: res.GetActivationToken(),
+	}
+}
+
+func UserFromProto(userID int64, res *pb.UserInfoResponse) User {
+	return User{
+		ID:          userID,
+		Email:       res.GetEmail(),
+		IsActivated: res.GetIsActivated(),
+		Locale:      res.GetLocale(),
+	}
+}
@@ -0,0 +1,16 @@
+package dto
+
+// FeedBlock is one section of the home feed.
+type FeedBlock struct {
+	Name     string    `json:"name"`
+	Products []Product `json:"products"`
+	// Fallback is set when the block could not be loaded and shows substitute
+	// or stale content instead.
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+type FeedResponse struct {
+	Blocks []FeedBlock `json:"blocks"`
+	// Errors names the blocks that are missing and why.
+	Errors map[string]string `json:"errors,omitempty"`
+}
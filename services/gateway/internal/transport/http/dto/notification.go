@@ -0,0 +1,66 @@
+package dto
+
+import (
+	pb "github.com/sakashimaa/go-pet-project/proto/notification"
+)
+
+type Notification struct {
+	ID        int64  `json:"id"`
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Read      bool   `json:"read"`
+	CreatedAt string `json:"created_at"`
+}
+
+type NotificationListResponse struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int64          `json:"unread_count"`
+}
+
+type MarkReadResponse struct {
+	Updated int64 `json:"updated"`
+}
+
+type UnreadCountResponse struct {
+	Count int64 `json:"count"`
+}
+
+type UnsubscribeResponse struct {
+	Email    string `json:"email"`
+	Category string `json:"category"`
+}
+
+func NotificationListFromProto(res *pb.ListNotificationsResponse) NotificationListResponse {
+	notifications := make([]Notification, 0, len(res.GetNotifications()))
+	for _, n := range res.GetNotifications() {
+		notifications = append(notifications, Notification{
+			ID:        n.GetId(),
+			Kind:      n.GetKind(),
+			Title:     n.GetTitle(),
+			Body:      n.GetBody(),
+			Read:      n.GetRead(),
+			CreatedAt: n.GetCreatedAt(),
+		})
+	}
+
+	return NotificationListResponse{
+		Notifications: notifications,
+		UnreadCount:   res.GetUnreadCount(),
+	}
+}
+
+func MarkReadFromProto(res *pb.MarkReadResponse) MarkReadResponse {
+	return MarkReadResponse{Updated: res.GetUpdated()}
+}
+
+func UnreadCountFromProto(res *pb.GetUnreadCountResponse) UnreadCountResponse {
+	return UnreadCountResponse{Count: res.GetCount()}
+}
+
+func UnsubscribeFromProto(res *pb.UnsubscribeResponse) UnsubscribeResponse {
+	return UnsubscribeResponse{
+		Email:    res.GetEmail(),
+		Category: res.GetCategory(),
+	}
+}
@@ -0,0 +1,179 @@
+package dto
+
+import (
+	pb "github.com/sakashimaa/go-pet-project/proto/status"
+)
+
+type OutboxTopic struct {
+	Topic                   string  `json:"topic"`
+	Pending                 int64   `json:"pending"`
+	Failed                  int64   `json:"failed"`
+	OldestPendingAgeSeconds float64 `json:"oldest_pending_age_seconds"`
+}
+
+type FailedOutboxEvent struct {
+	ID          int64  `json:"id"`
+	Topic       string `json:"topic"`
+	EventType   string `json:"event_type"`
+	AggregateID string `json:"aggregate_id"`
+	Attempts    int32  `json:"attempts"`
+	LastError   string `json:"last_error"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type OutboxStatus struct {
+	Topics         []OutboxTopic       `json:"topics"`
+	RecentFailures []FailedOutboxEvent `json:"recent_failures"`
+	Error          string              `json:"error,omitempty"`
+}
+
+type PartitionLag struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+	Committed int64  `json:"committed"`
+	Newest    int64  `json:"newest"`
+	Lag       int64  `json:"lag"`
+}
+
+type ConsumerGroupStatus struct {
+	GroupID    string         `json:"group_id"`
+	TotalLag   int64          `json:"total_lag"`
+	Partitions []PartitionLag `json:"partitions"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// ServiceStatus is one backend's answer to the status RPC. Error is set, and
+// the rest empty, when the backend could not be asked.
+type ServiceStatus struct {
+	Service        string                `json:"service"`
+	Outbox         *OutboxStatus         `json:"outbox"`
+	ConsumerGroups []ConsumerGroupStatus `json:"consumer_groups"`
+	Error          string                `json:"error,omitempty"`
+}
+
+func ServiceStatusFromProto(res *pb.GetStatusResponse) ServiceStatus {
+	status := ServiceStatus{
+		Service:        res.GetService(),
+		ConsumerGroups: make([]ConsumerGroupStatus, 0, len(res.GetConsumerGroups())),
+	}
+
+	if outbox := res.GetOutbox(); outbox != nil {
+		status.Outbox = &OutboxStatus{
+			Topics:         make([]OutboxTopic, 0, len(outbox.GetTopics())),
+			RecentFailures: make([]FailedOutboxEvent, 0, len(outbox.GetRecentFailures())),
+			Error:          outbox.GetError(),
+		}
+		for _, t := range outbox.GetTopics() {
+			status.Outbox.Topics = append(status.Outbox.Topics, OutboxTopic{
+				Topic:                   t.GetTopic(),
+				Pending:                 t.GetPending(),
+				Failed:                  t.GetFailed(),
+				OldestPendingAgeSeconds: t.GetOldestPendingAgeSeconds(),
+			})
+		}
+		for _, e := range outbox.GetRecentFailures() {
+			status.Outbox.RecentFailures = append(status.Outbox.RecentFailures, FailedOutboxEvent{
+				ID:          e.GetId(),
+				Topic:       e.GetTopic(),
+				EventType:   e.GetEventType(),
+				AggregateID: e.GetAggregateId(),
+				Attempts:    e.GetAttempts(),
+				LastError:   e.GetLastError(),
+				CreatedAt:   e.GetCreatedAt(),
+			})
+		}
+	}
+
+	for _, g := range res.GetConsumerGroups() {
+		group := ConsumerGroupStatus{
+			GroupID:    g.GetGroupId(),
+			TotalLag:   g.GetTotalLag(),
+			Partitions: make([]PartitionLag, 0, len(g.GetPartitions())),
+			Error:      g.GetError(),
+		}
+		for _, p := range g.GetPartitions() {
+			group.Partitions = append(group.Partitions, PartitionLag{
+				Topic:     p.GetTopic(),
+				Partition: p.GetPartition(),
+				Committed: p.GetCommitted(),
+				Newest:    p.GetNewest(),
+				Lag:       p.GetLag(),
+			})
+		}
+		status.ConsumerGroups = append(status.ConsumerGroups, group)
+	}
+
+	return status
+}
+
+// EventConsumer is a consumer group subscribed to an event's topic. Consumed
+// is whether the group's committed offset has moved past the event.
+type EventConsumer struct {
+	Service  string `json:"service"`
+	GroupID  string `json:"group_id"`
+	Consumed bool   `json:"consumed"`
+}
+
+// TracedEvent is an outbox event of a saga with where it was published and
+// who has read it.
+type TracedEvent struct {
+	Service       string `json:"service"`
+	EventID       string `json:"event_id"`
+	EventType     string `json:"event_type"`
+	Topic         string `json:"topic"`
+	AggregateType string `json:"aggregate_type,omitempty"`
+	AggregateID   string `json:"aggregate_id,omitempty"`
+	Status        string `json:"status"`
+	Attempts      int32  `json:"attempts"`
+	LastError     string `json:"last_error,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	PublishedAt   string `json:"published_at,omitempty"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	CausationID   string `json:"causation_id,omitempty"`
+	TraceID       string `json:"trace_id,omitempty"`
+	// Partition and Offset are unset until the event is published.
+	Partition *int32          `json:"partition,omitempty"`
+	Offset    *int64          `json:"offset,omitempty"`
+	Consumers []EventConsumer `json:"consumers,omitempty"`
+}
+
+// SagaTrace pieces together one order's saga from every service.
+type SagaTrace struct {
+	OrderID int64 `json:"order_id"`
+	// Status is the latest status on the order's timeline.
+	Status   string          `json:"status,omitempty"`
+	Timeline []TimelineEntry `json:"timeline"`
+	Events   []TracedEvent   `json:"events"`
+	// TraceIDs are the distinct traces the events were saved in, in order.
+	TraceIDs []string `json:"trace_ids"`
+	// Truncated is set when a service had more events than it returned.
+	Truncated bool `json:"truncated,omitempty"`
+	// Errors names the parts that could not be read and why.
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+func TracedEventFromProto(service string, e *pb.TracedEvent) TracedEvent {
+	event := TracedEvent{
+		Service:       service,
+		EventID:       e.GetEventId(),
+		EventType:     e.GetEventType(),
+		Topic:         e.GetTopic(),
+		AggregateType: e.GetAggregateType(),
+		AggregateID:   e.GetAggregateId(),
+		Status:        e.GetStatus(),
+		Attempts:      e.GetAttempts(),
+		LastError:     e.GetLastError(),
+		CreatedAt:     e.GetCreatedAt(),
+		PublishedAt:   e.GetPublishedAt(),
+		CorrelationID: e.GetCorrelationId(),
+		CausationID:   e.GetCausationId(),
+		TraceID:       e.GetTraceId(),
+	}
+
+	if e.GetOffset() >= 0 {
+		partition, offset := e.GetPartition(), e.GetOffset()
+		event.Partition, event.Offset = &partition, &offset
+	}
+
+	return event
+}
@@ -0,0 +1,176 @@
+package dto
+
+import (
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+type TimelineEntry struct {
+	ID        int64  `json:"id"`
+	EventType string `json:"event_type"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	// VisibleToCustomer is only meaningful on internal timelines.
+	VisibleToCustomer bool   `json:"visible_to_customer"`
+	CreatedAt         string `json:"created_at"`
+}
+
+type OrderTimelineResponse struct {
+	OrderID int64           `json:"order_id"`
+	Entries []TimelineEntry `json:"entries"`
+}
+
+func OrderTimelineFromProto(res *pb.GetOrderTimelineResponse) OrderTimelineResponse {
+	entries := make([]TimelineEntry, 0, len(res.GetEntries()))
+	for _, e := range res.GetEntries() {
+		entries = append(entries, TimelineEntry{
+			ID:                e.GetId(),
+			EventType:         e.GetEventType(),
+			Status:            e.GetStatus(),
+			Message:           e.GetMessage(),
+			VisibleToCustomer: e.GetVisibleToCustomer(),
+			CreatedAt:         e.GetCreatedAt(),
+		})
+	}
+
+	return OrderTimelineResponse{OrderID: res.GetOrderId(), Entries: entries}
+}
+
+// PurchaseCap is how many units of a product one customer may buy.
+type PurchaseCap struct {
+	ProductID  int64  `json:"product_id"`
+	MaxPerUser int32  `json:"max_per_user"`
+	SetBy      int64  `json:"set_by"`
+	UpdatedAt  string `json:"updated_at,omitempty"`
+}
+
+type PurchaseCapListResponse struct {
+	Caps []PurchaseCap `json:"caps"`
+}
+
+func PurchaseCapFromProto(c *pb.PurchaseCap) PurchaseCap {
+	return PurchaseCap{
+		ProductID:  c.GetProductId(),
+		MaxPerUser: c.GetMaxPerUser(),
+		SetBy:      c.GetSetBy(),
+		UpdatedAt:  c.GetUpdatedAt(),
+	}
+}
+
+func PurchaseCapListFromProto(res *pb.ListPurchaseCapsResponse) PurchaseCapListResponse {
+	caps := make([]PurchaseCap, 0, len(res.GetCaps()))
+	for _, c := range res.GetCaps() {
+		caps = append(caps, PurchaseCapFromProto(c))
+	}
+
+	return PurchaseCapListResponse{Caps: caps}
+}
+
+type ReturnItem struct {
+	ProductID int64  `json:"product_id"`
+	Name      string `json:"name"`
+	Price     int64  `json:"price"`
+	Quantity  int32  `json:"quantity"`
+}
+
+// Return is a customer return. RefundedAmount and RefundedAt are set once
+// payment paid the refund, which can be less than RefundAmount.
+type Return struct {
+	ID             int64        `json:"id"`
+	OrderID        int64        `json:"order_id"`
+	UserID         int64        `json:"user_id"`
+	Status         string       `json:"status"`
+	Reason         string       `json:"reason"`
+	Items          []ReturnItem `json:"items"`
+	RefundAmount   int64        `json:"refund_amount"`
+	RefundedAmount int64        `json:"refunded_amount,omitempty"`
+	ReviewNote     string       `json:"review_note,omitempty"`
+	CreatedAt      string       `json:"created_at"`
+	ReviewedAt     string       `json:"reviewed_at,omitempty"`
+	RefundedAt     string       `json:"refunded_at,omitempty"`
+}
+
+type ReturnListResponse struct {
+	Returns []Return `json:"returns"`
+}
+
+func ReturnFromProto(r *pb.Return) Return {
+	items := make([]ReturnItem, 0, len(r.GetItems()))
+	for _, item := range r.GetItems() {
+		items = append(items, ReturnItem{
+			ProductID: item.GetProductId(),
+			Name:      item.GetName(),
+			Price:     item.GetPrice(),
+			Quantity:  item.GetQuantity(),
+		})
+	}
+
+	return Return{
+		ID:             r.GetId(),
+		OrderID:        r.GetOrderId(),
+		UserID:         r.GetUserId(),
+		Status:         r.GetStatus(),
+		Reason:         r.GetReason(),
+		Items:          items,
+		RefundAmount:   r.GetRefundAmount(),
+		RefundedAmount: r.GetRefundedAmount(),
+		ReviewNote:     r.GetReviewNote(),
+		CreatedAt:      r.GetCreatedAt(),
+		ReviewedAt:     r.GetReviewedAt(),
+		RefundedAt:     r.GetRefundedAt(),
+	}
+}
+
+func ReturnListFromProto(res *pb.ListReturnsResponse) ReturnListResponse {
+	returns := make([]Return, 0, len(res.GetReturns()))
+	for _, r := range res.GetReturns() {
+		returns = append(returns, ReturnFromProto(r))
+	}
+
+	return ReturnListResponse{Returns: returns}
+}
+
+// OrderAnnotation is a staff-only note or flag on an order.
+type OrderAnnotation struct {
+	ID        int64  `json:"id"`
+	OrderID   int64  `json:"order_id"`
+	Kind      string `json:"kind"`
+	Flag      string `json:"flag,omitempty"`
+	Body      string `json:"body,omitempty"`
+	AuthorID  int64  `json:"author_id"`
+	CreatedAt string `json:"created_at"`
+	ClearedBy int64  `json:"cleared_by,omitempty"`
+	ClearedAt string `json:"cleared_at,omitempty"`
+}
+
+type OrderAnnotationsResponse struct {
+	Annotations []OrderAnnotation `json:"annotations"`
+	ActiveFlags []string          `json:"active_flags"`
+}
+
+func OrderAnnotationFromProto(a *pb.OrderAnnotation) OrderAnnotation {
+	return OrderAnnotation{
+		ID:        a.GetId(),
+		OrderID:   a.GetOrderId(),
+		Kind:      a.GetKind(),
+		Flag:      a.GetFlag(),
+		Body:      a.GetBody(),
+		AuthorID:  a.GetAuthorId(),
+		CreatedAt: a.GetCreatedAt(),
+		ClearedBy: a.GetClearedBy(),
+		ClearedAt: a.GetClearedAt(),
+	}
+}
+
+func OrderAnnotationsFromProto(res *pb.ListOrderAnnotationsResponse) OrderAnnotationsResponse {
+	annotations := make([]OrderAnnotation, 0, len(res.GetAnnotations()))
+	for _, a := range res.GetAnnotations() {
+		annotations = append(annotations, OrderAnnotationFromProto(a))
+	}
+
+	flags := res.GetActiveFlags()
+	if flags == nil {
+		flags = []string{}
+	}
+
+	return OrderAnnotationsResponse{Annotations: annotations, ActiveFlags: flags}
+}
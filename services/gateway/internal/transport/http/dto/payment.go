@@ -0,0 +1,90 @@
+package dto
+
+import (
+	pb "github.com/sakashimaa/go-pet-project/proto/payment"
+)
+
+type PaymentMethod struct {
+	ID        int64  `json:"id"`
+	Provider  string `json:"provider"`
+	Brand     string `json:"brand"`
+	Last4     string `json:"last4"`
+	ExpMonth  int32  `json:"exp_month"`
+	ExpYear   int32  `json:"exp_year"`
+	CreatedAt string `json:"created_at"`
+}
+
+type PaymentMethodListResponse struct {
+	PaymentMethods []PaymentMethod `json:"payment_methods"`
+}
+
+func PaymentMethodFromProto(m *pb.PaymentMethod) PaymentMethod {
+	return PaymentMethod{
+		ID:        m.GetId(),
+		Provider:  m.GetProvider(),
+		Brand:     m.GetBrand(),
+		Last4:     m.GetLast4(),
+		ExpMonth:  m.GetExpMonth(),
+		ExpYear:   m.GetExpYear(),
+		CreatedAt: m.GetCreatedAt(),
+	}
+}
+
+func PaymentMethodListFromProto(res *pb.ListPaymentMethodsResponse) PaymentMethodListResponse {
+	methods := make([]PaymentMethod, 0, len(res.GetPaymentMethods()))
+	for _, m := range res.GetPaymentMethods() {
+		methods = append(methods, PaymentMethodFromProto(m))
+	}
+
+	return PaymentMethodListResponse{PaymentMethods: methods}
+}
+
+type GiftCard struct {
+	ID            int64  `json:"id"`
+	Last4         string `json:"last4"`
+	InitialAmount int64  `json:"initial_amount"`
+	Balance       int64  `json:"balance"`
+	ExpiresAt     string `json:"expires_at"`
+	ClaimedAt     string `json:"claimed_at,omitempty"`
+	VoidedAt      string `json:"voided_at,omitempty"`
+	CreatedAt     string `json:"created_at"`
+}
+
+type GiftCardListResponse struct {
+	GiftCards []GiftCard `json:"gift_cards"`
+}
+
+// IssuedGiftCard is the only response that carries the full code.
+type IssuedGiftCard struct {
+	GiftCard
+	Code string `json:"code"`
+}
+
+func GiftCardFromProto(g *pb.GiftCard) GiftCard {
+	return GiftCard{
+		ID:            g.GetId(),
+		Last4:         g.GetLast4(),
+		InitialAmount: g.GetInitialAmount(),
+		Balance:       g.GetBalance(),
+		ExpiresAt:     g.GetExpiresAt(),
+		ClaimedAt:     g.GetClaimedAt(),
+		VoidedAt:      g.GetVoidedAt(),
+		CreatedAt:     g.GetCreatedAt(),
+	}
+}
+
+func GiftCardListFromProto(res *pb.ListGiftCardsResponse) GiftCardListResponse {
+	cards := make([]GiftCard, 0, len(res.GetGiftCards()))
+	for _, g := range res.GetGiftCards() {
+		cards = append(cards, GiftCardFromProto(g))
+	}
+
+	return GiftCardListResponse{GiftCards: cards}
+}
+
+func IssuedGiftCardFromProto(res *pb.IssueGiftCardResponse) IssuedGiftCard {
+	return IssuedGiftCard{
+		GiftCard: GiftCardFromProto(res.GetGiftCard()),
+		Code:     res.GetCode(),
+	}
+}
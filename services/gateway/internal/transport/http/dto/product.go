@@ -0,0 +1,257 @@
+// Package dto holds the JSON bodies the gateway answers with. Handlers map
+// gRPC responses into these types instead of encoding protobuf structs, so
+// proto changes do not leak into the public API.
+package dto
+
+import (
+	"encoding/json"
+
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+)
+
+type Product struct {
+	ID            int64             `json:"id"`
+	Name          string            `json:"name"`
+	Description   string            `json:"description"`
+	Price         int64             `json:"price"`
+	StockQuantity int64             `json:"stock_quantity"`
+	ImageURL      string            `json:"image_url,omitempty"`
+	Category      string            `json:"category,omitempty"`
+	SKU           string            `json:"sku,omitempty"`
+	EAN           string            `json:"ean,omitempty"`
+	Attributes    map[string]string `json:"attributes,omitempty"`
+	Rating        float64           `json:"rating"`
+	DeletedAt     string            `json:"deleted_at,omitempty"`
+	// EffectivePrice is Price less the discount of Promotion, if any.
+	EffectivePrice int64             `json:"effective_price"`
+	Promotion      *ProductPromotion `json:"promotion,omitempty"`
+}
+
+type ProductPromotion struct {
+	CampaignID      int64  `json:"campaign_id"`
+	Name            string `json:"name"`
+	DiscountPercent int32  `json:"discount_percent"`
+	EndsAt          string `json:"ends_at"`
+}
+
+type ProductResponse struct {
+	Product Product `json:"product"`
+}
+
+type ProductListResponse struct {
+	Products   []Product `json:"products"`
+	TotalCount int64     `json:"total_count"`
+}
+
+type RelatedProductsResponse struct {
+	Products []Product `json:"products"`
+}
+
+type ProductRevision struct {
+	ID        int64           `json:"id"`
+	Action    string          `json:"action"`
+	Actor     string          `json:"actor"`
+	Changes   json.RawMessage `json:"changes"`
+	CreatedAt string          `json:"created_at"`
+}
+
+type ProductHistoryResponse struct {
+	ProductID int64             `json:"product_id"`
+	Revisions []ProductRevision `json:"revisions"`
+}
+
+type Warehouse struct {
+	ID        int64    `json:"id"`
+	Code      string   `json:"code"`
+	Name      string   `json:"name"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Priority  int32    `json:"priority"`
+	IsActive  bool     `json:"is_active"`
+}
+
+type WarehouseListResponse struct {
+	Warehouses []Warehouse `json:"warehouses"`
+}
+
+type WarehouseStock struct {
+	WarehouseID   int64  `json:"warehouse_id"`
+	WarehouseCode string `json:"warehouse_code"`
+	Quantity      int64  `json:"quantity"`
+}
+
+type ProductStockResponse struct {
+	ProductID int64            `json:"product_id"`
+	Stock     []WarehouseStock `json:"stock"`
+}
+
+func ProductFromProto(p *pb.Product) Product {
+	return Product{
+		ID:             p.GetId(),
+		Name:           p.GetName(),
+		Description:    p.GetDescription(),
+		Price:          p.GetPrice(),
+		StockQuantity:  p.GetStockQuantity(),
+		ImageURL:       p.GetImageUrl(),
+		Category:       p.GetCategory(),
+		SKU:            p.GetSku(),
+		EAN:            p.GetEan(),
+		Attributes:     p.GetAttributes(),
+		Rating:         p.GetRating(),
+		DeletedAt:      p.GetDeletedAt(),
+		EffectivePrice: p.GetEffectivePrice(),
+		Promotion:      ProductPromotionFromProto(p.GetPromotion()),
+	}
+}
+
+func ProductPromotionFromProto(p *pb.ProductPromotion) *ProductPromotion {
+	if p == nil {
+		return nil
+	}
+
+	return &ProductPromotion{
+		CampaignID:      p.GetCampaignId(),
+		Name:            p.GetName(),
+		DiscountPercent: p.GetDiscountPercent(),
+		EndsAt:          p.GetEndsAt(),
+	}
+}
+
+func ProductsFromProto(products []*pb.Product) []Product {
+	out := make([]Product, 0, len(products))
+	for _, p := range products {
+		out = append(out, ProductFromProto(p))
+	}
+
+	return out
+}
+
+func ProductResponseFromProto(res *pb.GetProductResponse) ProductResponse {
+	return ProductResponse{Product: ProductFromProto(res.GetProduct())}
+}
+
+func ProductListFromProto(res *pb.ListProductsResponse) ProductListResponse {
+	return ProductListResponse{
+		Products:   ProductsFromProto(res.GetProducts()),
+		TotalCount: res.GetTotalCount(),
+	}
+}
+
+func RelatedProductsFromProto(res *pb.GetRelatedProductsResponse) RelatedProductsResponse {
+	return RelatedProductsResponse{Products: ProductsFromProto(res.GetProducts())}
+}
+
+func ProductHistoryFromProto(productID int64, res *pb.GetProductHistoryResponse) ProductHistoryResponse {
+	revisions := make([]ProductRevision, 0, len(res.GetRevisions()))
+	for _, r := range res.GetRevisions() {
+		revisions = append(revisions, ProductRevision{
+			ID:        r.GetId(),
+			Action:    r.GetAction(),
+			Actor:     r.GetActor(),
+			Changes:   json.RawMessage(r.GetChanges()),
+			CreatedAt: r.GetCreatedAt(),
+		})
+	}
+
+	return ProductHistoryResponse{ProductID: productID, Revisions: revisions}
+}
+
+func WarehouseFromProto(w *pb.Warehouse) Warehouse {
+	warehouse := Warehouse{
+		ID:       w.GetId(),
+		Code:     w.GetCode(),
+		Name:     w.GetName(),
+		Priority: w.GetPriority(),
+		IsActive: w.GetIsActive(),
+	}
+
+	if w.GetHasLocation() {
+		lat, lon := w.GetLatitude(), w.GetLongitude()
+		warehouse.Latitude, warehouse.Longitude = &lat, &lon
+	}
+
+	return warehouse
+}
+
+func WarehouseListFromProto(res *pb.ListWarehousesResponse) WarehouseListResponse {
+	warehouses := make([]Warehouse, 0, len(res.GetWarehouses()))
+	for _, w := range res.GetWarehouses() {
+		warehouses = append(warehouses, WarehouseFromProto(w))
+	}
+
+	return WarehouseListResponse{Warehouses: warehouses}
+}
+
+func ProductStockFromProto(productID int64, res *pb.GetProductStockResponse) ProductStockResponse {
+	stock := make([]WarehouseStock, 0, len(res.GetStock()))
+	for _, s := range res.GetStock() {
+		stock = append(stock, WarehouseStock{
+			WarehouseID:   s.GetWarehouseId(),
+			WarehouseCode: s.GetWarehouseCode(),
+			Quantity:      s.GetQuantity(),
+		})
+	}
+
+	return ProductStockResponse{ProductID: productID, Stock: stock}
+}
+
+// StockUpdate is a product's whole stock level, as WatchStock streams it.
+type StockUpdate struct {
+	ProductID     int64            `json:"product_id"`
+	StockQuantity int64            `json:"stock_quantity"`
+	Warehouses    []WarehouseStock `json:"warehouses"`
+}
+
+func StockUpdateFromProto(u *pb.StockUpdate) StockUpdate {
+	warehouses := make([]WarehouseStock, 0, len(u.GetWarehouses()))
+	for _, s := range u.GetWarehouses() {
+		warehouses = append(warehouses, WarehouseStock{
+			WarehouseID:   s.GetWarehouseId(),
+			WarehouseCode: s.GetWarehouseCode(),
+			Quantity:      s.GetQuantity(),
+		})
+	}
+
+	return StockUpdate{
+		ProductID:     u.GetProductId(),
+		StockQuantity: u.GetStockQuantity(),
+		Warehouses:    warehouses,
+	}
+}
+
+type Campaign struct {
+	ID              int64   `json:"id"`
+	Name            string  `json:"name"`
+	DiscountPercent int32   `json:"discount_percent"`
+	StartsAt        string  `json:"starts_at"`
+	EndsAt          string  `json:"ends_at"`
+	Status          string  `json:"status"`
+	ProductIDs      []int64 `json:"product_ids"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+type CampaignListResponse struct {
+	Campaigns []Campaign `json:"campaigns"`
+}
+
+func CampaignFromProto(c *pb.Campaign) Campaign {
+	return Campaign{
+		ID:              c.GetId(),
+		Name:            c.GetName(),
+		DiscountPercent: c.GetDiscountPercent(),
+		StartsAt:        c.GetStartsAt(),
+		EndsAt:          c.GetEndsAt(),
+		Status:          c.GetStatus(),
+		ProductIDs:      c.GetProductIds(),
+		CreatedAt:       c.GetCreatedAt(),
+	}
+}
+
+func CampaignListFromProto(res *pb.ListCampaignsResponse) CampaignListResponse {
+	campaigns := make([]Campaign, 0, len(res.GetCampaigns()))
+	for _, c := range res.GetCampaigns() {
+		campaigns = append(campaigns, CampaignFromProto(c))
+	}
+
+	return CampaignListResponse{Campaigns: campaigns}
+}
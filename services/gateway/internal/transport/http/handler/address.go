@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+// addressInput is an address book entry. The order service validates it;
+// the bounds here only keep oversized bodies from costing a gRPC call.
+type addressInput struct {
+	Label      string `json:"label" validate:"max=50"`
+	Recipient  string `json:"recipient" validate:"required,max=100"`
+	Phone      string `json:"phone" validate:"max=32"`
+	Line1      string `json:"line1" validate:"required,max=200"`
+	Line2      string `json:"line2" validate:"max=200"`
+	City       string `json:"city" validate:"required,max=100"`
+	Region     string `json:"region" validate:"max=100"`
+	PostalCode string `json:"postal_code" validate:"required,max=20"`
+	Country    string `json:"country" validate:"required,len=2"`
+}
+
+func (in *addressInput) toProto(id int64) *pb.Address {
+	return &pb.Address{
+		Id:         id,
+		Label:      in.Label,
+		Recipient:  in.Recipient,
+		Phone:      in.Phone,
+		Line1:      in.Line1,
+		Line2:      in.Line2,
+		City:       in.City,
+		Region:     in.Region,
+		PostalCode: in.PostalCode,
+		Country:    in.Country,
+	}
+}
+
+func (h *OrderHandler) parseAddress(c *fiber.Ctx) (*addressInput, error) {
+	var input addressInput
+	if err := c.BodyParser(&input); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	return &input, nil
+}
+
+func (h *OrderHandler) AddAddress(c *fiber.Ctx) error {
+	input, err := h.parseAddress(c)
+	if input == nil {
+		return err
+	}
+
+	return h.call(c, "add address", fiber.StatusCreated, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.AddAddress(ctx, &pb.AddAddressRequest{
+			UserId:  userId,
+			Address: input.toProto(0),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.AddressFromProto(res), nil
+	})
+}
+
+func (h *OrderHandler) ListAddresses(c *fiber.Ctx) error {
+	return h.call(c, "list addresses", fiber.StatusOK, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.ListAddresses(ctx, &pb.ListAddressesRequest{UserId: userId})
+		if err != nil {
+			return nil, err
+		}
+		return unpagedList(dto.AddressListFromProto(res), len(res.GetAddresses())), nil
+	})
+}
+
+func (h *OrderHandler) UpdateAddress(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	input, err := h.parseAddress(c)
+	if input == nil {
+		return err
+	}
+
+	return h.call(c, "update address", fiber.StatusOK, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.UpdateAddress(ctx, &pb.UpdateAddressRequest{
+			UserId:  userId,
+			Address: input.toProto(id),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.AddressFromProto(res), nil
+	})
+}
+
+func (h *OrderHandler) DeleteAddress(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	return h.call(c, "delete address", fiber.StatusOK, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.DeleteAddress(ctx, &pb.DeleteAddressRequest{
+			Id:     id,
+			UserId: userId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return fiber.Map{"success": res.GetSuccess()}, nil
+	})
+}
+
+// call runs an order RPC for the current user behind the circuit breaker.
+func (h *OrderHandler) call(c *fiber.Ctx, name string, successStatus int, rpc func(ctx context.Context, userId int64) (interface{}, error)) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	res, err := h.cb.Execute(func() (interface{}, error) {
+		return rpc(ctx, userId)
+	})
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"order request failed",
+			zap.String("request", name),
+			zap.Int64("user_id", userId),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return respond(c, successStatus, res)
+}
@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/analytics"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+type AnalyticsHandler struct {
+	client pb.AnalyticsServiceClient
+	logger *zap.Logger
+	cb     *gobreaker.CircuitBreaker
+}
+
+func NewAnalyticsHandler(client pb.AnalyticsServiceClient, logger *zap.Logger) *AnalyticsHandler {
+	settings := gobreaker.Settings{
+		Name:        "AnalyticsService",
+		MaxRequests: 3,
+		Interval:    5 * time.Second,
+		Timeout:     10 * time.Second,
+		ReadyToTrip: readyToTrip,
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			logger.Warn(
+				"Circuit breaker state changed",
+				zap.String("name", name),
+				zap.String("from", from.String()),
+				zap.String("to", to.String()),
+			)
+		},
+	}
+
+	return &AnalyticsHandler{
+		client: client,
+		logger: logger,
+		cb:     newBreaker(settings),
+	}
+}
+
+func (h *AnalyticsHandler) OrderVolume(c *fiber.Ctx) error {
+	return h.query(c, "order volume", func(ctx context.Context) (interface{}, error) {
+		res, err := h.client.GetOrderVolume(ctx, &pb.GetOrderVolumeRequest{Range: dateRange(c)})
+		if err != nil {
+			return nil, err
+		}
+		return dto.OrderVolumeFromProto(res), nil
+	})
+}
+
+func (h *AnalyticsHandler) Funnel(c *fiber.Ctx) error {
+	return h.query(c, "funnel", func(ctx context.Context) (interface{}, error) {
+		res, err := h.client.GetFunnel(ctx, &pb.GetFunnelRequest{Range: dateRange(c)})
+		if err != nil {
+			return nil, err
+		}
+		return dto.FunnelFromProto(res), nil
+	})
+}
+
+func (h *AnalyticsHandler) TopProducts(c *fiber.Ctx) error {
+	limit, err := optionalInt64(c.Query("limit"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit is invalid",
+		})
+	}
+
+	return h.query(c, "top products", func(ctx context.Context) (interface{}, error) {
+		res, err := h.client.GetTopProducts(ctx, &pb.GetTopProductsRequest{
+			Range: dateRange(c),
+			Limit: limit,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.TopProductsFromProto(res), nil
+	})
+}
+
+// query runs an analytics RPC behind the circuit breaker. Aggregations can
+// scan a year of data, so the timeout is looser than for regular reads.
+func (h *AnalyticsHandler) query(c *fiber.Ctx, name string, call func(ctx context.Context) (interface{}, error)) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.cb.Execute(func() (interface{}, error) {
+		return call(ctx)
+	})
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Error(ctx, h.logger, "Circuit breaker is open, request blocked")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"analytics query failed",
+			zap.String("query", name),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func dateRange(c *fiber.Ctx) *pb.DateRange {
+	return &pb.DateRange{
+		From: c.Query("from"),
+		To:   c.Query("to"),
+	}
+}
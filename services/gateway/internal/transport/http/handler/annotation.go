@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+type AddOrderNoteInput struct {
+	Body string `json:"body" validate:"required,max=2000"`
+}
+
+type SetOrderFlagInput struct {
+	Comment string `json:"comment" validate:"max=500"`
+}
+
+// annotationTarget reads the order id of an annotation route and the admin
+// calling it.
+func annotationTarget(c *fiber.Ctx) (orderID, adminID int64, err *fiber.Error) {
+	orderID, parseErr := strconv.ParseInt(c.Params("id"), 10, 64)
+	if parseErr != nil || orderID <= 0 {
+		return 0, 0, fiber.NewError(fiber.StatusBadRequest, "Id is invalid")
+	}
+
+	adminID, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return 0, 0, fiber.NewError(fiber.StatusUnauthorized, "userId parsing error")
+	}
+
+	return orderID, adminID, nil
+}
+
+// AddOrderNote and the other annotation handlers are mounted under /admin.
+func (h *OrderHandler) AddOrderNote(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	orderID, adminID, targetErr := annotationTarget(c)
+	if targetErr != nil {
+		return c.Status(targetErr.Code).JSON(fiber.Map{"error": targetErr.Message})
+	}
+
+	input := new(AddOrderNoteInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.OrderAnnotation](h.cb, func() (*pb.OrderAnnotation, error) {
+		return h.client.AddOrderNote(ctx, &pb.AddOrderNoteRequest{
+			AdminId: adminID,
+			OrderId: orderID,
+			Body:    input.Body,
+		})
+	})
+
+	if err != nil {
+		return h.orderCallFailed(ctx, c, "add order note failed", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.OrderAnnotationFromProto(res))
+}
+
+func (h *OrderHandler) SetOrderFlag(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	orderID, adminID, targetErr := annotationTarget(c)
+	if targetErr != nil {
+		return c.Status(targetErr.Code).JSON(fiber.Map{"error": targetErr.Message})
+	}
+
+	// The body is optional: a flag needs no comment.
+	input := new(SetOrderFlagInput)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "error parsing body",
+			})
+		}
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.OrderAnnotation](h.cb, func() (*pb.OrderAnnotation, error) {
+		return h.client.SetOrderFlag(ctx, &pb.SetOrderFlagRequest{
+			AdminId: adminID,
+			OrderId: orderID,
+			Flag:    c.Params("flag"),
+			Comment: input.Comment,
+		})
+	})
+
+	if err != nil {
+		return h.orderCallFailed(ctx, c, "set order flag failed", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.OrderAnnotationFromProto(res))
+}
+
+func (h *OrderHandler) ClearOrderFlag(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	orderID, adminID, targetErr := annotationTarget(c)
+	if targetErr != nil {
+		return c.Status(targetErr.Code).JSON(fiber.Map{"error": targetErr.Message})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.OrderAnnotation](h.cb, func() (*pb.OrderAnnotation, error) {
+		return h.client.ClearOrderFlag(ctx, &pb.ClearOrderFlagRequest{
+			AdminId: adminID,
+			OrderId: orderID,
+			Flag:    c.Params("flag"),
+		})
+	})
+
+	if err != nil {
+		return h.orderCallFailed(ctx, c, "clear order flag failed", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.OrderAnnotationFromProto(res))
+}
+
+func (h *OrderHandler) ListOrderAnnotations(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	orderID, adminID, targetErr := annotationTarget(c)
+	if targetErr != nil {
+		return c.Status(targetErr.Code).JSON(fiber.Map{"error": targetErr.Message})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.ListOrderAnnotationsResponse](h.cb, func() (*pb.ListOrderAnnotationsResponse, error) {
+		return h.client.ListOrderAnnotations(ctx, &pb.ListOrderAnnotationsRequest{
+			AdminId: adminID,
+			OrderId: orderID,
+		})
+	})
+
+	if err != nil {
+		return h.orderCallFailed(ctx, c, "list order annotations failed", err)
+	}
+
+	return sendList(c, unpagedList(dto.OrderAnnotationsFromProto(res), len(res.GetAnnotations())))
+}
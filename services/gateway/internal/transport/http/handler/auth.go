@@ -7,35 +7,47 @@ import (
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/gateway/middleware"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	"github.com/sakashimaa/go-pet-project/pkg/utils"
 	pb "github.com/sakashimaa/go-pet-project/proto/auth"
 	"github.com/sony/gobreaker"
 	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
 )
 
+const forgotPasswordMinDuration = 500 * time.Millisecond
+
 type AuthHandler struct {
 	client   pb.AuthServiceClient
 	validate *validator.Validate
 	cb       *gobreaker.CircuitBreaker
 	logger   *zap.Logger
+	// cookies is nil unless tokens are delivered as cookies.
+	cookies *SessionCookies
 }
 
 type RegisterInput struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=3"`
+	// Locale defaults to the language negotiated from Accept-Language.
+	Locale string `json:"locale"`
+}
+
+type UpdateLocaleInput struct {
+	Locale string `json:"locale"`
 }
 
-func NewAuthHandler(client pb.AuthServiceClient, logger *zap.Logger) *AuthHandler {
+func NewAuthHandler(client pb.AuthServiceClient, logger *zap.Logger, opts ...AuthOption) *AuthHandler {
 	settings := gobreaker.Settings{
 		Name:        "AuthService",
 		MaxRequests: 3,
 		Interval:    5 * time.Second,
 		Timeout:     10 * time.Second,
-		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
-			return counts.Requests >= 5 && failureRatio >= 0.6
-		},
+		ReadyToTrip: readyToTrip,
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			logger.Warn(
 				"Circuit breaker state changed",
@@ -46,19 +58,25 @@ func NewAuthHandler(client pb.AuthServiceClient, logger *zap.Logger) *AuthHandle
 		},
 	}
 
-	return &AuthHandler{
+	h := &AuthHandler{
 		client:   client,
 		validate: validator.New(),
-		cb:       gobreaker.NewCircuitBreaker(settings),
+		cb:       newBreaker(settings),
 		logger:   logger,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 func (h *AuthHandler) GetMe(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
 	defer cancel()
 
-	userId, ok := c.Locals("userId").(int64)
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
 	if !ok {
 		mylogger.Info(
 			ctx,
@@ -96,19 +114,63 @@ func (h *AuthHandler) GetMe(c *fiber.Ctx) error {
 		return c.Status(httpCode).JSON(fiber.Map{"error": err.Error()})
 	}
 
+	return c.JSON(dto.UserFromProto(userId, res))
+}
+
+func (h *AuthHandler) UpdateLocale(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	var input UpdateLocaleInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "error parsing body"})
+	}
+
+	if input.Locale == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "locale is required"})
+	}
+
+	_, err := utils.ExecuteWithBreaker[*pb.UpdateLocaleResponse](h.cb, func() (*pb.UpdateLocaleResponse, error) {
+		return h.client.UpdateLocale(ctx, &pb.UpdateLocaleRequest{UserId: userId, Locale: input.Locale})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker is open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"update locale failed",
+			zap.Int("http_code", httpCode),
+			zap.Int64("user_id", userId),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{"error": err.Error()})
+	}
+
 	mylogger.Info(
 		ctx,
 		h.logger,
-		"get me succeeded",
+		"locale updated",
 		zap.Int64("user_id", userId),
-		zap.String("email", res.Email),
+		zap.String("locale", input.Locale),
 	)
 
-	return c.JSON(fiber.Map{
-		"id":           userId,
-		"email":        res.Email,
-		"is_activated": res.IsActivated,
-	})
+	return c.JSON(fiber.Map{"locale": input.Locale})
 }
 
 func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
@@ -171,13 +233,6 @@ func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
 		return c.Status(httpCode).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"reset password succeeded",
-		zap.String("token", req.Token),
-	)
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": res.Success})
 }
 
@@ -213,6 +268,10 @@ func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
 		})
 	}
 
+	// Reply no sooner than forgotPasswordMinDuration whatever the outcome, so
+	// response times do not tell registered emails from unknown ones.
+	defer padResponse(time.Now(), forgotPasswordMinDuration)
+
 	res, err := utils.ExecuteWithBreaker[*pb.ForgotPasswordResponse](h.cb, func() (*pb.ForgotPasswordResponse, error) {
 		return h.client.ForgotPassword(ctx, req)
 	})
@@ -243,13 +302,6 @@ func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
 		return c.Status(httpCode).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"forgot password succeeded",
-		zap.String("email", req.Email),
-	)
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": res.Success,
 		"message": res.Message,
@@ -311,13 +363,6 @@ func (h *AuthHandler) Activate(c *fiber.Ctx) error {
 		})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"activate succeeded",
-		zap.Bool("success", res.Success),
-	)
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": res.Success,
 	})
@@ -329,19 +374,23 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 
 	req := new(pb.LogoutRequest)
 
-	if err := c.BodyParser(req); err != nil {
-		mylogger.Warn(
-			ctx,
-			h.logger,
-			"body parsing error",
-			zap.Error(err),
-		)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			mylogger.Warn(
+				ctx,
+				h.logger,
+				"body parsing error",
+				zap.Error(err),
+			)
 
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Cannot parse JSON",
-		})
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Cannot parse JSON",
+			})
+		}
 	}
 
+	req.RefreshToken = h.refreshTokenFrom(c, req.RefreshToken)
+
 	if req.RefreshToken == "" {
 		mylogger.Warn(
 			ctx,
@@ -387,12 +436,7 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"logout succeeded",
-		zap.Bool("success", res.Success),
-	)
+	h.clearSession(c)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": res.Success,
@@ -400,24 +444,28 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 }
 
 func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
 	defer cancel()
 
 	req := new(pb.RefreshRequest)
 
-	if err := c.BodyParser(req); err != nil {
-		mylogger.Warn(
-			ctx,
-			h.logger,
-			"body parsing error",
-			zap.Error(err),
-		)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(req); err != nil {
+			mylogger.Warn(
+				ctx,
+				h.logger,
+				"body parsing error",
+				zap.Error(err),
+			)
 
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Cannot parse JSON",
-		})
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Cannot parse JSON",
+			})
+		}
 	}
 
+	req.RefreshToken = h.refreshTokenFrom(c, req.RefreshToken)
+
 	if req.RefreshToken == "" {
 		mylogger.Warn(
 			ctx,
@@ -463,10 +511,7 @@ func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"refresh_token": res.RefreshToken,
-		"access_token":  res.AccessToken,
-	})
+	return h.issueSession(c, res.AccessToken, res.RefreshToken)
 }
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
@@ -502,9 +547,15 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	}
 
 	res, err := utils.ExecuteWithBreaker[*pb.RegisterResponse](h.cb, func() (*pb.RegisterResponse, error) {
+		locale := input.Locale
+		if locale == "" {
+			locale = middleware.Locale(c)
+		}
+
 		req := pb.RegisterRequest{
 			Email:    input.Email,
 			Password: input.Password,
+			Locale:   locale,
 		}
 
 		return h.client.Register(ctx, &req)
@@ -534,19 +585,49 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 			zap.Error(err),
 		)
 
+		if reason, msg, ok := emailDomainRejection(err); ok {
+			return c.Status(httpCode).JSON(fiber.Map{
+				"error":  msg,
+				"reason": reason,
+			})
+		}
+
 		return c.Status(httpCode).JSON(fiber.Map{
 			"error": err.Error(),
 		})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"register user succeeded",
-		zap.Int64("created_id", res.Id),
-	)
+	return c.Status(fiber.StatusCreated).JSON(dto.RegisterResponseFromProto(res))
+}
 
-	return c.Status(fiber.StatusCreated).JSON(res)
+// emailDomainMessages explain the ErrorInfo reasons auth rejects signup
+// email domains with.
+var emailDomainMessages = map[string]string{
+	"EMAIL_DOMAIN_DISPOSABLE":  "Disposable email addresses are not accepted, please use a permanent one",
+	"EMAIL_DOMAIN_DENIED":      "This email domain is not accepted, please use another address",
+	"EMAIL_DOMAIN_NOT_ALLOWED": "Please sign up with your work email address",
+}
+
+// emailDomainRejection returns the reason and the message to show when err
+// rejected the signup email's domain.
+func emailDomainRejection(err error) (string, string, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return "", "", false
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+
+		if msg, ok := emailDomainMessages[info.GetReason()]; ok {
+			return info.GetReason(), msg, true
+		}
+	}
+
+	return "", "", false
 }
 
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
@@ -600,5 +681,9 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(res)
+	return h.issueSession(c, res.AccessToken, res.RefreshToken)
+}
+
+func padResponse(start time.Time, min time.Duration) {
+	time.Sleep(min - time.Since(start))
 }
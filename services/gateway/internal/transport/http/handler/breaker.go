@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sony/gobreaker"
+)
+
+// Every handler's breaker trips on the same thresholds, which can be tuned
+// at runtime with SetBreakerThresholds.
+var (
+	breakerMinRequests  atomic.Uint32
+	breakerFailureRatio atomic.Uint64
+)
+
+func init() {
+	SetBreakerThresholds(5, 0.6)
+}
+
+// SetBreakerThresholds makes breakers trip after minRequests requests in
+// their interval of which at least failureRatio failed. Open breakers keep
+// their state; the thresholds apply from the next evaluation.
+func SetBreakerThresholds(minRequests uint32, failureRatio float64) {
+	breakerMinRequests.Store(minRequests)
+	breakerFailureRatio.Store(math.Float64bits(failureRatio))
+}
+
+func readyToTrip(counts gobreaker.Counts) bool {
+	failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+	return counts.Requests >= breakerMinRequests.Load() &&
+		failureRatio >= math.Float64frombits(breakerFailureRatio.Load())
+}
+
+var breakers sync.Map // name -> *gobreaker.CircuitBreaker
+
+// newBreaker creates a breaker and keeps it for the admin status endpoints.
+// A later breaker of the same name replaces the earlier one.
+func newBreaker(settings gobreaker.Settings) *gobreaker.CircuitBreaker {
+	cb := gobreaker.NewCircuitBreaker(settings)
+	breakers.Store(settings.Name, cb)
+
+	return cb
+}
+
+// BreakerState is a snapshot of one breaker.
+type BreakerState struct {
+	Name                 string `json:"name"`
+	State                string `json:"state"`
+	Requests             uint32 `json:"requests"`
+	TotalFailures        uint32 `json:"total_failures"`
+	ConsecutiveFailures  uint32 `json:"consecutive_failures"`
+	ConsecutiveSuccesses uint32 `json:"consecutive_successes"`
+}
+
+// BreakerStates lists the gateway's breakers by name. The counts cover the
+// breaker's current interval only.
+func BreakerStates() []BreakerState {
+	states := []BreakerState{}
+	breakers.Range(func(_, value any) bool {
+		cb := value.(*gobreaker.CircuitBreaker)
+		counts := cb.Counts()
+		states = append(states, BreakerState{
+			Name:                 cb.Name(),
+			State:                cb.State().String(),
+			Requests:             counts.Requests,
+			TotalFailures:        counts.TotalFailures,
+			ConsecutiveFailures:  counts.ConsecutiveFailures,
+			ConsecutiveSuccesses: counts.ConsecutiveSuccesses,
+		})
+		return true
+	})
+
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+
+	return states
+}
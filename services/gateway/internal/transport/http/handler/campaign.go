@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+// CreateCampaignInput discounts ProductIDs by DiscountPercent from StartsAt
+// until EndsAt.
+type CreateCampaignInput struct {
+	Name            string    `json:"name" validate:"required,min=3,max=100"`
+	DiscountPercent int32     `json:"discount_percent" validate:"required,gte=1,lte=99"`
+	StartsAt        time.Time `json:"starts_at" validate:"required"`
+	EndsAt          time.Time `json:"ends_at" validate:"required,gtfield=StartsAt"`
+	ProductIDs      []int64   `json:"product_ids" validate:"required,min=1,max=500,dive,gt=0"`
+}
+
+func (h *ProductHandler) CreateCampaign(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	input := new(CreateCampaignInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.Campaign](h.cb, func() (*pb.Campaign, error) {
+		return h.client.CreateCampaign(ctx, &pb.CreateCampaignRequest{
+			Name:            input.Name,
+			DiscountPercent: input.DiscountPercent,
+			StartsAt:        input.StartsAt.Format(time.RFC3339),
+			EndsAt:          input.EndsAt.Format(time.RFC3339),
+			ProductIds:      input.ProductIDs,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"create campaign failed",
+			zap.String("name", input.Name),
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	mylogger.Info(ctx, h.logger, "campaign created", zap.Int64("campaign_id", res.Id))
+
+	return c.Status(fiber.StatusCreated).JSON(dto.CampaignFromProto(res))
+}
+
+// ListCampaigns lists upcoming and running campaigns, and ended ones too
+// with ?include_ended=true.
+func (h *ProductHandler) ListCampaigns(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	page, err := query.ParsePage(c.Query("limit"), c.Query("offset"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit or offset is invalid",
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.ListCampaignsResponse](h.cb, func() (*pb.ListCampaignsResponse, error) {
+		return h.client.ListCampaigns(ctx, &pb.ListCampaignsRequest{
+			IncludeEnded: c.QueryBool("include_ended", false),
+			Limit:        page.Limit,
+			Offset:       page.Offset,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"list campaigns failed",
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return sendList(c, List{
+		Body:  dto.CampaignListFromProto(res),
+		Page:  page,
+		Count: len(res.GetCampaigns()),
+		Total: -1,
+	})
+}
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/protobuf/proto"
+)
+
+// coalescedCallTimeout bounds the shared upstream call. It is detached from
+// the caller that started it so one client hanging up does not fail everyone
+// else waiting on the same key.
+const coalescedCallTimeout = time.Second
+
+// coalesce runs fn once per key and tenant for all concurrent callers.
+// Callers whose own context ends stop waiting; the shared call keeps running
+// for the rest.
+func coalesce(ctx context.Context, group *singleflight.Group, key string, fn func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	ch := group.DoChan(tenant.FromContext(ctx)+"/"+key, func() (interface{}, error) {
+		callCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), coalescedCallTimeout)
+		defer cancel()
+
+		return fn(callCtx)
+	})
+
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// requestKey identifies a request by its deterministic wire encoding.
+func requestKey(prefix string, req proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + string(b), nil
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/gateway/middleware"
+)
+
+// Cookie lifetimes follow the token lifetimes issued by auth.
+const (
+	accessTokenCookieTTL  = 15 * time.Minute
+	refreshTokenCookieTTL = 30 * 24 * time.Hour
+)
+
+// SessionCookies configures delivering tokens as cookies, so browsers never
+// expose them to scripts the way a JSON body kept in localStorage does.
+type SessionCookies struct {
+	// AccessToken also moves the access token into a cookie; otherwise it
+	// stays in the body and only the refresh token is a cookie.
+	AccessToken bool
+	Domain      string
+	Secure      bool
+	// SameSite is "Strict", "Lax" or "None".
+	SameSite string
+}
+
+type AuthOption func(*AuthHandler)
+
+// WithSessionCookies switches login, refresh and logout to cookie mode.
+func WithSessionCookies(cfg SessionCookies) AuthOption {
+	return func(h *AuthHandler) {
+		h.cookies = &cfg
+	}
+}
+
+// issueSession answers a successful login or refresh. In cookie mode the
+// tokens go into HttpOnly cookies alongside a fresh CSRF token.
+func (h *AuthHandler) issueSession(c *fiber.Ctx, accessToken, refreshToken string) error {
+	if h.cookies == nil {
+		return c.Status(fiber.StatusOK).JSON(dto.TokenResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+		})
+	}
+
+	csrfToken, err := middleware.NewCSRFToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	// The refresh cookie is only ever needed by /auth, so it is not sent
+	// along with every API call.
+	c.Cookie(h.cookie(middleware.RefreshTokenCookie, refreshToken, "/auth", refreshTokenCookieTTL, true))
+	c.Cookie(h.cookie(middleware.CSRFCookie, csrfToken, "/", refreshTokenCookieTTL, false))
+
+	res := dto.CookieSessionResponse{CSRFToken: csrfToken}
+	if h.cookies.AccessToken {
+		c.Cookie(h.cookie(middleware.AccessTokenCookie, accessToken, "/", accessTokenCookieTTL, true))
+	} else {
+		res.AccessToken = accessToken
+	}
+
+	return c.Status(fiber.StatusOK).JSON(res)
+}
+
+func (h *AuthHandler) clearSession(c *fiber.Ctx) {
+	if h.cookies == nil {
+		return
+	}
+
+	c.Cookie(h.cookie(middleware.RefreshTokenCookie, "", "/auth", -time.Hour, true))
+	c.Cookie(h.cookie(middleware.AccessTokenCookie, "", "/", -time.Hour, true))
+	c.Cookie(h.cookie(middleware.CSRFCookie, "", "/", -time.Hour, false))
+}
+
+// refreshTokenFrom reads the refresh token from the body or, in cookie mode,
+// the refresh cookie. The /auth routes run behind NewCSRFMiddleware, which
+// has already checked cookie requests.
+func (h *AuthHandler) refreshTokenFrom(c *fiber.Ctx, body string) string {
+	if body != "" || h.cookies == nil {
+		return body
+	}
+
+	return c.Cookies(middleware.RefreshTokenCookie)
+}
+
+func (h *AuthHandler) cookie(name, value, path string, ttl time.Duration, httpOnly bool) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   h.cookies.Domain,
+		MaxAge:   int(ttl.Seconds()),
+		Expires:  time.Now().Add(ttl),
+		Secure:   h.cookies.Secure,
+		HTTPOnly: httpOnly,
+		SameSite: h.cookies.SameSite,
+	}
+}
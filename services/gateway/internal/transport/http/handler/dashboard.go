@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	authPb "github.com/sakashimaa/go-pet-project/proto/auth"
+	notificationPb "github.com/sakashimaa/go-pet-project/proto/notification"
+	orderPb "github.com/sakashimaa/go-pet-project/proto/order"
+	"github.com/sony/gobreaker"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const dashboardRecentOrders = 5
+
+// DashboardHandler aggregates the data a client needs on its home screen.
+// It reuses the per-service handlers so their circuit breakers are shared
+// with the regular routes.
+type DashboardHandler struct {
+	auth         *AuthHandler
+	order        *OrderHandler
+	notification *NotificationHandler
+	logger       *zap.Logger
+	tracer       trace.Tracer
+}
+
+func NewDashboardHandler(auth *AuthHandler, order *OrderHandler, notification *NotificationHandler, logger *zap.Logger) *DashboardHandler {
+	return &DashboardHandler{
+		auth:         auth,
+		order:        order,
+		notification: notification,
+		logger:       logger,
+		tracer:       otel.Tracer("gateway_dashboard"),
+	}
+}
+
+// Get fans out to auth, order and notification in parallel. A failing
+// section is reported under "errors" and left null instead of failing the
+// whole request; only when every section fails does it answer 503.
+func (h *DashboardHandler) Get(c *fiber.Ctx) error {
+	ctx, span := h.tracer.Start(c.UserContext(), "Gateway.Dashboard")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	span.SetAttributes(attribute.Int64("user_id", userId))
+
+	var (
+		profile *authPb.UserInfoResponse
+		orders  *orderPb.ListUserOrdersResponse
+		unread  *notificationPb.GetUnreadCountResponse
+
+		profileErr, ordersErr, unreadErr error
+	)
+
+	// Sections never return their error to the group: one slow or broken
+	// service must not cancel the others.
+	var g errgroup.Group
+	g.Go(func() error {
+		profile, profileErr = utils.ExecuteWithBreaker[*authPb.UserInfoResponse](h.auth.cb, func() (*authPb.UserInfoResponse, error) {
+			return h.auth.client.GetUserInfo(ctx, &authPb.UserInfoRequest{UserId: userId})
+		})
+		return nil
+	})
+	g.Go(func() error {
+		orders, ordersErr = utils.ExecuteWithBreaker[*orderPb.ListUserOrdersResponse](h.order.cb, func() (*orderPb.ListUserOrdersResponse, error) {
+			return h.order.client.ListUserOrders(ctx, &orderPb.ListUserOrdersRequest{UserId: userId, Limit: dashboardRecentOrders})
+		})
+		return nil
+	})
+	g.Go(func() error {
+		unread, unreadErr = utils.ExecuteWithBreaker[*notificationPb.GetUnreadCountResponse](h.notification.cb, func() (*notificationPb.GetUnreadCountResponse, error) {
+			return h.notification.client.GetUnreadCount(ctx, &notificationPb.GetUnreadCountRequest{UserId: userId})
+		})
+		return nil
+	})
+	_ = g.Wait()
+
+	res := fiber.Map{
+		"profile":              nil,
+		"recent_orders":        nil,
+		"unread_notifications": nil,
+	}
+	sectionErrors := fiber.Map{}
+
+	if h.section(ctx, span, sectionErrors, "profile", profileErr) {
+		res["profile"] = dto.UserFromProto(userId, profile)
+	}
+
+	if h.section(ctx, span, sectionErrors, "recent_orders", ordersErr) {
+		recent := make([]fiber.Map, 0, len(orders.Orders))
+		for _, order := range orders.Orders {
+			recent = append(recent, fiber.Map{
+				"id":           order.OrderId,
+				"status":       order.Status,
+				"total_sum":    order.TotalSum,
+				"delivery_fee": order.DeliveryFee,
+				"created_at":   order.CreatedAt,
+			})
+		}
+		res["recent_orders"] = recent
+	}
+
+	if h.section(ctx, span, sectionErrors, "unread_notifications", unreadErr) {
+		res["unread_notifications"] = unread.Count
+	}
+
+	if len(sectionErrors) == 0 {
+		return c.JSON(res)
+	}
+
+	res["errors"] = sectionErrors
+	if len(sectionErrors) == 3 {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(res)
+	}
+
+	return c.JSON(res)
+}
+
+// section reports whether a section loaded, recording why it did not.
+func (h *DashboardHandler) section(ctx context.Context, span trace.Span, sectionErrors fiber.Map, name string, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	span.RecordError(err)
+
+	mylogger.Warn(
+		ctx,
+		h.logger,
+		"dashboard section failed",
+		zap.String("section", name),
+		zap.Error(err),
+	)
+
+	sectionErrors[name] = sectionError(err)
+
+	return false
+}
+
+// sectionError is what a client is told about a section that did not load.
+func sectionError(err error) string {
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return "service temporarily unavailable"
+	}
+
+	return err.Error()
+}
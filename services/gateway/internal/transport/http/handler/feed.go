@@ -0,0 +1,280 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	feedBlockSize = 12
+	// feedLowStock is the most units a product can have left to be shown as
+	// almost gone.
+	feedLowStock = 5
+	// feedStaleTTL is how long the last good copy of a block is kept to fall
+	// back on once the block cannot be loaded.
+	feedStaleTTL = 24 * time.Hour
+)
+
+type feedLoader func(ctx context.Context, userID int64) ([]dto.Product, error)
+
+// feedBlock is one section of the home feed.
+type feedBlock struct {
+	name string
+	// ttl is how long a loaded block is served from the cache.
+	ttl time.Duration
+	// perUser caches the block per user rather than per tenant.
+	perUser bool
+	load    feedLoader
+	// fallback, when set, stands in for load while it fails.
+	fallback feedLoader
+}
+
+// FeedHandler composes the home feed out of product blocks. It reuses the
+// product handler so the circuit breaker and request coalescing are shared
+// with the catalog routes.
+type FeedHandler struct {
+	product *ProductHandler
+	cache   fiber.Storage
+	blocks  []feedBlock
+	logger  *zap.Logger
+	tracer  trace.Tracer
+}
+
+func NewFeedHandler(product *ProductHandler, cache fiber.Storage, logger *zap.Logger) *FeedHandler {
+	h := &FeedHandler{
+		product: product,
+		cache:   cache,
+		logger:  logger,
+		tracer:  otel.Tracer("gateway_feed"),
+	}
+
+	h.blocks = []feedBlock{
+		{
+			name: "new_arrivals",
+			ttl:  5 * time.Minute,
+			load: h.listProducts(&pb.ListProductsRequest{
+				Limit:       feedBlockSize,
+				InStockOnly: true,
+				Sort:        pb.ProductSort_PRODUCT_SORT_NEWEST,
+			}),
+		},
+		{
+			name:    "recommended",
+			ttl:     time.Minute,
+			perUser: true,
+			load:    h.personalized,
+			// The best rated products are what a user without history gets
+			// anyway.
+			fallback: h.listProducts(&pb.ListProductsRequest{
+				Limit:       feedBlockSize,
+				InStockOnly: true,
+				Sort:        pb.ProductSort_PRODUCT_SORT_RATING,
+			}),
+		},
+		{
+			name: "promotions",
+			ttl:  time.Minute,
+			load: h.promoted,
+		},
+		{
+			name: "almost_gone",
+			ttl:  30 * time.Second,
+			load: h.listProducts(&pb.ListProductsRequest{
+				Limit:       feedBlockSize,
+				InStockOnly: true,
+				StockMax:    feedLowStock,
+				Sort:        pb.ProductSort_PRODUCT_SORT_STOCK_ASC,
+			}),
+		},
+	}
+
+	return h
+}
+
+// Get loads every block in parallel. A block that cannot be loaded falls
+// back to its substitute or its last good copy, and is otherwise left out
+// and reported under "errors"; only when no block loads does it answer 503.
+func (h *FeedHandler) Get(c *fiber.Ctx) error {
+	ctx, span := h.tracer.Start(c.UserContext(), "Gateway.Feed")
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	span.SetAttributes(attribute.Int64("user_id", userId))
+
+	loaded := make([]*dto.FeedBlock, len(h.blocks))
+	errs := make([]error, len(h.blocks))
+
+	// Blocks never return their error to the group: one failing block must
+	// not cancel the others.
+	var g errgroup.Group
+	for i, block := range h.blocks {
+		g.Go(func() error {
+			loaded[i], errs[i] = h.loadBlock(ctx, block, userId)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	res := dto.FeedResponse{Blocks: make([]dto.FeedBlock, 0, len(h.blocks))}
+	for i, block := range h.blocks {
+		if errs[i] != nil {
+			span.RecordError(errs[i])
+
+			if res.Errors == nil {
+				res.Errors = make(map[string]string)
+			}
+			res.Errors[block.name] = sectionError(errs[i])
+			continue
+		}
+
+		res.Blocks = append(res.Blocks, *loaded[i])
+	}
+
+	if len(res.Blocks) == 0 {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(res)
+	}
+
+	return c.JSON(res)
+}
+
+// loadBlock serves block from the cache, then from its loader, then from its
+// fallback and last from its last good copy.
+func (h *FeedHandler) loadBlock(ctx context.Context, block feedBlock, userID int64) (*dto.FeedBlock, error) {
+	key := tenant.FromContext(ctx) + ":" + block.name
+	if block.perUser {
+		key += fmt.Sprintf(":%d", userID)
+	}
+
+	if products, ok := h.cached(ctx, key); ok {
+		return &dto.FeedBlock{Name: block.name, Products: products}, nil
+	}
+
+	products, err := block.load(ctx, userID)
+	if err == nil {
+		h.store(ctx, key, products, block.ttl)
+		h.store(ctx, "stale:"+key, products, feedStaleTTL)
+
+		return &dto.FeedBlock{Name: block.name, Products: products}, nil
+	}
+
+	mylogger.Warn(ctx, h.logger, "feed block failed", zap.String("block", block.name), zap.Error(err))
+
+	if block.fallback != nil {
+		fallback, fallbackErr := block.fallback(ctx, userID)
+		if fallbackErr == nil {
+			return &dto.FeedBlock{Name: block.name, Products: fallback, Fallback: true}, nil
+		}
+
+		mylogger.Warn(ctx, h.logger, "feed block fallback failed", zap.String("block", block.name), zap.Error(fallbackErr))
+	}
+
+	if stale, ok := h.cached(ctx, "stale:"+key); ok {
+		return &dto.FeedBlock{Name: block.name, Products: stale, Fallback: true}, nil
+	}
+
+	return nil, err
+}
+
+// listProducts loads a catalog listing, coalesced with identical listings
+// requested through the catalog routes.
+func (h *FeedHandler) listProducts(req *pb.ListProductsRequest) feedLoader {
+	return func(ctx context.Context, _ int64) ([]dto.Product, error) {
+		key, err := requestKey("list:", req)
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := coalesce(ctx, &h.product.reads, key, func(ctx context.Context) (interface{}, error) {
+			return h.product.cb.Execute(func() (interface{}, error) {
+				return h.product.client.ListProducts(ctx, req)
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		list, ok := res.(*pb.ListProductsResponse)
+		if !ok {
+			return nil, fmt.Errorf("unexpected response type %T", res)
+		}
+
+		return dto.ProductsFromProto(list.GetProducts()), nil
+	}
+}
+
+func (h *FeedHandler) personalized(ctx context.Context, userID int64) ([]dto.Product, error) {
+	res, err := utils.ExecuteWithBreaker[*pb.GetPersonalizedProductsResponse](h.product.cb, func() (*pb.GetPersonalizedProductsResponse, error) {
+		return h.product.client.GetPersonalizedProducts(ctx, &pb.GetPersonalizedProductsRequest{
+			UserId: userID,
+			Limit:  feedBlockSize,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ProductsFromProto(res.GetProducts()), nil
+}
+
+func (h *FeedHandler) promoted(ctx context.Context, _ int64) ([]dto.Product, error) {
+	res, err := utils.ExecuteWithBreaker[*pb.ListProductsResponse](h.product.cb, func() (*pb.ListProductsResponse, error) {
+		return h.product.client.ListPromotedProducts(ctx, &pb.ListPromotedProductsRequest{Limit: feedBlockSize})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dto.ProductsFromProto(res.GetProducts()), nil
+}
+
+// cached reads a block from the cache. A cache that fails reads as a miss.
+func (h *FeedHandler) cached(ctx context.Context, key string) ([]dto.Product, bool) {
+	val, err := h.cache.Get(key)
+	if err != nil {
+		mylogger.Warn(ctx, h.logger, "feed cache read failed", zap.String("key", key), zap.Error(err))
+		return nil, false
+	}
+	if val == nil {
+		return nil, false
+	}
+
+	var products []dto.Product
+	if err := json.Unmarshal(val, &products); err != nil {
+		return nil, false
+	}
+
+	return products, true
+}
+
+func (h *FeedHandler) store(ctx context.Context, key string, products []dto.Product, ttl time.Duration) {
+	val, err := json.Marshal(products)
+	if err != nil {
+		return
+	}
+
+	if err := h.cache.Set(key, val, ttl); err != nil {
+		mylogger.Warn(ctx, h.logger, "feed cache write failed", zap.String("key", key), zap.Error(err))
+	}
+}
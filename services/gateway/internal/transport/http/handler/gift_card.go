@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	pb "github.com/sakashimaa/go-pet-project/proto/payment"
+)
+
+type claimGiftCardInput struct {
+	Code string `json:"code"`
+}
+
+type issueGiftCardInput struct {
+	Amount    int64 `json:"amount"`
+	ValidDays int32 `json:"valid_days"`
+}
+
+func (h *PaymentHandler) ClaimGiftCard(c *fiber.Ctx) error {
+	var input claimGiftCardInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	return h.call(c, "claim gift card", fiber.StatusOK, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.ClaimGiftCard(ctx, &pb.ClaimGiftCardRequest{
+			UserId: userId,
+			Code:   input.Code,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.GiftCardFromProto(res), nil
+	})
+}
+
+func (h *PaymentHandler) ListGiftCards(c *fiber.Ctx) error {
+	return h.call(c, "list gift cards", fiber.StatusOK, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.ListGiftCards(ctx, &pb.ListGiftCardsRequest{UserId: userId})
+		if err != nil {
+			return nil, err
+		}
+		return unpagedList(dto.GiftCardListFromProto(res), len(res.GetGiftCards())), nil
+	})
+}
+
+// IssueGiftCard is mounted under /admin; the code in the response is not
+// shown again, so it has to be handed to the customer from here.
+func (h *PaymentHandler) IssueGiftCard(c *fiber.Ctx) error {
+	var input issueGiftCardInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	return h.call(c, "issue gift card", fiber.StatusCreated, func(ctx context.Context, adminId int64) (interface{}, error) {
+		res, err := h.client.IssueGiftCard(ctx, &pb.IssueGiftCardRequest{
+			AdminId:   adminId,
+			Amount:    input.Amount,
+			ValidDays: input.ValidDays,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.IssuedGiftCardFromProto(res), nil
+	})
+}
+
+func (h *PaymentHandler) VoidGiftCard(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	return h.call(c, "void gift card", fiber.StatusOK, func(ctx context.Context, adminId int64) (interface{}, error) {
+		res, err := h.client.VoidGiftCard(ctx, &pb.VoidGiftCardRequest{
+			Id:      id,
+			AdminId: adminId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.GiftCardFromProto(res), nil
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+)
+
+const headerTotalCount = "X-Total-Count"
+
+// List is a response of a list endpoint. Handlers return it instead of the
+// bare body so every list answers with the same pagination and caching
+// headers, see respond.
+type List struct {
+	Body any
+	// Page is the page that was asked for; a zero Limit means the list is
+	// not paged.
+	Page query.Page
+	// Count is the number of items in Body.
+	Count int
+	// Total counts the items of every page, -1 when the backend does not
+	// count them.
+	Total int64
+}
+
+// unpagedList is a list returned whole.
+func unpagedList(body any, count int) List {
+	return List{Body: body, Count: count, Total: int64(count)}
+}
+
+// respond writes a handler's result: lists through sendList, anything else
+// as plain JSON with the given status.
+func respond(c *fiber.Ctx, status int, res any) error {
+	if list, ok := res.(List); ok {
+		return sendList(c, list)
+	}
+
+	return c.Status(status).JSON(res)
+}
+
+// sendList writes X-Total-Count when the total is known, a Link header with
+// the next and previous pages, and an ETag of the body. A request whose
+// If-None-Match still matches gets 304 without the body.
+func sendList(c *fiber.Ctx, list List) error {
+	body, err := c.App().Config().JSONEncoder(list.Body)
+	if err != nil {
+		return err
+	}
+
+	if list.Total >= 0 {
+		c.Set(headerTotalCount, strconv.FormatInt(list.Total, 10))
+	}
+	if links := listLinks(c, list); links != "" {
+		c.Set(fiber.HeaderLink, links)
+	}
+
+	// Lists are per user, so only the client may keep them, and only after
+	// asking whether they changed.
+	etag := listETag(body)
+	c.Set(fiber.HeaderETag, etag)
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), etag) {
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+	return c.Status(fiber.StatusOK).Send(body)
+}
+
+// listLinks points at the neighbouring pages by rewriting limit and offset
+// of the request URL. Without a total, a full page is taken to have a next
+// one.
+func listLinks(c *fiber.Ctx, list List) string {
+	page := list.Page
+	if page.Limit <= 0 {
+		return ""
+	}
+
+	hasNext := int64(list.Count) == page.Limit
+	if list.Total >= 0 {
+		hasNext = page.Offset+page.Limit < list.Total
+	}
+
+	var links []string
+	if hasNext {
+		links = append(links, pageLink(c, page.Limit, page.Offset+page.Limit, "next"))
+	}
+	if page.Offset > 0 {
+		links = append(links, pageLink(c, page.Limit, max(page.Offset-page.Limit, 0), "prev"))
+	}
+
+	return strings.Join(links, ", ")
+}
+
+func pageLink(c *fiber.Ctx, limit, offset int64, rel string) string {
+	params, _ := url.ParseQuery(string(c.Request().URI().QueryString()))
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("limit", strconv.FormatInt(limit, 10))
+	params.Set("offset", strconv.FormatInt(offset, 10))
+
+	return fmt.Sprintf(`<%s?%s>; rel="%s"`, c.Path(), params.Encode(), rel)
+}
+
+func listETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches applies the weak comparison If-None-Match calls for.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
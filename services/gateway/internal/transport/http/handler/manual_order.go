@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+// PriceOverrideInput sets the unit price of a line. The reason codes are
+// checked by the order service.
+type PriceOverrideInput struct {
+	Price      int64  `json:"price" validate:"gte=0,lte=100000000"`
+	ReasonCode string `json:"reason_code" validate:"required"`
+}
+
+type ManualOrderItemInput struct {
+	CreateOrderItemInput
+	Override *PriceOverrideInput `json:"override"`
+}
+
+type OrderDiscountInput struct {
+	Amount     int64  `json:"amount" validate:"gt=0"`
+	ReasonCode string `json:"reason_code" validate:"required"`
+}
+
+type CreateManualOrderInput struct {
+	UserID            int64                  `json:"user_id" validate:"required,gt=0"`
+	Items             []ManualOrderItemInput `json:"items" validate:"min=1,max=100,dive"`
+	PaymentMethodID   int64                  `json:"payment_method_id" validate:"gte=0"`
+	ShippingAddressID int64                  `json:"shipping_address_id" validate:"required,gt=0"`
+	Discount          *OrderDiscountInput    `json:"discount"`
+	Note              string                 `json:"note" validate:"max=500"`
+}
+
+// CreateManualOrder places an order for a customer, e.g. one taken by
+// phone. It is mounted under /admin; the shipping address comes from the
+// customer's address book.
+func (h *OrderHandler) CreateManualOrder(c *fiber.Ctx) error {
+	var input CreateManualOrderInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	req := &pb.CreateManualOrderRequest{
+		UserId:            input.UserID,
+		Items:             make([]*pb.ManualOrderItem, 0, len(input.Items)),
+		PaymentMethodId:   input.PaymentMethodID,
+		ShippingAddressId: input.ShippingAddressID,
+		Note:              input.Note,
+	}
+	for _, item := range input.Items {
+		pbItem := &pb.ManualOrderItem{
+			ProductId:   item.ProductID,
+			Name:        item.Name,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+			WeightGrams: item.WeightGrams,
+		}
+		if item.Override != nil {
+			pbItem.Override = &pb.PriceOverride{
+				Price:      item.Override.Price,
+				ReasonCode: item.Override.ReasonCode,
+			}
+		}
+		req.Items = append(req.Items, pbItem)
+	}
+	if input.Discount != nil {
+		req.Discount = &pb.OrderDiscount{
+			Amount:     input.Discount.Amount,
+			ReasonCode: input.Discount.ReasonCode,
+		}
+	}
+
+	return h.call(c, "create manual order", fiber.StatusCreated, func(ctx context.Context, adminId int64) (interface{}, error) {
+		req.AdminId = adminId
+
+		res, err := h.client.CreateManualOrder(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return fiber.Map{
+			"order_id":     res.OrderId,
+			"delivery_fee": res.DeliveryFee,
+			"discount":     res.Discount,
+			"total_sum":    res.TotalSum,
+		}, nil
+	})
+}
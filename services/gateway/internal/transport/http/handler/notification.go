@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/notification"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+type NotificationHandler struct {
+	client pb.NotificationServiceClient
+	logger *zap.Logger
+	cb     *gobreaker.CircuitBreaker
+}
+
+func NewNotificationHandler(client pb.NotificationServiceClient, logger *zap.Logger) *NotificationHandler {
+	settings := gobreaker.Settings{
+		Name:        "NotificationService",
+		MaxRequests: 3,
+		Interval:    5 * time.Second,
+		Timeout:     10 * time.Second,
+		ReadyToTrip: readyToTrip,
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			logger.Warn(
+				"Circuit breaker state changed",
+				zap.String("name", name),
+				zap.String("from", from.String()),
+				zap.String("to", to.String()),
+			)
+		},
+	}
+
+	return &NotificationHandler{
+		client: client,
+		logger: logger,
+		cb:     newBreaker(settings),
+	}
+}
+
+func (h *NotificationHandler) List(c *fiber.Ctx) error {
+	limit, err := optionalInt64(c.Query("limit"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit is invalid",
+		})
+	}
+
+	offset, err := optionalInt64(c.Query("offset"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "offset is invalid",
+		})
+	}
+
+	return h.call(c, "list notifications", func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.ListNotifications(ctx, &pb.ListNotificationsRequest{
+			UserId:     userId,
+			UnreadOnly: c.QueryBool("unread", false),
+			Limit:      limit,
+			Offset:     offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return List{
+			Body:  dto.NotificationListFromProto(res),
+			Page:  query.Page{Limit: limit, Offset: offset},
+			Count: len(res.GetNotifications()),
+			Total: -1,
+		}, nil
+	})
+}
+
+type markReadInput struct {
+	Ids []int64 `json:"ids"`
+}
+
+// MarkRead marks the given notifications as read; an empty body marks all.
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	var input markReadInput
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&input); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "error parsing body",
+			})
+		}
+	}
+
+	return h.call(c, "mark notifications read", func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.MarkRead(ctx, &pb.MarkReadRequest{
+			UserId: userId,
+			Ids:    input.Ids,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.MarkReadFromProto(res), nil
+	})
+}
+
+func (h *NotificationHandler) UnreadCount(c *fiber.Ctx) error {
+	return h.call(c, "unread count", func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.GetUnreadCount(ctx, &pb.GetUnreadCountRequest{UserId: userId})
+		if err != nil {
+			return nil, err
+		}
+		return dto.UnreadCountFromProto(res), nil
+	})
+}
+
+// Unsubscribe processes the signed link from non-transactional emails. It is
+// public: the token itself authorises the request. POST serves RFC 8058
+// one-click unsubscribe, which sends the token in the query string too.
+func (h *NotificationHandler) Unsubscribe(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 2*time.Second)
+	defer cancel()
+
+	token := c.Query("token")
+	if token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "token is required",
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.UnsubscribeResponse](h.cb, func() (*pb.UnsubscribeResponse, error) {
+		return h.client.Unsubscribe(ctx, &pb.UnsubscribeRequest{Token: token})
+	})
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"unsubscribe failed",
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.UnsubscribeFromProto(res))
+}
+
+// call runs a notification RPC for the current user behind the circuit
+// breaker.
+func (h *NotificationHandler) call(c *fiber.Ctx, name string, rpc func(ctx context.Context, userId int64) (interface{}, error)) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 2*time.Second)
+	defer cancel()
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	res, err := h.cb.Execute(func() (interface{}, error) {
+		return rpc(ctx, userId)
+	})
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"notification request failed",
+			zap.String("request", name),
+			zap.Int64("user_id", userId),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return respond(c, fiber.StatusOK, res)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	orderPb "github.com/sakashimaa/go-pet-project/proto/order"
+	pb "github.com/sakashimaa/go-pet-project/proto/status"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Reading consumer lag means a round of offset requests to Kafka, so the
+// status calls get longer than regular reads.
+const opsStatusTimeout = 5 * time.Second
+
+// StatusTarget is a backend polled by the ops endpoints.
+type StatusTarget struct {
+	Name   string
+	Client pb.StatusServiceClient
+}
+
+// OpsHandler serves the admin endpoints behind the ops dashboard. The status
+// calls bypass the circuit breakers: the dashboard has to reach a backend
+// whose breaker is open, and its polling must not trip breakers that guard
+// customer traffic.
+type OpsHandler struct {
+	targets []StatusTarget
+	order   orderPb.OrderServiceClient
+	logger  *zap.Logger
+	tracer  trace.Tracer
+}
+
+func NewOpsHandler(targets []StatusTarget, order orderPb.OrderServiceClient, logger *zap.Logger) *OpsHandler {
+	return &OpsHandler{
+		targets: targets,
+		order:   order,
+		logger:  logger,
+		tracer:  otel.Tracer("gateway_ops"),
+	}
+}
+
+// Status asks every backend for its outbox backlog and consumer lag in
+// parallel and adds the gateway's breaker states. A backend that does not
+// answer is reported with an error instead of failing the request.
+func (h *OpsHandler) Status(c *fiber.Ctx) error {
+	ctx, span := h.tracer.Start(c.UserContext(), "Gateway.OpsStatus")
+	defer span.End()
+
+	failedLimit, err := optionalInt64(c.Query("failed_limit"))
+	if err != nil || failedLimit > 100 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "failed_limit must be between 0 and 100",
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opsStatusTimeout)
+	defer cancel()
+
+	services := make([]dto.ServiceStatus, len(h.targets))
+
+	var g errgroup.Group
+	for i, target := range h.targets {
+		g.Go(func() error {
+			res, err := target.Client.GetStatus(ctx, &pb.GetStatusRequest{FailedLimit: int32(failedLimit)})
+			if err != nil {
+				span.RecordError(err)
+				mylogger.Warn(ctx, h.logger, "status call failed", zap.String("service", target.Name), zap.Error(err))
+
+				services[i] = dto.ServiceStatus{Service: target.Name, Error: err.Error()}
+				return nil
+			}
+
+			services[i] = dto.ServiceStatusFromProto(res)
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return c.JSON(fiber.Map{
+		"services": services,
+		"breakers": BreakerStates(),
+	})
+}
+
+// Breakers reports the gateway's circuit breakers without asking the
+// backends, for polling more often than Status.
+func (h *OpsHandler) Breakers(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"breakers": BreakerStates(),
+	})
+}
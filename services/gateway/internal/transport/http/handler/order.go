@@ -1,11 +1,18 @@
 package handler
 
 import (
+	"bufio"
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	"github.com/sakashimaa/go-pet-project/pkg/utils"
 	pb "github.com/sakashimaa/go-pet-project/proto/order"
@@ -14,9 +21,32 @@ import (
 )
 
 type OrderHandler struct {
-	client pb.OrderServiceClient
-	logger *zap.Logger
-	cb     *gobreaker.CircuitBreaker
+	client   pb.OrderServiceClient
+	logger   *zap.Logger
+	cb       *gobreaker.CircuitBreaker
+	validate *validator.Validate
+}
+
+// The bounds mirror the order service limits so oversized requests are
+// turned away before they cost a gRPC call. The order total is only checked
+// by the order service.
+type CreateOrderItemInput struct {
+	ProductID int64  `json:"product_id" validate:"required,gt=0"`
+	Name      string `json:"name"`
+	Price     int64  `json:"price" validate:"gte=0,lte=100000000"`
+	Quantity  int32  `json:"quantity" validate:"gt=0,lte=1000"`
+	// WeightGrams is only used by weight-based delivery rates.
+	WeightGrams int32 `json:"weight_grams" validate:"gte=0,lte=1000000"`
+}
+
+type CreateOrderInput struct {
+	Items           []CreateOrderItemInput `json:"items" validate:"max=100,dive"`
+	PaymentMethodID int64                  `json:"payment_method_id" validate:"gte=0"`
+	// ShippingAddressID is an entry of the user's address book.
+	ShippingAddressID int64 `json:"shipping_address_id" validate:"required,gt=0"`
+	// ClientToken is generated once per checkout; resubmitting with it
+	// returns the order already placed.
+	ClientToken string `json:"client_token" validate:"omitempty,uuid"`
 }
 
 func NewOrderHandler(client pb.OrderServiceClient, logger *zap.Logger) *OrderHandler {
@@ -25,10 +55,7 @@ func NewOrderHandler(client pb.OrderServiceClient, logger *zap.Logger) *OrderHan
 		MaxRequests: 3,
 		Interval:    5 * time.Second,
 		Timeout:     10 * time.Second,
-		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
-			return counts.Requests >= 5 && failureRatio >= 0.6
-		},
+		ReadyToTrip: readyToTrip,
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			logger.Warn(
 				"Circuit breaker state changed",
@@ -40,16 +67,17 @@ func NewOrderHandler(client pb.OrderServiceClient, logger *zap.Logger) *OrderHan
 	}
 
 	return &OrderHandler{
-		client: client,
-		logger: logger,
-		cb:     gobreaker.NewCircuitBreaker(settings),
+		client:   client,
+		logger:   logger,
+		cb:       newBreaker(settings),
+		validate: validator.New(),
 	}
 }
 
 func (h *OrderHandler) Create(c *fiber.Ctx) error {
-	input := new(pb.CreateOrderRequest)
+	input := new(CreateOrderInput)
 
-	if err := c.BodyParser(&input); err != nil {
+	if err := c.BodyParser(input); err != nil {
 		h.logger.Warn(
 			"failed to parse body in create",
 			zap.Error(err),
@@ -60,7 +88,18 @@ func (h *OrderHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
-	userId, ok := c.Locals("userId").(int64)
+	if err := h.validate.Struct(input); err != nil {
+		h.logger.Warn(
+			"failed to validate create order input",
+			zap.Error(err),
+		)
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
 	if !ok {
 		mylogger.Info(
 			c.UserContext(),
@@ -72,12 +111,26 @@ func (h *OrderHandler) Create(c *fiber.Ctx) error {
 	}
 
 	result, err := h.cb.Execute(func() (interface{}, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
 		defer cancel()
 
+		items := make([]*pb.OrderItem, len(input.Items))
+		for i, item := range input.Items {
+			items[i] = &pb.OrderItem{
+				ProductId:   item.ProductID,
+				Name:        item.Name,
+				Price:       item.Price,
+				Quantity:    item.Quantity,
+				WeightGrams: item.WeightGrams,
+			}
+		}
+
 		req := pb.CreateOrderRequest{
-			UserId: userId,
-			Items:  input.Items,
+			UserId:            userId,
+			Items:             items,
+			PaymentMethodId:   input.PaymentMethodID,
+			ShippingAddressId: input.ShippingAddressID,
+			ClientToken:       input.ClientToken,
 		}
 
 		return h.client.CreateOrder(ctx, &req)
@@ -100,6 +153,10 @@ func (h *OrderHandler) Create(c *fiber.Ctx) error {
 			zap.Error(err),
 		)
 
+		if body, ok := orderLimitRejection(c, err); ok {
+			return c.Status(httpCode).JSON(body)
+		}
+
 		return c.Status(httpCode).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -114,13 +171,521 @@ func (h *OrderHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
-	h.logger.Info(
-		"create order succeeded",
-		zap.Int64("created_id", res.OrderId),
-	)
+	// A resubmitted order was created by the first request, not this one.
+	status := fiber.StatusCreated
+	if res.Duplicate {
+		status = fiber.StatusOK
+	}
+
+	return c.Status(status).JSON(fiber.Map{
+		"order_id":     res.OrderId,
+		"delivery_fee": res.DeliveryFee,
+		"total_sum":    res.TotalSum,
+		"duplicate":    res.Duplicate,
+		"status":       "success",
+	})
+}
+
+var partialReservationChoices = map[string]pb.PartialReservationChoice{
+	"wait":               pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_WAIT,
+	"remove_unavailable": pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_REMOVE_UNAVAILABLE,
+}
+
+func (h *OrderHandler) ResolvePartialReservation(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	orderId, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"invalid order id",
+			zap.String("id", idStr),
+		)
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	var input struct {
+		Choice string `json:"choice"`
+	}
+
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+	choice, ok := partialReservationChoices[input.Choice]
+	if !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "choice must be one of: wait, remove_unavailable",
+		})
+	}
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	result, err := h.cb.Execute(func() (interface{}, error) {
+		return h.client.ResolvePartialReservation(ctx, &pb.ResolvePartialReservationRequest{
+			OrderId: orderId,
+			UserId:  userId,
+			Choice:  choice,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"resolve partial reservation failed",
+			zap.Int64("order_id", orderId),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res, ok := result.(*pb.ResolvePartialReservationResponse)
+	if !ok {
+		mylogger.Warn(ctx, h.logger, "result cast failed")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"order_id":  res.OrderId,
+		"status":    res.Status,
+		"total_sum": res.TotalSum,
+	})
+}
+
+func (h *OrderHandler) Cancel(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	orderId, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"invalid order id",
+			zap.String("id", idStr),
+		)
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	result, err := h.cb.Execute(func() (interface{}, error) {
+		return h.client.CancelOrder(ctx, &pb.CancelOrderRequest{
+			OrderId: orderId,
+			UserId:  userId,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"cancel order failed",
+			zap.Int64("order_id", orderId),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res, ok := result.(*pb.CancelOrderResponse)
+	if !ok {
+		mylogger.Warn(ctx, h.logger, "result cast failed")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"order_id": res.OrderId,
-		"status":   "success",
+		"status":   res.Status,
+	})
+}
+
+type ForceOrderStatusInput struct {
+	Status string `json:"status" validate:"required"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// ForceStatus moves a stuck order by hand. The order service decides which
+// transitions are allowed and keeps the audit record.
+func (h *OrderHandler) ForceStatus(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	orderId, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"invalid order id",
+			zap.String("id", idStr),
+		)
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	var input ForceOrderStatusInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	adminId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	result, err := h.cb.Execute(func() (interface{}, error) {
+		return h.client.ForceOrderStatus(ctx, &pb.ForceOrderStatusRequest{
+			OrderId: orderId,
+			AdminId: adminId,
+			Status:  input.Status,
+			Reason:  input.Reason,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"force order status failed",
+			zap.Int64("order_id", orderId),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res, ok := result.(*pb.ForceOrderStatusResponse)
+	if !ok {
+		mylogger.Warn(ctx, h.logger, "result cast failed")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"order_id":        res.OrderId,
+		"previous_status": res.PreviousStatus,
+		"status":          res.Status,
 	})
 }
+
+func (h *OrderHandler) GetTimeline(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	orderId, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"invalid order id",
+			zap.String("id", idStr),
+		)
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	result, err := h.cb.Execute(func() (interface{}, error) {
+		return h.client.GetOrderTimeline(ctx, &pb.GetOrderTimelineRequest{
+			OrderId:         orderId,
+			UserId:          userId,
+			IncludeInternal: c.QueryBool("internal", false),
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"get order timeline failed",
+			zap.Int64("order_id", orderId),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res, ok := result.(*pb.GetOrderTimelineResponse)
+	if !ok {
+		mylogger.Warn(ctx, h.logger, "result cast failed")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.OrderTimelineFromProto(res))
+}
+
+func (h *OrderHandler) GetInvoice(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	orderId, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"invalid order id",
+			zap.String("id", idStr),
+		)
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	result, err := h.cb.Execute(func() (interface{}, error) {
+		return h.client.GetInvoice(ctx, &pb.GetInvoiceRequest{
+			OrderId: orderId,
+			UserId:  userId,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"get invoice failed",
+			zap.Int64("order_id", orderId),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res, ok := result.(*pb.GetInvoiceResponse)
+	if !ok {
+		mylogger.Warn(ctx, h.logger, "result cast failed")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, res.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", res.Number+".pdf"))
+
+	return c.Status(fiber.StatusOK).Send(res.Content)
+}
+
+// exportTimeout bounds a whole export, not just its first chunk.
+const exportTimeout = 5 * time.Minute
+
+type exportStart struct {
+	stream pb.OrderService_ExportOrdersClient
+	first  *pb.ExportOrdersChunk
+}
+
+// ExportOrders streams the CSV through as it arrives. The first chunk is
+// read before the response starts, so a bad range or a missing role still
+// gets a proper status rather than a truncated file.
+func (h *OrderHandler) ExportOrders(c *fiber.Ctx) error {
+	// The request context is cancelled when the handler returns, before the
+	// body is streamed.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.UserContext()), exportTimeout)
+
+	from, to := c.Query("from"), c.Query("to")
+
+	result, err := h.cb.Execute(func() (interface{}, error) {
+		stream, err := h.client.ExportOrders(ctx, &pb.ExportOrdersRequest{
+			From:   from,
+			To:     to,
+			Status: c.Query("status"),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		first, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		return exportStart{stream: stream, first: first}, nil
+	})
+
+	if err != nil {
+		cancel()
+
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"export orders failed",
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	start, ok := result.(exportStart)
+	if !ok {
+		cancel()
+		mylogger.Warn(ctx, h.logger, "result cast failed")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "orders-"+from+"-"+to+".csv"))
+
+	// The writer runs after this handler has returned, so it owns ctx.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		chunk := start.first
+		for {
+			if _, err := w.Write(chunk.Csv); err != nil {
+				return
+			}
+			// Fails once the client has gone away.
+			if err := w.Flush(); err != nil {
+				return
+			}
+
+			var err error
+			chunk, err = start.stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if err != nil {
+				mylogger.Warn(ctx, h.logger, "export orders interrupted", zap.Error(err))
+				return
+			}
+		}
+	})
+
+	return nil
+}
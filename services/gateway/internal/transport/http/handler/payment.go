@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/payment"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+type PaymentHandler struct {
+	client pb.PaymentServiceClient
+	logger *zap.Logger
+	cb     *gobreaker.CircuitBreaker
+}
+
+func NewPaymentHandler(client pb.PaymentServiceClient, logger *zap.Logger) *PaymentHandler {
+	settings := gobreaker.Settings{
+		Name:        "PaymentService",
+		MaxRequests: 3,
+		Interval:    5 * time.Second,
+		Timeout:     10 * time.Second,
+		ReadyToTrip: readyToTrip,
+		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+			logger.Warn(
+				"Circuit breaker state changed",
+				zap.String("name", name),
+				zap.String("from", from.String()),
+				zap.String("to", to.String()),
+			)
+		},
+	}
+
+	return &PaymentHandler{
+		client: client,
+		logger: logger,
+		cb:     newBreaker(settings),
+	}
+}
+
+// addPaymentMethodInput carries the token the provider issued for the card.
+// Card numbers must never be sent here, payment rejects anything like one.
+type addPaymentMethodInput struct {
+	Provider string `json:"provider"`
+	Token    string `json:"token"`
+	Brand    string `json:"brand"`
+	Last4    string `json:"last4"`
+	ExpMonth int32  `json:"exp_month"`
+	ExpYear  int32  `json:"exp_year"`
+}
+
+func (h *PaymentHandler) AddPaymentMethod(c *fiber.Ctx) error {
+	var input addPaymentMethodInput
+	if err := c.BodyParser(&input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	return h.call(c, "add payment method", fiber.StatusCreated, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.AddPaymentMethod(ctx, &pb.AddPaymentMethodRequest{
+			UserId:   userId,
+			Provider: input.Provider,
+			Token:    input.Token,
+			Brand:    input.Brand,
+			Last4:    input.Last4,
+			ExpMonth: input.ExpMonth,
+			ExpYear:  input.ExpYear,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return dto.PaymentMethodFromProto(res), nil
+	})
+}
+
+func (h *PaymentHandler) ListPaymentMethods(c *fiber.Ctx) error {
+	return h.call(c, "list payment methods", fiber.StatusOK, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.ListPaymentMethods(ctx, &pb.ListPaymentMethodsRequest{UserId: userId})
+		if err != nil {
+			return nil, err
+		}
+		return unpagedList(dto.PaymentMethodListFromProto(res), len(res.GetPaymentMethods())), nil
+	})
+}
+
+func (h *PaymentHandler) DeletePaymentMethod(c *fiber.Ctx) error {
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	return h.call(c, "delete payment method", fiber.StatusOK, func(ctx context.Context, userId int64) (interface{}, error) {
+		res, err := h.client.DeletePaymentMethod(ctx, &pb.DeletePaymentMethodRequest{
+			Id:     id,
+			UserId: userId,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return fiber.Map{"success": res.GetSuccess()}, nil
+	})
+}
+
+// call runs a payment RPC for the current user behind the circuit breaker.
+func (h *PaymentHandler) call(c *fiber.Ctx, name string, successStatus int, rpc func(ctx context.Context, userId int64) (interface{}, error)) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 2*time.Second)
+	defer cancel()
+
+	userId, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	res, err := h.cb.Execute(func() (interface{}, error) {
+		return rpc(ctx, userId)
+	})
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"payment request failed",
+			zap.String("request", name),
+			zap.Int64("user_id", userId),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return respond(c, successStatus, res)
+}
@@ -4,15 +4,19 @@ import (
 	"context"
 	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
 	"github.com/sakashimaa/go-pet-project/pkg/utils"
 	pb "github.com/sakashimaa/go-pet-project/proto/product"
 	"github.com/sony/gobreaker"
 	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
 )
 
 type ProductHandler struct {
@@ -20,6 +24,8 @@ type ProductHandler struct {
 	validate *validator.Validate
 	logger   *zap.Logger
 	cb       *gobreaker.CircuitBreaker
+	// reads coalesces identical concurrent GETs into one upstream call.
+	reads singleflight.Group
 }
 
 func NewProductHandler(client pb.ProductServiceClient, logger *zap.Logger) *ProductHandler {
@@ -28,10 +34,7 @@ func NewProductHandler(client pb.ProductServiceClient, logger *zap.Logger) *Prod
 		MaxRequests: 3,
 		Interval:    5 * time.Second,
 		Timeout:     10 * time.Second,
-		ReadyToTrip: func(counts gobreaker.Counts) bool {
-			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
-			return counts.Requests >= 5 && failureRatio >= 0.6
-		},
+		ReadyToTrip: readyToTrip,
 		OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
 			logger.Warn(
 				"Circuit breaker state changed",
@@ -46,17 +49,20 @@ func NewProductHandler(client pb.ProductServiceClient, logger *zap.Logger) *Prod
 		client:   client,
 		validate: validator.New(),
 		logger:   logger,
-		cb:       gobreaker.NewCircuitBreaker(settings),
+		cb:       newBreaker(settings),
 	}
 }
 
 type CreateProductInput struct {
-	Name          string `json:"name" validate:"required,min=3,max=100"`
-	Description   string `json:"description" validate:"max=1000"`
-	Price         int64  `json:"price" validate:"required,gt=0"`
-	StockQuantity int64  `json:"stock_quantity" validate:"gte=0"`
-	Category      string `json:"category" validate:"required"`
-	ImageUrl      string `json:"image_url" validate:"omitempty,url"`
+	Name          string            `json:"name" validate:"required,min=3,max=100"`
+	Description   string            `json:"description" validate:"max=1000"`
+	Price         int64             `json:"price" validate:"required,gt=0"`
+	StockQuantity int64             `json:"stock_quantity" validate:"gte=0"`
+	Category      string            `json:"category" validate:"required"`
+	ImageUrl      string            `json:"image_url" validate:"omitempty,url"`
+	SKU           string            `json:"sku" validate:"omitempty,max=64"`
+	EAN           string            `json:"ean" validate:"omitempty,len=13,numeric"`
+	Attributes    map[string]string `json:"attributes" validate:"omitempty,max=50,dive,keys,required,max=64,endkeys,max=256"`
 }
 
 func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
@@ -79,13 +85,6 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 		})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"delete product request",
-		zap.Int("product_id", id),
-	)
-
 	req := new(pb.DeleteProductRequest)
 	req.Id = int64(id)
 
@@ -127,18 +126,171 @@ func (h *ProductHandler) DeleteProduct(c *fiber.Ctx) error {
 		})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"product deleted successfully",
-		zap.Int("product_id", id),
-	)
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"success": res.Success,
 	})
 }
 
+func (h *ProductHandler) RestoreProduct(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		mylogger.Warn(ctx, h.logger, "invalid product id", zap.String("id", idStr))
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.GetProductResponse](h.cb, func() (*pb.GetProductResponse, error) {
+		return h.client.RestoreProduct(ctx, &pb.RestoreProductRequest{Id: id})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"restore product failed",
+			zap.Int64("product_id", id),
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	mylogger.Info(ctx, h.logger, "product restored", zap.Int64("product_id", id))
+
+	return c.Status(fiber.StatusOK).JSON(dto.ProductResponseFromProto(res))
+}
+
+func (h *ProductHandler) ListDeletedProducts(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	page, err := query.ParsePage(c.Query("limit"), c.Query("offset"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit or offset is invalid",
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.ListProductsResponse](h.cb, func() (*pb.ListProductsResponse, error) {
+		return h.client.ListDeletedProducts(ctx, &pb.ListDeletedProductsRequest{
+			Limit:  page.Limit,
+			Offset: page.Offset,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"list deleted products failed",
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return sendList(c, List{
+		Body:  dto.ProductListFromProto(res),
+		Page:  page,
+		Count: len(res.GetProducts()),
+		Total: res.GetTotalCount(),
+	})
+}
+
+func (h *ProductHandler) GetProductHistory(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		mylogger.Warn(ctx, h.logger, "invalid product id", zap.String("id", idStr))
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	page, err := query.ParsePage(c.Query("limit"), c.Query("offset"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit or offset is invalid",
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.GetProductHistoryResponse](h.cb, func() (*pb.GetProductHistoryResponse, error) {
+		return h.client.GetProductHistory(ctx, &pb.GetProductHistoryRequest{
+			ProductId: id,
+			Limit:     page.Limit,
+			Offset:    page.Offset,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"get product history failed",
+			zap.Int64("product_id", id),
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return sendList(c, List{
+		Body:  dto.ProductHistoryFromProto(id, res),
+		Page:  page,
+		Count: len(res.GetRevisions()),
+		Total: -1,
+	})
+}
+
 func (h *ProductHandler) DecreaseStock(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
 	defer cancel()
@@ -224,64 +376,65 @@ func (h *ProductHandler) DecreaseStock(c *fiber.Ctx) error {
 		})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"decreased stock successfully",
-		zap.Int("product_id", id),
-	)
-
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"success": res.Success,
-		"message": res.Message,
+		"success":        res.Success,
+		"message":        res.Message,
+		"stock_quantity": res.StockQuantity,
 	})
 }
 
+const (
+	defaultProductsLimit = 20
+	maxProductsLimit     = 100
+)
+
 func (h *ProductHandler) ListProducts(c *fiber.Ctx) error {
 	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
 	defer cancel()
 
-	offsetStr := c.Query("offset")
-	offset, err := strconv.Atoi(offsetStr)
-
+	page, err := query.ParsePage(c.Query("limit"), c.Query("offset"))
 	if err != nil {
 		mylogger.Warn(
 			ctx,
 			h.logger,
-			"offset is invalid",
-			zap.String("offset", offsetStr),
+			"pagination is invalid",
+			zap.String("limit", c.Query("limit")),
+			zap.String("offset", c.Query("offset")),
 		)
 
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "offset is invalid",
+			"error": "limit or offset is invalid",
 		})
 	}
+	page = page.Clamp(defaultProductsLimit, maxProductsLimit)
 
-	limitStr := c.Query("limit")
-	limit, err := strconv.Atoi(limitStr)
+	req, err := listProductsFilter(c)
 	if err != nil {
 		mylogger.Warn(
 			ctx,
 			h.logger,
-			"limit is invalid",
-			zap.String("limit", limitStr),
+			"list filter is invalid",
+			zap.Error(err),
 		)
 
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "limit is invalid",
+			"error": err.Error(),
 		})
 	}
 
-	search := c.Query("search")
+	req.Offset = page.Offset
+	req.Limit = page.Limit
+	req.Search = c.Query("search")
 
-	body, err := h.cb.Execute(func() (interface{}, error) {
-		req := pb.ListProductsRequest{
-			Offset: int64(offset),
-			Limit:  int64(limit),
-			Search: search,
-		}
+	key, err := requestKey("list:", req)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal error"})
+	}
 
-		return h.client.ListProducts(ctx, &req)
+	body, err := coalesce(ctx, &h.reads, key, func(ctx context.Context) (interface{}, error) {
+		return h.cb.Execute(func() (interface{}, error) {
+			return h.client.ListProducts(ctx, req)
+		})
 	})
 
 	if err != nil {
@@ -313,17 +466,12 @@ func (h *ProductHandler) ListProducts(c *fiber.Ctx) error {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "internal type error"})
 	}
 
-	mylogger.Info(
-		ctx,
-		h.logger,
-		"list products succeeded",
-		zap.Int("offset", offset),
-		zap.Int("limit", limit),
-		zap.String("search", search),
-		zap.Int64("total", res.TotalCount),
-	)
-
-	return c.Status(fiber.StatusOK).JSON(res)
+	return sendList(c, List{
+		Body:  dto.ProductListFromProto(res),
+		Page:  page,
+		Count: len(res.GetProducts()),
+		Total: res.GetTotalCount(),
+	})
 }
 
 func (h *ProductHandler) FindByID(c *fiber.Ctx) error {
@@ -358,12 +506,14 @@ func (h *ProductHandler) FindByID(c *fiber.Ctx) error {
 		})
 	}
 
-	result, err := h.cb.Execute(func() (interface{}, error) {
-		req := pb.GetProductRequest{
-			Id: int64(id),
-		}
+	result, err := coalesce(ctx, &h.reads, "id:"+strconv.Itoa(id), func(ctx context.Context) (interface{}, error) {
+		return h.cb.Execute(func() (interface{}, error) {
+			req := pb.GetProductRequest{
+				Id: int64(id),
+			}
 
-		return h.client.GetProduct(ctx, &req)
+			return h.client.GetProduct(ctx, &req)
+		})
 	})
 
 	if err != nil {
@@ -402,12 +552,7 @@ func (h *ProductHandler) FindByID(c *fiber.Ctx) error {
 		})
 	}
 
-	h.logger.Info(
-		"find by id succeeded",
-		zap.Int("product_id", id),
-	)
-
-	return c.Status(fiber.StatusOK).JSON(res)
+	return c.Status(fiber.StatusOK).JSON(dto.ProductResponseFromProto(res))
 }
 
 func (h *ProductHandler) Create(c *fiber.Ctx) error {
@@ -436,7 +581,7 @@ func (h *ProductHandler) Create(c *fiber.Ctx) error {
 	}
 
 	result, err := h.cb.Execute(func() (interface{}, error) {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
 		defer cancel()
 
 		req := pb.CreateProductRequest{
@@ -445,6 +590,9 @@ func (h *ProductHandler) Create(c *fiber.Ctx) error {
 			Price:         input.Price,
 			StockQuantity: input.StockQuantity,
 			Category:      input.Category,
+			Sku:           input.SKU,
+			Ean:           input.EAN,
+			Attributes:    input.Attributes,
 		}
 
 		return h.client.CreateProduct(ctx, &req)
@@ -481,13 +629,221 @@ func (h *ProductHandler) Create(c *fiber.Ctx) error {
 		})
 	}
 
-	h.logger.Info(
-		"create product succeeded",
-		zap.Int64("created_id", res.Id),
-	)
-
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"id":     res.Id,
 		"status": "success",
 	})
 }
+
+func (h *ProductHandler) FindBySKU(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	sku := c.Params("sku")
+	if sku == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "sku is required",
+		})
+	}
+
+	result, err := coalesce(ctx, &h.reads, "sku:"+sku, func(ctx context.Context) (interface{}, error) {
+		return h.cb.Execute(func() (interface{}, error) {
+			return h.client.GetProductBySKU(ctx, &pb.GetProductBySKURequest{Sku: sku})
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open", zap.String("sku", sku))
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"find by sku failed",
+			zap.String("sku", sku),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res, ok := result.(*pb.GetProductResponse)
+	if !ok {
+		mylogger.Error(ctx, h.logger, "failed to cast response", zap.String("sku", sku))
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ProductResponseFromProto(res))
+}
+
+func (h *ProductHandler) GetRelated(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		mylogger.Warn(ctx, h.logger, "invalid product id", zap.String("id", idStr))
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid product id",
+		})
+	}
+
+	limit, err := optionalInt64(c.Query("limit"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit is invalid",
+		})
+	}
+
+	result, err := h.cb.Execute(func() (interface{}, error) {
+		return h.client.GetRelatedProducts(ctx, &pb.GetRelatedProductsRequest{
+			ProductId: id,
+			Limit:     limit,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open", zap.Int64("product_id", id))
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"get related products failed",
+			zap.Int64("product_id", id),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	res, ok := result.(*pb.GetRelatedProductsResponse)
+	if !ok {
+		mylogger.Error(ctx, h.logger, "failed to cast response", zap.Int64("product_id", id))
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.RelatedProductsFromProto(res))
+}
+
+var productSorts = map[string]pb.ProductSort{
+	"":           pb.ProductSort_PRODUCT_SORT_UNSPECIFIED,
+	"newest":     pb.ProductSort_PRODUCT_SORT_NEWEST,
+	"price_asc":  pb.ProductSort_PRODUCT_SORT_PRICE_ASC,
+	"price_desc": pb.ProductSort_PRODUCT_SORT_PRICE_DESC,
+	"rating":     pb.ProductSort_PRODUCT_SORT_RATING,
+	"stock_asc":  pb.ProductSort_PRODUCT_SORT_STOCK_ASC,
+}
+
+// listProductsFilter reads facet filters from the query string. Multi-valued
+// params accept both repeated keys (?category=a&category=b) and commas
+// (?category=a,b); attributes use the attr.<key> prefix.
+func listProductsFilter(c *fiber.Ctx) (*pb.ListProductsRequest, error) {
+	req := &pb.ListProductsRequest{}
+	args := c.Context().QueryArgs()
+
+	for _, raw := range args.PeekMulti("category") {
+		req.Categories = append(req.Categories, splitQueryValues(string(raw))...)
+	}
+
+	var err error
+	if req.PriceMin, err = optionalInt64(c.Query("price_min")); err != nil {
+		return nil, errors.New("price_min is invalid")
+	}
+	if req.PriceMax, err = optionalInt64(c.Query("price_max")); err != nil {
+		return nil, errors.New("price_max is invalid")
+	}
+	if req.PriceMin > 0 && req.PriceMax > 0 && req.PriceMin > req.PriceMax {
+		return nil, errors.New("price_min must not exceed price_max")
+	}
+
+	if req.StockMax, err = optionalInt64(c.Query("stock_max")); err != nil {
+		return nil, errors.New("stock_max is invalid")
+	}
+
+	if inStock := c.Query("in_stock"); inStock != "" {
+		if req.InStockOnly, err = strconv.ParseBool(inStock); err != nil {
+			return nil, errors.New("in_stock is invalid")
+		}
+	}
+
+	sort, ok := productSorts[c.Query("sort")]
+	if !ok {
+		return nil, errors.New("sort is invalid")
+	}
+	req.Sort = sort
+
+	attributes := make(map[string][]string)
+	var keys []string
+	args.VisitAll(func(key, value []byte) {
+		name, found := strings.CutPrefix(string(key), "attr.")
+		if !found || name == "" {
+			return
+		}
+		if _, seen := attributes[name]; !seen {
+			keys = append(keys, name)
+		}
+		attributes[name] = append(attributes[name], splitQueryValues(string(value))...)
+	})
+
+	for _, key := range keys {
+		req.Attributes = append(req.Attributes, &pb.AttributeFilter{
+			Key:    key,
+			Values: attributes[key],
+		})
+	}
+
+	return req, nil
+}
+
+func splitQueryValues(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+func optionalInt64(raw string) (int64, error) {
+	if raw == "" {
+		return 0, nil
+	}
+
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v < 0 {
+		return 0, errors.New("invalid number")
+	}
+
+	return v, nil
+}
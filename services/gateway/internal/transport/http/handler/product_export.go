@@ -0,0 +1,295 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"github.com/sony/gobreaker"
+	"github.com/xuri/excelize/v2"
+	"go.uber.org/zap"
+)
+
+const (
+	exportFormatCSV  = "csv"
+	exportFormatXLSX = "xlsx"
+
+	mimeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+)
+
+// productExportColumns names the cells of productExportRecord.
+var productExportColumns = []any{
+	"id",
+	"sku",
+	"ean",
+	"name",
+	"category",
+	"category_path",
+	"price",
+	"stock_quantity",
+	"warehouse_stock",
+	"min_price",
+	"max_price",
+	"price_changes",
+	"price_changed_at",
+	"created_at",
+	"deleted_at",
+}
+
+// productExportRecord lays a product out as a row. Numbers stay numbers so
+// spreadsheets can sum and sort them.
+func productExportRecord(p *pb.ExportedProduct) []any {
+	warehouses := make([]string, 0, len(p.WarehouseStock))
+	for _, code := range slices.Sorted(maps.Keys(p.WarehouseStock)) {
+		warehouses = append(warehouses, code+"="+strconv.FormatInt(p.WarehouseStock[code], 10))
+	}
+
+	return []any{
+		p.Id,
+		p.Sku,
+		p.Ean,
+		p.Name,
+		p.Category,
+		strings.Join(p.CategoryPath, " > "),
+		p.Price,
+		p.StockQuantity,
+		strings.Join(warehouses, "; "),
+		p.MinPrice,
+		p.MaxPrice,
+		p.PriceChanges,
+		p.PriceChangedAt,
+		p.CreatedAt,
+		p.DeletedAt,
+	}
+}
+
+// productExport is an ExportProducts stream whose first chunk was already
+// read; first is nil for an empty catalog.
+type productExport struct {
+	stream pb.ProductService_ExportProductsClient
+	first  *pb.ExportProductsChunk
+}
+
+// eachChunk calls fn with the products of every chunk until the stream ends.
+func (e productExport) eachChunk(fn func([]*pb.ExportedProduct) error) error {
+	for chunk := e.first; chunk != nil; {
+		if err := fn(chunk.Products); err != nil {
+			return err
+		}
+
+		var err error
+		chunk, err = e.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeProductsCSV sends the rows on as each chunk arrives.
+func writeProductsCSV(w *bufio.Writer, export productExport) error {
+	cw := csv.NewWriter(w)
+	record := make([]string, len(productExportColumns))
+
+	writeRow := func(cells []any) error {
+		for i, cell := range cells {
+			record[i] = fmt.Sprint(cell)
+		}
+		return cw.Write(record)
+	}
+
+	if err := writeRow(productExportColumns); err != nil {
+		return err
+	}
+
+	flush := func() error {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return err
+		}
+
+		// Fails once the client has gone away.
+		return w.Flush()
+	}
+
+	err := export.eachChunk(func(products []*pb.ExportedProduct) error {
+		for _, p := range products {
+			if err := writeRow(productExportRecord(p)); err != nil {
+				return err
+			}
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	// An empty catalog still gets its header row.
+	return flush()
+}
+
+// writeProductsXLSX builds the workbook from the whole stream before any of
+// it is sent, since an XLSX file is a zip archive. The stream writer keeps
+// large sheets in a temporary file rather than in memory.
+func writeProductsXLSX(w *bufio.Writer, export productExport) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Products"
+	if err := f.SetSheetName("Sheet1", sheet); err != nil {
+		return err
+	}
+
+	sw, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	row := 1
+	setRow := func(cells []any) error {
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+		row++
+
+		return sw.SetRow(cell, cells)
+	}
+
+	if err := setRow(productExportColumns); err != nil {
+		return err
+	}
+
+	err = export.eachChunk(func(products []*pb.ExportedProduct) error {
+		for _, p := range products {
+			if err := setRow(productExportRecord(p)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := sw.Flush(); err != nil {
+		return err
+	}
+	if err := f.Write(w); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// ExportProducts converts the catalog streamed by the product service to
+// CSV or XLSX. The first chunk is read before the response starts, so a
+// missing role still gets a proper status rather than a truncated file.
+func (h *ProductHandler) ExportProducts(c *fiber.Ctx) error {
+	format := c.Query("format", exportFormatCSV)
+	if format != exportFormatCSV && format != exportFormatXLSX {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "format must be csv or xlsx",
+		})
+	}
+
+	// The request context is cancelled when the handler returns, before the
+	// body is streamed.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.UserContext()), exportTimeout)
+
+	req := &pb.ExportProductsRequest{
+		Category:       c.Query("category"),
+		IncludeDeleted: c.QueryBool("include_deleted"),
+	}
+
+	result, err := h.cb.Execute(func() (interface{}, error) {
+		stream, err := h.client.ExportProducts(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		first, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return productExport{stream: stream}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		return productExport{stream: stream, first: first}, nil
+	})
+
+	if err != nil {
+		cancel()
+
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"export products failed",
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	export, ok := result.(productExport)
+	if !ok {
+		cancel()
+		mylogger.Warn(ctx, h.logger, "result cast failed")
+
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "internal error",
+		})
+	}
+
+	write := writeProductsCSV
+	contentType := "text/csv; charset=utf-8"
+	if format == exportFormatXLSX {
+		write = writeProductsXLSX
+		contentType = mimeXLSX
+	}
+
+	filename := "products-" + time.Now().UTC().Format(time.DateOnly) + "." + format
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+	// The writer runs after this handler has returned, so it owns ctx.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		if err := write(w, export); err != nil {
+			mylogger.Warn(ctx, h.logger, "export products interrupted", zap.String("format", format), zap.Error(err))
+		}
+	})
+
+	return nil
+}
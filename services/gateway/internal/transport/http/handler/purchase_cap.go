@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// SetPurchaseCapInput caps a product; a MaxPerUser of 0 lifts the cap.
+type SetPurchaseCapInput struct {
+	MaxPerUser int32 `json:"max_per_user" validate:"gte=0,lte=1000"`
+}
+
+func (h *OrderHandler) SetPurchaseCap(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	productID, err := strconv.ParseInt(c.Params("product_id"), 10, 64)
+	if err != nil || productID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Product id is invalid",
+		})
+	}
+
+	input := new(SetPurchaseCapInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	adminID, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.PurchaseCap](h.cb, func() (*pb.PurchaseCap, error) {
+		return h.client.SetPurchaseCap(ctx, &pb.SetPurchaseCapRequest{
+			AdminId:    adminID,
+			ProductId:  productID,
+			MaxPerUser: input.MaxPerUser,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"set purchase cap failed",
+			zap.Int64("product_id", productID),
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.PurchaseCapFromProto(res))
+}
+
+func (h *OrderHandler) ListPurchaseCaps(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	adminID, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.ListPurchaseCapsResponse](h.cb, func() (*pb.ListPurchaseCapsResponse, error) {
+		return h.client.ListPurchaseCaps(ctx, &pb.ListPurchaseCapsRequest{AdminId: adminID})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"list purchase caps failed",
+			zap.Int("http_code", httpCode),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return sendList(c, unpagedList(dto.PurchaseCapListFromProto(res), len(res.GetCaps())))
+}
+
+// orderLimitMessages explain the ErrorInfo reasons the order service
+// refuses orders over its limits with.
+var orderLimitMessages = map[string]string{
+	"ORDER_RATE_LIMITED":    "You have placed too many orders, please try again later",
+	"PURCHASE_CAP_EXCEEDED": "This product is limited per customer and you have reached the limit",
+}
+
+// orderLimitRejection returns the body to answer with when err refused an
+// order over the rate limit or a purchase cap, and sets Retry-After for the
+// former.
+func orderLimitRejection(c *fiber.Ctx, err error) (fiber.Map, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return nil, false
+	}
+
+	var body fiber.Map
+	for _, detail := range st.Details() {
+		switch d := detail.(type) {
+		case *errdetails.ErrorInfo:
+			msg, ok := orderLimitMessages[d.GetReason()]
+			if !ok {
+				continue
+			}
+
+			body = fiber.Map{"error": msg, "reason": d.GetReason()}
+			if productID, err := strconv.ParseInt(d.GetMetadata()["product_id"], 10, 64); err == nil {
+				body["product_id"] = productID
+			}
+			if remaining, err := strconv.Atoi(d.GetMetadata()["remaining"]); err == nil {
+				body["remaining"] = remaining
+			}
+		case *errdetails.RetryInfo:
+			seconds := int64(d.GetRetryDelay().AsDuration().Round(time.Second) / time.Second)
+			c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(max(seconds, 1), 10))
+		}
+	}
+
+	return body, body != nil
+}
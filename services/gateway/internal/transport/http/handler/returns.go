@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+type ReturnLineInput struct {
+	ProductID int64 `json:"product_id" validate:"required,gt=0"`
+	Quantity  int32 `json:"quantity" validate:"required,gt=0,lte=1000"`
+}
+
+// RequestReturnInput lists what the customer sends back and why. The order
+// service checks the lines against what was bought.
+type RequestReturnInput struct {
+	Items  []ReturnLineInput `json:"items" validate:"min=1,max=100,dive"`
+	Reason string            `json:"reason" validate:"required,max=500"`
+}
+
+// ReviewReturnInput approves or rejects a return; a rejection needs a note
+// the customer is shown.
+type ReviewReturnInput struct {
+	Approve bool   `json:"approve"`
+	Note    string `json:"note" validate:"max=500"`
+}
+
+func (h *OrderHandler) RequestReturn(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	orderID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		mylogger.Warn(ctx, h.logger, "invalid order id", zap.String("id", idStr))
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	input := new(RequestReturnInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	userID, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	req := &pb.RequestReturnRequest{
+		UserId:  userID,
+		OrderId: orderID,
+		Items:   make([]*pb.ReturnLine, 0, len(input.Items)),
+		Reason:  input.Reason,
+	}
+	for _, item := range input.Items {
+		req.Items = append(req.Items, &pb.ReturnLine{ProductId: item.ProductID, Quantity: item.Quantity})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.Return](h.cb, func() (*pb.Return, error) {
+		return h.client.RequestReturn(ctx, req)
+	})
+
+	if err != nil {
+		return h.orderCallFailed(ctx, c, "request return failed", err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.ReturnFromProto(res))
+}
+
+// ListReturns lists the caller's own returns.
+func (h *OrderHandler) ListReturns(c *fiber.Ctx) error {
+	return h.listReturns(c, false)
+}
+
+// ListAllReturns lists the returns of every customer, newest first, for
+// support to work through. ?status= narrows it, e.g. to "requested".
+func (h *OrderHandler) ListAllReturns(c *fiber.Ctx) error {
+	return h.listReturns(c, true)
+}
+
+func (h *OrderHandler) listReturns(c *fiber.Ctx, allUsers bool) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	userID, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.ListReturnsResponse](h.cb, func() (*pb.ListReturnsResponse, error) {
+		return h.client.ListReturns(ctx, &pb.ListReturnsRequest{
+			UserId:   userID,
+			Status:   c.Query("status"),
+			AllUsers: allUsers,
+		})
+	})
+
+	if err != nil {
+		return h.orderCallFailed(ctx, c, "list returns failed", err)
+	}
+
+	return sendList(c, unpagedList(dto.ReturnListFromProto(res), len(res.GetReturns())))
+}
+
+// ReviewReturn is mounted under /admin.
+func (h *OrderHandler) ReviewReturn(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	returnID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		mylogger.Warn(ctx, h.logger, "invalid return id", zap.String("id", idStr))
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	input := new(ReviewReturnInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	adminID, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.Return](h.cb, func() (*pb.Return, error) {
+		return h.client.ReviewReturn(ctx, &pb.ReviewReturnRequest{
+			AdminId:  adminID,
+			ReturnId: returnID,
+			Approve:  input.Approve,
+			Note:     input.Note,
+		})
+	})
+
+	if err != nil {
+		return h.orderCallFailed(ctx, c, "review return failed", err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ReturnFromProto(res))
+}
+
+// orderCallFailed answers a failed order service call with the status its
+// error maps to.
+func (h *OrderHandler) orderCallFailed(ctx context.Context, c *fiber.Ctx, msg string, err error) error {
+	if errors.Is(err, gobreaker.ErrOpenState) {
+		mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"error": "service temporarily unavailable",
+		})
+	}
+
+	httpCode := utils.GRPCStatusToHTTP(err)
+
+	mylogger.Warn(ctx, h.logger, msg, zap.Int("http_code", httpCode), zap.Error(err))
+
+	return c.Status(httpCode).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
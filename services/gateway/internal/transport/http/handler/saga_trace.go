@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	orderPb "github.com/sakashimaa/go-pet-project/proto/order"
+	pb "github.com/sakashimaa/go-pet-project/proto/status"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// OrderTrace pieces together the saga of one order: its timeline from the
+// order service, the outbox events of every service that belong to it, where
+// each was published and which consumer groups have read it.
+//
+// Events are found in two rounds. The first finds the order's own events,
+// whose correlation ids the second uses to collect the events the other
+// services emitted while handling them. Parts that cannot be read are
+// reported under "errors" instead of failing the request.
+func (h *OpsHandler) OrderTrace(c *fiber.Ctx) error {
+	ctx, span := h.tracer.Start(c.UserContext(), "Gateway.OrderTrace")
+	defer span.End()
+
+	idStr := c.Params("id")
+	orderID, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || orderID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	userID, ok := authctx.UserIDFromContext(c.UserContext())
+	if !ok {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "userId parsing error"})
+	}
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	ctx, cancel := context.WithTimeout(ctx, opsStatusTimeout)
+	defer cancel()
+
+	var (
+		timeline    *orderPb.GetOrderTimelineResponse
+		timelineErr error
+		traces      []*pb.TraceEventsResponse
+		traceErrs   []error
+		statuses    []*pb.GetStatusResponse
+	)
+
+	var g errgroup.Group
+	g.Go(func() error {
+		timeline, timelineErr = h.order.GetOrderTimeline(ctx, &orderPb.GetOrderTimelineRequest{
+			OrderId:         orderID,
+			UserId:          userID,
+			IncludeInternal: true,
+		})
+		return nil
+	})
+	g.Go(func() error {
+		traces, traceErrs = h.traceOrder(ctx, orderID)
+		return nil
+	})
+	g.Go(func() error {
+		statuses = h.consumerStatuses(ctx)
+		return nil
+	})
+	_ = g.Wait()
+
+	if timelineErr != nil && utils.GRPCStatusToHTTP(timelineErr) == fiber.StatusNotFound {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "order not found",
+		})
+	}
+
+	res := dto.SagaTrace{
+		OrderID:  orderID,
+		Timeline: []dto.TimelineEntry{},
+		Events:   []dto.TracedEvent{},
+		TraceIDs: []string{},
+	}
+	addError := func(part string, err error) {
+		span.RecordError(err)
+		mylogger.Warn(ctx, h.logger, "order trace part failed", zap.String("part", part), zap.Error(err))
+
+		if res.Errors == nil {
+			res.Errors = make(map[string]string)
+		}
+		res.Errors[part] = err.Error()
+	}
+
+	if timelineErr != nil {
+		addError("timeline", timelineErr)
+	} else {
+		res.Timeline = dto.OrderTimelineFromProto(timeline).Entries
+		for _, entry := range res.Timeline {
+			if entry.Status != "" {
+				res.Status = entry.Status
+			}
+		}
+	}
+
+	for i, target := range h.targets {
+		if traceErrs[i] != nil {
+			addError(target.Name, traceErrs[i])
+			continue
+		}
+
+		res.Truncated = res.Truncated || traces[i].GetTruncated()
+		for _, e := range traces[i].GetEvents() {
+			event := dto.TracedEventFromProto(target.Name, e)
+			event.Consumers = eventConsumers(h.targets, statuses, event)
+			res.Events = append(res.Events, event)
+		}
+	}
+
+	slices.SortStableFunc(res.Events, func(a, b dto.TracedEvent) int {
+		return parseEventTime(a.CreatedAt).Compare(parseEventTime(b.CreatedAt))
+	})
+
+	for _, event := range res.Events {
+		if event.TraceID != "" && !slices.Contains(res.TraceIDs, event.TraceID) {
+			res.TraceIDs = append(res.TraceIDs, event.TraceID)
+		}
+	}
+
+	return c.JSON(res)
+}
+
+// traceOrder asks every backend for the order's events, then again for the
+// flows those events started. The answers and errors are by target.
+func (h *OpsHandler) traceOrder(ctx context.Context, orderID int64) ([]*pb.TraceEventsResponse, []error) {
+	req := &pb.TraceEventsRequest{
+		AggregateType: "Order",
+		AggregateId:   strconv.FormatInt(orderID, 10),
+	}
+
+	traces, errs := h.traceEvents(ctx, req)
+
+	for i := range h.targets {
+		if errs[i] != nil {
+			continue
+		}
+
+		for _, e := range traces[i].GetEvents() {
+			if id := e.GetCorrelationId(); id != "" && !slices.Contains(req.CorrelationIds, id) {
+				req.CorrelationIds = append(req.CorrelationIds, id)
+			}
+		}
+	}
+
+	if len(req.CorrelationIds) == 0 {
+		return traces, errs
+	}
+
+	return h.traceEvents(ctx, req)
+}
+
+func (h *OpsHandler) traceEvents(ctx context.Context, req *pb.TraceEventsRequest) ([]*pb.TraceEventsResponse, []error) {
+	traces := make([]*pb.TraceEventsResponse, len(h.targets))
+	errs := make([]error, len(h.targets))
+
+	var wg sync.WaitGroup
+	for i, target := range h.targets {
+		wg.Go(func() {
+			traces[i], errs[i] = target.Client.TraceEvents(ctx, req)
+		})
+	}
+	wg.Wait()
+
+	return traces, errs
+}
+
+// consumerStatuses reads the consumer group offsets of every backend. A
+// backend that does not answer leaves a nil status: its consumers are left
+// out of the trace.
+func (h *OpsHandler) consumerStatuses(ctx context.Context) []*pb.GetStatusResponse {
+	statuses := make([]*pb.GetStatusResponse, len(h.targets))
+
+	var wg sync.WaitGroup
+	for i, target := range h.targets {
+		wg.Go(func() {
+			res, err := target.Client.GetStatus(ctx, &pb.GetStatusRequest{})
+			if err != nil {
+				mylogger.Warn(ctx, h.logger, "status call failed", zap.String("service", target.Name), zap.Error(err))
+				return
+			}
+
+			statuses[i] = res
+		})
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+// eventConsumers lists the groups subscribed to the partition event was
+// published to. A committed offset is the next one the group reads, so the
+// group is past the event once it is greater than the event's offset.
+func eventConsumers(targets []StatusTarget, statuses []*pb.GetStatusResponse, event dto.TracedEvent) []dto.EventConsumer {
+	if event.Offset == nil {
+		return nil
+	}
+
+	var consumers []dto.EventConsumer
+	for i, status := range statuses {
+		for _, group := range status.GetConsumerGroups() {
+			for _, p := range group.GetPartitions() {
+				if p.GetTopic() != event.Topic || p.GetPartition() != *event.Partition {
+					continue
+				}
+
+				consumers = append(consumers, dto.EventConsumer{
+					Service:  targets[i].Name,
+					GroupID:  group.GetGroupId(),
+					Consumed: p.GetCommitted() > *event.Offset,
+				})
+			}
+		}
+	}
+
+	return consumers
+}
+
+// parseEventTime reads the created_at of a traced event. Times that do not
+// parse sort first.
+func parseEventTime(raw string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, raw)
+	return t
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+const (
+	// stockWatchTimeout ends a watch after a while; EventSource clients
+	// reconnect on their own and get a fresh snapshot.
+	stockWatchTimeout = time.Hour
+	// stockWatchKeepAlive is how often an idle watch writes a comment, which
+	// is how a client that went away is noticed.
+	stockWatchKeepAlive = 15 * time.Second
+)
+
+// parseProductIDs reads a comma-separated list such as "1,2,3". The product
+// service checks how many may be watched.
+func parseProductIDs(s string) ([]int64, error) {
+	if s == "" {
+		return nil, errors.New("product_ids is required")
+	}
+
+	parts := strings.Split(s, ",")
+	ids := make([]int64, 0, len(parts))
+	for _, part := range parts {
+		id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("invalid product id %q", part)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// writeStockEvent sends an update as a server-sent event.
+func writeStockEvent(w *bufio.Writer, update *pb.StockUpdate) error {
+	data, err := json.Marshal(dto.StockUpdateFromProto(update))
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: stock\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// streamStockUpdates relays the watch until it ends or the client goes away,
+// which the failing flush of a keep-alive reveals.
+func streamStockUpdates(w *bufio.Writer, stream pb.ProductService_WatchStockClient, first *pb.StockUpdate) error {
+	if err := writeStockEvent(w, first); err != nil {
+		return err
+	}
+
+	updates := make(chan *pb.StockUpdate)
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			update, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			select {
+			case updates <- update:
+			case <-stream.Context().Done():
+				recvErr <- stream.Context().Err()
+				return
+			}
+		}
+	}()
+
+	keepAlive := time.NewTicker(stockWatchKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case update := <-updates:
+			if err := writeStockEvent(w, update); err != nil {
+				return err
+			}
+		case <-keepAlive.C:
+			if _, err := w.WriteString(": keep-alive\n\n"); err != nil {
+				return err
+			}
+			if err := w.Flush(); err != nil {
+				return err
+			}
+		case err := <-recvErr:
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+// WatchStock relays the product service's stock watch as server-sent
+// events, one "stock" event per update, starting with the current level of
+// every product. The first update is read before the response starts, so an
+// invalid watch still gets a proper status.
+func (h *ProductHandler) WatchStock(c *fiber.Ctx) error {
+	ids, err := parseProductIDs(c.Query("product_ids"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// The request context is cancelled when the handler returns, before the
+	// body is streamed.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c.UserContext()), stockWatchTimeout)
+
+	var stream pb.ProductService_WatchStockClient
+	first, err := utils.ExecuteWithBreaker[*pb.StockUpdate](h.cb, func() (*pb.StockUpdate, error) {
+		var err error
+		stream, err = h.client.WatchStock(ctx, &pb.WatchStockRequest{ProductIds: ids})
+		if err != nil {
+			return nil, err
+		}
+
+		return stream.Recv()
+	})
+
+	if err != nil {
+		cancel()
+
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "service temporarily unavailable",
+			})
+		}
+
+		httpCode := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"watch stock failed",
+			zap.Int("http_code", httpCode),
+			zap.Int64s("product_ids", ids),
+			zap.Error(err),
+		)
+
+		return c.Status(httpCode).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+
+	// The writer runs after this handler has returned, so it owns ctx.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+
+		if err := streamStockUpdates(w, stream, first); err != nil && ctx.Err() == nil {
+			mylogger.Warn(ctx, h.logger, "stock watch interrupted", zap.Int64s("product_ids", ids), zap.Error(err))
+		}
+	})
+
+	return nil
+}
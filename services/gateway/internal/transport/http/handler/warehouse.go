@@ -0,0 +1,337 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/gateway/internal/transport/http/dto"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"github.com/sony/gobreaker"
+	"go.uber.org/zap"
+)
+
+type CreateWarehouseInput struct {
+	Code      string   `json:"code" validate:"required,max=32"`
+	Name      string   `json:"name" validate:"required,min=3,max=100"`
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitempty,gte=-180,lte=180"`
+	Priority  int32    `json:"priority"`
+}
+
+type TransferStockInput struct {
+	FromWarehouseID int64 `json:"from_warehouse_id" validate:"required,gt=0"`
+	ToWarehouseID   int64 `json:"to_warehouse_id" validate:"required,gt=0,nefield=FromWarehouseID"`
+	Quantity        int64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// AdjustStockInput changes stock by Delta units, negative to take them. The
+// product service checks that Reason fits the direction.
+type AdjustStockInput struct {
+	WarehouseID int64  `json:"warehouse_id" validate:"gte=0"`
+	Delta       int64  `json:"delta" validate:"required,ne=0"`
+	Reason      string `json:"reason" validate:"required,oneof=restock customer_return damaged lost correction"`
+	Note        string `json:"note" validate:"max=500"`
+}
+
+func (h *ProductHandler) CreateWarehouse(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	input := new(CreateWarehouseInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	req := &pb.CreateWarehouseRequest{
+		Code:     input.Code,
+		Name:     input.Name,
+		Priority: input.Priority,
+	}
+	if input.Latitude != nil && input.Longitude != nil {
+		req.HasLocation = true
+		req.Latitude = *input.Latitude
+		req.Longitude = *input.Longitude
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.Warehouse](h.cb, func() (*pb.Warehouse, error) {
+		return h.client.CreateWarehouse(ctx, req)
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"create warehouse failed",
+			zap.String("code", input.Code),
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	mylogger.Info(ctx, h.logger, "warehouse created", zap.Int64("warehouse_id", res.Id))
+
+	return c.Status(fiber.StatusCreated).JSON(dto.WarehouseFromProto(res))
+}
+
+func (h *ProductHandler) ListWarehouses(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	res, err := utils.ExecuteWithBreaker[*pb.ListWarehousesResponse](h.cb, func() (*pb.ListWarehousesResponse, error) {
+		return h.client.ListWarehouses(ctx, &pb.ListWarehousesRequest{})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"list warehouses failed",
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return sendList(c, unpagedList(dto.WarehouseListFromProto(res), len(res.GetWarehouses())))
+}
+
+func (h *ProductHandler) GetProductStock(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		mylogger.Warn(ctx, h.logger, "invalid product id", zap.String("id", idStr))
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.GetProductStockResponse](h.cb, func() (*pb.GetProductStockResponse, error) {
+		return h.client.GetProductStock(ctx, &pb.GetProductStockRequest{ProductId: id})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"get product stock failed",
+			zap.Int64("product_id", id),
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(dto.ProductStockFromProto(id, res))
+}
+
+func (h *ProductHandler) TransferStock(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		mylogger.Warn(ctx, h.logger, "invalid product id", zap.String("id", idStr))
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	input := new(TransferStockInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	_, err = utils.ExecuteWithBreaker[*pb.TransferStockResponse](h.cb, func() (*pb.TransferStockResponse, error) {
+		return h.client.TransferStock(ctx, &pb.TransferStockRequest{
+			ProductId:       id,
+			FromWarehouseId: input.FromWarehouseID,
+			ToWarehouseId:   input.ToWarehouseID,
+			Quantity:        input.Quantity,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"transfer stock failed",
+			zap.Int64("product_id", id),
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	mylogger.Info(
+		ctx,
+		h.logger,
+		"stock transferred",
+		zap.Int64("product_id", id),
+		zap.Int64("from_warehouse_id", input.FromWarehouseID),
+		zap.Int64("to_warehouse_id", input.ToWarehouseID),
+		zap.Int64("quantity", input.Quantity),
+	)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"success": true,
+	})
+}
+
+func (h *ProductHandler) AdjustStock(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), time.Second)
+	defer cancel()
+
+	idStr := c.Params("id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		mylogger.Warn(ctx, h.logger, "invalid product id", zap.String("id", idStr))
+
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Id is invalid",
+		})
+	}
+
+	input := new(AdjustStockInput)
+	if err := c.BodyParser(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "error parsing body",
+		})
+	}
+
+	if err := h.validate.Struct(input); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": utils.FormatValidationError(err),
+		})
+	}
+
+	res, err := utils.ExecuteWithBreaker[*pb.AdjustStockResponse](h.cb, func() (*pb.AdjustStockResponse, error) {
+		return h.client.AdjustStock(ctx, &pb.AdjustStockRequest{
+			ProductId:   id,
+			WarehouseId: input.WarehouseID,
+			Delta:       input.Delta,
+			Reason:      input.Reason,
+			Note:        input.Note,
+		})
+	})
+
+	if err != nil {
+		if errors.Is(err, gobreaker.ErrOpenState) {
+			mylogger.Warn(ctx, h.logger, "Circuit breaker open")
+
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"error": "Service temporarily unavailable",
+			})
+		}
+
+		httpStatus := utils.GRPCStatusToHTTP(err)
+
+		mylogger.Warn(
+			ctx,
+			h.logger,
+			"adjust stock failed",
+			zap.Int64("product_id", id),
+			zap.Int64("delta", input.Delta),
+			zap.String("reason", input.Reason),
+			zap.Int("http_status", httpStatus),
+			zap.Error(err),
+		)
+
+		return c.Status(httpStatus).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	mylogger.Info(
+		ctx,
+		h.logger,
+		"stock adjusted",
+		zap.Int64("product_id", id),
+		zap.Int64("delta", input.Delta),
+		zap.String("reason", input.Reason),
+	)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"product_id":     id,
+		"stock_quantity": res.StockQuantity,
+	})
+}
@@ -8,32 +8,150 @@ import (
 )
 
 type Handlers struct {
-	Auth    *handler.AuthHandler
-	Product *handler.ProductHandler
-	Order   *handler.OrderHandler
+	Auth         *handler.AuthHandler
+	Product      *handler.ProductHandler
+	Order        *handler.OrderHandler
+	Analytics    *handler.AnalyticsHandler
+	Payment      *handler.PaymentHandler
+	Notification *handler.NotificationHandler
+	Dashboard    *handler.DashboardHandler
+	Feed         *handler.FeedHandler
+	Ops          *handler.OpsHandler
 }
 
-func RegisterRoutes(app *fiber.App, h *Handlers, authClient pb.AuthServiceClient) {
-	authGroup := app.Group("/auth")
+// AuthThrottles guards the auth endpoints that invite guessing. A nil
+// handler leaves its route unthrottled.
+type AuthThrottles struct {
+	ForgotPassword fiber.Handler
+	Activate       fiber.Handler
+}
+
+// RegisterRoutes wires the routes. A nil productGuard leaves product reads
+// to the global rate limiter alone.
+func RegisterRoutes(app *fiber.App, h *Handlers, authClient pb.AuthServiceClient, throttles AuthThrottles, productGuard fiber.Handler) {
+	// Pages that start a session have no session to forge requests with.
+	authGroup := app.Group("/auth", middleware.NewCSRFMiddleware(middleware.CSRFConfig{
+		Exempt: middleware.ExemptPaths("/auth/register", "/auth/login", "/auth/reset-password", "/auth/forgot-password"),
+	}))
 
 	authGroup.Post("/register", h.Auth.Register)
 	authGroup.Post("/refresh", h.Auth.Refresh)
 	authGroup.Post("/login", h.Auth.Login)
 	authGroup.Post("/reset-password", h.Auth.ResetPassword)
-	authGroup.Post("/forgot-password", h.Auth.ForgotPassword)
-	authGroup.Get("/activate", h.Auth.Activate)
+	authGroup.Post("/forgot-password", throttled(throttles.ForgotPassword, h.Auth.ForgotPassword)...)
+	authGroup.Get("/activate", throttled(throttles.Activate, h.Auth.Activate)...)
 	authGroup.Post("/logout", h.Auth.Logout)
 
-	api := app.Group("/api", middleware.NewAuthMiddleware(authClient), middleware.NewIsActivatedMiddleware())
+	app.Get("/unsubscribe", h.Notification.Unsubscribe)
+	app.Post("/unsubscribe", h.Notification.Unsubscribe)
+
+	api := app.Group(
+		"/api",
+		middleware.NewCSRFMiddleware(middleware.CSRFConfig{}),
+		middleware.NewAuthMiddleware(authClient),
+	)
+
+	// Accounts can browse before confirming their email but not buy or
+	// change anything.
+	activated := middleware.NewIsActivatedMiddleware()
+
 	api.Get("/me", h.Auth.GetMe)
+	api.Patch("/me/locale", h.Auth.UpdateLocale)
+	api.Get("/me/dashboard", h.Dashboard.Get)
+
+	notifications := api.Group("/me/notifications")
+	notifications.Get("", h.Notification.List)
+	notifications.Get("/unread-count", h.Notification.UnreadCount)
+	notifications.Post("/read", h.Notification.MarkRead)
+
+	api.Get("/feed", throttled(productGuard, h.Feed.Get)...)
 
 	product := api.Group("/products")
-	product.Post("", h.Product.Create)
-	product.Post("/decrease-stock/:id", h.Product.DecreaseStock)
-	product.Delete("/:id", h.Product.DeleteProduct)
-	product.Get("/:id", h.Product.FindByID)
-	product.Get("", h.Product.ListProducts)
+	product.Post("", activated, h.Product.Create)
+	product.Post("/decrease-stock/:id", activated, h.Product.DecreaseStock)
+	product.Delete("/:id", activated, h.Product.DeleteProduct)
+	// Reads share one guard: scrapers walk the listing and then fetch each
+	// product it links to.
+	product.Get("/sku/:sku", throttled(productGuard, h.Product.FindBySKU)...)
+	product.Get("/:id/related", throttled(productGuard, h.Product.GetRelated)...)
+	product.Get("/:id", throttled(productGuard, h.Product.FindByID)...)
+	product.Get("", throttled(productGuard, h.Product.ListProducts)...)
 
-	order := api.Group("/orders")
+	order := api.Group("/orders", activated)
 	order.Post("", h.Order.Create)
+	order.Post("/:id/partial-reservation", h.Order.ResolvePartialReservation)
+	order.Post("/:id/cancel", h.Order.Cancel)
+	order.Get("/:id/timeline", h.Order.GetTimeline)
+	order.Get("/:id/invoice", h.Order.GetInvoice)
+	order.Post("/:id/returns", h.Order.RequestReturn)
+
+	api.Get("/me/returns", activated, h.Order.ListReturns)
+
+	paymentMethods := api.Group("/me/payment-methods", activated)
+	paymentMethods.Get("", h.Payment.ListPaymentMethods)
+	paymentMethods.Post("", h.Payment.AddPaymentMethod)
+	paymentMethods.Delete("/:id", h.Payment.DeletePaymentMethod)
+
+	giftCards := api.Group("/me/gift-cards", activated)
+	giftCards.Get("", h.Payment.ListGiftCards)
+	giftCards.Post("", h.Payment.ClaimGiftCard)
+
+	addresses := api.Group("/me/addresses", activated)
+	addresses.Get("", h.Order.ListAddresses)
+	addresses.Post("", h.Order.AddAddress)
+	addresses.Put("/:id", h.Order.UpdateAddress)
+	addresses.Delete("/:id", h.Order.DeleteAddress)
+
+	admin := api.Group("/admin", activated, middleware.NewRequireRoleMiddleware("admin"))
+
+	adminProducts := admin.Group("/products")
+	adminProducts.Get("/deleted", h.Product.ListDeletedProducts)
+	adminProducts.Get("/export", h.Product.ExportProducts)
+	adminProducts.Get("/stock/watch", h.Product.WatchStock)
+	adminProducts.Post("/:id/restore", h.Product.RestoreProduct)
+	adminProducts.Get("/:id/history", h.Product.GetProductHistory)
+	adminProducts.Get("/:id/stock", h.Product.GetProductStock)
+	adminProducts.Post("/:id/stock/transfer", h.Product.TransferStock)
+	adminProducts.Post("/:id/stock/adjust", h.Product.AdjustStock)
+
+	admin.Get("/orders/export", h.Order.ExportOrders)
+	admin.Post("/orders/manual", h.Order.CreateManualOrder)
+	admin.Post("/orders/:id/status", h.Order.ForceStatus)
+	admin.Get("/orders/:id/annotations", h.Order.ListOrderAnnotations)
+	admin.Post("/orders/:id/notes", h.Order.AddOrderNote)
+	admin.Put("/orders/:id/flags/:flag", h.Order.SetOrderFlag)
+	admin.Delete("/orders/:id/flags/:flag", h.Order.ClearOrderFlag)
+	admin.Get("/purchase-caps", h.Order.ListPurchaseCaps)
+	admin.Put("/purchase-caps/:product_id", h.Order.SetPurchaseCap)
+	admin.Get("/returns", h.Order.ListAllReturns)
+	admin.Post("/returns/:id/review", h.Order.ReviewReturn)
+
+	admin.Post("/gift-cards", h.Payment.IssueGiftCard)
+	admin.Post("/gift-cards/:id/void", h.Payment.VoidGiftCard)
+
+	warehouses := admin.Group("/warehouses")
+	warehouses.Get("", h.Product.ListWarehouses)
+	warehouses.Post("", h.Product.CreateWarehouse)
+
+	campaigns := admin.Group("/campaigns")
+	campaigns.Get("", h.Product.ListCampaigns)
+	campaigns.Post("", h.Product.CreateCampaign)
+
+	analytics := admin.Group("/analytics")
+	analytics.Get("/orders", h.Analytics.OrderVolume)
+	analytics.Get("/funnel", h.Analytics.Funnel)
+	analytics.Get("/top-products", h.Analytics.TopProducts)
+
+	ops := admin.Group("/ops")
+	ops.Get("/status", h.Ops.Status)
+	ops.Get("/breakers", h.Ops.Breakers)
+	ops.Get("/orders/:id/trace", h.Ops.OrderTrace)
+}
+
+func throttled(throttle, h fiber.Handler) []fiber.Handler {
+	if throttle == nil {
+		return []fiber.Handler{h}
+	}
+
+	return []fiber.Handler{throttle, h}
 }
@@ -0,0 +1,235 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveFields are JSON keys, credentials and personal data, whose values
+// never reach the access log. They are matched case-insensitively at any
+// depth.
+var sensitiveFields = map[string]bool{
+	"password":         true,
+	"new_password":     true,
+	"old_password":     true,
+	"token":            true,
+	"access_token":     true,
+	"refresh_token":    true,
+	"activation_token": true,
+	"csrf_token":       true,
+	"secret":           true,
+	"api_key":          true,
+	"card_number":      true,
+	"cvv":              true,
+	"email":            true,
+	"phone":            true,
+}
+
+var accessLogDropped = prometheus.NewCounter(prometheus.CounterOpts{
+	Name: "gateway_access_log_dropped_total",
+	Help: "Access log entries dropped because the writer fell behind.",
+})
+
+// RegisterAccessLogMetrics exposes the access log metrics on reg.
+func RegisterAccessLogMetrics(reg prometheus.Registerer) error {
+	return reg.Register(accessLogDropped)
+}
+
+type AccessLogConfig struct {
+	Logger *zap.Logger
+	// BodySampleRate is the share of requests, from 0 to 1, that are logged
+	// with their request and response bodies.
+	BodySampleRate float64
+	// MaxBodyBytes cuts logged bodies after redaction.
+	MaxBodyBytes int
+	// Buffer is how many entries may wait for the writer. Entries past it
+	// are dropped rather than slowing requests down.
+	Buffer int
+}
+
+type accessEntry struct {
+	method   string
+	route    string
+	path     string
+	status   int
+	latency  time.Duration
+	userID   int64
+	traceID  string
+	spanID   string
+	reqBody  []byte
+	respBody []byte
+	sampled  bool
+}
+
+// AccessLogger writes one line per request from its own goroutine, so
+// encoding and redacting never run on the request path.
+type AccessLogger struct {
+	cfg     AccessLogConfig
+	entries chan accessEntry
+}
+
+func NewAccessLogger(cfg AccessLogConfig) *AccessLogger {
+	return &AccessLogger{cfg: cfg, entries: make(chan accessEntry, cfg.Buffer)}
+}
+
+// Handler records the request once the rest of the chain is done. Use it
+// right after the tracing middleware: it reads the span and the user that
+// later middleware put in the context, and otelfiber restores the context
+// when it returns.
+func (l *AccessLogger) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		entry := accessEntry{
+			method:  c.Method(),
+			route:   c.Route().Path,
+			path:    c.Path(),
+			status:  c.Response().StatusCode(),
+			latency: time.Since(start),
+		}
+
+		// The error handler writes the response after this returns.
+		if err != nil {
+			entry.status = fiber.StatusInternalServerError
+			var fe *fiber.Error
+			if errors.As(err, &fe) {
+				entry.status = fe.Code
+			}
+		}
+
+		ctx := c.UserContext()
+		if userID, ok := authctx.UserIDFromContext(ctx); ok {
+			entry.userID = userID
+		}
+		if spanCtx := trace.SpanContextFromContext(ctx); spanCtx.IsValid() {
+			entry.traceID = spanCtx.TraceID().String()
+			entry.spanID = spanCtx.SpanID().String()
+		}
+
+		if l.cfg.BodySampleRate > 0 && rand.Float64() < l.cfg.BodySampleRate {
+			// fasthttp reuses both buffers once the handler returns.
+			entry.sampled = true
+			entry.reqBody = append([]byte(nil), c.Body()...)
+			// Reading a streamed body would buffer all of it, e.g. a whole
+			// export.
+			if !c.Response().IsBodyStream() {
+				entry.respBody = append([]byte(nil), c.Response().Body()...)
+			}
+		}
+
+		select {
+		case l.entries <- entry:
+		default:
+			accessLogDropped.Inc()
+		}
+
+		return err
+	}
+}
+
+// Start writes entries until ctx is cancelled and then flushes what is left.
+// Stop it after the HTTP server so the last requests are written too.
+func (l *AccessLogger) Start(ctx context.Context) error {
+	for {
+		select {
+		case entry := <-l.entries:
+			l.write(entry)
+		case <-ctx.Done():
+			for {
+				select {
+				case entry := <-l.entries:
+					l.write(entry)
+				default:
+					return nil
+				}
+			}
+		}
+	}
+}
+
+func (l *AccessLogger) write(e accessEntry) {
+	fields := []zap.Field{
+		zap.String("method", e.method),
+		zap.String("route", e.route),
+		zap.String("path", e.path),
+		zap.Int("status", e.status),
+		zap.Duration("latency", e.latency),
+	}
+	if e.userID != 0 {
+		fields = append(fields, zap.Int64("user_id", e.userID))
+	}
+	if e.traceID != "" {
+		fields = append(fields, zap.String("trace_id", e.traceID), zap.String("span_id", e.spanID))
+	}
+	if e.sampled {
+		fields = append(fields,
+			zap.String("request_body", scrubBody(e.reqBody, l.cfg.MaxBodyBytes)),
+			zap.String("response_body", scrubBody(e.respBody, l.cfg.MaxBodyBytes)),
+		)
+	}
+
+	if e.status >= fiber.StatusInternalServerError {
+		l.cfg.Logger.Error("request", fields...)
+		return
+	}
+
+	l.cfg.Logger.Info("request", fields...)
+}
+
+// scrubBody redacts sensitive fields of a JSON body. Anything that is not
+// JSON is left out entirely: there is no telling what is in it.
+func scrubBody(body []byte, max int) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return "[non-JSON body omitted]"
+	}
+
+	out, err := json.Marshal(scrub(v))
+	if err != nil {
+		return "[body omitted]"
+	}
+
+	if max > 0 && len(out) > max {
+		return string(out[:max]) + "...[truncated]"
+	}
+
+	return string(out)
+}
+
+func scrub(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, val := range v {
+			if sensitiveFields[strings.ToLower(key)] {
+				v[key] = redacted
+				continue
+			}
+			v[key] = scrub(val)
+		}
+		return v
+	case []any:
+		for i, val := range v {
+			v[i] = scrub(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// PartnerKeyHeader carries the API key of an allowlisted partner.
+const PartnerKeyHeader = "X-API-Key"
+
+var botGuardRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "gateway_bot_guard_requests_total",
+	Help: "Requests seen by a bot guard, by outcome: partner, flagged, challenged, solved or error.",
+}, []string{"guard", "outcome"})
+
+// RegisterBotGuardMetrics exposes the bot guard metrics on reg.
+func RegisterBotGuardMetrics(reg prometheus.Registerer) error {
+	return reg.Register(botGuardRequests)
+}
+
+// Challenge lets a flagged client prove it is not a scraper.
+type Challenge interface {
+	// Issue returns what the client needs to solve the challenge. It is sent
+	// under "challenge" in the 429 body.
+	Issue(c *fiber.Ctx) (fiber.Map, error)
+	// Verify reports whether the request carries a solved challenge.
+	Verify(c *fiber.Ctx) (bool, error)
+}
+
+type BotGuardConfig struct {
+	Client goredis.UniversalClient
+	// Name namespaces the counters and labels the metrics.
+	Name string
+	// A client IP making more than BurstMax requests within BurstWindow is
+	// flagged for SuspectFor. The window is short on purpose: the global
+	// limiter already covers sustained volume.
+	BurstWindow time.Duration
+	BurstMax    int64
+	SuspectFor  time.Duration
+	// Challenge lets flagged clients clear the flag early. Nil turns them
+	// away until SuspectFor has passed.
+	Challenge Challenge
+	// PartnerKeys maps partner names to the API keys they send in
+	// PartnerKeyHeader. Partners are never flagged.
+	PartnerKeys map[string]string
+}
+
+// NewBotGuardMiddleware flags client IPs that fetch in bursts, the way list
+// scrapers walk pages, and answers 429 with a challenge until the flag
+// expires or the challenge is solved. An unknown API key is treated like no
+// key, so the guard does not tell anyone which keys exist. If Redis is
+// unreachable requests go through, like with NewThrottleMiddleware.
+func NewBotGuardMiddleware(cfg BotGuardConfig) fiber.Handler {
+	partners := make([][32]byte, 0, len(cfg.PartnerKeys))
+	for _, key := range cfg.PartnerKeys {
+		partners = append(partners, sha256.Sum256([]byte(key)))
+	}
+
+	return func(c *fiber.Ctx) error {
+		if key := c.Get(PartnerKeyHeader); key != "" && isPartner(partners, key) {
+			botGuardRequests.WithLabelValues(cfg.Name, "partner").Inc()
+			return c.Next()
+		}
+
+		ctx, cancel := context.WithTimeout(c.UserContext(), throttleOpTimeout)
+		defer cancel()
+
+		ip := c.IP()
+		suspectKey := "gateway:bot:" + cfg.Name + ":suspect:" + ip
+		burstKey := "gateway:bot:" + cfg.Name + ":burst:" + ip
+
+		suspectFor, err := cfg.Client.TTL(ctx, suspectKey).Result()
+		if err != nil {
+			botGuardRequests.WithLabelValues(cfg.Name, "error").Inc()
+			return c.Next()
+		}
+
+		if suspectFor <= 0 {
+			retryAfter, err := hit(ctx, cfg.Client, burstKey, cfg.BurstWindow, cfg.BurstMax)
+			if err != nil {
+				botGuardRequests.WithLabelValues(cfg.Name, "error").Inc()
+				return c.Next()
+			}
+			if retryAfter == 0 {
+				return c.Next()
+			}
+
+			if err := cfg.Client.Set(ctx, suspectKey, 1, cfg.SuspectFor).Err(); err != nil {
+				botGuardRequests.WithLabelValues(cfg.Name, "error").Inc()
+				return c.Next()
+			}
+			botGuardRequests.WithLabelValues(cfg.Name, "flagged").Inc()
+
+			return challenge(c, cfg, cfg.SuspectFor)
+		}
+
+		if cfg.Challenge != nil {
+			solved, err := cfg.Challenge.Verify(c)
+			if err != nil {
+				botGuardRequests.WithLabelValues(cfg.Name, "error").Inc()
+				return c.Next()
+			}
+			if solved {
+				// A fresh burst window, or the next request would flag the
+				// client again straight away.
+				if err := cfg.Client.Del(ctx, suspectKey, burstKey).Err(); err != nil {
+					botGuardRequests.WithLabelValues(cfg.Name, "error").Inc()
+				}
+				botGuardRequests.WithLabelValues(cfg.Name, "solved").Inc()
+				return c.Next()
+			}
+		}
+
+		return challenge(c, cfg, suspectFor)
+	}
+}
+
+func isPartner(partners [][32]byte, key string) bool {
+	sum := sha256.Sum256([]byte(key))
+
+	found := false
+	for _, partner := range partners {
+		if subtle.ConstantTimeCompare(sum[:], partner[:]) == 1 {
+			found = true
+		}
+	}
+
+	return found
+}
+
+func challenge(c *fiber.Ctx, cfg BotGuardConfig, retryAfter time.Duration) error {
+	botGuardRequests.WithLabelValues(cfg.Name, "challenged").Inc()
+
+	if cfg.Challenge == nil {
+		return tooManyAttempts(c, retryAfter)
+	}
+
+	issued, err := cfg.Challenge.Issue(c)
+	if err != nil {
+		return tooManyAttempts(c, retryAfter)
+	}
+
+	c.Set(fiber.HeaderRetryAfter, retryAfterSeconds(retryAfter))
+
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error":     "Too many requests. Solve the challenge or try again later.",
+		"challenge": issued,
+	})
+}
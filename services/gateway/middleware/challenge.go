@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Headers a client answers a challenge with.
+const (
+	ChallengeTokenHeader    = "X-Challenge-Token"
+	ChallengeSolutionHeader = "X-Challenge-Solution"
+)
+
+const (
+	powTokenTTL    = 5 * time.Minute
+	captchaTimeout = 3 * time.Second
+)
+
+// ProofOfWork asks for a nonce whose SHA-256 over "token:nonce" starts with
+// difficulty zero bits. Each extra bit doubles the work, which is nothing
+// for a person loading a page and adds up for a scraper doing it over and
+// over. Tokens are signed, so issuing them keeps no state; Redis only
+// remembers the spent ones.
+type ProofOfWork struct {
+	client     goredis.UniversalClient
+	secret     []byte
+	difficulty int
+}
+
+func NewProofOfWork(client goredis.UniversalClient, secret string, difficulty int) *ProofOfWork {
+	return &ProofOfWork{client: client, secret: []byte(secret), difficulty: difficulty}
+}
+
+func (p *ProofOfWork) Issue(c *fiber.Ctx) (fiber.Map, error) {
+	expires := time.Now().Add(powTokenTTL).Unix()
+	payload := c.IP() + "|" + strconv.FormatInt(expires, 10)
+
+	token := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(p.sign(payload))
+
+	return fiber.Map{
+		"type":       "pow",
+		"token":      token,
+		"difficulty": p.difficulty,
+	}, nil
+}
+
+// Verify accepts a token only from the IP it was issued to, before it
+// expires and once.
+func (p *ProofOfWork) Verify(c *fiber.Ctx) (bool, error) {
+	token := c.Get(ChallengeTokenHeader)
+	nonce := c.Get(ChallengeSolutionHeader)
+	if token == "" || nonce == "" {
+		return false, nil
+	}
+
+	encodedPayload, encodedSig, ok := strings.Cut(token, ".")
+	if !ok {
+		return false, nil
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return false, nil
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(encodedSig)
+	if err != nil || !hmac.Equal(sig, p.sign(string(payload))) {
+		return false, nil
+	}
+
+	ip, rawExpires, ok := strings.Cut(string(payload), "|")
+	if !ok || ip != c.IP() {
+		return false, nil
+	}
+	expires, err := strconv.ParseInt(rawExpires, 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return false, nil
+	}
+
+	sum := sha256.Sum256([]byte(token + ":" + nonce))
+	if leadingZeroBits(sum[:]) < p.difficulty {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), throttleOpTimeout)
+	defer cancel()
+
+	// Without this one solved token would clear every later flag until it
+	// expires.
+	first, err := p.client.SetNX(ctx, "gateway:bot:pow:spent:"+encodedSig, 1, powTokenTTL).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return first, nil
+}
+
+func (p *ProofOfWork) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, v := range b {
+		if v != 0 {
+			return n + bits.LeadingZeros8(v)
+		}
+		n += 8
+	}
+
+	return n
+}
+
+// Captcha hands the decision to a CAPTCHA provider with a siteverify
+// endpoint, such as Turnstile or hCaptcha. The client renders the widget
+// with the site key from the challenge and sends the token it gets in
+// ChallengeSolutionHeader.
+type Captcha struct {
+	verifyURL string
+	secret    string
+	siteKey   string
+	client    *http.Client
+}
+
+func NewCaptcha(verifyURL, secret, siteKey string) *Captcha {
+	return &Captcha{
+		verifyURL: verifyURL,
+		secret:    secret,
+		siteKey:   siteKey,
+		client:    &http.Client{Timeout: captchaTimeout},
+	}
+}
+
+func (c *Captcha) Issue(*fiber.Ctx) (fiber.Map, error) {
+	return fiber.Map{
+		"type":     "captcha",
+		"site_key": c.siteKey,
+	}, nil
+}
+
+func (c *Captcha) Verify(ctx *fiber.Ctx) (bool, error) {
+	response := ctx.Get(ChallengeSolutionHeader)
+	if response == "" {
+		return false, nil
+	}
+
+	form := url.Values{
+		"secret":   {c.secret},
+		"response": {response},
+		"remoteip": {ctx.IP()},
+	}
+
+	req, err := http.NewRequestWithContext(ctx.UserContext(), http.MethodPost, c.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha verify returned %s", res.Status)
+	}
+
+	var body struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		return false, err
+	}
+
+	return body.Success, nil
+}
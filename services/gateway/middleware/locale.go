@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"embed"
+	"encoding/json"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/pkg/i18n"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// Error messages are keyed by their English text, gettext style, so handlers
+// keep returning plain English and only translations live in locales/.
+var errorMessages = i18n.NewBundle(language.English).MustLoadFS(localeFiles, "locales")
+
+// NewLocaleMiddleware negotiates the response language from Accept-Language,
+// exposes it via Locale, and translates {"error": "..."} bodies of failed
+// responses. Messages without a translation, such as upstream gRPC errors,
+// pass through unchanged.
+func NewLocaleMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		loc := errorMessages.Localizer(c.Get(fiber.HeaderAcceptLanguage))
+		c.Locals("locale", loc.Lang())
+
+		err := c.Next()
+
+		c.Set(fiber.HeaderContentLanguage, loc.Lang())
+
+		res := c.Response()
+		if res.StatusCode() < fiber.StatusBadRequest ||
+			!strings.HasPrefix(string(res.Header.ContentType()), fiber.MIMEApplicationJSON) {
+			return err
+		}
+
+		var body map[string]any
+		if json.Unmarshal(res.Body(), &body) != nil {
+			return err
+		}
+
+		msg, ok := body["error"].(string)
+		if !ok {
+			return err
+		}
+
+		if translated := loc.T(msg, nil); translated != msg {
+			body["error"] = translated
+			if data, marshalErr := json.Marshal(body); marshalErr == nil {
+				res.SetBodyRaw(data)
+			}
+		}
+
+		return err
+	}
+}
+
+// Locale is the language negotiated for the request, e.g. "ru".
+func Locale(c *fiber.Ctx) string {
+	if locale, ok := c.Locals("locale").(string); ok {
+		return locale
+	}
+
+	return language.English.String()
+}
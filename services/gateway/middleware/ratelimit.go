@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+)
+
+// ReloadableLimiter is fiber's limiter with limits that can change while the
+// gateway runs. fiber reads Max and Expiration once, so a change builds a
+// new limiter over the same storage; counters carry over.
+type ReloadableLimiter struct {
+	base    limiter.Config
+	current atomic.Pointer[fiber.Handler]
+}
+
+// NewReloadableLimiter needs cfg.Storage to be set: the default storage is
+// private to each limiter and would reset the counters on every change.
+func NewReloadableLimiter(cfg limiter.Config) *ReloadableLimiter {
+	l := &ReloadableLimiter{base: cfg}
+	l.build(cfg.Max, cfg.Expiration)
+
+	return l
+}
+
+// SetLimits allows limit requests per key in each window. Calls must not
+// overlap; the config watcher runs its subscribers one at a time.
+func (l *ReloadableLimiter) SetLimits(limit int, window time.Duration) {
+	if l.base.Max == limit && l.base.Expiration == window {
+		return
+	}
+
+	l.build(limit, window)
+}
+
+func (l *ReloadableLimiter) build(limit int, window time.Duration) {
+	l.base.Max = limit
+	l.base.Expiration = window
+
+	handler := limiter.New(l.base)
+	l.current.Store(&handler)
+}
+
+func (l *ReloadableLimiter) Handler() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return (*l.current.Load())(c)
+	}
+}
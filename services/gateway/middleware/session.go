@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Cookie names used when the gateway delivers tokens as cookies instead of
+// JSON bodies.
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	// CSRFCookie is readable by scripts: the front-end echoes it in
+	// CSRFHeader, which a cross-site form cannot do (double-submit).
+	CSRFCookie = "csrf_token"
+	CSRFHeader = "X-CSRF-Token"
+)
+
+// NewCSRFToken returns a random token for CSRFCookie.
+func NewCSRFToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// ValidCSRF reports whether the request echoes its CSRF cookie in the header.
+func ValidCSRF(c *fiber.Ctx) bool {
+	cookie := c.Cookies(CSRFCookie)
+	header := c.Get(CSRFHeader)
+	if cookie == "" || header == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) == 1
+}
+
+// isSafeMethod reports whether the method must not change state, so needs no
+// CSRF token.
+func isSafeMethod(method string) bool {
+	switch method {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions, fiber.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
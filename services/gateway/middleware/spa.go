@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	spaIndex = "index.html"
+
+	// Fingerprinted assets never change under the same name.
+	cacheImmutable = "public, max-age=31536000, immutable"
+	// Everything else, index.html above all, is revalidated by ETag so a
+	// deploy shows up on the next load.
+	cacheRevalidate = "no-cache"
+)
+
+type SPAConfig struct {
+	// Files is the built front-end with index.html at its root.
+	Files fs.FS
+	// ImmutablePrefix is the directory of fingerprinted assets, e.g.
+	// "assets/" for Vite builds.
+	ImmutablePrefix string
+	// Exclude lists path prefixes that belong to the API. Unknown paths
+	// under them keep their JSON 404 instead of getting the app.
+	Exclude []string
+}
+
+// NewSPAMiddleware serves a single-page app from Files. Paths that are not
+// files and have no extension get index.html, so the app's history-API
+// routes survive a reload. Register it after every API route.
+func NewSPAMiddleware(cfg SPAConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+			return c.Next()
+		}
+
+		for _, prefix := range cfg.Exclude {
+			if c.Path() == prefix || strings.HasPrefix(c.Path(), prefix+"/") {
+				return c.Next()
+			}
+		}
+
+		name := strings.TrimPrefix(path.Clean("/"+c.Path()), "/")
+		if name == "" {
+			name = spaIndex
+		}
+
+		body, err := fs.ReadFile(cfg.Files, name)
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) && !isDirErr(cfg.Files, name) {
+				return err
+			}
+
+			// A missing script or image is a 404, not the app.
+			if path.Ext(name) != "" {
+				return c.Next()
+			}
+
+			name = spaIndex
+			if body, err = fs.ReadFile(cfg.Files, name); err != nil {
+				return err
+			}
+		}
+
+		if cfg.ImmutablePrefix != "" && strings.HasPrefix(name, cfg.ImmutablePrefix) {
+			c.Set(fiber.HeaderCacheControl, cacheImmutable)
+		} else {
+			c.Set(fiber.HeaderCacheControl, cacheRevalidate)
+		}
+
+		etag := etagOf(body)
+		c.Set(fiber.HeaderETag, etag)
+		if c.Get(fiber.HeaderIfNoneMatch) == etag {
+			return c.SendStatus(fiber.StatusNotModified)
+		}
+
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = fiber.MIMEOctetStream
+		}
+		c.Set(fiber.HeaderContentType, contentType)
+
+		return c.Send(body)
+	}
+}
+
+// isDirErr reports whether name is a directory, which fs.ReadFile refuses
+// with an error that is not fs.ErrNotExist.
+func isDirErr(files fs.FS, name string) bool {
+	info, err := fs.Stat(files, name)
+	return err == nil && info.IsDir()
+}
+
+func etagOf(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+)
+
+type TenantConfig struct {
+	// Domains maps storefront hosts onto tenants, e.g.
+	// "shop.example.com" -> "shop".
+	Domains map[string]string
+	// Header names the request header that picks the tenant on hosts
+	// without a mapping, e.g. for API clients.
+	Header string
+	// Allowed lists the tenants that may be picked by header besides the
+	// ones in Domains and tenant.DefaultID.
+	Allowed []string
+}
+
+// Validate rejects tenant ids the services would refuse anyway.
+func (cfg TenantConfig) Validate() error {
+	for host, id := range cfg.Domains {
+		if err := tenant.Validate(id); err != nil {
+			return fmt.Errorf("tenant %q of %s: %w", id, host, err)
+		}
+	}
+
+	for _, id := range cfg.Allowed {
+		if err := tenant.Validate(id); err != nil {
+			return fmt.Errorf("tenant %q: %w", id, err)
+		}
+	}
+
+	return nil
+}
+
+// NewTenantMiddleware resolves the tenant of a request from its host, then
+// from cfg.Header, falling back to tenant.DefaultID. The tenant is exposed via
+// Locals("tenantId") and put on the user context, from which the gRPC clients
+// forward it. Unknown tenants get 404 so a typo never creates an empty store.
+func NewTenantMiddleware(cfg TenantConfig) fiber.Handler {
+	domains := make(map[string]string, len(cfg.Domains))
+	known := map[string]bool{tenant.DefaultID: true}
+	for host, id := range cfg.Domains {
+		domains[strings.ToLower(host)] = id
+		known[id] = true
+	}
+	for _, id := range cfg.Allowed {
+		known[id] = true
+	}
+
+	return func(c *fiber.Ctx) error {
+		id, ok := domains[requestHost(c)]
+		if !ok {
+			id = strings.TrimSpace(c.Get(cfg.Header))
+		}
+		if id == "" {
+			id = tenant.DefaultID
+		}
+
+		if !known[id] {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Unknown tenant"})
+		}
+
+		c.Locals("tenantId", id)
+		c.SetUserContext(tenant.WithID(c.UserContext(), id))
+
+		return c.Next()
+	}
+}
+
+func requestHost(c *fiber.Ctx) string {
+	host := c.Hostname()
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	return strings.ToLower(host)
+}
@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	goredis "github.com/redis/go-redis/v9"
+)
+
+const throttleOpTimeout = 100 * time.Millisecond
+
+var (
+	throttledAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_throttled_attempts_total",
+		Help: "Requests rejected by a throttle, by throttle and the subject (ip, key) that hit its limit.",
+	}, []string{"throttle", "subject"})
+	throttleErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "gateway_throttle_errors_total",
+		Help: "Requests let through unchecked because the throttle counters were unavailable.",
+	}, []string{"throttle"})
+)
+
+// RegisterThrottleMetrics exposes the throttle metrics on reg.
+func RegisterThrottleMetrics(reg prometheus.Registerer) error {
+	if err := reg.Register(throttledAttempts); err != nil {
+		return err
+	}
+
+	return reg.Register(throttleErrors)
+}
+
+type ThrottleConfig struct {
+	Client goredis.UniversalClient
+	// Name namespaces the counters and labels the metrics.
+	Name   string
+	Window time.Duration
+	// MaxPerIP caps attempts per client IP within Window; 0 disables it.
+	MaxPerIP int64
+	// Key picks a second subject to count, e.g. the email an attempt
+	// targets, so spreading attempts over many IPs does not help. An empty
+	// key is not counted.
+	Key       func(c *fiber.Ctx) string
+	MaxPerKey int64
+}
+
+// NewThrottleMiddleware counts attempts in fixed Redis windows shared by all
+// gateway replicas and answers 429 once a subject is over its limit. Every
+// attempt counts, successful or not, so an attacker learns nothing from which
+// ones were throttled. If Redis is unreachable requests go through: the
+// global rate limiter still applies and auth must stay usable.
+func NewThrottleMiddleware(cfg ThrottleConfig) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithTimeout(c.UserContext(), throttleOpTimeout)
+		defer cancel()
+
+		if cfg.MaxPerIP > 0 {
+			retryAfter, err := hit(ctx, cfg.Client, throttleKey(cfg, "ip:"+c.IP()), cfg.Window, cfg.MaxPerIP)
+			if err != nil {
+				throttleErrors.WithLabelValues(cfg.Name).Inc()
+				return c.Next()
+			}
+			if retryAfter > 0 {
+				throttledAttempts.WithLabelValues(cfg.Name, "ip").Inc()
+				return tooManyAttempts(c, retryAfter)
+			}
+		}
+
+		if cfg.Key == nil || cfg.MaxPerKey <= 0 {
+			return c.Next()
+		}
+
+		key := cfg.Key(c)
+		if key == "" {
+			return c.Next()
+		}
+
+		retryAfter, err := hit(ctx, cfg.Client, throttleKey(cfg, "key:"+key), cfg.Window, cfg.MaxPerKey)
+		if err != nil {
+			throttleErrors.WithLabelValues(cfg.Name).Inc()
+			return c.Next()
+		}
+		if retryAfter > 0 {
+			throttledAttempts.WithLabelValues(cfg.Name, "key").Inc()
+			return tooManyAttempts(c, retryAfter)
+		}
+
+		return c.Next()
+	}
+}
+
+// EmailKey reads the "email" field of a JSON body. The email is hashed so
+// addresses do not sit in Redis in clear text.
+func EmailKey(c *fiber.Ctx) string {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := c.BodyParser(&body); err != nil {
+		return ""
+	}
+
+	email := strings.ToLower(strings.TrimSpace(body.Email))
+	if email == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(email))
+	return hex.EncodeToString(sum[:])
+}
+
+func throttleKey(cfg ThrottleConfig, subject string) string {
+	return "gateway:throttle:" + cfg.Name + ":" + subject
+}
+
+// hit counts an attempt at key and returns how long the subject has to wait
+// when it is over max within window, or 0 when the attempt is allowed.
+func hit(ctx context.Context, client goredis.UniversalClient, key string, window time.Duration, max int64) (time.Duration, error) {
+	var incr *goredis.IntCmd
+	var ttl *goredis.DurationCmd
+	_, err := client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		// NX keeps the window fixed instead of sliding with every attempt.
+		pipe.ExpireNX(ctx, key, window)
+		ttl = pipe.TTL(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if incr.Val() <= max {
+		return 0, nil
+	}
+
+	if ttl.Val() > 0 {
+		return ttl.Val(), nil
+	}
+
+	return window, nil
+}
+
+func tooManyAttempts(c *fiber.Ctx, retryAfter time.Duration) error {
+	c.Set(fiber.HeaderRetryAfter, retryAfterSeconds(retryAfter))
+
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error": "Too many attempts. Try again later.",
+	})
+}
+
+func retryAfterSeconds(d time.Duration) string {
+	return strconv.Itoa(int(d.Seconds()) + 1)
+}
@@ -0,0 +1,22 @@
+// Package web holds the front-end the gateway can serve itself. The build
+// of the SPA is copied into dist/ before the gateway is compiled; the page
+// checked in here only stands in for it.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed all:dist
+var dist embed.FS
+
+// Dist is the built front-end, index.html at its root.
+func Dist() fs.FS {
+	sub, err := fs.Sub(dist, "dist")
+	if err != nil {
+		panic(err)
+	}
+
+	return sub
+}
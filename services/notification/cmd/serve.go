@@ -0,0 +1,357 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/sakashimaa/go-pet-project/notification/internal/infrastructure/email"
+	"github.com/sakashimaa/go-pet-project/notification/internal/repository"
+	"github.com/sakashimaa/go-pet-project/notification/internal/service"
+	notificationGrpc "github.com/sakashimaa/go-pet-project/notification/transport/grpc"
+	debugHttp "github.com/sakashimaa/go-pet-project/notification/transport/http"
+	"github.com/sakashimaa/go-pet-project/notification/transport/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/app"
+	"github.com/sakashimaa/go-pet-project/pkg/config"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/debug"
+	"github.com/sakashimaa/go-pet-project/pkg/jobs"
+	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/lock"
+	"github.com/sakashimaa/go-pet-project/pkg/retention"
+	"github.com/sakashimaa/go-pet-project/pkg/servicestatus"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	analyticsPb "github.com/sakashimaa/go-pet-project/proto/analytics"
+	notificationPb "github.com/sakashimaa/go-pet-project/proto/notification"
+	statusPb "github.com/sakashimaa/go-pet-project/proto/status"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func serve(ctx context.Context) error {
+	tp, err := utils.InitTracer(ctx, "notification-service")
+	if err != nil {
+		log.Fatalf("Error starting telemetry: %v", err)
+	}
+
+	cfg, err := config.LoadLoggerConfig()
+	if err != nil {
+		log.Fatalf("failed to load logger config: %v", err)
+	}
+
+	logger, err := config.NewLogger(cfg)
+	if err != nil {
+		log.Fatalf("Error creating logger: %v", err)
+	}
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Fatalf("error syncing logger: %v", err)
+		}
+	}()
+
+	pool, err := db.NewPostgresDB(utils.ParseWithFallback("DB_URL", ""))
+	if err != nil {
+		log.Fatalf("error creating postgres db: %v", err)
+	}
+
+	runner := app.NewRunner(logger)
+	runner.Add(app.Component{Name: "telemetry", Stop: tp.Shutdown})
+	runner.Add(app.Component{Name: "postgres", Stop: app.Func(pool.Close)})
+
+	locker := lock.NewPostgres(pool, logger)
+
+	kafkaConfig, err := kafka2.LoadConfig("notification-service")
+	if err != nil {
+		log.Fatalf("error loading kafka config: %v", err)
+	}
+	emailSender := newEmailSender(ctx, logger)
+	runner.Add(app.Component{Name: "email sender", Stop: app.Closer(emailSender.Close)})
+	inboxService := service.NewInboxService(repository.NewNotificationRepository(pool), logger)
+	notificationService := service.NewNotificationService(emailSender, inboxService, logger, pool)
+
+	unsubscribeSecret := utils.ParseWithFallback("UNSUBSCRIBE_SECRET", "")
+	if unsubscribeSecret == "" {
+		log.Fatalf("UNSUBSCRIBE_SECRET is required")
+	}
+	suppressionService := service.NewSuppressionService(
+		repository.NewSuppressionRepository(pool),
+		unsubscribeSecret,
+		utils.ParseWithFallback("UNSUBSCRIBE_BASE_URL", "http://localhost:3000/unsubscribe"),
+		logger,
+	)
+
+	encryptionConfig, err := kafka2.LoadEncryptionConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption config: %v", err)
+	}
+
+	keyring, err := encryptionConfig.Keyring()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption keys: %v", err)
+	}
+
+	priorityLane := kafka.PriorityLane()
+	priorityLane.Concurrency = parseConcurrency("NOTIFICATION_PRIORITY_CONCURRENCY", priorityLane.Concurrency)
+	bulkLane := kafka.BulkLane()
+	bulkLane.Concurrency = parseConcurrency("NOTIFICATION_BULK_CONCURRENCY", bulkLane.Concurrency)
+
+	consumer := kafka.NewConsumer(notificationService, logger, priorityLane, bulkLane)
+
+	if utils.ParseWithFallback("NOTIFICATION_RETRY_TOPICS", "true") == "true" {
+		retryProducer, err := kafka2.NewProducer(kafkaConfig)
+		if err != nil {
+			log.Fatalf("error creating retry producer: %v", err)
+		}
+		runner.Add(app.Component{Name: "retry producer", Stop: app.Closer(retryProducer.Close)})
+
+		consumer.UseRetryTopics(kafka.RetryTopics{
+			Producer:   retryProducer,
+			Tiers:      kafka.RetryTiers(parseDurations("NOTIFICATION_RETRY_DELAYS", "5m,30m,2h")),
+			DeadLetter: utils.ParseWithFallback("NOTIFICATION_DLQ_TOPIC", kafka.DeadLetterTopic),
+			Keyring:    keyring,
+		})
+	}
+
+	scheduler := jobs.New(logger, jobs.WithLocks(locker))
+
+	recipients := parseRecipients(utils.ParseWithFallback("REPORT_RECIPIENTS", ""))
+	if len(recipients) > 0 {
+		analyticsConn, err := grpc.NewClient(
+			utils.ParseWithFallback("ANALYTICS_RPC_URL", "localhost:50055"),
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithStatsHandler(otelgrpc.NewClientHandler()),
+		)
+		if err != nil {
+			log.Fatalf("error creating analytics client: %v", err)
+		}
+		runner.Add(app.Component{Name: "analytics client", Stop: app.Closer(analyticsConn.Close)})
+
+		sendHour, err := strconv.Atoi(utils.ParseWithFallback("REPORT_SEND_HOUR", "6"))
+		if err != nil || sendHour < 0 || sendHour > 23 {
+			log.Fatalf("REPORT_SEND_HOUR must be an hour between 0 and 23")
+		}
+
+		reportJob := service.NewDailyReportJob(
+			analyticsPb.NewAnalyticsServiceClient(analyticsConn),
+			emailSender,
+			repository.NewReportRunRepository(pool),
+			suppressionService,
+			recipients,
+			sendHour,
+			logger,
+		)
+
+		err = scheduler.Add(jobs.Job{
+			Name:       "daily_sales_report",
+			Schedule:   "*/5 * * * *",
+			Run:        reportJob.Run,
+			RunOnStart: true,
+		})
+		if err != nil {
+			log.Fatalf("Error scheduling jobs: %v", err)
+		}
+	} else {
+		logger.Info("REPORT_RECIPIENTS is empty, daily report disabled")
+	}
+
+	// Dedup rows only matter while Kafka can still redeliver the event, so
+	// keep them well past the topic retention.
+	retentionJob := retention.NewJob(
+		logger,
+		retention.Policy{
+			Name:      "processed_events",
+			Retention: parseDuration("PROCESSED_EVENTS_RETENTION", 30*24*time.Hour),
+			Purge:     retention.DeleteBefore(pool, "processed_events", "processed_at"),
+		},
+	)
+	err = scheduler.Add(jobs.Job{
+		Name:       "retention",
+		Schedule:   jobs.Every(parseDuration("RETENTION_INTERVAL", time.Hour)),
+		Run:        retentionJob.Run,
+		RunOnStart: true,
+	})
+	if err != nil {
+		log.Fatalf("Error scheduling jobs: %v", err)
+	}
+	runner.Add(app.Component{Name: "job scheduler", Start: app.Loop(scheduler.Start)})
+
+	lis, err := net.Listen("tcp", ":50056")
+	if err != nil {
+		log.Fatalf("Error listening on :50056 %v", err)
+	}
+
+	grpcServer := grpc.NewServer(grpc.StatsHandler(otelgrpc.NewServerHandler()))
+	notificationPb.RegisterNotificationServiceServer(grpcServer, notificationGrpc.NewNotificationHandler(inboxService, suppressionService, logger))
+	statusPb.RegisterStatusServiceServer(grpcServer, servicestatus.New(
+		"notification-service",
+		logger,
+		servicestatus.WithConsumerGroups(
+			kafkaConfig,
+			servicestatus.ConsumerGroup{ID: priorityLane.GroupID, Topics: priorityLane.Topics},
+			servicestatus.ConsumerGroup{ID: bulkLane.GroupID, Topics: bulkLane.Topics},
+		),
+	))
+
+	reflectionCfg, err := config.LoadReflectionConfig()
+	if err != nil {
+		log.Fatalf("Error loading reflection config: %v", err)
+	}
+	if config.RegisterReflection(grpcServer, reflectionCfg) {
+		logger.Info("gRPC reflection is enabled, do not expose this port publicly")
+	}
+
+	consumerConfig, err := kafka2.LoadConsumerConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka consumer config: %v", err)
+	}
+
+	runner.Add(app.Component{
+		Name: "kafka consumer",
+		Start: app.Loop(func(ctx context.Context) {
+			consumer.Start(
+				ctx,
+				kafkaConfig,
+				kafka2.WithDecryption(keyring),
+				kafka2.WithConcurrency(consumerConfig.Concurrency),
+				kafka2.WithMiddleware(kafka2.Metrics(), kafka2.Logging(logger)),
+			)
+		}),
+	})
+	runner.Add(app.Component{
+		Name: "grpc server",
+		Start: func(context.Context) error {
+			log.Println("gRPC server listening on 50056 🔥")
+			return grpcServer.Serve(lis)
+		},
+		Stop: app.Func(grpcServer.GracefulStop),
+	})
+
+	debugConfig, err := debug.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading debug config: %v", err)
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(collectors.NewGoCollector())
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if err := config.RegisterLogMetrics(reg); err != nil {
+		log.Fatalf("Error registering log metrics: %v", err)
+	}
+	if err := retention.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering retention metrics: %v", err)
+	}
+	if err := jobs.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering job metrics: %v", err)
+	}
+	if err := lock.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering lock metrics: %v", err)
+	}
+	if err := kafka2.RegisterConsumerMetrics(reg); err != nil {
+		log.Fatalf("Error registering consumer metrics: %v", err)
+	}
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", config.MetricsHandler(reg))
+	if err := debug.Mount(adminMux, debugConfig); err != nil {
+		log.Fatalf("Error mounting debug endpoints: %v", err)
+	}
+
+	runner.Add(app.HTTPServer("admin server", &http.Server{
+		Addr:              utils.ParseWithFallback("ADMIN_ADDR", ":9096"),
+		Handler:           adminMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}))
+
+	return runner.Run(ctx)
+}
+
+func parseRecipients(raw string) []string {
+	var recipients []string
+	for _, addr := range strings.Split(raw, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			recipients = append(recipients, addr)
+		}
+	}
+
+	return recipients
+}
+
+func parseConcurrency(key string, fallback int) int {
+	n, err := strconv.Atoi(utils.ParseWithFallback(key, strconv.Itoa(fallback)))
+	if err != nil || n < 1 {
+		log.Fatalf("%s must be a positive integer", key)
+	}
+
+	return n
+}
+
+func parseDuration(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(utils.ParseWithFallback(key, fallback.String()))
+	if err != nil || d <= 0 {
+		log.Fatalf("%s must be a positive duration", key)
+	}
+
+	return d
+}
+
+func parseDurations(key, fallback string) []time.Duration {
+	var durations []time.Duration
+	for _, raw := range strings.Split(utils.ParseWithFallback(key, fallback), ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || d <= 0 {
+			log.Fatalf("%s must be a comma-separated list of positive durations", key)
+		}
+		durations = append(durations, d)
+	}
+
+	return durations
+}
+
+// newEmailSender picks the delivery mode from EMAIL_SENDER: "smtp" (default),
+// "capture" to keep emails in memory and serve them on /debug/emails, or
+// "noop" to drop them.
+func newEmailSender(ctx context.Context, logger *zap.Logger) email.Sender {
+	switch mode := utils.ParseWithFallback("EMAIL_SENDER", "smtp"); mode {
+	case "smtp":
+		return email.NewSMTPSender(logger)
+	case "noop":
+		return email.NewNoopSender(logger)
+	case "capture":
+		capture, err := email.NewCaptureSender(utils.ParseWithFallback("EMAIL_CAPTURE_DIR", ""), logger)
+		if err != nil {
+			log.Fatalf("error creating capture sender: %v", err)
+		}
+
+		server := &http.Server{
+			Addr:              utils.ParseWithFallback("DEBUG_HTTP_ADDR", ":8085"),
+			Handler:           debugHttp.NewDebugHandler(capture),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("debug http server stopped: %v", err)
+			}
+		}()
+
+		go func() {
+			<-ctx.Done()
+			_ = server.Close()
+		}()
+
+		logger.Warn("EMAIL_SENDER=capture, emails are not delivered", zap.String("debug_addr", server.Addr))
+		return capture
+	default:
+		log.Fatalf("unknown EMAIL_SENDER %q", mode)
+		return nil
+	}
+}
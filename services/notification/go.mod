@@ -3,3 +3,5 @@ module github.com/sakashimaa/go-pet-project/notification
 go 1.25.4
 
 require github.com/joho/godotenv v1.5.1
+
+require github.com/prometheus/client_golang v1.23.2 // indirect
@@ -4,19 +4,25 @@ type UserRegisteredEvent struct {
 	UserID          int64  `json:"user_id"`
 	Email           string `json:"email"`
 	ActivationToken string `json:"activation_token"`
+	Locale          string `json:"locale"`
 	Event           string `json:"event"`
 	EventID         int64  `json:"event_id"`
+	// Imported is set for users moved over from another system. Those who
+	// were already activated there come without an activation token.
+	Imported bool `json:"imported"`
 }
 
 type UserForgotPasswordEvent struct {
 	Email               string `json:"email"`
 	ForgotPasswordToken string `json:"forgot_password_token"`
+	Locale              string `json:"locale"`
 	Event               string `json:"event"`
 	EventID             int64  `json:"event_id"`
 }
 
 type UserResetPasswordEvent struct {
 	Email   string `json:"email"`
+	Locale  string `json:"locale"`
 	Event   string `json:"event"`
 	EventID int64  `json:"event_id"`
 }
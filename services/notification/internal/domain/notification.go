@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+const (
+	NotificationKindWelcome            = "welcome"
+	NotificationKindPartialReservation = "partial_reservation"
+)
+
+type Notification struct {
+	ID            int64
+	UserID        int64
+	Kind          string
+	Title         string
+	Body          string
+	SourceEventID string
+	ReadAt        *time.Time
+	CreatedAt     time.Time
+}
+
+type InventoryPartiallyReservedEvent struct {
+	OrderID int64 `json:"order_id"`
+	UserID  int64 `json:"user_id"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+const ReportTypeDailySales = "daily_sales"
+
+type TopProductLine struct {
+	ProductID int64
+	Units     int64
+	Orders    int64
+}
+
+type DailySalesReport struct {
+	Date            time.Time
+	Orders          int64
+	PaidOrders      int64
+	CancelledOrders int64
+	FailedPayments  int64
+	Revenue         int64
+	TopProducts     []TopProductLine
+}
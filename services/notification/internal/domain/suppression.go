@@ -0,0 +1,10 @@
+package domain
+
+// Email categories a recipient can unsubscribe from. Transactional mail
+// (activation, password reset) has no category and is never suppressed.
+const (
+	EmailCategoryAll     = "all"
+	EmailCategoryReports = "reports"
+)
+
+const SuppressionReasonUnsubscribed = "unsubscribed"
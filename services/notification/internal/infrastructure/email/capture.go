@@ -0,0 +1,161 @@
+package email
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+// captureLimit bounds the in-memory history so a long-running dev instance
+// does not grow without limit.
+const captureLimit = 200
+
+// CaptureSender renders emails exactly like the SMTP sender but keeps them in
+// memory, and optionally on disk, instead of delivering them. It backs the
+// "capture" sandbox mode used in development and integration tests.
+type CaptureSender struct {
+	dir    string
+	logger *zap.Logger
+
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewCaptureSender keeps messages in memory. When dir is non-empty every
+// message is also written there as JSON.
+func NewCaptureSender(dir string, logger *zap.Logger) (*CaptureSender, error) {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("error creating capture dir: %w", err)
+		}
+	}
+
+	return &CaptureSender{dir: dir, logger: logger}, nil
+}
+
+func (s *CaptureSender) SendActivationEmail(ctx context.Context, to, token, locale string) error {
+	return s.capture(ctx, activationMessage(to, token, locale))
+}
+
+func (s *CaptureSender) SendForgotPasswordEmail(ctx context.Context, to, token, locale string) error {
+	return s.capture(ctx, forgotPasswordMessage(to, token, locale))
+}
+
+func (s *CaptureSender) SendResetPasswordEmail(ctx context.Context, to, locale string) error {
+	return s.capture(ctx, resetPasswordMessage(to, locale))
+}
+
+func (s *CaptureSender) SendReportEmail(ctx context.Context, to []string, subject, htmlBody, unsubscribeURL string) error {
+	return s.capture(ctx, reportMessage(to, subject, htmlBody, unsubscribeURL))
+}
+
+func (s *CaptureSender) Close() error {
+	return nil
+}
+
+// Messages returns captured messages, oldest first, optionally filtered by
+// recipient.
+func (s *CaptureSender) Messages(to string) []Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Message, 0, len(s.messages))
+	for _, m := range s.messages {
+		if to == "" || slices.Contains(m.To, to) {
+			out = append(out, m)
+		}
+	}
+
+	return out
+}
+
+func (s *CaptureSender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.messages = nil
+}
+
+func (s *CaptureSender) capture(ctx context.Context, msg Message) error {
+	msg.SentAt = time.Now().UTC()
+
+	s.mu.Lock()
+	s.messages = append(s.messages, msg)
+	if len(s.messages) > captureLimit {
+		s.messages = s.messages[len(s.messages)-captureLimit:]
+	}
+	s.mu.Unlock()
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Captured email",
+		zap.Strings("to", msg.To),
+		zap.String("subject", msg.Subject),
+	)
+
+	if s.dir == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(msg, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d.json", msg.SentAt.UnixNano())
+	if err := os.WriteFile(filepath.Join(s.dir, name), data, 0o644); err != nil {
+		return fmt.Errorf("error writing captured email: %w", err)
+	}
+
+	return nil
+}
+
+type noopSender struct {
+	logger *zap.Logger
+}
+
+// NewNoopSender drops every email after logging it.
+func NewNoopSender(logger *zap.Logger) Sender {
+	return &noopSender{logger: logger}
+}
+
+func (s *noopSender) SendActivationEmail(ctx context.Context, to, _, _ string) error {
+	return s.drop(ctx, []string{to}, "activation")
+}
+
+func (s *noopSender) SendForgotPasswordEmail(ctx context.Context, to, _, _ string) error {
+	return s.drop(ctx, []string{to}, "forgot_password")
+}
+
+func (s *noopSender) SendResetPasswordEmail(ctx context.Context, to, _ string) error {
+	return s.drop(ctx, []string{to}, "reset_password")
+}
+
+func (s *noopSender) SendReportEmail(ctx context.Context, to []string, _, _, _ string) error {
+	return s.drop(ctx, to, "report")
+}
+
+func (s *noopSender) Close() error {
+	return nil
+}
+
+func (s *noopSender) drop(ctx context.Context, to []string, kind string) error {
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Dropped email (noop sender)",
+		zap.Strings("to", to),
+		zap.String("kind", kind),
+	)
+
+	return nil
+}
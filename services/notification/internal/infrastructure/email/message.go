@@ -0,0 +1,108 @@
+package email
+
+import (
+	"embed"
+	"fmt"
+	"html"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/i18n"
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+var messages = i18n.NewBundle(language.English).MustLoadFS(localeFiles, "locales")
+
+// Message is a fully rendered email, independent of how it is delivered.
+type Message struct {
+	To             []string  `json:"to"`
+	Subject        string    `json:"subject"`
+	HTMLBody       string    `json:"html_body"`
+	UnsubscribeURL string    `json:"unsubscribe_url,omitempty"`
+	Locale         string    `json:"locale"`
+	SentAt         time.Time `json:"sent_at"`
+}
+
+func (m Message) bytes() []byte {
+	// Subjects may be non-ASCII (e.g. Russian), so they are RFC 2047 encoded.
+	headers := fmt.Sprintf("To: %s\nSubject: %s\n", strings.Join(m.To, ", "), mime.QEncoding.Encode("utf-8", m.Subject))
+	if m.UnsubscribeURL != "" {
+		// RFC 8058 one-click unsubscribe.
+		headers += fmt.Sprintf("List-Unsubscribe: <%s>\nList-Unsubscribe-Post: List-Unsubscribe=One-Click\n", m.UnsubscribeURL)
+	}
+	contentType := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+
+	return []byte(headers + contentType + m.HTMLBody)
+}
+
+func resetPasswordMessage(to, locale string) Message {
+	loc := messages.Localizer(locale)
+
+	return Message{
+		To:      []string{to},
+		Subject: loc.T("email.reset_password.subject", nil),
+		HTMLBody: fmt.Sprintf(`
+		<h1>%s</h1>
+	`, loc.T("email.reset_password.heading", nil)),
+		Locale: loc.Lang(),
+	}
+}
+
+func activationMessage(to, token, locale string) Message {
+	loc := messages.Localizer(locale)
+	link := fmt.Sprintf("http://localhost:3000/auth/activate?token=%s", token)
+
+	return Message{
+		To:      []string{to},
+		Subject: loc.T("email.activation.subject", nil),
+		HTMLBody: fmt.Sprintf(`
+		<h1>%s</h1>
+		<p>%s</p>
+		<a href="%s">%s</a>
+	`, loc.T("email.activation.heading", nil), loc.T("email.activation.text", nil), link, loc.T("email.activation.link", nil)),
+		Locale: loc.Lang(),
+	}
+}
+
+func forgotPasswordMessage(to, token, locale string) Message {
+	loc := messages.Localizer(locale)
+	link := fmt.Sprintf("http://localhost:3000/auth/reset-password?token=%s", token)
+
+	return Message{
+		To:      []string{to},
+		Subject: loc.T("email.forgot_password.subject", nil),
+		HTMLBody: fmt.Sprintf(`
+		<h1>%s</h1>
+		<p>%s</p>
+		<a href="%s">%s</a>
+	`, loc.T("email.forgot_password.heading", nil), loc.T("email.forgot_password.text", nil), link, loc.T("email.forgot_password.link", nil)),
+		Locale: loc.Lang(),
+	}
+}
+
+// reportMessage wraps an already rendered report; only the footer is
+// localized, in the default language, as reports go to staff.
+func reportMessage(to []string, subject, htmlBody, unsubscribeURL string) Message {
+	loc := messages.Localizer()
+
+	if unsubscribeURL != "" {
+		htmlBody += fmt.Sprintf(`
+		<p style="font-size: 12px; color: #888">
+			%s
+			<a href="%s">%s</a>
+		</p>
+	`, loc.T("email.report.footer", nil), html.EscapeString(unsubscribeURL), loc.T("email.unsubscribe", nil))
+	}
+
+	return Message{
+		To:             to,
+		Subject:        subject,
+		HTMLBody:       htmlBody,
+		UnsubscribeURL: unsubscribeURL,
+		Locale:         loc.Lang(),
+	}
+}
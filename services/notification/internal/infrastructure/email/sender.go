@@ -3,8 +3,6 @@ package email
 import (
 	"context"
 	"fmt"
-	"net/smtp"
-	"os"
 
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	"go.opentelemetry.io/otel"
@@ -14,32 +12,53 @@ import (
 )
 
 type Sender interface {
-	SendActivationEmail(ctx context.Context, to string, token string) error
-	SendForgotPasswordEmail(ctx context.Context, to string, token string) error
-	SendResetPasswordEmail(ctx context.Context, to string) error
+	// Transactional emails are rendered in locale, falling back to English.
+	SendActivationEmail(ctx context.Context, to, token, locale string) error
+	SendForgotPasswordEmail(ctx context.Context, to, token, locale string) error
+	SendResetPasswordEmail(ctx context.Context, to, locale string) error
+	// SendReportEmail sends a non-transactional report. unsubscribeURL, when
+	// set, is linked in the footer and advertised via List-Unsubscribe.
+	SendReportEmail(ctx context.Context, to []string, subject, htmlBody, unsubscribeURL string) error
+	Close() error
 }
 
 type smtpSender struct {
-	from     string
-	password string
-	host     string
-	port     string
-	logger   *zap.Logger
-	tracer   trace.Tracer
+	mailer *mailer
+	logger *zap.Logger
+	tracer trace.Tracer
 }
 
+// NewSMTPSender builds a pooled sender from the SMTP_* environment. See
+// providersFromEnv and poolConfigFromEnv for the recognised variables.
 func NewSMTPSender(logger *zap.Logger) Sender {
+	return NewSMTPSenderWithProviders(providersFromEnv(), poolConfigFromEnv(), logger)
+}
+
+func NewSMTPSenderWithProviders(providers []Provider, cfg PoolConfig, logger *zap.Logger) Sender {
 	return &smtpSender{
-		from:     os.Getenv("SMTP_USER"),
-		password: os.Getenv("SMTP_PASSWORD"),
-		host:     os.Getenv("SMTP_HOST"),
-		port:     os.Getenv("SMTP_PORT"),
-		logger:   logger,
-		tracer:   otel.Tracer("notification/infrastructure/email"),
+		mailer: newMailer(providers, cfg),
+		logger: logger,
+		tracer: otel.Tracer("notification/infrastructure/email"),
+	}
+}
+
+func (s *smtpSender) Close() error {
+	s.mailer.close()
+	return nil
+}
+
+// send delivers msg and records which provider accepted it on the span.
+func (s *smtpSender) send(ctx context.Context, to []string, msg []byte) error {
+	provider, err := s.mailer.send(ctx, to, msg)
+	if err != nil {
+		return err
 	}
+
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("smtp.provider", provider))
+	return nil
 }
 
-func (s *smtpSender) SendResetPasswordEmail(ctx context.Context, to string) error {
+func (s *smtpSender) SendResetPasswordEmail(ctx context.Context, to, locale string) error {
 	ctx, span := s.tracer.Start(ctx, "smtp.SendResetPasswordEmail")
 	defer span.End()
 
@@ -47,15 +66,7 @@ func (s *smtpSender) SendResetPasswordEmail(ctx context.Context, to string) erro
 		attribute.String("to.email", to),
 	)
 
-	subject := "Subjet: You recently reset password on our website.\n"
-	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
-	body := fmt.Sprintf(`
-		<h1>If you didnt do it, contact our support</h1>
-	`)
-
-	msg := []byte(subject + mime + body)
-	addr := fmt.Sprintf("%s:%s", s.host, s.port)
-	auth := smtp.PlainAuth("", s.from, s.password, s.host)
+	msg := resetPasswordMessage(to, locale).bytes()
 
 	mylogger.Info(
 		ctx,
@@ -64,7 +75,7 @@ func (s *smtpSender) SendResetPasswordEmail(ctx context.Context, to string) erro
 		zap.String("to", to),
 	)
 
-	if err := smtp.SendMail(addr, auth, s.from, []string{to}, msg); err != nil {
+	if err := s.send(ctx, []string{to}, msg); err != nil {
 		span.RecordError(err)
 		mylogger.Error(
 			ctx,
@@ -81,7 +92,7 @@ func (s *smtpSender) SendResetPasswordEmail(ctx context.Context, to string) erro
 	return nil
 }
 
-func (s *smtpSender) SendActivationEmail(ctx context.Context, to string, token string) error {
+func (s *smtpSender) SendActivationEmail(ctx context.Context, to, token, locale string) error {
 	ctx, span := s.tracer.Start(ctx, "smtp.SendActivationEmail")
 	defer span.End()
 
@@ -90,19 +101,7 @@ func (s *smtpSender) SendActivationEmail(ctx context.Context, to string, token s
 		attribute.String("token", token),
 	)
 
-	link := fmt.Sprintf("http://localhost:3000/auth/activate?token=%s", token)
-
-	subject := "Subjet: Welcome! Activate your Account.\n"
-	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
-	body := fmt.Sprintf(`
-		<h1>Welcome to our App! 🚀</h1>
-		<p>Please click the link below to activate your account:</p>
-		<a href="%s">Activate Account</a>
-	`, link)
-
-	msg := []byte(subject + mime + body)
-	addr := fmt.Sprintf("%s:%s", s.host, s.port)
-	auth := smtp.PlainAuth("", s.from, s.password, s.host)
+	msg := activationMessage(to, token, locale).bytes()
 
 	mylogger.Info(
 		ctx,
@@ -111,7 +110,7 @@ func (s *smtpSender) SendActivationEmail(ctx context.Context, to string, token s
 		zap.String("to", to),
 	)
 
-	if err := smtp.SendMail(addr, auth, s.from, []string{to}, msg); err != nil {
+	if err := s.send(ctx, []string{to}, msg); err != nil {
 		mylogger.Error(
 			ctx,
 			s.logger,
@@ -134,7 +133,7 @@ func (s *smtpSender) SendActivationEmail(ctx context.Context, to string, token s
 	return nil
 }
 
-func (s *smtpSender) SendForgotPasswordEmail(ctx context.Context, to string, token string) error {
+func (s *smtpSender) SendForgotPasswordEmail(ctx context.Context, to, token, locale string) error {
 	ctx, span := s.tracer.Start(ctx, "smtp.SendForgotPasswordEmail")
 	defer span.End()
 
@@ -143,19 +142,7 @@ func (s *smtpSender) SendForgotPasswordEmail(ctx context.Context, to string, tok
 		attribute.String("token", token),
 	)
 
-	link := fmt.Sprintf("http://localhost:3000/auth/reset-password?token=%s", token)
-
-	subject := "Subjet: You requested password reset.\n"
-	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
-	body := fmt.Sprintf(`
-		<h1>Click this link to reset your password</h1>
-		<p>If you dont request resetting password, just ignore this message:</p>
-		<a href="%s">Reset password</a>
-	`, link)
-
-	msg := []byte(subject + mime + body)
-	addr := fmt.Sprintf("%s:%s", s.host, s.port)
-	auth := smtp.PlainAuth("", s.from, s.password, s.host)
+	msg := forgotPasswordMessage(to, token, locale).bytes()
 
 	mylogger.Info(
 		ctx,
@@ -164,7 +151,7 @@ func (s *smtpSender) SendForgotPasswordEmail(ctx context.Context, to string, tok
 		zap.String("to", to),
 	)
 
-	if err := smtp.SendMail(addr, auth, s.from, []string{to}, msg); err != nil {
+	if err := s.send(ctx, []string{to}, msg); err != nil {
 		mylogger.Error(
 			ctx,
 			s.logger,
@@ -184,3 +171,39 @@ func (s *smtpSender) SendForgotPasswordEmail(ctx context.Context, to string, tok
 
 	return nil
 }
+
+func (s *smtpSender) SendReportEmail(ctx context.Context, to []string, subject, htmlBody, unsubscribeURL string) error {
+	ctx, span := s.tracer.Start(ctx, "smtp.SendReportEmail")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.StringSlice("to", to),
+		attribute.String("subject", subject),
+	)
+
+	msg := reportMessage(to, subject, htmlBody, unsubscribeURL).bytes()
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Sending report email",
+		zap.Strings("to", to),
+		zap.String("subject", subject),
+	)
+
+	if err := s.send(ctx, to, msg); err != nil {
+		span.RecordError(err)
+		mylogger.Error(
+			ctx,
+			s.logger,
+			"Error sending report email",
+			zap.Strings("to", to),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to send mail: %v", err)
+	}
+
+	mylogger.Info(ctx, s.logger, "Report email sent successfully", zap.Strings("to", to))
+	return nil
+}
@@ -0,0 +1,299 @@
+package email
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
+)
+
+var ErrNoProviders = errors.New("no smtp providers configured")
+
+// Provider is one SMTP relay. Providers are tried in the configured order.
+type Provider struct {
+	Name     string
+	Host     string
+	Port     string
+	User     string
+	Password string
+	// AllowPlaintext skips STARTTLS for local relays such as MailHog. Every
+	// other provider must support it.
+	AllowPlaintext bool
+}
+
+func (p Provider) addr() string {
+	return net.JoinHostPort(p.Host, p.Port)
+}
+
+type PoolConfig struct {
+	// Size is the number of idle connections kept per provider.
+	Size        int
+	Timeout     time.Duration
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{
+		Size:        4,
+		Timeout:     10 * time.Second,
+		MaxAttempts: 3,
+		Backoff:     500 * time.Millisecond,
+	}
+}
+
+type smtpConn struct {
+	conn   net.Conn
+	client *smtp.Client
+}
+
+func (c *smtpConn) close() {
+	_ = c.client.Close()
+}
+
+type providerPool struct {
+	provider Provider
+	cfg      PoolConfig
+	idle     chan *smtpConn
+}
+
+func newProviderPool(provider Provider, cfg PoolConfig) *providerPool {
+	return &providerPool{
+		provider: provider,
+		cfg:      cfg,
+		idle:     make(chan *smtpConn, max(cfg.Size, 1)),
+	}
+}
+
+func (p *providerPool) dial(ctx context.Context) (*smtpConn, error) {
+	dialer := net.Dialer{Timeout: p.cfg.Timeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", p.provider.addr())
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(p.cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, p.provider.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	c := &smtpConn{conn: conn, client: client}
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: p.provider.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			c.close()
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	} else if !p.provider.AllowPlaintext {
+		c.close()
+		return nil, fmt.Errorf("%s does not support STARTTLS", p.provider.Host)
+	}
+
+	if p.provider.User != "" {
+		auth := smtp.PlainAuth("", p.provider.User, p.provider.Password, p.provider.Host)
+		if err := client.Auth(auth); err != nil {
+			c.close()
+			return nil, fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// get returns an idle connection that still answers RSET, or a new one.
+func (p *providerPool) get(ctx context.Context) (*smtpConn, error) {
+	for {
+		select {
+		case c := <-p.idle:
+			_ = c.conn.SetDeadline(time.Now().Add(p.cfg.Timeout))
+			if err := c.client.Reset(); err != nil {
+				c.close()
+				continue
+			}
+			return c, nil
+		default:
+			return p.dial(ctx)
+		}
+	}
+}
+
+func (p *providerPool) put(c *smtpConn) {
+	select {
+	case p.idle <- c:
+	default:
+		_ = c.client.Quit()
+	}
+}
+
+func (p *providerPool) send(ctx context.Context, from string, to []string, msg []byte) error {
+	c, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(p.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = c.conn.SetDeadline(deadline)
+
+	if err := sendOn(c.client, from, to, msg); err != nil {
+		c.close()
+		return err
+	}
+
+	p.put(c)
+	return nil
+}
+
+func sendOn(client *smtp.Client, from string, to []string, msg []byte) error {
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+
+	for _, addr := range to {
+		if err := client.Rcpt(addr); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+func (p *providerPool) close() {
+	for {
+		select {
+		case c := <-p.idle:
+			_ = c.client.Quit()
+		default:
+			return
+		}
+	}
+}
+
+// mailer sends through pooled connections, failing over across providers in
+// order and retrying whole rounds with exponential backoff unless every
+// provider rejected the message permanently.
+type mailer struct {
+	pools []*providerPool
+	cfg   PoolConfig
+}
+
+func newMailer(providers []Provider, cfg PoolConfig) *mailer {
+	pools := make([]*providerPool, 0, len(providers))
+	for _, provider := range providers {
+		pools = append(pools, newProviderPool(provider, cfg))
+	}
+
+	return &mailer{pools: pools, cfg: cfg}
+}
+
+func (m *mailer) send(ctx context.Context, to []string, msg []byte) (string, error) {
+	if len(m.pools) == 0 {
+		return "", ErrNoProviders
+	}
+
+	policy := retry.Policy{
+		MaxAttempts: m.cfg.MaxAttempts,
+		Initial:     m.cfg.Backoff,
+		Multiplier:  2,
+		Jitter:      0.2,
+		Retryable:   transient,
+	}
+
+	return retry.DoValue(ctx, policy, func(ctx context.Context) (string, error) {
+		var errs []error
+		for _, pool := range m.pools {
+			err := pool.send(ctx, pool.provider.User, to, msg)
+			if err == nil {
+				return pool.provider.Name, nil
+			}
+
+			errs = append(errs, fmt.Errorf("%s: %w", pool.provider.Name, err))
+		}
+
+		return "", errors.Join(errs...)
+	})
+}
+
+// transient reports whether a failed round may succeed later, i.e. some
+// provider failed for another reason than rejecting the message for good.
+func transient(err error) bool {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return slices.ContainsFunc(joined.Unwrap(), transient)
+	}
+
+	var protoErr *textproto.Error
+	return !errors.As(err, &protoErr) || protoErr.Code < 500
+}
+
+func (m *mailer) close() {
+	for _, pool := range m.pools {
+		pool.close()
+	}
+}
+
+// providersFromEnv reads the primary provider from SMTP_* and an optional
+// backup from SMTP_FALLBACK_*.
+func providersFromEnv() []Provider {
+	allowPlaintext, _ := strconv.ParseBool(os.Getenv("SMTP_ALLOW_PLAINTEXT"))
+
+	var providers []Provider
+	for _, p := range []struct{ name, prefix string }{
+		{"primary", "SMTP_"},
+		{"fallback", "SMTP_FALLBACK_"},
+	} {
+		host := os.Getenv(p.prefix + "HOST")
+		if host == "" {
+			continue
+		}
+
+		providers = append(providers, Provider{
+			Name:           p.name,
+			Host:           host,
+			Port:           os.Getenv(p.prefix + "PORT"),
+			User:           os.Getenv(p.prefix + "USER"),
+			Password:       os.Getenv(p.prefix + "PASSWORD"),
+			AllowPlaintext: allowPlaintext,
+		})
+	}
+
+	return providers
+}
+
+func poolConfigFromEnv() PoolConfig {
+	cfg := DefaultPoolConfig()
+
+	if n, err := strconv.Atoi(os.Getenv("SMTP_POOL_SIZE")); err == nil && n > 0 {
+		cfg.Size = n
+	}
+	if d, err := time.ParseDuration(os.Getenv("SMTP_TIMEOUT")); err == nil && d > 0 {
+		cfg.Timeout = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("SMTP_MAX_ATTEMPTS")); err == nil && n > 0 {
+		cfg.MaxAttempts = n
+	}
+
+	return cfg
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/notification/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type NotificationRepository interface {
+	// Create stores n unless a notification for the same source event
+	// already exists.
+	Create(ctx context.Context, n *domain.Notification) error
+	List(ctx context.Context, userID int64, unreadOnly bool, limit, offset int64) ([]domain.Notification, error)
+	// MarkRead marks ids (or every notification when ids is empty) of the
+	// user as read and returns how many changed.
+	MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error)
+	UnreadCount(ctx context.Context, userID int64) (int64, error)
+}
+
+type notificationRepo struct {
+	pool   *pgxpool.Pool
+	tracer trace.Tracer
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool) NotificationRepository {
+	return &notificationRepo{
+		pool:   pool,
+		tracer: otel.Tracer("notification/notification_repository"),
+	}
+}
+
+func (r *notificationRepo) Create(ctx context.Context, n *domain.Notification) error {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.Create")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", n.UserID),
+		attribute.String("kind", n.Kind),
+	)
+
+	var sourceEventID *string
+	if n.SourceEventID != "" {
+		sourceEventID = &n.SourceEventID
+	}
+
+	query := `
+		INSERT INTO notifications (user_id, kind, title, body, source_event_id)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (source_event_id) DO NOTHING
+	`
+
+	if _, err := r.pool.Exec(ctx, query, n.UserID, n.Kind, n.Title, n.Body, sourceEventID); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("error creating notification: %w", err)
+	}
+
+	return nil
+}
+
+func (r *notificationRepo) List(ctx context.Context, userID int64, unreadOnly bool, limit, offset int64) ([]domain.Notification, error) {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.List")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		SELECT id, user_id, kind, title, body, read_at, created_at
+		FROM notifications
+		WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, unreadOnly, limit, offset)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error listing notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var notifications []domain.Notification
+	for rows.Next() {
+		var n domain.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Title, &n.Body, &n.ReadAt, &n.CreatedAt); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("error scanning notification: %w", err)
+		}
+
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+func (r *notificationRepo) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.MarkRead")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", userID),
+		attribute.Int("ids", len(ids)),
+	)
+
+	query := `
+		UPDATE notifications
+		SET read_at = NOW()
+		WHERE user_id = $1
+			AND read_at IS NULL
+			AND (cardinality($2::BIGINT[]) = 0 OR id = ANY($2))
+	`
+
+	if ids == nil {
+		ids = []int64{}
+	}
+
+	tag, err := r.pool.Exec(ctx, query, userID, ids)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("error marking notifications read: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+func (r *notificationRepo) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "NotificationRepository.UnreadCount")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		SELECT COUNT(*)
+		FROM notifications
+		WHERE user_id = $1 AND read_at IS NULL
+	`
+
+	var count int64
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("error counting unread notifications: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	runStatusRunning = "running"
+	runStatusSent    = "sent"
+	runStatusFailed  = "failed"
+)
+
+type ReportRunRepository interface {
+	ClaimRun(ctx context.Context, reportType string, date time.Time, staleAfter time.Duration) (bool, error)
+	MarkSent(ctx context.Context, reportType string, date time.Time) error
+	MarkFailed(ctx context.Context, reportType string, date time.Time, cause error) error
+}
+
+type reportRunRepo struct {
+	pool   *pgxpool.Pool
+	tracer trace.Tracer
+}
+
+func NewReportRunRepository(pool *pgxpool.Pool) ReportRunRepository {
+	return &reportRunRepo{
+		pool:   pool,
+		tracer: otel.Tracer("notification/report_run_repository"),
+	}
+}
+
+// ClaimRun returns true when the caller owns this report run. A run is
+// claimable if it never started, previously failed, or has been "running"
+// longer than staleAfter (the previous owner most likely crashed).
+func (r *reportRunRepo) ClaimRun(ctx context.Context, reportType string, date time.Time, staleAfter time.Duration) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "ReportRunRepository.ClaimRun")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("report_type", reportType),
+		attribute.String("report_date", date.Format(time.DateOnly)),
+	)
+
+	query := `
+		INSERT INTO report_runs (report_type, report_date, status)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (report_type, report_date) DO UPDATE
+		SET status = EXCLUDED.status,
+			attempts = report_runs.attempts + 1,
+			started_at = NOW(),
+			last_error = NULL
+		WHERE report_runs.status = $4
+			OR (report_runs.status = $3 AND report_runs.started_at < NOW() - make_interval(secs => $5))
+		RETURNING attempts;
+	`
+
+	var attempts int
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		reportType,
+		date,
+		runStatusRunning,
+		runStatusFailed,
+		staleAfter.Seconds(),
+	).Scan(&attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to claim report run: %w", err)
+	}
+
+	return true, nil
+}
+
+func (r *reportRunRepo) MarkSent(ctx context.Context, reportType string, date time.Time) error {
+	return r.finish(ctx, reportType, date, runStatusSent, nil)
+}
+
+func (r *reportRunRepo) MarkFailed(ctx context.Context, reportType string, date time.Time, cause error) error {
+	msg := cause.Error()
+	return r.finish(ctx, reportType, date, runStatusFailed, &msg)
+}
+
+func (r *reportRunRepo) finish(ctx context.Context, reportType string, date time.Time, status string, lastError *string) error {
+	ctx, span := r.tracer.Start(ctx, "ReportRunRepository.finish")
+	defer span.End()
+
+	query := `
+		UPDATE report_runs
+		SET status = $3, last_error = $4, finished_at = NOW()
+		WHERE report_type = $1 AND report_date = $2;
+	`
+
+	if _, err := r.pool.Exec(ctx, query, reportType, date, status, lastError); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update report run: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/notification/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type SuppressionRepository interface {
+	Suppress(ctx context.Context, email, category, reason string) error
+	// Suppressed reports which of emails (keyed as passed in) opted out of
+	// category, either directly or through the "all" category.
+	Suppressed(ctx context.Context, emails []string, category string) (map[string]bool, error)
+}
+
+type suppressionRepo struct {
+	pool   *pgxpool.Pool
+	tracer trace.Tracer
+}
+
+func NewSuppressionRepository(pool *pgxpool.Pool) SuppressionRepository {
+	return &suppressionRepo{
+		pool:   pool,
+		tracer: otel.Tracer("notification/suppression_repository"),
+	}
+}
+
+func (r *suppressionRepo) Suppress(ctx context.Context, email, category, reason string) error {
+	ctx, span := r.tracer.Start(ctx, "SuppressionRepository.Suppress")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("category", category))
+
+	query := `
+		INSERT INTO email_suppressions (email, category, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (email, category) DO NOTHING
+	`
+
+	if _, err := r.pool.Exec(ctx, query, normalizeEmail(email), category, reason); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("error suppressing email: %w", err)
+	}
+
+	return nil
+}
+
+func (r *suppressionRepo) Suppressed(ctx context.Context, emails []string, category string) (map[string]bool, error) {
+	ctx, span := r.tracer.Start(ctx, "SuppressionRepository.Suppressed")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int("emails", len(emails)),
+		attribute.String("category", category),
+	)
+
+	normalized := make([]string, 0, len(emails))
+	for _, email := range emails {
+		normalized = append(normalized, normalizeEmail(email))
+	}
+
+	query := `
+		SELECT DISTINCT email
+		FROM email_suppressions
+		WHERE email = ANY($1) AND category IN ($2, $3)
+	`
+
+	rows, err := r.pool.Query(ctx, query, normalized, category, domain.EmailCategoryAll)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error querying suppressions: %w", err)
+	}
+	defer rows.Close()
+
+	matched := make(map[string]bool)
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("error scanning suppression: %w", err)
+		}
+
+		matched[email] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	suppressed := make(map[string]bool, len(matched))
+	for _, email := range emails {
+		if matched[normalizeEmail(email)] {
+			suppressed[email] = true
+		}
+	}
+
+	return suppressed, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
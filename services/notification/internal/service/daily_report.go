@@ -0,0 +1,216 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/notification/internal/domain"
+	"github.com/sakashimaa/go-pet-project/notification/internal/infrastructure/email"
+	"github.com/sakashimaa/go-pet-project/notification/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/analytics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	reportTopProducts = 5
+	reportStaleAfter  = 15 * time.Minute
+)
+
+var dailySalesTemplate = template.Must(template.New("daily_sales").Funcs(template.FuncMap{
+	"money": formatMoney,
+}).Parse(`
+	<h1>Daily sales report — {{ .Date.Format "2006-01-02" }}</h1>
+	<table cellpadding="6" border="1" style="border-collapse: collapse">
+		<tr><td>Orders created</td><td>{{ .Orders }}</td></tr>
+		<tr><td>Orders paid</td><td>{{ .PaidOrders }}</td></tr>
+		<tr><td>Revenue</td><td>{{ money .Revenue }}</td></tr>
+		<tr><td>Failed payments</td><td>{{ .FailedPayments }}</td></tr>
+		<tr><td>Cancelled orders</td><td>{{ .CancelledOrders }}</td></tr>
+	</table>
+	<h2>Top products</h2>
+	{{ if .TopProducts }}
+	<table cellpadding="6" border="1" style="border-collapse: collapse">
+		<tr><th>Product</th><th>Units</th><th>Orders</th></tr>
+		{{ range .TopProducts }}
+		<tr><td>#{{ .ProductID }}</td><td>{{ .Units }}</td><td>{{ .Orders }}</td></tr>
+		{{ end }}
+	</table>
+	{{ else }}
+	<p>No products were ordered.</p>
+	{{ end }}
+`))
+
+// DailyReportJob emails yesterday's sales summary once per day. Each run is
+// recorded in report_runs, so restarts and multiple replicas send it once.
+type DailyReportJob struct {
+	analytics    pb.AnalyticsServiceClient
+	sender       email.Sender
+	runs         repository.ReportRunRepository
+	suppressions *SuppressionService
+	recipients   []string
+	sendHour     int
+	logger       *zap.Logger
+	tracer       trace.Tracer
+}
+
+func NewDailyReportJob(
+	analytics pb.AnalyticsServiceClient,
+	sender email.Sender,
+	runs repository.ReportRunRepository,
+	suppressions *SuppressionService,
+	recipients []string,
+	sendHour int,
+	logger *zap.Logger,
+) *DailyReportJob {
+	return &DailyReportJob{
+		analytics:    analytics,
+		sender:       sender,
+		runs:         runs,
+		suppressions: suppressions,
+		recipients:   recipients,
+		sendHour:     sendHour,
+		logger:       logger,
+		tracer:       otel.Tracer("notification/daily-report"),
+	}
+}
+
+// Run sends yesterday's report once the send hour has passed. Schedule it
+// every few minutes: runs before the hour or after the report went out do
+// nothing, and a replica that was down at the send hour catches up.
+func (j *DailyReportJob) Run(ctx context.Context) error {
+	now := time.Now().UTC()
+	if now.Hour() < j.sendHour {
+		return nil
+	}
+
+	date := now.Truncate(24*time.Hour).AddDate(0, 0, -1)
+	if err := j.RunFor(ctx, date); err != nil {
+		return fmt.Errorf("daily report for %s: %w", date.Format(time.DateOnly), err)
+	}
+
+	return nil
+}
+
+// RunFor sends the report for date unless another run already did.
+func (j *DailyReportJob) RunFor(ctx context.Context, date time.Time) error {
+	ctx, span := j.tracer.Start(ctx, "DailyReportJob.RunFor")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("report_date", date.Format(time.DateOnly)))
+
+	claimed, err := j.runs.ClaimRun(ctx, domain.ReportTypeDailySales, date, reportStaleAfter)
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := j.send(ctx, date); err != nil {
+		span.RecordError(err)
+
+		if markErr := j.runs.MarkFailed(context.WithoutCancel(ctx), domain.ReportTypeDailySales, date, err); markErr != nil {
+			mylogger.Error(ctx, j.logger, "Failed to record report failure", zap.Error(markErr))
+		}
+
+		return err
+	}
+
+	return j.runs.MarkSent(ctx, domain.ReportTypeDailySales, date)
+}
+
+func (j *DailyReportJob) send(ctx context.Context, date time.Time) error {
+	report, err := j.compile(ctx, date)
+	if err != nil {
+		return err
+	}
+
+	body, err := RenderDailySalesReport(report)
+	if err != nil {
+		return err
+	}
+
+	recipients, err := j.suppressions.Deliverable(ctx, j.recipients, domain.EmailCategoryReports)
+	if err != nil {
+		return err
+	}
+
+	if skipped := len(j.recipients) - len(recipients); skipped > 0 {
+		mylogger.Info(ctx, j.logger, "Skipping unsubscribed report recipients", zap.Int("skipped", skipped))
+	}
+
+	// One message per recipient so each carries its own unsubscribe link.
+	subject := fmt.Sprintf("Daily sales report for %s", date.Format(time.DateOnly))
+	for _, to := range recipients {
+		unsubscribeURL := j.suppressions.UnsubscribeURL(to, domain.EmailCategoryReports)
+		if err := j.sender.SendReportEmail(ctx, []string{to}, subject, body, unsubscribeURL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (j *DailyReportJob) compile(ctx context.Context, date time.Time) (*domain.DailySalesReport, error) {
+	period := &pb.DateRange{
+		From: date.Format(time.DateOnly),
+		To:   date.Format(time.DateOnly),
+	}
+
+	volume, err := j.analytics.GetOrderVolume(ctx, &pb.GetOrderVolumeRequest{Range: period})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch order volume: %w", err)
+	}
+
+	top, err := j.analytics.GetTopProducts(ctx, &pb.GetTopProductsRequest{Range: period, Limit: reportTopProducts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch top products: %w", err)
+	}
+
+	report := &domain.DailySalesReport{Date: date}
+	for _, day := range volume.Days {
+		report.Orders += day.Orders
+		report.PaidOrders += day.PaidOrders
+		report.CancelledOrders += day.CancelledOrders
+		report.FailedPayments += day.FailedPayments
+		report.Revenue += day.Revenue
+	}
+
+	for _, p := range top.Products {
+		report.TopProducts = append(report.TopProducts, domain.TopProductLine{
+			ProductID: p.ProductId,
+			Units:     p.Units,
+			Orders:    p.Orders,
+		})
+	}
+
+	return report, nil
+}
+
+func RenderDailySalesReport(report *domain.DailySalesReport) (string, error) {
+	var buf bytes.Buffer
+	if err := dailySalesTemplate.Execute(&buf, report); err != nil {
+		return "", fmt.Errorf("failed to render report: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// formatMoney renders minor units (cents) as a decimal amount.
+func formatMoney(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/notification/internal/domain"
+	"github.com/sakashimaa/go-pet-project/notification/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultInboxLimit = 20
+	maxInboxLimit     = 100
+)
+
+// InboxService stores in-app notifications. They are written independently
+// of email so the bell works even when email delivery is disabled.
+type InboxService struct {
+	repo   repository.NotificationRepository
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+func NewInboxService(repo repository.NotificationRepository, logger *zap.Logger) *InboxService {
+	return &InboxService{
+		repo:   repo,
+		logger: logger,
+		tracer: otel.Tracer("notification/inbox"),
+	}
+}
+
+// Notify stores n. fallbackKey identifies the triggering event when the
+// message carries no event_id header, so redeliveries stay deduplicated.
+func (s *InboxService) Notify(ctx context.Context, n *domain.Notification, fallbackKey string) error {
+	ctx, span := s.tracer.Start(ctx, "InboxService.Notify")
+	defer span.End()
+
+	n.SourceEventID = fallbackKey
+	if incoming, ok := eventmeta.Incoming(ctx); ok && incoming.EventID != "" {
+		n.SourceEventID = incoming.EventID
+	}
+
+	span.SetAttributes(
+		attribute.Int64("user_id", n.UserID),
+		attribute.String("source_event_id", n.SourceEventID),
+	)
+
+	return s.repo.Create(ctx, n)
+}
+
+func (s *InboxService) List(ctx context.Context, userID int64, unreadOnly bool, limit, offset int64) ([]domain.Notification, int64, error) {
+	ctx, span := s.tracer.Start(ctx, "InboxService.List")
+	defer span.End()
+
+	if userID <= 0 || limit < 0 || offset < 0 {
+		return nil, 0, ErrInvalidInput
+	}
+
+	if limit == 0 {
+		limit = defaultInboxLimit
+	}
+	limit = min(limit, maxInboxLimit)
+
+	notifications, err := s.repo.List(ctx, userID, unreadOnly, limit, offset)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	unread, err := s.repo.UnreadCount(ctx, userID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, 0, err
+	}
+
+	return notifications, unread, nil
+}
+
+func (s *InboxService) MarkRead(ctx context.Context, userID int64, ids []int64) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "InboxService.MarkRead")
+	defer span.End()
+
+	if userID <= 0 {
+		return 0, ErrInvalidInput
+	}
+
+	for _, id := range ids {
+		if id <= 0 {
+			return 0, fmt.Errorf("%w: notification id must be positive", ErrInvalidInput)
+		}
+	}
+
+	return s.repo.MarkRead(ctx, userID, ids)
+}
+
+func (s *InboxService) UnreadCount(ctx context.Context, userID int64) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "InboxService.UnreadCount")
+	defer span.End()
+
+	if userID <= 0 {
+		return 0, ErrInvalidInput
+	}
+
+	return s.repo.UnreadCount(ctx, userID)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sakashimaa/go-pet-project/notification/internal/domain"
@@ -16,14 +17,16 @@ import (
 
 type NotificationService struct {
 	emailSender email.Sender
+	inbox       *InboxService
 	logger      *zap.Logger
 	pool        *pgxpool.Pool
 	tracer      trace.Tracer
 }
 
-func NewNotificationService(emailSender email.Sender, logger *zap.Logger, pool *pgxpool.Pool) *NotificationService {
+func NewNotificationService(emailSender email.Sender, inbox *InboxService, logger *zap.Logger, pool *pgxpool.Pool) *NotificationService {
 	return &NotificationService{
 		emailSender: emailSender,
+		inbox:       inbox,
 		logger:      logger,
 		pool:        pool,
 		tracer:      otel.Tracer("notification-service"),
@@ -34,13 +37,47 @@ func (s *NotificationService) HandleUserRegistered(ctx context.Context, event do
 	ctx, span := s.tracer.Start(ctx, "NotificationService.HandleUserRegistered")
 	defer span.End()
 
-	span.SetAttributes(attribute.Int64("event_id", event.EventID))
+	span.SetAttributes(
+		attribute.Int64("event_id", event.EventID),
+		attribute.Bool("imported", event.Imported),
+	)
+
+	if !event.Imported {
+		err := s.inbox.Notify(ctx, &domain.Notification{
+			UserID: event.UserID,
+			Kind:   domain.NotificationKindWelcome,
+			Title:  "Welcome!",
+			Body:   "Check your inbox to activate your account.",
+		}, fmt.Sprintf("UserRegistered:%d", event.UserID))
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+	}
+
+	if event.ActivationToken == "" {
+		return nil
+	}
 
 	return outboxUtils.ProcessWithDeduplication(ctx, s.pool, s.logger, event.EventID, func() error {
-		return s.emailSender.SendActivationEmail(ctx, event.Email, event.ActivationToken)
+		return s.emailSender.SendActivationEmail(ctx, event.Email, event.ActivationToken, event.Locale)
 	})
 }
 
+func (s *NotificationService) HandleInventoryPartiallyReserved(ctx context.Context, event domain.InventoryPartiallyReservedEvent) error {
+	ctx, span := s.tracer.Start(ctx, "NotificationService.HandleInventoryPartiallyReserved")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", event.OrderID))
+
+	return s.inbox.Notify(ctx, &domain.Notification{
+		UserID: event.UserID,
+		Kind:   domain.NotificationKindPartialReservation,
+		Title:  fmt.Sprintf("Order #%d needs your attention", event.OrderID),
+		Body:   "Some items are out of stock. Choose whether to wait for them or remove them from the order.",
+	}, fmt.Sprintf("InventoryPartiallyReserved:%d", event.OrderID))
+}
+
 func (s *NotificationService) HandleUserForgotPassword(ctx context.Context, event domain.UserForgotPasswordEvent) error {
 	ctx, span := s.tracer.Start(ctx, "NotificationService.HandleUserForgotPassword")
 	defer span.End()
@@ -48,7 +85,7 @@ func (s *NotificationService) HandleUserForgotPassword(ctx context.Context, even
 	span.SetAttributes(attribute.String("email", event.Email))
 
 	return outboxUtils.ProcessWithDeduplication(ctx, s.pool, s.logger, event.EventID, func() error {
-		return s.emailSender.SendForgotPasswordEmail(ctx, event.Email, event.ForgotPasswordToken)
+		return s.emailSender.SendForgotPasswordEmail(ctx, event.Email, event.ForgotPasswordToken, event.Locale)
 	})
 }
 
@@ -60,7 +97,7 @@ func (s *NotificationService) HandleUserResetPassword(ctx context.Context, event
 		zap.String("to", event.Email),
 	)
 
-	err := s.emailSender.SendResetPasswordEmail(ctx, event.Email)
+	err := s.emailSender.SendResetPasswordEmail(ctx, event.Email, event.Locale)
 	if err != nil {
 		mylogger.Error(
 			ctx,
@@ -0,0 +1,7 @@
+package service
+
+import "errors"
+
+var (
+	ErrInvalidInput = errors.New("invalid input")
+)
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+
+	"github.com/sakashimaa/go-pet-project/notification/internal/domain"
+	"github.com/sakashimaa/go-pet-project/notification/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe token")
+
+// SuppressionService issues signed unsubscribe links and maintains the
+// suppression list the dispatcher consults before sending non-transactional
+// mail. Tokens are "<base64(email|category)>.<base64(hmac)>"; they do not
+// expire, as unsubscribe links must keep working in old emails.
+type SuppressionService struct {
+	repo    repository.SuppressionRepository
+	secret  []byte
+	baseURL string
+	logger  *zap.Logger
+	tracer  trace.Tracer
+}
+
+func NewSuppressionService(repo repository.SuppressionRepository, secret, baseURL string, logger *zap.Logger) *SuppressionService {
+	return &SuppressionService{
+		repo:    repo,
+		secret:  []byte(secret),
+		baseURL: baseURL,
+		logger:  logger,
+		tracer:  otel.Tracer("notification/suppression"),
+	}
+}
+
+// UnsubscribeURL is the link embedded in mail of category sent to email.
+func (s *SuppressionService) UnsubscribeURL(email, category string) string {
+	return s.baseURL + "?token=" + url.QueryEscape(s.sign(email, category))
+}
+
+// Unsubscribe verifies token and adds its address to the suppression list.
+func (s *SuppressionService) Unsubscribe(ctx context.Context, token string) (string, string, error) {
+	ctx, span := s.tracer.Start(ctx, "SuppressionService.Unsubscribe")
+	defer span.End()
+
+	email, category, err := s.verify(token)
+	if err != nil {
+		return "", "", err
+	}
+
+	span.SetAttributes(attribute.String("category", category))
+
+	if err := s.repo.Suppress(ctx, email, category, domain.SuppressionReasonUnsubscribed); err != nil {
+		span.RecordError(err)
+		return "", "", err
+	}
+
+	mylogger.Info(ctx, s.logger, "Email unsubscribed", zap.String("category", category))
+	return email, category, nil
+}
+
+// Deliverable drops recipients that opted out of category.
+func (s *SuppressionService) Deliverable(ctx context.Context, recipients []string, category string) ([]string, error) {
+	suppressed, err := s.repo.Suppressed(ctx, recipients, category)
+	if err != nil {
+		return nil, err
+	}
+
+	deliverable := make([]string, 0, len(recipients))
+	for _, r := range recipients {
+		if !suppressed[r] {
+			deliverable = append(deliverable, r)
+		}
+	}
+
+	return deliverable, nil
+}
+
+func (s *SuppressionService) sign(email, category string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(email + "|" + category))
+	return payload + "." + base64.RawURLEncoding.EncodeToString(s.mac(payload))
+}
+
+func (s *SuppressionService) verify(token string) (string, string, error) {
+	payload, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	gotMAC, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(gotMAC, s.mac(payload)) {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	email, category, ok := strings.Cut(string(raw), "|")
+	if !ok || email == "" || category == "" {
+		return "", "", ErrInvalidUnsubscribeToken
+	}
+
+	return email, category, nil
+}
+
+func (s *SuppressionService) mac(payload string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(payload))
+	return h.Sum(nil)
+}
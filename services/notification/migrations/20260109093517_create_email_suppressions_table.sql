@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    email VARCHAR(255) NOT NULL,
+    -- Mail category the address opted out of; 'all' covers every
+    -- non-transactional category.
+    category VARCHAR(64) NOT NULL,
+    reason VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (email, category)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS email_suppressions;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+package grpc
+
+import (
+	"errors"
+
+	"github.com/sakashimaa/go-pet-project/notification/internal/service"
+	"google.golang.org/grpc/codes"
+)
+
+func mapErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput),
+		errors.Is(err, service.ErrInvalidUnsubscribeToken):
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,86 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/notification/internal/service"
+	pb "github.com/sakashimaa/go-pet-project/proto/notification"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+type NotificationHandler struct {
+	pb.UnimplementedNotificationServiceServer
+	inbox        *service.InboxService
+	suppressions *service.SuppressionService
+	logger       *zap.Logger
+}
+
+func NewNotificationHandler(inbox *service.InboxService, suppressions *service.SuppressionService, logger *zap.Logger) *NotificationHandler {
+	return &NotificationHandler{inbox: inbox, suppressions: suppressions, logger: logger}
+}
+
+func (h *NotificationHandler) ListNotifications(ctx context.Context, req *pb.ListNotificationsRequest) (*pb.ListNotificationsResponse, error) {
+	notifications, unread, err := h.inbox.List(ctx, req.UserId, req.UnreadOnly, req.Limit, req.Offset)
+	if err != nil {
+		return nil, h.fail("ListNotifications", err)
+	}
+
+	res := &pb.ListNotificationsResponse{
+		Notifications: make([]*pb.Notification, 0, len(notifications)),
+		UnreadCount:   unread,
+	}
+	for _, n := range notifications {
+		res.Notifications = append(res.Notifications, &pb.Notification{
+			Id:        n.ID,
+			Kind:      n.Kind,
+			Title:     n.Title,
+			Body:      n.Body,
+			Read:      n.ReadAt != nil,
+			CreatedAt: n.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return res, nil
+}
+
+func (h *NotificationHandler) MarkRead(ctx context.Context, req *pb.MarkReadRequest) (*pb.MarkReadResponse, error) {
+	updated, err := h.inbox.MarkRead(ctx, req.UserId, req.Ids)
+	if err != nil {
+		return nil, h.fail("MarkRead", err)
+	}
+
+	return &pb.MarkReadResponse{Updated: updated}, nil
+}
+
+func (h *NotificationHandler) GetUnreadCount(ctx context.Context, req *pb.GetUnreadCountRequest) (*pb.GetUnreadCountResponse, error) {
+	count, err := h.inbox.UnreadCount(ctx, req.UserId)
+	if err != nil {
+		return nil, h.fail("GetUnreadCount", err)
+	}
+
+	return &pb.GetUnreadCountResponse{Count: count}, nil
+}
+
+func (h *NotificationHandler) Unsubscribe(ctx context.Context, req *pb.UnsubscribeRequest) (*pb.UnsubscribeResponse, error) {
+	email, category, err := h.suppressions.Unsubscribe(ctx, req.Token)
+	if err != nil {
+		return nil, h.fail("Unsubscribe", err)
+	}
+
+	return &pb.UnsubscribeResponse{Email: email, Category: category}, nil
+}
+
+func (h *NotificationHandler) fail(method string, err error) error {
+	code := mapErrorCode(err)
+
+	h.logger.Error(
+		"notification request failed",
+		zap.String("method", method),
+		zap.String("status_code", code.String()),
+		zap.Error(err),
+	)
+
+	return status.Error(code, err.Error())
+}
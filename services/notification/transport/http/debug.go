@@ -0,0 +1,33 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sakashimaa/go-pet-project/notification/internal/infrastructure/email"
+)
+
+// NewDebugHandler exposes captured emails:
+//
+//	GET    /debug/emails?to=<addr>  list captured messages
+//	DELETE /debug/emails            clear them
+//
+// It must only be mounted in capture mode; it serves activation and reset
+// tokens in clear text.
+func NewDebugHandler(capture *email.CaptureSender) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /debug/emails", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"emails": capture.Messages(r.URL.Query().Get("to")),
+		})
+	})
+
+	mux.HandleFunc("DELETE /debug/emails", func(w http.ResponseWriter, r *http.Request) {
+		capture.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	return mux
+}
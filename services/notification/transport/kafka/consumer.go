@@ -2,97 +2,109 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
-	"log"
+	"sync"
 
-	"github.com/IBM/sarama"
 	"github.com/sakashimaa/go-pet-project/notification/internal/domain"
 	"github.com/sakashimaa/go-pet-project/notification/internal/service"
 	"github.com/sakashimaa/go-pet-project/pkg/kafka"
-	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	"go.uber.org/zap"
 )
 
 type Consumer struct {
 	service *service.NotificationService
+	router  *kafka.Router
 	logger  *zap.Logger
+	lanes   []Lane
+	retry   *RetryTopics
 }
 
-func NewConsumer(service *service.NotificationService, logger *zap.Logger) *Consumer {
-	return &Consumer{
+func NewConsumer(service *service.NotificationService, logger *zap.Logger, lanes ...Lane) *Consumer {
+	if len(lanes) == 0 {
+		lanes = []Lane{PriorityLane(), BulkLane()}
+	}
+
+	c := &Consumer{
 		service: service,
 		logger:  logger,
+		lanes:   lanes,
 	}
-}
 
-func (c *Consumer) Start(ctx context.Context, brokers []string) {
-	consumerGroup := kafka.NewConsumerGroup(
-		brokers,
-		"notification-service-group",
-		[]string{"user_events"},
-		c.processMessage,
-		c.logger,
+	// Not strict: a payload that does not decode would fail the same way in
+	// every retry tier, so it is skipped rather than dead-lettered.
+	c.router = kafka.NewRouter(logger).Register(
+		kafka.On("UserRegistered", c.userRegistered),
+		kafka.On("InventoryPartiallyReserved", c.inventoryPartiallyReserved),
+		kafka.On("UserForgotPassword", c.userForgotPassword),
+		kafka.On("UserResetPassword", c.userResetPassword),
 	)
 
-	consumerGroup.Run(ctx)
+	return c
 }
 
-func (c *Consumer) processMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
-	mylogger.Info(
-		ctx,
-		c.logger,
-		"Processing message",
-		zap.String("topic", msg.Topic),
-	)
+// Start runs every lane, and every retry tier when retry topics are used,
+// and blocks until ctx is cancelled.
+func (c *Consumer) Start(ctx context.Context, config kafka.Config, opts ...kafka.ConsumerOption) {
+	var wg sync.WaitGroup
 
-	type EventWrapper struct {
-		Event   string          `json:"event"`
-		Payload json.RawMessage `json:"payload"`
-	}
+	for _, lane := range c.lanes {
+		logger := c.logger.With(zap.String("lane", lane.Name))
+		handler := kafka.Chain(c.router.Route, c.forwardOnFailure(0), kafka.Retry(lane.retryPolicy(), logger))
 
-	var wrapper EventWrapper
-	if err := json.Unmarshal(msg.Value, &wrapper); err != nil {
-		mylogger.Error(ctx, c.logger, "Error unmarshalling wrapper", zap.Error(err))
-		return err
-	}
+		for i := 0; i < max(lane.Concurrency, 1); i++ {
+			consumerGroup := kafka.NewConsumerGroup(
+				config,
+				lane.GroupID,
+				lane.Topics,
+				handler,
+				logger,
+				opts...,
+			)
 
-	switch wrapper.Event {
-	case "UserRegistered":
-		var event domain.UserRegisteredEvent
-		if err := json.Unmarshal(wrapper.Payload, &event); err != nil {
-			log.Printf("❌ Error parsing event: %v", err)
-			return nil
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				consumerGroup.Run(ctx)
+			}()
 		}
+	}
 
-		if err := c.service.HandleUserRegistered(ctx, event); err != nil {
-			log.Printf("❌ Error processing register event: %v", err)
-			return err
-		}
-	case "UserForgotPassword":
-		var event domain.UserForgotPasswordEvent
-		if err := json.Unmarshal(msg.Value, &event); err != nil {
-			log.Printf("❌ Error parsing event: %v", err)
-			return nil
-		}
+	if c.retry != nil {
+		for i, tier := range c.retry.Tiers {
+			// The tier's delay is the backoff, so a single attempt each.
+			handler := kafka.Chain(c.router.Route, waitUntilDue, c.forwardOnFailure(i+1))
 
-		if err := c.service.HandleUserForgotPassword(ctx, event); err != nil {
-			log.Printf("❌ Error processing forgot password event: %v", err)
-			return err
-		}
-	case "UserResetPassword":
-		var event domain.UserResetPasswordEvent
-		if err := json.Unmarshal(msg.Value, &event); err != nil {
-			log.Printf("❌ Error parsing event: %v", err)
-			return nil
-		}
+			consumerGroup := kafka.NewConsumerGroup(
+				config,
+				retryTierGroupID(tier),
+				[]string{tier.Topic},
+				handler,
+				c.logger.With(zap.String("retry_tier", tier.Topic)),
+				opts...,
+			)
 
-		if err := c.service.HandleUserResetPassword(ctx, event); err != nil {
-			log.Printf("❌ Error processing reset password event: %v", err)
-			return err
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				consumerGroup.Run(ctx)
+			}()
 		}
-	default:
-		log.Printf("Ignored event type: %s", wrapper.Event)
 	}
 
-	return nil
+	wg.Wait()
+}
+
+func (c *Consumer) userRegistered(ctx context.Context, event *domain.UserRegisteredEvent) error {
+	return c.service.HandleUserRegistered(ctx, *event)
+}
+
+func (c *Consumer) inventoryPartiallyReserved(ctx context.Context, event *domain.InventoryPartiallyReservedEvent) error {
+	return c.service.HandleInventoryPartiallyReserved(ctx, *event)
+}
+
+func (c *Consumer) userForgotPassword(ctx context.Context, event *domain.UserForgotPasswordEvent) error {
+	return c.service.HandleUserForgotPassword(ctx, *event)
+}
+
+func (c *Consumer) userResetPassword(ctx context.Context, event *domain.UserResetPasswordEvent) error {
+	return c.service.HandleUserResetPassword(ctx, *event)
 }
@@ -0,0 +1,57 @@
+package kafka
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
+)
+
+// Lane is an independently consumed slice of notification traffic. Each lane
+// has its own consumer group, so a backlog in one never delays another.
+type Lane struct {
+	Name    string
+	GroupID string
+	Topics  []string
+	// Concurrency is the number of group members the lane runs; each member
+	// owns a share of the partitions.
+	Concurrency int
+	// MaxAttempts bounds in-process retries before the message moves to
+	// the retry topics, or is left unmarked when they are not used.
+	MaxAttempts int
+	Backoff     time.Duration
+}
+
+// PriorityLane carries time-sensitive mail such as password resets.
+func PriorityLane() Lane {
+	return Lane{
+		Name:        "priority",
+		GroupID:     "notification-service-priority-group",
+		Topics:      []string{"user_events_priority"},
+		Concurrency: 4,
+		MaxAttempts: 5,
+		Backoff:     200 * time.Millisecond,
+	}
+}
+
+// BulkLane carries everything else.
+func BulkLane() Lane {
+	return Lane{
+		Name:        "bulk",
+		GroupID:     "notification-service-group",
+		Topics:      []string{"user_events", "order_events"},
+		Concurrency: 1,
+		MaxAttempts: 3,
+		Backoff:     time.Second,
+	}
+}
+
+// retryPolicy is how a lane retries a message in process before it moves
+// on.
+func (l Lane) retryPolicy() retry.Policy {
+	return retry.Policy{
+		MaxAttempts: l.MaxAttempts,
+		Initial:     l.Backoff,
+		Multiplier:  2,
+		Jitter:      0.2,
+	}
+}
@@ -0,0 +1,213 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderRetryAttempt is the retry tier a message is in, starting at 1.
+	HeaderRetryAttempt = "retry_attempt"
+	// HeaderRetryNotBefore is when the tier may process the message, in
+	// RFC 3339.
+	HeaderRetryNotBefore = "retry_not_before"
+	// HeaderRetryOriginalTopic is the topic the message was first read from.
+	HeaderRetryOriginalTopic = "retry_original_topic"
+	// HeaderRetryError is the error of the last failed attempt.
+	HeaderRetryError = "retry_last_error"
+
+	DeadLetterTopic = "notification_dlq"
+)
+
+// RetryTier is a topic holding failed messages until Delay has passed since
+// they were put there. Every message in a tier waits the same delay, so the
+// tier consumer can sleep on the head of a partition without holding back
+// messages that are due earlier.
+type RetryTier struct {
+	Topic string
+	Delay time.Duration
+}
+
+// RetryTiers names a tier topic after its delay, e.g. notification_retry_5m.
+func RetryTiers(delays []time.Duration) []RetryTier {
+	tiers := make([]RetryTier, 0, len(delays))
+	for _, delay := range delays {
+		tiers = append(tiers, RetryTier{
+			Topic: "notification_retry_" + formatDelay(delay),
+			Delay: delay,
+		})
+	}
+
+	return tiers
+}
+
+// DefaultRetryTiers gives SMTP outages five minutes, then half an hour, then
+// two hours to clear up before a message is dead-lettered.
+func DefaultRetryTiers() []RetryTier {
+	return RetryTiers([]time.Duration{5 * time.Minute, 30 * time.Minute, 2 * time.Hour})
+}
+
+// RetryTopics moves messages that failed their in-process retries through
+// the tiers and finally to DeadLetter, instead of leaving them to Kafka.
+type RetryTopics struct {
+	Producer   kafka.Producer
+	Tiers      []RetryTier
+	DeadLetter string
+	// Keyring re-encrypts messages that arrived encrypted; the handler only
+	// sees their plaintext.
+	Keyring *kafka.Keyring
+}
+
+// UseRetryTopics must be called before Start.
+func (c *Consumer) UseRetryTopics(retry RetryTopics) {
+	if retry.DeadLetter == "" {
+		retry.DeadLetter = DeadLetterTopic
+	}
+
+	c.retry = &retry
+}
+
+// retryTierGroupID gives each tier its own consumer group so a tier waiting
+// out its delay never blocks another.
+func retryTierGroupID(tier RetryTier) string {
+	return "notification-service-retry-" + formatDelay(tier.Delay)
+}
+
+// forwardOnFailure hands a message that still fails after next to the tier
+// after stage, 0 being the lanes. The message counts as handled once it is
+// forwarded.
+func (c *Consumer) forwardOnFailure(stage int) kafka.Middleware {
+	return func(next kafka.HandlerFunc) kafka.HandlerFunc {
+		return func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+			err := next(ctx, msg)
+			if err == nil || c.retry == nil || ctx.Err() != nil {
+				return err
+			}
+
+			return c.forward(ctx, msg, stage+1, err)
+		}
+	}
+}
+
+func (c *Consumer) forward(ctx context.Context, msg *sarama.ConsumerMessage, stage int, cause error) error {
+	headers := retryHeaders(msg)
+	headers[HeaderRetryError] = cause.Error()
+	if headers[HeaderRetryOriginalTopic] == "" {
+		headers[HeaderRetryOriginalTopic] = msg.Topic
+	}
+
+	topic := c.retry.DeadLetter
+	if stage <= len(c.retry.Tiers) {
+		tier := c.retry.Tiers[stage-1]
+		topic = tier.Topic
+		headers[HeaderRetryAttempt] = strconv.Itoa(stage)
+		headers[HeaderRetryNotBefore] = time.Now().Add(tier.Delay).UTC().Format(time.RFC3339)
+	}
+
+	var value any = kafka.RawMessage(msg.Value)
+	if wasEncrypted(msg) {
+		if c.retry.Keyring == nil {
+			return fmt.Errorf("cannot forward encrypted message without a keyring: %w", cause)
+		}
+
+		ciphertext, encHeaders, err := c.retry.Keyring.Encrypt(msg.Value)
+		if err != nil {
+			return fmt.Errorf("error re-encrypting message for %s: %w", topic, err)
+		}
+
+		for k, v := range encHeaders {
+			headers[k] = v
+		}
+		value = kafka.RawMessage(ciphertext)
+	}
+
+	if err := c.retry.Producer.ProduceMessageWithHeaders(ctx, topic, string(msg.Key), value, headers); err != nil {
+		mylogger.Error(ctx, c.logger, "Failed to forward notification for retry", zap.String("topic", topic), zap.Error(err))
+		return fmt.Errorf("error forwarding message to %s: %w", topic, err)
+	}
+
+	fields := []zap.Field{
+		zap.String("from", msg.Topic),
+		zap.String("to", topic),
+		zap.Int("stage", stage),
+		zap.Error(cause),
+	}
+	if topic == c.retry.DeadLetter {
+		mylogger.Error(ctx, c.logger, "Notification dead-lettered", fields...)
+	} else {
+		mylogger.Warn(ctx, c.logger, "Notification scheduled for retry", fields...)
+	}
+
+	return nil
+}
+
+// waitUntilDue holds a tier message until its retry_not_before. Returning
+// the context error on shutdown leaves the message for redelivery.
+func waitUntilDue(next kafka.HandlerFunc) kafka.HandlerFunc {
+	return func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+		if raw := kafka.Header(msg, HeaderRetryNotBefore); raw != "" {
+			notBefore, err := time.Parse(time.RFC3339, raw)
+			if err == nil {
+				if wait := time.Until(notBefore); wait > 0 {
+					timer := time.NewTimer(wait)
+					select {
+					case <-ctx.Done():
+						timer.Stop()
+						return ctx.Err()
+					case <-timer.C:
+					}
+				}
+			}
+		}
+
+		return next(ctx, msg)
+	}
+}
+
+// retryHeaders keeps the event metadata and retry bookkeeping of msg. The
+// trace context is set again by the producer, and the payload is forwarded
+// as the plain envelope the handler saw, so format and encryption headers
+// go.
+func retryHeaders(msg *sarama.ConsumerMessage) map[string]string {
+	dropped := append(otel.GetTextMapPropagator().Fields(),
+		kafka.HeaderContentType,
+		kafka.HeaderProtoMessage,
+		kafka.HeaderEncryption,
+		kafka.HeaderEncryptionKeyID,
+		kafka.HeaderEncryptedDataKey,
+	)
+
+	headers := make(map[string]string, len(msg.Headers))
+	for _, h := range msg.Headers {
+		if key := string(h.Key); !slices.Contains(dropped, key) {
+			headers[key] = string(h.Value)
+		}
+	}
+
+	return headers
+}
+
+func wasEncrypted(msg *sarama.ConsumerMessage) bool {
+	return kafka.Header(msg, kafka.HeaderEncryption) != ""
+}
+
+// formatDelay renders 5m0s as 5m and 2h0m0s as 2h for topic names.
+func formatDelay(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	default:
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	repository2 "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/seed"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	productPb "github.com/sakashimaa/go-pet-project/proto/product"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	// Zipf exponents: a few customers place most orders and a few products
+	// are in most baskets.
+	seedUserSkew    = 1.05
+	seedProductSkew = 1.1
+
+	seedMeanLines    = 1.8
+	seedMeanQuantity = 1.3
+	seedProductPage  = 100
+)
+
+// seedOrders places orders for the users auth has replicated here, with
+// products listed by the product service. Each order starts the saga, so
+// reservations, payments and invoices follow once the services run.
+func seedOrders(ctx context.Context, opts seed.Options) error {
+	pool, err := db.NewPostgresDB(utils.ParseWithFallback("DB_URL", ""))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	users, err := seedUserIDs(ctx, pool)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return fmt.Errorf("no users to place orders for: seed auth-service first and let order-service consume its events")
+	}
+
+	products, err := seedProductCatalog(ctx, utils.ParseWithFallback("PRODUCT_RPC_URL", "localhost:50052"))
+	if err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return fmt.Errorf("no products in stock: seed product-service first")
+	}
+
+	logger := zap.NewNop()
+	orderService := service.NewOrderService(
+		pool,
+		logger,
+		repository.NewOrderRepository(pool, logger),
+		repository2.NewOutboxRepository(pool, logger, "order-service"),
+	)
+
+	addresses, err := seedAddressBook(ctx, orderService, users, opts.Seed)
+	if err != nil {
+		return err
+	}
+
+	userPopularity := seed.NewZipf(len(users), seedUserSkew)
+	productPopularity := seed.NewZipf(len(products), seedProductSkew)
+
+	return seed.Run(ctx, opts, "orders", func(ctx context.Context, i int) error {
+		r := seed.ForRecord(opts.Seed, i)
+
+		lines := min(r.Geometric(seedMeanLines), domain.MaxOrderLines, len(products))
+		picked := make(map[int64]bool, lines)
+		items := make([]*pb.OrderItem, 0, lines)
+		for len(items) < lines {
+			product := products[productPopularity.Pick(r)]
+			if picked[product.Id] {
+				continue
+			}
+			picked[product.Id] = true
+
+			items = append(items, &pb.OrderItem{
+				ProductId: product.Id,
+				Name:      product.Name,
+				Price:     product.Price,
+				Quantity:  int32(min(r.Geometric(seedMeanQuantity), domain.MaxItemQuantity)),
+			})
+		}
+
+		userID := users[userPopularity.Pick(r)]
+		_, err := orderService.CreateOrder(ctx, &pb.CreateOrderRequest{
+			UserId:            userID,
+			Items:             items,
+			ShippingAddressId: addresses[userID],
+		})
+		return err
+	})
+}
+
+// seedAddressBook gives every user an address to ship to, reusing the first
+// one of users who already have some.
+func seedAddressBook(ctx context.Context, orderService service.OrderService, users []int64, seedValue uint64) (map[int64]int64, error) {
+	addresses := make(map[int64]int64, len(users))
+	for _, userID := range users {
+		existing, err := orderService.ListAddresses(ctx, &pb.ListAddressesRequest{UserId: userID})
+		if err != nil {
+			return nil, fmt.Errorf("error listing addresses: %w", err)
+		}
+		if len(existing.Addresses) > 0 {
+			addresses[userID] = existing.Addresses[0].Id
+			continue
+		}
+
+		r := seed.ForRecord(seedValue, int(userID))
+		first, last := r.Person()
+		line1, city, postalCode, country := r.Address()
+
+		address, err := orderService.AddAddress(ctx, &pb.AddAddressRequest{
+			UserId: userID,
+			Address: &pb.Address{
+				Label:      "Home",
+				Recipient:  first + " " + last,
+				Line1:      line1,
+				City:       city,
+				PostalCode: postalCode,
+				Country:    country,
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error adding address: %w", err)
+		}
+		addresses[userID] = address.Id
+	}
+
+	return addresses, nil
+}
+
+func seedUserIDs(ctx context.Context, pool *pgxpool.Pool) ([]int64, error) {
+	rows, err := pool.Query(ctx, `SELECT id FROM users WHERE tenant_id = $1 ORDER BY id`, tenant.DefaultID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing users: %w", err)
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// seedProductCatalog lists the products in stock, best rated first so they
+// are the best sellers at the head of the Zipf curve.
+func seedProductCatalog(ctx context.Context, url string) ([]*productPb.Product, error) {
+	conn, err := grpc.NewClient(url, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	client := productPb.NewProductServiceClient(conn)
+
+	var products []*productPb.Product
+	for offset := int64(0); ; offset += seedProductPage {
+		res, err := client.ListProducts(ctx, &productPb.ListProductsRequest{
+			Offset:      offset,
+			Limit:       seedProductPage,
+			InStockOnly: true,
+			Sort:        productPb.ProductSort_PRODUCT_SORT_RATING,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error listing products: %w", err)
+		}
+
+		products = append(products, res.Products...)
+		if len(res.Products) < seedProductPage {
+			return products, nil
+		}
+	}
+}
@@ -0,0 +1,376 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	"github.com/sakashimaa/go-pet-project/order/internal/transport/grpc"
+	"github.com/sakashimaa/go-pet-project/order/internal/transport/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/app"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/config"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/debug"
+	"github.com/sakashimaa/go-pet-project/pkg/jobs"
+	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/lock"
+	"github.com/sakashimaa/go-pet-project/pkg/objectstore"
+	repository2 "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/pkg/retention"
+	"github.com/sakashimaa/go-pet-project/pkg/servicestatus"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	statusPb "github.com/sakashimaa/go-pet-project/proto/status"
+	googleGrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// paymentWatchdogConfig says how long a reserved order waits for its payment
+// result before it is cancelled, and how much longer while the payment
+// service at PaymentAddr is down. An empty PaymentAddr skips the check.
+type paymentWatchdogConfig struct {
+	Timeout     time.Duration `env:"PAYMENT_TIMEOUT" env-default:"15m"`
+	Interval    time.Duration `env:"PAYMENT_WATCHDOG_INTERVAL" env-default:"1m"`
+	PaymentAddr string        `env:"PAYMENT_RPC_URL" env-default:"localhost:50054"`
+	DownGrace   time.Duration `env:"PAYMENT_DOWN_GRACE" env-default:"1h"`
+}
+
+// invoiceConfig holds the seller details printed on invoices.
+type invoiceConfig struct {
+	CompanyName    string `env:"INVOICE_COMPANY_NAME" env-default:"Go Pet Project Ltd"`
+	CompanyAddress string `env:"INVOICE_COMPANY_ADDRESS"`
+	CompanyTaxID   string `env:"INVOICE_COMPANY_TAX_ID"`
+	CompanyEmail   string `env:"INVOICE_COMPANY_EMAIL"`
+	Currency       string `env:"INVOICE_CURRENCY" env-default:"USD"`
+	TaxRateBps     int64  `env:"INVOICE_TAX_RATE_BPS" env-default:"0"`
+}
+
+// shippingConfig picks the delivery rate: "flat", "weight" or "carrier".
+type shippingConfig struct {
+	Rate       string `env:"SHIPPING_RATE" env-default:"flat"`
+	FlatFee    int64  `env:"SHIPPING_FLAT_FEE" env-default:"0"`
+	FreeFrom   int64  `env:"SHIPPING_FREE_FROM" env-default:"0"`
+	BaseFee    int64  `env:"SHIPPING_BASE_FEE" env-default:"0"`
+	PerKgFee   int64  `env:"SHIPPING_PER_KG_FEE" env-default:"0"`
+	CarrierURL string `env:"SHIPPING_CARRIER_URL"`
+}
+
+// orderLimitsConfig caps how often a customer may order; 0 places no limit.
+// Per-product purchase caps are set by admins through the API.
+type orderLimitsConfig struct {
+	MaxPerUserPerHour int `env:"ORDER_MAX_PER_USER_PER_HOUR" env-default:"0"`
+}
+
+func newShippingRater(cfg shippingConfig) (service.ShippingRater, error) {
+	switch cfg.Rate {
+	case "flat":
+		return service.FlatRate{Fee: cfg.FlatFee, FreeFrom: cfg.FreeFrom}, nil
+	case "weight":
+		return service.WeightRate{Base: cfg.BaseFee, PerKg: cfg.PerKgFee}, nil
+	case "carrier":
+		if cfg.CarrierURL == "" {
+			return nil, fmt.Errorf("SHIPPING_CARRIER_URL is required for the carrier rate")
+		}
+		return service.NewCarrierRate(cfg.CarrierURL), nil
+	default:
+		return nil, fmt.Errorf("unknown shipping rate %q", cfg.Rate)
+	}
+}
+
+func serve(ctx context.Context) error {
+	tp, err := utils.InitTracer(ctx, "order-service")
+	if err != nil {
+		log.Fatalf("failed to init tracer: %v", err)
+	}
+
+	pool, err := db.NewPostgresDB(utils.ParseWithFallback("DB_URL", ""))
+	if err != nil {
+		log.Fatalf("failed to create pool: %v", err)
+	}
+
+	loggerCfg, err := config.LoadLoggerConfig()
+	if err != nil {
+		log.Fatalf("failed to load logger config: %v", err)
+	}
+	logger, logLevel, err := config.NewLeveledLogger(loggerCfg)
+	if err != nil {
+		log.Fatalf("failed to create logger: %v", err)
+	}
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Fatalf("failed to sync logger: %v", err)
+		}
+	}()
+
+	runner := app.NewRunner(logger)
+	runner.Add(app.Component{Name: "telemetry", Stop: tp.Shutdown})
+
+	reloadConfig, err := config.LoadReloadConfig()
+	if err != nil {
+		log.Fatalf("Error loading config reload settings: %v", err)
+	}
+
+	configWatcher := config.NewWatcher(reloadConfig, logger)
+	if err := configWatcher.Load(ctx); err != nil {
+		log.Fatalf("Error loading tunables: %v", err)
+	}
+	configWatcher.Subscribe(config.SetLogLevel(logLevel))
+	runner.Add(app.Component{Name: "config watcher", Start: app.Loop(configWatcher.Start)})
+
+	runner.Add(app.Component{Name: "postgres", Stop: app.Func(pool.Close)})
+
+	locker := lock.NewPostgres(pool, logger)
+
+	var invoiceCfg invoiceConfig
+	if err := cleanenv.ReadEnv(&invoiceCfg); err != nil {
+		log.Fatalf("Error loading invoice config: %v", err)
+	}
+
+	var shippingCfg shippingConfig
+	if err := cleanenv.ReadEnv(&shippingCfg); err != nil {
+		log.Fatalf("Error loading shipping config: %v", err)
+	}
+
+	shippingRater, err := newShippingRater(shippingCfg)
+	if err != nil {
+		log.Fatalf("Error creating shipping rater: %v", err)
+	}
+
+	var limitsCfg orderLimitsConfig
+	if err := cleanenv.ReadEnv(&limitsCfg); err != nil {
+		log.Fatalf("Error loading order limits config: %v", err)
+	}
+
+	objectStoreCfg, err := objectstore.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading object store config: %v", err)
+	}
+
+	invoiceStore, err := objectstore.New(objectStoreCfg)
+	if err != nil {
+		log.Fatalf("Error creating object store: %v", err)
+	}
+
+	orderRepo := repository.NewOrderRepository(pool, logger)
+	outboxRepo := repository2.NewOutboxRepository(pool, logger, "order-service")
+	orderService := service.NewOrderService(
+		pool,
+		logger,
+		orderRepo,
+		outboxRepo,
+		service.WithInvoicing(invoiceStore, domain.InvoiceIssuer{
+			Name:       invoiceCfg.CompanyName,
+			Address:    invoiceCfg.CompanyAddress,
+			TaxID:      invoiceCfg.CompanyTaxID,
+			Email:      invoiceCfg.CompanyEmail,
+			Currency:   invoiceCfg.Currency,
+			TaxRateBps: invoiceCfg.TaxRateBps,
+		}),
+		service.WithShippingRater(shippingRater),
+		service.WithOrderRateLimit(limitsCfg.MaxPerUserPerHour),
+	)
+	orderHandler := grpc.NewOrderHandler(orderService, logger)
+
+	kafkaConfig, err := kafka2.LoadConfig("order-service")
+	if err != nil {
+		log.Fatalf("error loading kafka config: %v", err)
+	}
+
+	kafkaProducer, err := kafka2.NewProducer(kafkaConfig)
+	if err != nil {
+		log.Fatalf("error creating kafka producer: %v", err)
+	}
+	runner.Add(app.Component{Name: "kafka producer", Stop: app.Closer(kafkaProducer.Close)})
+
+	messageFormat, err := kafka2.ParseMessageFormat(utils.ParseWithFallback("KAFKA_MESSAGE_FORMAT", "envelope"))
+	if err != nil {
+		log.Fatalf("error parsing kafka message format: %v", err)
+	}
+
+	encryptionConfig, err := kafka2.LoadEncryptionConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption config: %v", err)
+	}
+
+	keyring, err := encryptionConfig.Keyring()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption keys: %v", err)
+	}
+
+	outboxOpts := []worker.Option{worker.WithMessageFormat(messageFormat)}
+	if utils.ParseWithFallback("OUTBOX_LEADER_ELECTION", "false") == "true" {
+		outboxOpts = append(outboxOpts, worker.WithLeaderElection(worker.NewLockElector(locker, "order-service", logger)))
+	}
+	if compacted := utils.ParseWithFallback("OUTBOX_COMPACT_EVENT_TYPES", ""); compacted != "" {
+		outboxOpts = append(outboxOpts, worker.WithCompaction(strings.Split(compacted, ",")...))
+	}
+	if keyring != nil {
+		outboxOpts = append(outboxOpts, worker.WithEncryption(keyring, encryptionConfig.Topics))
+	}
+
+	outboxProcessor := worker.NewOutboxProcessor(pool, outboxRepo, kafkaProducer, logger, outboxOpts...)
+	configWatcher.Subscribe(func(t config.Tunables) {
+		outboxProcessor.SetInterval(t.OutboxInterval)
+	})
+
+	runner.Add(app.Component{Name: "outbox processor", Start: app.Loop(outboxProcessor.Start)})
+
+	var watchdogCfg paymentWatchdogConfig
+	if err := cleanenv.ReadEnv(&watchdogCfg); err != nil {
+		log.Fatalf("Error loading payment watchdog config: %v", err)
+	}
+
+	var watchdogOpts []service.WatchdogOption
+	if watchdogCfg.PaymentAddr != "" {
+		paymentConn, err := googleGrpc.NewClient(watchdogCfg.PaymentAddr, googleGrpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Fatalf("Error creating payment client: %v", err)
+		}
+		runner.Add(app.Component{Name: "payment client", Stop: app.Closer(paymentConn.Close)})
+
+		watchdogOpts = append(watchdogOpts, service.WithPaymentHealth(grpc.NewPaymentHealth(paymentConn), watchdogCfg.DownGrace))
+	}
+
+	scheduler := jobs.New(logger, jobs.WithLocks(locker))
+	err = scheduler.Add(jobs.Job{
+		Name:     "payment_watchdog",
+		Schedule: jobs.Every(watchdogCfg.Interval),
+		Run:      service.NewPaymentWatchdog(orderService, watchdogCfg.Timeout, logger, watchdogOpts...).Run,
+	})
+	if err != nil {
+		log.Fatalf("Error scheduling jobs: %v", err)
+	}
+	runner.Add(app.Component{Name: "job scheduler", Start: app.Loop(scheduler.Start)})
+
+	consumer := kafka.NewConsumer(orderService, logger)
+
+	lis, err := net.Listen("tcp", ":50053")
+	if err != nil {
+		log.Fatalf("Error listening on :50053 %v", err)
+	}
+
+	// The gateway already restricts exports to admins; checking again here
+	// keeps other callers inside the cluster from pulling every order.
+	requireAdmin := authctx.RequireStream(
+		func(ctx context.Context) error { return authctx.RequireRole(ctx, "admin") },
+		pb.OrderService_ExportOrders_FullMethodName,
+	)
+
+	s := googleGrpc.NewServer(
+		googleGrpc.ChainUnaryInterceptor(tenant.UnaryServerInterceptor(), authctx.UnaryServerInterceptor()),
+		googleGrpc.ChainStreamInterceptor(tenant.StreamServerInterceptor(), requireAdmin),
+	)
+	pb.RegisterOrderServiceServer(s, orderHandler)
+	statusPb.RegisterStatusServiceServer(s, servicestatus.New(
+		"order-service",
+		logger,
+		servicestatus.WithOutbox(pool),
+		servicestatus.WithConsumerGroups(kafkaConfig, servicestatus.ConsumerGroup{ID: kafka.GroupID, Topics: kafka.Topics}),
+	))
+
+	reflectionCfg, err := config.LoadReflectionConfig()
+	if err != nil {
+		log.Fatalf("Error loading reflection config: %v", err)
+	}
+	if config.RegisterReflection(s, reflectionCfg) {
+		logger.Info("gRPC reflection is enabled, do not expose this port publicly")
+	}
+
+	consumerConfig, err := kafka2.LoadConsumerConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka consumer config: %v", err)
+	}
+
+	inbox := kafka2.NewPostgresInbox(pool, "processed_messages")
+	inboxRetention := retention.NewJob(logger, retention.Policy{
+		Name:      "processed_messages",
+		Retention: consumerConfig.InboxRetention,
+		Purge:     retention.DeleteBefore(pool, "processed_messages", "processed_at"),
+	})
+	err = scheduler.Add(jobs.Job{
+		Name:     "inbox_retention",
+		Schedule: "@hourly",
+		Run:      inboxRetention.Run,
+	})
+	if err != nil {
+		log.Fatalf("Error scheduling jobs: %v", err)
+	}
+
+	runner.Add(app.Component{
+		Name: "kafka consumer",
+		Start: app.Loop(func(ctx context.Context) {
+			consumer.Start(
+				ctx,
+				kafkaConfig,
+				kafka2.WithDecryption(keyring),
+				kafka2.WithConcurrency(consumerConfig.Concurrency),
+				kafka2.WithMiddleware(kafka2.Metrics(), kafka2.Logging(logger), kafka2.Deduplicate(inbox, logger)),
+			)
+		}),
+	})
+	runner.Add(app.Component{
+		Name: "grpc server",
+		Start: func(context.Context) error {
+			log.Println("gRPC server listening on 50053 🔥")
+			return s.Serve(lis)
+		},
+		Stop: app.Func(s.GracefulStop),
+	})
+
+	reg := prometheus.NewRegistry()
+
+	reg.MustRegister(collectors.NewGoCollector())
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if err := config.RegisterLogMetrics(reg); err != nil {
+		log.Fatalf("Error registering log metrics: %v", err)
+	}
+
+	if err := jobs.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering job metrics: %v", err)
+	}
+	if err := lock.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering lock metrics: %v", err)
+	}
+	if err := retention.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering retention metrics: %v", err)
+	}
+	if err := kafka2.RegisterConsumerMetrics(reg); err != nil {
+		log.Fatalf("Error registering consumer metrics: %v", err)
+	}
+
+	if err := repository2.RegisterMetrics(reg, pool, "order-service"); err != nil {
+		log.Fatalf("Error registering outbox metrics: %v", err)
+	}
+
+	debugConfig, err := debug.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading debug config: %v", err)
+	}
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", config.MetricsHandler(reg))
+	if err := debug.Mount(adminMux, debugConfig); err != nil {
+		log.Fatalf("Error mounting debug endpoints: %v", err)
+	}
+
+	runner.Add(app.HTTPServer("admin server", &http.Server{
+		Addr:              utils.ParseWithFallback("ADMIN_ADDR", ":9093"),
+		Handler:           adminMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}))
+
+	return runner.Run(ctx)
+}
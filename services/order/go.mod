@@ -1,3 +1,5 @@
 module github.com/sakashimaa/go-pet-project/order
 
 go 1.25.4
+
+require pgregory.net/rapid v1.2.0
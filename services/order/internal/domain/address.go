@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+// MaxAddressesPerUser caps the address book, which is for the handful of
+// places a customer ships to, not a contact list.
+const MaxAddressesPerUser = 20
+
+var ErrInvalidAddress = errors.New("invalid address")
+
+var (
+	countryPattern    = regexp.MustCompile(`^[A-Z]{2}$`)
+	postalCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 -]*$`)
+	phonePattern      = regexp.MustCompile(`^\+?[0-9 ()-]{5,32}$`)
+)
+
+// ShippingAddress is where an order goes. Orders keep their own copy, taken
+// from the address book when the order is placed.
+type ShippingAddress struct {
+	Recipient  string `json:"recipient"`
+	Phone      string `json:"phone,omitempty"`
+	Line1      string `json:"line1"`
+	Line2      string `json:"line2,omitempty"`
+	City       string `json:"city"`
+	Region     string `json:"region,omitempty"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+}
+
+// Address is an entry of a customer's address book.
+type Address struct {
+	ID     int64  `db:"id"`
+	UserID int64  `db:"user_id"`
+	Label  string `db:"label"`
+	ShippingAddress
+
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// Normalize trims every field and upper-cases the country code.
+func (a *Address) Normalize() {
+	a.Label = strings.TrimSpace(a.Label)
+	a.Recipient = strings.TrimSpace(a.Recipient)
+	a.Phone = strings.TrimSpace(a.Phone)
+	a.Line1 = strings.TrimSpace(a.Line1)
+	a.Line2 = strings.TrimSpace(a.Line2)
+	a.City = strings.TrimSpace(a.City)
+	a.Region = strings.TrimSpace(a.Region)
+	a.PostalCode = strings.TrimSpace(a.PostalCode)
+	a.Country = strings.ToUpper(strings.TrimSpace(a.Country))
+}
+
+// Validate checks a normalized address.
+func (a *Address) Validate() error {
+	required := []struct {
+		name, value string
+		max         int
+	}{
+		{"recipient", a.Recipient, 100},
+		{"line1", a.Line1, 200},
+		{"city", a.City, 100},
+		{"postal_code", a.PostalCode, 20},
+	}
+	for _, f := range required {
+		if f.value == "" {
+			return fmt.Errorf("%w: %s is required", ErrInvalidAddress, f.name)
+		}
+		if utf8.RuneCountInString(f.value) > f.max {
+			return fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidAddress, f.name, f.max)
+		}
+	}
+
+	optional := []struct {
+		name, value string
+		max         int
+	}{
+		{"label", a.Label, 50},
+		{"line2", a.Line2, 200},
+		{"region", a.Region, 100},
+	}
+	for _, f := range optional {
+		if utf8.RuneCountInString(f.value) > f.max {
+			return fmt.Errorf("%w: %s is longer than %d characters", ErrInvalidAddress, f.name, f.max)
+		}
+	}
+
+	switch {
+	case !postalCodePattern.MatchString(a.PostalCode):
+		return fmt.Errorf("%w: postal_code may only hold letters, digits, spaces and dashes", ErrInvalidAddress)
+	case !countryPattern.MatchString(a.Country):
+		return fmt.Errorf("%w: country must be an ISO 3166-1 alpha-2 code", ErrInvalidAddress)
+	case a.Phone != "" && !phonePattern.MatchString(a.Phone):
+		return fmt.Errorf("%w: phone is not a phone number", ErrInvalidAddress)
+	}
+
+	return nil
+}
+
+func AddressFromPB(a *pb.Address) Address {
+	if a == nil {
+		return Address{}
+	}
+
+	return Address{
+		ID:    a.Id,
+		Label: a.Label,
+		ShippingAddress: ShippingAddress{
+			Recipient:  a.Recipient,
+			Phone:      a.Phone,
+			Line1:      a.Line1,
+			Line2:      a.Line2,
+			City:       a.City,
+			Region:     a.Region,
+			PostalCode: a.PostalCode,
+			Country:    a.Country,
+		},
+	}
+}
+
+func (a *Address) ToPB() *pb.Address {
+	return &pb.Address{
+		Id:         a.ID,
+		UserId:     a.UserID,
+		Label:      a.Label,
+		Recipient:  a.Recipient,
+		Phone:      a.Phone,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		CreatedAt:  a.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  a.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+type AnnotationKind string
+
+const (
+	AnnotationNote AnnotationKind = "note"
+	AnnotationFlag AnnotationKind = "flag"
+)
+
+type OrderFlag string
+
+const (
+	// FlagFraudSuspect marks an order support is checking for fraud.
+	FlagFraudSuspect OrderFlag = "fraud_suspect"
+	FlagVIP          OrderFlag = "vip"
+	// FlagEscalated marks an order handed to a senior agent.
+	FlagEscalated OrderFlag = "escalated"
+)
+
+const (
+	MaxNoteLength = 2000
+	// MaxFlagCommentLength caps the comment a flag is set with.
+	MaxFlagCommentLength = 500
+)
+
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+// ParseOrderFlag returns the flag named s.
+func ParseOrderFlag(s string) (OrderFlag, error) {
+	switch flag := OrderFlag(s); flag {
+	case FlagFraudSuspect, FlagVIP, FlagEscalated:
+		return flag, nil
+	}
+
+	return "", fmt.Errorf("%w: unknown flag %q", ErrInvalidAnnotation, s)
+}
+
+// NoteBody trims a note and checks it is neither empty nor too long.
+func NoteBody(s string) (string, error) {
+	body := strings.TrimSpace(s)
+	if body == "" || utf8.RuneCountInString(body) > MaxNoteLength {
+		return "", fmt.Errorf("%w: note must be between 1 and %d characters", ErrInvalidAnnotation, MaxNoteLength)
+	}
+
+	return body, nil
+}
+
+// OrderAnnotation is a note or a flag support put on an order. Customers
+// never see annotations.
+type OrderAnnotation struct {
+	ID      int64
+	OrderID int64
+	Kind    AnnotationKind
+	// Flag is nil for notes.
+	Flag *OrderFlag
+	// Body is the note, or the comment a flag was set with.
+	Body      string
+	AuthorID  int64
+	CreatedAt time.Time
+	// ClearedBy and ClearedAt are set once a flag was cleared.
+	ClearedBy *int64
+	ClearedAt *time.Time
+}
+
+// ActiveFlags returns the flags among annotations that were not cleared.
+func ActiveFlags(annotations []OrderAnnotation) []string {
+	var flags []string
+	for _, a := range annotations {
+		if a.Kind == AnnotationFlag && a.ClearedAt == nil {
+			flags = append(flags, string(*a.Flag))
+		}
+	}
+
+	return flags
+}
+
+func (a *OrderAnnotation) ToPB() *pb.OrderAnnotation {
+	res := &pb.OrderAnnotation{
+		Id:        a.ID,
+		OrderId:   a.OrderID,
+		Kind:      string(a.Kind),
+		Body:      a.Body,
+		AuthorId:  a.AuthorID,
+		CreatedAt: a.CreatedAt.Format(time.RFC3339),
+	}
+
+	if a.Flag != nil {
+		res.Flag = string(*a.Flag)
+	}
+	if a.ClearedBy != nil {
+		res.ClearedBy = *a.ClearedBy
+	}
+	if a.ClearedAt != nil {
+		res.ClearedAt = a.ClearedAt.Format(time.RFC3339)
+	}
+
+	return res
+}
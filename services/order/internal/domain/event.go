@@ -1,6 +1,60 @@
 package domain
 
+import "time"
+
 type UserRegisteredEvent struct {
 	UserID int64  `json:"user_id"`
 	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+type UserRoleChangedEvent struct {
+	UserID int64  `json:"user_id"`
+	Role   string `json:"role"`
+}
+
+type InventoryReservedEvent struct {
+	OrderID    int64     `json:"order_id"`
+	UserID     int64     `json:"user_id"`
+	Amount     int64     `json:"amount"`
+	ReservedAt time.Time `json:"reserved_at"`
+}
+
+type ReservedItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+	Price     int64 `json:"price"`
+}
+
+type UnavailableItem struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+}
+
+type InventoryPartiallyReservedEvent struct {
+	OrderID          int64             `json:"order_id"`
+	UserID           int64             `json:"user_id"`
+	Amount           int64             `json:"amount"`
+	ReservedItems    []ReservedItem    `json:"reserved_items"`
+	UnavailableItems []UnavailableItem `json:"unavailable_items"`
+	ReservedAt       time.Time         `json:"reserved_at"`
+}
+
+// OrderConfirmedEvent asks payment to charge an order whose reservation was
+// settled by the user rather than by the product service.
+type OrderConfirmedEvent struct {
+	OrderID         int64     `json:"order_id"`
+	UserID          int64     `json:"user_id"`
+	Amount          int64     `json:"amount"`
+	ReservedAt      time.Time `json:"reserved_at"`
+	PaymentMethodID *int64    `json:"payment_method_id,omitempty"`
+}
+
+// PaymentTimedOutEvent tells payment that the order was cancelled because no
+// payment result arrived in time, so it must not be charged anymore.
+type PaymentTimedOutEvent struct {
+	OrderID    int64     `json:"order_id"`
+	UserID     int64     `json:"user_id"`
+	Amount     int64     `json:"amount"`
+	TimedOutAt time.Time `json:"timed_out_at"`
 }
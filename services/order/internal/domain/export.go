@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"strconv"
+	"time"
+)
+
+// OrderExportFilter selects the orders created in [From, To).
+type OrderExportFilter struct {
+	From time.Time
+	To   time.Time
+	// Status keeps only orders in that status when set.
+	Status OrderStatus
+}
+
+// OrderExportRow is one order as accounting sees it. The invoice fields are
+// nil until the order is paid and invoiced.
+type OrderExportRow struct {
+	OrderID         int64
+	UserID          int64
+	Status          OrderStatus
+	TotalSum        int64
+	ReservedAmount  int64
+	PaymentMethodID *int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+
+	InvoiceNumber *string
+	Currency      *string
+	TaxRateBps    *int64
+	NetTotal      *int64
+	TaxTotal      *int64
+	InvoiceTotal  *int64
+	InvoicedAt    *time.Time
+}
+
+// OrderExportHeader names the columns of OrderExportRow.Record.
+var OrderExportHeader = []string{
+	"order_id",
+	"user_id",
+	"status",
+	"created_at",
+	"updated_at",
+	"total_sum",
+	"payment_status",
+	"payment_method_id",
+	"amount_charged",
+	"invoice_number",
+	"invoiced_at",
+	"currency",
+	"tax_rate_bps",
+	"net_total",
+	"tax_total",
+	"gross_total",
+}
+
+// Record formats the row for CSV. Missing values are empty cells rather
+// than zeros, so "no invoice yet" does not read as "invoiced for 0".
+func (r *OrderExportRow) Record() []string {
+	paymentStatus := "unpaid"
+	var charged int64
+	if r.Status == OrderStatusPaid || r.Status == OrderStatusShipped {
+		paymentStatus = "paid"
+		charged = r.ReservedAmount
+	}
+
+	invoicedAt := ""
+	if r.InvoicedAt != nil {
+		invoicedAt = r.InvoicedAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		strconv.FormatInt(r.OrderID, 10),
+		strconv.FormatInt(r.UserID, 10),
+		string(r.Status),
+		r.CreatedAt.UTC().Format(time.RFC3339),
+		r.UpdatedAt.UTC().Format(time.RFC3339),
+		strconv.FormatInt(r.TotalSum, 10),
+		paymentStatus,
+		optionalInt(r.PaymentMethodID),
+		strconv.FormatInt(charged, 10),
+		optionalString(r.InvoiceNumber),
+		invoicedAt,
+		optionalString(r.Currency),
+		optionalInt(r.TaxRateBps),
+		optionalInt(r.NetTotal),
+		optionalInt(r.TaxTotal),
+		optionalInt(r.InvoiceTotal),
+	}
+}
+
+// IsKnownOrderStatus reports whether s is one of the OrderStatus values.
+func IsKnownOrderStatus(s OrderStatus) bool {
+	switch s {
+	case OrderStatusNew, OrderStatusReserved, OrderStatusPartiallyReserved, OrderStatusAwaitingStock,
+		OrderStatusPaid, OrderStatusCancelled, OrderStatusShipped:
+		return true
+	}
+
+	return false
+}
+
+func optionalInt(v *int64) string {
+	if v == nil {
+		return ""
+	}
+
+	return strconv.FormatInt(*v, 10)
+}
+
+func optionalString(v *string) string {
+	if v == nil {
+		return ""
+	}
+
+	return *v
+}
@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const TimelineInvoiceIssued = "invoice_issued"
+
+// DeliveryLineName names the invoice line of the delivery fee, which has no
+// product.
+const DeliveryLineName = "Delivery"
+
+// DiscountLineName names the negative invoice line of a manual order's
+// discount.
+const DiscountLineName = "Discount"
+
+// InvoiceIssuer is the seller printed on every invoice.
+type InvoiceIssuer struct {
+	Name     string
+	Address  string
+	TaxID    string
+	Email    string
+	Currency string
+	// TaxRateBps is the tax included in item prices, in hundredths of a
+	// percent, e.g. 2000 for 20% VAT.
+	TaxRateBps int64
+}
+
+type InvoiceLine struct {
+	ProductID int64  `json:"product_id"`
+	Name      string `json:"name"`
+	Quantity  int64  `json:"quantity"`
+	UnitPrice int64  `json:"unit_price"`
+	Net       int64  `json:"net"`
+	Tax       int64  `json:"tax"`
+	Gross     int64  `json:"gross"`
+}
+
+type Invoice struct {
+	ID         int64         `db:"id"`
+	OrderID    int64         `db:"order_id"`
+	UserID     int64         `db:"user_id"`
+	Number     string        `db:"number"`
+	Lines      []InvoiceLine `db:"lines"`
+	Currency   string        `db:"currency"`
+	TaxRateBps int64         `db:"tax_rate_bps"`
+	NetTotal   int64         `db:"net_total"`
+	TaxTotal   int64         `db:"tax_total"`
+	Total      int64         `db:"total"`
+	ObjectKey  string        `db:"object_key"`
+	IssuedAt   time.Time     `db:"issued_at"`
+}
+
+type InvoiceGeneratedEvent struct {
+	OrderID   int64     `json:"order_id"`
+	UserID    int64     `json:"user_id"`
+	InvoiceID int64     `json:"invoice_id"`
+	Number    string    `json:"number"`
+	Total     int64     `json:"total"`
+	Currency  string    `json:"currency"`
+	IssuedAt  time.Time `json:"issued_at"`
+}
+
+// InvoiceNumber formats the per-year sequence, e.g. INV-2026-000042.
+func InvoiceNumber(year int, sequence int64) string {
+	return fmt.Sprintf("INV-%d-%06d", year, sequence)
+}
+
+// NewInvoice bills the paid items of an order. Prices include tax, so each
+// line is split into net and tax, rounding the tax to the nearest unit.
+func NewInvoice(order *Order, issuer InvoiceIssuer, issuedAt time.Time) *Invoice {
+	invoice := &Invoice{
+		OrderID:    order.ID,
+		UserID:     order.UserID,
+		Currency:   issuer.Currency,
+		TaxRateBps: issuer.TaxRateBps,
+		IssuedAt:   issuedAt,
+	}
+
+	for _, item := range order.Items {
+		if item.Status != OrderItemStatusPaid && item.Status != OrderItemStatusShipped {
+			continue
+		}
+
+		gross := item.Price * int64(item.Quantity)
+		tax := includedTax(gross, issuer.TaxRateBps)
+
+		invoice.addLine(InvoiceLine{
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Quantity:  int64(item.Quantity),
+			UnitPrice: item.Price,
+			Net:       gross - tax,
+			Tax:       tax,
+			Gross:     gross,
+		})
+	}
+
+	// The discount lowers the tax on the items it is taken off, and never
+	// exceeds what is billed for them.
+	if discount := min(order.Discount, invoice.Total); discount > 0 {
+		tax := includedTax(discount, issuer.TaxRateBps)
+
+		invoice.addLine(InvoiceLine{
+			Name:      DiscountLineName,
+			Quantity:  1,
+			UnitPrice: -discount,
+			Net:       -(discount - tax),
+			Tax:       -tax,
+			Gross:     -discount,
+		})
+	}
+
+	// Delivery is billed as a line of its own, taxed like the items.
+	if order.DeliveryFee > 0 {
+		tax := includedTax(order.DeliveryFee, issuer.TaxRateBps)
+
+		invoice.addLine(InvoiceLine{
+			Name:      DeliveryLineName,
+			Quantity:  1,
+			UnitPrice: order.DeliveryFee,
+			Net:       order.DeliveryFee - tax,
+			Tax:       tax,
+			Gross:     order.DeliveryFee,
+		})
+	}
+
+	return invoice
+}
+
+func (i *Invoice) addLine(line InvoiceLine) {
+	i.Lines = append(i.Lines, line)
+	i.NetTotal += line.Net
+	i.TaxTotal += line.Tax
+	i.Total += line.Gross
+}
+
+func includedTax(gross, rateBps int64) int64 {
+	if rateBps <= 0 {
+		return 0
+	}
+
+	divisor := 10_000 + rateBps
+	return (gross*rateBps + divisor/2) / divisor
+}
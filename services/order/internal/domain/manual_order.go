@@ -0,0 +1,41 @@
+package domain
+
+import "time"
+
+// Reason codes for prices set by support. Reports group overrides by them,
+// so the list is closed; anything else is "other" with a note.
+const (
+	PriceReasonPhoneOrder  = "phone_order"
+	PriceReasonGoodwill    = "goodwill"
+	PriceReasonPriceMatch  = "price_match"
+	PriceReasonDamagedItem = "damaged_item"
+	PriceReasonOther       = "other"
+)
+
+// IsKnownPriceReason reports whether code is one of the reason codes above.
+func IsKnownPriceReason(code string) bool {
+	switch code {
+	case PriceReasonPhoneOrder, PriceReasonGoodwill, PriceReasonPriceMatch, PriceReasonDamagedItem, PriceReasonOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// PriceOverride is the audit record of a price support set on a manual
+// order: either the unit price of one line or, with ProductID nil, the
+// discount on the whole order.
+type PriceOverride struct {
+	ID      int64 `db:"id"`
+	OrderID int64 `db:"order_id"`
+	AdminID int64 `db:"admin_id"`
+	// ProductID is the overridden line, nil for the order discount.
+	ProductID *int64 `db:"product_id"`
+	// OriginalAmount is the list unit price for a line and the subtotal for
+	// the order discount; Amount is the unit price charged or the discount.
+	OriginalAmount int64     `db:"original_amount"`
+	Amount         int64     `db:"amount"`
+	ReasonCode     string    `db:"reason_code"`
+	Note           string    `db:"note"`
+	CreatedAt      time.Time `db:"created_at"`
+}
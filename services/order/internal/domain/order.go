@@ -1,18 +1,40 @@
 package domain
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	pb "github.com/sakashimaa/go-pet-project/proto/order"
 )
 
 type OrderStatus string
 
 const (
-	OrderStatusNew       OrderStatus = "new"
-	OrderStatusPaid      OrderStatus = "paid"
-	OrderStatusCancelled OrderStatus = "cancelled"
-	OrderStatusShipped   OrderStatus = "shipped"
+	OrderStatusNew               OrderStatus = "new"
+	OrderStatusReserved          OrderStatus = "reserved"
+	OrderStatusPartiallyReserved OrderStatus = "partially_reserved"
+	OrderStatusAwaitingStock     OrderStatus = "awaiting_stock"
+	OrderStatusPaid              OrderStatus = "paid"
+	OrderStatusCancelled         OrderStatus = "cancelled"
+	OrderStatusShipped           OrderStatus = "shipped"
+)
+
+type OrderItemStatus string
+
+const (
+	OrderItemStatusPending     OrderItemStatus = "pending"
+	OrderItemStatusReserved    OrderItemStatus = "reserved"
+	OrderItemStatusUnavailable OrderItemStatus = "unavailable"
+	OrderItemStatusRemoved     OrderItemStatus = "removed"
+	OrderItemStatusPaid        OrderItemStatus = "paid"
+	OrderItemStatusShipped     OrderItemStatus = "shipped"
+)
+
+const (
+	FulfillmentChoiceWait              = "wait"
+	FulfillmentChoiceRemoveUnavailable = "remove_unavailable"
 )
 
 type Order struct {
@@ -21,11 +43,45 @@ type Order struct {
 	Status   OrderStatus `db:"status"`
 	Items    []OrderItem `db:"items"`
 	TotalSum int64       `db:"total_sum"`
+	// DeliveryFee is quoted when the order is placed and stays as quoted,
+	// even if items are removed later. It is part of TotalSum.
+	DeliveryFee int64 `db:"delivery_fee"`
+	// Discount is taken off the items of a manual order; see
+	// AppliedDiscount.
+	Discount int64 `db:"discount"`
+	// PlacedBy is the admin who placed a manual order, nil for orders the
+	// customer placed.
+	PlacedBy *int64 `db:"placed_by"`
+
+	ReservedAmount    int64  `db:"reserved_amount"`
+	FulfillmentChoice string `db:"fulfillment_choice"`
+	// PaymentMethodID is the saved method to charge, owned by the payment
+	// service. Nil leaves the choice to the provider.
+	PaymentMethodID *int64 `db:"payment_method_id"`
+	// ShippingAddress is the copy of the address book entry the order ships
+	// to. Orders placed before addresses existed have none.
+	ShippingAddress *ShippingAddress `db:"shipping_address"`
+	// ClientToken is the token the client submitted the order with, nil
+	// when it sent none. A user places one order per token.
+	ClientToken *uuid.UUID `db:"client_token"`
 
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
+// StaleReservation is an order the payment watchdog is about to expire,
+// along with the tenant it has to be handled in.
+type StaleReservation struct {
+	OrderID  int64
+	TenantID string
+}
+
+// OrderCursor is the keyset position of an order in newest-first listings.
+type OrderCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        int64     `json:"id"`
+}
+
 type OrderItem struct {
 	ID        int64  `db:"id"`
 	OrderID   int64  `db:"order_id"`
@@ -33,21 +89,106 @@ type OrderItem struct {
 	Name      string `db:"name"`
 	Price     int64  `db:"price"`
 	Quantity  int32  `db:"quantity"`
+	// WeightGrams is the weight of one unit, 0 when the client did not say.
+	WeightGrams int32 `db:"weight_grams"`
+	// ListPrice is the price support overrode with Price on a manual order.
+	// It is only set while the order is placed; the audit trail keeps it.
+	ListPrice *int64 `db:"-"`
+
+	Status OrderItemStatus `db:"status"`
 }
 
+// Limits on a single order. Prices and totals are in minor units. They keep
+// every total far below int64 overflow and stop one request from reserving
+// a warehouse's worth of stock.
+const (
+	MaxOrderLines   = 100
+	MaxItemQuantity = 1_000
+	MaxItemPrice    = 100_000_000
+	MaxOrderTotal   = 1_000_000_000
+	// MaxItemWeightGrams is a tonne per unit.
+	MaxItemWeightGrams = 1_000_000
+	MaxDeliveryFee     = 10_000_000
+)
+
+var ErrInvalidOrder = errors.New("invalid order")
+
+// Validate checks a new order against the limits. Lines are checked before
+// anything is summed, so the total it compares cannot overflow either.
+func (o *Order) Validate() error {
+	if len(o.Items) > MaxOrderLines {
+		return fmt.Errorf("%w: more than %d lines", ErrInvalidOrder, MaxOrderLines)
+	}
+
+	var total int64
+	for _, item := range o.Items {
+		if item.Quantity <= 0 || item.Quantity > MaxItemQuantity {
+			return fmt.Errorf("%w: quantity of product %d must be between 1 and %d", ErrInvalidOrder, item.ProductID, MaxItemQuantity)
+		}
+		if item.Price < 0 || item.Price > MaxItemPrice {
+			return fmt.Errorf("%w: price of product %d must be between 0 and %d", ErrInvalidOrder, item.ProductID, MaxItemPrice)
+		}
+		if item.WeightGrams < 0 || item.WeightGrams > MaxItemWeightGrams {
+			return fmt.Errorf("%w: weight of product %d must be between 0 and %d grams", ErrInvalidOrder, item.ProductID, MaxItemWeightGrams)
+		}
+
+		total += item.Price * int64(item.Quantity)
+	}
+
+	if total > MaxOrderTotal {
+		return fmt.Errorf("%w: total exceeds %d", ErrInvalidOrder, MaxOrderTotal)
+	}
+
+	return nil
+}
+
+// CalculateTotal sums the items that are still part of the order, less the
+// discount, and the delivery fee.
 func (o *Order) CalculateTotal() {
+	o.TotalSum = o.Subtotal() - o.AppliedDiscount() + o.DeliveryFee
+}
+
+// AppliedDiscount is the discount capped at the subtotal, so removing items
+// from a discounted order never makes it cost less than nothing.
+func (o *Order) AppliedDiscount() int64 {
+	return min(o.Discount, o.Subtotal())
+}
+
+// Subtotal is the price of the items that are still part of the order.
+func (o *Order) Subtotal() int64 {
 	var total int64
 	for _, item := range o.Items {
+		if item.Status == OrderItemStatusRemoved {
+			continue
+		}
+
 		total += item.Price * int64(item.Quantity)
 	}
-	o.TotalSum = total
+
+	return total
+}
+
+// WeightGrams is the weight of the items that are still part of the order.
+func (o *Order) WeightGrams() int64 {
+	var weight int64
+	for _, item := range o.Items {
+		if item.Status == OrderItemStatusRemoved {
+			continue
+		}
+
+		weight += int64(item.WeightGrams) * int64(item.Quantity)
+	}
+
+	return weight
 }
 
 func (i *OrderItem) ToPB() *pb.OrderItem {
 	return &pb.OrderItem{
-		ProductId: i.ProductID,
-		Name:      i.Name,
-		Price:     i.Price,
-		Quantity:  i.Quantity,
+		ProductId:   i.ProductID,
+		Name:        i.Name,
+		Price:       i.Price,
+		Quantity:    i.Quantity,
+		Status:      string(i.Status),
+		WeightGrams: i.WeightGrams,
 	}
 }
@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+// MaxOverrideReasonLength caps the reason an admin gives for forcing a
+// status.
+const MaxOverrideReasonLength = 500
+
+// statusOverrides are the transitions an admin may force, for orders the
+// saga left stuck. Paid and cancelled orders never swap places: money taken
+// is refunded through payment, and stock released may already be sold.
+var statusOverrides = map[OrderStatus][]OrderStatus{
+	OrderStatusNew:               {OrderStatusCancelled},
+	OrderStatusReserved:          {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPartiallyReserved: {OrderStatusCancelled},
+	OrderStatusAwaitingStock:     {OrderStatusCancelled},
+	OrderStatusPaid:              {OrderStatusShipped},
+}
+
+// CanForceStatus reports whether an admin may move an order from one status
+// to the other.
+func CanForceStatus(from, to OrderStatus) bool {
+	for _, allowed := range statusOverrides[from] {
+		if allowed == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// StatusOverride is the audit record of a forced status.
+type StatusOverride struct {
+	ID         int64       `db:"id"`
+	OrderID    int64       `db:"order_id"`
+	AdminID    int64       `db:"admin_id"`
+	FromStatus OrderStatus `db:"from_status"`
+	ToStatus   OrderStatus `db:"to_status"`
+	Reason     string      `db:"reason"`
+	CreatedAt  time.Time   `db:"created_at"`
+}
+
+// OrderStatusForcedEvent tells the other services an admin moved the order
+// by hand, outside the saga.
+type OrderStatusForcedEvent struct {
+	OrderID    int64     `json:"order_id"`
+	UserID     int64     `json:"user_id"`
+	AdminID    int64     `json:"admin_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	Reason     string    `json:"reason"`
+	ForcedAt   time.Time `json:"forced_at"`
+}
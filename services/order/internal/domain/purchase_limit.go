@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+// OrderRateWindow is the window the per-user order rate limit counts over.
+const OrderRateWindow = time.Hour
+
+var (
+	ErrOrderRateLimited    = errors.New("too many orders placed")
+	ErrPurchaseCapExceeded = errors.New("purchase limit exceeded")
+)
+
+// RateLimitError is returned when a user already placed Limit orders within
+// OrderRateWindow. It unwraps to ErrOrderRateLimited.
+type RateLimitError struct {
+	Limit int
+	// RetryAfter is when the oldest of those orders leaves the window.
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: at most %d orders per hour, retry in %s", ErrOrderRateLimited, e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrOrderRateLimited
+}
+
+// PurchaseCapError is returned when an order would take a user over the
+// cap of a product. It unwraps to ErrPurchaseCapExceeded.
+type PurchaseCapError struct {
+	ProductID int64
+	Cap       int32
+	// Remaining is how many more units the user may still buy.
+	Remaining int32
+}
+
+func (e *PurchaseCapError) Error() string {
+	return fmt.Sprintf("%s: product %d is limited to %d per customer, %d left", ErrPurchaseCapExceeded, e.ProductID, e.Cap, e.Remaining)
+}
+
+func (e *PurchaseCapError) Unwrap() error {
+	return ErrPurchaseCapExceeded
+}
+
+// PurchaseCap limits how many units of a product one customer may buy
+// across all their orders.
+type PurchaseCap struct {
+	ProductID  int64
+	MaxPerUser int32
+	SetBy      int64
+	UpdatedAt  time.Time
+}
+
+func (c *PurchaseCap) ToPB() *pb.PurchaseCap {
+	return &pb.PurchaseCap{
+		ProductId:  c.ProductID,
+		MaxPerUser: c.MaxPerUser,
+		SetBy:      c.SetBy,
+		UpdatedAt:  c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// PurchaseCapUsage is a cap together with how much of the product a user
+// already bought under it.
+type PurchaseCapUsage struct {
+	ProductID  int64
+	MaxPerUser int32
+	Purchased  int32
+}
+
+// CheckPurchaseCaps returns a *PurchaseCapError for the first capped product
+// the items would take the user over. Lines of the same product add up.
+func CheckPurchaseCaps(items []OrderItem, usage []PurchaseCapUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	ordered := make(map[int64]int32, len(items))
+	for _, item := range items {
+		ordered[item.ProductID] += item.Quantity
+	}
+
+	for _, u := range usage {
+		if u.Purchased+ordered[u.ProductID] > u.MaxPerUser {
+			return &PurchaseCapError{
+				ProductID: u.ProductID,
+				Cap:       u.MaxPerUser,
+				Remaining: max(u.MaxPerUser-u.Purchased, 0),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+type ReturnStatus string
+
+const (
+	ReturnStatusRequested ReturnStatus = "requested"
+	ReturnStatusApproved  ReturnStatus = "approved"
+	ReturnStatusRejected  ReturnStatus = "rejected"
+	// ReturnStatusRefunded is an approved return payment has paid back.
+	ReturnStatusRefunded ReturnStatus = "refunded"
+)
+
+const (
+	// ReturnWindow is how long after placing an order its items can be
+	// returned.
+	ReturnWindow = 30 * 24 * time.Hour
+	// MaxReturnReasonLength caps the reason a customer gives for a return
+	// and the note support answers with.
+	MaxReturnReasonLength = 500
+	// MaxListedReturns is how many returns ListReturns shows.
+	MaxListedReturns = 100
+)
+
+var ErrInvalidReturn = errors.New("invalid return")
+
+// IsKnownReturnStatus reports whether s is one of the ReturnStatus values.
+func IsKnownReturnStatus(s ReturnStatus) bool {
+	switch s {
+	case ReturnStatusRequested, ReturnStatusApproved, ReturnStatusRejected, ReturnStatusRefunded:
+		return true
+	}
+
+	return false
+}
+
+// Return is a request to send back items of an order. The items are copies
+// of the order lines they come from, so later price changes do not touch
+// the refund.
+type Return struct {
+	ID      int64
+	OrderID int64
+	UserID  int64
+	Status  ReturnStatus
+	Reason  string
+	Items   []ReturnItem
+	// RefundAmount is what the items cost, fixed when the return is
+	// requested.
+	RefundAmount int64
+	// RefundedAmount is what payment paid back, nil until it did.
+	RefundedAmount *int64
+	ReviewedBy     *int64
+	ReviewNote     string
+	ReviewedAt     *time.Time
+	RefundedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+type ReturnItem struct {
+	OrderItemID int64
+	ProductID   int64
+	Name        string
+	Price       int64
+	Quantity    int32
+}
+
+// ReturnLine is a product and how many units of it the customer sends back.
+type ReturnLine struct {
+	ProductID int64
+	Quantity  int32
+}
+
+// IsReturnable reports whether items of the order can still be returned:
+// only paid or shipped orders within ReturnWindow of being placed.
+func (o *Order) IsReturnable(now time.Time) bool {
+	if o.Status != OrderStatusPaid && o.Status != OrderStatusShipped {
+		return false
+	}
+
+	return now.Sub(o.CreatedAt) <= ReturnWindow
+}
+
+// PlanReturn matches the lines a customer sends back to the paid or shipped
+// lines of order. returned holds the units of each order line already taken
+// by other returns. A product bought on several lines is taken from them in
+// order.
+func PlanReturn(order *Order, returned map[int64]int32, lines []ReturnLine) ([]ReturnItem, error) {
+	if len(lines) == 0 || len(lines) > MaxOrderLines {
+		return nil, fmt.Errorf("%w: between 1 and %d lines are required", ErrInvalidReturn, MaxOrderLines)
+	}
+
+	wanted := make(map[int64]int32, len(lines))
+	products := make([]int64, 0, len(lines))
+	for _, line := range lines {
+		if line.ProductID <= 0 || line.Quantity <= 0 || line.Quantity > MaxItemQuantity {
+			return nil, fmt.Errorf("%w: product %d quantity %d", ErrInvalidReturn, line.ProductID, line.Quantity)
+		}
+
+		if _, ok := wanted[line.ProductID]; !ok {
+			products = append(products, line.ProductID)
+		}
+		wanted[line.ProductID] += line.Quantity
+	}
+
+	var items []ReturnItem
+	for _, productID := range products {
+		left := wanted[productID]
+
+		for _, item := range order.Items {
+			if left == 0 {
+				break
+			}
+			if item.ProductID != productID {
+				continue
+			}
+			if item.Status != OrderItemStatusPaid && item.Status != OrderItemStatusShipped {
+				continue
+			}
+
+			available := item.Quantity - returned[item.ID]
+			if available <= 0 {
+				continue
+			}
+
+			quantity := min(left, available)
+			items = append(items, ReturnItem{
+				OrderItemID: item.ID,
+				ProductID:   item.ProductID,
+				Name:        item.Name,
+				Price:       item.Price,
+				Quantity:    quantity,
+			})
+			left -= quantity
+		}
+
+		if left > 0 {
+			return nil, fmt.Errorf("%w: %d more units of product %d than can be returned", ErrInvalidReturn, left, productID)
+		}
+	}
+
+	return items, nil
+}
+
+// RefundFor is what returning items pays back: their prices, but never more
+// than is left of what the order was charged for its items once earlier
+// returns are taken off. The delivery fee is not refunded.
+func RefundFor(order *Order, items []ReturnItem, claimed int64) int64 {
+	var amount int64
+	for _, item := range items {
+		amount += item.Price * int64(item.Quantity)
+	}
+
+	left := order.ReservedAmount - order.DeliveryFee - claimed
+
+	return max(min(amount, left), 0)
+}
+
+func (r *Return) ToPB() *pb.Return {
+	res := &pb.Return{
+		Id:           r.ID,
+		OrderId:      r.OrderID,
+		UserId:       r.UserID,
+		Status:       string(r.Status),
+		Reason:       r.Reason,
+		Items:        make([]*pb.ReturnItem, 0, len(r.Items)),
+		RefundAmount: r.RefundAmount,
+		ReviewNote:   r.ReviewNote,
+		CreatedAt:    r.CreatedAt.Format(time.RFC3339),
+	}
+
+	for _, item := range r.Items {
+		res.Items = append(res.Items, &pb.ReturnItem{
+			ProductId: item.ProductID,
+			Name:      item.Name,
+			Price:     item.Price,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	if r.RefundedAmount != nil {
+		res.RefundedAmount = *r.RefundedAmount
+	}
+	if r.ReviewedAt != nil {
+		res.ReviewedAt = r.ReviewedAt.Format(time.RFC3339)
+	}
+	if r.RefundedAt != nil {
+		res.RefundedAt = r.RefundedAt.Format(time.RFC3339)
+	}
+
+	return res
+}
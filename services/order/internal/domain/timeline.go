@@ -0,0 +1,53 @@
+package domain
+
+import "time"
+
+const (
+	TimelineOrderCreated      = "order_created"
+	TimelineInventoryReserved = "inventory_reserved"
+	TimelinePartialReserved   = "inventory_partially_reserved"
+	TimelineReservationChoice = "reservation_choice"
+	TimelinePaymentSucceeded  = "payment_succeeded"
+	TimelinePaymentFailed     = "payment_failed"
+	TimelinePaymentTimedOut   = "payment_timed_out"
+	TimelineLatePayment       = "late_payment"
+	TimelineOrderCancelled    = "order_cancelled"
+	TimelineShipmentUpdated   = "shipment_updated"
+	TimelineReturnRequested   = "return_requested"
+	TimelineReturnApproved    = "return_approved"
+	TimelineReturnRejected    = "return_rejected"
+	TimelineReturnRefunded    = "return_refunded"
+	// TimelineReservationReleased is internal: stock reserved after the
+	// order was cancelled was handed back.
+	TimelineReservationReleased = "reservation_released"
+	// TimelineStatusForced is internal: an admin moved the order by hand.
+	TimelineStatusForced = "status_forced"
+	// TimelinePricesOverridden is internal: support set prices on a manual
+	// order.
+	TimelinePricesOverridden = "prices_overridden"
+	// TimelineNoteAdded, TimelineFlagSet and TimelineFlagCleared are
+	// internal: support annotated the order.
+	TimelineNoteAdded   = "note_added"
+	TimelineFlagSet     = "flag_set"
+	TimelineFlagCleared = "flag_cleared"
+)
+
+// TimelineEvent is a single entry of the order's chronological history.
+// Entries that are not visible to the customer are only shown to support.
+type TimelineEvent struct {
+	ID                int64       `db:"id"`
+	OrderID           int64       `db:"order_id"`
+	EventType         string      `db:"event_type"`
+	Status            OrderStatus `db:"status"`
+	Message           string      `db:"message"`
+	VisibleToCustomer bool        `db:"visible_to_customer"`
+	CreatedAt         time.Time   `db:"created_at"`
+}
+
+type ShipmentUpdatedEvent struct {
+	OrderID        int64     `json:"order_id"`
+	Status         string    `json:"status"`
+	Carrier        string    `json:"carrier"`
+	TrackingNumber string    `json:"tracking_number"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
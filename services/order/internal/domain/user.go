@@ -1,6 +1,12 @@
 package domain
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
 type User struct {
 	ID    int64  `db:"id"`
 	Email string `db:"email"`
+	Role  string `db:"role"`
 }
@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const addressColumns = `id, user_id, label, recipient, phone, line1, line2, city, region, postal_code, country, created_at, updated_at`
+
+// CreateAddress adds the address unless the user already has
+// domain.MaxAddressesPerUser of them, in which case it fails with
+// ErrAddressBookFull.
+func (r *orderRepo) CreateAddress(ctx context.Context, address *domain.Address) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.CreateAddress")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", address.UserID))
+
+	query := `
+		INSERT INTO addresses (user_id, label, recipient, phone, line1, line2, city, region, postal_code, country, tenant_id)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+		WHERE (SELECT COUNT(*) FROM addresses WHERE user_id = $1 AND tenant_id = $11) < $12
+		RETURNING id, created_at, updated_at;
+	`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		address.UserID,
+		address.Label,
+		address.Recipient,
+		address.Phone,
+		address.Line1,
+		address.Line2,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.Country,
+		tenant.FromContext(ctx),
+		domain.MaxAddressesPerUser,
+	).Scan(&address.ID, &address.CreatedAt, &address.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAddressBookFull
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to create address", zap.Error(err))
+
+		return fmt.Errorf("failed to create address: %w", err)
+	}
+
+	return nil
+}
+
+func (r *orderRepo) ListAddresses(ctx context.Context, userID int64) ([]domain.Address, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListAddresses")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		SELECT ` + addressColumns + `
+		FROM addresses
+		WHERE user_id = $1 AND tenant_id = $2
+		ORDER BY created_at, id;
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to list addresses", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.Address
+	for rows.Next() {
+		var a domain.Address
+		if err := scanAddress(rows, &a); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan address: %w", err)
+		}
+
+		result = append(result, a)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetAddress returns one of the user's addresses, reading through tx so an
+// order can copy it in the transaction that creates the order.
+func (r *orderRepo) GetAddress(ctx context.Context, tx pgx.Tx, id, userID int64) (*domain.Address, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetAddress")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("address_id", id))
+
+	query := `
+		SELECT ` + addressColumns + `
+		FROM addresses
+		WHERE id = $1 AND user_id = $2 AND tenant_id = $3;
+	`
+
+	var a domain.Address
+	if err := scanAddress(tx.QueryRow(ctx, query, id, userID, tenant.FromContext(ctx)), &a); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAddressNotFound
+		}
+
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to get address: %w", err)
+	}
+
+	return &a, nil
+}
+
+func (r *orderRepo) UpdateAddress(ctx context.Context, address *domain.Address) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.UpdateAddress")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("address_id", address.ID))
+
+	query := `
+		UPDATE addresses
+		SET label = $3, recipient = $4, phone = $5, line1 = $6, line2 = $7, city = $8,
+			region = $9, postal_code = $10, country = $11, updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND tenant_id = $12
+		RETURNING created_at, updated_at;
+	`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		address.ID,
+		address.UserID,
+		address.Label,
+		address.Recipient,
+		address.Phone,
+		address.Line1,
+		address.Line2,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.Country,
+		tenant.FromContext(ctx),
+	).Scan(&address.CreatedAt, &address.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAddressNotFound
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to update address", zap.Error(err))
+
+		return fmt.Errorf("failed to update address: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAddress removes the address for good: orders shipped to it keep
+// their own copy.
+func (r *orderRepo) DeleteAddress(ctx context.Context, id, userID int64) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.DeleteAddress")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("address_id", id))
+
+	tag, err := r.pool.Exec(ctx, `DELETE FROM addresses WHERE id = $1 AND user_id = $2 AND tenant_id = $3`, id, userID, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to delete address", zap.Error(err))
+
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrAddressNotFound
+	}
+
+	return nil
+}
+
+func scanAddress(row pgx.Row, a *domain.Address) error {
+	return row.Scan(
+		&a.ID,
+		&a.UserID,
+		&a.Label,
+		&a.Recipient,
+		&a.Phone,
+		&a.Line1,
+		&a.Line2,
+		&a.City,
+		&a.Region,
+		&a.PostalCode,
+		&a.Country,
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const annotationColumns = `
+	id, order_id, kind, flag, body, author_id, created_at, cleared_by, cleared_at
+`
+
+func scanAnnotation(row pgx.Row, a *domain.OrderAnnotation) error {
+	return row.Scan(
+		&a.ID,
+		&a.OrderID,
+		&a.Kind,
+		&a.Flag,
+		&a.Body,
+		&a.AuthorID,
+		&a.CreatedAt,
+		&a.ClearedBy,
+		&a.ClearedAt,
+	)
+}
+
+func (r *orderRepo) CreateAnnotation(ctx context.Context, tx pgx.Tx, a *domain.OrderAnnotation) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.CreateAnnotation")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", a.OrderID),
+		attribute.String("kind", string(a.Kind)),
+	)
+
+	query := `
+		INSERT INTO order_annotations (order_id, kind, flag, body, author_id, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at;
+	`
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		a.OrderID,
+		string(a.Kind),
+		a.Flag,
+		a.Body,
+		a.AuthorID,
+		tenant.FromContext(ctx),
+	).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to create annotation", zap.Int64("order_id", a.OrderID), zap.Error(err))
+
+		return fmt.Errorf("failed to create annotation: %w", err)
+	}
+
+	return nil
+}
+
+// GetActiveFlag returns the flag set on the order, or nil if it is not set.
+func (r *orderRepo) GetActiveFlag(ctx context.Context, tx pgx.Tx, orderID int64, flag domain.OrderFlag) (*domain.OrderAnnotation, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetActiveFlag")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.String("flag", string(flag)),
+	)
+
+	query := `SELECT` + annotationColumns + `
+		FROM order_annotations
+		WHERE order_id = $1 AND tenant_id = $2 AND kind = $3 AND flag = $4 AND cleared_at IS NULL;
+	`
+
+	var a domain.OrderAnnotation
+	err := scanAnnotation(tx.QueryRow(ctx, query, orderID, tenant.FromContext(ctx), string(domain.AnnotationFlag), string(flag)), &a)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to query flag: %w", err)
+	}
+
+	return &a, nil
+}
+
+// ClearFlag stamps the flag set on the order as cleared by adminID. It fails
+// with ErrAnnotationNotFound if the flag is not set.
+func (r *orderRepo) ClearFlag(ctx context.Context, tx pgx.Tx, orderID int64, flag domain.OrderFlag, adminID int64) (*domain.OrderAnnotation, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ClearFlag")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.String("flag", string(flag)),
+		attribute.Int64("admin_id", adminID),
+	)
+
+	query := `
+		UPDATE order_annotations
+		SET cleared_by = $5, cleared_at = NOW()
+		WHERE order_id = $1 AND tenant_id = $2 AND kind = $3 AND flag = $4 AND cleared_at IS NULL
+		RETURNING` + annotationColumns + `;`
+
+	var a domain.OrderAnnotation
+	err := scanAnnotation(tx.QueryRow(ctx, query, orderID, tenant.FromContext(ctx), string(domain.AnnotationFlag), string(flag), adminID), &a)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAnnotationNotFound
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to clear flag", zap.Int64("order_id", orderID), zap.Error(err))
+
+		return nil, fmt.Errorf("failed to clear flag: %w", err)
+	}
+
+	return &a, nil
+}
+
+// ListAnnotations returns the notes and flags of the order, newest first.
+func (r *orderRepo) ListAnnotations(ctx context.Context, orderID int64) ([]domain.OrderAnnotation, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListAnnotations")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	query := `SELECT` + annotationColumns + `
+		FROM order_annotations
+		WHERE order_id = $1 AND tenant_id = $2
+		ORDER BY created_at DESC, id DESC;
+	`
+
+	rows, err := r.pool.Query(ctx, query, orderID, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to list annotations", zap.Int64("order_id", orderID), zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var annotations []domain.OrderAnnotation
+	for rows.Next() {
+		var a domain.OrderAnnotation
+		if err := scanAnnotation(rows, &a); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan annotation: %w", err)
+		}
+
+		annotations = append(annotations, a)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return annotations, nil
+}
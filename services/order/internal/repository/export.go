@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ExportOrders returns the next page of orders matching filter, oldest
+// first, after the (afterCreatedAt, afterID) position; pass a zero time to
+// start. Pages follow the orders index, so the cost of a page does not grow
+// with how far into the range it is.
+func (r *orderRepo) ExportOrders(ctx context.Context, filter domain.OrderExportFilter, afterCreatedAt time.Time, afterID int64, limit int) ([]domain.OrderExportRow, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ExportOrders")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("after_id", afterID),
+		attribute.Int("limit", limit),
+	)
+
+	query := `
+		SELECT o.id, o.user_id, o.status, o.total_sum, o.reserved_amount, o.payment_method_id,
+			o.created_at, o.updated_at,
+			i.number, i.currency, i.tax_rate_bps, i.net_total, i.tax_total, i.total, i.issued_at
+		FROM orders o
+		LEFT JOIN invoices i ON i.order_id = o.id
+		WHERE o.tenant_id = $1
+			AND o.created_at >= $2 AND o.created_at < $3
+			AND ($4 = '' OR o.status = $4)
+			AND (o.created_at, o.id) > ($5, $6)
+		ORDER BY o.created_at, o.id
+		LIMIT $7;
+	`
+
+	start := filter.From
+	if !afterCreatedAt.IsZero() {
+		start = afterCreatedAt
+	}
+
+	rows, err := r.pool.Query(ctx, query,
+		tenant.FromContext(ctx),
+		filter.From,
+		filter.To,
+		string(filter.Status),
+		start,
+		afterID,
+		limit,
+	)
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to query orders for export: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]domain.OrderExportRow, 0, limit)
+	for rows.Next() {
+		var row domain.OrderExportRow
+		if err := rows.Scan(
+			&row.OrderID,
+			&row.UserID,
+			&row.Status,
+			&row.TotalSum,
+			&row.ReservedAmount,
+			&row.PaymentMethodID,
+			&row.CreatedAt,
+			&row.UpdatedAt,
+			&row.InvoiceNumber,
+			&row.Currency,
+			&row.TaxRateBps,
+			&row.NetTotal,
+			&row.TaxTotal,
+			&row.InvoiceTotal,
+			&row.InvoicedAt,
+		); err != nil {
+			span.RecordError(err)
+
+			return nil, fmt.Errorf("failed to scan exported order: %w", err)
+		}
+
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	return result, nil
+}
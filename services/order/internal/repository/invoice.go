@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// NextInvoiceSequence hands out the tenant's next number of the year. The row
+// lock it takes is held until the transaction ends, which serialises
+// invoicing per tenant and year but keeps the sequence gap-free.
+func (r *orderRepo) NextInvoiceSequence(ctx context.Context, tx pgx.Tx, year int) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.NextInvoiceSequence")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("year", year))
+
+	query := `
+		INSERT INTO invoice_sequences (tenant_id, year, last_value)
+		VALUES ($2, $1, 1)
+		ON CONFLICT (tenant_id, year) DO UPDATE SET last_value = invoice_sequences.last_value + 1
+		RETURNING last_value;
+	`
+
+	var sequence int64
+	if err := tx.QueryRow(ctx, query, year, tenant.FromContext(ctx)).Scan(&sequence); err != nil {
+		span.RecordError(err)
+
+		return 0, fmt.Errorf("failed to allocate invoice number: %w", err)
+	}
+
+	return sequence, nil
+}
+
+func (r *orderRepo) CreateInvoice(ctx context.Context, tx pgx.Tx, invoice *domain.Invoice) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.CreateInvoice")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", invoice.OrderID),
+		attribute.String("number", invoice.Number),
+	)
+
+	lines, err := json.Marshal(invoice.Lines)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invoice lines: %w", err)
+	}
+
+	query := `
+		INSERT INTO invoices (order_id, user_id, number, lines, currency, tax_rate_bps, net_total, tax_total, total, object_key, issued_at, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id;
+	`
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		invoice.OrderID,
+		invoice.UserID,
+		invoice.Number,
+		lines,
+		invoice.Currency,
+		invoice.TaxRateBps,
+		invoice.NetTotal,
+		invoice.TaxTotal,
+		invoice.Total,
+		invoice.ObjectKey,
+		invoice.IssuedAt,
+		tenant.FromContext(ctx),
+	).Scan(&invoice.ID)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" && pgError.ConstraintName == "idx_invoices_order_id_key" {
+			return ErrInvoiceExists
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to insert invoice",
+			zap.Int64("order_id", invoice.OrderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to insert invoice: %w", err)
+	}
+
+	return nil
+}
+
+func (r *orderRepo) GetInvoiceByOrderID(ctx context.Context, orderID int64) (*domain.Invoice, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetInvoiceByOrderID")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	query := `
+		SELECT id, order_id, user_id, number, lines, currency, tax_rate_bps, net_total, tax_total, total, object_key, issued_at
+		FROM invoices
+		WHERE order_id = $1 AND tenant_id = $2;
+	`
+
+	var (
+		invoice domain.Invoice
+		lines   []byte
+	)
+
+	err := r.pool.QueryRow(ctx, query, orderID, tenant.FromContext(ctx)).Scan(
+		&invoice.ID,
+		&invoice.OrderID,
+		&invoice.UserID,
+		&invoice.Number,
+		&lines,
+		&invoice.Currency,
+		&invoice.TaxRateBps,
+		&invoice.NetTotal,
+		&invoice.TaxTotal,
+		&invoice.Total,
+		&invoice.ObjectKey,
+		&invoice.IssuedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvoiceNotFound
+		}
+
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to query invoice: %w", err)
+	}
+
+	if err := json.Unmarshal(lines, &invoice.Lines); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal invoice lines: %w", err)
+	}
+
+	return &invoice, nil
+}
@@ -2,15 +2,21 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository/sqlc"
 	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -22,10 +28,55 @@ type OrderRepository interface {
 	CreateOrder(ctx context.Context, tx pgx.Tx, order *domain.Order) error
 	ChangeOrderStatus(ctx context.Context, tx pgx.Tx, orderID int64, status string) error
 	GetAllItemsOfOrder(ctx context.Context, tx pgx.Tx, orderID int64) ([]outboxDomain.OrderItem, error)
+	UpdateUserRole(ctx context.Context, userID int64, role string) error
+	GetUserRole(ctx context.Context, userID int64) (string, error)
+	GetOrderByID(ctx context.Context, tx pgx.Tx, orderID int64) (*domain.Order, error)
+	UpdateReservation(ctx context.Context, tx pgx.Tx, orderID int64, from, to domain.OrderStatus, reservedAmount int64) error
+	ForceOrderStatus(ctx context.Context, tx pgx.Tx, orderID int64, from, to domain.OrderStatus) error
+	CreateStatusOverride(ctx context.Context, tx pgx.Tx, override *domain.StatusOverride) error
+	CreatePriceOverride(ctx context.Context, tx pgx.Tx, override *domain.PriceOverride) error
+	SetItemsStatus(ctx context.Context, tx pgx.Tx, orderID int64, productIDs []int64, status domain.OrderItemStatus) error
+	TransitionItemsStatus(ctx context.Context, tx pgx.Tx, orderID int64, from, to domain.OrderItemStatus) error
+	ResolvePartialReservation(ctx context.Context, tx pgx.Tx, order *domain.Order) error
+	AddTimelineEvent(ctx context.Context, tx pgx.Tx, event *domain.TimelineEvent) error
+	GetTimeline(ctx context.Context, orderID int64, customerOnly bool) ([]domain.TimelineEvent, error)
+	HasTimelineEvent(ctx context.Context, tx pgx.Tx, orderID int64, eventTypes ...string) (bool, error)
+	GetOrderOwner(ctx context.Context, orderID int64) (int64, error)
+	GetOrderByClientToken(ctx context.Context, userID int64, token uuid.UUID) (*domain.Order, error)
+	ListUserOrders(ctx context.Context, userID int64, after *domain.OrderCursor, limit int) ([]domain.Order, error)
+	ListStaleReservations(ctx context.Context, reservedBefore time.Time, limit int) ([]domain.StaleReservation, error)
+	NextInvoiceSequence(ctx context.Context, tx pgx.Tx, year int) (int64, error)
+	CreateInvoice(ctx context.Context, tx pgx.Tx, invoice *domain.Invoice) error
+	GetInvoiceByOrderID(ctx context.Context, orderID int64) (*domain.Invoice, error)
+	ExportOrders(ctx context.Context, filter domain.OrderExportFilter, afterCreatedAt time.Time, afterID int64, limit int) ([]domain.OrderExportRow, error)
+	CreateAddress(ctx context.Context, address *domain.Address) error
+	ListAddresses(ctx context.Context, userID int64) ([]domain.Address, error)
+	GetAddress(ctx context.Context, tx pgx.Tx, id, userID int64) (*domain.Address, error)
+	UpdateAddress(ctx context.Context, address *domain.Address) error
+	DeleteAddress(ctx context.Context, id, userID int64) error
+	LockUserOrders(ctx context.Context, tx pgx.Tx, userID int64) error
+	ListRecentOrderTimes(ctx context.Context, tx pgx.Tx, userID int64, since time.Time, limit int) ([]time.Time, error)
+	GetPurchaseCapUsage(ctx context.Context, tx pgx.Tx, userID int64, productIDs []int64) ([]domain.PurchaseCapUsage, error)
+	SetPurchaseCap(ctx context.Context, purchaseCap *domain.PurchaseCap) error
+	DeletePurchaseCap(ctx context.Context, productID int64) error
+	ListPurchaseCaps(ctx context.Context) ([]domain.PurchaseCap, error)
+	CreateReturn(ctx context.Context, tx pgx.Tx, ret *domain.Return) error
+	ReturnedQuantities(ctx context.Context, tx pgx.Tx, orderID int64) (map[int64]int32, error)
+	ClaimedRefundAmount(ctx context.Context, tx pgx.Tx, orderID int64) (int64, error)
+	GetReturn(ctx context.Context, tx pgx.Tx, returnID int64) (*domain.Return, error)
+	ListReturns(ctx context.Context, userID *int64, status domain.ReturnStatus, limit int) ([]domain.Return, error)
+	ReviewReturn(ctx context.Context, tx pgx.Tx, ret *domain.Return) error
+	MarkReturnRefunded(ctx context.Context, tx pgx.Tx, returnID, amount int64) (bool, error)
+	CreateAnnotation(ctx context.Context, tx pgx.Tx, a *domain.OrderAnnotation) error
+	GetActiveFlag(ctx context.Context, tx pgx.Tx, orderID int64, flag domain.OrderFlag) (*domain.OrderAnnotation, error)
+	ClearFlag(ctx context.Context, tx pgx.Tx, orderID int64, flag domain.OrderFlag, adminID int64) (*domain.OrderAnnotation, error)
+	ListAnnotations(ctx context.Context, orderID int64) ([]domain.OrderAnnotation, error)
 }
 
 type orderRepo struct {
-	pool   *pgxpool.Pool
+	pool *pgxpool.Pool
+	// q holds the sqlc-generated queries from queries/*.sql.
+	q      *sqlc.Queries
 	logger *zap.Logger
 	tracer trace.Tracer
 }
@@ -33,6 +84,7 @@ type orderRepo struct {
 func NewOrderRepository(pool *pgxpool.Pool, logger *zap.Logger) OrderRepository {
 	return &orderRepo{
 		pool:   pool,
+		q:      sqlc.New(pool),
 		logger: logger,
 		tracer: otel.Tracer("order_repository"),
 	}
@@ -117,10 +169,10 @@ func (r *orderRepo) ChangeOrderStatus(ctx context.Context, tx pgx.Tx, orderID in
 	query := `
 		UPDATE orders
 		SET status = $1
-		WHERE id = $2 AND status != 'paid';
+		WHERE id = $2 AND tenant_id = $3 AND status != 'paid';
 	`
 
-	commandTag, err := tx.Exec(ctx, query, status, orderID)
+	commandTag, err := tx.Exec(ctx, query, status, orderID, tenant.FromContext(ctx))
 	if err != nil {
 		span.RecordError(err)
 
@@ -139,10 +191,10 @@ func (r *orderRepo) ChangeOrderStatus(ctx context.Context, tx pgx.Tx, orderID in
 		checkQuery := `
 			SELECT status
 			FROM orders
-			WHERE id = $1
+			WHERE id = $1 AND tenant_id = $2
 		`
 
-		err = tx.QueryRow(ctx, checkQuery, orderID).Scan(&currentStatus)
+		err = tx.QueryRow(ctx, checkQuery, orderID, tenant.FromContext(ctx)).Scan(&currentStatus)
 		if err != nil {
 			if errors.Is(err, pgx.ErrNoRows) {
 				return ErrOrderNotFound
@@ -186,22 +238,42 @@ func (r *orderRepo) CreateOrder(ctx context.Context, tx pgx.Tx, order *domain.Or
 	)
 
 	queryOrder := `
-		INSERT INTO orders (user_id, status, total_sum, created_at, updated_at)
-		VALUES ($1, $2, $3, NOW(), NOW())
+		INSERT INTO orders (user_id, status, total_sum, delivery_fee, discount, placed_by, payment_method_id, shipping_address, client_token, tenant_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW(), NOW())
 		RETURNING id, created_at, updated_at
 	`
 
+	var shippingAddress []byte
+	if order.ShippingAddress != nil {
+		var err error
+		if shippingAddress, err = json.Marshal(order.ShippingAddress); err != nil {
+			return fmt.Errorf("failed to marshal shipping address: %w", err)
+		}
+	}
+
 	if err := tx.QueryRow(
 		ctx,
 		queryOrder,
 		order.UserID,
 		string(order.Status),
 		order.TotalSum,
+		order.DeliveryFee,
+		order.Discount,
+		order.PlacedBy,
+		order.PaymentMethodID,
+		shippingAddress,
+		order.ClientToken,
+		tenant.FromContext(ctx),
 	).Scan(
 		&order.ID,
 		&order.CreatedAt,
 		&order.UpdatedAt,
 	); err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" && pgError.ConstraintName == "idx_orders_client_token" {
+			return ErrDuplicateClientToken
+		}
+
 		span.RecordError(err)
 
 		mylogger.Warn(
@@ -215,8 +287,8 @@ func (r *orderRepo) CreateOrder(ctx context.Context, tx pgx.Tx, order *domain.Or
 	}
 
 	queryItem := `
-		INSERT INTO order_items (order_id, product_id, name, price, quantity)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO order_items (order_id, product_id, name, price, quantity, weight_grams)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
 	for _, item := range order.Items {
@@ -228,6 +300,7 @@ func (r *orderRepo) CreateOrder(ctx context.Context, tx pgx.Tx, order *domain.Or
 			item.Name,
 			item.Price,
 			item.Quantity,
+			item.WeightGrams,
 		)
 		if err != nil {
 			span.RecordError(err)
@@ -246,6 +319,45 @@ func (r *orderRepo) CreateOrder(ctx context.Context, tx pgx.Tx, order *domain.Or
 	return nil
 }
 
+// GetOrderByClientToken finds the order the user placed with token. Only
+// the fields CreateOrder answers with are read.
+func (r *orderRepo) GetOrderByClientToken(ctx context.Context, userID int64, token uuid.UUID) (*domain.Order, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetOrderByClientToken")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", userID),
+	)
+
+	query := `
+		SELECT id, status, total_sum, delivery_fee, discount, created_at, updated_at
+		FROM orders
+		WHERE tenant_id = $1 AND user_id = $2 AND client_token = $3
+	`
+
+	order := domain.Order{UserID: userID, ClientToken: &token}
+	err := r.pool.QueryRow(ctx, query, tenant.FromContext(ctx), userID, token).Scan(
+		&order.ID,
+		&order.Status,
+		&order.TotalSum,
+		&order.DeliveryFee,
+		&order.Discount,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to query order by client token: %w", err)
+	}
+
+	return &order, nil
+}
+
 func (r *orderRepo) SaveUserDuplication(ctx context.Context, event *domain.UserRegisteredEvent) error {
 	ctx, span := r.tracer.Start(ctx, "OrderRepository.SaveUserDuplication")
 	defer span.End()
@@ -256,11 +368,16 @@ func (r *orderRepo) SaveUserDuplication(ctx context.Context, event *domain.UserR
 	)
 
 	query := `
-		INSERT INTO users (id, email)
-		VALUES ($1, $2)
+		INSERT INTO users (id, email, role, tenant_id)
+		VALUES ($1, $2, $3, $4)
 	`
 
-	_, err := r.pool.Exec(ctx, query, event.UserID, event.Email)
+	role := event.Role
+	if role == "" {
+		role = domain.RoleUser
+	}
+
+	_, err := r.pool.Exec(ctx, query, event.UserID, event.Email, role, tenant.FromContext(ctx))
 	if err != nil {
 		var pgError *pgconn.PgError
 		if errors.As(err, &pgError) {
@@ -291,3 +408,415 @@ func (r *orderRepo) SaveUserDuplication(ctx context.Context, event *domain.UserR
 
 	return nil
 }
+
+func (r *orderRepo) UpdateUserRole(ctx context.Context, userID int64, role string) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.UpdateUserRole")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", userID),
+		attribute.String("role", role),
+	)
+
+	query := `
+		UPDATE users
+		SET role = $1
+		WHERE id = $2 AND tenant_id = $3;
+	`
+
+	ct, err := r.pool.Exec(ctx, query, role, userID, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Error updating user role",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+
+		return err
+	}
+
+	if ct.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
+func (r *orderRepo) GetUserRole(ctx context.Context, userID int64) (string, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetUserRole")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", userID),
+	)
+
+	query := `
+		SELECT role
+		FROM users
+		WHERE id = $1 AND tenant_id = $2;
+	`
+
+	var role string
+	if err := r.pool.QueryRow(ctx, query, userID, tenant.FromContext(ctx)).Scan(&role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrUserNotFound
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Error getting user role",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r *orderRepo) GetOrderByID(ctx context.Context, tx pgx.Tx, orderID int64) (*domain.Order, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetOrderByID")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+	)
+
+	q := r.q.WithTx(tx)
+
+	row, err := q.GetOrderForUpdate(ctx, sqlc.GetOrderForUpdateParams{ID: orderID, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to query order",
+			zap.Int64("order_id", orderID),
+			zap.Error(err),
+		)
+
+		return nil, fmt.Errorf("failed to query order: %w", err)
+	}
+
+	order := domain.Order{
+		ID:              row.ID,
+		UserID:          row.UserID,
+		Status:          domain.OrderStatus(row.Status),
+		TotalSum:        row.TotalSum,
+		DeliveryFee:     row.DeliveryFee,
+		Discount:        row.Discount,
+		ReservedAmount:  row.ReservedAmount,
+		PaymentMethodID: row.PaymentMethodID,
+		PlacedBy:        row.PlacedBy,
+		CreatedAt:       row.CreatedAt,
+		UpdatedAt:       row.UpdatedAt,
+	}
+	if row.FulfillmentChoice != nil {
+		order.FulfillmentChoice = *row.FulfillmentChoice
+	}
+
+	items, err := q.ListOrderItems(ctx, orderID)
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to query order items: %w", err)
+	}
+
+	for _, item := range items {
+		order.Items = append(order.Items, domain.OrderItem{
+			ID:          item.ID,
+			OrderID:     orderID,
+			ProductID:   derefInt64(item.ProductID),
+			Name:        item.Name,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+			WeightGrams: item.WeightGrams,
+			Status:      domain.OrderItemStatus(item.Status),
+		})
+	}
+
+	return &order, nil
+}
+
+func (r *orderRepo) UpdateReservation(ctx context.Context, tx pgx.Tx, orderID int64, from, to domain.OrderStatus, reservedAmount int64) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.UpdateReservation")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.String("from", string(from)),
+		attribute.String("to", string(to)),
+	)
+
+	query := `
+		UPDATE orders
+		SET status = $3, reserved_amount = $4, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $5 AND status = $2;
+	`
+
+	ct, err := tx.Exec(ctx, query, orderID, string(from), string(to), reservedAmount, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to update reservation",
+			zap.Int64("order_id", orderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to update reservation: %w", err)
+	}
+
+	if ct.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
+
+func (r *orderRepo) SetItemsStatus(ctx context.Context, tx pgx.Tx, orderID int64, productIDs []int64, status domain.OrderItemStatus) error {
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.SetItemsStatus")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.Int("items_count", len(productIDs)),
+		attribute.String("status", string(status)),
+	)
+
+	query := `
+		UPDATE order_items
+		SET status = $3
+		WHERE order_id = $1 AND product_id = ANY($2);
+	`
+
+	if _, err := tx.Exec(ctx, query, orderID, productIDs, string(status)); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to update items status",
+			zap.Int64("order_id", orderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to update items status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *orderRepo) TransitionItemsStatus(ctx context.Context, tx pgx.Tx, orderID int64, from, to domain.OrderItemStatus) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.TransitionItemsStatus")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.String("from", string(from)),
+		attribute.String("to", string(to)),
+	)
+
+	query := `
+		UPDATE order_items
+		SET status = $3
+		WHERE order_id = $1 AND status = $2;
+	`
+
+	if _, err := tx.Exec(ctx, query, orderID, string(from), string(to)); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to transition items status",
+			zap.Int64("order_id", orderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to transition items status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *orderRepo) ResolvePartialReservation(ctx context.Context, tx pgx.Tx, order *domain.Order) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ResolvePartialReservation")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", order.ID),
+		attribute.String("choice", order.FulfillmentChoice),
+	)
+
+	query := `
+		UPDATE orders
+		SET status = $2, total_sum = $3, fulfillment_choice = $4, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $5
+		RETURNING updated_at;
+	`
+
+	if err := tx.QueryRow(
+		ctx,
+		query,
+		order.ID,
+		string(order.Status),
+		order.TotalSum,
+		order.FulfillmentChoice,
+		tenant.FromContext(ctx),
+	).Scan(&order.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrOrderNotFound
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to resolve partial reservation",
+			zap.Int64("order_id", order.ID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to resolve partial reservation: %w", err)
+	}
+
+	return nil
+}
+
+func (r *orderRepo) ListUserOrders(ctx context.Context, userID int64, after *domain.OrderCursor, limit int) ([]domain.Order, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListUserOrders")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", userID),
+		attribute.Int("limit", limit),
+	)
+
+	where := query.And(query.Eq("tenant_id", tenant.FromContext(ctx)), query.Eq("user_id", userID))
+	if after != nil {
+		where = query.And(where, query.After("created_at", "id", after.CreatedAt, after.ID))
+	}
+
+	var b query.Builder
+	sql := `
+		SELECT id, user_id, status, total_sum, delivery_fee, created_at
+		FROM orders` + b.Where(where) + `
+		ORDER BY created_at DESC, id DESC` + b.Page(query.Page{Limit: int64(limit)})
+
+	rows, err := r.pool.Query(ctx, sql, b.Args()...)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to query user orders",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+
+		return nil, fmt.Errorf("failed to query user orders: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]domain.Order, 0, limit)
+	for rows.Next() {
+		var order domain.Order
+		if err := rows.Scan(
+			&order.ID,
+			&order.UserID,
+			&order.Status,
+			&order.TotalSum,
+			&order.DeliveryFee,
+			&order.CreatedAt,
+		); err != nil {
+			span.RecordError(err)
+
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+
+		result = append(result, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListStaleReservations returns the orders that have been waiting for payment
+// since before reservedBefore, oldest first. The watchdog serves every
+// tenant, so unlike the rest of the repository it is not scoped by ctx.
+func (r *orderRepo) ListStaleReservations(ctx context.Context, reservedBefore time.Time, limit int) ([]domain.StaleReservation, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListStaleReservations")
+	defer span.End()
+
+	query := `
+		SELECT id, tenant_id
+		FROM orders
+		WHERE status = $1 AND updated_at < $2
+		ORDER BY updated_at
+		LIMIT $3;
+	`
+
+	rows, err := r.pool.Query(ctx, query, string(domain.OrderStatusReserved), reservedBefore, limit)
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to query stale reservations: %w", err)
+	}
+
+	defer rows.Close()
+
+	var stale []domain.StaleReservation
+	for rows.Next() {
+		var reservation domain.StaleReservation
+		if err := rows.Scan(&reservation.OrderID, &reservation.TenantID); err != nil {
+			span.RecordError(err)
+
+			return nil, fmt.Errorf("failed to scan stale reservation: %w", err)
+		}
+
+		stale = append(stale, reservation)
+	}
+
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	return stale, nil
+}
+
+func derefInt64(v *int64) int64 {
+	if v == nil {
+		return 0
+	}
+
+	return *v
+}
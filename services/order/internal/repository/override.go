@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// ForceOrderStatus moves the order from one status to another, failing with
+// ErrStatusConflict when it is no longer in from. Unlike ChangeOrderStatus
+// it does not refuse paid orders: the caller decides what may be forced.
+func (r *orderRepo) ForceOrderStatus(ctx context.Context, tx pgx.Tx, orderID int64, from, to domain.OrderStatus) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ForceOrderStatus")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.String("from", string(from)),
+		attribute.String("to", string(to)),
+	)
+
+	query := `
+		UPDATE orders
+		SET status = $3, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $4 AND status = $2;
+	`
+
+	ct, err := tx.Exec(ctx, query, orderID, string(from), string(to), tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to force order status",
+			zap.Int64("order_id", orderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to force order status: %w", err)
+	}
+
+	if ct.RowsAffected() == 0 {
+		return ErrStatusConflict
+	}
+
+	return nil
+}
+
+func (r *orderRepo) CreateStatusOverride(ctx context.Context, tx pgx.Tx, override *domain.StatusOverride) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.CreateStatusOverride")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", override.OrderID),
+		attribute.Int64("admin_id", override.AdminID),
+	)
+
+	query := `
+		INSERT INTO order_status_overrides (order_id, admin_id, from_status, to_status, reason, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at;
+	`
+
+	if err := tx.QueryRow(
+		ctx,
+		query,
+		override.OrderID,
+		override.AdminID,
+		string(override.FromStatus),
+		string(override.ToStatus),
+		override.Reason,
+		tenant.FromContext(ctx),
+	).Scan(&override.ID, &override.CreatedAt); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to insert status override",
+			zap.Int64("order_id", override.OrderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to insert status override: %w", err)
+	}
+
+	return nil
+}
+
+func (r *orderRepo) CreatePriceOverride(ctx context.Context, tx pgx.Tx, override *domain.PriceOverride) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.CreatePriceOverride")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", override.OrderID),
+		attribute.Int64("admin_id", override.AdminID),
+	)
+
+	query := `
+		INSERT INTO order_price_overrides (order_id, admin_id, product_id, original_amount, amount, reason_code, note, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at;
+	`
+
+	if err := tx.QueryRow(
+		ctx,
+		query,
+		override.OrderID,
+		override.AdminID,
+		override.ProductID,
+		override.OriginalAmount,
+		override.Amount,
+		override.ReasonCode,
+		override.Note,
+		tenant.FromContext(ctx),
+	).Scan(&override.ID, &override.CreatedAt); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to insert price override",
+			zap.Int64("order_id", override.OrderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to insert price override: %w", err)
+	}
+
+	return nil
+}
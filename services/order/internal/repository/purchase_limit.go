@@ -0,0 +1,240 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// LockUserOrders serializes the orders of a user until tx ends, so two
+// concurrent orders cannot both pass the rate limit or a purchase cap.
+func (r *orderRepo) LockUserOrders(ctx context.Context, tx pgx.Tx, userID int64) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.LockUserOrders")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `SELECT pg_advisory_xact_lock(hashtextextended('user_orders:' || $1 || ':' || $2::text, 0));`
+
+	if _, err := tx.Exec(ctx, query, tenant.FromContext(ctx), userID); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to lock user orders", zap.Int64("user_id", userID), zap.Error(err))
+
+		return fmt.Errorf("failed to lock user orders: %w", err)
+	}
+
+	return nil
+}
+
+// ListRecentOrderTimes returns when the user placed their orders since
+// since, newest first and at most limit of them. Orders support placed on
+// their behalf are not counted.
+func (r *orderRepo) ListRecentOrderTimes(ctx context.Context, tx pgx.Tx, userID int64, since time.Time, limit int) ([]time.Time, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListRecentOrderTimes")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		SELECT created_at
+		FROM orders
+		WHERE tenant_id = $1 AND user_id = $2 AND placed_by IS NULL AND created_at > $3
+		ORDER BY created_at DESC
+		LIMIT $4;
+	`
+
+	rows, err := tx.Query(ctx, query, tenant.FromContext(ctx), userID, since, limit)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to list recent orders", zap.Int64("user_id", userID), zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list recent orders: %w", err)
+	}
+
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan recent order: %w", err)
+		}
+
+		times = append(times, t)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return times, nil
+}
+
+// GetPurchaseCapUsage returns the caps of the products among productIDs
+// that have one, with how many units the user bought of each. Cancelled
+// orders and items that were never reserved or were removed do not count.
+func (r *orderRepo) GetPurchaseCapUsage(ctx context.Context, tx pgx.Tx, userID int64, productIDs []int64) ([]domain.PurchaseCapUsage, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetPurchaseCapUsage")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		SELECT c.product_id, c.max_per_user, COALESCE((
+			SELECT SUM(oi.quantity)
+			FROM order_items oi
+			JOIN orders o ON o.id = oi.order_id
+			WHERE oi.product_id = c.product_id
+				AND o.tenant_id = c.tenant_id
+				AND o.user_id = $2
+				AND o.status <> $4
+				AND oi.status NOT IN ($5, $6)
+		), 0)::INT
+		FROM purchase_caps c
+		WHERE c.tenant_id = $1 AND c.product_id = ANY($3)
+		ORDER BY c.product_id;
+	`
+
+	rows, err := tx.Query(
+		ctx,
+		query,
+		tenant.FromContext(ctx),
+		userID,
+		productIDs,
+		string(domain.OrderStatusCancelled),
+		string(domain.OrderItemStatusUnavailable),
+		string(domain.OrderItemStatusRemoved),
+	)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to get purchase cap usage", zap.Int64("user_id", userID), zap.Error(err))
+
+		return nil, fmt.Errorf("failed to get purchase cap usage: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.PurchaseCapUsage
+	for rows.Next() {
+		var u domain.PurchaseCapUsage
+		if err := rows.Scan(&u.ProductID, &u.MaxPerUser, &u.Purchased); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan purchase cap usage: %w", err)
+		}
+
+		result = append(result, u)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+// SetPurchaseCap creates or replaces the cap of the product.
+func (r *orderRepo) SetPurchaseCap(ctx context.Context, purchaseCap *domain.PurchaseCap) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.SetPurchaseCap")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("product_id", purchaseCap.ProductID),
+		attribute.Int64("admin_id", purchaseCap.SetBy),
+	)
+
+	query := `
+		INSERT INTO purchase_caps (tenant_id, product_id, max_per_user, set_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, product_id) DO UPDATE
+		SET max_per_user = EXCLUDED.max_per_user, set_by = EXCLUDED.set_by, updated_at = NOW()
+		RETURNING updated_at;
+	`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		tenant.FromContext(ctx),
+		purchaseCap.ProductID,
+		purchaseCap.MaxPerUser,
+		purchaseCap.SetBy,
+	).Scan(&purchaseCap.UpdatedAt)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to set purchase cap", zap.Int64("product_id", purchaseCap.ProductID), zap.Error(err))
+
+		return fmt.Errorf("failed to set purchase cap: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePurchaseCap lifts the cap of the product. A product without one is
+// left as it is.
+func (r *orderRepo) DeletePurchaseCap(ctx context.Context, productID int64) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.DeletePurchaseCap")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("product_id", productID))
+
+	query := `DELETE FROM purchase_caps WHERE tenant_id = $1 AND product_id = $2;`
+
+	if _, err := r.pool.Exec(ctx, query, tenant.FromContext(ctx), productID); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to delete purchase cap", zap.Int64("product_id", productID), zap.Error(err))
+
+		return fmt.Errorf("failed to delete purchase cap: %w", err)
+	}
+
+	return nil
+}
+
+func (r *orderRepo) ListPurchaseCaps(ctx context.Context) ([]domain.PurchaseCap, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListPurchaseCaps")
+	defer span.End()
+
+	query := `
+		SELECT product_id, max_per_user, set_by, updated_at
+		FROM purchase_caps
+		WHERE tenant_id = $1
+		ORDER BY product_id;
+	`
+
+	rows, err := r.pool.Query(ctx, query, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to list purchase caps", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list purchase caps: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.PurchaseCap
+	for rows.Next() {
+		var c domain.PurchaseCap
+		if err := rows.Scan(&c.ProductID, &c.MaxPerUser, &c.SetBy, &c.UpdatedAt); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan purchase cap: %w", err)
+		}
+
+		result = append(result, c)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,16 @@
+-- name: GetOrderForUpdate :one
+SELECT id, user_id, status, total_sum, delivery_fee, discount, reserved_amount, fulfillment_choice, payment_method_id, placed_by, created_at, updated_at
+FROM orders
+WHERE id = $1 AND tenant_id = $2
+FOR UPDATE;
+
+-- name: ListOrderItems :many
+SELECT id, order_id, product_id, name, price, quantity, weight_grams, status
+FROM order_items
+WHERE order_id = @order_id::bigint
+ORDER BY id;
+
+-- name: GetOrderOwner :one
+SELECT user_id
+FROM orders
+WHERE id = $1 AND tenant_id = $2;
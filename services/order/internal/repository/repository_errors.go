@@ -5,4 +5,16 @@ import "errors"
 var (
 	ErrOrderNotFound    = errors.New("order not found")
 	ErrOrderAlreadyPaid = errors.New("order already paid")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrStatusConflict   = errors.New("order is not in the expected status")
+	ErrInvoiceNotFound  = errors.New("invoice not found")
+	ErrInvoiceExists    = errors.New("invoice already exists for this order")
+	ErrAddressNotFound  = errors.New("address not found")
+	ErrAddressBookFull  = errors.New("address book is full")
+	ErrReturnNotFound   = errors.New("return not found")
+	// ErrAnnotationNotFound means the flag to clear is not set on the order.
+	ErrAnnotationNotFound = errors.New("annotation not found")
+	// ErrDuplicateClientToken means the user already placed an order with
+	// the token.
+	ErrDuplicateClientToken = errors.New("order with this client token already exists")
 )
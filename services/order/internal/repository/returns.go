@@ -0,0 +1,370 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const returnColumns = `
+	id, order_id, user_id, status, reason, refund_amount, refunded_amount,
+	reviewed_by, review_note, reviewed_at, refunded_at, created_at
+`
+
+func scanReturn(row pgx.Row, ret *domain.Return) error {
+	return row.Scan(
+		&ret.ID,
+		&ret.OrderID,
+		&ret.UserID,
+		&ret.Status,
+		&ret.Reason,
+		&ret.RefundAmount,
+		&ret.RefundedAmount,
+		&ret.ReviewedBy,
+		&ret.ReviewNote,
+		&ret.ReviewedAt,
+		&ret.RefundedAt,
+		&ret.CreatedAt,
+	)
+}
+
+// CreateReturn stores a requested return with its items.
+func (r *orderRepo) CreateReturn(ctx context.Context, tx pgx.Tx, ret *domain.Return) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.CreateReturn")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", ret.OrderID),
+		attribute.Int64("user_id", ret.UserID),
+	)
+
+	query := `
+		INSERT INTO order_returns (order_id, user_id, status, reason, refund_amount, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at;
+	`
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		ret.OrderID,
+		ret.UserID,
+		string(ret.Status),
+		ret.Reason,
+		ret.RefundAmount,
+		tenant.FromContext(ctx),
+	).Scan(&ret.ID, &ret.CreatedAt)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to create return", zap.Int64("order_id", ret.OrderID), zap.Error(err))
+
+		return fmt.Errorf("failed to create return: %w", err)
+	}
+
+	itemQuery := `
+		INSERT INTO order_return_items (return_id, order_item_id, product_id, name, price, quantity)
+		VALUES ($1, $2, $3, $4, $5, $6);
+	`
+
+	for _, item := range ret.Items {
+		_, err := tx.Exec(ctx, itemQuery, ret.ID, item.OrderItemID, item.ProductID, item.Name, item.Price, item.Quantity)
+		if err != nil {
+			span.RecordError(err)
+
+			mylogger.Error(ctx, r.logger, "Failed to create return item", zap.Int64("return_id", ret.ID), zap.Error(err))
+
+			return fmt.Errorf("failed to create return item: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReturnedQuantities returns how many units of each order line are taken by
+// returns of the order that were not rejected, keyed by order item id.
+func (r *orderRepo) ReturnedQuantities(ctx context.Context, tx pgx.Tx, orderID int64) (map[int64]int32, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ReturnedQuantities")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	query := `
+		SELECT ri.order_item_id, SUM(ri.quantity)::INT
+		FROM order_return_items ri
+		JOIN order_returns rt ON rt.id = ri.return_id
+		WHERE rt.order_id = $1 AND rt.tenant_id = $2 AND rt.status <> $3
+		GROUP BY ri.order_item_id;
+	`
+
+	rows, err := tx.Query(ctx, query, orderID, tenant.FromContext(ctx), string(domain.ReturnStatusRejected))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to query returned quantities", zap.Int64("order_id", orderID), zap.Error(err))
+
+		return nil, fmt.Errorf("failed to query returned quantities: %w", err)
+	}
+	defer rows.Close()
+
+	returned := make(map[int64]int32)
+	for rows.Next() {
+		var (
+			itemID   int64
+			quantity int32
+		)
+		if err := rows.Scan(&itemID, &quantity); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan returned quantity: %w", err)
+		}
+
+		returned[itemID] = quantity
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return returned, nil
+}
+
+// ClaimedRefundAmount sums what returns of the order that were not rejected
+// ask to be refunded.
+func (r *orderRepo) ClaimedRefundAmount(ctx context.Context, tx pgx.Tx, orderID int64) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ClaimedRefundAmount")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	query := `
+		SELECT COALESCE(SUM(refund_amount), 0)::BIGINT
+		FROM order_returns
+		WHERE order_id = $1 AND tenant_id = $2 AND status <> $3;
+	`
+
+	var amount int64
+	err := tx.QueryRow(ctx, query, orderID, tenant.FromContext(ctx), string(domain.ReturnStatusRejected)).Scan(&amount)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to sum return refunds: %w", err)
+	}
+
+	return amount, nil
+}
+
+// GetReturn returns the return with its items, locked until tx ends.
+func (r *orderRepo) GetReturn(ctx context.Context, tx pgx.Tx, returnID int64) (*domain.Return, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetReturn")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("return_id", returnID))
+
+	query := `SELECT` + returnColumns + `FROM order_returns WHERE id = $1 AND tenant_id = $2 FOR UPDATE;`
+
+	var ret domain.Return
+	if err := scanReturn(tx.QueryRow(ctx, query, returnID, tenant.FromContext(ctx)), &ret); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReturnNotFound
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to query return", zap.Int64("return_id", returnID), zap.Error(err))
+
+		return nil, fmt.Errorf("failed to query return: %w", err)
+	}
+
+	returns := []domain.Return{ret}
+	if err := r.loadReturnItems(ctx, tx, returns); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &returns[0], nil
+}
+
+// ListReturns returns up to limit returns, newest first. A nil userID lists
+// the returns of every user; an empty status lists every status.
+func (r *orderRepo) ListReturns(ctx context.Context, userID *int64, status domain.ReturnStatus, limit int) ([]domain.Return, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ListReturns")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("status", string(status)))
+
+	query := `SELECT` + returnColumns + `
+		FROM order_returns
+		WHERE tenant_id = $1
+			AND ($2::BIGINT IS NULL OR user_id = $2)
+			AND ($3 = '' OR status = $3)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $4;
+	`
+
+	rows, err := r.pool.Query(ctx, query, tenant.FromContext(ctx), userID, string(status), limit)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to list returns", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list returns: %w", err)
+	}
+	defer rows.Close()
+
+	var returns []domain.Return
+	for rows.Next() {
+		var ret domain.Return
+		if err := scanReturn(rows, &ret); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan return: %w", err)
+		}
+
+		returns = append(returns, ret)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	if err := r.loadReturnItems(ctx, r.pool, returns); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return returns, nil
+}
+
+type returnItemQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// loadReturnItems fills in the items of returns with one query.
+func (r *orderRepo) loadReturnItems(ctx context.Context, q returnItemQuerier, returns []domain.Return) error {
+	if len(returns) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*domain.Return, len(returns))
+	ids := make([]int64, 0, len(returns))
+	for i := range returns {
+		byID[returns[i].ID] = &returns[i]
+		ids = append(ids, returns[i].ID)
+	}
+
+	query := `
+		SELECT return_id, order_item_id, product_id, name, price, quantity
+		FROM order_return_items
+		WHERE return_id = ANY($1)
+		ORDER BY return_id, order_item_id;
+	`
+
+	rows, err := q.Query(ctx, query, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query return items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			returnID int64
+			item     domain.ReturnItem
+		)
+		if err := rows.Scan(&returnID, &item.OrderItemID, &item.ProductID, &item.Name, &item.Price, &item.Quantity); err != nil {
+			return fmt.Errorf("failed to scan return item: %w", err)
+		}
+
+		byID[returnID].Items = append(byID[returnID].Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return nil
+}
+
+// ReviewReturn stores the decision on a requested return. It fails with
+// ErrStatusConflict if the return was reviewed in the meantime.
+func (r *orderRepo) ReviewReturn(ctx context.Context, tx pgx.Tx, ret *domain.Return) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.ReviewReturn")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("return_id", ret.ID),
+		attribute.String("status", string(ret.Status)),
+	)
+
+	query := `
+		UPDATE order_returns
+		SET status = $3, reviewed_by = $4, review_note = $5, reviewed_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = $6
+		RETURNING reviewed_at;
+	`
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		ret.ID,
+		tenant.FromContext(ctx),
+		string(ret.Status),
+		ret.ReviewedBy,
+		ret.ReviewNote,
+		string(domain.ReturnStatusRequested),
+	).Scan(&ret.ReviewedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrStatusConflict
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to review return", zap.Int64("return_id", ret.ID), zap.Error(err))
+
+		return fmt.Errorf("failed to review return: %w", err)
+	}
+
+	return nil
+}
+
+// MarkReturnRefunded records what payment refunded for an approved return.
+// It reports false if the return is not waiting for a refund, e.g. because
+// the refund was delivered before.
+func (r *orderRepo) MarkReturnRefunded(ctx context.Context, tx pgx.Tx, returnID, amount int64) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.MarkReturnRefunded")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("return_id", returnID),
+		attribute.Int64("amount", amount),
+	)
+
+	query := `
+		UPDATE order_returns
+		SET status = $3, refunded_amount = $4, refunded_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = $5;
+	`
+
+	tag, err := tx.Exec(
+		ctx,
+		query,
+		returnID,
+		tenant.FromContext(ctx),
+		string(domain.ReturnStatusRefunded),
+		amount,
+		string(domain.ReturnStatusApproved),
+	)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to mark return refunded", zap.Int64("return_id", returnID), zap.Error(err))
+
+		return false, fmt.Errorf("failed to mark return refunded: %w", err)
+	}
+
+	return tag.RowsAffected() > 0, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository/sqlc"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+func (r *orderRepo) AddTimelineEvent(ctx context.Context, tx pgx.Tx, event *domain.TimelineEvent) error {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.AddTimelineEvent")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", event.OrderID),
+		attribute.String("event_type", event.EventType),
+	)
+
+	query := `
+		INSERT INTO order_events (order_id, event_type, status, message, visible_to_customer)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at;
+	`
+
+	if err := tx.QueryRow(
+		ctx,
+		query,
+		event.OrderID,
+		event.EventType,
+		string(event.Status),
+		event.Message,
+		event.VisibleToCustomer,
+	).Scan(&event.ID, &event.CreatedAt); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to insert timeline event",
+			zap.Int64("order_id", event.OrderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to insert timeline event: %w", err)
+	}
+
+	return nil
+}
+
+// HasTimelineEvent reports whether the order's timeline has an entry of any
+// of eventTypes.
+func (r *orderRepo) HasTimelineEvent(ctx context.Context, tx pgx.Tx, orderID int64, eventTypes ...string) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.HasTimelineEvent")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.StringSlice("event_types", eventTypes),
+	)
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM order_events
+			WHERE order_id = $1 AND event_type = ANY($2)
+		);
+	`
+
+	var found bool
+	if err := tx.QueryRow(ctx, query, orderID, eventTypes).Scan(&found); err != nil {
+		span.RecordError(err)
+
+		return false, fmt.Errorf("failed to query timeline: %w", err)
+	}
+
+	return found, nil
+}
+
+func (r *orderRepo) GetTimeline(ctx context.Context, orderID int64, customerOnly bool) ([]domain.TimelineEvent, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetTimeline")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.Bool("customer_only", customerOnly),
+	)
+
+	query := `
+		SELECT e.id, e.order_id, e.event_type, e.status, e.message, e.visible_to_customer, e.created_at
+		FROM order_events e
+		JOIN orders o ON o.id = e.order_id
+		WHERE e.order_id = $1 AND o.tenant_id = $3 AND (e.visible_to_customer OR NOT $2)
+		ORDER BY e.created_at, e.id;
+	`
+
+	rows, err := r.pool.Query(ctx, query, orderID, customerOnly, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to query timeline",
+			zap.Int64("order_id", orderID),
+			zap.Error(err),
+		)
+
+		return nil, fmt.Errorf("failed to query timeline: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]domain.TimelineEvent, 0)
+	for rows.Next() {
+		var event domain.TimelineEvent
+		if err := rows.Scan(
+			&event.ID,
+			&event.OrderID,
+			&event.EventType,
+			&event.Status,
+			&event.Message,
+			&event.VisibleToCustomer,
+			&event.CreatedAt,
+		); err != nil {
+			span.RecordError(err)
+
+			return nil, fmt.Errorf("failed to scan timeline event: %w", err)
+		}
+
+		result = append(result, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (r *orderRepo) GetOrderOwner(ctx context.Context, orderID int64) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "OrderRepository.GetOrderOwner")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+	)
+
+	userID, err := r.q.GetOrderOwner(ctx, sqlc.GetOrderOwnerParams{ID: orderID, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrOrderNotFound
+		}
+
+		span.RecordError(err)
+
+		return 0, fmt.Errorf("failed to query order owner: %w", err)
+	}
+
+	return userID, nil
+}
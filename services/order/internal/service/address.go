@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+func (s *orderService) AddAddress(ctx context.Context, req *pb.AddAddressRequest) (*pb.Address, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.AddAddress")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", req.UserId))
+
+	address := domain.AddressFromPB(req.Address)
+	address.ID = 0
+	address.UserID = req.UserId
+	address.Normalize()
+	if err := address.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.orderRepo.CreateAddress(ctx, &address); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	mylogger.Info(ctx, s.logger, "Address added", zap.Int64("address_id", address.ID), zap.Int64("user_id", address.UserID))
+
+	return address.ToPB(), nil
+}
+
+func (s *orderService) ListAddresses(ctx context.Context, req *pb.ListAddressesRequest) (*pb.ListAddressesResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ListAddresses")
+	defer span.End()
+
+	addresses, err := s.orderRepo.ListAddresses(ctx, req.UserId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	res := &pb.ListAddressesResponse{Addresses: make([]*pb.Address, 0, len(addresses))}
+	for i := range addresses {
+		res.Addresses = append(res.Addresses, addresses[i].ToPB())
+	}
+
+	return res, nil
+}
+
+func (s *orderService) UpdateAddress(ctx context.Context, req *pb.UpdateAddressRequest) (*pb.Address, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.UpdateAddress")
+	defer span.End()
+
+	address := domain.AddressFromPB(req.Address)
+	address.UserID = req.UserId
+	address.Normalize()
+	if err := address.Validate(); err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int64("address_id", address.ID))
+
+	if err := s.orderRepo.UpdateAddress(ctx, &address); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return address.ToPB(), nil
+}
+
+func (s *orderService) DeleteAddress(ctx context.Context, req *pb.DeleteAddressRequest) (*pb.DeleteAddressResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.DeleteAddress")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("address_id", req.Id))
+
+	if err := s.orderRepo.DeleteAddress(ctx, req.Id, req.UserId); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &pb.DeleteAddressResponse{Success: true}, nil
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// AddOrderNote puts a staff-only note on an order. The note is kept in the
+// annotations; the internal timeline records that it was written.
+func (s *orderService) AddOrderNote(ctx context.Context, req *pb.AddOrderNoteRequest) (*pb.OrderAnnotation, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.AddOrderNote")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.Int64("admin_id", req.AdminId),
+	)
+
+	body, err := domain.NoteBody(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	note := &domain.OrderAnnotation{
+		OrderID:  req.OrderId,
+		Kind:     domain.AnnotationNote,
+		Body:     body,
+		AuthorID: req.AdminId,
+	}
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, req.OrderId)
+		if err != nil {
+			return err
+		}
+
+		if err := s.orderRepo.CreateAnnotation(ctx, tx, note); err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineNoteAdded, order.Status,
+			fmt.Sprintf("Admin #%d added a support note", req.AdminId), false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mylogger.Info(ctx, s.logger, "Order note added", zap.Int64("order_id", req.OrderId), zap.Int64("admin_id", req.AdminId))
+
+	return note.ToPB(), nil
+}
+
+// SetOrderFlag flags an order. Setting a flag the order already carries
+// returns it unchanged.
+func (s *orderService) SetOrderFlag(ctx context.Context, req *pb.SetOrderFlagRequest) (*pb.OrderAnnotation, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.SetOrderFlag")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.Int64("admin_id", req.AdminId),
+		attribute.String("flag", req.Flag),
+	)
+
+	flag, err := domain.ParseOrderFlag(req.Flag)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(comment) > domain.MaxFlagCommentLength {
+		return nil, fmt.Errorf("%w: comment is longer than %d characters", domain.ErrInvalidAnnotation, domain.MaxFlagCommentLength)
+	}
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	var annotation *domain.OrderAnnotation
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		// The order stays locked until the flag is written, so two admins
+		// setting the same flag cannot both find it missing.
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, req.OrderId)
+		if err != nil {
+			return err
+		}
+
+		annotation, err = s.orderRepo.GetActiveFlag(ctx, tx, order.ID, flag)
+		if err != nil || annotation != nil {
+			return err
+		}
+
+		annotation = &domain.OrderAnnotation{
+			OrderID:  order.ID,
+			Kind:     domain.AnnotationFlag,
+			Flag:     &flag,
+			Body:     comment,
+			AuthorID: req.AdminId,
+		}
+		if err := s.orderRepo.CreateAnnotation(ctx, tx, annotation); err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineFlagSet, order.Status,
+			fmt.Sprintf("Admin #%d flagged the order %s", req.AdminId, flag), false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Order flag set",
+		zap.Int64("order_id", req.OrderId),
+		zap.String("flag", string(flag)),
+		zap.Int64("admin_id", req.AdminId),
+	)
+
+	return annotation.ToPB(), nil
+}
+
+// ClearOrderFlag takes a flag off an order. The flag stays in the
+// annotations with who cleared it and when.
+func (s *orderService) ClearOrderFlag(ctx context.Context, req *pb.ClearOrderFlagRequest) (*pb.OrderAnnotation, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ClearOrderFlag")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.Int64("admin_id", req.AdminId),
+		attribute.String("flag", req.Flag),
+	)
+
+	flag, err := domain.ParseOrderFlag(req.Flag)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	var annotation *domain.OrderAnnotation
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, req.OrderId)
+		if err != nil {
+			return err
+		}
+
+		annotation, err = s.orderRepo.ClearFlag(ctx, tx, order.ID, flag, req.AdminId)
+		if err != nil {
+			return err
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineFlagCleared, order.Status,
+			fmt.Sprintf("Admin #%d cleared the %s flag", req.AdminId, flag), false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Order flag cleared",
+		zap.Int64("order_id", req.OrderId),
+		zap.String("flag", string(flag)),
+		zap.Int64("admin_id", req.AdminId),
+	)
+
+	return annotation.ToPB(), nil
+}
+
+func (s *orderService) ListOrderAnnotations(ctx context.Context, req *pb.ListOrderAnnotationsRequest) (*pb.ListOrderAnnotationsResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ListOrderAnnotations")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.Int64("admin_id", req.AdminId),
+	)
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	// An order without annotations lists nothing; one that does not exist
+	// is not found.
+	if _, err := s.orderRepo.GetOrderOwner(ctx, req.OrderId); err != nil {
+		return nil, err
+	}
+
+	annotations, err := s.orderRepo.ListAnnotations(ctx, req.OrderId)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	res := &pb.ListOrderAnnotationsResponse{
+		Annotations: make([]*pb.OrderAnnotation, 0, len(annotations)),
+		ActiveFlags: domain.ActiveFlags(annotations),
+	}
+	for i := range annotations {
+		res.Annotations = append(res.Annotations, annotations[i].ToPB())
+	}
+
+	return res, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.uber.org/zap"
+)
+
+const (
+	exportDateLayout   = "2006-01-02"
+	maxExportRangeDays = 366
+	// exportPageSize orders are read, formatted and sent at a time, so
+	// memory stays flat however long the range is.
+	exportPageSize = 500
+)
+
+// ParseExportFilter turns inclusive YYYY-MM-DD bounds into a half-open UTC
+// range. Both bounds are required: an export has to say which period it
+// covers.
+func ParseExportFilter(from, to, status string) (domain.OrderExportFilter, error) {
+	start, err := time.Parse(exportDateLayout, from)
+	if err != nil {
+		return domain.OrderExportFilter{}, fmt.Errorf("%w: bad 'from' date", ErrInvalidExportFilter)
+	}
+
+	end, err := time.Parse(exportDateLayout, to)
+	if err != nil {
+		return domain.OrderExportFilter{}, fmt.Errorf("%w: bad 'to' date", ErrInvalidExportFilter)
+	}
+
+	if start.After(end) {
+		return domain.OrderExportFilter{}, fmt.Errorf("%w: 'from' is after 'to'", ErrInvalidExportFilter)
+	}
+
+	filter := domain.OrderExportFilter{From: start, To: end.AddDate(0, 0, 1), Status: domain.OrderStatus(status)}
+	if filter.To.Sub(filter.From) > maxExportRangeDays*24*time.Hour {
+		return domain.OrderExportFilter{}, fmt.Errorf("%w: range exceeds %d days", ErrInvalidExportFilter, maxExportRangeDays)
+	}
+
+	if status != "" && !domain.IsKnownOrderStatus(filter.Status) {
+		return domain.OrderExportFilter{}, fmt.Errorf("%w: unknown status %q", ErrInvalidExportFilter, status)
+	}
+
+	return filter, nil
+}
+
+// ExportOrders hands the CSV to send one page of orders at a time, the
+// header row first. Rows are ordered by creation time.
+func (s *orderService) ExportOrders(ctx context.Context, req *pb.ExportOrdersRequest, send func(csv []byte) error) error {
+	filter, err := ParseExportFilter(req.From, req.To, req.Status)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(domain.OrderExportHeader); err != nil {
+		return err
+	}
+
+	var (
+		afterCreatedAt time.Time
+		afterID        int64
+		exported       int
+	)
+	for {
+		rows, err := s.orderRepo.ExportOrders(ctx, filter, afterCreatedAt, afterID, exportPageSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range rows {
+			if err := w.Write(rows[i].Record()); err != nil {
+				return err
+			}
+		}
+
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+
+		if err := send(buf.Bytes()); err != nil {
+			return err
+		}
+		buf.Reset()
+
+		exported += len(rows)
+		if len(rows) < exportPageSize {
+			break
+		}
+
+		last := rows[len(rows)-1]
+		afterCreatedAt, afterID = last.CreatedAt, last.OrderID
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Orders exported",
+		zap.String("from", req.From),
+		zap.String("to", req.To),
+		zap.String("status", req.Status),
+		zap.Int("orders", exported),
+	)
+
+	return nil
+}
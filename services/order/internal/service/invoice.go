@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/objectstore"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+const invoiceContentType = "application/pdf"
+
+type invoicing struct {
+	store  objectstore.Store
+	issuer domain.InvoiceIssuer
+}
+
+// WithInvoicing issues a PDF invoice for every paid order and keeps it in
+// store. Without it no invoices are generated.
+func WithInvoicing(store objectstore.Store, issuer domain.InvoiceIssuer) Option {
+	return func(s *orderService) {
+		s.invoicing = &invoicing{store: store, issuer: issuer}
+	}
+}
+
+// GenerateInvoice issues the invoice of a paid order. It is idempotent, so
+// a redelivered PaymentSucceeded does not bill the order twice.
+func (s *orderService) GenerateInvoice(ctx context.Context, orderID int64) error {
+	if s.invoicing == nil {
+		return nil
+	}
+
+	ctx, span := s.tracer.Start(ctx, "OrderService.GenerateInvoice")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	var (
+		invoice *domain.Invoice
+		issued  bool
+	)
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		// The row lock serialises concurrent deliveries for the same order.
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		if order.Status != domain.OrderStatusPaid && order.Status != domain.OrderStatusShipped {
+			mylogger.Info(
+				ctx,
+				s.logger,
+				"Order is not paid, skipping invoice",
+				zap.Int64("order_id", orderID),
+				zap.String("status", string(order.Status)),
+			)
+
+			return nil
+		}
+
+		_, err = s.orderRepo.GetInvoiceByOrderID(ctx, orderID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, repository.ErrInvoiceNotFound) {
+			return err
+		}
+
+		issuedAt := time.Now().UTC()
+		sequence, err := s.orderRepo.NextInvoiceSequence(ctx, tx, issuedAt.Year())
+		if err != nil {
+			return err
+		}
+
+		invoice = domain.NewInvoice(order, s.invoicing.issuer, issuedAt)
+		invoice.Number = domain.InvoiceNumber(issuedAt.Year(), sequence)
+		invoice.ObjectKey = fmt.Sprintf("invoices/%s/%d/%s.pdf", tenant.FromContext(ctx), issuedAt.Year(), invoice.Number)
+
+		// The object is written before the row. If the commit fails the file is
+		// orphaned, but no invoice row ever points at a missing document.
+		document := renderInvoice(invoice, s.invoicing.issuer)
+		if err := s.invoicing.store.Put(ctx, invoice.ObjectKey, document, invoiceContentType); err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("failed to store invoice: %w", err)
+		}
+
+		// A concurrent delivery got there first; the failed insert aborted
+		// the transaction, so it is rolled back below and reported as done.
+		if err := s.orderRepo.CreateInvoice(ctx, tx, invoice); err != nil {
+			return err
+		}
+
+		err = s.emitEvent(ctx, tx, "order_events", fmt.Sprintf("%d", order.ID), "InvoiceGenerated", &domain.InvoiceGeneratedEvent{
+			OrderID:   order.ID,
+			UserID:    order.UserID,
+			InvoiceID: invoice.ID,
+			Number:    invoice.Number,
+			Total:     invoice.Total,
+			Currency:  invoice.Currency,
+			IssuedAt:  invoice.IssuedAt,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to emit event: %w", err)
+		}
+
+		message := fmt.Sprintf("Invoice %s issued", invoice.Number)
+		if err := s.recordTimeline(ctx, tx, order.ID, domain.TimelineInvoiceIssued, order.Status, message, true); err != nil {
+			return err
+		}
+
+		issued = true
+		return nil
+	})
+	if errors.Is(err, repository.ErrInvoiceExists) {
+		return nil
+	}
+	if err != nil || !issued {
+		return err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Invoice generated",
+		zap.Int64("order_id", orderID),
+		zap.String("number", invoice.Number),
+	)
+
+	return nil
+}
+
+func (s *orderService) GetInvoice(ctx context.Context, req *pb.GetInvoiceRequest) (*pb.GetInvoiceResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.GetInvoice")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", req.OrderId))
+
+	if s.invoicing == nil {
+		return nil, repository.ErrInvoiceNotFound
+	}
+
+	invoice, err := s.orderRepo.GetInvoiceByOrderID(ctx, req.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice.UserID != req.UserId {
+		return nil, repository.ErrInvoiceNotFound
+	}
+
+	content, err := s.invoicing.store.Get(ctx, invoice.ObjectKey)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to load invoice %s: %w", invoice.Number, err)
+	}
+
+	return &pb.GetInvoiceResponse{
+		Number:      invoice.Number,
+		ContentType: invoiceContentType,
+		Content:     content,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/pdf"
+)
+
+const (
+	invoiceMargin     = 50.0
+	invoiceRight      = pdf.PageWidth - invoiceMargin
+	invoiceLineHeight = 16.0
+	// invoiceRowsPerPage keeps the table clear of the totals block.
+	invoiceRowsPerPage = 30
+)
+
+// renderInvoice lays the invoice out on as many A4 pages as its lines need.
+// Columns are right-aligned to fixed edges: quantity, unit price, net, tax
+// and gross.
+func renderInvoice(invoice *domain.Invoice, issuer domain.InvoiceIssuer) []byte {
+	doc := pdf.New()
+
+	columns := []float64{330, 395, 455, 505, invoiceRight}
+
+	newPage := func() (*pdf.Page, float64) {
+		page := doc.AddPage()
+
+		page.Text(invoiceMargin, 70, pdf.FontBold, 20, "Invoice")
+		page.TextRight(invoiceRight, 70, pdf.FontBold, 12, invoice.Number)
+
+		y := 100.0
+		for _, line := range []string{issuer.Name, issuer.Address, taxIDLine(issuer.TaxID), issuer.Email} {
+			if line == "" {
+				continue
+			}
+			page.Text(invoiceMargin, y, pdf.FontRegular, 10, line)
+			y += 14
+		}
+
+		page.TextRight(invoiceRight, 100, pdf.FontRegular, 10, "Issued "+invoice.IssuedAt.Format("2006-01-02"))
+		page.TextRight(invoiceRight, 114, pdf.FontRegular, 10, fmt.Sprintf("Order #%d", invoice.OrderID))
+		page.TextRight(invoiceRight, 128, pdf.FontRegular, 10, fmt.Sprintf("Customer #%d", invoice.UserID))
+
+		y = max(y, 142) + 24
+		page.Text(invoiceMargin, y, pdf.FontBold, 10, "Item")
+		for i, title := range []string{"Qty", "Unit price", "Net", "Tax", "Total"} {
+			page.TextRight(columns[i], y, pdf.FontBold, 10, title)
+		}
+		page.Line(invoiceMargin, y+6, invoiceRight, y+6)
+
+		return page, y + invoiceLineHeight + 4
+	}
+
+	page, y := newPage()
+	for i, line := range invoice.Lines {
+		if i > 0 && i%invoiceRowsPerPage == 0 {
+			page, y = newPage()
+		}
+
+		page.Text(invoiceMargin, y, pdf.FontRegular, 10, truncate(line.Name, 45))
+		values := []string{
+			strconv.FormatInt(line.Quantity, 10),
+			formatMoney(line.UnitPrice),
+			formatMoney(line.Net),
+			formatMoney(line.Tax),
+			formatMoney(line.Gross),
+		}
+		for i, value := range values {
+			page.TextRight(columns[i], y, pdf.FontRegular, 10, value)
+		}
+
+		y += invoiceLineHeight
+	}
+
+	page.Line(invoiceMargin, y-8, invoiceRight, y-8)
+	y += 8
+
+	rate := fmt.Sprintf("Tax %s%%", formatBps(invoice.TaxRateBps))
+	totals := []struct {
+		label string
+		value int64
+		font  pdf.Font
+	}{
+		{"Net", invoice.NetTotal, pdf.FontRegular},
+		{rate, invoice.TaxTotal, pdf.FontRegular},
+		{"Total " + invoice.Currency, invoice.Total, pdf.FontBold},
+	}
+	for _, t := range totals {
+		page.TextRight(columns[3], y, t.font, 10, t.label)
+		page.TextRight(invoiceRight, y, t.font, 10, formatMoney(t.value))
+		y += invoiceLineHeight
+	}
+
+	page.Text(invoiceMargin, y+24, pdf.FontRegular, 9, "Prices include tax. Paid in full, thank you for your order.")
+
+	return doc.Bytes()
+}
+
+// formatMoney prints minor units with two decimals, e.g. 5350 as 53.50.
+func formatMoney(amount int64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, amount/100, amount%100)
+}
+
+// formatBps prints a rate in basis points as a percentage, e.g. 2050 as 20.5.
+func formatBps(bps int64) string {
+	return strconv.FormatFloat(float64(bps)/100, 'f', -1, 64)
+}
+
+func taxIDLine(taxID string) string {
+	if taxID == "" {
+		return ""
+	}
+	return "Tax ID " + taxID
+}
+
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-3]) + "..."
+}
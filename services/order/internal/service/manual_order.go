@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// CreateManualOrder places an order for a customer on behalf of support,
+// with the prices support set. Past placing it, the order goes through the
+// saga like any other: product reserves it at the overridden prices and
+// payment charges the customer's method. Each override is kept in the audit
+// trail with its reason.
+func (s *orderService) CreateManualOrder(ctx context.Context, req *pb.CreateManualOrderRequest) (*pb.CreateOrderResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.CreateManualOrder")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("admin_id", req.AdminId),
+		attribute.Int64("user_id", req.UserId),
+	)
+
+	note := strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(note) > domain.MaxOverrideReasonLength {
+		return nil, fmt.Errorf("%w: note is longer than %d characters", ErrInvalidPriceOverride, domain.MaxOverrideReasonLength)
+	}
+
+	adminID := req.AdminId
+	order := &domain.Order{
+		UserID:   req.UserId,
+		Status:   domain.OrderStatusNew,
+		Items:    make([]domain.OrderItem, 0, len(req.Items)),
+		PlacedBy: &adminID,
+	}
+	if methodID := req.PaymentMethodId; methodID != 0 {
+		order.PaymentMethodID = &methodID
+	}
+
+	var overrides []*domain.PriceOverride
+	for _, item := range req.Items {
+		orderItem := domain.OrderItem{
+			ProductID:   item.ProductId,
+			Name:        item.Name,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+			WeightGrams: item.WeightGrams,
+		}
+
+		if item.Override != nil {
+			if err := checkPriceReason(item.Override.ReasonCode, note); err != nil {
+				return nil, err
+			}
+
+			listPrice := item.Price
+			orderItem.ListPrice = &listPrice
+			orderItem.Price = item.Override.Price
+
+			productID := item.ProductId
+			overrides = append(overrides, &domain.PriceOverride{
+				ProductID:      &productID,
+				OriginalAmount: listPrice,
+				Amount:         item.Override.Price,
+				ReasonCode:     item.Override.ReasonCode,
+			})
+		}
+
+		order.Items = append(order.Items, orderItem)
+	}
+
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+
+	if discount := req.Discount; discount != nil {
+		if err := checkPriceReason(discount.ReasonCode, note); err != nil {
+			return nil, err
+		}
+		if discount.Amount <= 0 || discount.Amount > order.Subtotal() {
+			return nil, fmt.Errorf("%w: discount must be between 1 and the item total %d", ErrInvalidPriceOverride, order.Subtotal())
+		}
+
+		order.Discount = discount.Amount
+		overrides = append(overrides, &domain.PriceOverride{
+			OriginalAmount: order.Subtotal(),
+			Amount:         discount.Amount,
+			ReasonCode:     discount.ReasonCode,
+		})
+	}
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	err := s.placeOrder(ctx, order, req.ShippingAddressId, func(tx pgx.Tx) error {
+		for _, override := range overrides {
+			override.OrderID = order.ID
+			override.AdminID = req.AdminId
+			override.Note = note
+
+			if err := s.orderRepo.CreatePriceOverride(ctx, tx, override); err != nil {
+				return err
+			}
+		}
+
+		if len(overrides) == 0 {
+			return nil
+		}
+
+		message := fmt.Sprintf("Admin #%d set %d price override(s)", req.AdminId, len(overrides))
+		if note != "" {
+			message += ": " + note
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelinePricesOverridden, domain.OrderStatusNew, message, false)
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Manual order placed",
+		zap.Int64("order_id", order.ID),
+		zap.Int64("admin_id", req.AdminId),
+		zap.Int64("user_id", req.UserId),
+		zap.Int("overrides", len(overrides)),
+	)
+
+	return &pb.CreateOrderResponse{
+		OrderId:     order.ID,
+		DeliveryFee: order.DeliveryFee,
+		TotalSum:    order.TotalSum,
+		Discount:    order.Discount,
+	}, nil
+}
+
+// checkPriceReason requires a known reason code for every override, and a
+// note to explain "other".
+func checkPriceReason(code, note string) error {
+	if !domain.IsKnownPriceReason(code) {
+		return fmt.Errorf("%w: unknown reason code %q", ErrInvalidPriceOverride, code)
+	}
+	if code == domain.PriceReasonOther && note == "" {
+		return fmt.Errorf("%w: reason code %q needs a note", ErrInvalidPriceOverride, code)
+	}
+
+	return nil
+}
@@ -5,15 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sakashimaa/go-pet-project/order/internal/domain"
 	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
 	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
 	pb "github.com/sakashimaa/go-pet-project/proto/order"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
@@ -23,311 +28,989 @@ import (
 
 type OrderService interface {
 	HandleUserRegistered(ctx context.Context, event *domain.UserRegisteredEvent) error
+	HandleUserRoleChanged(ctx context.Context, event *domain.UserRoleChangedEvent) error
 	CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error)
 	ChangeOrderStatusPaymentSucceeded(ctx context.Context, event *generalDomain.PaymentSucceededEvent) error
 	CancelOrder(ctx context.Context, event *generalDomain.PaymentFailedEvent) error
+	HandleInventoryReserved(ctx context.Context, event *domain.InventoryReservedEvent) error
+	HandleInventoryPartiallyReserved(ctx context.Context, event *domain.InventoryPartiallyReservedEvent) error
+	ResolvePartialReservation(ctx context.Context, req *pb.ResolvePartialReservationRequest) (*pb.ResolvePartialReservationResponse, error)
+	HandleShipmentUpdated(ctx context.Context, event *domain.ShipmentUpdatedEvent) error
+	GetOrderTimeline(ctx context.Context, req *pb.GetOrderTimelineRequest) (*pb.GetOrderTimelineResponse, error)
+	ListUserOrders(ctx context.Context, req *pb.ListUserOrdersRequest) (*pb.ListUserOrdersResponse, error)
+	CancelOrderByUser(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error)
+	ExpireUnpaidOrders(ctx context.Context, reservedBefore time.Time) (int, error)
+	GenerateInvoice(ctx context.Context, orderID int64) error
+	GetInvoice(ctx context.Context, req *pb.GetInvoiceRequest) (*pb.GetInvoiceResponse, error)
+	ExportOrders(ctx context.Context, req *pb.ExportOrdersRequest, send func(csv []byte) error) error
+	ForceOrderStatus(ctx context.Context, req *pb.ForceOrderStatusRequest) (*pb.ForceOrderStatusResponse, error)
+	CreateManualOrder(ctx context.Context, req *pb.CreateManualOrderRequest) (*pb.CreateOrderResponse, error)
+	AddAddress(ctx context.Context, req *pb.AddAddressRequest) (*pb.Address, error)
+	ListAddresses(ctx context.Context, req *pb.ListAddressesRequest) (*pb.ListAddressesResponse, error)
+	UpdateAddress(ctx context.Context, req *pb.UpdateAddressRequest) (*pb.Address, error)
+	DeleteAddress(ctx context.Context, req *pb.DeleteAddressRequest) (*pb.DeleteAddressResponse, error)
+	SetPurchaseCap(ctx context.Context, req *pb.SetPurchaseCapRequest) (*pb.PurchaseCap, error)
+	ListPurchaseCaps(ctx context.Context, req *pb.ListPurchaseCapsRequest) (*pb.ListPurchaseCapsResponse, error)
+	RequestReturn(ctx context.Context, req *pb.RequestReturnRequest) (*pb.Return, error)
+	ReviewReturn(ctx context.Context, req *pb.ReviewReturnRequest) (*pb.Return, error)
+	ListReturns(ctx context.Context, req *pb.ListReturnsRequest) (*pb.ListReturnsResponse, error)
+	HandleRefundIssued(ctx context.Context, event *generalDomain.RefundIssuedEvent) error
+	AddOrderNote(ctx context.Context, req *pb.AddOrderNoteRequest) (*pb.OrderAnnotation, error)
+	SetOrderFlag(ctx context.Context, req *pb.SetOrderFlagRequest) (*pb.OrderAnnotation, error)
+	ClearOrderFlag(ctx context.Context, req *pb.ClearOrderFlagRequest) (*pb.OrderAnnotation, error)
+	ListOrderAnnotations(ctx context.Context, req *pb.ListOrderAnnotationsRequest) (*pb.ListOrderAnnotationsResponse, error)
 }
 
 type orderService struct {
-	pool       *pgxpool.Pool
-	logger     *zap.Logger
-	orderRepo  repository.OrderRepository
-	outboxRepo worker.OutboxRepository
-	tracer     trace.Tracer
+	pool          *pgxpool.Pool
+	logger        *zap.Logger
+	orderRepo     repository.OrderRepository
+	outboxRepo    worker.OutboxRepository
+	tracer        trace.Tracer
+	invoicing     *invoicing
+	shippingRater ShippingRater
+	ordersPerHour int
 }
 
-func NewOrderService(pool *pgxpool.Pool, logger *zap.Logger, orderRepo repository.OrderRepository, outboxRepo worker.OutboxRepository) OrderService {
-	return &orderService{
-		pool:       pool,
-		logger:     logger,
-		orderRepo:  orderRepo,
-		outboxRepo: outboxRepo,
-		tracer:     otel.Tracer("order_service"),
+type Option func(*orderService)
+
+func NewOrderService(pool *pgxpool.Pool, logger *zap.Logger, orderRepo repository.OrderRepository, outboxRepo worker.OutboxRepository, opts ...Option) OrderService {
+	s := &orderService{
+		pool:          pool,
+		logger:        logger,
+		orderRepo:     orderRepo,
+		outboxRepo:    outboxRepo,
+		tracer:        otel.Tracer("order_service"),
+		shippingRater: FlatRate{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 func (s *orderService) CancelOrder(ctx context.Context, event *generalDomain.PaymentFailedEvent) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Failed to begin transaction",
-			zap.Error(err),
-		)
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, event.OrderID)
+		if err != nil {
+			return err
+		}
 
-		return err
-	}
-	defer func() {
-		shutdownCtx := context.WithoutCancel(ctx)
+		if order.Status == domain.OrderStatusCancelled {
+			// Cancelled by the customer or the payment watchdog, the stock
+			// has been returned already.
+			mylogger.Info(ctx, s.logger, "Order already cancelled", zap.Int64("order_id", event.OrderID))
+			return nil
+		}
+
+		err = s.orderRepo.ChangeOrderStatus(ctx, tx, event.OrderID, "cancelled")
+		if err != nil {
+			if errors.Is(err, repository.ErrOrderNotFound) {
+				mylogger.Warn(
+					ctx,
+					s.logger,
+					"Order not found",
+					zap.Int64("order_id", event.OrderID),
+				)
 
-		if err := tx.Rollback(shutdownCtx); err != nil {
-			mylogger.Warn(shutdownCtx, s.logger, "Failed to rollback transaction", zap.Error(err))
+				return err
+			}
+
+			mylogger.Warn(
+				ctx,
+				s.logger,
+				"Cancel order failed",
+				zap.Error(err),
+			)
+
+			return fmt.Errorf("failed to cancel order: %w", err)
 		}
-	}()
 
-	err = s.orderRepo.ChangeOrderStatus(ctx, tx, event.OrderID, "cancelled")
-	if err != nil {
-		if errors.Is(err, repository.ErrOrderNotFound) {
+		// Only reserved items hold stock. Removed ones never did, and a
+		// reservation that has not arrived yet is released when it does.
+		err = s.emitEvent(ctx, tx, "product_events", fmt.Sprintf("%d", event.OrderID), "OrderCancelled", &generalDomain.OrderCancelledEvent{
+			OrderID: event.OrderID,
+			Items:   reservedItems(order),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to emit event: %w", err)
+		}
+
+		return s.recordTimeline(ctx, tx, event.OrderID, domain.TimelinePaymentFailed, domain.OrderStatusCancelled, "Payment failed, the order was cancelled", true)
+	})
+}
+
+func (s *orderService) ChangeOrderStatusPaymentSucceeded(ctx context.Context, event *generalDomain.PaymentSucceededEvent) error {
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, event.OrderID)
+		if err != nil {
+			return err
+		}
+
+		if order.Status == domain.OrderStatusPaid || order.Status == domain.OrderStatusShipped {
+			// Redelivered, e.g. because invoicing failed after the status change.
+			return nil
+		}
+
+		if order.Status == domain.OrderStatusCancelled {
+			// The money arrived after the order gave up waiting for it, so it
+			// stays cancelled and support has to refund the payment.
 			mylogger.Warn(
 				ctx,
 				s.logger,
-				"Order not found",
+				"Payment succeeded for a cancelled order",
+				zap.Int64("order_id", event.OrderID),
+				zap.Int64("payment_id", event.PaymentID),
+			)
+
+			message := fmt.Sprintf("Payment #%d arrived after cancellation and needs a refund", event.PaymentID)
+			if err := s.recordTimeline(ctx, tx, event.OrderID, domain.TimelineLatePayment, domain.OrderStatusCancelled, message, false); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		err = s.orderRepo.ChangeOrderStatus(ctx, tx, event.OrderID, "paid")
+		if err != nil {
+			if errors.Is(err, repository.ErrOrderNotFound) {
+				mylogger.Warn(
+					ctx,
+					s.logger,
+					"Order not found",
+					zap.Int64("order_id", event.OrderID),
+				)
+
+				return err
+			}
+
+			mylogger.Error(
+				ctx,
+				s.logger,
+				"Failed to update order status",
 				zap.Int64("order_id", event.OrderID),
+				zap.Error(err),
 			)
 
+			return fmt.Errorf("failed to update order status: %w", err)
+		}
+
+		unpaid := []domain.OrderItemStatus{domain.OrderItemStatusPending, domain.OrderItemStatusReserved}
+		for _, from := range unpaid {
+			err = s.orderRepo.TransitionItemsStatus(ctx, tx, event.OrderID, from, domain.OrderItemStatusPaid)
+			if err != nil {
+				return fmt.Errorf("failed to update items status: %w", err)
+			}
+		}
+
+		if err := s.emitOrderPaid(ctx, tx, order, unpaid...); err != nil {
 			return err
 		}
 
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Cancel order failed",
-			zap.Error(err),
-		)
+		return s.recordTimeline(ctx, tx, event.OrderID, domain.TimelinePaymentSucceeded, domain.OrderStatusPaid, fmt.Sprintf("Payment #%d received", event.PaymentID), true)
+	})
+}
+
+func (s *orderService) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
+	items := make([]domain.OrderItem, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, domain.OrderItem{
+			ProductID:   item.ProductId,
+			Name:        item.Name,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+			WeightGrams: item.WeightGrams,
+		})
+	}
 
-		return fmt.Errorf("failed to cancel order: %w", err)
+	order := &domain.Order{
+		UserID: req.UserId,
+		Status: domain.OrderStatusNew,
+		Items:  items,
+	}
+	if methodID := req.PaymentMethodId; methodID != 0 {
+		order.PaymentMethodID = &methodID
+	}
+
+	if req.ClientToken != "" {
+		token, err := uuid.Parse(req.ClientToken)
+		if err != nil {
+			return nil, fmt.Errorf("%w: client token must be a UUID", domain.ErrInvalidOrder)
+		}
+		order.ClientToken = &token
+	}
+
+	if err := order.Validate(); err != nil {
+		return nil, err
+	}
+
+	if order.ClientToken != nil {
+		existing, err := s.orderRepo.GetOrderByClientToken(ctx, order.UserID, *order.ClientToken)
+		if err == nil {
+			return s.duplicateOrderResponse(ctx, existing), nil
+		}
+		if !errors.Is(err, repository.ErrOrderNotFound) {
+			return nil, err
+		}
 	}
 
-	orderItems, err := s.orderRepo.GetAllItemsOfOrder(ctx, tx, event.OrderID)
+	err := s.placeOrder(ctx, order, req.ShippingAddressId, nil)
+	if errors.Is(err, repository.ErrDuplicateClientToken) {
+		// A concurrent request with the same token placed it first.
+		existing, err := s.orderRepo.GetOrderByClientToken(ctx, order.UserID, *order.ClientToken)
+		if err != nil {
+			return nil, err
+		}
+
+		return s.duplicateOrderResponse(ctx, existing), nil
+	}
 	if err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Failed to query items of order",
-			zap.Int64("order_id", event.OrderID),
-			zap.Error(err),
-		)
+		return nil, err
+	}
+
+	return &pb.CreateOrderResponse{
+		OrderId:     order.ID,
+		DeliveryFee: order.DeliveryFee,
+		TotalSum:    order.TotalSum,
+	}, nil
+}
+
+// duplicateOrderResponse answers a resubmitted order with the one placed
+// the first time.
+func (s *orderService) duplicateOrderResponse(ctx context.Context, order *domain.Order) *pb.CreateOrderResponse {
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Order resubmitted with a used client token",
+		zap.Int64("order_id", order.ID),
+		zap.Int64("user_id", order.UserID),
+	)
 
-		return fmt.Errorf("failed to query items of order: %w", err)
+	return &pb.CreateOrderResponse{
+		OrderId:     order.ID,
+		DeliveryFee: order.DeliveryFee,
+		TotalSum:    order.TotalSum,
+		Discount:    order.Discount,
+		Duplicate:   true,
 	}
+}
 
-	err = s.emitEvent(ctx, tx, "product_events", fmt.Sprintf("%d", event.OrderID), "OrderCancelled", &generalDomain.OrderCancelledEvent{
-		OrderID: event.OrderID,
-		Items:   orderItems,
+// placeOrder ships order to the customer's address, prices it and saves it
+// with the OrderCreated event that starts the saga. audit, when given, runs
+// in the same transaction once the order has its id.
+func (s *orderService) placeOrder(ctx context.Context, order *domain.Order, shippingAddressID int64, audit func(tx pgx.Tx) error) error {
+	if shippingAddressID <= 0 {
+		return fmt.Errorf("%w: a shipping address is required", domain.ErrInvalidOrder)
+	}
+
+	var address *domain.Address
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		address, err = s.orderRepo.GetAddress(ctx, tx, shippingAddressID, order.UserID)
+		return err
 	})
 	if err != nil {
-		return fmt.Errorf("failed to emit event: %w", err)
+		return err
 	}
+	order.ShippingAddress = &address.ShippingAddress
 
-	if err := tx.Commit(ctx); err != nil {
-		mylogger.Error(ctx, s.logger, "Failed to commit transaction", zap.Error(err))
+	// The rate may ask a carrier, so it is quoted before the transaction
+	// rather than holding it open for a network call.
+	if err := s.quoteDelivery(ctx, order); err != nil {
+		mylogger.Warn(ctx, s.logger, "Failed to quote delivery", zap.Int64("user_id", order.UserID), zap.Error(err))
 		return err
 	}
+	order.CalculateTotal()
 
-	return nil
-}
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		// Support may place orders past the limits on a customer's behalf;
+		// they still count towards the customer's caps.
+		if order.PlacedBy == nil {
+			if err := s.checkOrderLimits(ctx, tx, order); err != nil {
+				return err
+			}
+		}
 
-func (s *orderService) ChangeOrderStatusPaymentSucceeded(ctx context.Context, event *generalDomain.PaymentSucceededEvent) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Failed to begin transaction",
-			zap.Error(err),
-		)
+		if err := s.orderRepo.CreateOrder(ctx, tx, order); err != nil {
+			if errors.Is(err, repository.ErrDuplicateClientToken) {
+				return err
+			}
 
-		return fmt.Errorf("failed to begin transaction")
-	}
-	defer func() {
-		shutdownCtx := context.WithoutCancel(ctx)
-		if err := tx.Rollback(shutdownCtx); err != nil {
 			mylogger.Error(
-				shutdownCtx,
+				ctx,
 				s.logger,
-				"Failed to rollback transaction",
+				"Failed to create order",
+				zap.Int64("user_id", order.UserID),
 				zap.Error(err),
 			)
+
+			return fmt.Errorf("failed to create order: %v", err)
 		}
-	}()
 
-	err = s.orderRepo.ChangeOrderStatus(ctx, tx, event.OrderID, "paid")
-	if err != nil {
-		if errors.Is(err, repository.ErrOrderNotFound) {
+		if audit != nil {
+			if err := audit(tx); err != nil {
+				return err
+			}
+		}
+
+		eventItems := make([]map[string]any, len(order.Items))
+		for i, item := range order.Items {
+			eventItems[i] = map[string]any{
+				"product_id": item.ProductID,
+				"quantity":   item.Quantity,
+			}
+			if item.ListPrice != nil {
+				// Product charges the catalog price unless told otherwise.
+				eventItems[i]["price_override"] = map[string]any{"price": item.Price}
+			}
+		}
+
+		orderData := map[string]any{
+			"order_id": order.ID,
+			"event_id": order.ID,
+			"user_id":  order.UserID,
+			"items":    eventItems,
+			// For shipping, which has to know where the order goes without
+			// asking back.
+			"shipping_address": order.ShippingAddress,
+			// Product adds it to the amount payment charges.
+			"delivery_fee": order.DeliveryFee,
+		}
+		if order.PaymentMethodID != nil {
+			// Product hands it on to payment together with the reservation.
+			orderData["payment_method_id"] = *order.PaymentMethodID
+		}
+		if order.Discount > 0 {
+			// Product takes it off the items it reserved.
+			orderData["discount"] = order.Discount
+		}
+
+		eventEnvelope := map[string]any{
+			"event":   "OrderCreated",
+			"payload": orderData,
+		}
+
+		payloadBytes, err := json.Marshal(eventEnvelope)
+		if err != nil {
 			mylogger.Warn(
 				ctx,
 				s.logger,
-				"Order not found",
-				zap.Int64("order_id", event.OrderID),
+				"Failed to marshal event envelope",
+				zap.Error(err),
 			)
 
-			return err
+			return fmt.Errorf("failed to marshal event envelope: %v", err)
+		}
+
+		outboxEvent := &outboxDomain.OutboxEvent{
+			AggregateType: "Order",
+			AggregateID:   fmt.Sprintf("%d", order.ID),
+			EventType:     "OrderCreated",
+			Payload:       payloadBytes,
+			Headers:       nil,
+			Topic:         "order_events",
+		}
+
+		if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
+			mylogger.Error(
+				ctx,
+				s.logger,
+				"Failed to save outbox event",
+				zap.Error(err),
+			)
+
+			return fmt.Errorf("failed to save outbox event: %v", err)
 		}
 
-		mylogger.Error(
+		message := "Order placed"
+		if order.PlacedBy != nil {
+			message = "Order placed by support"
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineOrderCreated, domain.OrderStatusNew, message, true)
+	})
+}
+
+func (s *orderService) HandleUserRegistered(ctx context.Context, event *domain.UserRegisteredEvent) error {
+	if event.UserID <= 0 || event.Email == "" {
+		return fmt.Errorf("user id or email are not provided")
+	}
+
+	ctx, span := s.tracer.Start(ctx, "OrderService.HandleUserRegistered")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", event.UserID),
+	)
+
+	if err := s.orderRepo.SaveUserDuplication(ctx, event); err != nil {
+		span.RecordError(err)
+
+		mylogger.Warn(
 			ctx,
 			s.logger,
-			"Failed to update order status",
-			zap.Int64("order_id", event.OrderID),
+			"Failed to save user",
 			zap.Error(err),
 		)
 
-		return fmt.Errorf("failed to update order status: %w", err)
+		return err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"User saved successfully",
+		zap.Int64("user_id", event.UserID),
+	)
+
+	return nil
+}
+
+func (s *orderService) HandleUserRoleChanged(ctx context.Context, event *domain.UserRoleChangedEvent) error {
+	if event.UserID <= 0 || event.Role == "" {
+		return fmt.Errorf("user id or role are not provided")
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		mylogger.Error(
+	ctx, span := s.tracer.Start(ctx, "OrderService.HandleUserRoleChanged")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", event.UserID),
+		attribute.String("role", event.Role),
+	)
+
+	if err := s.orderRepo.UpdateUserRole(ctx, event.UserID, event.Role); err != nil {
+		span.RecordError(err)
+
+		mylogger.Warn(
 			ctx,
 			s.logger,
-			"Failed to commit transaction",
+			"Failed to update user role",
+			zap.Int64("user_id", event.UserID),
 			zap.Error(err),
 		)
 
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return err
 	}
 
 	return nil
 }
 
-func (s *orderService) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
-	items := make([]domain.OrderItem, 0, len(req.Items))
-	for _, item := range req.Items {
-		items = append(items, domain.OrderItem{
-			ProductID: item.ProductId,
-			Name:      item.Name,
-			Price:     item.Price,
-			Quantity:  item.Quantity,
-		})
+// requireRole checks the locally replicated role of the user, so admin-only
+// operations don't need a round trip to the auth service.
+func (s *orderService) requireRole(ctx context.Context, userID int64, role string) error {
+	userRole, err := s.orderRepo.GetUserRole(ctx, userID)
+	if err != nil {
+		return err
 	}
 
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("failed to start transaction: %v", err)
+	if userRole != role {
+		return ErrPermissionDenied
 	}
-	defer func() {
-		shutdownCtx := context.WithoutCancel(ctx)
-		err := tx.Rollback(shutdownCtx)
 
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			mylogger.Warn(
-				shutdownCtx,
-				s.logger,
-				"Error rolling back transaction",
-				zap.Error(err),
-			)
+	return nil
+}
+
+func (s *orderService) HandleInventoryReserved(ctx context.Context, event *domain.InventoryReservedEvent) error {
+	ctx, span := s.tracer.Start(ctx, "OrderService.HandleInventoryReserved")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", event.OrderID),
+	)
+
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		err := s.orderRepo.UpdateReservation(ctx, tx, event.OrderID, domain.OrderStatusNew, domain.OrderStatusReserved, event.Amount)
+		if err != nil {
+			if errors.Is(err, repository.ErrStatusConflict) {
+				items, err := s.orderRepo.GetAllItemsOfOrder(ctx, tx, event.OrderID)
+				if err != nil {
+					span.RecordError(err)
+					return err
+				}
+
+				return s.releaseLateReservation(ctx, tx, event.OrderID, items)
+			}
+
+			span.RecordError(err)
+			return err
 		}
-	}()
 
-	order := &domain.Order{
-		UserID: req.UserId,
-		Status: domain.OrderStatusNew,
-		Items:  items,
-	}
+		err = s.orderRepo.TransitionItemsStatus(ctx, tx, event.OrderID, domain.OrderItemStatusPending, domain.OrderItemStatusReserved)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
 
-	order.CalculateTotal()
+		return s.recordTimeline(ctx, tx, event.OrderID, domain.TimelineInventoryReserved, domain.OrderStatusReserved, "All items are reserved", true)
+	})
+}
+
+func (s *orderService) HandleInventoryPartiallyReserved(ctx context.Context, event *domain.InventoryPartiallyReservedEvent) error {
+	ctx, span := s.tracer.Start(ctx, "OrderService.HandleInventoryPartiallyReserved")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", event.OrderID),
+		attribute.Int("reserved_count", len(event.ReservedItems)),
+		attribute.Int("unavailable_count", len(event.UnavailableItems)),
+	)
+
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		err := s.orderRepo.UpdateReservation(ctx, tx, event.OrderID, domain.OrderStatusNew, domain.OrderStatusPartiallyReserved, event.Amount)
+		if err != nil {
+			if errors.Is(err, repository.ErrStatusConflict) {
+				items := make([]generalDomain.OrderItem, 0, len(event.ReservedItems))
+				for _, item := range event.ReservedItems {
+					items = append(items, generalDomain.OrderItem{ProductID: item.ProductID, Quantity: int32(item.Quantity)})
+				}
+
+				return s.releaseLateReservation(ctx, tx, event.OrderID, items)
+			}
+
+			span.RecordError(err)
+			return err
+		}
+
+		reservedIDs := make([]int64, 0, len(event.ReservedItems))
+		for _, item := range event.ReservedItems {
+			reservedIDs = append(reservedIDs, item.ProductID)
+		}
+
+		unavailableIDs := make([]int64, 0, len(event.UnavailableItems))
+		for _, item := range event.UnavailableItems {
+			unavailableIDs = append(unavailableIDs, item.ProductID)
+		}
+
+		if err := s.orderRepo.SetItemsStatus(ctx, tx, event.OrderID, reservedIDs, domain.OrderItemStatusReserved); err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		if err := s.orderRepo.SetItemsStatus(ctx, tx, event.OrderID, unavailableIDs, domain.OrderItemStatusUnavailable); err != nil {
+			span.RecordError(err)
+			return err
+		}
 
-	err = s.orderRepo.CreateOrder(ctx, tx, order)
+		message := fmt.Sprintf("%d of %d items are reserved, waiting for your decision", len(event.ReservedItems), len(event.ReservedItems)+len(event.UnavailableItems))
+		return s.recordTimeline(ctx, tx, event.OrderID, domain.TimelinePartialReserved, domain.OrderStatusPartiallyReserved, message, true)
+	})
 	if err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Failed to create order",
-			zap.Int64("user_id", req.UserId),
-			zap.Error(err),
-		)
+		return err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Order partially reserved, waiting for user choice",
+		zap.Int64("order_id", event.OrderID),
+	)
 
-		return nil, fmt.Errorf("failed to create order: %v", err)
+	return nil
+}
+
+func (s *orderService) ResolvePartialReservation(ctx context.Context, req *pb.ResolvePartialReservationRequest) (*pb.ResolvePartialReservationResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ResolvePartialReservation")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.String("choice", req.Choice.String()),
+	)
+
+	if req.Choice != pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_WAIT &&
+		req.Choice != pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_REMOVE_UNAVAILABLE {
+		return nil, ErrInvalidChoice
 	}
 
-	eventItems := make([]map[string]any, len(items))
-	for i, item := range items {
-		eventItems[i] = map[string]any{
-			"product_id": item.ProductID,
-			"quantity":   item.Quantity,
+	var order *domain.Order
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		order, err = s.orderRepo.GetOrderByID(ctx, tx, req.OrderId)
+		if err != nil {
+			return err
 		}
-	}
 
-	orderData := map[string]any{
-		"order_id": order.ID,
-		"event_id": order.ID,
-		"user_id":  order.UserID,
-		"items":    eventItems,
+		if order.UserID != req.UserId {
+			return repository.ErrOrderNotFound
+		}
+
+		switch req.Choice {
+		case pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_WAIT:
+			if order.Status != domain.OrderStatusPartiallyReserved {
+				return ErrOrderNotPartiallyReserved
+			}
+
+			order.Status = domain.OrderStatusAwaitingStock
+			order.FulfillmentChoice = domain.FulfillmentChoiceWait
+		default:
+			if order.Status != domain.OrderStatusPartiallyReserved && order.Status != domain.OrderStatusAwaitingStock {
+				return ErrOrderNotPartiallyReserved
+			}
+
+			for i := range order.Items {
+				if order.Items[i].Status == domain.OrderItemStatusUnavailable {
+					order.Items[i].Status = domain.OrderItemStatusRemoved
+				}
+			}
+
+			err = s.orderRepo.TransitionItemsStatus(ctx, tx, order.ID, domain.OrderItemStatusUnavailable, domain.OrderItemStatusRemoved)
+			if err != nil {
+				return err
+			}
+
+			order.Status = domain.OrderStatusReserved
+			order.FulfillmentChoice = domain.FulfillmentChoiceRemoveUnavailable
+			order.CalculateTotal()
+		}
+
+		if err := s.orderRepo.ResolvePartialReservation(ctx, tx, order); err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		if order.Status == domain.OrderStatusReserved {
+			err = s.emitEvent(ctx, tx, "payment_events", fmt.Sprintf("%d", order.ID), "OrderConfirmed", &domain.OrderConfirmedEvent{
+				OrderID:         order.ID,
+				UserID:          order.UserID,
+				Amount:          order.ReservedAmount,
+				ReservedAt:      order.UpdatedAt,
+				PaymentMethodID: order.PaymentMethodID,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to emit event: %w", err)
+			}
+		}
+
+		message := "Waiting for the missing items to be restocked"
+		if order.FulfillmentChoice == domain.FulfillmentChoiceRemoveUnavailable {
+			message = "Unavailable items were removed from the order"
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineReservationChoice, order.Status, message, true)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	eventEnvelope := map[string]any{
-		"event":   "OrderCreated",
-		"payload": orderData,
+	return &pb.ResolvePartialReservationResponse{
+		OrderId:  order.ID,
+		Status:   string(order.Status),
+		TotalSum: order.TotalSum,
+	}, nil
+}
+
+// cancellableStatuses are the states before payment in which the customer
+// may still cancel the order.
+var cancellableStatuses = map[domain.OrderStatus]bool{
+	domain.OrderStatusNew:               true,
+	domain.OrderStatusReserved:          true,
+	domain.OrderStatusPartiallyReserved: true,
+	domain.OrderStatusAwaitingStock:     true,
+}
+
+func (s *orderService) CancelOrderByUser(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.CancelOrderByUser")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.Int64("user_id", req.UserId),
+	)
+
+	var order *domain.Order
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		order, err = s.orderRepo.GetOrderByID(ctx, tx, req.OrderId)
+		if err != nil {
+			return err
+		}
+
+		if order.UserID != req.UserId {
+			return repository.ErrOrderNotFound
+		}
+
+		if !cancellableStatuses[order.Status] {
+			return ErrOrderNotCancellable
+		}
+
+		if err := s.orderRepo.ChangeOrderStatus(ctx, tx, order.ID, string(domain.OrderStatusCancelled)); err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		// A reservation still in flight for a new order is released when it
+		// arrives, see releaseLateReservation.
+		if reserved := reservedItems(order); len(reserved) > 0 {
+			err = s.emitEvent(ctx, tx, "product_events", fmt.Sprintf("%d", order.ID), "OrderCancelled", &generalDomain.OrderCancelledEvent{
+				OrderID: order.ID,
+				Items:   reserved,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to emit event: %w", err)
+			}
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineOrderCancelled, domain.OrderStatusCancelled, "Order was cancelled by the customer", true)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	payloadBytes, err := json.Marshal(eventEnvelope)
+	mylogger.Info(ctx, s.logger, "Order cancelled by user", zap.Int64("order_id", order.ID))
+
+	return &pb.CancelOrderResponse{
+		OrderId: order.ID,
+		Status:  string(domain.OrderStatusCancelled),
+	}, nil
+}
+
+// releaseLateReservation handles a reservation that lands after the order has
+// left the new state. Payment events may overtake the reservation, in which
+// case there is nothing to do, but an order the customer cancelled while it
+// was new must give the freshly reserved stock back.
+func (s *orderService) releaseLateReservation(ctx context.Context, tx pgx.Tx, orderID int64, items []generalDomain.OrderItem) error {
+	order, err := s.orderRepo.GetOrderByID(ctx, tx, orderID)
 	if err != nil {
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Failed to marshal event envelope",
-			zap.Error(err),
-		)
+		return err
+	}
 
-		return nil, fmt.Errorf("failed to marshal event envelope: %v", err)
+	if order.Status != domain.OrderStatusCancelled {
+		mylogger.Info(ctx, s.logger, "Order already moved past reservation", zap.Int64("order_id", orderID))
+		return nil
 	}
 
-	outboxEvent := &outboxDomain.OutboxEvent{
-		AggregateType: "Order",
-		AggregateID:   fmt.Sprintf("%d", order.ID),
-		EventType:     "OrderCreated",
-		Payload:       payloadBytes,
-		Headers:       nil,
-		Topic:         "order_events",
+	if len(items) == 0 {
+		return nil
 	}
 
-	if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Failed to save outbox event",
-			zap.Error(err),
-		)
+	// A reservation the order took before it was cancelled went back with the
+	// cancellation, and a late one is released only once: anything else is a
+	// redelivery, and releasing it would conjure up stock.
+	seen, err := s.orderRepo.HasTimelineEvent(ctx, tx, orderID,
+		domain.TimelineInventoryReserved, domain.TimelinePartialReserved, domain.TimelineReservationReleased)
+	if err != nil {
+		return err
+	}
+	if seen {
+		mylogger.Info(ctx, s.logger, "Reservation of a cancelled order already released", zap.Int64("order_id", orderID))
+		return nil
+	}
 
-		return nil, fmt.Errorf("failed to save outbox event: %v", err)
+	err = s.emitEvent(ctx, tx, "product_events", fmt.Sprintf("%d", orderID), "OrderCancelled", &generalDomain.OrderCancelledEvent{
+		OrderID: orderID,
+		Items:   items,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Failed to commit transaction",
-			zap.Error(err),
-		)
+	mylogger.Info(ctx, s.logger, "Released reservation of a cancelled order", zap.Int64("order_id", orderID))
+
+	return s.recordTimeline(ctx, tx, orderID, domain.TimelineReservationReleased, domain.OrderStatusCancelled, "Stock reserved after the cancellation was released", false)
+}
 
-		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+// reservedItems returns the items of order that hold stock.
+func reservedItems(order *domain.Order) []generalDomain.OrderItem {
+	var items []generalDomain.OrderItem
+	for _, item := range order.Items {
+		if item.Status != domain.OrderItemStatusReserved {
+			continue
+		}
+
+		items = append(items, generalDomain.OrderItem{
+			ID:        item.ID,
+			OrderID:   order.ID,
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Price:     item.Price,
+			Quantity:  item.Quantity,
+		})
 	}
 
-	return &pb.CreateOrderResponse{OrderId: order.ID}, nil
+	return items
 }
 
-func (s *orderService) HandleUserRegistered(ctx context.Context, event *domain.UserRegisteredEvent) error {
-	if event.UserID <= 0 || event.Email == "" {
-		return fmt.Errorf("user id or email are not provided")
+// emitOrderPaid announces the items of order that were in one of the
+// statuses from when it was paid for.
+func (s *orderService) emitOrderPaid(ctx context.Context, tx pgx.Tx, order *domain.Order, from ...domain.OrderItemStatus) error {
+	var items []generalDomain.OrderItem
+	for _, item := range order.Items {
+		if !slices.Contains(from, item.Status) {
+			continue
+		}
+
+		items = append(items, generalDomain.OrderItem{
+			ID:        item.ID,
+			OrderID:   order.ID,
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Price:     item.Price,
+			Quantity:  item.Quantity,
+		})
 	}
 
-	ctx, span := s.tracer.Start(ctx, "OrderService.HandleUserRegistered")
+	err := s.emitEvent(ctx, tx, "order_events", fmt.Sprintf("%d", order.ID), "OrderPaid", &generalDomain.OrderPaidEvent{
+		OrderID: order.ID,
+		UserID:  order.UserID,
+		Items:   items,
+		PaidAt:  time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return nil
+}
+
+func (s *orderService) HandleShipmentUpdated(ctx context.Context, event *domain.ShipmentUpdatedEvent) error {
+	ctx, span := s.tracer.Start(ctx, "OrderService.HandleShipmentUpdated")
 	defer span.End()
 
 	span.SetAttributes(
-		attribute.Int64("user_id", event.UserID),
+		attribute.Int64("order_id", event.OrderID),
+		attribute.String("shipment_status", event.Status),
 	)
 
-	if err := s.orderRepo.SaveUserDuplication(ctx, event); err != nil {
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, event.OrderID)
+		if err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		message := fmt.Sprintf("Shipment %s", event.Status)
+		if event.Carrier != "" {
+			message = fmt.Sprintf("Shipment %s via %s (tracking %s)", event.Status, event.Carrier, event.TrackingNumber)
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineShipmentUpdated, order.Status, message, true)
+	})
+}
+
+func (s *orderService) GetOrderTimeline(ctx context.Context, req *pb.GetOrderTimelineRequest) (*pb.GetOrderTimelineResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.GetOrderTimeline")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.Bool("include_internal", req.IncludeInternal),
+	)
+
+	if req.IncludeInternal {
+		if err := s.requireRole(ctx, req.UserId, domain.RoleAdmin); err != nil {
+			return nil, err
+		}
+	} else {
+		ownerID, err := s.orderRepo.GetOrderOwner(ctx, req.OrderId)
+		if err != nil {
+			return nil, err
+		}
+
+		if ownerID != req.UserId {
+			return nil, repository.ErrOrderNotFound
+		}
+	}
+
+	events, err := s.orderRepo.GetTimeline(ctx, req.OrderId, !req.IncludeInternal)
+	if err != nil {
 		span.RecordError(err)
+		return nil, err
+	}
 
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Failed to save user",
-			zap.Error(err),
-		)
+	entries := make([]*pb.TimelineEntry, 0, len(events))
+	for _, event := range events {
+		entries = append(entries, &pb.TimelineEntry{
+			Id:                event.ID,
+			EventType:         event.EventType,
+			Status:            string(event.Status),
+			Message:           event.Message,
+			VisibleToCustomer: event.VisibleToCustomer,
+			CreatedAt:         event.CreatedAt.Format(time.RFC3339),
+		})
+	}
 
-		return err
+	return &pb.GetOrderTimelineResponse{
+		OrderId: req.OrderId,
+		Entries: entries,
+	}, nil
+}
+
+const (
+	defaultUserOrdersLimit = 5
+	maxUserOrdersLimit     = 50
+)
+
+func (s *orderService) ListUserOrders(ctx context.Context, req *pb.ListUserOrdersRequest) (*pb.ListUserOrdersResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ListUserOrders")
+	defer span.End()
+
+	limit := int(req.Limit)
+	if limit <= 0 {
+		limit = defaultUserOrdersLimit
 	}
+	limit = min(limit, maxUserOrdersLimit)
 
-	mylogger.Info(
-		ctx,
-		s.logger,
-		"User saved successfully",
-		zap.Int64("user_id", event.UserID),
+	span.SetAttributes(
+		attribute.Int64("user_id", req.UserId),
+		attribute.Int("limit", limit),
 	)
 
+	var after *domain.OrderCursor
+	var cursor domain.OrderCursor
+	if ok, err := query.DecodeCursor(req.Cursor, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	} else if ok {
+		after = &cursor
+	}
+
+	// One extra row tells whether another page exists.
+	orders, err := s.orderRepo.ListUserOrders(ctx, req.UserId, after, limit+1)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	var nextCursor string
+	if len(orders) > limit {
+		orders = orders[:limit]
+
+		last := orders[limit-1]
+		nextCursor, err = query.EncodeCursor(domain.OrderCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	summaries := make([]*pb.OrderSummary, 0, len(orders))
+	for _, order := range orders {
+		summaries = append(summaries, &pb.OrderSummary{
+			OrderId:     order.ID,
+			Status:      string(order.Status),
+			TotalSum:    order.TotalSum,
+			CreatedAt:   order.CreatedAt.Format(time.RFC3339),
+			DeliveryFee: order.DeliveryFee,
+		})
+	}
+
+	return &pb.ListUserOrdersResponse{Orders: summaries, NextCursor: nextCursor}, nil
+}
+
+func (s *orderService) recordTimeline(ctx context.Context, tx pgx.Tx, orderID int64, eventType string, status domain.OrderStatus, message string, visibleToCustomer bool) error {
+	err := s.orderRepo.AddTimelineEvent(ctx, tx, &domain.TimelineEvent{
+		OrderID:           orderID,
+		EventType:         eventType,
+		Status:            status,
+		Message:           message,
+		VisibleToCustomer: visibleToCustomer,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record timeline event: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// ForceOrderStatus lets an admin move an order the saga left stuck. The
+// order gets the same side effects it would have had on the normal path, so
+// a forced cancellation still hands its stock back, and the change is
+// recorded in the audit table, on the timeline and as an OrderStatusForced
+// event.
+func (s *orderService) ForceOrderStatus(ctx context.Context, req *pb.ForceOrderStatusRequest) (*pb.ForceOrderStatusResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ForceOrderStatus")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.Int64("admin_id", req.AdminId),
+		attribute.String("status", req.Status),
+	)
+
+	to := domain.OrderStatus(req.Status)
+	if !domain.IsKnownOrderStatus(to) {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidStatusOverride, req.Status)
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidStatusOverride)
+	}
+	if utf8.RuneCountInString(reason) > domain.MaxOverrideReasonLength {
+		return nil, fmt.Errorf("%w: reason is longer than %d characters", ErrInvalidStatusOverride, domain.MaxOverrideReasonLength)
+	}
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	var from domain.OrderStatus
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, req.OrderId)
+		if err != nil {
+			return err
+		}
+		from = order.Status
+
+		if !domain.CanForceStatus(from, to) {
+			return fmt.Errorf("%w: %s to %s", ErrStatusNotForcible, from, to)
+		}
+
+		if err := s.orderRepo.ForceOrderStatus(ctx, tx, order.ID, from, to); err != nil {
+			return err
+		}
+
+		if err := s.applyForcedStatus(ctx, tx, order, to); err != nil {
+			return err
+		}
+
+		err = s.orderRepo.CreateStatusOverride(ctx, tx, &domain.StatusOverride{
+			OrderID:    order.ID,
+			AdminID:    req.AdminId,
+			FromStatus: from,
+			ToStatus:   to,
+			Reason:     reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("Admin #%d forced the status from %s to %s: %s", req.AdminId, from, to, reason)
+		if err := s.recordTimeline(ctx, tx, order.ID, domain.TimelineStatusForced, to, message, false); err != nil {
+			return err
+		}
+
+		err = s.emitEvent(ctx, tx, "order_events", fmt.Sprintf("%d", order.ID), "OrderStatusForced", &domain.OrderStatusForcedEvent{
+			OrderID:    order.ID,
+			UserID:     order.UserID,
+			AdminID:    req.AdminId,
+			FromStatus: string(from),
+			ToStatus:   string(to),
+			Reason:     reason,
+			ForcedAt:   time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to emit event: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Order status forced",
+		zap.Int64("order_id", req.OrderId),
+		zap.Int64("admin_id", req.AdminId),
+		zap.String("from", string(from)),
+		zap.String("to", string(to)),
+	)
+
+	if to == domain.OrderStatusPaid {
+		// The status is committed whatever happens here, and invoicing is
+		// idempotent, so a failure is left for support to retry rather than
+		// failing a change that already happened.
+		if err := s.GenerateInvoice(ctx, req.OrderId); err != nil {
+			mylogger.Error(ctx, s.logger, "Failed to generate invoice for forced payment", zap.Int64("order_id", req.OrderId), zap.Error(err))
+		}
+	}
+
+	return &pb.ForceOrderStatusResponse{
+		OrderId:        req.OrderId,
+		PreviousStatus: string(from),
+		Status:         string(to),
+	}, nil
+}
+
+// applyForcedStatus does what reaching status the normal way would have
+// done to the items and the other services.
+func (s *orderService) applyForcedStatus(ctx context.Context, tx pgx.Tx, order *domain.Order, to domain.OrderStatus) error {
+	aggregateID := fmt.Sprintf("%d", order.ID)
+
+	switch to {
+	case domain.OrderStatusCancelled:
+		if order.Status == domain.OrderStatusReserved {
+			// Payment may be about to charge; this stops it like a timeout does.
+			err := s.emitEvent(ctx, tx, "payment_events", aggregateID, "PaymentTimedOut", &domain.PaymentTimedOutEvent{
+				OrderID:    order.ID,
+				UserID:     order.UserID,
+				Amount:     order.ReservedAmount,
+				TimedOutAt: time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to emit event: %w", err)
+			}
+		}
+
+		// A reservation still in flight for a new order is released when it
+		// arrives, see releaseLateReservation.
+		if reserved := reservedItems(order); len(reserved) > 0 {
+			err := s.emitEvent(ctx, tx, "product_events", aggregateID, "OrderCancelled", &generalDomain.OrderCancelledEvent{
+				OrderID: order.ID,
+				Items:   reserved,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to emit event: %w", err)
+			}
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineOrderCancelled, to, "Order was cancelled by support", true)
+	case domain.OrderStatusPaid:
+		if err := s.orderRepo.TransitionItemsStatus(ctx, tx, order.ID, domain.OrderItemStatusReserved, domain.OrderItemStatusPaid); err != nil {
+			return fmt.Errorf("failed to update items status: %w", err)
+		}
+
+		if err := s.emitOrderPaid(ctx, tx, order, domain.OrderItemStatusReserved); err != nil {
+			return err
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelinePaymentSucceeded, to, "Payment was confirmed by support", true)
+	case domain.OrderStatusShipped:
+		if err := s.orderRepo.TransitionItemsStatus(ctx, tx, order.ID, domain.OrderItemStatusPaid, domain.OrderItemStatusShipped); err != nil {
+			return fmt.Errorf("failed to update items status: %w", err)
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineShipmentUpdated, to, "Order was marked as shipped by support", true)
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// expireBatchSize bounds the orders one watchdog pass cancels.
+const expireBatchSize = 100
+
+// paymentHealthTimeout bounds the health check of one watchdog pass.
+const paymentHealthTimeout = 5 * time.Second
+
+// PaymentHealth tells whether the payment service is processing payments.
+type PaymentHealth interface {
+	Serving(ctx context.Context) (bool, error)
+}
+
+// PaymentWatchdog cancels reserved orders whose payment result did not arrive
+// within the timeout, so their stock does not stay locked forever.
+type PaymentWatchdog struct {
+	service OrderService
+	timeout time.Duration
+	health  PaymentHealth
+	grace   time.Duration
+	logger  *zap.Logger
+}
+
+type WatchdogOption func(*PaymentWatchdog)
+
+// WithPaymentHealth makes the watchdog tell a slow payment from a payment
+// service that is down. While it is down the payments are still queued and
+// will be made once it is back, so the timeout is stretched by grace instead
+// of cancelling orders that would have been paid.
+func WithPaymentHealth(health PaymentHealth, grace time.Duration) WatchdogOption {
+	return func(w *PaymentWatchdog) {
+		w.health = health
+		w.grace = grace
+	}
+}
+
+func NewPaymentWatchdog(service OrderService, timeout time.Duration, logger *zap.Logger, opts ...WatchdogOption) *PaymentWatchdog {
+	w := &PaymentWatchdog{
+		service: service,
+		timeout: timeout,
+		logger:  logger,
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Run cancels the orders that have been waiting longer than the timeout.
+func (w *PaymentWatchdog) Run(ctx context.Context) error {
+	reservedBefore := time.Now().Add(-w.timeout)
+	if !w.paymentServing(ctx) {
+		reservedBefore = reservedBefore.Add(-w.grace)
+	}
+
+	n, err := w.service.ExpireUnpaidOrders(ctx, reservedBefore)
+	if n > 0 {
+		mylogger.Info(ctx, w.logger, "Cancelled unpaid orders", zap.Int("orders", n))
+	}
+
+	return err
+}
+
+// paymentServing reports whether the payment service is up. Without a health
+// check it is assumed to be; a check that fails counts as down.
+func (w *PaymentWatchdog) paymentServing(ctx context.Context) bool {
+	if w.health == nil {
+		return true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, paymentHealthTimeout)
+	defer cancel()
+
+	serving, err := w.health.Serving(ctx)
+	if err != nil || !serving {
+		mylogger.Warn(ctx, w.logger, "Payment service is down, holding unpaid orders", zap.Duration("grace", w.grace), zap.Error(err))
+		return false
+	}
+
+	return true
+}
+
+// ExpireUnpaidOrders cancels the orders reserved before reservedBefore that
+// are still waiting for payment, and returns how many it cancelled. Each order
+// is cancelled in its own transaction, so one failure does not block the rest.
+func (s *orderService) ExpireUnpaidOrders(ctx context.Context, reservedBefore time.Time) (int, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ExpireUnpaidOrders")
+	defer span.End()
+
+	stale, err := s.orderRepo.ListStaleReservations(ctx, reservedBefore, expireBatchSize)
+	if err != nil {
+		span.RecordError(err)
+		return 0, err
+	}
+
+	span.SetAttributes(attribute.Int("stale_orders", len(stale)))
+
+	var expired int
+	var errs []error
+	for _, reservation := range stale {
+		// The events emitted while cancelling belong to the order's tenant.
+		orderCtx := tenant.WithID(ctx, reservation.TenantID)
+
+		ok, err := s.expireUnpaidOrder(orderCtx, reservation.OrderID, reservedBefore)
+		if err != nil {
+			mylogger.Warn(ctx, s.logger, "Failed to expire unpaid order", zap.Int64("order_id", reservation.OrderID), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+
+		if ok {
+			expired++
+		}
+	}
+
+	return expired, errors.Join(errs...)
+}
+
+func (s *orderService) expireUnpaidOrder(ctx context.Context, orderID int64, reservedBefore time.Time) (bool, error) {
+	expired := false
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, orderID)
+		if err != nil {
+			return err
+		}
+
+		// The payment result may have landed between listing and locking.
+		if order.Status != domain.OrderStatusReserved || !order.UpdatedAt.Before(reservedBefore) {
+			return nil
+		}
+
+		if err := s.orderRepo.ChangeOrderStatus(ctx, tx, order.ID, string(domain.OrderStatusCancelled)); err != nil {
+			return err
+		}
+
+		aggregateID := fmt.Sprintf("%d", order.ID)
+
+		err = s.emitEvent(ctx, tx, "payment_events", aggregateID, "PaymentTimedOut", &domain.PaymentTimedOutEvent{
+			OrderID:    order.ID,
+			UserID:     order.UserID,
+			Amount:     order.ReservedAmount,
+			TimedOutAt: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to emit event: %w", err)
+		}
+
+		err = s.emitEvent(ctx, tx, "product_events", aggregateID, "OrderCancelled", &generalDomain.OrderCancelledEvent{
+			OrderID: order.ID,
+			Items:   reservedItems(order),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to emit event: %w", err)
+		}
+
+		err = s.recordTimeline(ctx, tx, order.ID, domain.TimelinePaymentTimedOut, domain.OrderStatusCancelled, "Payment was not received in time, the order was cancelled", true)
+		if err != nil {
+			return err
+		}
+
+		expired = true
+		return nil
+	})
+	if err != nil || !expired {
+		return false, err
+	}
+
+	mylogger.Info(ctx, s.logger, "Order cancelled after payment timeout", zap.Int64("order_id", orderID))
+
+	return true, nil
+}
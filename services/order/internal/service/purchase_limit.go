@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// WithOrderRateLimit lets a user place at most perHour orders within
+// domain.OrderRateWindow. Zero, the default, places no limit.
+func WithOrderRateLimit(perHour int) Option {
+	return func(s *orderService) {
+		s.ordersPerHour = perHour
+	}
+}
+
+// checkOrderLimits enforces the rate limit and the purchase caps on an
+// order a customer places, in the transaction that creates it. The user's
+// orders are locked first, so concurrent orders are counted one after the
+// other rather than each seeing the others missing.
+func (s *orderService) checkOrderLimits(ctx context.Context, tx pgx.Tx, order *domain.Order) error {
+	if err := s.orderRepo.LockUserOrders(ctx, tx, order.UserID); err != nil {
+		return err
+	}
+
+	if s.ordersPerHour > 0 {
+		now := time.Now()
+		recent, err := s.orderRepo.ListRecentOrderTimes(ctx, tx, order.UserID, now.Add(-domain.OrderRateWindow), s.ordersPerHour)
+		if err != nil {
+			return err
+		}
+
+		if len(recent) >= s.ordersPerHour {
+			oldest := recent[len(recent)-1]
+			return &domain.RateLimitError{
+				Limit:      s.ordersPerHour,
+				RetryAfter: max(oldest.Add(domain.OrderRateWindow).Sub(now), 0),
+			}
+		}
+	}
+
+	productIDs := make([]int64, 0, len(order.Items))
+	for _, item := range order.Items {
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	usage, err := s.orderRepo.GetPurchaseCapUsage(ctx, tx, order.UserID, productIDs)
+	if err != nil {
+		return err
+	}
+
+	return domain.CheckPurchaseCaps(order.Items, usage)
+}
+
+// SetPurchaseCap caps a product for every customer of the tenant, or lifts
+// its cap when MaxPerUser is 0. Units bought before the cap was set count
+// towards it.
+func (s *orderService) SetPurchaseCap(ctx context.Context, req *pb.SetPurchaseCapRequest) (*pb.PurchaseCap, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.SetPurchaseCap")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("admin_id", req.AdminId),
+		attribute.Int64("product_id", req.ProductId),
+		attribute.Int64("max_per_user", int64(req.MaxPerUser)),
+	)
+
+	if req.ProductId <= 0 {
+		return nil, fmt.Errorf("%w: product id is required", ErrInvalidPurchaseCap)
+	}
+	if req.MaxPerUser < 0 || req.MaxPerUser > domain.MaxItemQuantity {
+		return nil, fmt.Errorf("%w: max per user must be between 0 and %d", ErrInvalidPurchaseCap, domain.MaxItemQuantity)
+	}
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	if req.MaxPerUser == 0 {
+		if err := s.orderRepo.DeletePurchaseCap(ctx, req.ProductId); err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		mylogger.Info(ctx, s.logger, "Purchase cap lifted", zap.Int64("product_id", req.ProductId), zap.Int64("admin_id", req.AdminId))
+
+		return &pb.PurchaseCap{ProductId: req.ProductId, SetBy: req.AdminId}, nil
+	}
+
+	purchaseCap := &domain.PurchaseCap{
+		ProductID:  req.ProductId,
+		MaxPerUser: req.MaxPerUser,
+		SetBy:      req.AdminId,
+	}
+	if err := s.orderRepo.SetPurchaseCap(ctx, purchaseCap); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Purchase cap set",
+		zap.Int64("product_id", req.ProductId),
+		zap.Int32("max_per_user", req.MaxPerUser),
+		zap.Int64("admin_id", req.AdminId),
+	)
+
+	return purchaseCap.ToPB(), nil
+}
+
+func (s *orderService) ListPurchaseCaps(ctx context.Context, req *pb.ListPurchaseCapsRequest) (*pb.ListPurchaseCapsResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ListPurchaseCaps")
+	defer span.End()
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	caps, err := s.orderRepo.ListPurchaseCaps(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	res := &pb.ListPurchaseCapsResponse{Caps: make([]*pb.PurchaseCap, 0, len(caps))}
+	for i := range caps {
+		res.Caps = append(res.Caps, caps[i].ToPB())
+	}
+
+	return res, nil
+}
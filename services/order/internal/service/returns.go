@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// RequestReturn opens a return of items of a paid or shipped order. The
+// refund is the price the customer paid for the items, fixed now and capped
+// so the returns of an order never add up to more than was charged for it.
+func (s *orderService) RequestReturn(ctx context.Context, req *pb.RequestReturnRequest) (*pb.Return, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.RequestReturn")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", req.OrderId),
+		attribute.Int64("user_id", req.UserId),
+		attribute.Int("lines", len(req.Items)),
+	)
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || utf8.RuneCountInString(reason) > domain.MaxReturnReasonLength {
+		return nil, fmt.Errorf("%w: reason must be between 1 and %d characters", domain.ErrInvalidReturn, domain.MaxReturnReasonLength)
+	}
+
+	lines := make([]domain.ReturnLine, 0, len(req.Items))
+	for _, item := range req.Items {
+		lines = append(lines, domain.ReturnLine{ProductID: item.ProductId, Quantity: item.Quantity})
+	}
+
+	var ret *domain.Return
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, req.OrderId)
+		if err != nil {
+			return err
+		}
+
+		if order.UserID != req.UserId {
+			return repository.ErrOrderNotFound
+		}
+
+		if !order.IsReturnable(time.Now()) {
+			return ErrReturnNotAllowed
+		}
+
+		returned, err := s.orderRepo.ReturnedQuantities(ctx, tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		items, err := domain.PlanReturn(order, returned, lines)
+		if err != nil {
+			return err
+		}
+
+		claimed, err := s.orderRepo.ClaimedRefundAmount(ctx, tx, order.ID)
+		if err != nil {
+			return err
+		}
+
+		ret = &domain.Return{
+			OrderID:      order.ID,
+			UserID:       order.UserID,
+			Status:       domain.ReturnStatusRequested,
+			Reason:       reason,
+			Items:        items,
+			RefundAmount: domain.RefundFor(order, items, claimed),
+		}
+		if err := s.orderRepo.CreateReturn(ctx, tx, ret); err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineReturnRequested, order.Status,
+			fmt.Sprintf("Return #%d was requested", ret.ID), true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Return requested",
+		zap.Int64("return_id", ret.ID),
+		zap.Int64("order_id", ret.OrderID),
+		zap.Int64("refund_amount", ret.RefundAmount),
+	)
+
+	return ret.ToPB(), nil
+}
+
+// ReviewReturn lets an admin approve or reject a requested return. An
+// approved return puts its items back in stock and, unless there is nothing
+// to refund, asks payment for the refund.
+func (s *orderService) ReviewReturn(ctx context.Context, req *pb.ReviewReturnRequest) (*pb.Return, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ReviewReturn")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("admin_id", req.AdminId),
+		attribute.Int64("return_id", req.ReturnId),
+		attribute.Bool("approve", req.Approve),
+	)
+
+	note := strings.TrimSpace(req.Note)
+	if utf8.RuneCountInString(note) > domain.MaxReturnReasonLength {
+		return nil, fmt.Errorf("%w: note must be at most %d characters", domain.ErrInvalidReturn, domain.MaxReturnReasonLength)
+	}
+	if !req.Approve && note == "" {
+		return nil, fmt.Errorf("%w: a rejection needs a note", domain.ErrInvalidReturn)
+	}
+
+	if err := s.requireRole(ctx, req.AdminId, domain.RoleAdmin); err != nil {
+		return nil, err
+	}
+
+	var ret *domain.Return
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		ret, err = s.orderRepo.GetReturn(ctx, tx, req.ReturnId)
+		if err != nil {
+			return err
+		}
+
+		if ret.Status != domain.ReturnStatusRequested {
+			return ErrReturnAlreadyReviewed
+		}
+
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, ret.OrderID)
+		if err != nil {
+			return err
+		}
+
+		ret.Status = domain.ReturnStatusRejected
+		if req.Approve {
+			ret.Status = domain.ReturnStatusApproved
+		}
+		ret.ReviewedBy = &req.AdminId
+		ret.ReviewNote = note
+
+		if err := s.orderRepo.ReviewReturn(ctx, tx, ret); err != nil {
+			span.RecordError(err)
+			return err
+		}
+
+		if !req.Approve {
+			return s.recordTimeline(ctx, tx, order.ID, domain.TimelineReturnRejected, order.Status,
+				fmt.Sprintf("Return #%d was rejected: %s", ret.ID, note), true)
+		}
+
+		if err := s.emitReturnApproved(ctx, tx, ret); err != nil {
+			return err
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineReturnApproved, order.Status,
+			fmt.Sprintf("Return #%d was approved", ret.ID), true)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Return reviewed",
+		zap.Int64("return_id", ret.ID),
+		zap.String("status", string(ret.Status)),
+		zap.Int64("admin_id", req.AdminId),
+	)
+
+	return ret.ToPB(), nil
+}
+
+// emitReturnApproved sends the approved return to product for restocking
+// and, if there is money to give back, to payment for the refund.
+func (s *orderService) emitReturnApproved(ctx context.Context, tx pgx.Tx, ret *domain.Return) error {
+	items := make([]generalDomain.OrderItem, 0, len(ret.Items))
+	for _, item := range ret.Items {
+		items = append(items, generalDomain.OrderItem{
+			ID:        item.OrderItemID,
+			OrderID:   ret.OrderID,
+			ProductID: item.ProductID,
+			Name:      item.Name,
+			Price:     item.Price,
+			Quantity:  item.Quantity,
+		})
+	}
+
+	event := &generalDomain.ReturnApprovedEvent{
+		ReturnID:   ret.ID,
+		OrderID:    ret.OrderID,
+		UserID:     ret.UserID,
+		Items:      items,
+		Amount:     ret.RefundAmount,
+		ApprovedAt: *ret.ReviewedAt,
+	}
+
+	aggregateID := fmt.Sprintf("%d", ret.OrderID)
+	if err := s.emitEvent(ctx, tx, "product_events", aggregateID, "ReturnApproved", event); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	if ret.RefundAmount == 0 {
+		return nil
+	}
+
+	if err := s.emitEvent(ctx, tx, "payment_events", aggregateID, "ReturnApproved", event); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return nil
+}
+
+// ListReturns lists the caller's returns, or with AllUsers those of every
+// customer, which only admins may see.
+func (s *orderService) ListReturns(ctx context.Context, req *pb.ListReturnsRequest) (*pb.ListReturnsResponse, error) {
+	ctx, span := s.tracer.Start(ctx, "OrderService.ListReturns")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", req.UserId),
+		attribute.Bool("all_users", req.AllUsers),
+	)
+
+	status := domain.ReturnStatus(req.Status)
+	if status != "" && !domain.IsKnownReturnStatus(status) {
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidReturn, req.Status)
+	}
+
+	userID := &req.UserId
+	if req.AllUsers {
+		if err := s.requireRole(ctx, req.UserId, domain.RoleAdmin); err != nil {
+			return nil, err
+		}
+
+		userID = nil
+	}
+
+	returns, err := s.orderRepo.ListReturns(ctx, userID, status, domain.MaxListedReturns)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	res := &pb.ListReturnsResponse{Returns: make([]*pb.Return, 0, len(returns))}
+	for i := range returns {
+		res.Returns = append(res.Returns, returns[i].ToPB())
+	}
+
+	return res, nil
+}
+
+// HandleRefundIssued marks a return refunded once payment paid it back.
+// Redeliveries find the return refunded already and change nothing.
+func (s *orderService) HandleRefundIssued(ctx context.Context, event *generalDomain.RefundIssuedEvent) error {
+	ctx, span := s.tracer.Start(ctx, "OrderService.HandleRefundIssued")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("return_id", event.ReturnID),
+		attribute.Int64("order_id", event.OrderID),
+		attribute.Int64("amount", event.Amount),
+	)
+
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		ret, err := s.orderRepo.GetReturn(ctx, tx, event.ReturnID)
+		if err != nil {
+			return err
+		}
+
+		order, err := s.orderRepo.GetOrderByID(ctx, tx, ret.OrderID)
+		if err != nil {
+			return err
+		}
+
+		marked, err := s.orderRepo.MarkReturnRefunded(ctx, tx, ret.ID, event.Amount)
+		if err != nil {
+			return err
+		}
+		if !marked {
+			mylogger.Info(
+				ctx,
+				s.logger,
+				"Refund of a return that is not awaiting one",
+				zap.Int64("return_id", ret.ID),
+				zap.String("status", string(ret.Status)),
+			)
+			return nil
+		}
+
+		return s.recordTimeline(ctx, tx, order.ID, domain.TimelineReturnRefunded, order.Status,
+			fmt.Sprintf("Return #%d was refunded", ret.ID), true)
+	})
+	if errors.Is(err, repository.ErrReturnNotFound) {
+		mylogger.Warn(ctx, s.logger, "Refund of an unknown return", zap.Int64("return_id", event.ReturnID))
+		return nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return err
+	}
+
+	return nil
+}
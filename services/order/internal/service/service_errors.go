@@ -0,0 +1,21 @@
+package service
+
+import "errors"
+
+var (
+	ErrPermissionDenied          = errors.New("permission denied")
+	ErrInvalidChoice             = errors.New("invalid partial reservation choice")
+	ErrOrderNotPartiallyReserved = errors.New("order is not partially reserved")
+	ErrInvalidCursor             = errors.New("invalid cursor")
+	ErrOrderNotCancellable       = errors.New("order can no longer be cancelled")
+	ErrInvalidExportFilter       = errors.New("invalid export filter")
+	ErrInvalidStatusOverride     = errors.New("invalid status override")
+	ErrStatusNotForcible         = errors.New("status cannot be forced")
+	ErrInvalidPriceOverride      = errors.New("invalid price override")
+	ErrShippingUnavailable       = errors.New("delivery fee cannot be quoted")
+	ErrInvalidPurchaseCap        = errors.New("invalid purchase cap")
+	// ErrReturnNotAllowed means the order is not paid or shipped, or was
+	// placed longer than domain.ReturnWindow ago.
+	ErrReturnNotAllowed      = errors.New("order can no longer be returned")
+	ErrReturnAlreadyReviewed = errors.New("return was already reviewed")
+)
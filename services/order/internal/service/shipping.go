@@ -0,0 +1,129 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+)
+
+// ShippingRater quotes the delivery fee of an order about to be placed. The
+// order has its items and shipping address set. Fees are in minor units.
+type ShippingRater interface {
+	Quote(ctx context.Context, order *domain.Order) (int64, error)
+}
+
+// WithShippingRater replaces the default of free delivery.
+func WithShippingRater(rater ShippingRater) Option {
+	return func(s *orderService) {
+		s.shippingRater = rater
+	}
+}
+
+// FlatRate charges the same fee for every order, nothing from FreeFrom on.
+// A zero FreeFrom never waives the fee.
+type FlatRate struct {
+	Fee      int64
+	FreeFrom int64
+}
+
+func (r FlatRate) Quote(_ context.Context, order *domain.Order) (int64, error) {
+	if r.FreeFrom > 0 && order.Subtotal() >= r.FreeFrom {
+		return 0, nil
+	}
+
+	return r.Fee, nil
+}
+
+// WeightRate charges Base plus PerKg for every started kilogram. Items the
+// client gave no weight for count as weightless.
+type WeightRate struct {
+	Base  int64
+	PerKg int64
+}
+
+func (r WeightRate) Quote(_ context.Context, order *domain.Order) (int64, error) {
+	kilograms := (order.WeightGrams() + 999) / 1000
+
+	return r.Base + r.PerKg*kilograms, nil
+}
+
+const carrierTimeout = 3 * time.Second
+
+type carrierQuoteRequest struct {
+	Address     *domain.ShippingAddress `json:"address"`
+	WeightGrams int64                   `json:"weight_grams"`
+	Subtotal    int64                   `json:"subtotal"`
+}
+
+type carrierQuoteResponse struct {
+	Fee int64 `json:"fee"`
+}
+
+// CarrierRate asks a carrier's rate API. It posts the address, weight and
+// subtotal of the order as JSON and expects {"fee": <minor units>} back.
+type CarrierRate struct {
+	url    string
+	client *http.Client
+}
+
+func NewCarrierRate(url string) *CarrierRate {
+	return &CarrierRate{
+		url:    url,
+		client: &http.Client{Timeout: carrierTimeout},
+	}
+}
+
+func (r *CarrierRate) Quote(ctx context.Context, order *domain.Order) (int64, error) {
+	body, err := json.Marshal(carrierQuoteRequest{
+		Address:     order.ShippingAddress,
+		WeightGrams: order.WeightGrams(),
+		Subtotal:    order.Subtotal(),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("carrier answered %s", res.Status)
+	}
+
+	var quote carrierQuoteResponse
+	if err := json.NewDecoder(res.Body).Decode(&quote); err != nil {
+		return 0, fmt.Errorf("invalid carrier quote: %w", err)
+	}
+
+	return quote.Fee, nil
+}
+
+// quoteDelivery sets the delivery fee of a new order. A rater that fails or
+// quotes nonsense blocks the order rather than shipping it at a wrong price.
+func (s *orderService) quoteDelivery(ctx context.Context, order *domain.Order) error {
+	fee, err := s.shippingRater.Quote(ctx, order)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrShippingUnavailable, err)
+	}
+
+	if fee < 0 || fee > domain.MaxDeliveryFee {
+		return fmt.Errorf("%w: fee %d is out of range", ErrShippingUnavailable, fee)
+	}
+
+	order.DeliveryFee = fee
+	return nil
+}
@@ -1,12 +1,95 @@
 package grpc
 
 import (
+	"errors"
+	"strconv"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain is the ErrorInfo domain of the details order attaches.
+const errorDomain = "order"
+
+// ErrorInfo reasons of the order limits.
+const (
+	reasonOrderRateLimited    = "ORDER_RATE_LIMITED"
+	reasonPurchaseCapExceeded = "PURCHASE_CAP_EXCEEDED"
 )
 
 func mapErrorCode(err error) codes.Code {
 	switch {
+	case errors.Is(err, repository.ErrOrderNotFound), errors.Is(err, repository.ErrInvoiceNotFound),
+		errors.Is(err, repository.ErrAddressNotFound), errors.Is(err, repository.ErrReturnNotFound),
+		errors.Is(err, repository.ErrAnnotationNotFound):
+		return codes.NotFound
+	case errors.Is(err, service.ErrPermissionDenied):
+		return codes.PermissionDenied
+	case errors.Is(err, service.ErrInvalidChoice), errors.Is(err, service.ErrInvalidCursor), errors.Is(err, domain.ErrInvalidOrder),
+		errors.Is(err, service.ErrInvalidExportFilter), errors.Is(err, service.ErrInvalidStatusOverride),
+		errors.Is(err, domain.ErrInvalidAddress), errors.Is(err, service.ErrInvalidPriceOverride),
+		errors.Is(err, service.ErrInvalidPurchaseCap), errors.Is(err, domain.ErrInvalidReturn),
+		errors.Is(err, domain.ErrInvalidAnnotation):
+		return codes.InvalidArgument
+	case errors.Is(err, domain.ErrOrderRateLimited):
+		return codes.ResourceExhausted
+	case errors.Is(err, service.ErrOrderNotPartiallyReserved), errors.Is(err, service.ErrOrderNotCancellable),
+		errors.Is(err, service.ErrStatusNotForcible), errors.Is(err, repository.ErrStatusConflict),
+		errors.Is(err, repository.ErrAddressBookFull), errors.Is(err, domain.ErrPurchaseCapExceeded),
+		errors.Is(err, service.ErrReturnNotAllowed), errors.Is(err, service.ErrReturnAlreadyReviewed):
+		return codes.FailedPrecondition
+	case errors.Is(err, service.ErrShippingUnavailable):
+		return codes.Unavailable
 	default:
 		return codes.Internal
 	}
 }
+
+// limitStatus is the status of an order refused by the rate limit or a
+// purchase cap, with the details a client needs to tell the user when to
+// retry or how many units are left. ok is false for any other error.
+func limitStatus(err error) (st *status.Status, ok bool) {
+	var rateLimit *domain.RateLimitError
+	var purchaseCap *domain.PurchaseCapError
+
+	var details []protoadapt.MessageV1
+	switch {
+	case errors.As(err, &rateLimit):
+		st = status.New(codes.ResourceExhausted, err.Error())
+		details = []protoadapt.MessageV1{
+			&errdetails.ErrorInfo{
+				Reason:   reasonOrderRateLimited,
+				Domain:   errorDomain,
+				Metadata: map[string]string{"limit": strconv.Itoa(rateLimit.Limit)},
+			},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(rateLimit.RetryAfter)},
+		}
+	case errors.As(err, &purchaseCap):
+		st = status.New(codes.FailedPrecondition, err.Error())
+		details = []protoadapt.MessageV1{
+			&errdetails.ErrorInfo{
+				Reason: reasonPurchaseCapExceeded,
+				Domain: errorDomain,
+				Metadata: map[string]string{
+					"product_id": strconv.FormatInt(purchaseCap.ProductID, 10),
+					"cap":        strconv.Itoa(int(purchaseCap.Cap)),
+					"remaining":  strconv.Itoa(int(purchaseCap.Remaining)),
+				},
+			},
+		}
+	default:
+		return nil, false
+	}
+
+	if detailed, detailErr := st.WithDetails(details...); detailErr == nil {
+		st = detailed
+	}
+
+	return st, true
+}
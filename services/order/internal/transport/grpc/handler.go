@@ -4,8 +4,10 @@ import (
 	"context"
 
 	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
 	pb "github.com/sakashimaa/go-pet-project/proto/order"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
@@ -20,6 +22,10 @@ func NewOrderHandler(service service.OrderService, logger *zap.Logger) *OrderHan
 }
 
 func (h *OrderHandler) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.CreateOrderResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId, authctx.RequireActivated); err != nil {
+		return nil, err
+	}
+
 	res, err := h.service.CreateOrder(ctx, req)
 
 	if err != nil {
@@ -32,8 +38,475 @@ func (h *OrderHandler) CreateOrder(ctx context.Context, req *pb.CreateOrderReque
 			zap.Error(err),
 		)
 
+		if code == codes.InvalidArgument {
+			return nil, status.Error(code, err.Error())
+		}
+		if st, ok := limitStatus(err); ok {
+			return nil, st.Err()
+		}
+
 		return nil, status.Error(code, code.String())
 	}
 
 	return &pb.CreateOrderResponse{OrderId: res.OrderId}, nil
 }
+
+func (h *OrderHandler) ResolvePartialReservation(ctx context.Context, req *pb.ResolvePartialReservationRequest) (*pb.ResolvePartialReservationResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId, authctx.RequireActivated); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ResolvePartialReservation(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"resolve partial reservation failed",
+			zap.String("method", "ResolvePartialReservation"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) GetOrderTimeline(ctx context.Context, req *pb.GetOrderTimelineRequest) (*pb.GetOrderTimelineResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.GetOrderTimeline(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"get order timeline failed",
+			zap.String("method", "GetOrderTimeline"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) ListUserOrders(ctx context.Context, req *pb.ListUserOrdersRequest) (*pb.ListUserOrdersResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ListUserOrders(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"list user orders failed",
+			zap.String("method", "ListUserOrders"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.CancelOrderResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.CancelOrderByUser(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"cancel order failed",
+			zap.String("method", "CancelOrder"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) GetInvoice(ctx context.Context, req *pb.GetInvoiceRequest) (*pb.GetInvoiceResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.GetInvoice(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"get invoice failed",
+			zap.String("method", "GetInvoice"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) ExportOrders(req *pb.ExportOrdersRequest, stream pb.OrderService_ExportOrdersServer) error {
+	err := h.service.ExportOrders(stream.Context(), req, func(csv []byte) error {
+		return stream.Send(&pb.ExportOrdersChunk{Csv: csv})
+	})
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"export orders failed",
+			zap.String("method", "ExportOrders"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return status.Error(code, err.Error())
+	}
+
+	return nil
+}
+
+func (h *OrderHandler) ForceOrderStatus(ctx context.Context, req *pb.ForceOrderStatusRequest) (*pb.ForceOrderStatusResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ForceOrderStatus(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"force order status failed",
+			zap.String("method", "ForceOrderStatus"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) CreateManualOrder(ctx context.Context, req *pb.CreateManualOrderRequest) (*pb.CreateOrderResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.CreateManualOrder(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"create manual order failed",
+			zap.String("method", "CreateManualOrder"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) AddAddress(ctx context.Context, req *pb.AddAddressRequest) (*pb.Address, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.AddAddress(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"add address failed",
+			zap.String("method", "AddAddress"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) ListAddresses(ctx context.Context, req *pb.ListAddressesRequest) (*pb.ListAddressesResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ListAddresses(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"list addresses failed",
+			zap.String("method", "ListAddresses"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) UpdateAddress(ctx context.Context, req *pb.UpdateAddressRequest) (*pb.Address, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.UpdateAddress(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"update address failed",
+			zap.String("method", "UpdateAddress"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) DeleteAddress(ctx context.Context, req *pb.DeleteAddressRequest) (*pb.DeleteAddressResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.DeleteAddress(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"delete address failed",
+			zap.String("method", "DeleteAddress"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) SetPurchaseCap(ctx context.Context, req *pb.SetPurchaseCapRequest) (*pb.PurchaseCap, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.SetPurchaseCap(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"set purchase cap failed",
+			zap.String("method", "SetPurchaseCap"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) ListPurchaseCaps(ctx context.Context, req *pb.ListPurchaseCapsRequest) (*pb.ListPurchaseCapsResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ListPurchaseCaps(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"list purchase caps failed",
+			zap.String("method", "ListPurchaseCaps"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) RequestReturn(ctx context.Context, req *pb.RequestReturnRequest) (*pb.Return, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.RequestReturn(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"request return failed",
+			zap.String("method", "RequestReturn"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) ReviewReturn(ctx context.Context, req *pb.ReviewReturnRequest) (*pb.Return, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ReviewReturn(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"review return failed",
+			zap.String("method", "ReviewReturn"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) ListReturns(ctx context.Context, req *pb.ListReturnsRequest) (*pb.ListReturnsResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ListReturns(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"list returns failed",
+			zap.String("method", "ListReturns"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) AddOrderNote(ctx context.Context, req *pb.AddOrderNoteRequest) (*pb.OrderAnnotation, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.AddOrderNote(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"add order note failed",
+			zap.String("method", "AddOrderNote"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) SetOrderFlag(ctx context.Context, req *pb.SetOrderFlagRequest) (*pb.OrderAnnotation, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.SetOrderFlag(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"set order flag failed",
+			zap.String("method", "SetOrderFlag"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) ClearOrderFlag(ctx context.Context, req *pb.ClearOrderFlagRequest) (*pb.OrderAnnotation, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ClearOrderFlag(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"clear order flag failed",
+			zap.String("method", "ClearOrderFlag"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
+
+func (h *OrderHandler) ListOrderAnnotations(ctx context.Context, req *pb.ListOrderAnnotationsRequest) (*pb.ListOrderAnnotationsResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	res, err := h.service.ListOrderAnnotations(ctx, req)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"list order annotations failed",
+			zap.String("method", "ListOrderAnnotations"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, err.Error())
+	}
+
+	return res, nil
+}
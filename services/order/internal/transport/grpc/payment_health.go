@@ -0,0 +1,30 @@
+package grpc
+
+import (
+	"context"
+
+	paymentPb "github.com/sakashimaa/go-pet-project/proto/payment"
+	googleGrpc "google.golang.org/grpc"
+	healthPb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// PaymentHealth asks the payment service's health service whether
+// PaymentService is serving, which it only is while its consumer is alive.
+type PaymentHealth struct {
+	client healthPb.HealthClient
+}
+
+func NewPaymentHealth(conn googleGrpc.ClientConnInterface) *PaymentHealth {
+	return &PaymentHealth{client: healthPb.NewHealthClient(conn)}
+}
+
+func (h *PaymentHealth) Serving(ctx context.Context) (bool, error) {
+	res, err := h.client.Check(ctx, &healthPb.HealthCheckRequest{
+		Service: paymentPb.PaymentService_ServiceDesc.ServiceName,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return res.GetStatus() == healthPb.HealthCheckResponse_SERVING, nil
+}
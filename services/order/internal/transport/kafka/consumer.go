@@ -2,102 +2,67 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 
-	"github.com/IBM/sarama"
-	"github.com/sakashimaa/go-pet-project/order/internal/domain"
 	"github.com/sakashimaa/go-pet-project/order/internal/service"
 	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/kafka"
-	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	"go.uber.org/zap"
 )
 
+// GroupID is the consumer group the service reads with.
+const GroupID = "order-service-group-v2"
+
+// Topics are the topics GroupID subscribes to.
+var Topics = []string{"order_events", "user_events", "payment_events"}
+
 type Consumer struct {
 	service service.OrderService
+	router  *kafka.Router
 	logger  *zap.Logger
 }
 
 func NewConsumer(service service.OrderService, logger *zap.Logger) *Consumer {
-	return &Consumer{
+	c := &Consumer{
 		service: service,
 		logger:  logger,
 	}
+
+	c.router = kafka.NewRouter(logger, kafka.StrictPayloads()).
+		Register(
+			kafka.On("UserRegistered", service.HandleUserRegistered),
+			kafka.On("UserRoleChanged", service.HandleUserRoleChanged),
+			kafka.On("InventoryReserved", service.HandleInventoryReserved),
+			kafka.On("InventoryPartiallyReserved", service.HandleInventoryPartiallyReserved),
+			kafka.On("ShipmentUpdated", service.HandleShipmentUpdated),
+			kafka.On("PaymentSucceeded", c.handlePaymentSucceeded),
+			kafka.On("PaymentFailed", service.CancelOrder),
+			kafka.On("RefundIssued", service.HandleRefundIssued),
+		).
+		// PaymentTimedOut is emitted by our own payment watchdog for the
+		// payment service, InvoiceGenerated for notification and analytics,
+		// OrderPaid for the product service and ReturnApproved for payment.
+		Ignore("PaymentTimedOut", "InvoiceGenerated", "OrderPaid", "ReturnApproved")
+
+	return c
 }
 
-func (c *Consumer) Start(ctx context.Context, brokers []string) {
+func (c *Consumer) Start(ctx context.Context, config kafka.Config, opts ...kafka.ConsumerOption) {
 	consumerGroup := kafka.NewConsumerGroup(
-		brokers,
-		"order-service-group-v2",
-		[]string{"order_events", "user_events", "payment_events"},
-		c.processMessage,
+		config,
+		GroupID,
+		Topics,
+		c.router.Route,
 		c.logger,
+		opts...,
 	)
 
 	consumerGroup.Run(ctx)
 }
 
-func (c *Consumer) processMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
-	mylogger.Info(
-		ctx,
-		c.logger,
-		"Processing message",
-		zap.String("topic", msg.Topic),
-	)
-
-	type EventWrapper struct {
-		Event   string          `json:"event"`
-		Payload json.RawMessage `json:"payload"`
-	}
-
-	var wrapper EventWrapper
-	if err := json.Unmarshal(msg.Value, &wrapper); err != nil {
-		mylogger.Error(ctx, c.logger, "Error unmarshalling wrapper", zap.Error(err))
+func (c *Consumer) handlePaymentSucceeded(ctx context.Context, event *generalDomain.PaymentSucceededEvent) error {
+	if err := c.service.ChangeOrderStatusPaymentSucceeded(ctx, event); err != nil {
 		return err
 	}
 
-	switch wrapper.Event {
-	case "UserRegistered":
-		var event domain.UserRegisteredEvent
-		if err := json.Unmarshal(wrapper.Payload, &event); err != nil {
-			mylogger.Error(ctx, c.logger, "Failed to unmarshal event", zap.Error(err))
-			return err
-		}
-
-		err := c.service.HandleUserRegistered(ctx, &event)
-		if err != nil {
-			mylogger.Error(ctx, c.logger, "Failed to handle register event", zap.Error(err))
-			return err
-		}
-
-		return nil
-	case "PaymentSucceeded":
-		var event generalDomain.PaymentSucceededEvent
-		if err := json.Unmarshal(wrapper.Payload, &event); err != nil {
-			mylogger.Error(ctx, c.logger, "Failed to unmarshal payload", zap.Error(err))
-			return err
-		}
-
-		err := c.service.ChangeOrderStatusPaymentSucceeded(ctx, &event)
-		if err != nil {
-			mylogger.Error(ctx, c.logger, "Failed to change order status", zap.Error(err))
-			return err
-		}
-	case "PaymentFailed":
-		var event generalDomain.PaymentFailedEvent
-		if err := json.Unmarshal(wrapper.Payload, &event); err != nil {
-			mylogger.Error(ctx, c.logger, "Failed to unmarshal payload", zap.Error(err))
-			return err
-		}
-
-		err := c.service.CancelOrder(ctx, &event)
-		if err != nil {
-			mylogger.Error(ctx, c.logger, "Failed to cancel order", zap.Error(err))
-			return err
-		}
-	default:
-		mylogger.Warn(ctx, c.logger, "Ignored event type", zap.String("event_type", wrapper.Event))
-	}
-
-	return nil
+	return c.service.GenerateInvoice(ctx, event.OrderID)
 }
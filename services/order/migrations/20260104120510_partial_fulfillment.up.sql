@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE order_items
+ADD COLUMN status VARCHAR(32) NOT NULL DEFAULT 'pending';
+
+ALTER TABLE orders
+ADD COLUMN reserved_amount BIGINT NOT NULL DEFAULT 0,
+ADD COLUMN fulfillment_choice VARCHAR(32);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_order_items_order_id;
+--
+-- ALTER TABLE orders
+-- DROP COLUMN fulfillment_choice,
+-- DROP COLUMN reserved_amount;
+--
+-- ALTER TABLE order_items
+-- DROP COLUMN status;
+-- +goose StatementEnd
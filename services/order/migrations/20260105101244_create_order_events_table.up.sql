@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS order_events (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    event_type VARCHAR(64) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    visible_to_customer BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_events_order_id ON order_events(order_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_order_events_order_id;
+-- DROP TABLE IF EXISTS order_events;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_orders_reserved_updated_at ON orders(updated_at) WHERE status = 'reserved';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_orders_reserved_updated_at;
+-- +goose StatementEnd
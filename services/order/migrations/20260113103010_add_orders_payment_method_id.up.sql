@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The saved payment method lives in the payment service, so this is only a
+-- reference that is forwarded with the order's events.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS payment_method_id BIGINT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE orders DROP COLUMN IF EXISTS payment_method_id;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Numbers are allocated inside the invoice's transaction, so a rolled back
+-- invoice gives its number back and the yearly sequence has no gaps.
+CREATE TABLE IF NOT EXISTS invoice_sequences (
+    year INT PRIMARY KEY,
+    last_value BIGINT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+    user_id BIGINT NOT NULL,
+    number VARCHAR(32) NOT NULL,
+    lines JSONB NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    tax_rate_bps BIGINT NOT NULL,
+    net_total BIGINT NOT NULL,
+    tax_total BIGINT NOT NULL,
+    total BIGINT NOT NULL,
+    object_key TEXT NOT NULL,
+    issued_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_order_id_key ON invoices(order_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_number_key ON invoices(number);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS invoices;
+-- DROP TABLE IF EXISTS invoice_sequences;
+-- +goose StatementEnd
@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE orders
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_orders_tenant_user_created
+    ON orders(tenant_id, user_id, created_at DESC, id DESC);
+
+-- Every storefront numbers its invoices on its own.
+ALTER TABLE invoice_sequences
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+ALTER TABLE invoice_sequences DROP CONSTRAINT IF EXISTS invoice_sequences_pkey;
+ALTER TABLE invoice_sequences ADD PRIMARY KEY (tenant_id, year);
+
+DROP INDEX IF EXISTS idx_invoices_number_key;
+ALTER TABLE invoices
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_number_key ON invoices(tenant_id, number);
+
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE outbox DROP COLUMN tenant_id;
+--
+-- DROP INDEX IF EXISTS idx_invoices_number_key;
+-- ALTER TABLE invoices DROP COLUMN tenant_id;
+-- CREATE UNIQUE INDEX idx_invoices_number_key ON invoices(number);
+--
+-- ALTER TABLE invoice_sequences DROP CONSTRAINT invoice_sequences_pkey;
+-- ALTER TABLE invoice_sequences DROP COLUMN tenant_id;
+-- ALTER TABLE invoice_sequences ADD PRIMARY KEY (year);
+--
+-- DROP INDEX IF EXISTS idx_orders_tenant_user_created;
+-- ALTER TABLE orders DROP COLUMN tenant_id;
+-- ALTER TABLE users DROP COLUMN tenant_id;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Serves the accounting export, which walks a tenant's orders by creation
+-- time in keyset pages.
+CREATE INDEX IF NOT EXISTS idx_orders_tenant_created_at ON orders(tenant_id, created_at, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_orders_tenant_created_at;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every status an admin forced by hand, who did it and why. Rows are never
+-- updated or deleted.
+CREATE TABLE IF NOT EXISTS order_status_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+    admin_id BIGINT NOT NULL,
+    from_status VARCHAR(32) NOT NULL,
+    to_status VARCHAR(32) NOT NULL,
+    reason TEXT NOT NULL,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_overrides_order_id ON order_status_overrides(order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS order_status_overrides;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS addresses (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    label VARCHAR(50) NOT NULL DEFAULT '',
+    recipient VARCHAR(100) NOT NULL,
+    phone VARCHAR(32) NOT NULL DEFAULT '',
+    line1 VARCHAR(200) NOT NULL,
+    line2 VARCHAR(200) NOT NULL DEFAULT '',
+    city VARCHAR(100) NOT NULL,
+    region VARCHAR(100) NOT NULL DEFAULT '',
+    postal_code VARCHAR(20) NOT NULL,
+    country VARCHAR(2) NOT NULL,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_addresses_tenant_user ON addresses(tenant_id, user_id);
+
+-- Orders copy the address they ship to, so the address book can change
+-- without rewriting where past orders went.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_address JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE orders DROP COLUMN IF EXISTS shipping_address;
+-- DROP TABLE IF EXISTS addresses;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The delivery fee is kept apart from the items it ships, and total_sum
+-- includes it.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_fee BIGINT NOT NULL DEFAULT 0;
+
+-- Weight of one unit in grams, for weight-based delivery rates.
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS weight_grams INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE order_items DROP COLUMN IF EXISTS weight_grams;
+-- ALTER TABLE orders DROP COLUMN IF EXISTS delivery_fee;
+-- +goose StatementEnd
@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS discount BIGINT NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS placed_by BIGINT;
+
+-- Every price support set on a manual order, who set it and why. A NULL
+-- product_id is the discount on the whole order. Rows are never updated or
+-- deleted.
+CREATE TABLE IF NOT EXISTS order_price_overrides (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+    admin_id BIGINT NOT NULL,
+    product_id BIGINT,
+    original_amount BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    reason_code VARCHAR(32) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_price_overrides_order_id ON order_price_overrides(order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS order_price_overrides;
+-- ALTER TABLE orders DROP COLUMN IF EXISTS placed_by, DROP COLUMN IF EXISTS discount;
+-- +goose StatementEnd
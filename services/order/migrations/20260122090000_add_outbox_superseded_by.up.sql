@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Set on events the outbox worker skipped because a later event of the same
+-- type for the same aggregate replaces them. Such events also get
+-- published_at, so nothing waits on them.
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS superseded_by BIGINT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE outbox DROP COLUMN superseded_by;
+-- +goose StatementEnd
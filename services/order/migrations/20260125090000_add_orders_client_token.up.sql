@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- client_token is generated by the client once per checkout, so a submit
+-- retried after a timeout or a double click finds the order it already
+-- placed. Tokens only have to be unique per customer.
+ALTER TABLE orders
+    ADD COLUMN IF NOT EXISTS client_token UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_client_token
+    ON orders(tenant_id, user_id, client_token)
+    WHERE client_token IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_orders_client_token;
+-- ALTER TABLE orders DROP COLUMN client_token;
+-- +goose StatementEnd
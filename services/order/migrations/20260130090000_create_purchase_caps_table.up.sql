@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- How many units of a product one customer may buy across all their orders,
+-- for limited releases. Products without a row are not capped.
+CREATE TABLE IF NOT EXISTS purchase_caps (
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    product_id BIGINT NOT NULL,
+    max_per_user INT NOT NULL CHECK (max_per_user > 0),
+    set_by BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, product_id)
+);
+
+-- Serves the caps, which sum what a user already bought of a product.
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id ON order_items(product_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_order_items_product_id;
+-- DROP TABLE IF EXISTS purchase_caps;
+-- +goose StatementEnd
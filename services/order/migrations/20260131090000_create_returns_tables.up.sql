@@ -0,0 +1,40 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Items customers send back. refund_amount is fixed when the return is
+-- requested; refunded_amount is what payment actually paid back.
+CREATE TABLE IF NOT EXISTS order_returns (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+    user_id BIGINT NOT NULL,
+    status VARCHAR(32) NOT NULL,
+    reason TEXT NOT NULL,
+    refund_amount BIGINT NOT NULL CHECK (refund_amount >= 0),
+    refunded_amount BIGINT,
+    reviewed_by BIGINT,
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    refunded_at TIMESTAMP WITH TIME ZONE,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_returns_order_id ON order_returns(order_id);
+CREATE INDEX IF NOT EXISTS idx_order_returns_user_id ON order_returns(tenant_id, user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_order_returns_status ON order_returns(tenant_id, status, created_at);
+
+CREATE TABLE IF NOT EXISTS order_return_items (
+    return_id BIGINT NOT NULL REFERENCES order_returns(id) ON DELETE CASCADE,
+    order_item_id BIGINT NOT NULL REFERENCES order_items(id),
+    product_id BIGINT NOT NULL,
+    name TEXT NOT NULL,
+    price BIGINT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (return_id, order_item_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS order_return_items;
+-- DROP TABLE IF EXISTS order_returns;
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Notes and flags support puts on orders. They are never shown to the
+-- customer. Rows are never deleted: clearing a flag stamps who cleared it
+-- and when, so the history of an order stays complete.
+CREATE TABLE IF NOT EXISTS order_annotations (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id),
+    kind VARCHAR(16) NOT NULL,
+    flag VARCHAR(32),
+    body TEXT NOT NULL DEFAULT '',
+    author_id BIGINT NOT NULL,
+    cleared_by BIGINT,
+    cleared_at TIMESTAMP WITH TIME ZONE,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK ((kind = 'note' AND flag IS NULL) OR (kind = 'flag' AND flag IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_annotations_order_id ON order_annotations(order_id, created_at DESC);
+
+-- An order carries each flag at most once at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_order_annotations_active_flag_key
+    ON order_annotations(tenant_id, order_id, flag)
+    WHERE kind = 'flag' AND cleared_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS order_annotations;
+-- +goose StatementEnd
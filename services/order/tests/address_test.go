@@ -0,0 +1,136 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) addAddress(ctx context.Context, userId int64) int64 {
+	address, err := s.OrderService.AddAddress(ctx, &pb.AddAddressRequest{
+		UserId: userId,
+		Address: &pb.Address{
+			Label:      "Home",
+			Recipient:  "Anna Petrova",
+			Line1:      "12 Sadovaya Street",
+			City:       "Saint Petersburg",
+			PostalCode: "190000",
+			Country:    "ru",
+		},
+	})
+	s.Require().NoError(err)
+
+	return address.Id
+}
+
+func (s *IntegrationTestSuite) TestAddressBook_CRUD() {
+	s.seedData(999, "test@example.com")
+	id := s.addAddress(s.Ctx, 999)
+
+	list, err := s.OrderService.ListAddresses(s.Ctx, &pb.ListAddressesRequest{UserId: 999})
+	s.Require().NoError(err)
+	s.Require().Len(list.Addresses, 1)
+	s.Require().Equal("RU", list.Addresses[0].Country, "Country codes are stored upper-case")
+
+	updated := list.Addresses[0]
+	updated.Line1 = "14 Sadovaya Street"
+	_, err = s.OrderService.UpdateAddress(s.Ctx, &pb.UpdateAddressRequest{UserId: 999, Address: updated})
+	s.Require().NoError(err)
+
+	_, err = s.OrderService.UpdateAddress(s.Ctx, &pb.UpdateAddressRequest{UserId: 1000, Address: updated})
+	s.Require().ErrorIs(err, repository.ErrAddressNotFound, "Other users' addresses are invisible")
+
+	_, err = s.OrderService.DeleteAddress(s.Ctx, &pb.DeleteAddressRequest{Id: id, UserId: 1000})
+	s.Require().ErrorIs(err, repository.ErrAddressNotFound)
+
+	_, err = s.OrderService.DeleteAddress(s.Ctx, &pb.DeleteAddressRequest{Id: id, UserId: 999})
+	s.Require().NoError(err)
+
+	list, err = s.OrderService.ListAddresses(s.Ctx, &pb.ListAddressesRequest{UserId: 999})
+	s.Require().NoError(err)
+	s.Require().Empty(list.Addresses)
+}
+
+func (s *IntegrationTestSuite) TestAddressBook_RejectsInvalidAddresses() {
+	valid := func() *pb.Address {
+		return &pb.Address{Recipient: "Anna Petrova", Line1: "12 Sadovaya Street", City: "Moscow", PostalCode: "101000", Country: "RU"}
+	}
+
+	cases := map[string]func(a *pb.Address){
+		"no recipient":    func(a *pb.Address) { a.Recipient = "  " },
+		"no street":       func(a *pb.Address) { a.Line1 = "" },
+		"bad country":     func(a *pb.Address) { a.Country = "Russia" },
+		"bad postal code": func(a *pb.Address) { a.PostalCode = "<script>" },
+		"bad phone":       func(a *pb.Address) { a.Phone = "call me" },
+		"recipient too long": func(a *pb.Address) {
+			a.Recipient = fmt.Sprintf("%0101d", 0)
+		},
+	}
+
+	for name, mutate := range cases {
+		address := valid()
+		mutate(address)
+
+		_, err := s.OrderService.AddAddress(s.Ctx, &pb.AddAddressRequest{UserId: 999, Address: address})
+		s.Require().ErrorIs(err, domain.ErrInvalidAddress, name)
+	}
+}
+
+func (s *IntegrationTestSuite) TestAddressBook_IsCapped() {
+	for range domain.MaxAddressesPerUser {
+		s.addAddress(s.Ctx, 999)
+	}
+
+	_, err := s.OrderService.AddAddress(s.Ctx, &pb.AddAddressRequest{
+		UserId:  999,
+		Address: &pb.Address{Recipient: "Anna", Line1: "1 Main Street", City: "Moscow", PostalCode: "101000", Country: "RU"},
+	})
+	s.Require().ErrorIs(err, repository.ErrAddressBookFull)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_SnapshotsShippingAddress() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+
+	// Editing the address book afterwards leaves the order where it was.
+	_, err := s.DbPool.Exec(s.Ctx, `UPDATE addresses SET city = 'Moscow'`)
+	s.Require().NoError(err)
+
+	var raw []byte
+	err = s.DbPool.QueryRow(s.Ctx, `SELECT shipping_address FROM orders WHERE id = $1`, resp.OrderId).Scan(&raw)
+	s.Require().NoError(err)
+
+	var snapshot domain.ShippingAddress
+	s.Require().NoError(json.Unmarshal(raw, &snapshot))
+	s.Require().Equal("Saint Petersburg", snapshot.City)
+
+	var payload []byte
+	err = s.DbPool.QueryRow(s.Ctx, `SELECT payload FROM outbox WHERE aggregate_id = $1 AND event_type = 'OrderCreated'`, fmt.Sprintf("%d", resp.OrderId)).
+		Scan(&payload)
+	s.Require().NoError(err)
+
+	var envelope struct {
+		Payload struct {
+			ShippingAddress domain.ShippingAddress `json:"shipping_address"`
+		} `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &envelope))
+	s.Require().Equal("190000", envelope.Payload.ShippingAddress.PostalCode)
+	s.Require().Equal("RU", envelope.Payload.ShippingAddress.Country)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_RequiresShippingAddress() {
+	s.seedData(999, "test@example.com")
+	item := &pb.OrderItem{ProductId: 1, Name: "Kuronami No Yaiba", Price: 5350, Quantity: 1}
+
+	_, err := s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{UserId: 999, Items: []*pb.OrderItem{item}})
+	s.Require().ErrorIs(err, domain.ErrInvalidOrder)
+
+	foreign := s.addAddress(s.Ctx, 1000)
+	_, err = s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{UserId: 999, Items: []*pb.OrderItem{item}, ShippingAddressId: foreign})
+	s.Require().ErrorIs(err, repository.ErrAddressNotFound)
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) timelineEventTypes(orderId, userId int64, internal bool) []string {
+	timeline, err := s.OrderService.GetOrderTimeline(s.Ctx, &pb.GetOrderTimelineRequest{
+		OrderId:         orderId,
+		UserId:          userId,
+		IncludeInternal: internal,
+	})
+	s.Require().NoError(err)
+
+	types := make([]string, 0, len(timeline.Entries))
+	for _, entry := range timeline.Entries {
+		types = append(types, entry.EventType)
+	}
+
+	return types
+}
+
+func (s *IntegrationTestSuite) TestOrderAnnotations_NotesAndFlags() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	resp := s.createOrder(999)
+
+	note, err := s.OrderService.AddOrderNote(s.Ctx, &pb.AddOrderNoteRequest{
+		AdminId: 1,
+		OrderId: resp.OrderId,
+		Body:    "  Customer called about the delivery date  ",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.AnnotationNote), note.Kind)
+	s.Require().Equal("Customer called about the delivery date", note.Body)
+	s.Require().Equal(int64(1), note.AuthorId)
+
+	flag, err := s.OrderService.SetOrderFlag(s.Ctx, &pb.SetOrderFlagRequest{
+		AdminId: 1,
+		OrderId: resp.OrderId,
+		Flag:    string(domain.FlagFraudSuspect),
+		Comment: "Billing and shipping countries differ",
+	})
+	s.Require().NoError(err)
+
+	again, err := s.OrderService.SetOrderFlag(s.Ctx, &pb.SetOrderFlagRequest{
+		AdminId: 1,
+		OrderId: resp.OrderId,
+		Flag:    string(domain.FlagFraudSuspect),
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(flag.Id, again.Id, "A flag already set is returned as it is")
+
+	list, err := s.OrderService.ListOrderAnnotations(s.Ctx, &pb.ListOrderAnnotationsRequest{AdminId: 1, OrderId: resp.OrderId})
+	s.Require().NoError(err)
+	s.Require().Len(list.Annotations, 2)
+	s.Require().Equal([]string{string(domain.FlagFraudSuspect)}, list.ActiveFlags)
+
+	cleared, err := s.OrderService.ClearOrderFlag(s.Ctx, &pb.ClearOrderFlagRequest{
+		AdminId: 1,
+		OrderId: resp.OrderId,
+		Flag:    string(domain.FlagFraudSuspect),
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(flag.Id, cleared.Id)
+	s.Require().Equal(int64(1), cleared.ClearedBy)
+	s.Require().NotEmpty(cleared.ClearedAt)
+
+	list, err = s.OrderService.ListOrderAnnotations(s.Ctx, &pb.ListOrderAnnotationsRequest{AdminId: 1, OrderId: resp.OrderId})
+	s.Require().NoError(err)
+	s.Require().Len(list.Annotations, 2, "Cleared flags stay in the history")
+	s.Require().Empty(list.ActiveFlags)
+
+	// The flag can be set again once cleared.
+	_, err = s.OrderService.SetOrderFlag(s.Ctx, &pb.SetOrderFlagRequest{
+		AdminId: 1,
+		OrderId: resp.OrderId,
+		Flag:    string(domain.FlagFraudSuspect),
+	})
+	s.Require().NoError(err)
+
+	internal := s.timelineEventTypes(resp.OrderId, 1, true)
+	s.Require().Subset(internal, []string{domain.TimelineNoteAdded, domain.TimelineFlagSet, domain.TimelineFlagCleared})
+
+	customer := s.timelineEventTypes(resp.OrderId, 999, false)
+	s.Require().NotContains(customer, domain.TimelineNoteAdded)
+	s.Require().NotContains(customer, domain.TimelineFlagSet)
+	s.Require().NotContains(customer, domain.TimelineFlagCleared)
+}
+
+func (s *IntegrationTestSuite) TestOrderAnnotations_Rejects() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	resp := s.createOrder(999)
+
+	_, err := s.OrderService.AddOrderNote(s.Ctx, &pb.AddOrderNoteRequest{AdminId: 999, OrderId: resp.OrderId, Body: "Looks fine"})
+	s.Require().ErrorIs(err, service.ErrPermissionDenied)
+
+	_, err = s.OrderService.ListOrderAnnotations(s.Ctx, &pb.ListOrderAnnotationsRequest{AdminId: 999, OrderId: resp.OrderId})
+	s.Require().ErrorIs(err, service.ErrPermissionDenied)
+
+	_, err = s.OrderService.AddOrderNote(s.Ctx, &pb.AddOrderNoteRequest{AdminId: 1, OrderId: resp.OrderId, Body: "   "})
+	s.Require().ErrorIs(err, domain.ErrInvalidAnnotation)
+
+	_, err = s.OrderService.SetOrderFlag(s.Ctx, &pb.SetOrderFlagRequest{AdminId: 1, OrderId: resp.OrderId, Flag: "suspicious"})
+	s.Require().ErrorIs(err, domain.ErrInvalidAnnotation)
+
+	_, err = s.OrderService.ClearOrderFlag(s.Ctx, &pb.ClearOrderFlagRequest{AdminId: 1, OrderId: resp.OrderId, Flag: string(domain.FlagVIP)})
+	s.Require().ErrorIs(err, repository.ErrAnnotationNotFound)
+
+	_, err = s.OrderService.SetOrderFlag(s.Ctx, &pb.SetOrderFlagRequest{AdminId: 1, OrderId: 999999, Flag: string(domain.FlagVIP)})
+	s.Require().ErrorIs(err, repository.ErrOrderNotFound)
+
+	_, err = s.OrderService.ListOrderAnnotations(s.Ctx, &pb.ListOrderAnnotationsRequest{AdminId: 1, OrderId: 999999})
+	s.Require().ErrorIs(err, repository.ErrOrderNotFound)
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/transport/grpc"
+	"github.com/sakashimaa/go-pet-project/pkg/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func (s *IntegrationTestSuite) callerContext(userId string) context.Context {
+	return metadata.NewIncomingContext(s.Ctx, metadata.Pairs("x-user-id", userId, "x-user-activated", "true"))
+}
+
+func (s *IntegrationTestSuite) unactivatedCallerContext(userId string) context.Context {
+	return metadata.NewIncomingContext(s.Ctx, metadata.Pairs("x-user-id", userId, "x-user-activated", "false"))
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_UsesForwardedCaller() {
+	s.seedData(999, "test@example.com")
+	handler := grpc.NewOrderHandler(s.OrderService, zap.NewNop())
+
+	item := domain.OrderItem{ProductID: 1, Name: "Kuronami No Yaiba", Price: 5350, Quantity: 1}
+
+	resp, err := handler.CreateOrder(s.callerContext("999"), &pb.CreateOrderRequest{
+		Items:             []*pb.OrderItem{item.ToPB()},
+		ShippingAddressId: s.addAddress(s.Ctx, 999),
+	})
+	s.Require().NoError(err)
+
+	var userId int64
+	err = s.DbPool.QueryRow(s.Ctx, `SELECT user_id FROM orders WHERE id = $1`, resp.OrderId).Scan(&userId)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(999), userId)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_RejectsForeignUserId() {
+	handler := grpc.NewOrderHandler(s.OrderService, zap.NewNop())
+
+	_, err := handler.CreateOrder(s.callerContext("1000"), &pb.CreateOrderRequest{UserId: 999})
+	s.Require().Equal(codes.PermissionDenied, status.Code(err))
+
+	_, err = handler.CreateOrder(s.Ctx, &pb.CreateOrderRequest{UserId: 999})
+	s.Require().Equal(codes.Unauthenticated, status.Code(err), "Calls without a forwarded caller are refused")
+}
+
+func (s *IntegrationTestSuite) TestGetOrderTimeline_EnforcesOwnershipOfCaller() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	handler := grpc.NewOrderHandler(s.OrderService, zap.NewNop())
+
+	_, err := handler.GetOrderTimeline(s.callerContext("1000"), &pb.GetOrderTimelineRequest{OrderId: resp.OrderId})
+	s.Require().Equal(codes.NotFound, status.Code(err))
+
+	timeline, err := handler.GetOrderTimeline(s.callerContext("999"), &pb.GetOrderTimelineRequest{OrderId: resp.OrderId})
+	s.Require().NoError(err)
+	s.Require().NotEmpty(timeline.Entries)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_RequiresActivatedAccount() {
+	s.seedData(999, "test@example.com")
+	handler := grpc.NewOrderHandler(s.OrderService, zap.NewNop())
+
+	item := domain.OrderItem{ProductID: 1, Name: "Kuronami No Yaiba", Price: 5350, Quantity: 1}
+
+	_, err := handler.CreateOrder(s.unactivatedCallerContext("999"), &pb.CreateOrderRequest{
+		Items:             []*pb.OrderItem{item.ToPB()},
+		ShippingAddressId: s.addAddress(s.Ctx, 999),
+	})
+	s.Require().Equal(codes.PermissionDenied, status.Code(err))
+
+	var count int
+	err = s.DbPool.QueryRow(s.Ctx, `SELECT COUNT(*) FROM orders WHERE user_id = 999`).Scan(&count)
+	s.Require().NoError(err)
+	s.Require().Zero(count, "No order is created for an unactivated account")
+}
+
+func (s *IntegrationTestSuite) TestListUserOrders_AllowedBeforeActivation() {
+	s.seedData(999, "test@example.com")
+	s.createOrder(999)
+	handler := grpc.NewOrderHandler(s.OrderService, zap.NewNop())
+
+	res, err := handler.ListUserOrders(s.unactivatedCallerContext("999"), &pb.ListUserOrdersRequest{})
+	s.Require().NoError(err)
+	s.Require().Len(res.Orders, 1)
+}
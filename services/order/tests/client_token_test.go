@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) tokenOrderRequest(userId int64, token string) *pb.CreateOrderRequest {
+	return &pb.CreateOrderRequest{
+		UserId:            userId,
+		Items:             []*pb.OrderItem{{ProductId: 1, Name: "Kuronami No Yaiba", Price: 5350, Quantity: 1}},
+		ShippingAddressId: s.addAddress(s.Ctx, userId),
+		ClientToken:       token,
+	}
+}
+
+func (s *IntegrationTestSuite) countUserOrders(userId int64) int {
+	var count int
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM orders WHERE user_id = $1", userId).Scan(&count)
+	s.Require().NoError(err)
+
+	return count
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_ClientTokenReturnsExistingOrder() {
+	s.seedData(999, "test@example.com")
+	req := s.tokenOrderRequest(999, uuid.NewString())
+
+	first, err := s.OrderService.CreateOrder(s.Ctx, req)
+	s.Require().NoError(err)
+	s.Require().False(first.Duplicate)
+
+	second, err := s.OrderService.CreateOrder(s.Ctx, req)
+	s.Require().NoError(err)
+	s.Require().True(second.Duplicate)
+	s.Require().Equal(first.OrderId, second.OrderId)
+	s.Require().Equal(first.TotalSum, second.TotalSum)
+
+	s.Require().Equal(1, s.countUserOrders(999))
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_ConcurrentClientTokenPlacesOneOrder() {
+	s.seedData(999, "test@example.com")
+	req := s.tokenOrderRequest(999, uuid.NewString())
+
+	const submits = 5
+	ids := make([]int64, submits)
+	errs := make([]error, submits)
+
+	var wg sync.WaitGroup
+	for i := range submits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			res, err := s.OrderService.CreateOrder(s.Ctx, req)
+			if errs[i] = err; err == nil {
+				ids[i] = res.OrderId
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := range submits {
+		s.Require().NoError(errs[i])
+		s.Require().Equal(ids[0], ids[i])
+	}
+	s.Require().Equal(1, s.countUserOrders(999))
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_ClientTokenIsPerUser() {
+	s.seedData(999, "test@example.com")
+	s.seedData(1000, "other@example.com")
+	token := uuid.NewString()
+
+	mine, err := s.OrderService.CreateOrder(s.Ctx, s.tokenOrderRequest(999, token))
+	s.Require().NoError(err)
+
+	theirs, err := s.OrderService.CreateOrder(s.Ctx, s.tokenOrderRequest(1000, token))
+	s.Require().NoError(err)
+	s.Require().False(theirs.Duplicate)
+	s.Require().NotEqual(mine.OrderId, theirs.OrderId)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_RejectsMalformedClientToken() {
+	s.seedData(999, "test@example.com")
+
+	_, err := s.OrderService.CreateOrder(s.Ctx, s.tokenOrderRequest(999, "not-a-uuid"))
+	s.Require().ErrorIs(err, domain.ErrInvalidOrder)
+	s.Require().Zero(s.countUserOrders(999))
+}
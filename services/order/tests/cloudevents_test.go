@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
+	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+type capturedMessage struct {
+	Topic   string
+	Key     string
+	Value   []byte
+	Headers map[string]string
+}
+
+type captureProducer struct {
+	mu       sync.Mutex
+	messages []capturedMessage
+}
+
+func (p *captureProducer) ProduceMessageWithHeaders(_ context.Context, topic, key string, message interface{}, headers map[string]string) error {
+	var (
+		value []byte
+		err   error
+	)
+	if msg, ok := message.(proto.Message); ok {
+		value, err = proto.Marshal(msg)
+	} else {
+		value, err = json.Marshal(message)
+	}
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.messages = append(p.messages, capturedMessage{Topic: topic, Key: key, Value: value, Headers: headers})
+	return nil
+}
+
+func (p *captureProducer) ProduceMessages(ctx context.Context, topic string, messages []kafka2.Message) error {
+	for _, m := range messages {
+		if err := p.ProduceMessageWithHeaders(ctx, topic, m.Key, m.Value, m.Headers); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *captureProducer) find(key string) (capturedMessage, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, m := range p.messages {
+		if m.Key == key {
+			return m, true
+		}
+	}
+
+	return capturedMessage{}, false
+}
+
+func (s *IntegrationTestSuite) TestOutbox_CloudEventsFormat() {
+	// Stop the suite's envelope worker so only the CloudEvents one publishes.
+	s.workerCancel()
+
+	producer := &captureProducer{}
+	processor := worker.NewOutboxProcessor(
+		s.DbPool,
+		outboxRepository.NewOutboxRepository(s.DbPool, zap.NewNop(), "order-service"),
+		producer,
+		zap.NewNop(),
+		worker.WithMessageFormat(kafka2.FormatCloudEvents),
+	)
+
+	ctx, cancel := context.WithCancel(s.Ctx)
+	defer cancel()
+	go processor.Start(ctx)
+
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	key := fmt.Sprintf("%d", resp.OrderId)
+
+	var msg capturedMessage
+	s.Require().Eventually(func() bool {
+		var ok bool
+		msg, ok = producer.find(key)
+		return ok
+	}, 10*time.Second, 100*time.Millisecond)
+
+	s.Require().Equal(kafka2.CloudEventsContentType, msg.Headers[kafka2.HeaderContentType])
+
+	var event kafka2.CloudEvent
+	s.Require().NoError(json.Unmarshal(msg.Value, &event))
+
+	s.Require().Equal("1.0", event.SpecVersion)
+	s.Require().Equal(kafka2.CloudEventTypePrefix+"OrderCreated", event.Type)
+	s.Require().Equal("/order-service", event.Source)
+	s.Require().Equal("Order/"+key, event.Subject)
+	s.Require().Equal(msg.Headers[eventmeta.HeaderEventID], event.ID)
+
+	envelope, err := event.ToEnvelope()
+	s.Require().NoError(err)
+
+	var decoded struct {
+		Event   string          `json:"event"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(envelope, &decoded))
+	s.Require().Equal("OrderCreated", decoded.Event)
+	s.Require().JSONEq(string(event.Data), string(decoded.Payload))
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IBM/sarama"
+	"github.com/google/uuid"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+	"github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/retry"
+	"go.uber.org/zap"
+)
+
+func eventMessage(eventID string) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{
+		Topic: "order_events",
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte(eventmeta.HeaderEventID), Value: []byte(eventID)},
+			{Key: []byte(eventmeta.HeaderEventType), Value: []byte("OrderCreated")},
+		},
+	}
+}
+
+func (s *IntegrationTestSuite) TestConsumerMiddleware_DeduplicateSkipsRedeliveries() {
+	var handled int
+	failNext := true
+	handler := kafka.Chain(
+		func(context.Context, *sarama.ConsumerMessage) error {
+			handled++
+			if failNext {
+				failNext = false
+				return errors.New("boom")
+			}
+			return nil
+		},
+		kafka.Deduplicate(kafka.NewPostgresInbox(s.DbPool, "processed_messages"), zap.NewNop()),
+	)
+
+	msg := eventMessage(uuid.NewString())
+
+	s.Require().Error(handler(s.Ctx, msg))
+	s.Require().NoError(handler(s.Ctx, msg), "a failed message is handled again")
+	s.Require().NoError(handler(s.Ctx, msg), "a handled message is skipped")
+	s.Require().Equal(2, handled)
+
+	s.Require().NoError(handler(s.Ctx, eventMessage(uuid.NewString())))
+	s.Require().Equal(3, handled)
+
+	s.Require().NoError(handler(s.Ctx, &sarama.ConsumerMessage{Topic: "order_events"}))
+	s.Require().NoError(handler(s.Ctx, &sarama.ConsumerMessage{Topic: "order_events"}))
+	s.Require().Equal(5, handled, "messages without an event_id are never skipped")
+}
+
+func (s *IntegrationTestSuite) TestConsumerMiddleware_ChainRetriesAndRecovers() {
+	var calls []string
+	trace := func(name string) kafka.Middleware {
+		return func(next kafka.HandlerFunc) kafka.HandlerFunc {
+			return func(ctx context.Context, msg *sarama.ConsumerMessage) error {
+				calls = append(calls, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+
+	attempts := 0
+	handler := kafka.Chain(
+		func(context.Context, *sarama.ConsumerMessage) error {
+			attempts++
+			if attempts < 3 {
+				panic("handler bug")
+			}
+			return nil
+		},
+		trace("outer"),
+		kafka.Retry(retry.Policy{MaxAttempts: 3}, zap.NewNop()),
+		trace("inner"),
+		kafka.Recover(zap.NewNop()),
+	)
+
+	s.Require().NoError(handler(s.Ctx, eventMessage(uuid.NewString())))
+	s.Require().Equal(3, attempts)
+	s.Require().Equal([]string{"outer", "inner", "inner", "inner"}, calls)
+
+	failing := kafka.Chain(
+		func(context.Context, *sarama.ConsumerMessage) error { panic("always") },
+		kafka.Recover(zap.NewNop()),
+	)
+	s.Require().ErrorIs(failing(s.Ctx, eventMessage(uuid.NewString())), kafka.ErrHandlerPanic)
+}
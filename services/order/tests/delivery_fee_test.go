@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.uber.org/zap"
+)
+
+// useShippingRater rebuilds the service under test with rater. SetupTest
+// puts the free-delivery service back for the next test.
+func (s *IntegrationTestSuite) useShippingRater(rater service.ShippingRater) {
+	logger := zap.NewNop()
+	s.OrderService = service.NewOrderService(
+		s.DbPool,
+		logger,
+		repository.NewOrderRepository(s.DbPool, logger),
+		outboxRepository.NewOutboxRepository(s.DbPool, logger, "order-service"),
+		service.WithInvoicing(s.InvoiceStore, domain.InvoiceIssuer{
+			Name:       "Test Shop Ltd",
+			Currency:   "USD",
+			TaxRateBps: testTaxRateBps,
+		}),
+		service.WithShippingRater(rater),
+	)
+}
+
+func (s *IntegrationTestSuite) createWeighedOrder(userId int64, weightGrams int32, quantity int32) (*pb.CreateOrderResponse, error) {
+	return s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{
+		UserId: userId,
+		Items: []*pb.OrderItem{
+			{ProductId: 1, Name: "Kettlebell", Price: 2000, Quantity: quantity, WeightGrams: weightGrams},
+		},
+		ShippingAddressId: s.addAddress(s.Ctx, userId),
+	})
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_DeliveryFeeIsFreeByDefault() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+
+	s.Require().Zero(resp.DeliveryFee)
+	s.Require().Equal(int64(5350), resp.TotalSum)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_WeightRateAddsDeliveryFee() {
+	s.useShippingRater(service.WeightRate{Base: 300, PerKg: 150})
+	s.seedData(999, "test@example.com")
+
+	// 3 x 1.2 kg is 3.6 kg, billed as 4 started kilograms.
+	resp, err := s.createWeighedOrder(999, 1200, 3)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(300+4*150), resp.DeliveryFee)
+	s.Require().Equal(int64(3*2000+900), resp.TotalSum)
+
+	var deliveryFee, totalSum int64
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT delivery_fee, total_sum FROM orders WHERE id = $1", resp.OrderId).
+		Scan(&deliveryFee, &totalSum)
+	s.Require().NoError(err)
+	s.Require().Equal(resp.DeliveryFee, deliveryFee)
+	s.Require().Equal(resp.TotalSum, totalSum)
+
+	var payload []byte
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT payload FROM outbox WHERE event_type = 'OrderCreated'").Scan(&payload)
+	s.Require().NoError(err)
+
+	var envelope struct {
+		Payload struct {
+			DeliveryFee int64 `json:"delivery_fee"`
+		} `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &envelope))
+	s.Require().Equal(int64(900), envelope.Payload.DeliveryFee, "product charges the fee with the items")
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_FlatRateIsWaivedForLargeOrders() {
+	s.useShippingRater(service.FlatRate{Fee: 499, FreeFrom: 5000})
+	s.seedData(999, "test@example.com")
+
+	small, err := s.createWeighedOrder(999, 0, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(499), small.DeliveryFee)
+
+	large, err := s.createWeighedOrder(999, 0, 3)
+	s.Require().NoError(err)
+	s.Require().Zero(large.DeliveryFee)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_CarrierRate() {
+	var quoted struct {
+		Address     domain.ShippingAddress `json:"address"`
+		WeightGrams int64                  `json:"weight_grams"`
+	}
+	carrier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&quoted)
+		_, _ = w.Write([]byte(`{"fee": 725}`))
+	}))
+	defer carrier.Close()
+
+	s.useShippingRater(service.NewCarrierRate(carrier.URL))
+	s.seedData(999, "test@example.com")
+
+	resp, err := s.createWeighedOrder(999, 500, 2)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(725), resp.DeliveryFee)
+	s.Require().Equal(int64(1000), quoted.WeightGrams)
+	s.Require().Equal("RU", quoted.Address.Country)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_CarrierFailureBlocksOrder() {
+	carrier := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer carrier.Close()
+
+	s.useShippingRater(service.NewCarrierRate(carrier.URL))
+	s.seedData(999, "test@example.com")
+
+	_, err := s.createWeighedOrder(999, 500, 1)
+	s.Require().ErrorIs(err, service.ErrShippingUnavailable)
+
+	var orders int
+	s.Require().NoError(s.DbPool.QueryRow(s.Ctx, "SELECT count(*) FROM orders").Scan(&orders))
+	s.Require().Zero(orders)
+}
+
+func (s *IntegrationTestSuite) TestGenerateInvoice_BillsDeliveryAsOwnLine() {
+	s.useShippingRater(service.FlatRate{Fee: 600})
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	s.payOrder(resp.OrderId)
+
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, resp.OrderId))
+
+	var raw []byte
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT lines FROM invoices WHERE order_id = $1", resp.OrderId).Scan(&raw)
+	s.Require().NoError(err)
+
+	var lines []domain.InvoiceLine
+	s.Require().NoError(json.Unmarshal(raw, &lines))
+	s.Require().Len(lines, 2)
+	s.Require().Equal(domain.DeliveryLineName, lines[1].Name)
+	s.Require().Equal(int64(600), lines[1].Gross)
+
+	_, _, _, total := s.invoiceFor(resp.OrderId)
+	s.Require().Equal(int64(5350+600), total)
+}
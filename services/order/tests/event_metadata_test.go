@@ -0,0 +1,72 @@
+package tests
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+)
+
+type outboxMetadata struct {
+	EventID       string
+	Producer      string
+	CorrelationID string
+	CausationID   *string
+}
+
+func (s *IntegrationTestSuite) outboxMetadata(orderID int64, eventType string) outboxMetadata {
+	query := `
+		SELECT event_id::text, producer, correlation_id::text, causation_id::text
+		FROM outbox
+		WHERE aggregate_id = $1 AND event_type = $2
+	`
+
+	var m outboxMetadata
+	err := s.DbPool.QueryRow(s.Ctx, query, fmt.Sprintf("%d", orderID), eventType).
+		Scan(&m.EventID, &m.Producer, &m.CorrelationID, &m.CausationID)
+	s.Require().NoError(err)
+
+	return m
+}
+
+func (s *IntegrationTestSuite) TestEventMetadata_RootEvent() {
+	s.seedData(999, "test@example.com")
+
+	resp := s.createOrder(999)
+
+	m := s.outboxMetadata(resp.OrderId, "OrderCreated")
+
+	_, err := uuid.Parse(m.EventID)
+	s.Require().NoError(err)
+	s.Require().Equal("order-service", m.Producer)
+	s.Require().Equal(m.EventID, m.CorrelationID)
+	s.Require().Nil(m.CausationID)
+}
+
+func (s *IntegrationTestSuite) TestEventMetadata_ChainedFromIncomingEvent() {
+	s.seedData(999, "test@example.com")
+
+	resp := s.createOrder(999)
+
+	incoming := eventmeta.Metadata{
+		EventID:       uuid.NewString(),
+		CorrelationID: uuid.NewString(),
+	}
+	ctx := eventmeta.WithIncoming(s.Ctx, incoming)
+
+	err := s.OrderService.CancelOrder(ctx, &domain.PaymentFailedEvent{
+		OrderID:  resp.OrderId,
+		Amount:   5350,
+		FailedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	m := s.outboxMetadata(resp.OrderId, "OrderCancelled")
+
+	s.Require().NotEqual(incoming.EventID, m.EventID)
+	s.Require().Equal(incoming.CorrelationID, m.CorrelationID)
+	s.Require().NotNil(m.CausationID)
+	s.Require().Equal(incoming.EventID, *m.CausationID)
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"context"
+
+	"github.com/IBM/sarama"
+	"github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"go.uber.org/zap"
+)
+
+type routedEvent struct {
+	OrderID int64 `json:"order_id"`
+}
+
+func envelope(value string) *sarama.ConsumerMessage {
+	return &sarama.ConsumerMessage{Topic: "order_events", Value: []byte(value)}
+}
+
+func (s *IntegrationTestSuite) TestEventRouter_DecodesPayloadForItsRoute() {
+	var got []int64
+	router := kafka.NewRouter(zap.NewNop(), kafka.StrictPayloads()).
+		Register(kafka.On("OrderCreated", func(_ context.Context, e *routedEvent) error {
+			got = append(got, e.OrderID)
+			return nil
+		})).
+		Ignore("InvoiceGenerated")
+
+	s.Require().NoError(router.Route(s.Ctx, envelope(`{"event":"OrderCreated","payload":{"order_id":42},"order_id":7}`)))
+	s.Require().NoError(router.Route(s.Ctx, envelope(`{"event":"InvoiceGenerated","payload":{"order_id":1}}`)))
+	s.Require().NoError(router.Route(s.Ctx, envelope(`{"event":"SomethingNew","payload":{}}`)), "unknown events are skipped")
+
+	s.Require().Equal([]int64{42}, got, "the payload is decoded, not the envelope")
+}
+
+func (s *IntegrationTestSuite) TestEventRouter_StrictFailsMalformedPayloads() {
+	handled := false
+	handle := kafka.On("OrderCreated", func(context.Context, *routedEvent) error {
+		handled = true
+		return nil
+	})
+
+	strict := kafka.NewRouter(zap.NewNop(), kafka.StrictPayloads()).Register(handle)
+	s.Require().ErrorIs(strict.Route(s.Ctx, envelope(`{"event":"OrderCreated","payload":{"order_id":"x"}}`)), kafka.ErrMalformedEvent)
+	s.Require().ErrorIs(strict.Route(s.Ctx, envelope(`not json`)), kafka.ErrMalformedEvent)
+
+	lenient := kafka.NewRouter(zap.NewNop()).Register(handle)
+	s.Require().NoError(lenient.Route(s.Ctx, envelope(`{"event":"OrderCreated","payload":{"order_id":"x"}}`)))
+	s.Require().NoError(lenient.Route(s.Ctx, envelope(`not json`)))
+
+	s.Require().False(handled)
+}
+
+func (s *IntegrationTestSuite) TestEventRouter_RejectsDuplicateRoutes() {
+	router := kafka.NewRouter(zap.NewNop()).Ignore("OrderCreated")
+
+	s.Require().Panics(func() {
+		router.Register(kafka.On("OrderCreated", func(context.Context, *routedEvent) error { return nil }))
+	})
+}
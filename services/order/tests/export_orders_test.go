@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) exportOrders(req *pb.ExportOrdersRequest) ([][]string, int) {
+	var (
+		buf    bytes.Buffer
+		chunks int
+	)
+	err := s.OrderService.ExportOrders(s.Ctx, req, func(chunk []byte) error {
+		chunks++
+		buf.Write(chunk)
+		return nil
+	})
+	s.Require().NoError(err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	s.Require().NoError(err)
+
+	return records, chunks
+}
+
+func (s *IntegrationTestSuite) TestExportOrders_IncludesInvoiceColumns() {
+	s.seedData(999, "test@example.com")
+	paid := s.createOrder(999)
+	unpaid := s.createOrder(999)
+	s.payOrder(paid.OrderId)
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, paid.OrderId))
+
+	today := time.Now().UTC().Format("2006-01-02")
+	records, _ := s.exportOrders(&pb.ExportOrdersRequest{From: today, To: today})
+
+	s.Require().Len(records, 3)
+	s.Require().Equal(domain.OrderExportHeader, records[0])
+
+	column := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		column[name] = i
+	}
+
+	paidRow, unpaidRow := records[1], records[2]
+	s.Require().Equal(fmt.Sprint(paid.OrderId), paidRow[column["order_id"]])
+	s.Require().Equal("paid", paidRow[column["payment_status"]])
+	s.Require().Equal("5350", paidRow[column["gross_total"]])
+	s.Require().Equal("892", paidRow[column["tax_total"]])
+	s.Require().NotEmpty(paidRow[column["invoice_number"]])
+
+	s.Require().Equal(fmt.Sprint(unpaid.OrderId), unpaidRow[column["order_id"]])
+	s.Require().Equal("unpaid", unpaidRow[column["payment_status"]])
+	s.Require().Empty(unpaidRow[column["invoice_number"]])
+	s.Require().Empty(unpaidRow[column["tax_total"]])
+
+	records, _ = s.exportOrders(&pb.ExportOrdersRequest{From: today, To: today, Status: string(domain.OrderStatusPaid)})
+	s.Require().Len(records, 2)
+	s.Require().Equal(fmt.Sprint(paid.OrderId), records[1][column["order_id"]])
+}
+
+func (s *IntegrationTestSuite) TestExportOrders_PagesThroughLargeRanges() {
+	s.seedData(999, "test@example.com")
+
+	// More than one page, all with the same creation time, so the keyset
+	// has to fall back on the id.
+	const orders = 1201
+	_, err := s.DbPool.Exec(s.Ctx, `
+		INSERT INTO orders (user_id, status, total_sum, created_at)
+		SELECT 999, 'new', 100, '2026-03-01T12:00:00Z'
+		FROM generate_series(1, $1)
+	`, orders)
+	s.Require().NoError(err)
+
+	records, chunks := s.exportOrders(&pb.ExportOrdersRequest{From: "2026-03-01", To: "2026-03-01"})
+	s.Require().Len(records, orders+1)
+	s.Require().Equal(3, chunks)
+
+	seen := make(map[string]bool, orders)
+	for _, record := range records[1:] {
+		s.Require().False(seen[record[0]], "order %s exported twice", record[0])
+		seen[record[0]] = true
+	}
+}
+
+func (s *IntegrationTestSuite) TestExportOrders_RejectsBadFilters() {
+	cases := map[string]*pb.ExportOrdersRequest{
+		"missing from":   {To: "2026-01-31"},
+		"reversed":       {From: "2026-02-01", To: "2026-01-01"},
+		"too long":       {From: "2025-01-01", To: "2026-06-01"},
+		"unknown status": {From: "2026-01-01", To: "2026-01-31", Status: "lost"},
+	}
+
+	for name, req := range cases {
+		err := s.OrderService.ExportOrders(s.Ctx, req, func([]byte) error { return nil })
+		s.Require().ErrorIs(err, service.ErrInvalidExportFilter, name)
+	}
+}
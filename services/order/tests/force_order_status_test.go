@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) seedAdmin(id int64) {
+	_, err := s.DbPool.Exec(s.Ctx, "INSERT INTO users (id, email, role) VALUES ($1, $2, $3)", id, fmt.Sprintf("admin%d@example.com", id), domain.RoleAdmin)
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) TestForceOrderStatus_CancelsStuckReservation() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	resp := s.createOrder(999)
+	s.reserveOrder(resp.OrderId)
+
+	res, err := s.OrderService.ForceOrderStatus(s.Ctx, &pb.ForceOrderStatusRequest{
+		OrderId: resp.OrderId,
+		AdminId: 1,
+		Status:  string(domain.OrderStatusCancelled),
+		Reason:  "Customer asked support to cancel",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderStatusReserved), res.PreviousStatus)
+	s.Require().Equal(string(domain.OrderStatusCancelled), s.orderStatus(resp.OrderId))
+
+	var adminID int64
+	var reason string
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT admin_id, reason FROM order_status_overrides WHERE order_id = $1", resp.OrderId).
+		Scan(&adminID, &reason)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), adminID)
+	s.Require().Equal("Customer asked support to cancel", reason)
+
+	for _, eventType := range []string{"OrderStatusForced", "OrderCancelled", "PaymentTimedOut"} {
+		var count int
+		err = s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM outbox WHERE aggregate_id = $1 AND event_type = $2", fmt.Sprintf("%d", resp.OrderId), eventType).
+			Scan(&count)
+		s.Require().NoError(err)
+		s.Require().Equal(1, count, eventType)
+	}
+
+	timeline, err := s.OrderService.GetOrderTimeline(s.Ctx, &pb.GetOrderTimelineRequest{
+		OrderId:         resp.OrderId,
+		UserId:          1,
+		IncludeInternal: true,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(domain.TimelineStatusForced, timeline.Entries[len(timeline.Entries)-1].EventType)
+}
+
+func (s *IntegrationTestSuite) TestForceOrderStatus_ConfirmsPayment() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	resp := s.createOrder(999)
+	s.reserveOrder(resp.OrderId)
+
+	_, err := s.OrderService.ForceOrderStatus(s.Ctx, &pb.ForceOrderStatusRequest{
+		OrderId: resp.OrderId,
+		AdminId: 1,
+		Status:  string(domain.OrderStatusPaid),
+		Reason:  "Provider confirmed the charge, the webhook was lost",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderStatusPaid), s.orderStatus(resp.OrderId))
+
+	var itemStatus string
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT status FROM order_items WHERE order_id = $1", resp.OrderId).Scan(&itemStatus)
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderItemStatusPaid), itemStatus)
+
+	var invoices int
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM invoices WHERE order_id = $1", resp.OrderId).Scan(&invoices)
+	s.Require().NoError(err)
+	s.Require().Equal(1, invoices)
+}
+
+func (s *IntegrationTestSuite) TestForceOrderStatus_Rejects() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	resp := s.createOrder(999)
+	s.reserveOrder(resp.OrderId)
+
+	cases := map[string]struct {
+		req  *pb.ForceOrderStatusRequest
+		want error
+	}{
+		"not an admin": {
+			req:  &pb.ForceOrderStatusRequest{OrderId: resp.OrderId, AdminId: 999, Status: "paid", Reason: "because"},
+			want: service.ErrPermissionDenied,
+		},
+		"no reason": {
+			req:  &pb.ForceOrderStatusRequest{OrderId: resp.OrderId, AdminId: 1, Status: "paid", Reason: "  "},
+			want: service.ErrInvalidStatusOverride,
+		},
+		"unknown status": {
+			req:  &pb.ForceOrderStatusRequest{OrderId: resp.OrderId, AdminId: 1, Status: "refunded", Reason: "because"},
+			want: service.ErrInvalidStatusOverride,
+		},
+		"not allowed": {
+			req:  &pb.ForceOrderStatusRequest{OrderId: resp.OrderId, AdminId: 1, Status: "shipped", Reason: "because"},
+			want: service.ErrStatusNotForcible,
+		},
+	}
+
+	for name, tc := range cases {
+		_, err := s.OrderService.ForceOrderStatus(s.Ctx, tc.req)
+		s.Require().ErrorIs(err, tc.want, name)
+	}
+
+	s.Require().Equal(string(domain.OrderStatusReserved), s.orderStatus(resp.OrderId))
+
+	// Paid orders stay paid: the money is refunded through payment instead.
+	_, err := s.OrderService.ForceOrderStatus(s.Ctx, &pb.ForceOrderStatusRequest{OrderId: resp.OrderId, AdminId: 1, Status: "paid", Reason: "confirmed"})
+	s.Require().NoError(err)
+
+	_, err = s.OrderService.ForceOrderStatus(s.Ctx, &pb.ForceOrderStatusRequest{OrderId: resp.OrderId, AdminId: 1, Status: "cancelled", Reason: "changed my mind"})
+	s.Require().ErrorIs(err, service.ErrStatusNotForcible)
+}
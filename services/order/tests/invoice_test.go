@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+const testTaxRateBps = 2000
+
+func (s *IntegrationTestSuite) payOrder(orderId int64) {
+	s.reserveOrder(orderId)
+
+	err := s.OrderService.ChangeOrderStatusPaymentSucceeded(s.Ctx, &generalDomain.PaymentSucceededEvent{
+		OrderID:   orderId,
+		PaymentID: 1,
+		Amount:    5350,
+		PaidAt:    time.Now(),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) invoiceFor(orderId int64) (number string, net, tax, total int64) {
+	err := s.DbPool.QueryRow(s.Ctx, `
+		SELECT number, net_total, tax_total, total
+		FROM invoices
+		WHERE order_id = $1
+	`, orderId).Scan(&number, &net, &tax, &total)
+	s.Require().NoError(err)
+
+	return number, net, tax, total
+}
+
+func (s *IntegrationTestSuite) TestGenerateInvoice_NumbersPerYear() {
+	s.seedData(999, "test@example.com")
+	first := s.createOrder(999)
+	second := s.createOrder(999)
+	s.payOrder(first.OrderId)
+	s.payOrder(second.OrderId)
+
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, first.OrderId))
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, second.OrderId))
+
+	year := time.Now().UTC().Year()
+	number, net, tax, total := s.invoiceFor(first.OrderId)
+	s.Require().Equal(fmt.Sprintf("INV-%d-000001", year), number)
+	s.Require().Equal(int64(5350), total)
+	s.Require().Equal(int64(892), tax, "20% included in 53.50 rounds to 8.92")
+	s.Require().Equal(total, net+tax)
+
+	number, _, _, _ = s.invoiceFor(second.OrderId)
+	s.Require().Equal(fmt.Sprintf("INV-%d-000002", year), number)
+
+	var events int
+	err := s.DbPool.QueryRow(s.Ctx, `
+		SELECT count(*) FROM outbox WHERE event_type = 'InvoiceGenerated'
+	`).Scan(&events)
+	s.Require().NoError(err)
+	s.Require().Equal(2, events)
+}
+
+func (s *IntegrationTestSuite) TestGenerateInvoice_Idempotent() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	s.payOrder(resp.OrderId)
+
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, resp.OrderId))
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, resp.OrderId))
+
+	var invoices int
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT count(*) FROM invoices WHERE order_id = $1", resp.OrderId).Scan(&invoices)
+	s.Require().NoError(err)
+	s.Require().Equal(1, invoices)
+}
+
+func (s *IntegrationTestSuite) TestGenerateInvoice_SkipsUnpaidOrder() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, resp.OrderId))
+
+	_, err := s.OrderService.GetInvoice(s.Ctx, &pb.GetInvoiceRequest{OrderId: resp.OrderId, UserId: 999})
+	s.Require().ErrorIs(err, repository.ErrInvoiceNotFound)
+}
+
+func (s *IntegrationTestSuite) TestGetInvoice_ReturnsPDFToOwnerOnly() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	s.payOrder(resp.OrderId)
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, resp.OrderId))
+
+	invoice, err := s.OrderService.GetInvoice(s.Ctx, &pb.GetInvoiceRequest{OrderId: resp.OrderId, UserId: 999})
+	s.Require().NoError(err)
+	s.Require().Equal("application/pdf", invoice.ContentType)
+	s.Require().True(bytes.HasPrefix(invoice.Content, []byte("%PDF-")))
+
+	_, err = s.OrderService.GetInvoice(s.Ctx, &pb.GetInvoiceRequest{OrderId: resp.OrderId, UserId: 1000})
+	s.Require().ErrorIs(err, repository.ErrInvoiceNotFound)
+
+	var timeline int
+	err = s.DbPool.QueryRow(s.Ctx, `
+		SELECT count(*) FROM order_events WHERE order_id = $1 AND event_type = $2
+	`, resp.OrderId, domain.TimelineInvoiceIssued).Scan(&timeline)
+	s.Require().NoError(err)
+	s.Require().Equal(1, timeline)
+}
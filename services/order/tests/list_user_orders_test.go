@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) TestListUserOrders_NewestFirstAndLimited() {
+	s.seedData(999, "test@example.com")
+
+	var ids []int64
+	for range 3 {
+		ids = append(ids, s.createOrder(999).OrderId)
+	}
+
+	res, err := s.OrderService.ListUserOrders(s.Ctx, &pb.ListUserOrdersRequest{UserId: 999, Limit: 2})
+	s.Require().NoError(err)
+	s.Require().Len(res.Orders, 2)
+
+	s.Equal(ids[2], res.Orders[0].OrderId)
+	s.Equal(ids[1], res.Orders[1].OrderId)
+	s.Equal("new", res.Orders[0].Status)
+	s.Equal(int64(5350), res.Orders[0].TotalSum)
+}
+
+func (s *IntegrationTestSuite) TestListUserOrders_OtherUsersOrdersHidden() {
+	s.seedData(999, "test@example.com")
+	s.createOrder(999)
+
+	res, err := s.OrderService.ListUserOrders(s.Ctx, &pb.ListUserOrdersRequest{UserId: 1000})
+	s.Require().NoError(err)
+	s.Empty(res.Orders)
+}
+
+func (s *IntegrationTestSuite) TestListUserOrders_CursorPagination() {
+	s.seedData(999, "test@example.com")
+
+	created := map[int64]bool{}
+	for range 3 {
+		created[s.createOrder(999).OrderId] = true
+	}
+
+	first, err := s.OrderService.ListUserOrders(s.Ctx, &pb.ListUserOrdersRequest{UserId: 999, Limit: 2})
+	s.Require().NoError(err)
+	s.Require().Len(first.Orders, 2)
+	s.Require().NotEmpty(first.NextCursor)
+
+	second, err := s.OrderService.ListUserOrders(s.Ctx, &pb.ListUserOrdersRequest{UserId: 999, Limit: 2, Cursor: first.NextCursor})
+	s.Require().NoError(err)
+	s.Require().Len(second.Orders, 1)
+	s.Empty(second.NextCursor)
+
+	seen := map[int64]bool{}
+	for _, order := range append(first.Orders, second.Orders...) {
+		seen[order.OrderId] = true
+	}
+	s.Equal(created, seen)
+}
+
+func (s *IntegrationTestSuite) TestListUserOrders_InvalidCursor() {
+	_, err := s.OrderService.ListUserOrders(s.Ctx, &pb.ListUserOrdersRequest{UserId: 999, Cursor: "not a cursor"})
+	s.ErrorIs(err, service.ErrInvalidCursor)
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+// manualOrderRequest is a phone order for user 999 placed by admin 1: the
+// first line at a goodwill price, the second at its list price, and 500 off
+// the whole order.
+func (s *IntegrationTestSuite) manualOrderRequest() *pb.CreateManualOrderRequest {
+	return &pb.CreateManualOrderRequest{
+		AdminId: 1,
+		UserId:  999,
+		Items: []*pb.ManualOrderItem{
+			{
+				ProductId: 1,
+				Name:      "Kuronami No Yaiba",
+				Price:     5350,
+				Quantity:  1,
+				Override:  &pb.PriceOverride{Price: 4000, ReasonCode: domain.PriceReasonGoodwill},
+			},
+			{ProductId: 2, Name: "Sheath", Price: 1000, Quantity: 2},
+		},
+		ShippingAddressId: s.addAddress(s.Ctx, 999),
+		Discount:          &pb.OrderDiscount{Amount: 500, ReasonCode: domain.PriceReasonPhoneOrder},
+		Note:              "Ordered by phone, blade arrived scratched last time",
+	}
+}
+
+func (s *IntegrationTestSuite) TestCreateManualOrder_AppliesOverrides() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+
+	resp, err := s.OrderService.CreateManualOrder(s.Ctx, s.manualOrderRequest())
+	s.Require().NoError(err)
+	s.Require().Equal(int64(500), resp.Discount)
+	s.Require().Equal(int64(4000+2*1000-500), resp.TotalSum)
+
+	var discount, totalSum, placedBy int64
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT discount, total_sum, placed_by FROM orders WHERE id = $1", resp.OrderId).
+		Scan(&discount, &totalSum, &placedBy)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(500), discount)
+	s.Require().Equal(resp.TotalSum, totalSum)
+	s.Require().Equal(int64(1), placedBy)
+
+	var price int64
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT price FROM order_items WHERE order_id = $1 AND product_id = 1", resp.OrderId).Scan(&price)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(4000), price)
+
+	rows, err := s.DbPool.Query(s.Ctx, `
+		SELECT product_id, original_amount, amount, reason_code, note
+		FROM order_price_overrides
+		WHERE order_id = $1
+		ORDER BY id
+	`, resp.OrderId)
+	s.Require().NoError(err)
+	var overrides []domain.PriceOverride
+	for rows.Next() {
+		var o domain.PriceOverride
+		s.Require().NoError(rows.Scan(&o.ProductID, &o.OriginalAmount, &o.Amount, &o.ReasonCode, &o.Note))
+		overrides = append(overrides, o)
+	}
+	s.Require().NoError(rows.Err())
+	s.Require().Len(overrides, 2)
+	s.Require().Equal(int64(1), *overrides[0].ProductID)
+	s.Require().Equal(int64(5350), overrides[0].OriginalAmount)
+	s.Require().Equal(int64(4000), overrides[0].Amount)
+	s.Require().Nil(overrides[1].ProductID, "The order discount has no line")
+	s.Require().Equal(int64(6000), overrides[1].OriginalAmount)
+	s.Require().Equal(domain.PriceReasonPhoneOrder, overrides[1].ReasonCode)
+	s.Require().Equal("Ordered by phone, blade arrived scratched last time", overrides[1].Note)
+
+	var payload []byte
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT payload FROM outbox WHERE aggregate_id = $1 AND event_type = 'OrderCreated'", fmt.Sprintf("%d", resp.OrderId)).
+		Scan(&payload)
+	s.Require().NoError(err)
+
+	var envelope struct {
+		Payload struct {
+			Items []struct {
+				ProductID     int64 `json:"product_id"`
+				PriceOverride *struct {
+					Price int64 `json:"price"`
+				} `json:"price_override"`
+			} `json:"items"`
+			Discount int64 `json:"discount"`
+		} `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &envelope))
+	s.Require().NotNil(envelope.Payload.Items[0].PriceOverride)
+	s.Require().Equal(int64(4000), envelope.Payload.Items[0].PriceOverride.Price)
+	s.Require().Nil(envelope.Payload.Items[1].PriceOverride, "Product charges the catalog price")
+	s.Require().Equal(int64(500), envelope.Payload.Discount)
+
+	timeline, err := s.OrderService.GetOrderTimeline(s.Ctx, &pb.GetOrderTimelineRequest{
+		OrderId:         resp.OrderId,
+		UserId:          1,
+		IncludeInternal: true,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(domain.TimelinePricesOverridden, timeline.Entries[len(timeline.Entries)-1].EventType)
+}
+
+func (s *IntegrationTestSuite) TestCreateManualOrder_RequiresAdmin() {
+	s.seedData(999, "test@example.com")
+	s.seedData(2, "support@example.com")
+
+	req := s.manualOrderRequest()
+	req.AdminId = 2
+
+	_, err := s.OrderService.CreateManualOrder(s.Ctx, req)
+	s.Require().ErrorIs(err, service.ErrPermissionDenied)
+}
+
+func (s *IntegrationTestSuite) TestCreateManualOrder_RejectsBadOverrides() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+
+	cases := map[string]func(req *pb.CreateManualOrderRequest){
+		"unknown reason": func(req *pb.CreateManualOrderRequest) {
+			req.Items[0].Override.ReasonCode = "because"
+		},
+		"other without note": func(req *pb.CreateManualOrderRequest) {
+			req.Discount.ReasonCode = domain.PriceReasonOther
+			req.Note = " "
+		},
+		"discount above items": func(req *pb.CreateManualOrderRequest) {
+			req.Discount.Amount = 6001
+		},
+	}
+
+	for name, mutate := range cases {
+		req := s.manualOrderRequest()
+		mutate(req)
+
+		_, err := s.OrderService.CreateManualOrder(s.Ctx, req)
+		s.Require().ErrorIs(err, service.ErrInvalidPriceOverride, name)
+	}
+
+	var orders int
+	s.Require().NoError(s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM orders").Scan(&orders))
+	s.Require().Zero(orders)
+}
+
+func (s *IntegrationTestSuite) TestGenerateInvoice_BillsDiscountAsNegativeLine() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+
+	resp, err := s.OrderService.CreateManualOrder(s.Ctx, s.manualOrderRequest())
+	s.Require().NoError(err)
+	s.payOrder(resp.OrderId)
+
+	s.Require().NoError(s.OrderService.GenerateInvoice(s.Ctx, resp.OrderId))
+
+	var raw []byte
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT lines FROM invoices WHERE order_id = $1", resp.OrderId).Scan(&raw)
+	s.Require().NoError(err)
+
+	var lines []domain.InvoiceLine
+	s.Require().NoError(json.Unmarshal(raw, &lines))
+	s.Require().Len(lines, 3)
+	s.Require().Equal(domain.DiscountLineName, lines[2].Name)
+	s.Require().Equal(int64(-500), lines[2].Gross)
+
+	_, net, tax, total := s.invoiceFor(resp.OrderId)
+	s.Require().Equal(resp.TotalSum, total)
+	s.Require().Equal(total, net+tax)
+}
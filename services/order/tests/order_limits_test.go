@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"math"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) TestCreateOrder_RejectsOutOfBoundsItems() {
+	s.seedData(999, "test@example.com")
+
+	cases := map[string][]*pb.OrderItem{
+		"huge quantity":  {{ProductId: 1, Price: 100, Quantity: math.MaxInt32}},
+		"zero quantity":  {{ProductId: 1, Price: 100, Quantity: 0}},
+		"huge price":     {{ProductId: 1, Price: math.MaxInt64, Quantity: 2}},
+		"negative price": {{ProductId: 1, Price: -1, Quantity: 1}},
+		"total too large": {
+			{ProductId: 1, Price: domain.MaxItemPrice, Quantity: 6},
+			{ProductId: 2, Price: domain.MaxItemPrice, Quantity: 5},
+		},
+	}
+
+	tooMany := make([]*pb.OrderItem, domain.MaxOrderLines+1)
+	for i := range tooMany {
+		tooMany[i] = &pb.OrderItem{ProductId: int64(i + 1), Price: 100, Quantity: 1}
+	}
+	cases["too many lines"] = tooMany
+
+	for name, items := range cases {
+		_, err := s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{UserId: 999, Items: items})
+		s.Require().ErrorIs(err, domain.ErrInvalidOrder, name)
+	}
+
+	var count int
+	s.Require().NoError(s.DbPool.QueryRow(s.Ctx, `SELECT COUNT(*) FROM orders`).Scan(&count))
+	s.Require().Zero(count)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_AcceptsItemsAtTheLimit() {
+	s.seedData(999, "test@example.com")
+
+	resp, err := s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{
+		UserId: 999,
+		Items: []*pb.OrderItem{
+			{ProductId: 1, Price: domain.MaxItemPrice, Quantity: 10},
+		},
+		ShippingAddressId: s.addAddress(s.Ctx, 999),
+	})
+	s.Require().NoError(err)
+	s.Require().NotZero(resp.OrderId)
+}
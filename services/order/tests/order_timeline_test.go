@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) TestGetOrderTimeline_Success() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+
+	err := s.OrderService.HandleInventoryReserved(s.Ctx, &domain.InventoryReservedEvent{
+		OrderID: resp.OrderId,
+		UserID:  999,
+		Amount:  5350,
+	})
+	s.Require().NoError(err)
+
+	err = s.OrderService.ChangeOrderStatusPaymentSucceeded(s.Ctx, &generalDomain.PaymentSucceededEvent{
+		OrderID:   resp.OrderId,
+		PaymentID: 1,
+		Amount:    5350,
+		PaidAt:    time.Now(),
+	})
+	s.Require().NoError(err)
+
+	timeline, err := s.OrderService.GetOrderTimeline(s.Ctx, &pb.GetOrderTimelineRequest{
+		OrderId: resp.OrderId,
+		UserId:  999,
+	})
+	s.Require().NoError(err)
+	s.Require().Len(timeline.Entries, 3)
+	s.Require().Equal(domain.TimelineOrderCreated, timeline.Entries[0].EventType)
+	s.Require().Equal(domain.TimelineInventoryReserved, timeline.Entries[1].EventType)
+	s.Require().Equal(domain.TimelinePaymentSucceeded, timeline.Entries[2].EventType)
+}
+
+func (s *IntegrationTestSuite) TestGetOrderTimeline_Failure() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+
+	_, err := s.OrderService.GetOrderTimeline(s.Ctx, &pb.GetOrderTimelineRequest{
+		OrderId: resp.OrderId,
+		UserId:  1000,
+	})
+	s.Require().ErrorIs(err, repository.ErrOrderNotFound)
+
+	_, err = s.OrderService.GetOrderTimeline(s.Ctx, &pb.GetOrderTimelineRequest{
+		OrderId:         resp.OrderId,
+		UserId:          999,
+		IncludeInternal: true,
+	})
+	s.Require().ErrorIs(err, service.ErrPermissionDenied, "Internal entries are for support only")
+}
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) createPartiallyReservedOrder(userId int64) int64 {
+	s.seedData(userId, "partial@example.com")
+
+	resp, err := s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{
+		UserId: userId,
+		Items: []*pb.OrderItem{
+			{ProductId: 1, Name: "Available", Price: 100, Quantity: 2},
+			{ProductId: 2, Name: "Sold out", Price: 500, Quantity: 1},
+		},
+		ShippingAddressId: s.addAddress(s.Ctx, userId),
+	})
+	s.Require().NoError(err)
+
+	err = s.OrderService.HandleInventoryPartiallyReserved(s.Ctx, &domain.InventoryPartiallyReservedEvent{
+		OrderID:          resp.OrderId,
+		UserID:           userId,
+		Amount:           200,
+		ReservedItems:    []domain.ReservedItem{{ProductID: 1, Quantity: 2, Price: 100}},
+		UnavailableItems: []domain.UnavailableItem{{ProductID: 2, Quantity: 1}},
+		ReservedAt:       time.Now(),
+	})
+	s.Require().NoError(err)
+
+	return resp.OrderId
+}
+
+func (s *IntegrationTestSuite) TestPartialReservation_RemoveUnavailable_Success() {
+	orderId := s.createPartiallyReservedOrder(999)
+
+	var status string
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT status FROM orders WHERE id = $1", orderId).Scan(&status)
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderStatusPartiallyReserved), status)
+
+	res, err := s.OrderService.ResolvePartialReservation(s.Ctx, &pb.ResolvePartialReservationRequest{
+		OrderId: orderId,
+		UserId:  999,
+		Choice:  pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_REMOVE_UNAVAILABLE,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderStatusReserved), res.Status)
+	s.Require().Equal(int64(200), res.TotalSum)
+
+	var itemStatus string
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT status FROM order_items WHERE order_id = $1 AND product_id = 2", orderId).
+		Scan(&itemStatus)
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderItemStatusRemoved), itemStatus)
+
+	var count int
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM outbox WHERE aggregate_id = $1 AND event_type = 'OrderConfirmed'", fmt.Sprintf("%d", orderId)).
+		Scan(&count)
+	s.Require().NoError(err)
+	s.Require().Equal(1, count)
+}
+
+func (s *IntegrationTestSuite) TestPartialReservation_Wait_Success() {
+	orderId := s.createPartiallyReservedOrder(999)
+
+	res, err := s.OrderService.ResolvePartialReservation(s.Ctx, &pb.ResolvePartialReservationRequest{
+		OrderId: orderId,
+		UserId:  999,
+		Choice:  pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_WAIT,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderStatusAwaitingStock), res.Status)
+	s.Require().Equal(int64(700), res.TotalSum, "Waiting keeps every item on the order")
+}
+
+func (s *IntegrationTestSuite) TestPartialReservation_Failure() {
+	orderId := s.createPartiallyReservedOrder(999)
+
+	_, err := s.OrderService.ResolvePartialReservation(s.Ctx, &pb.ResolvePartialReservationRequest{
+		OrderId: orderId,
+		UserId:  1000,
+		Choice:  pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_WAIT,
+	})
+	s.Require().ErrorIs(err, repository.ErrOrderNotFound, "Foreign orders must not be visible")
+
+	_, err = s.OrderService.ResolvePartialReservation(s.Ctx, &pb.ResolvePartialReservationRequest{
+		OrderId: orderId,
+		UserId:  999,
+	})
+	s.Require().ErrorIs(err, service.ErrInvalidChoice)
+
+	order := s.createOrder(999)
+	_, err = s.OrderService.ResolvePartialReservation(s.Ctx, &pb.ResolvePartialReservationRequest{
+		OrderId: order.OrderId,
+		UserId:  999,
+		Choice:  pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_WAIT,
+	})
+	s.Require().ErrorIs(err, service.ErrOrderNotPartiallyReserved)
+}
@@ -32,6 +32,12 @@ func (s *IntegrationTestSuite) TestPaymentSucceeded_Success() {
 	s.Require().NotEmpty(status)
 
 	s.Require().Equal(status, "paid")
+
+	var paidEvents int
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM outbox WHERE aggregate_id = $1 AND event_type = 'OrderPaid'", fmt.Sprintf("%d", resp.OrderId)).
+		Scan(&paidEvents)
+	s.Require().NoError(err)
+	s.Require().Equal(1, paidEvents)
 }
 
 func (s *IntegrationTestSuite) TestPaymentFailed_Failure() {
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"go.uber.org/zap"
+)
+
+type fakePaymentHealth struct {
+	serving bool
+}
+
+func (h *fakePaymentHealth) Serving(context.Context) (bool, error) {
+	return h.serving, nil
+}
+
+func (s *IntegrationTestSuite) reserveOrder(orderId int64) {
+	err := s.OrderService.HandleInventoryReserved(s.Ctx, &domain.InventoryReservedEvent{
+		OrderID:    orderId,
+		UserID:     999,
+		Amount:     5350,
+		ReservedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) orderStatus(orderId int64) string {
+	var status string
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT status FROM orders WHERE id = $1", orderId).Scan(&status)
+	s.Require().NoError(err)
+
+	return status
+}
+
+func (s *IntegrationTestSuite) TestExpireUnpaidOrders_CancelsStaleReservation() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	s.reserveOrder(resp.OrderId)
+
+	n, err := s.OrderService.ExpireUnpaidOrders(s.Ctx, time.Now().Add(-time.Minute))
+	s.Require().NoError(err)
+	s.Require().Zero(n, "The reservation is still fresh")
+
+	n, err = s.OrderService.ExpireUnpaidOrders(s.Ctx, time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+	s.Require().Equal(1, n)
+	s.Require().Equal(string(domain.OrderStatusCancelled), s.orderStatus(resp.OrderId))
+
+	var events []string
+	rows, err := s.DbPool.Query(s.Ctx, `
+		SELECT event_type
+		FROM outbox
+		WHERE aggregate_id = $1 AND event_type IN ('PaymentTimedOut', 'OrderCancelled')
+		ORDER BY event_type
+	`, fmt.Sprintf("%d", resp.OrderId))
+	s.Require().NoError(err)
+	for rows.Next() {
+		var eventType string
+		s.Require().NoError(rows.Scan(&eventType))
+		events = append(events, eventType)
+	}
+	s.Require().Equal([]string{"OrderCancelled", "PaymentTimedOut"}, events)
+}
+
+func (s *IntegrationTestSuite) TestExpireUnpaidOrders_LatePaymentKeepsOrderCancelled() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	s.reserveOrder(resp.OrderId)
+
+	_, err := s.OrderService.ExpireUnpaidOrders(s.Ctx, time.Now().Add(time.Minute))
+	s.Require().NoError(err)
+
+	err = s.OrderService.ChangeOrderStatusPaymentSucceeded(s.Ctx, &generalDomain.PaymentSucceededEvent{
+		OrderID:   resp.OrderId,
+		PaymentID: 1,
+		Amount:    5350,
+		PaidAt:    time.Now(),
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderStatusCancelled), s.orderStatus(resp.OrderId))
+
+	err = s.OrderService.CancelOrder(s.Ctx, &generalDomain.PaymentFailedEvent{
+		OrderID:  resp.OrderId,
+		Amount:   5350,
+		FailedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	var returns int
+	err = s.DbPool.QueryRow(s.Ctx, `
+		SELECT COUNT(*)
+		FROM outbox
+		WHERE aggregate_id = $1 AND event_type = 'OrderCancelled'
+	`, fmt.Sprintf("%d", resp.OrderId)).Scan(&returns)
+	s.Require().NoError(err)
+	s.Require().Equal(1, returns, "Stock is returned only once")
+}
+
+func (s *IntegrationTestSuite) TestPaymentWatchdog_HoldsOrdersWhilePaymentIsDown() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	s.reserveOrder(resp.OrderId)
+
+	// A negative timeout makes the fresh reservation overdue.
+	health := &fakePaymentHealth{serving: false}
+	watchdog := service.NewPaymentWatchdog(s.OrderService, -time.Minute, zap.NewNop(), service.WithPaymentHealth(health, time.Hour))
+
+	s.Require().NoError(watchdog.Run(s.Ctx))
+	s.Require().Equal(string(domain.OrderStatusReserved), s.orderStatus(resp.OrderId), "A down payment service stretches the timeout")
+
+	health.serving = true
+	s.Require().NoError(watchdog.Run(s.Ctx))
+	s.Require().Equal(string(domain.OrderStatusCancelled), s.orderStatus(resp.OrderId), "A slow payment times out")
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
+	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/proto/events"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+func (s *IntegrationTestSuite) TestOutbox_ProtobufFormat() {
+	s.workerCancel()
+
+	producer := &captureProducer{}
+	processor := worker.NewOutboxProcessor(
+		s.DbPool,
+		outboxRepository.NewOutboxRepository(s.DbPool, zap.NewNop(), "order-service"),
+		producer,
+		zap.NewNop(),
+		worker.WithMessageFormat(kafka2.FormatProtobuf),
+	)
+
+	ctx, cancel := context.WithCancel(s.Ctx)
+	defer cancel()
+	go processor.Start(ctx)
+
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+	key := fmt.Sprintf("%d", resp.OrderId)
+
+	var msg capturedMessage
+	s.Require().Eventually(func() bool {
+		var ok bool
+		msg, ok = producer.find(key)
+		return ok
+	}, 10*time.Second, 100*time.Millisecond)
+
+	s.Require().Equal(kafka2.ProtobufContentType, msg.Headers[kafka2.HeaderContentType])
+	s.Require().Equal("events.OrderCreated", msg.Headers[kafka2.HeaderProtoMessage])
+
+	var event events.OrderCreated
+	s.Require().NoError(proto.Unmarshal(msg.Value, &event))
+
+	s.Require().Equal(resp.OrderId, event.OrderId)
+	s.Require().Equal(int64(999), event.UserId)
+	s.Require().NotEmpty(event.Items)
+}
@@ -0,0 +1,217 @@
+package tests
+
+import (
+	"sync"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"go.uber.org/zap"
+)
+
+// useOrderRateLimit rebuilds the service under test with the rate limit.
+// SetupTest puts the unlimited service back for the next test.
+func (s *IntegrationTestSuite) useOrderRateLimit(perHour int) {
+	logger := zap.NewNop()
+	s.OrderService = service.NewOrderService(
+		s.DbPool,
+		logger,
+		repository.NewOrderRepository(s.DbPool, logger),
+		outboxRepository.NewOutboxRepository(s.DbPool, logger, "order-service"),
+		service.WithOrderRateLimit(perHour),
+	)
+}
+
+func (s *IntegrationTestSuite) setPurchaseCap(productId int64, maxPerUser int32) {
+	_, err := s.OrderService.SetPurchaseCap(s.Ctx, &pb.SetPurchaseCapRequest{
+		AdminId:    1,
+		ProductId:  productId,
+		MaxPerUser: maxPerUser,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) orderProduct(userId, productId int64, quantities ...int32) (*pb.CreateOrderResponse, error) {
+	items := make([]*pb.OrderItem, len(quantities))
+	for i, quantity := range quantities {
+		items[i] = &pb.OrderItem{ProductId: productId, Name: "Limited Vandal", Price: 4000, Quantity: quantity}
+	}
+
+	return s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{
+		UserId:            userId,
+		Items:             items,
+		ShippingAddressId: s.addAddress(s.Ctx, userId),
+	})
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_RateLimitRefusesOrdersOverTheLimit() {
+	s.useOrderRateLimit(2)
+	s.seedData(999, "test@example.com")
+	s.seedData(1000, "other@example.com")
+
+	s.createOrder(999)
+	s.createOrder(999)
+
+	_, err := s.orderProduct(999, 1, 1)
+	var rateLimit *domain.RateLimitError
+	s.Require().ErrorAs(err, &rateLimit)
+	s.Require().ErrorIs(err, domain.ErrOrderRateLimited)
+	s.Require().Equal(2, rateLimit.Limit)
+	s.Require().Positive(rateLimit.RetryAfter)
+	s.Require().LessOrEqual(rateLimit.RetryAfter, domain.OrderRateWindow)
+	s.Require().Equal(2, s.countUserOrders(999))
+
+	// Other users have their own allowance.
+	s.createOrder(1000)
+
+	// Orders older than the window no longer count.
+	_, err = s.DbPool.Exec(s.Ctx, "UPDATE orders SET created_at = NOW() - INTERVAL '61 minutes' WHERE user_id = 999")
+	s.Require().NoError(err)
+	s.createOrder(999)
+}
+
+func (s *IntegrationTestSuite) TestCreateManualOrder_IgnoresRateLimit() {
+	s.useOrderRateLimit(1)
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+
+	s.createOrder(999)
+
+	_, err := s.OrderService.CreateManualOrder(s.Ctx, s.manualOrderRequest())
+	s.Require().NoError(err)
+
+	// Orders support placed do not use up the customer's allowance either.
+	_, err = s.DbPool.Exec(s.Ctx, "UPDATE orders SET created_at = NOW() - INTERVAL '61 minutes' WHERE placed_by IS NULL")
+	s.Require().NoError(err)
+	s.createOrder(999)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_PurchaseCapCountsEarlierOrders() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	s.setPurchaseCap(7, 3)
+
+	first, err := s.orderProduct(999, 7, 2)
+	s.Require().NoError(err)
+
+	_, err = s.orderProduct(999, 7, 2)
+	var capErr *domain.PurchaseCapError
+	s.Require().ErrorAs(err, &capErr)
+	s.Require().ErrorIs(err, domain.ErrPurchaseCapExceeded)
+	s.Require().Equal(int64(7), capErr.ProductID)
+	s.Require().Equal(int32(3), capErr.Cap)
+	s.Require().Equal(int32(1), capErr.Remaining)
+
+	_, err = s.orderProduct(999, 7, 1)
+	s.Require().NoError(err)
+
+	// A cancelled order hands its units back.
+	_, err = s.DbPool.Exec(s.Ctx, "UPDATE orders SET status = $2 WHERE id = $1", first.OrderId, string(domain.OrderStatusCancelled))
+	s.Require().NoError(err)
+
+	_, err = s.orderProduct(999, 7, 2)
+	s.Require().NoError(err)
+
+	// Products without a cap are not limited.
+	_, err = s.orderProduct(999, 8, 1000)
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_PurchaseCapSumsLinesOfOneOrder() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	s.setPurchaseCap(7, 3)
+
+	_, err := s.orderProduct(999, 7, 2, 2)
+	var capErr *domain.PurchaseCapError
+	s.Require().ErrorAs(err, &capErr)
+	s.Require().Equal(int32(3), capErr.Remaining)
+	s.Require().Zero(s.countUserOrders(999))
+}
+
+func (s *IntegrationTestSuite) TestCreateOrder_ConcurrentOrdersRespectPurchaseCap() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	s.setPurchaseCap(7, 1)
+	addressId := s.addAddress(s.Ctx, 999)
+
+	const submits = 5
+	errs := make([]error, submits)
+
+	var wg sync.WaitGroup
+	for i := range submits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, errs[i] = s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{
+				UserId:            999,
+				Items:             []*pb.OrderItem{{ProductId: 7, Name: "Limited Vandal", Price: 4000, Quantity: 1}},
+				ShippingAddressId: addressId,
+			})
+		}()
+	}
+	wg.Wait()
+
+	placed := 0
+	for _, err := range errs {
+		if err == nil {
+			placed++
+			continue
+		}
+		s.Require().ErrorIs(err, domain.ErrPurchaseCapExceeded)
+	}
+	s.Require().Equal(1, placed)
+	s.Require().Equal(1, s.countUserOrders(999))
+}
+
+func (s *IntegrationTestSuite) TestSetPurchaseCap_ZeroLiftsTheCap() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	s.setPurchaseCap(7, 1)
+	s.setPurchaseCap(8, 5)
+
+	res, err := s.OrderService.ListPurchaseCaps(s.Ctx, &pb.ListPurchaseCapsRequest{AdminId: 1})
+	s.Require().NoError(err)
+	s.Require().Len(res.Caps, 2)
+	s.Require().Equal(int64(7), res.Caps[0].ProductId)
+	s.Require().Equal(int32(1), res.Caps[0].MaxPerUser)
+	s.Require().Equal(int64(1), res.Caps[0].SetBy)
+
+	s.setPurchaseCap(7, 0)
+
+	res, err = s.OrderService.ListPurchaseCaps(s.Ctx, &pb.ListPurchaseCapsRequest{AdminId: 1})
+	s.Require().NoError(err)
+	s.Require().Len(res.Caps, 1)
+	s.Require().Equal(int64(8), res.Caps[0].ProductId)
+
+	_, err = s.orderProduct(999, 7, 3)
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) TestSetPurchaseCap_RequiresAdmin() {
+	s.seedData(999, "test@example.com")
+
+	_, err := s.OrderService.SetPurchaseCap(s.Ctx, &pb.SetPurchaseCapRequest{AdminId: 999, ProductId: 7, MaxPerUser: 1})
+	s.Require().ErrorIs(err, service.ErrPermissionDenied)
+
+	_, err = s.OrderService.ListPurchaseCaps(s.Ctx, &pb.ListPurchaseCapsRequest{AdminId: 999})
+	s.Require().ErrorIs(err, service.ErrPermissionDenied)
+}
+
+func (s *IntegrationTestSuite) TestSetPurchaseCap_RejectsInvalidCaps() {
+	s.seedAdmin(1)
+
+	cases := map[string]*pb.SetPurchaseCapRequest{
+		"no product":     {AdminId: 1, MaxPerUser: 1},
+		"negative cap":   {AdminId: 1, ProductId: 7, MaxPerUser: -1},
+		"cap over limit": {AdminId: 1, ProductId: 7, MaxPerUser: domain.MaxItemQuantity + 1},
+	}
+
+	for name, req := range cases {
+		_, err := s.OrderService.SetPurchaseCap(s.Ctx, req)
+		s.Require().ErrorIs(err, service.ErrInvalidPurchaseCap, name)
+	}
+}
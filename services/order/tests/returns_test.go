@@ -0,0 +1,246 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+// paidOrder places an order of the product in lines of quantities at 4000
+// each and takes it through reservation and payment.
+func (s *IntegrationTestSuite) paidOrder(userId, productId int64, quantities ...int32) int64 {
+	resp, err := s.orderProduct(userId, productId, quantities...)
+	s.Require().NoError(err)
+
+	var amount int64
+	for _, quantity := range quantities {
+		amount += 4000 * int64(quantity)
+	}
+
+	err = s.OrderService.HandleInventoryReserved(s.Ctx, &domain.InventoryReservedEvent{
+		OrderID:    resp.OrderId,
+		UserID:     userId,
+		Amount:     amount,
+		ReservedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	err = s.OrderService.ChangeOrderStatusPaymentSucceeded(s.Ctx, &generalDomain.PaymentSucceededEvent{
+		OrderID:   resp.OrderId,
+		PaymentID: 1,
+		Amount:    amount,
+		PaidAt:    time.Now(),
+	})
+	s.Require().NoError(err)
+
+	return resp.OrderId
+}
+
+func (s *IntegrationTestSuite) requestReturn(userId, orderId, productId int64, quantity int32) (*pb.Return, error) {
+	return s.OrderService.RequestReturn(s.Ctx, &pb.RequestReturnRequest{
+		UserId:  userId,
+		OrderId: orderId,
+		Items:   []*pb.ReturnLine{{ProductId: productId, Quantity: quantity}},
+		Reason:  "Arrived scratched",
+	})
+}
+
+func (s *IntegrationTestSuite) returnApprovedEvents(orderId int64) map[string]generalDomain.ReturnApprovedEvent {
+	rows, err := s.DbPool.Query(s.Ctx, `
+		SELECT topic, payload
+		FROM outbox
+		WHERE aggregate_id = $1 AND event_type = 'ReturnApproved'
+	`, fmt.Sprintf("%d", orderId))
+	s.Require().NoError(err)
+	defer rows.Close()
+
+	events := make(map[string]generalDomain.ReturnApprovedEvent)
+	for rows.Next() {
+		var (
+			topic   string
+			payload []byte
+		)
+		s.Require().NoError(rows.Scan(&topic, &payload))
+
+		var envelope struct {
+			Payload generalDomain.ReturnApprovedEvent `json:"payload"`
+		}
+		s.Require().NoError(json.Unmarshal(payload, &envelope))
+
+		events[topic] = envelope.Payload
+	}
+	s.Require().NoError(rows.Err())
+
+	return events
+}
+
+func (s *IntegrationTestSuite) TestReturns_ApprovedReturnIsRestockedAndRefunded() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	orderId := s.paidOrder(999, 7, 2)
+
+	ret, err := s.requestReturn(999, orderId, 7, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.ReturnStatusRequested), ret.Status)
+	s.Require().Equal(int64(4000), ret.RefundAmount)
+	s.Require().Len(ret.Items, 1)
+
+	reviewed, err := s.OrderService.ReviewReturn(s.Ctx, &pb.ReviewReturnRequest{
+		AdminId:  1,
+		ReturnId: ret.Id,
+		Approve:  true,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.ReturnStatusApproved), reviewed.Status)
+	s.Require().NotEmpty(reviewed.ReviewedAt)
+
+	events := s.returnApprovedEvents(orderId)
+	s.Require().Len(events, 2, "Product restocks and payment refunds")
+	for _, topic := range []string{"product_events", "payment_events"} {
+		event := events[topic]
+		s.Require().Equal(ret.Id, event.ReturnID, topic)
+		s.Require().Equal(int64(4000), event.Amount, topic)
+		s.Require().Len(event.Items, 1, topic)
+		s.Require().Equal(int64(7), event.Items[0].ProductID, topic)
+		s.Require().Equal(int32(1), event.Items[0].Quantity, topic)
+	}
+
+	refund := &generalDomain.RefundIssuedEvent{
+		RefundID:   1,
+		ReturnID:   ret.Id,
+		OrderID:    orderId,
+		PaymentID:  1,
+		Amount:     4000,
+		RefundedAt: time.Now(),
+	}
+	s.Require().NoError(s.OrderService.HandleRefundIssued(s.Ctx, refund))
+	s.Require().NoError(s.OrderService.HandleRefundIssued(s.Ctx, refund), "Redeliveries are ignored")
+
+	list, err := s.OrderService.ListReturns(s.Ctx, &pb.ListReturnsRequest{UserId: 999})
+	s.Require().NoError(err)
+	s.Require().Len(list.Returns, 1)
+	s.Require().Equal(string(domain.ReturnStatusRefunded), list.Returns[0].Status)
+	s.Require().Equal(int64(4000), list.Returns[0].RefundedAmount)
+	s.Require().NotEmpty(list.Returns[0].RefundedAt)
+
+	timeline, err := s.OrderService.GetOrderTimeline(s.Ctx, &pb.GetOrderTimelineRequest{
+		OrderId: orderId,
+		UserId:  999,
+	})
+	s.Require().NoError(err)
+
+	var returnEntries []string
+	for _, entry := range timeline.Entries {
+		switch entry.EventType {
+		case domain.TimelineReturnRequested, domain.TimelineReturnApproved, domain.TimelineReturnRefunded:
+			returnEntries = append(returnEntries, entry.EventType)
+		}
+	}
+	s.Require().Equal([]string{
+		domain.TimelineReturnRequested,
+		domain.TimelineReturnApproved,
+		domain.TimelineReturnRefunded,
+	}, returnEntries, "Redelivered refunds add no entry")
+}
+
+func (s *IntegrationTestSuite) TestRequestReturn_CannotReturnMoreThanWasBought() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	orderId := s.paidOrder(999, 7, 1, 2)
+
+	// Units of a product are taken from every line it was bought on.
+	ret, err := s.requestReturn(999, orderId, 7, 3)
+	s.Require().NoError(err)
+	s.Require().Len(ret.Items, 2)
+	s.Require().Equal(int64(12000), ret.RefundAmount)
+
+	_, err = s.requestReturn(999, orderId, 7, 1)
+	s.Require().ErrorIs(err, domain.ErrInvalidReturn)
+
+	_, err = s.requestReturn(999, orderId, 8, 1)
+	s.Require().ErrorIs(err, domain.ErrInvalidReturn, "The product is not part of the order")
+
+	// A rejected return gives its units back.
+	_, err = s.OrderService.ReviewReturn(s.Ctx, &pb.ReviewReturnRequest{
+		AdminId:  1,
+		ReturnId: ret.Id,
+		Note:     "No damage visible on the photos",
+	})
+	s.Require().NoError(err)
+
+	again, err := s.requestReturn(999, orderId, 7, 1)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(4000), again.RefundAmount)
+}
+
+func (s *IntegrationTestSuite) TestRequestReturn_Rejects() {
+	s.seedData(999, "test@example.com")
+	s.seedData(1000, "other@example.com")
+
+	unpaid := s.createOrder(999)
+	_, err := s.requestReturn(999, unpaid.OrderId, 1, 1)
+	s.Require().ErrorIs(err, service.ErrReturnNotAllowed)
+
+	orderId := s.paidOrder(999, 7, 1)
+
+	_, err = s.requestReturn(1000, orderId, 7, 1)
+	s.Require().ErrorIs(err, repository.ErrOrderNotFound)
+
+	_, err = s.OrderService.RequestReturn(s.Ctx, &pb.RequestReturnRequest{
+		UserId:  999,
+		OrderId: orderId,
+		Items:   []*pb.ReturnLine{{ProductId: 7, Quantity: 1}},
+	})
+	s.Require().ErrorIs(err, domain.ErrInvalidReturn, "A reason is required")
+
+	_, err = s.requestReturn(999, orderId, 7, 0)
+	s.Require().ErrorIs(err, domain.ErrInvalidReturn)
+
+	_, err = s.DbPool.Exec(s.Ctx, "UPDATE orders SET created_at = $2 WHERE id = $1", orderId, time.Now().Add(-domain.ReturnWindow-time.Hour))
+	s.Require().NoError(err)
+
+	_, err = s.requestReturn(999, orderId, 7, 1)
+	s.Require().ErrorIs(err, service.ErrReturnNotAllowed, "The return window has passed")
+}
+
+func (s *IntegrationTestSuite) TestReviewReturn_Rejects() {
+	s.seedData(999, "test@example.com")
+	s.seedAdmin(1)
+	orderId := s.paidOrder(999, 7, 1)
+
+	ret, err := s.requestReturn(999, orderId, 7, 1)
+	s.Require().NoError(err)
+
+	_, err = s.OrderService.ReviewReturn(s.Ctx, &pb.ReviewReturnRequest{AdminId: 999, ReturnId: ret.Id, Approve: true})
+	s.Require().ErrorIs(err, service.ErrPermissionDenied)
+
+	_, err = s.OrderService.ReviewReturn(s.Ctx, &pb.ReviewReturnRequest{AdminId: 1, ReturnId: ret.Id})
+	s.Require().ErrorIs(err, domain.ErrInvalidReturn, "A rejection needs a note")
+
+	_, err = s.OrderService.ReviewReturn(s.Ctx, &pb.ReviewReturnRequest{AdminId: 1, ReturnId: 999999, Approve: true})
+	s.Require().ErrorIs(err, repository.ErrReturnNotFound)
+
+	_, err = s.OrderService.ReviewReturn(s.Ctx, &pb.ReviewReturnRequest{AdminId: 1, ReturnId: ret.Id, Approve: true})
+	s.Require().NoError(err)
+
+	_, err = s.OrderService.ReviewReturn(s.Ctx, &pb.ReviewReturnRequest{AdminId: 1, ReturnId: ret.Id, Note: "Changed my mind"})
+	s.Require().ErrorIs(err, service.ErrReturnAlreadyReviewed)
+
+	_, err = s.OrderService.ListReturns(s.Ctx, &pb.ListReturnsRequest{UserId: 999, AllUsers: true})
+	s.Require().ErrorIs(err, service.ErrPermissionDenied)
+
+	all, err := s.OrderService.ListReturns(s.Ctx, &pb.ListReturnsRequest{
+		UserId:   1,
+		AllUsers: true,
+		Status:   string(domain.ReturnStatusApproved),
+	})
+	s.Require().NoError(err)
+	s.Require().Len(all.Returns, 1)
+	s.Require().Equal(int64(999), all.Returns[0].UserId)
+}
@@ -0,0 +1,404 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	orderDomain "github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	"github.com/sakashimaa/go-pet-project/pkg/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+	"pgregory.net/rapid"
+)
+
+// The saga property test drives the order service with the messages product
+// and payment would send, delivered in random order, late and more than once,
+// between random customer actions and payment timeouts. Product and payment
+// are stood in for by sagaWorld, which reserves stock the way product does
+// and applies the OrderCancelled events the order service writes to its
+// outbox.
+
+const (
+	sagaUserID   = 4242
+	sagaProducts = 3
+)
+
+// sagaMessage is an event on its way to the order service.
+type sagaMessage struct {
+	name    string
+	orderID int64
+	deliver func() error
+}
+
+type sagaOrder struct {
+	id int64
+	// reserved is what product holds for the order, released what the order
+	// service has handed back so far, both by product.
+	reserved map[int64]int64
+	released map[int64]int64
+
+	paymentStarted bool
+	wasPaid        bool
+	wasCancelled   bool
+}
+
+type sagaWorld struct {
+	s         *IntegrationTestSuite
+	t         *rapid.T
+	addressID int64
+
+	initial map[int64]int64
+	stock   map[int64]int64
+	orders  []*sagaOrder
+	byID    map[int64]*sagaOrder
+
+	pending   []sagaMessage
+	delivered []sagaMessage
+	// lastOutboxID is the last outbox row the world has acted on.
+	lastOutboxID int64
+}
+
+func (s *IntegrationTestSuite) TestSagaProperties() {
+	s.seedData(sagaUserID, "saga@example.com")
+	addressID := s.addAddress(s.Ctx, sagaUserID)
+
+	rapid.Check(s.T(), func(t *rapid.T) {
+		_, err := s.DbPool.Exec(s.Ctx, "TRUNCATE orders, order_items, order_events, outbox CASCADE")
+		if err != nil {
+			t.Fatalf("reset: %v", err)
+		}
+
+		w := &sagaWorld{
+			s:         s,
+			t:         t,
+			addressID: addressID,
+			initial:   make(map[int64]int64),
+			stock:     make(map[int64]int64),
+			byID:      make(map[int64]*sagaOrder),
+		}
+
+		for p := int64(1); p <= sagaProducts; p++ {
+			w.initial[p] = int64(rapid.IntRange(0, 4).Draw(t, fmt.Sprintf("stock_%d", p)))
+			w.stock[p] = w.initial[p]
+		}
+
+		for range rapid.IntRange(1, 3).Draw(t, "orders") {
+			w.placeOrder()
+		}
+
+		for range rapid.IntRange(0, 30).Draw(t, "steps") {
+			w.step()
+			w.sync()
+			w.checkInvariants()
+		}
+
+		w.drain()
+		w.checkSettled()
+	})
+}
+
+// placeOrder creates an order and reserves what stock allows, like product
+// does when it consumes OrderCreated.
+func (w *sagaWorld) placeOrder() {
+	products := rapid.SliceOfNDistinct(rapid.Int64Range(1, sagaProducts), 1, sagaProducts, rapid.ID[int64]).Draw(w.t, "products")
+
+	quantities := make(map[int64]int64, len(products))
+	items := make([]*pb.OrderItem, 0, len(products))
+	for _, p := range products {
+		quantity := rapid.IntRange(1, 3).Draw(w.t, "quantity")
+		quantities[p] = int64(quantity)
+		items = append(items, &pb.OrderItem{ProductId: p, Name: fmt.Sprintf("Product %d", p), Price: 1000 * p, Quantity: int32(quantity)})
+	}
+
+	resp, err := w.s.OrderService.CreateOrder(w.s.Ctx, &pb.CreateOrderRequest{UserId: sagaUserID, Items: items, ShippingAddressId: w.addressID})
+	if err != nil {
+		w.t.Fatalf("create order: %v", err)
+	}
+
+	order := &sagaOrder{id: resp.OrderId, reserved: make(map[int64]int64), released: make(map[int64]int64)}
+	w.orders = append(w.orders, order)
+	w.byID[order.id] = order
+
+	var (
+		amount      int64
+		reserved    []orderDomain.ReservedItem
+		unavailable []orderDomain.UnavailableItem
+	)
+	for _, p := range products {
+		if w.stock[p] < quantities[p] {
+			unavailable = append(unavailable, orderDomain.UnavailableItem{ProductID: p, Quantity: quantities[p]})
+			continue
+		}
+
+		w.stock[p] -= quantities[p]
+		order.reserved[p] = quantities[p]
+		amount += 1000 * p * quantities[p]
+		reserved = append(reserved, orderDomain.ReservedItem{ProductID: p, Quantity: quantities[p], Price: 1000 * p})
+	}
+
+	ctx := w.s.Ctx
+	switch {
+	case len(reserved) == 0:
+		// Product gives up on the order without telling anyone.
+	case len(unavailable) == 0:
+		w.send("InventoryReserved", order.id, func() error {
+			return w.s.OrderService.HandleInventoryReserved(ctx, &orderDomain.InventoryReservedEvent{
+				OrderID: order.id, UserID: sagaUserID, Amount: amount, ReservedAt: time.Now(),
+			})
+		})
+		// Payment reads the same event and charges straight away.
+		w.startPayment(order, amount)
+	default:
+		w.send("InventoryPartiallyReserved", order.id, func() error {
+			return w.s.OrderService.HandleInventoryPartiallyReserved(ctx, &orderDomain.InventoryPartiallyReservedEvent{
+				OrderID: order.id, UserID: sagaUserID, Amount: amount,
+				ReservedItems: reserved, UnavailableItems: unavailable, ReservedAt: time.Now(),
+			})
+		})
+	}
+}
+
+// startPayment sends the one payment outcome a charge has.
+func (w *sagaWorld) startPayment(order *sagaOrder, amount int64) {
+	if order.paymentStarted {
+		w.t.Fatalf("order %d: payment started twice", order.id)
+	}
+	order.paymentStarted = true
+
+	ctx := w.s.Ctx
+	if rapid.Bool().Draw(w.t, "payment_succeeds") {
+		w.send("PaymentSucceeded", order.id, func() error {
+			return w.s.OrderService.ChangeOrderStatusPaymentSucceeded(ctx, &domain.PaymentSucceededEvent{
+				OrderID: order.id, PaymentID: order.id, Amount: amount, PaidAt: time.Now(),
+			})
+		})
+		return
+	}
+
+	w.send("PaymentFailed", order.id, func() error {
+		return w.s.OrderService.CancelOrder(ctx, &domain.PaymentFailedEvent{
+			OrderID: order.id, PaymentID: order.id, Amount: amount, FailedAt: time.Now(),
+		})
+	})
+}
+
+func (w *sagaWorld) send(name string, orderID int64, deliver func() error) {
+	w.pending = append(w.pending, sagaMessage{name: name, orderID: orderID, deliver: deliver})
+}
+
+func (w *sagaWorld) deliver(m sagaMessage) {
+	err := m.deliver()
+	// A failed payment cannot cancel a paid order; the consumer gives up on
+	// the message after its retries.
+	if err != nil && !errors.Is(err, repository.ErrOrderAlreadyPaid) {
+		w.t.Fatalf("deliver %s for order %d: %v", m.name, m.orderID, err)
+	}
+}
+
+func (w *sagaWorld) step() {
+	actions := []string{"cancel", "resolve", "timeout"}
+	if len(w.pending) > 0 {
+		actions = append(actions, "deliver", "deliver", "deliver")
+	}
+	if len(w.delivered) > 0 {
+		actions = append(actions, "redeliver")
+	}
+
+	ctx := w.s.Ctx
+	switch rapid.SampledFrom(actions).Draw(w.t, "action") {
+	case "deliver":
+		i := rapid.IntRange(0, len(w.pending)-1).Draw(w.t, "message")
+		m := w.pending[i]
+		w.pending = append(w.pending[:i], w.pending[i+1:]...)
+		w.delivered = append(w.delivered, m)
+		w.deliver(m)
+	case "redeliver":
+		w.deliver(rapid.SampledFrom(w.delivered).Draw(w.t, "redelivered"))
+	case "cancel":
+		order := rapid.SampledFrom(w.orders).Draw(w.t, "cancelled_order")
+		_, err := w.s.OrderService.CancelOrderByUser(ctx, &pb.CancelOrderRequest{OrderId: order.id, UserId: sagaUserID})
+		if err != nil && !errors.Is(err, service.ErrOrderNotCancellable) {
+			w.t.Fatalf("cancel order %d: %v", order.id, err)
+		}
+	case "resolve":
+		order := rapid.SampledFrom(w.orders).Draw(w.t, "resolved_order")
+		choice := rapid.SampledFrom([]pb.PartialReservationChoice{
+			pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_WAIT,
+			pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_REMOVE_UNAVAILABLE,
+		}).Draw(w.t, "choice")
+		w.resolve(order, choice)
+	case "timeout":
+		if _, err := w.s.OrderService.ExpireUnpaidOrders(ctx, time.Now().Add(time.Hour)); err != nil {
+			w.t.Fatalf("expire unpaid orders: %v", err)
+		}
+	}
+}
+
+func (w *sagaWorld) resolve(order *sagaOrder, choice pb.PartialReservationChoice) {
+	_, err := w.s.OrderService.ResolvePartialReservation(w.s.Ctx, &pb.ResolvePartialReservationRequest{
+		OrderId: order.id, UserId: sagaUserID, Choice: choice,
+	})
+	if err != nil && !errors.Is(err, service.ErrOrderNotPartiallyReserved) {
+		w.t.Fatalf("resolve order %d: %v", order.id, err)
+	}
+}
+
+// sync plays what the order service wrote to its outbox to product and
+// payment: cancellations hand stock back, confirmations start a payment.
+func (w *sagaWorld) sync() {
+	rows, err := w.s.DbPool.Query(w.s.Ctx,
+		`SELECT id, event_type, payload FROM outbox WHERE id > $1 AND event_type IN ('OrderCancelled', 'OrderConfirmed') ORDER BY id`,
+		w.lastOutboxID)
+	if err != nil {
+		w.t.Fatalf("read outbox: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id        int64
+			eventType string
+			payload   []byte
+		)
+		if err := rows.Scan(&id, &eventType, &payload); err != nil {
+			w.t.Fatalf("scan outbox: %v", err)
+		}
+		w.lastOutboxID = id
+
+		switch eventType {
+		case "OrderCancelled":
+			var wrapper struct {
+				Payload domain.OrderCancelledEvent `json:"payload"`
+			}
+			if err := json.Unmarshal(payload, &wrapper); err != nil {
+				w.t.Fatalf("decode OrderCancelled: %v", err)
+			}
+
+			order := w.byID[wrapper.Payload.OrderID]
+			for _, item := range wrapper.Payload.Items {
+				w.stock[item.ProductID] += int64(item.Quantity)
+				order.released[item.ProductID] += int64(item.Quantity)
+			}
+		case "OrderConfirmed":
+			var wrapper struct {
+				Payload orderDomain.OrderConfirmedEvent `json:"payload"`
+			}
+			if err := json.Unmarshal(payload, &wrapper); err != nil {
+				w.t.Fatalf("decode OrderConfirmed: %v", err)
+			}
+
+			w.startPayment(w.byID[wrapper.Payload.OrderID], wrapper.Payload.Amount)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		w.t.Fatalf("read outbox: %v", err)
+	}
+}
+
+func (w *sagaWorld) status(order *sagaOrder) orderDomain.OrderStatus {
+	var status string
+	if err := w.s.DbPool.QueryRow(w.s.Ctx, "SELECT status FROM orders WHERE id = $1", order.id).Scan(&status); err != nil {
+		w.t.Fatalf("order %d status: %v", order.id, err)
+	}
+
+	return orderDomain.OrderStatus(status)
+}
+
+func (w *sagaWorld) checkInvariants() {
+	for p, stock := range w.stock {
+		if stock < 0 {
+			w.t.Fatalf("product %d: stock went negative: %d", p, stock)
+		}
+		if stock > w.initial[p] {
+			w.t.Fatalf("product %d: stock %d is above the %d there ever was", p, stock, w.initial[p])
+		}
+	}
+
+	for _, order := range w.orders {
+		for p, released := range order.released {
+			if released > order.reserved[p] {
+				w.t.Fatalf("order %d: released %d of product %d but reserved %d", order.id, released, p, order.reserved[p])
+			}
+		}
+
+		switch w.status(order) {
+		case orderDomain.OrderStatusPaid:
+			order.wasPaid = true
+		case orderDomain.OrderStatusCancelled:
+			order.wasCancelled = true
+		}
+		if order.wasPaid && order.wasCancelled {
+			w.t.Fatalf("order %d was both paid and cancelled", order.id)
+		}
+	}
+}
+
+// drain lets the saga run to its end: every message arrives, customers
+// still deciding drop what is unavailable, and unpaid reservations time out.
+func (w *sagaWorld) drain() {
+	for round := 0; ; round++ {
+		if round > 10 {
+			w.t.Fatalf("saga did not settle")
+		}
+
+		for len(w.pending) > 0 {
+			m := w.pending[0]
+			w.pending = w.pending[1:]
+			w.delivered = append(w.delivered, m)
+			w.deliver(m)
+			w.sync()
+			w.checkInvariants()
+		}
+
+		for _, order := range w.orders {
+			switch w.status(order) {
+			case orderDomain.OrderStatusPartiallyReserved, orderDomain.OrderStatusAwaitingStock:
+				w.resolve(order, pb.PartialReservationChoice_PARTIAL_RESERVATION_CHOICE_REMOVE_UNAVAILABLE)
+			}
+		}
+		w.sync()
+		w.checkInvariants()
+
+		if len(w.pending) > 0 {
+			continue
+		}
+
+		if _, err := w.s.OrderService.ExpireUnpaidOrders(w.s.Ctx, time.Now().Add(time.Hour)); err != nil {
+			w.t.Fatalf("expire unpaid orders: %v", err)
+		}
+		w.sync()
+		w.checkInvariants()
+
+		if len(w.pending) == 0 {
+			return
+		}
+	}
+}
+
+// checkSettled asserts every reservation ended up converted by a payment or
+// released back to stock.
+func (w *sagaWorld) checkSettled() {
+	for _, order := range w.orders {
+		status := w.status(order)
+
+		for p, reserved := range order.reserved {
+			released := order.released[p]
+
+			switch status {
+			case orderDomain.OrderStatusPaid:
+				if released != 0 {
+					w.t.Fatalf("paid order %d released %d of product %d", order.id, released, p)
+				}
+			case orderDomain.OrderStatusCancelled:
+				if released != reserved {
+					w.t.Fatalf("cancelled order %d released %d of the %d reserved of product %d", order.id, released, reserved, p)
+				}
+			default:
+				w.t.Fatalf("order %d holds a reservation but settled as %s", order.id, status)
+			}
+		}
+	}
+}
@@ -4,12 +4,12 @@ import (
 	"context"
 	"testing"
 
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	orderDomain "github.com/sakashimaa/go-pet-project/order/internal/domain"
 	"github.com/sakashimaa/go-pet-project/order/internal/repository"
 	"github.com/sakashimaa/go-pet-project/order/internal/service"
 	"github.com/sakashimaa/go-pet-project/pkg/domain"
 	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/objectstore"
 	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
 	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
 	"github.com/sakashimaa/go-pet-project/pkg/testsuite"
@@ -24,6 +24,7 @@ type IntegrationTestSuite struct {
 	OrderService    service.OrderService
 	TestProducer    kafka2.Producer
 	OutboxProcessor *worker.OutboxProcessor
+	InvoiceStore    objectstore.Store
 	workerCancel    context.CancelFunc
 }
 
@@ -40,16 +41,31 @@ func (s *IntegrationTestSuite) SetupTest() {
 	s.BaseSuite.TruncateTable("users")
 	s.BaseSuite.TruncateTable("order_items")
 	s.BaseSuite.TruncateTable("outbox")
+	s.BaseSuite.TruncateTable("invoices")
+	s.BaseSuite.TruncateTable("invoice_sequences")
+	s.BaseSuite.TruncateTable("addresses")
+	s.BaseSuite.TruncateTable("purchase_caps")
+	s.BaseSuite.TruncateTable("order_returns")
+	s.BaseSuite.TruncateTable("order_annotations")
 
 	logger := zap.NewNop()
 	orderRepo := repository.NewOrderRepository(s.DbPool, logger)
-	outboxRepo := outboxRepository.NewOutboxRepository(s.DbPool, logger)
+	outboxRepo := outboxRepository.NewOutboxRepository(s.DbPool, logger, "order-service")
 
 	var err error
-	s.TestProducer, err = kafka2.NewProducer(s.KafkaBrokers)
+	s.TestProducer, err = kafka2.NewProducer(kafka2.Config{Brokers: s.KafkaBrokers})
 	s.Require().NoError(err, "failed to create kafka producer")
 
-	s.OrderService = service.NewOrderService(s.DbPool, logger, orderRepo, outboxRepo)
+	s.InvoiceStore = objectstore.NewFileStore(s.T().TempDir())
+
+	s.OrderService = service.NewOrderService(s.DbPool, logger, orderRepo, outboxRepo,
+		service.WithInvoicing(s.InvoiceStore, orderDomain.InvoiceIssuer{
+			Name:       "Test Shop Ltd",
+			Address:    "1 Test Street",
+			Currency:   "USD",
+			TaxRateBps: testTaxRateBps,
+		}),
+	)
 
 	s.OutboxProcessor = worker.NewOutboxProcessor(s.DbPool, outboxRepo, s.TestProducer, logger)
 
@@ -91,8 +107,9 @@ func (s *IntegrationTestSuite) createOrder(userId int64) *pb.CreateOrderResponse
 	}
 
 	resp, err := s.OrderService.CreateOrder(s.Ctx, &pb.CreateOrderRequest{
-		UserId: 999,
-		Items:  pbItems,
+		UserId:            userId,
+		Items:             pbItems,
+		ShippingAddressId: s.addAddress(s.Ctx, userId),
 	})
 	s.Require().NoError(err)
 	s.Require().NotNil(resp)
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) createTenantOrder(tenantID string, userId int64) int64 {
+	item := domain.OrderItem{ProductID: 1, Name: "Kuronami No Yaiba", Price: 5350, Quantity: 1}
+
+	ctx := tenant.WithID(s.Ctx, tenantID)
+	resp, err := s.OrderService.CreateOrder(ctx, &pb.CreateOrderRequest{
+		UserId:            userId,
+		Items:             []*pb.OrderItem{item.ToPB()},
+		ShippingAddressId: s.addAddress(ctx, userId),
+	})
+	s.Require().NoError(err)
+
+	return resp.OrderId
+}
+
+func (s *IntegrationTestSuite) TestTenant_OrdersStampedWithTenant() {
+	s.seedData(999, "test@example.com")
+
+	orderID := s.createTenantOrder("shop-a", 999)
+
+	var orderTenant, outboxTenant string
+	err := s.DbPool.QueryRow(s.Ctx, `SELECT tenant_id FROM orders WHERE id = $1`, orderID).Scan(&orderTenant)
+	s.Require().NoError(err)
+	s.Equal("shop-a", orderTenant)
+
+	err = s.DbPool.QueryRow(s.Ctx, `
+		SELECT tenant_id FROM outbox WHERE aggregate_id = $1 AND event_type = 'OrderCreated'
+	`, fmt.Sprintf("%d", orderID)).Scan(&outboxTenant)
+	s.Require().NoError(err)
+	s.Equal("shop-a", outboxTenant)
+}
+
+func (s *IntegrationTestSuite) TestTenant_OrdersHiddenFromOtherTenants() {
+	s.seedData(999, "test@example.com")
+
+	orderID := s.createTenantOrder("shop-a", 999)
+	s.createTenantOrder("shop-b", 999)
+
+	res, err := s.OrderService.ListUserOrders(tenant.WithID(s.Ctx, "shop-a"), &pb.ListUserOrdersRequest{UserId: 999})
+	s.Require().NoError(err)
+	s.Require().Len(res.Orders, 1)
+	s.Equal(orderID, res.Orders[0].OrderId)
+
+	_, err = s.OrderService.GetOrderTimeline(tenant.WithID(s.Ctx, "shop-b"), &pb.GetOrderTimelineRequest{
+		OrderId: orderID,
+		UserId:  999,
+	})
+	s.ErrorIs(err, repository.ErrOrderNotFound)
+}
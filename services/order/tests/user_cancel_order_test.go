@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+	"github.com/sakashimaa/go-pet-project/order/internal/service"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/order"
+)
+
+func (s *IntegrationTestSuite) cancelledEventItems(orderId int64) ([]generalDomain.OrderItem, bool) {
+	var payload []byte
+	err := s.DbPool.QueryRow(s.Ctx, `
+		SELECT payload
+		FROM outbox
+		WHERE aggregate_id = $1 AND event_type = 'OrderCancelled'
+	`, fmt.Sprintf("%d", orderId)).Scan(&payload)
+	if err != nil {
+		return nil, false
+	}
+
+	var envelope struct {
+		Payload generalDomain.OrderCancelledEvent `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &envelope))
+
+	return envelope.Payload.Items, true
+}
+
+func (s *IntegrationTestSuite) TestCancelOrderByUser_ReservedReturnsStock() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+
+	err := s.OrderService.HandleInventoryReserved(s.Ctx, &domain.InventoryReservedEvent{
+		OrderID:    resp.OrderId,
+		UserID:     999,
+		Amount:     5350,
+		ReservedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	res, err := s.OrderService.CancelOrderByUser(s.Ctx, &pb.CancelOrderRequest{OrderId: resp.OrderId, UserId: 999})
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderStatusCancelled), res.Status)
+
+	items, ok := s.cancelledEventItems(resp.OrderId)
+	s.Require().True(ok, "Reserved stock must be returned")
+	s.Require().Len(items, 1)
+	s.Require().Equal(int64(1), items[0].ProductID)
+}
+
+func (s *IntegrationTestSuite) TestCancelOrderByUser_NewReleasesLateReservation() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+
+	_, err := s.OrderService.CancelOrderByUser(s.Ctx, &pb.CancelOrderRequest{OrderId: resp.OrderId, UserId: 999})
+	s.Require().NoError(err)
+
+	_, ok := s.cancelledEventItems(resp.OrderId)
+	s.Require().False(ok, "Nothing is reserved yet")
+
+	err = s.OrderService.HandleInventoryReserved(s.Ctx, &domain.InventoryReservedEvent{
+		OrderID:    resp.OrderId,
+		UserID:     999,
+		Amount:     5350,
+		ReservedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	items, ok := s.cancelledEventItems(resp.OrderId)
+	s.Require().True(ok, "The late reservation must be released")
+	s.Require().Len(items, 1)
+
+	var status string
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT status FROM orders WHERE id = $1", resp.OrderId).Scan(&status)
+	s.Require().NoError(err)
+	s.Require().Equal(string(domain.OrderStatusCancelled), status)
+}
+
+func (s *IntegrationTestSuite) TestCancelOrderByUser_Failure() {
+	s.seedData(999, "test@example.com")
+	resp := s.createOrder(999)
+
+	_, err := s.OrderService.CancelOrderByUser(s.Ctx, &pb.CancelOrderRequest{OrderId: resp.OrderId, UserId: 1000})
+	s.Require().ErrorIs(err, repository.ErrOrderNotFound)
+
+	err = s.OrderService.ChangeOrderStatusPaymentSucceeded(s.Ctx, &generalDomain.PaymentSucceededEvent{
+		OrderID:   resp.OrderId,
+		PaymentID: 1,
+		Amount:    5350,
+		PaidAt:    time.Now(),
+	})
+	s.Require().NoError(err)
+
+	_, err = s.OrderService.CancelOrderByUser(s.Ctx, &pb.CancelOrderRequest{OrderId: resp.OrderId, UserId: 999})
+	s.Require().ErrorIs(err, service.ErrOrderNotCancellable)
+}
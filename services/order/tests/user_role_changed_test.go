@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/order/internal/domain"
+	"github.com/sakashimaa/go-pet-project/order/internal/repository"
+)
+
+func (s *IntegrationTestSuite) TestUserRoleChanged_Success() {
+	var id int64 = 1
+	s.seedData(id, "test@example.com")
+
+	err := s.OrderService.HandleUserRoleChanged(s.Ctx, &domain.UserRoleChangedEvent{
+		UserID: id,
+		Role:   domain.RoleAdmin,
+	})
+	s.Require().NoError(err)
+
+	var dbRole string
+	query := `
+		SELECT role
+		FROM users
+		WHERE id = $1
+	`
+
+	err = s.DbPool.QueryRow(s.Ctx, query, id).
+		Scan(&dbRole)
+	s.Require().NoError(err)
+	s.Require().Equal(domain.RoleAdmin, dbRole)
+}
+
+func (s *IntegrationTestSuite) TestUserRoleChanged_UnknownUser_Failure() {
+	err := s.OrderService.HandleUserRoleChanged(s.Ctx, &domain.UserRoleChangedEvent{
+		UserID: 12345,
+		Role:   domain.RoleAdmin,
+	})
+	s.Require().ErrorIs(err, repository.ErrUserNotFound)
+}
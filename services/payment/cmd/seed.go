@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	outbox "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/seed"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	"go.uber.org/zap"
+)
+
+var seedCardBrands = seed.NewWeighted([]string{"visa", "mastercard", "amex"}, []float64{55, 35, 10})
+
+// seedPaymentMethods saves cards for users who have paid before. Payments
+// themselves are not seeded here: they come from the saga once order-service
+// is seeded, like in production.
+func seedPaymentMethods(ctx context.Context, opts seed.Options) error {
+	pool, err := db.NewPostgresDB(utils.ParseWithFallback("DB_URL", ""))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	rows, err := pool.Query(ctx, `SELECT DISTINCT user_id FROM payments WHERE tenant_id = $1 ORDER BY user_id`, tenant.DefaultID)
+	if err != nil {
+		return fmt.Errorf("error listing payers: %w", err)
+	}
+	payers, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("error listing payers: %w", err)
+	}
+	if len(payers) == 0 {
+		return fmt.Errorf("no payers to save cards for: seed order-service first and let the saga run")
+	}
+
+	logger := zap.NewNop()
+	paymentService := service.NewPaymentService(
+		pool,
+		repository.NewPaymentRepository(pool, logger),
+		repository.NewPaymentMethodRepository(pool, logger),
+		repository.NewLedgerRepository(pool, logger),
+		repository.NewGiftCardRepository(pool, logger),
+		repository.NewRefundRepository(pool, logger),
+		outbox.NewOutboxRepository(pool, logger, "payment-service"),
+		logger,
+	)
+
+	now := time.Now()
+
+	return seed.Run(ctx, opts, "payment methods", func(ctx context.Context, i int) error {
+		r := seed.ForRecord(opts.Seed, i)
+
+		return paymentService.AddPaymentMethod(ctx, &domain.PaymentMethod{
+			UserID:   seed.Pick(r, payers),
+			Provider: "stripe",
+			Token:    fmt.Sprintf("tok_seed_%d_%d", opts.Seed, i),
+			Brand:    seedCardBrands.Pick(r),
+			Last4:    fmt.Sprintf("%04d", r.IntN(10_000)),
+			ExpMonth: int32(1 + r.IntN(12)),
+			ExpYear:  int32(now.Year() + 1 + r.IntN(5)),
+		})
+	})
+}
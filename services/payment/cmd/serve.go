@@ -0,0 +1,324 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+	"github.com/sakashimaa/go-pet-project/payment/internal/transport/grpc"
+	"github.com/sakashimaa/go-pet-project/payment/internal/transport/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/app"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/config"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/debug"
+	"github.com/sakashimaa/go-pet-project/pkg/jobs"
+	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/lock"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	outbox "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/pkg/retention"
+	"github.com/sakashimaa/go-pet-project/pkg/servicestatus"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	pb "github.com/sakashimaa/go-pet-project/proto/payment"
+	statusPb "github.com/sakashimaa/go-pet-project/proto/status"
+	googleGrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthPb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// heartbeatConfig says how often the payment consumer beats and how old its
+// last beat may be before PaymentService is reported NOT_SERVING.
+type heartbeatConfig struct {
+	Interval   time.Duration `env:"HEARTBEAT_INTERVAL" env-default:"10s"`
+	StaleAfter time.Duration `env:"HEARTBEAT_STALE_AFTER" env-default:"45s"`
+}
+
+func serve(ctx context.Context) error {
+	tp, err := utils.InitTracer(ctx, "payment-service")
+	if err != nil {
+		log.Fatalf("Error init tracer: %v", err)
+	}
+
+	pool, err := db.NewPostgresDB(utils.ParseWithFallback("DB_URL", ""))
+	if err != nil {
+		log.Fatalf("Error creating postgres DB: %v", err)
+	}
+
+	loggerCfg, err := config.LoadLoggerConfig()
+	if err != nil {
+		log.Fatalf("failed to load logger config: %v", err)
+	}
+
+	logger, logLevel, err := config.NewLeveledLogger(loggerCfg)
+	if err != nil {
+		log.Fatalf("Error creating logger: %v", err)
+	}
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Fatalf("failed to sync logger: %v", err)
+		}
+	}()
+
+	runner := app.NewRunner(logger)
+	runner.Add(app.Component{Name: "telemetry", Stop: tp.Shutdown})
+
+	reloadConfig, err := config.LoadReloadConfig()
+	if err != nil {
+		log.Fatalf("Error loading config reload settings: %v", err)
+	}
+
+	configWatcher := config.NewWatcher(reloadConfig, logger)
+	if err := configWatcher.Load(ctx); err != nil {
+		log.Fatalf("Error loading tunables: %v", err)
+	}
+	configWatcher.Subscribe(config.SetLogLevel(logLevel))
+	runner.Add(app.Component{Name: "config watcher", Start: app.Loop(configWatcher.Start)})
+
+	runner.Add(app.Component{Name: "postgres", Stop: app.Func(pool.Close)})
+
+	locker := lock.NewPostgres(pool, logger)
+
+	mylogger.Info(
+		ctx,
+		logger,
+		"Payment service started!",
+	)
+
+	kafkaConfig, err := kafka2.LoadConfig("payment-service")
+	if err != nil {
+		log.Fatalf("error loading kafka config: %v", err)
+	}
+
+	feeBasisPoints, err := strconv.ParseInt(utils.ParseWithFallback("PAYMENT_FEE_BPS", "0"), 10, 64)
+	if err != nil || feeBasisPoints < 0 {
+		log.Fatalf("invalid PAYMENT_FEE_BPS: %v", err)
+	}
+
+	paymentRepo := repository.NewPaymentRepository(pool, logger)
+	paymentMethodRepo := repository.NewPaymentMethodRepository(pool, logger)
+	ledgerRepo := repository.NewLedgerRepository(pool, logger)
+	giftCardRepo := repository.NewGiftCardRepository(pool, logger)
+	refundRepo := repository.NewRefundRepository(pool, logger)
+	outboxRepo := outbox.NewOutboxRepository(pool, logger, "payment-service")
+	paymentService := service.NewPaymentService(
+		pool,
+		paymentRepo,
+		paymentMethodRepo,
+		ledgerRepo,
+		giftCardRepo,
+		refundRepo,
+		outboxRepo,
+		logger,
+		service.WithFeeBasisPoints(feeBasisPoints),
+	)
+	paymentHandler := grpc.NewPaymentHandler(paymentService, logger)
+
+	consumer := kafka.NewConsumer(paymentService, logger)
+
+	kafkaProducer, err := kafka2.NewProducer(kafkaConfig)
+	if err != nil {
+		log.Fatalf("error creating kafka producer: %v", err)
+	}
+	runner.Add(app.Component{Name: "kafka producer", Stop: app.Closer(kafkaProducer.Close)})
+
+	messageFormat, err := kafka2.ParseMessageFormat(utils.ParseWithFallback("KAFKA_MESSAGE_FORMAT", "envelope"))
+	if err != nil {
+		log.Fatalf("error parsing kafka message format: %v", err)
+	}
+
+	encryptionConfig, err := kafka2.LoadEncryptionConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption config: %v", err)
+	}
+
+	keyring, err := encryptionConfig.Keyring()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption keys: %v", err)
+	}
+
+	outboxOpts := []worker.Option{worker.WithMessageFormat(messageFormat)}
+	if utils.ParseWithFallback("OUTBOX_LEADER_ELECTION", "false") == "true" {
+		outboxOpts = append(outboxOpts, worker.WithLeaderElection(worker.NewLockElector(locker, "payment-service", logger)))
+	}
+	if compacted := utils.ParseWithFallback("OUTBOX_COMPACT_EVENT_TYPES", ""); compacted != "" {
+		outboxOpts = append(outboxOpts, worker.WithCompaction(strings.Split(compacted, ",")...))
+	}
+	if keyring != nil {
+		outboxOpts = append(outboxOpts, worker.WithEncryption(keyring, encryptionConfig.Topics))
+	}
+
+	outboxProcessor := worker.NewOutboxProcessor(pool, outboxRepo, kafkaProducer, logger, outboxOpts...)
+	configWatcher.Subscribe(func(t config.Tunables) {
+		outboxProcessor.SetInterval(t.OutboxInterval)
+	})
+
+	runner.Add(app.Component{Name: "outbox processor", Start: app.Loop(outboxProcessor.Start)})
+
+	consumerConfig, err := kafka2.LoadConsumerConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka consumer config: %v", err)
+	}
+
+	scheduler := jobs.New(logger, jobs.WithLocks(locker))
+	runner.Add(app.Component{Name: "job scheduler", Start: app.Loop(scheduler.Start)})
+
+	inbox := kafka2.NewPostgresInbox(pool, "processed_messages")
+	inboxRetention := retention.NewJob(logger, retention.Policy{
+		Name:      "processed_messages",
+		Retention: consumerConfig.InboxRetention,
+		Purge:     retention.DeleteBefore(pool, "processed_messages", "processed_at"),
+	})
+	err = scheduler.Add(jobs.Job{
+		Name:     "inbox_retention",
+		Schedule: "@hourly",
+		Run:      inboxRetention.Run,
+	})
+	if err != nil {
+		log.Fatalf("Error scheduling jobs: %v", err)
+	}
+
+	var heartbeatCfg heartbeatConfig
+	if err := cleanenv.ReadEnv(&heartbeatCfg); err != nil {
+		log.Fatalf("Error loading heartbeat config: %v", err)
+	}
+
+	// The process answering is not enough for the saga: PaymentService only
+	// reports SERVING while the consumer keeps its heartbeat fresh.
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(pb.PaymentService_ServiceDesc.ServiceName, healthPb.HealthCheckResponse_NOT_SERVING)
+
+	heartbeat := service.NewHeartbeat(
+		repository.NewHeartbeatRepository(pool, logger),
+		kafka.GroupID,
+		heartbeatCfg.Interval,
+		heartbeatCfg.StaleAfter,
+		func(alive bool) {
+			status := healthPb.HealthCheckResponse_NOT_SERVING
+			if alive {
+				status = healthPb.HealthCheckResponse_SERVING
+			}
+			healthServer.SetServingStatus(pb.PaymentService_ServiceDesc.ServiceName, status)
+		},
+		logger,
+	)
+	runner.Add(app.Component{Name: "heartbeat", Start: app.Loop(heartbeat.Start)})
+
+	runner.Add(app.Component{
+		Name: "kafka consumer",
+		Start: app.Loop(func(ctx context.Context) {
+			consumer.Start(
+				ctx,
+				kafkaConfig,
+				kafka2.WithDecryption(keyring),
+				kafka2.WithSessionState(heartbeat.SetActive),
+				kafka2.WithConcurrency(consumerConfig.Concurrency),
+				kafka2.WithMiddleware(kafka2.Metrics(), kafka2.Logging(logger), kafka2.Deduplicate(inbox, logger)),
+			)
+		}),
+	})
+
+	lis, err := net.Listen("tcp", ":50054")
+	if err != nil {
+		log.Fatalf("Error listening on :50054 %v", err)
+	}
+
+	// The gateway already restricts gift card issuing and voiding to admins;
+	// checking again here keeps other callers inside the cluster from
+	// bypassing it. The balance sheet is for admins only as well.
+	isAdmin := func(ctx context.Context) error { return authctx.RequireRole(ctx, "admin") }
+
+	s := googleGrpc.NewServer(googleGrpc.ChainUnaryInterceptor(
+		tenant.UnaryServerInterceptor(),
+		authctx.UnaryServerInterceptor(),
+		authctx.RequireUnary(
+			isAdmin,
+			pb.PaymentService_GetBalanceSheet_FullMethodName,
+			pb.PaymentService_IssueGiftCard_FullMethodName,
+			pb.PaymentService_VoidGiftCard_FullMethodName,
+		),
+	))
+	pb.RegisterPaymentServiceServer(s, paymentHandler)
+	statusPb.RegisterStatusServiceServer(s, servicestatus.New(
+		"payment-service",
+		logger,
+		servicestatus.WithOutbox(pool),
+		servicestatus.WithConsumerGroups(kafkaConfig, servicestatus.ConsumerGroup{ID: kafka.GroupID, Topics: kafka.Topics}),
+	))
+	healthPb.RegisterHealthServer(s, healthServer)
+
+	reflectionCfg, err := config.LoadReflectionConfig()
+	if err != nil {
+		log.Fatalf("Error loading reflection config: %v", err)
+	}
+	if config.RegisterReflection(s, reflectionCfg) {
+		logger.Info("gRPC reflection is enabled, do not expose this port publicly")
+	}
+
+	runner.Add(app.Component{
+		Name: "grpc server",
+		Start: func(context.Context) error {
+			log.Println("gRPC server listening on 50054 🔥")
+			return s.Serve(lis)
+		},
+		Stop: app.Func(func() {
+			// Callers watching health see the shutdown before the server goes.
+			healthServer.Shutdown()
+			s.GracefulStop()
+		}),
+	})
+
+	reg := prometheus.NewRegistry()
+
+	reg.MustRegister(collectors.NewGoCollector())
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if err := config.RegisterLogMetrics(reg); err != nil {
+		log.Fatalf("Error registering log metrics: %v", err)
+	}
+
+	if err := jobs.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering job metrics: %v", err)
+	}
+	if err := lock.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering lock metrics: %v", err)
+	}
+	if err := retention.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering retention metrics: %v", err)
+	}
+	if err := kafka2.RegisterConsumerMetrics(reg); err != nil {
+		log.Fatalf("Error registering consumer metrics: %v", err)
+	}
+	if err := outbox.RegisterMetrics(reg, pool, "payment-service"); err != nil {
+		log.Fatalf("Error registering outbox metrics: %v", err)
+	}
+
+	debugConfig, err := debug.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading debug config: %v", err)
+	}
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", config.MetricsHandler(reg))
+	if err := debug.Mount(adminMux, debugConfig); err != nil {
+		log.Fatalf("Error mounting debug endpoints: %v", err)
+	}
+
+	runner.Add(app.HTTPServer("admin server", &http.Server{
+		Addr:              utils.ParseWithFallback("ADMIN_ADDR", ":9094"),
+		Handler:           adminMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}))
+
+	return runner.Run(ctx)
+}
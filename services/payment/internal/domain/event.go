@@ -7,4 +7,15 @@ type InventoryReservedEvent struct {
 	UserID     int64     `json:"user_id"`
 	Amount     int64     `json:"amount"`
 	ReservedAt time.Time `json:"reserved_at"`
+	// PaymentMethodID is the saved method the user chose at checkout.
+	PaymentMethodID *int64 `json:"payment_method_id,omitempty"`
+}
+
+// PaymentTimedOutEvent is emitted by order when it stopped waiting for the
+// payment result and cancelled the order.
+type PaymentTimedOutEvent struct {
+	OrderID    int64     `json:"order_id"`
+	UserID     int64     `json:"user_id"`
+	Amount     int64     `json:"amount"`
+	TimedOutAt time.Time `json:"timed_out_at"`
 }
@@ -0,0 +1,170 @@
+package domain
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// Limits on issuing a gift card. Amounts are in minor units.
+const (
+	MaxGiftCardAmount    = 100_000_000
+	MaxGiftCardValidDays = 5 * 365
+)
+
+// giftCardAlphabet leaves out 0, O, 1 and I, which are easy to misread on a
+// printed card. Its 32 letters divide 256, so every letter is equally likely.
+const giftCardAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const (
+	giftCardCodeLength = 16
+	giftCardGroupSize  = 4
+)
+
+// GiftCard is store credit. An admin issues it and hands the code to the
+// customer, who claims it onto their account; payments spend claimed cards
+// before charging the provider. Only a hash of the code is stored.
+type GiftCard struct {
+	ID            int64  `db:"id"`
+	Last4         string `db:"last4"`
+	InitialAmount int64  `db:"initial_amount"`
+	Balance       int64  `db:"balance"`
+	// UserID is nil until the card is claimed.
+	UserID    *int64     `db:"user_id"`
+	IssuedBy  int64      `db:"issued_by"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	ClaimedAt *time.Time `db:"claimed_at"`
+	VoidedAt  *time.Time `db:"voided_at"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Usable reports whether the card can still pay for something.
+func (c *GiftCard) Usable(now time.Time) bool {
+	return c.VoidedAt == nil && c.Balance > 0 && now.Before(c.ExpiresAt)
+}
+
+// GiftCardRedemption is the part of an order paid from one gift card. It is
+// held from before the provider is charged until the payment is recorded, and
+// given back if the provider declines. Refunded is the part returns put
+// back onto the card since.
+type GiftCardRedemption struct {
+	GiftCardID int64 `db:"gift_card_id"`
+	OrderID    int64 `db:"order_id"`
+	Amount     int64 `db:"amount"`
+	Refunded   int64 `db:"refunded"`
+}
+
+// NewGiftCardCode returns a random code such as ABCD-EFGH-JKLM-NPQR.
+func NewGiftCardCode() (string, error) {
+	raw := make([]byte, giftCardCodeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for i, v := range raw {
+		if i > 0 && i%giftCardGroupSize == 0 {
+			b.WriteByte('-')
+		}
+		b.WriteByte(giftCardAlphabet[int(v)%len(giftCardAlphabet)])
+	}
+
+	return b.String(), nil
+}
+
+// NormalizeGiftCardCode accepts a code however it was typed: any case, with
+// or without dashes and spaces.
+func NormalizeGiftCardCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '-' || r == ' ':
+			return -1
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		default:
+			return r
+		}
+	}, code)
+}
+
+// HashGiftCardCode is how a code is looked up. Codes are random enough that
+// an unsalted hash cannot be brute-forced.
+func HashGiftCardCode(code string) string {
+	sum := sha256.Sum256([]byte(NormalizeGiftCardCode(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+// GiftCardLast4 is the part of the code shown back to the customer.
+func GiftCardLast4(code string) string {
+	normalized := NormalizeGiftCardCode(code)
+	if len(normalized) < 4 {
+		return normalized
+	}
+
+	return normalized[len(normalized)-4:]
+}
+
+// PlanRedemptions spends cards in the given order until amount is covered or
+// the cards run out. Cards that cannot be used are skipped.
+func PlanRedemptions(cards []GiftCard, orderID, amount int64, now time.Time) []GiftCardRedemption {
+	var redemptions []GiftCardRedemption
+
+	for i := range cards {
+		if amount == 0 {
+			break
+		}
+		if !cards[i].Usable(now) {
+			continue
+		}
+
+		spend := min(cards[i].Balance, amount)
+		redemptions = append(redemptions, GiftCardRedemption{
+			GiftCardID: cards[i].ID,
+			OrderID:    orderID,
+			Amount:     spend,
+		})
+		amount -= spend
+	}
+
+	return redemptions
+}
+
+// PlanGiftCardRefunds puts amount back onto the cards of redemptions, in
+// the order they were spent, up to what each of them paid. The returned
+// redemptions carry the amount to credit each card.
+func PlanGiftCardRefunds(redemptions []GiftCardRedemption, amount int64) []GiftCardRedemption {
+	var credits []GiftCardRedemption
+
+	for _, r := range redemptions {
+		if amount == 0 {
+			break
+		}
+
+		credit := min(r.Amount-r.Refunded, amount)
+		if credit <= 0 {
+			continue
+		}
+
+		credits = append(credits, GiftCardRedemption{
+			GiftCardID: r.GiftCardID,
+			OrderID:    r.OrderID,
+			Amount:     credit,
+		})
+		amount -= credit
+	}
+
+	return credits
+}
+
+// RedeemedAmount sums redemptions.
+func RedeemedAmount(redemptions []GiftCardRedemption) int64 {
+	var total int64
+	for _, r := range redemptions {
+		total += r.Amount
+	}
+
+	return total
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrUnbalancedTransaction = errors.New("ledger transaction is unbalanced")
+
+const (
+	AccountCash           = "cash"
+	AccountRevenue        = "revenue"
+	AccountProcessingFees = "processing_fees"
+	AccountRefunds        = "refunds"
+	// AccountGiftCardLiability is the store credit customers can still spend.
+	AccountGiftCardLiability = "gift_card_liability"
+	// AccountGiftCardsIssued is what issuing that credit cost the shop.
+	AccountGiftCardsIssued = "gift_cards_issued"
+)
+
+const (
+	DirectionDebit  = "debit"
+	DirectionCredit = "credit"
+)
+
+const (
+	EntryTypePayment = "payment"
+	EntryTypeRefund  = "refund"
+	EntryTypeFee     = "fee"
+	// The gift card entry types must fit in 16 characters.
+	EntryTypeGiftCardIssue  = "gift_card_issue"
+	EntryTypeGiftCardRedeem = "gift_card_redeem"
+	EntryTypeGiftCardVoid   = "gift_card_void"
+)
+
+type LedgerEntry struct {
+	ID            int64  `db:"id"`
+	TransactionID string `db:"transaction_id"`
+	// PaymentID and OrderID are 0 for gift card issuance, which belongs to
+	// no payment. GiftCardID is 0 for everything else.
+	PaymentID  int64  `db:"payment_id"`
+	OrderID    int64  `db:"order_id"`
+	GiftCardID int64  `db:"gift_card_id"`
+	EntryType  string `db:"entry_type"`
+	Account    string `db:"account"`
+	Direction  string `db:"direction"`
+	Amount     int64  `db:"amount"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// DateRange is half-open: From is inclusive, To is exclusive. A zero bound
+// leaves that side open.
+type DateRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// AccountBalance sums the entries of one account. Balance is debits minus
+// credits, so across all accounts the balances add up to zero.
+type AccountBalance struct {
+	Account string
+	Debits  int64
+	Credits int64
+}
+
+func (b AccountBalance) Balance() int64 {
+	return b.Debits - b.Credits
+}
+
+// PaymentEntries books a captured payment: the part the provider charged
+// comes in as cash and the part paid by gift cards is taken off their
+// liability, together as revenue. The processor fee, if any, is paid out of
+// cash.
+func PaymentEntries(transactionID string, payment *Payment, fee int64) []LedgerEntry {
+	var entries []LedgerEntry
+
+	if cash := payment.Amount - payment.GiftCardAmount; cash > 0 {
+		entries = append(entries, entry(transactionID, payment, EntryTypePayment, AccountCash, DirectionDebit, cash))
+	}
+	if payment.GiftCardAmount > 0 {
+		entries = append(entries, entry(transactionID, payment, EntryTypeGiftCardRedeem, AccountGiftCardLiability, DirectionDebit, payment.GiftCardAmount))
+	}
+	entries = append(entries, entry(transactionID, payment, EntryTypePayment, AccountRevenue, DirectionCredit, payment.Amount))
+
+	if fee > 0 {
+		entries = append(entries,
+			entry(transactionID, payment, EntryTypeFee, AccountProcessingFees, DirectionDebit, fee),
+			entry(transactionID, payment, EntryTypeFee, AccountCash, DirectionCredit, fee),
+		)
+	}
+
+	return entries
+}
+
+// RefundEntries books money returned to the customer for a payment: the
+// cash part leaves cash and each gift card credit is owed again as
+// liability on its card.
+func RefundEntries(transactionID string, payment *Payment, cash int64, credits []GiftCardRedemption) []LedgerEntry {
+	entries := []LedgerEntry{
+		entry(transactionID, payment, EntryTypeRefund, AccountRefunds, DirectionDebit, cash+RedeemedAmount(credits)),
+	}
+
+	if cash > 0 {
+		entries = append(entries, entry(transactionID, payment, EntryTypeRefund, AccountCash, DirectionCredit, cash))
+	}
+	for _, credit := range credits {
+		e := entry(transactionID, payment, EntryTypeRefund, AccountGiftCardLiability, DirectionCredit, credit.Amount)
+		e.GiftCardID = credit.GiftCardID
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// GiftCardIssueEntries books the credit of a newly issued card.
+func GiftCardIssueEntries(transactionID string, card *GiftCard) []LedgerEntry {
+	return []LedgerEntry{
+		giftCardEntry(transactionID, card, EntryTypeGiftCardIssue, AccountGiftCardsIssued, DirectionDebit, card.InitialAmount),
+		giftCardEntry(transactionID, card, EntryTypeGiftCardIssue, AccountGiftCardLiability, DirectionCredit, card.InitialAmount),
+	}
+}
+
+// GiftCardVoidEntries reverses the unspent balance of a voided card.
+func GiftCardVoidEntries(transactionID string, card *GiftCard, balance int64) []LedgerEntry {
+	return []LedgerEntry{
+		giftCardEntry(transactionID, card, EntryTypeGiftCardVoid, AccountGiftCardLiability, DirectionDebit, balance),
+		giftCardEntry(transactionID, card, EntryTypeGiftCardVoid, AccountGiftCardsIssued, DirectionCredit, balance),
+	}
+}
+
+// ValidateEntries checks the double-entry invariant: amounts are positive
+// and each transaction debits exactly as much as it credits.
+func ValidateEntries(entries []LedgerEntry) error {
+	sums := make(map[string]int64)
+
+	for _, e := range entries {
+		if e.Amount <= 0 {
+			return fmt.Errorf("%w: non-positive amount %d on %s", ErrUnbalancedTransaction, e.Amount, e.Account)
+		}
+
+		switch e.Direction {
+		case DirectionDebit:
+			sums[e.TransactionID] += e.Amount
+		case DirectionCredit:
+			sums[e.TransactionID] -= e.Amount
+		default:
+			return fmt.Errorf("%w: unknown direction %q", ErrUnbalancedTransaction, e.Direction)
+		}
+	}
+
+	for transactionID, sum := range sums {
+		if sum != 0 {
+			return fmt.Errorf("%w: transaction %s is off by %d", ErrUnbalancedTransaction, transactionID, sum)
+		}
+	}
+
+	return nil
+}
+
+func entry(transactionID string, payment *Payment, entryType, account, direction string, amount int64) LedgerEntry {
+	return LedgerEntry{
+		TransactionID: transactionID,
+		PaymentID:     payment.ID,
+		OrderID:       payment.OrderID,
+		EntryType:     entryType,
+		Account:       account,
+		Direction:     direction,
+		Amount:        amount,
+	}
+}
+
+func giftCardEntry(transactionID string, card *GiftCard, entryType, account, direction string, amount int64) LedgerEntry {
+	return LedgerEntry{
+		TransactionID: transactionID,
+		GiftCardID:    card.ID,
+		EntryType:     entryType,
+		Account:       account,
+		Direction:     direction,
+		Amount:        amount,
+	}
+}
@@ -3,12 +3,17 @@ package domain
 import "time"
 
 type Payment struct {
-	ID            int64  `db:"id"`
-	OrderID       int64  `db:"order_id"`
-	UserID        int64  `db:"user_id"`
-	Status        string `db:"status"`
-	Amount        int64  `db:"amount"`
-	TransactionID string `db:"transaction_id"`
+	ID      int64  `db:"id"`
+	OrderID int64  `db:"order_id"`
+	UserID  int64  `db:"user_id"`
+	Status  string `db:"status"`
+	Amount  int64  `db:"amount"`
+	// GiftCardAmount is the part of Amount paid from gift cards; the
+	// provider was charged the rest.
+	GiftCardAmount int64  `db:"gift_card_amount"`
+	TransactionID  string `db:"transaction_id"`
+	// PaymentMethodID is the saved method that was charged, if any.
+	PaymentMethodID *int64 `db:"payment_method_id"`
 
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
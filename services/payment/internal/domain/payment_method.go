@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+// PaymentMethod is a card saved at the payment provider. Token is the
+// provider's reference to the card; the card number itself is never stored.
+type PaymentMethod struct {
+	ID       int64  `db:"id"`
+	UserID   int64  `db:"user_id"`
+	Provider string `db:"provider"`
+	Token    string `db:"token"`
+	Brand    string `db:"brand"`
+	Last4    string `db:"last4"`
+	ExpMonth int32  `db:"exp_month"`
+	ExpYear  int32  `db:"exp_year"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// Expired reports whether the card is past the end of its expiry month.
+func (m *PaymentMethod) Expired(now time.Time) bool {
+	endOfMonth := time.Date(int(m.ExpYear), time.Month(m.ExpMonth)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !now.UTC().Before(endOfMonth)
+}
+
+// Charge is what the service hands to the provider. Token is empty when the
+// order was placed without a saved method.
+type Charge struct {
+	OrderID  int64
+	UserID   int64
+	Amount   int64
+	Provider string
+	Token    string
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Refund is money sent back to the customer for a return. Amount never takes
+// the refunds of a payment above what it charged. GiftCardAmount of it is put
+// back onto the gift cards that paid and the rest goes to the charged card.
+type Refund struct {
+	ID        int64 `db:"id"`
+	PaymentID int64 `db:"payment_id"`
+	OrderID   int64 `db:"order_id"`
+	// ReturnID is the order service's return the refund is for, at most one
+	// refund each.
+	ReturnID       int64 `db:"return_id"`
+	Amount         int64 `db:"amount"`
+	GiftCardAmount int64 `db:"gift_card_amount"`
+
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// CashAmount is the part of the refund the provider pays out.
+func (r Refund) CashAmount() int64 {
+	return r.Amount - r.GiftCardAmount
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type GiftCardRepository interface {
+	Create(ctx context.Context, tx pgx.Tx, card *domain.GiftCard, codeHash string) error
+	Claim(ctx context.Context, codeHash string, userID int64) (*domain.GiftCard, error)
+	ListByUser(ctx context.Context, userID int64) ([]domain.GiftCard, error)
+	ListUsableForUpdate(ctx context.Context, tx pgx.Tx, userID int64) ([]domain.GiftCard, error)
+	Void(ctx context.Context, tx pgx.Tx, id int64) (card *domain.GiftCard, balance int64, err error)
+	Redeem(ctx context.Context, tx pgx.Tx, redemption domain.GiftCardRedemption) error
+	ListRefundableForUpdate(ctx context.Context, tx pgx.Tx, orderID int64) ([]domain.GiftCardRedemption, error)
+	RefundRedemption(ctx context.Context, tx pgx.Tx, credit domain.GiftCardRedemption) error
+	ReleaseRedemptions(ctx context.Context, tx pgx.Tx, orderID int64) error
+}
+
+type giftCardRepo struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+func NewGiftCardRepository(pool *pgxpool.Pool, logger *zap.Logger) GiftCardRepository {
+	return &giftCardRepo{
+		pool:   pool,
+		logger: logger,
+		tracer: otel.Tracer("repository/gift_card_repo"),
+	}
+}
+
+const giftCardColumns = `id, last4, initial_amount, balance, user_id, issued_by, expires_at, claimed_at, voided_at, created_at`
+
+func (r *giftCardRepo) Create(ctx context.Context, tx pgx.Tx, card *domain.GiftCard, codeHash string) error {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.Create")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("amount", card.InitialAmount),
+		attribute.Int64("issued_by", card.IssuedBy),
+	)
+
+	query := `
+		INSERT INTO gift_cards (code_hash, last4, initial_amount, balance, issued_by, expires_at, tenant_id)
+		VALUES ($1, $2, $3, $3, $4, $5, $6)
+		RETURNING ` + giftCardColumns + `;
+	`
+
+	err := scanGiftCard(tx.QueryRow(
+		ctx,
+		query,
+		codeHash,
+		card.Last4,
+		card.InitialAmount,
+		card.IssuedBy,
+		card.ExpiresAt,
+		tenant.FromContext(ctx),
+	), card)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to create gift card", zap.Error(err))
+
+		return fmt.Errorf("failed to create gift card: %w", err)
+	}
+
+	return nil
+}
+
+// Claim binds an unclaimed card to the user. Claiming a card the user
+// already holds returns it again; a card somebody else holds is reported as
+// not found, so codes cannot be probed for their owner.
+func (r *giftCardRepo) Claim(ctx context.Context, codeHash string, userID int64) (*domain.GiftCard, error) {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.Claim")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	tenantID := tenant.FromContext(ctx)
+
+	query := `
+		UPDATE gift_cards
+		SET user_id = $2, claimed_at = NOW()
+		WHERE code_hash = $1 AND tenant_id = $3 AND user_id IS NULL
+			AND voided_at IS NULL AND expires_at > NOW()
+		RETURNING ` + giftCardColumns + `;
+	`
+
+	var card domain.GiftCard
+	err := scanGiftCard(r.pool.QueryRow(ctx, query, codeHash, userID, tenantID), &card)
+	if err == nil {
+		return &card, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to claim gift card", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to claim gift card: %w", err)
+	}
+
+	lookup := `
+		SELECT ` + giftCardColumns + `
+		FROM gift_cards
+		WHERE code_hash = $1 AND tenant_id = $2;
+	`
+
+	if err := scanGiftCard(r.pool.QueryRow(ctx, lookup, codeHash, tenantID), &card); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrGiftCardNotFound
+		}
+
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to get gift card: %w", err)
+	}
+
+	switch {
+	case card.UserID != nil && *card.UserID == userID:
+		return &card, nil
+	case card.UserID != nil, card.VoidedAt != nil:
+		return nil, ErrGiftCardNotFound
+	default:
+		return nil, ErrGiftCardExpired
+	}
+}
+
+func (r *giftCardRepo) ListByUser(ctx context.Context, userID int64) ([]domain.GiftCard, error) {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.ListByUser")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		SELECT ` + giftCardColumns + `
+		FROM gift_cards
+		WHERE user_id = $1 AND tenant_id = $2
+		ORDER BY expires_at, id;
+	`
+
+	return r.list(ctx, span, r.pool, query, userID, tenant.FromContext(ctx))
+}
+
+// ListUsableForUpdate locks the cards a payment may spend, soonest to expire
+// first, so concurrent payments of the same user spend them one at a time.
+func (r *giftCardRepo) ListUsableForUpdate(ctx context.Context, tx pgx.Tx, userID int64) ([]domain.GiftCard, error) {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.ListUsableForUpdate")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		SELECT ` + giftCardColumns + `
+		FROM gift_cards
+		WHERE user_id = $1 AND tenant_id = $2
+			AND balance > 0 AND voided_at IS NULL AND expires_at > NOW()
+		ORDER BY expires_at, id
+		FOR UPDATE;
+	`
+
+	return r.list(ctx, span, tx, query, userID, tenant.FromContext(ctx))
+}
+
+// Void zeroes the balance of a card and returns it with the balance it had.
+func (r *giftCardRepo) Void(ctx context.Context, tx pgx.Tx, id int64) (*domain.GiftCard, int64, error) {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.Void")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("gift_card_id", id))
+
+	tenantID := tenant.FromContext(ctx)
+
+	lock := `
+		SELECT ` + giftCardColumns + `
+		FROM gift_cards
+		WHERE id = $1 AND tenant_id = $2
+		FOR UPDATE;
+	`
+
+	var card domain.GiftCard
+	if err := scanGiftCard(tx.QueryRow(ctx, lock, id, tenantID), &card); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, 0, ErrGiftCardNotFound
+		}
+
+		span.RecordError(err)
+
+		return nil, 0, fmt.Errorf("failed to get gift card: %w", err)
+	}
+
+	if card.VoidedAt != nil {
+		return nil, 0, ErrGiftCardVoided
+	}
+
+	balance := card.Balance
+
+	query := `
+		UPDATE gift_cards
+		SET balance = 0, voided_at = NOW()
+		WHERE id = $1
+		RETURNING voided_at;
+	`
+
+	var voidedAt time.Time
+	if err := tx.QueryRow(ctx, query, id).Scan(&voidedAt); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to void gift card", zap.Int64("gift_card_id", id), zap.Error(err))
+
+		return nil, 0, fmt.Errorf("failed to void gift card: %w", err)
+	}
+
+	card.Balance = 0
+	card.VoidedAt = &voidedAt
+
+	return &card, balance, nil
+}
+
+// Redeem takes the amount off the card and records what the order used. The
+// card must have been locked by ListUsableForUpdate in the same transaction.
+func (r *giftCardRepo) Redeem(ctx context.Context, tx pgx.Tx, redemption domain.GiftCardRedemption) error {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.Redeem")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("gift_card_id", redemption.GiftCardID),
+		attribute.Int64("order_id", redemption.OrderID),
+		attribute.Int64("amount", redemption.Amount),
+	)
+
+	debit := `
+		UPDATE gift_cards
+		SET balance = balance - $2
+		WHERE id = $1 AND balance >= $2;
+	`
+
+	tag, err := tx.Exec(ctx, debit, redemption.GiftCardID, redemption.Amount)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to debit gift card", zap.Error(err))
+
+		return fmt.Errorf("failed to debit gift card: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrGiftCardBalanceTooLow
+	}
+
+	insert := `
+		INSERT INTO gift_card_redemptions (gift_card_id, order_id, amount, tenant_id)
+		VALUES ($1, $2, $3, $4);
+	`
+
+	if _, err := tx.Exec(ctx, insert, redemption.GiftCardID, redemption.OrderID, redemption.Amount, tenant.FromContext(ctx)); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to record gift card redemption", zap.Error(err))
+
+		return fmt.Errorf("failed to record gift card redemption: %w", err)
+	}
+
+	return nil
+}
+
+// ListRefundableForUpdate returns the redemptions of an order that returns
+// can still put money back from, with their cards locked until tx ends.
+// Voided cards are left out: the shop took that credit back.
+func (r *giftCardRepo) ListRefundableForUpdate(ctx context.Context, tx pgx.Tx, orderID int64) ([]domain.GiftCardRedemption, error) {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.ListRefundableForUpdate")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	query := `
+		SELECT r.gift_card_id, r.order_id, r.amount, r.refunded
+		FROM gift_card_redemptions r
+		JOIN gift_cards g ON g.id = r.gift_card_id
+		WHERE r.order_id = $1 AND r.tenant_id = $2 AND r.refunded < r.amount AND g.voided_at IS NULL
+		ORDER BY r.id
+		FOR UPDATE OF g;
+	`
+
+	return r.listRedemptions(ctx, span, tx, query, orderID, tenant.FromContext(ctx))
+}
+
+// RefundRedemption puts credit.Amount back onto the card and counts it
+// against what the order redeemed from it. The card must have been locked by
+// ListRefundableForUpdate in the same transaction.
+func (r *giftCardRepo) RefundRedemption(ctx context.Context, tx pgx.Tx, credit domain.GiftCardRedemption) error {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.RefundRedemption")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("gift_card_id", credit.GiftCardID),
+		attribute.Int64("order_id", credit.OrderID),
+		attribute.Int64("amount", credit.Amount),
+	)
+
+	track := `
+		UPDATE gift_card_redemptions
+		SET refunded = refunded + $3
+		WHERE gift_card_id = $1 AND order_id = $2 AND refunded + $3 <= amount;
+	`
+
+	tag, err := tx.Exec(ctx, track, credit.GiftCardID, credit.OrderID, credit.Amount)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to refund gift card redemption", zap.Error(err))
+
+		return fmt.Errorf("failed to refund gift card redemption: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrGiftCardRedemptionNotFound
+	}
+
+	restore := `
+		UPDATE gift_cards
+		SET balance = balance + $2
+		WHERE id = $1;
+	`
+
+	if _, err := tx.Exec(ctx, restore, credit.GiftCardID, credit.Amount); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to credit gift card", zap.Error(err))
+
+		return fmt.Errorf("failed to credit gift card: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseRedemptions gives the amounts held for an order back to its cards.
+func (r *giftCardRepo) ReleaseRedemptions(ctx context.Context, tx pgx.Tx, orderID int64) error {
+	ctx, span := r.tracer.Start(ctx, "GiftCardRepository.ReleaseRedemptions")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	query := `
+		WITH released AS (
+			DELETE FROM gift_card_redemptions
+			WHERE order_id = $1 AND tenant_id = $2
+			RETURNING gift_card_id, amount
+		)
+		UPDATE gift_cards g
+		SET balance = g.balance + released.amount
+		FROM released
+		WHERE g.id = released.gift_card_id;
+	`
+
+	if _, err := tx.Exec(ctx, query, orderID, tenant.FromContext(ctx)); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to release gift card redemptions", zap.Int64("order_id", orderID), zap.Error(err))
+
+		return fmt.Errorf("failed to release gift card redemptions: %w", err)
+	}
+
+	return nil
+}
+
+func (r *giftCardRepo) listRedemptions(ctx context.Context, span trace.Span, q querier, query string, args ...any) ([]domain.GiftCardRedemption, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to list gift card redemptions: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.GiftCardRedemption
+	for rows.Next() {
+		var redemption domain.GiftCardRedemption
+		if err := rows.Scan(&redemption.GiftCardID, &redemption.OrderID, &redemption.Amount, &redemption.Refunded); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan gift card redemption: %w", err)
+		}
+
+		result = append(result, redemption)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func (r *giftCardRepo) list(ctx context.Context, span trace.Span, q querier, query string, args ...any) ([]domain.GiftCard, error) {
+	rows, err := q.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to list gift cards", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list gift cards: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.GiftCard
+	for rows.Next() {
+		var card domain.GiftCard
+		if err := scanGiftCard(rows, &card); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan gift card: %w", err)
+		}
+
+		result = append(result, card)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+func scanGiftCard(row pgx.Row, c *domain.GiftCard) error {
+	return row.Scan(
+		&c.ID,
+		&c.Last4,
+		&c.InitialAmount,
+		&c.Balance,
+		&c.UserID,
+		&c.IssuedBy,
+		&c.ExpiresAt,
+		&c.ClaimedAt,
+		&c.VoidedAt,
+		&c.CreatedAt,
+	)
+}
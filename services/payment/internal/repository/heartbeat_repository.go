@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// HeartbeatRepository keeps the last beat of each component. Times are the
+// database's, so replicas with skewed clocks agree on how old a beat is.
+type HeartbeatRepository interface {
+	Beat(ctx context.Context, component string) error
+	Alive(ctx context.Context, component string, within time.Duration) (bool, error)
+}
+
+type heartbeatRepo struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+func NewHeartbeatRepository(pool *pgxpool.Pool, logger *zap.Logger) HeartbeatRepository {
+	return &heartbeatRepo{
+		pool:   pool,
+		logger: logger,
+		tracer: otel.Tracer("repository/heartbeat_repo"),
+	}
+}
+
+func (r *heartbeatRepo) Beat(ctx context.Context, component string) error {
+	ctx, span := r.tracer.Start(ctx, "HeartbeatRepository.Beat")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("component", component))
+
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO heartbeats (component, beat_at)
+		VALUES ($1, NOW())
+		ON CONFLICT (component) DO UPDATE SET beat_at = EXCLUDED.beat_at;
+	`, component)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("error saving heartbeat: %w", err)
+	}
+
+	return nil
+}
+
+// Alive reports whether component beat within the last within.
+func (r *heartbeatRepo) Alive(ctx context.Context, component string, within time.Duration) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "HeartbeatRepository.Alive")
+	defer span.End()
+
+	span.SetAttributes(attribute.String("component", component))
+
+	var alive bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM heartbeats
+			WHERE component = $1 AND beat_at > NOW() - make_interval(secs => $2)
+		);
+	`, component, within.Seconds()).Scan(&alive)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("error reading heartbeat: %w", err)
+	}
+
+	return alive, nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type LedgerRepository interface {
+	RecordEntries(ctx context.Context, tx pgx.Tx, entries []domain.LedgerEntry) error
+	GetBalanceSheet(ctx context.Context, period domain.DateRange) ([]domain.AccountBalance, error)
+}
+
+type ledgerRepo struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+func NewLedgerRepository(pool *pgxpool.Pool, logger *zap.Logger) LedgerRepository {
+	return &ledgerRepo{
+		pool:   pool,
+		logger: logger,
+		tracer: otel.Tracer("repository/ledger_repo"),
+	}
+}
+
+func (r *ledgerRepo) RecordEntries(ctx context.Context, tx pgx.Tx, entries []domain.LedgerEntry) error {
+	ctx, span := r.tracer.Start(ctx, "LedgerRepository.RecordEntries")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("entries", len(entries)))
+
+	query := `
+		INSERT INTO ledger_entries (transaction_id, payment_id, order_id, gift_card_id, entry_type, account, direction, amount, tenant_id)
+		VALUES ($1, NULLIF($2, 0), NULLIF($3, 0), NULLIF($4, 0), $5, $6, $7, $8, $9);
+	`
+
+	tenantID := tenant.FromContext(ctx)
+
+	batch := &pgx.Batch{}
+	for _, e := range entries {
+		batch.Queue(query, e.TransactionID, e.PaymentID, e.OrderID, e.GiftCardID, e.EntryType, e.Account, e.Direction, e.Amount, tenantID)
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to record ledger entries", zap.Error(err))
+
+		return fmt.Errorf("failed to record ledger entries: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ledgerRepo) GetBalanceSheet(ctx context.Context, period domain.DateRange) ([]domain.AccountBalance, error) {
+	ctx, span := r.tracer.Start(ctx, "LedgerRepository.GetBalanceSheet")
+	defer span.End()
+
+	query := `
+		SELECT
+			account,
+			COALESCE(SUM(amount) FILTER (WHERE direction = 'debit'), 0) AS debits,
+			COALESCE(SUM(amount) FILTER (WHERE direction = 'credit'), 0) AS credits
+		FROM ledger_entries
+		WHERE tenant_id = $3
+			AND ($1::timestamp IS NULL OR created_at >= $1)
+			AND ($2::timestamp IS NULL OR created_at < $2)
+		GROUP BY account
+		ORDER BY account;
+	`
+
+	rows, err := r.pool.Query(ctx, query, nullableTime(period.From), nullableTime(period.To), tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to query balance sheet", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to query balance sheet: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.AccountBalance
+	for rows.Next() {
+		var b domain.AccountBalance
+		if err := rows.Scan(&b.Account, &b.Debits, &b.Credits); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan account balance: %w", err)
+		}
+
+		result = append(result, b)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+func nullableTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type PaymentMethodRepository interface {
+	Create(ctx context.Context, method *domain.PaymentMethod) error
+	ListByUser(ctx context.Context, userID int64) ([]domain.PaymentMethod, error)
+	GetByID(ctx context.Context, id, userID int64) (*domain.PaymentMethod, error)
+	Delete(ctx context.Context, id, userID int64) error
+}
+
+type paymentMethodRepo struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+func NewPaymentMethodRepository(pool *pgxpool.Pool, logger *zap.Logger) PaymentMethodRepository {
+	return &paymentMethodRepo{
+		pool:   pool,
+		logger: logger,
+		tracer: otel.Tracer("repository/payment_method_repo"),
+	}
+}
+
+func (r *paymentMethodRepo) Create(ctx context.Context, method *domain.PaymentMethod) error {
+	ctx, span := r.tracer.Start(ctx, "PaymentMethodRepository.Create")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", method.UserID),
+		attribute.String("provider", method.Provider),
+	)
+
+	query := `
+		INSERT INTO payment_methods (user_id, provider, token, brand, last4, exp_month, exp_year)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at;
+	`
+
+	err := r.pool.QueryRow(
+		ctx,
+		query,
+		method.UserID,
+		method.Provider,
+		method.Token,
+		method.Brand,
+		method.Last4,
+		method.ExpMonth,
+		method.ExpYear,
+	).Scan(&method.ID, &method.CreatedAt)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" && pgError.ConstraintName == "idx_payment_methods_provider_token_key" {
+			return ErrPaymentMethodExists
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to create payment method", zap.Error(err))
+
+		return fmt.Errorf("failed to create payment method: %w", err)
+	}
+
+	return nil
+}
+
+func (r *paymentMethodRepo) ListByUser(ctx context.Context, userID int64) ([]domain.PaymentMethod, error) {
+	ctx, span := r.tracer.Start(ctx, "PaymentMethodRepository.ListByUser")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("user_id", userID))
+
+	query := `
+		SELECT id, user_id, provider, token, brand, last4, exp_month, exp_year, created_at
+		FROM payment_methods
+		WHERE user_id = $1 AND deleted_at IS NULL
+		ORDER BY created_at DESC, id DESC;
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to list payment methods", zap.Error(err))
+
+		return nil, fmt.Errorf("failed to list payment methods: %w", err)
+	}
+	defer rows.Close()
+
+	var result []domain.PaymentMethod
+	for rows.Next() {
+		var m domain.PaymentMethod
+		if err := scanPaymentMethod(rows, &m); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan payment method: %w", err)
+		}
+
+		result = append(result, m)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *paymentMethodRepo) GetByID(ctx context.Context, id, userID int64) (*domain.PaymentMethod, error) {
+	ctx, span := r.tracer.Start(ctx, "PaymentMethodRepository.GetByID")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("payment_method_id", id))
+
+	query := `
+		SELECT id, user_id, provider, token, brand, last4, exp_month, exp_year, created_at
+		FROM payment_methods
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
+	`
+
+	var m domain.PaymentMethod
+	if err := scanPaymentMethod(r.pool.QueryRow(ctx, query, id, userID), &m); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPaymentMethodNotFound
+		}
+
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to get payment method: %w", err)
+	}
+
+	return &m, nil
+}
+
+// Delete soft-deletes the method, payments that used it keep their reference.
+func (r *paymentMethodRepo) Delete(ctx context.Context, id, userID int64) error {
+	ctx, span := r.tracer.Start(ctx, "PaymentMethodRepository.Delete")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("payment_method_id", id))
+
+	query := `
+		UPDATE payment_methods
+		SET deleted_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL;
+	`
+
+	tag, err := r.pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to delete payment method", zap.Error(err))
+
+		return fmt.Errorf("failed to delete payment method: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrPaymentMethodNotFound
+	}
+
+	return nil
+}
+
+func scanPaymentMethod(row pgx.Row, m *domain.PaymentMethod) error {
+	return row.Scan(
+		&m.ID,
+		&m.UserID,
+		&m.Provider,
+		&m.Token,
+		&m.Brand,
+		&m.Last4,
+		&m.ExpMonth,
+		&m.ExpYear,
+		&m.CreatedAt,
+	)
+}
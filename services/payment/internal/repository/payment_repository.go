@@ -6,9 +6,12 @@ import (
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository/sqlc"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -17,11 +20,14 @@ import (
 
 type PaymentRepository interface {
 	Create(ctx context.Context, tx pgx.Tx, payment *domain.Payment) error
+	Complete(ctx context.Context, tx pgx.Tx, payment *domain.Payment) error
 	GetOrderByID(ctx context.Context, orderID int64) (*domain.Payment, error)
 }
 
 type paymentRepo struct {
-	pool   *pgxpool.Pool
+	pool *pgxpool.Pool
+	// q holds the sqlc-generated queries from queries/*.sql.
+	q      *sqlc.Queries
 	logger *zap.Logger
 	tracer trace.Tracer
 }
@@ -29,6 +35,7 @@ type paymentRepo struct {
 func NewPaymentRepository(pool *pgxpool.Pool, logger *zap.Logger) PaymentRepository {
 	return &paymentRepo{
 		pool:   pool,
+		q:      sqlc.New(pool),
 		logger: logger,
 		tracer: otel.Tracer("repository/payment_repo"),
 	}
@@ -44,23 +51,22 @@ func (r *paymentRepo) Create(ctx context.Context, tx pgx.Tx, payment *domain.Pay
 		attribute.Int64("amount", payment.Amount),
 	)
 
-	query := `
-		INSERT INTO payments (order_id, user_id, amount, status, transaction_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
-		RETURNING id, created_at, updated_at
-	`
-
-	if err := tx.QueryRow(ctx, query,
-		payment.OrderID,
-		payment.UserID,
-		payment.Amount,
-		payment.Status,
-		payment.TransactionID,
-	).Scan(
-		&payment.ID,
-		&payment.CreatedAt,
-		&payment.UpdatedAt,
-	); err != nil {
+	row, err := r.q.WithTx(tx).CreatePayment(ctx, sqlc.CreatePaymentParams{
+		OrderID:         payment.OrderID,
+		UserID:          &payment.UserID,
+		Amount:          payment.Amount,
+		GiftCardAmount:  payment.GiftCardAmount,
+		Status:          payment.Status,
+		TransactionID:   payment.TransactionID,
+		PaymentMethodID: payment.PaymentMethodID,
+		TenantID:        tenant.FromContext(ctx),
+	})
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" && pgError.ConstraintName == "idx_payments_order_id_key" {
+			return ErrPaymentExists
+		}
+
 		span.RecordError(err)
 
 		mylogger.Warn(ctx, r.logger, "Create payment failed", zap.Error(err))
@@ -68,6 +74,41 @@ func (r *paymentRepo) Create(ctx context.Context, tx pgx.Tx, payment *domain.Pay
 		return err
 	}
 
+	payment.ID = row.ID
+	if row.CreatedAt != nil {
+		payment.CreatedAt = *row.CreatedAt
+	}
+	if row.UpdatedAt != nil {
+		payment.UpdatedAt = *row.UpdatedAt
+	}
+
+	return nil
+}
+
+// Complete records the outcome of a payment created as PENDING.
+func (r *paymentRepo) Complete(ctx context.Context, tx pgx.Tx, payment *domain.Payment) error {
+	ctx, span := r.tracer.Start(ctx, "PaymentRepository.Complete")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", payment.OrderID),
+		attribute.String("status", payment.Status),
+	)
+
+	err := r.q.WithTx(tx).CompletePayment(ctx, sqlc.CompletePaymentParams{
+		ID:              payment.ID,
+		Status:          payment.Status,
+		GiftCardAmount:  payment.GiftCardAmount,
+		PaymentMethodID: payment.PaymentMethodID,
+	})
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Warn(ctx, r.logger, "Complete payment failed", zap.Error(err))
+
+		return err
+	}
+
 	return nil
 }
 
@@ -75,15 +116,8 @@ func (r *paymentRepo) GetOrderByID(ctx context.Context, orderID int64) (*domain.
 	ctx, span := r.tracer.Start(ctx, "PaymentRepository.GetOrderByID")
 	defer span.End()
 
-	query := `
-		SELECT id, order_id, status
-		FROM payments
-		WHERE order_id = $1
-	`
-
-	var result domain.Payment
-	if err := r.pool.QueryRow(ctx, query, orderID).
-		Scan(&result.ID, &result.OrderID, &result.Status); err != nil {
+	row, err := r.q.GetPaymentByOrderID(ctx, sqlc.GetPaymentByOrderIDParams{OrderID: orderID, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
 		span.RecordError(err)
 
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -96,5 +130,9 @@ func (r *paymentRepo) GetOrderByID(ctx context.Context, orderID int64) (*domain.
 		return nil, fmt.Errorf("error getting order by id: %w", err)
 	}
 
-	return &result, nil
+	return &domain.Payment{
+		ID:      row.ID,
+		OrderID: row.OrderID,
+		Status:  row.Status,
+	}, nil
 }
@@ -0,0 +1,14 @@
+-- name: CreatePayment :one
+INSERT INTO payments (order_id, user_id, amount, gift_card_amount, status, transaction_id, payment_method_id, tenant_id, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+RETURNING id, created_at, updated_at;
+
+-- name: GetPaymentByOrderID :one
+SELECT id, order_id, status
+FROM payments
+WHERE order_id = $1 AND tenant_id = $2;
+
+-- name: CompletePayment :exec
+UPDATE payments
+SET status = $2, gift_card_amount = $3, payment_method_id = $4, updated_at = NOW()
+WHERE id = $1;
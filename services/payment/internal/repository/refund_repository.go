@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type RefundRepository interface {
+	LockPayment(ctx context.Context, tx pgx.Tx, orderID int64) (*domain.Payment, error)
+	GetByReturnID(ctx context.Context, tx pgx.Tx, returnID int64) (*domain.Refund, error)
+	RefundedAmount(ctx context.Context, tx pgx.Tx, paymentID int64) (total, giftCard int64, err error)
+	Create(ctx context.Context, tx pgx.Tx, refund *domain.Refund) error
+}
+
+type refundRepo struct {
+	pool   *pgxpool.Pool
+	logger *zap.Logger
+	tracer trace.Tracer
+}
+
+func NewRefundRepository(pool *pgxpool.Pool, logger *zap.Logger) RefundRepository {
+	return &refundRepo{
+		pool:   pool,
+		logger: logger,
+		tracer: otel.Tracer("repository/refund_repo"),
+	}
+}
+
+// LockPayment returns the order's payment locked until tx ends, so refunds
+// against it are booked one at a time.
+func (r *refundRepo) LockPayment(ctx context.Context, tx pgx.Tx, orderID int64) (*domain.Payment, error) {
+	ctx, span := r.tracer.Start(ctx, "RefundRepository.LockPayment")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("order_id", orderID))
+
+	query := `
+		SELECT id, order_id, user_id, status, amount, gift_card_amount, transaction_id
+		FROM payments
+		WHERE order_id = $1 AND tenant_id = $2
+		FOR UPDATE;
+	`
+
+	var (
+		payment domain.Payment
+		userID  *int64
+	)
+	err := tx.QueryRow(ctx, query, orderID, tenant.FromContext(ctx)).Scan(
+		&payment.ID,
+		&payment.OrderID,
+		&userID,
+		&payment.Status,
+		&payment.Amount,
+		&payment.GiftCardAmount,
+		&payment.TransactionID,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOrderNotFound
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to lock payment", zap.Int64("order_id", orderID), zap.Error(err))
+
+		return nil, fmt.Errorf("failed to lock payment: %w", err)
+	}
+	if userID != nil {
+		payment.UserID = *userID
+	}
+
+	return &payment, nil
+}
+
+// GetByReturnID returns the refund made for a return, or nil if there is
+// none yet.
+func (r *refundRepo) GetByReturnID(ctx context.Context, tx pgx.Tx, returnID int64) (*domain.Refund, error) {
+	ctx, span := r.tracer.Start(ctx, "RefundRepository.GetByReturnID")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("return_id", returnID))
+
+	query := `
+		SELECT id, payment_id, order_id, return_id, amount, gift_card_amount, created_at
+		FROM refunds
+		WHERE return_id = $1 AND tenant_id = $2;
+	`
+
+	var refund domain.Refund
+	err := tx.QueryRow(ctx, query, returnID, tenant.FromContext(ctx)).Scan(
+		&refund.ID,
+		&refund.PaymentID,
+		&refund.OrderID,
+		&refund.ReturnID,
+		&refund.Amount,
+		&refund.GiftCardAmount,
+		&refund.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+
+	return &refund, nil
+}
+
+// RefundedAmount sums what was refunded of a payment so far, and the part
+// of that put back onto gift cards.
+func (r *refundRepo) RefundedAmount(ctx context.Context, tx pgx.Tx, paymentID int64) (int64, int64, error) {
+	ctx, span := r.tracer.Start(ctx, "RefundRepository.RefundedAmount")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("payment_id", paymentID))
+
+	query := `
+		SELECT COALESCE(SUM(amount), 0)::BIGINT, COALESCE(SUM(gift_card_amount), 0)::BIGINT
+		FROM refunds
+		WHERE payment_id = $1;
+	`
+
+	var total, giftCard int64
+	if err := tx.QueryRow(ctx, query, paymentID).Scan(&total, &giftCard); err != nil {
+		span.RecordError(err)
+		return 0, 0, fmt.Errorf("failed to sum refunds: %w", err)
+	}
+
+	return total, giftCard, nil
+}
+
+func (r *refundRepo) Create(ctx context.Context, tx pgx.Tx, refund *domain.Refund) error {
+	ctx, span := r.tracer.Start(ctx, "RefundRepository.Create")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("payment_id", refund.PaymentID),
+		attribute.Int64("return_id", refund.ReturnID),
+		attribute.Int64("amount", refund.Amount),
+	)
+
+	query := `
+		INSERT INTO refunds (payment_id, order_id, return_id, amount, gift_card_amount, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at;
+	`
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		refund.PaymentID,
+		refund.OrderID,
+		refund.ReturnID,
+		refund.Amount,
+		refund.GiftCardAmount,
+		tenant.FromContext(ctx),
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" && pgError.ConstraintName == "idx_refunds_return_id_key" {
+			return ErrRefundExists
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(ctx, r.logger, "Failed to create refund", zap.Int64("return_id", refund.ReturnID), zap.Error(err))
+
+		return fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	return nil
+}
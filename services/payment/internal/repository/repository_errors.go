@@ -4,4 +4,22 @@ import "errors"
 
 var (
 	ErrOrderNotFound = errors.New("order not found")
+	// ErrPaymentExists means the order already has a payment, typically
+	// because its event was delivered more than once.
+	ErrPaymentExists = errors.New("payment already exists for this order")
+	// ErrRefundExists means the return was already refunded.
+	ErrRefundExists = errors.New("refund already exists for this return")
+	// ErrPaymentMethodNotFound also covers methods that were deleted or
+	// belong to another user, so their existence is not disclosed.
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+	ErrPaymentMethodExists   = errors.New("payment method already saved")
+	// ErrGiftCardNotFound also covers cards claimed by another user and
+	// voided cards.
+	ErrGiftCardNotFound      = errors.New("gift card not found")
+	ErrGiftCardExpired       = errors.New("gift card has expired")
+	ErrGiftCardVoided        = errors.New("gift card is already voided")
+	ErrGiftCardBalanceTooLow = errors.New("gift card balance is too low")
+	// ErrGiftCardRedemptionNotFound means a refund would put back more than
+	// the order paid from the card.
+	ErrGiftCardRedemptionNotFound = errors.New("gift card redemption not found")
 )
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+// IssueGiftCard creates a card worth amount that can be claimed for
+// validDays. The code is returned only here; afterwards the card is known by
+// its id and last four characters.
+func (s *paymentService) IssueGiftCard(ctx context.Context, issuedBy, amount int64, validDays int32) (*domain.GiftCard, string, error) {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.IssueGiftCard")
+	defer span.End()
+
+	switch {
+	case amount <= 0 || amount > domain.MaxGiftCardAmount:
+		return nil, "", fmt.Errorf("%w: amount must be between 1 and %d", ErrInvalidGiftCard, domain.MaxGiftCardAmount)
+	case validDays <= 0 || validDays > domain.MaxGiftCardValidDays:
+		return nil, "", fmt.Errorf("%w: validity must be between 1 and %d days", ErrInvalidGiftCard, domain.MaxGiftCardValidDays)
+	}
+
+	code, err := domain.NewGiftCardCode()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to generate gift card code: %w", err)
+	}
+
+	card := &domain.GiftCard{
+		Last4:         domain.GiftCardLast4(code),
+		InitialAmount: amount,
+		IssuedBy:      issuedBy,
+		ExpiresAt:     time.Now().UTC().AddDate(0, 0, int(validDays)),
+	}
+
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		if err := s.giftCardRepo.Create(ctx, tx, card, domain.HashGiftCardCode(code)); err != nil {
+			return err
+		}
+
+		entries := domain.GiftCardIssueEntries(uuid.New().String(), card)
+		if err := domain.ValidateEntries(entries); err != nil {
+			return err
+		}
+
+		return s.ledgerRepo.RecordEntries(ctx, tx, entries)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Gift card issued",
+		zap.Int64("gift_card_id", card.ID),
+		zap.Int64("amount", card.InitialAmount),
+		zap.Int64("issued_by", issuedBy),
+	)
+
+	return card, code, nil
+}
+
+// VoidGiftCard takes the unspent balance off a card, e.g. one issued by
+// mistake. What was already spent stays spent.
+func (s *paymentService) VoidGiftCard(ctx context.Context, id int64) (*domain.GiftCard, error) {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.VoidGiftCard")
+	defer span.End()
+
+	var card *domain.GiftCard
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var (
+			balance int64
+			err     error
+		)
+		card, balance, err = s.giftCardRepo.Void(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+
+		if balance == 0 {
+			return nil
+		}
+
+		entries := domain.GiftCardVoidEntries(uuid.New().String(), card, balance)
+		if err := domain.ValidateEntries(entries); err != nil {
+			return err
+		}
+
+		return s.ledgerRepo.RecordEntries(ctx, tx, entries)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	mylogger.Info(ctx, s.logger, "Gift card voided", zap.Int64("gift_card_id", id))
+
+	return card, nil
+}
+
+// ClaimGiftCard puts a card on the user's account, from where payments
+// spend it.
+func (s *paymentService) ClaimGiftCard(ctx context.Context, code string, userID int64) (*domain.GiftCard, error) {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.ClaimGiftCard")
+	defer span.End()
+
+	if domain.NormalizeGiftCardCode(code) == "" {
+		return nil, fmt.Errorf("%w: code is required", ErrInvalidGiftCard)
+	}
+
+	return s.giftCardRepo.Claim(ctx, domain.HashGiftCardCode(code), userID)
+}
+
+func (s *paymentService) ListGiftCards(ctx context.Context, userID int64) ([]domain.GiftCard, error) {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.ListGiftCards")
+	defer span.End()
+
+	return s.giftCardRepo.ListByUser(ctx, userID)
+}
+
+// redeemGiftCards holds the part of an order the user's gift cards cover
+// and returns it. It runs in the transaction that claimed the order with a
+// PENDING payment, so concurrent deliveries never plan against the same
+// cards, and a charge that fails gives the hold back with the rollback.
+func (s *paymentService) redeemGiftCards(ctx context.Context, tx pgx.Tx, event domain.InventoryReservedEvent) (int64, error) {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.redeemGiftCards")
+	defer span.End()
+
+	cards, err := s.giftCardRepo.ListUsableForUpdate(ctx, tx, event.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	redemptions := domain.PlanRedemptions(cards, event.OrderID, event.Amount, time.Now())
+	for _, redemption := range redemptions {
+		if err := s.giftCardRepo.Redeem(ctx, tx, redemption); err != nil {
+			return 0, err
+		}
+	}
+
+	redeemed := domain.RedeemedAmount(redemptions)
+	if redeemed > 0 {
+		mylogger.Info(
+			ctx,
+			s.logger,
+			"Gift cards redeemed",
+			zap.Int64("order_id", event.OrderID),
+			zap.Int64("amount", redeemed),
+		)
+	}
+
+	return redeemed, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+// Heartbeat tells the rest of the saga whether payments are being processed.
+// While the Kafka consumer holds a session it beats a row shared by every
+// replica; the consumer counts as alive while any replica beat within
+// staleAfter, so one replica losing its partitions in a rebalance does not
+// take the service down.
+type Heartbeat struct {
+	repo       repository.HeartbeatRepository
+	component  string
+	interval   time.Duration
+	staleAfter time.Duration
+	report     func(alive bool)
+	logger     *zap.Logger
+
+	active atomic.Bool
+}
+
+// NewHeartbeat beats for component every interval and passes whether it is
+// alive to report.
+func NewHeartbeat(
+	repo repository.HeartbeatRepository,
+	component string,
+	interval time.Duration,
+	staleAfter time.Duration,
+	report func(alive bool),
+	logger *zap.Logger,
+) *Heartbeat {
+	return &Heartbeat{
+		repo:       repo,
+		component:  component,
+		interval:   interval,
+		staleAfter: staleAfter,
+		report:     report,
+		logger:     logger,
+	}
+}
+
+// SetActive records whether the consumer holds a session; it is meant for
+// kafka.WithSessionState.
+func (h *Heartbeat) SetActive(active bool) {
+	h.active.Store(active)
+}
+
+// Start beats and reports every interval until ctx is done.
+func (h *Heartbeat) Start(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		h.tick(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick beats if the consumer is active here, then reports the shared row. A
+// row that cannot be read counts as stale.
+func (h *Heartbeat) tick(ctx context.Context) {
+	if h.active.Load() {
+		if err := h.repo.Beat(ctx, h.component); err != nil {
+			mylogger.Warn(ctx, h.logger, "Failed to beat", zap.String("component", h.component), zap.Error(err))
+		}
+	}
+
+	alive, err := h.repo.Alive(ctx, h.component, h.staleAfter)
+	if err != nil {
+		mylogger.Warn(ctx, h.logger, "Failed to read heartbeat", zap.String("component", h.component), zap.Error(err))
+		alive = false
+	}
+
+	h.report(alive)
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+var (
+	last4Pattern = regexp.MustCompile(`^[0-9]{4}$`)
+	// panPattern matches what looks like a raw card number. Providers hand
+	// out opaque tokens, so a bare run of digits means the client sent a PAN.
+	panPattern = regexp.MustCompile(`^[0-9 -]{12,23}$`)
+)
+
+func (s *paymentService) AddPaymentMethod(ctx context.Context, method *domain.PaymentMethod) error {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.AddPaymentMethod")
+	defer span.End()
+
+	if err := validatePaymentMethod(method, time.Now()); err != nil {
+		return err
+	}
+
+	if err := s.paymentMethodRepo.Create(ctx, method); err != nil {
+		return err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Payment method added",
+		zap.Int64("payment_method_id", method.ID),
+		zap.Int64("user_id", method.UserID),
+	)
+
+	return nil
+}
+
+func (s *paymentService) ListPaymentMethods(ctx context.Context, userID int64) ([]domain.PaymentMethod, error) {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.ListPaymentMethods")
+	defer span.End()
+
+	return s.paymentMethodRepo.ListByUser(ctx, userID)
+}
+
+func (s *paymentService) DeletePaymentMethod(ctx context.Context, id, userID int64) error {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.DeletePaymentMethod")
+	defer span.End()
+
+	return s.paymentMethodRepo.Delete(ctx, id, userID)
+}
+
+// charge asks the provider to take amount, what gift cards left of the
+// order, and approves a fully covered order without asking. A method that is
+// gone or expired declines the payment instead of failing the delivery, so
+// the order is cancelled rather than retried forever.
+func (s *paymentService) charge(ctx context.Context, event domain.InventoryReservedEvent, amount int64) (bool, *int64, error) {
+	if amount == 0 {
+		return true, nil, nil
+	}
+
+	charge := domain.Charge{
+		OrderID: event.OrderID,
+		UserID:  event.UserID,
+		Amount:  amount,
+	}
+
+	var paymentMethodID *int64
+	if event.PaymentMethodID != nil {
+		method, err := s.paymentMethodRepo.GetByID(ctx, *event.PaymentMethodID, event.UserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrPaymentMethodNotFound) {
+				mylogger.Warn(
+					ctx,
+					s.logger,
+					"Payment method not found, declining",
+					zap.Int64("order_id", event.OrderID),
+					zap.Int64("payment_method_id", *event.PaymentMethodID),
+				)
+
+				return false, nil, nil
+			}
+
+			return false, nil, err
+		}
+
+		if method.Expired(time.Now()) {
+			mylogger.Warn(
+				ctx,
+				s.logger,
+				"Payment method expired, declining",
+				zap.Int64("order_id", event.OrderID),
+				zap.Int64("payment_method_id", method.ID),
+			)
+
+			return false, &method.ID, nil
+		}
+
+		charge.Provider = method.Provider
+		charge.Token = method.Token
+		paymentMethodID = &method.ID
+	}
+
+	approved, err := s.provider.Charge(ctx, charge)
+	if err != nil {
+		return false, nil, err
+	}
+
+	return approved, paymentMethodID, nil
+}
+
+func validatePaymentMethod(method *domain.PaymentMethod, now time.Time) error {
+	switch {
+	case method.UserID <= 0:
+		return fmt.Errorf("%w: user id is required", ErrInvalidPaymentMethod)
+	case method.Provider == "":
+		return fmt.Errorf("%w: provider is required", ErrInvalidPaymentMethod)
+	case method.Token == "":
+		return fmt.Errorf("%w: token is required", ErrInvalidPaymentMethod)
+	case panPattern.MatchString(method.Token):
+		return fmt.Errorf("%w: token looks like a card number, send the provider token instead", ErrInvalidPaymentMethod)
+	case method.Last4 != "" && !last4Pattern.MatchString(method.Last4):
+		return fmt.Errorf("%w: last4 must be 4 digits", ErrInvalidPaymentMethod)
+	case method.ExpMonth < 1 || method.ExpMonth > 12:
+		return fmt.Errorf("%w: exp_month must be between 1 and 12", ErrInvalidPaymentMethod)
+	case method.Expired(now):
+		return fmt.Errorf("%w: card is expired", ErrInvalidPaymentMethod)
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
 	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
 	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
@@ -21,31 +22,82 @@ import (
 	"go.uber.org/zap"
 )
 
+const dateLayout = "2006-01-02"
+
 type PaymentService interface {
 	ProcessPayment(ctx context.Context, event domain.InventoryReservedEvent) error
+	HandlePaymentTimedOut(ctx context.Context, event domain.PaymentTimedOutEvent) error
+	GetBalanceSheet(ctx context.Context, period domain.DateRange) ([]domain.AccountBalance, error)
+	AddPaymentMethod(ctx context.Context, method *domain.PaymentMethod) error
+	ListPaymentMethods(ctx context.Context, userID int64) ([]domain.PaymentMethod, error)
+	DeletePaymentMethod(ctx context.Context, id, userID int64) error
+	IssueGiftCard(ctx context.Context, issuedBy, amount int64, validDays int32) (*domain.GiftCard, string, error)
+	VoidGiftCard(ctx context.Context, id int64) (*domain.GiftCard, error)
+	ClaimGiftCard(ctx context.Context, code string, userID int64) (*domain.GiftCard, error)
+	ListGiftCards(ctx context.Context, userID int64) ([]domain.GiftCard, error)
+	RefundReturn(ctx context.Context, event generalDomain.ReturnApprovedEvent) error
 }
 
 type paymentService struct {
-	pool        *pgxpool.Pool
-	paymentRepo repository.PaymentRepository
-	outboxRepo  worker.OutboxRepository
-	logger      *zap.Logger
-	tracer      trace.Tracer
+	pool              *pgxpool.Pool
+	paymentRepo       repository.PaymentRepository
+	paymentMethodRepo repository.PaymentMethodRepository
+	ledgerRepo        repository.LedgerRepository
+	giftCardRepo      repository.GiftCardRepository
+	refundRepo        repository.RefundRepository
+	outboxRepo        worker.OutboxRepository
+	provider          Provider
+	logger            *zap.Logger
+	tracer            trace.Tracer
+	feeBasisPoints    int64
+}
+
+type Option func(*paymentService)
+
+// WithFeeBasisPoints sets the processor fee booked for every captured
+// payment, in hundredths of a percent. The default is no fee.
+func WithFeeBasisPoints(bps int64) Option {
+	return func(s *paymentService) {
+		s.feeBasisPoints = bps
+	}
+}
+
+// WithProvider replaces the simulated provider.
+func WithProvider(provider Provider) Option {
+	return func(s *paymentService) {
+		s.provider = provider
+	}
 }
 
 func NewPaymentService(
 	pool *pgxpool.Pool,
 	paymentRepo repository.PaymentRepository,
+	paymentMethodRepo repository.PaymentMethodRepository,
+	ledgerRepo repository.LedgerRepository,
+	giftCardRepo repository.GiftCardRepository,
+	refundRepo repository.RefundRepository,
 	outboxRepo worker.OutboxRepository,
 	logger *zap.Logger,
+	opts ...Option,
 ) PaymentService {
-	return &paymentService{
-		pool:        pool,
-		paymentRepo: paymentRepo,
-		outboxRepo:  outboxRepo,
-		logger:      logger,
-		tracer:      otel.Tracer("service/payment_service"),
+	s := &paymentService{
+		pool:              pool,
+		paymentRepo:       paymentRepo,
+		paymentMethodRepo: paymentMethodRepo,
+		ledgerRepo:        ledgerRepo,
+		giftCardRepo:      giftCardRepo,
+		refundRepo:        refundRepo,
+		outboxRepo:        outboxRepo,
+		provider:          simulatedProvider{},
+		logger:            logger,
+		tracer:            otel.Tracer("service/payment_service"),
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 func (s *paymentService) ProcessPayment(ctx context.Context, event domain.InventoryReservedEvent) error {
@@ -83,91 +135,207 @@ func (s *paymentService) ProcessPayment(ctx context.Context, event domain.Invent
 		return nil
 	}
 
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error beginning transaction",
-			zap.Error(err),
-		)
-
-		return fmt.Errorf("error beginning transaction: %w", err)
+	payment := &domain.Payment{
+		OrderID:       event.OrderID,
+		UserID:        event.UserID,
+		Amount:        event.Amount,
+		Status:        "PENDING",
+		TransactionID: uuid.New().String(),
 	}
-	defer func() {
-		cleanupCtx := context.WithoutCancel(ctx)
-		err := tx.Rollback(cleanupCtx)
 
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+	// The PENDING row claims the order under idx_payments_order_id_key
+	// before anything is charged. A concurrent delivery blocks on the insert
+	// until this transaction ends and then finds the payment, so only one
+	// of them charges. If the commit fails the retry charges again, which
+	// the provider ignores.
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		if err := s.paymentRepo.Create(ctx, tx, payment); err != nil {
+			if !errors.Is(err, repository.ErrPaymentExists) {
+				mylogger.Warn(ctx, s.logger, "Payment create failed", zap.Error(err))
+			}
+			return err
+		}
+
+		giftCardAmount, err := s.redeemGiftCards(ctx, tx, event)
+		if err != nil {
+			mylogger.Warn(ctx, s.logger, "Gift card redemption failed", zap.Int64("order_id", event.OrderID), zap.Error(err))
+			return err
+		}
+
+		approved, paymentMethodID, err := s.charge(ctx, event, event.Amount-giftCardAmount)
+		if err != nil {
+			mylogger.Warn(ctx, s.logger, "Charge failed", zap.Int64("order_id", event.OrderID), zap.Error(err))
+			return err
+		}
+
+		var eventType string
+		var eventPayload any
+
+		if !approved {
+			payment.Status = "FAIL"
+			eventType = "PaymentFailed"
+			eventPayload = generalDomain.PaymentFailedEvent{
+				OrderID:  event.OrderID,
+				Amount:   event.Amount,
+				FailedAt: time.Now(),
+			}
+		} else {
+			payment.Status = "PAID"
+			payment.GiftCardAmount = giftCardAmount
+			eventType = "PaymentSucceeded"
+			eventPayload = generalDomain.PaymentSucceededEvent{
+				OrderID: event.OrderID,
+				Amount:  event.Amount,
+				PaidAt:  time.Now(),
+			}
+		}
+		payment.PaymentMethodID = paymentMethodID
+
+		if err := s.paymentRepo.Complete(ctx, tx, payment); err != nil {
+			return err
+		}
+
+		if !approved && giftCardAmount > 0 {
+			// The provider declined the rest, so the cards pay for nothing.
+			if err := s.giftCardRepo.ReleaseRedemptions(ctx, tx, event.OrderID); err != nil {
+				return err
+			}
+		}
+
+		if payment.Status == "PAID" {
+			if err := s.recordPaymentEntries(ctx, tx, payment); err != nil {
+				mylogger.Warn(ctx, s.logger, "Failed to record ledger entries", zap.Error(err))
+				return err
+			}
+		}
+
+		if err := s.emitEvent(ctx, tx, eventType, eventPayload); err != nil {
 			mylogger.Warn(
 				ctx,
 				s.logger,
-				"Error rolling back transaction",
+				"Failed to emit event",
 				zap.Error(err),
-				zap.String("method_name", "Register"),
-				zap.String("service", "auth_service"),
 			)
+
+			return err
 		}
-	}()
-
-	var status string
-	var eventType string
-	var eventPayload any
-
-	if event.OrderID%2 == 0 {
-		status = "FAIL"
-		eventType = "PaymentFailed"
-		eventPayload = generalDomain.PaymentFailedEvent{
-			OrderID:  event.OrderID,
-			Amount:   event.Amount,
-			FailedAt: time.Now(),
-		}
-	} else {
-		status = "PAID"
-		eventType = "PaymentSucceeded"
-		eventPayload = generalDomain.PaymentSucceededEvent{
-			OrderID: event.OrderID,
-			Amount:  event.Amount,
-			PaidAt:  time.Now(),
-		}
+
+		return nil
+	})
+	if errors.Is(err, repository.ErrPaymentExists) {
+		// A concurrent delivery of the same event claimed the order first
+		// and charged it; this one never reached the provider.
+		mylogger.Info(ctx, s.logger, "Payment already created concurrently", zap.Int64("order_id", event.OrderID))
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"ProcessPayment finished",
+		zap.Int64("order_id", event.OrderID),
+	)
+
+	return nil
+}
+
+// HandlePaymentTimedOut records the order as timed out, so a reservation
+// event delivered late does not charge an order that is already cancelled.
+func (s *paymentService) HandlePaymentTimedOut(ctx context.Context, event domain.PaymentTimedOutEvent) error {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.HandlePaymentTimedOut")
+	defer span.End()
+
+	existingPayment, err := s.paymentRepo.GetOrderByID(ctx, event.OrderID)
+	if err != nil {
+		return err
+	}
+	if existingPayment != nil {
+		// The order service refunds payments that arrive after the timeout.
+		mylogger.Warn(
+			ctx,
+			s.logger,
+			"Payment already exists for timed out order",
+			zap.Int64("order_id", event.OrderID),
+			zap.String("status", existingPayment.Status),
+		)
+
+		return nil
 	}
 
 	payment := &domain.Payment{
 		OrderID:       event.OrderID,
 		UserID:        event.UserID,
 		Amount:        event.Amount,
-		Status:        status,
+		Status:        "TIMED_OUT",
 		TransactionID: uuid.New().String(),
 	}
 
-	if err := s.paymentRepo.Create(ctx, tx, payment); err != nil {
-		mylogger.Warn(ctx, s.logger, "Payment create failed", zap.Error(err))
+	err = db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return s.paymentRepo.Create(ctx, tx, payment)
+	})
+	if errors.Is(err, repository.ErrPaymentExists) {
+		return nil
+	}
+	if err != nil {
 		return err
 	}
 
-	if err := s.emitEvent(ctx, tx, eventType, eventPayload); err != nil {
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Failed to emit event",
-			zap.Error(err),
-		)
+	mylogger.Info(ctx, s.logger, "Payment timed out", zap.Int64("order_id", event.OrderID))
 
-		return err
+	return nil
+}
+
+func (s *paymentService) GetBalanceSheet(ctx context.Context, period domain.DateRange) ([]domain.AccountBalance, error) {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.GetBalanceSheet")
+	defer span.End()
+
+	return s.ledgerRepo.GetBalanceSheet(ctx, period)
+}
+
+// ParseDateRange turns inclusive YYYY-MM-DD bounds into a half-open UTC
+// range. Unlike analytics, empty bounds stay open: the balance sheet
+// defaults to the whole ledger.
+func ParseDateRange(from, to string) (domain.DateRange, error) {
+	var period domain.DateRange
+
+	if from != "" {
+		parsed, err := time.Parse(dateLayout, from)
+		if err != nil {
+			return domain.DateRange{}, fmt.Errorf("%w: bad 'from' date", ErrInvalidRange)
+		}
+		period.From = parsed
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+	if to != "" {
+		parsed, err := time.Parse(dateLayout, to)
+		if err != nil {
+			return domain.DateRange{}, fmt.Errorf("%w: bad 'to' date", ErrInvalidRange)
+		}
+		period.To = parsed.AddDate(0, 0, 1)
 	}
 
-	mylogger.Info(
-		ctx,
-		s.logger,
-		"ProcessPayment finished",
-		zap.Int64("order_id", event.OrderID),
-	)
+	if !period.From.IsZero() && !period.To.IsZero() && !period.From.Before(period.To) {
+		return domain.DateRange{}, fmt.Errorf("%w: 'from' is after 'to'", ErrInvalidRange)
+	}
 
-	return nil
+	return period, nil
+}
+
+// recordPaymentEntries books a captured payment in the same transaction as
+// the payment row, so the ledger never disagrees with the payments table.
+func (s *paymentService) recordPaymentEntries(ctx context.Context, tx pgx.Tx, payment *domain.Payment) error {
+	// The processor only takes its cut of what it charged.
+	fee := (payment.Amount - payment.GiftCardAmount) * s.feeBasisPoints / 10_000
+
+	entries := domain.PaymentEntries(uuid.New().String(), payment, fee)
+	if err := domain.ValidateEntries(entries); err != nil {
+		return err
+	}
+
+	return s.ledgerRepo.RecordEntries(ctx, tx, entries)
 }
 
 func (s *paymentService) emitEvent(ctx context.Context, tx pgx.Tx, eventType string, payload any) error {
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+)
+
+// Provider charges customers at the payment processor.
+type Provider interface {
+	// Charge takes the money for an order. Processors key charges on the
+	// order, so retrying one that went through does not charge twice.
+	Charge(ctx context.Context, charge domain.Charge) (approved bool, err error)
+	// Refund sends the refund's CashAmount back for a charge. Processors
+	// key refunds on the return, so retrying one that went through does not
+	// pay out twice.
+	Refund(ctx context.Context, refund domain.Refund) error
+}
+
+// simulatedProvider stands in for a real processor: it approves orders with
+// odd ids and declines the rest, which keeps every saga path reachable.
+// Refunds always go through.
+type simulatedProvider struct{}
+
+func (simulatedProvider) Charge(_ context.Context, charge domain.Charge) (bool, error) {
+	return charge.OrderID%2 != 0, nil
+}
+
+func (simulatedProvider) Refund(context.Context, domain.Refund) error {
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// RefundReturn pays back an approved return and tells order with
+// RefundIssued. Refunds never take a payment below zero: what is left of it
+// is refunded when the return asks for more, and nothing when it is used up.
+// The provider pays back at most what it charged; the rest goes back onto
+// the gift cards the order was paid with.
+func (s *paymentService) RefundReturn(ctx context.Context, event generalDomain.ReturnApprovedEvent) error {
+	ctx, span := s.tracer.Start(ctx, "PaymentService.RefundReturn")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("return_id", event.ReturnID),
+		attribute.Int64("order_id", event.OrderID),
+		attribute.Int64("amount", event.Amount),
+	)
+
+	var refund *domain.Refund
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		payment, err := s.refundRepo.LockPayment(ctx, tx, event.OrderID)
+		if errors.Is(err, repository.ErrOrderNotFound) {
+			mylogger.Warn(ctx, s.logger, "Return of an order without a payment", zap.Int64("return_id", event.ReturnID))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		existing, err := s.refundRepo.GetByReturnID(ctx, tx, event.ReturnID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			mylogger.Info(ctx, s.logger, "Return already refunded", zap.Int64("return_id", event.ReturnID))
+			return nil
+		}
+
+		if payment.Status != "PAID" {
+			mylogger.Warn(
+				ctx,
+				s.logger,
+				"Return of an order that was not paid",
+				zap.Int64("return_id", event.ReturnID),
+				zap.String("status", payment.Status),
+			)
+			return nil
+		}
+
+		refunded, refundedToGiftCards, err := s.refundRepo.RefundedAmount(ctx, tx, payment.ID)
+		if err != nil {
+			return err
+		}
+
+		requested := min(event.Amount, payment.Amount-refunded)
+		charged := payment.Amount - payment.GiftCardAmount - (refunded - refundedToGiftCards)
+		cash := max(min(requested, charged), 0)
+
+		refundable, err := s.giftCardRepo.ListRefundableForUpdate(ctx, tx, payment.OrderID)
+		if err != nil {
+			return err
+		}
+		credits := domain.PlanGiftCardRefunds(refundable, max(requested-cash, 0))
+
+		amount := cash + domain.RedeemedAmount(credits)
+		if amount <= 0 {
+			mylogger.Warn(ctx, s.logger, "Payment already fully refunded", zap.Int64("return_id", event.ReturnID))
+			return nil
+		}
+
+		refund = &domain.Refund{
+			PaymentID:      payment.ID,
+			OrderID:        payment.OrderID,
+			ReturnID:       event.ReturnID,
+			Amount:         amount,
+			GiftCardAmount: amount - cash,
+		}
+
+		// The provider is called with the payment locked, so a concurrent
+		// delivery waits here and then finds the refund. If the commit
+		// fails the retry repeats the call, which the provider ignores.
+		if cash > 0 {
+			if err := s.provider.Refund(ctx, *refund); err != nil {
+				return fmt.Errorf("provider refund failed: %w", err)
+			}
+		}
+
+		if err := s.refundRepo.Create(ctx, tx, refund); err != nil {
+			return err
+		}
+
+		for _, credit := range credits {
+			if err := s.giftCardRepo.RefundRedemption(ctx, tx, credit); err != nil {
+				return err
+			}
+		}
+
+		entries := domain.RefundEntries(uuid.New().String(), payment, cash, credits)
+		if err := domain.ValidateEntries(entries); err != nil {
+			return err
+		}
+		if err := s.ledgerRepo.RecordEntries(ctx, tx, entries); err != nil {
+			return err
+		}
+
+		return s.emitEvent(ctx, tx, "RefundIssued", generalDomain.RefundIssuedEvent{
+			RefundID:   refund.ID,
+			ReturnID:   event.ReturnID,
+			OrderID:    payment.OrderID,
+			PaymentID:  payment.ID,
+			Amount:     amount,
+			RefundedAt: time.Now(),
+		})
+	})
+	if errors.Is(err, repository.ErrRefundExists) {
+		return nil
+	}
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Warn(ctx, s.logger, "Refund failed", zap.Int64("return_id", event.ReturnID), zap.Error(err))
+
+		return err
+	}
+
+	if refund != nil {
+		mylogger.Info(
+			ctx,
+			s.logger,
+			"Return refunded",
+			zap.Int64("return_id", event.ReturnID),
+			zap.Int64("order_id", event.OrderID),
+			zap.Int64("amount", refund.Amount),
+			zap.Int64("gift_card_amount", refund.GiftCardAmount),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,9 @@
+package service
+
+import "errors"
+
+var (
+	ErrInvalidRange         = errors.New("invalid date range")
+	ErrInvalidPaymentMethod = errors.New("invalid payment method")
+	ErrInvalidGiftCard      = errors.New("invalid gift card")
+)
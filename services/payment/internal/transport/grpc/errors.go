@@ -0,0 +1,25 @@
+package grpc
+
+import (
+	"errors"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+	"google.golang.org/grpc/codes"
+)
+
+func mapErrorCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, service.ErrInvalidRange), errors.Is(err, service.ErrInvalidPaymentMethod),
+		errors.Is(err, service.ErrInvalidGiftCard):
+		return codes.InvalidArgument
+	case errors.Is(err, repository.ErrPaymentMethodNotFound), errors.Is(err, repository.ErrGiftCardNotFound):
+		return codes.NotFound
+	case errors.Is(err, repository.ErrGiftCardExpired), errors.Is(err, repository.ErrGiftCardVoided):
+		return codes.FailedPrecondition
+	case errors.Is(err, repository.ErrPaymentMethodExists):
+		return codes.AlreadyExists
+	default:
+		return codes.Internal
+	}
+}
@@ -0,0 +1,214 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	pb "github.com/sakashimaa/go-pet-project/proto/payment"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+type PaymentHandler struct {
+	pb.UnimplementedPaymentServiceServer
+	service service.PaymentService
+	logger  *zap.Logger
+}
+
+func NewPaymentHandler(service service.PaymentService, logger *zap.Logger) *PaymentHandler {
+	return &PaymentHandler{service: service, logger: logger}
+}
+
+func (h *PaymentHandler) GetBalanceSheet(ctx context.Context, req *pb.GetBalanceSheetRequest) (*pb.GetBalanceSheetResponse, error) {
+	period, err := service.ParseDateRange(req.GetRange().GetFrom(), req.GetRange().GetTo())
+	if err != nil {
+		return nil, h.fail("GetBalanceSheet", err)
+	}
+
+	balances, err := h.service.GetBalanceSheet(ctx, period)
+	if err != nil {
+		return nil, h.fail("GetBalanceSheet", err)
+	}
+
+	res := &pb.GetBalanceSheetResponse{
+		Accounts: make([]*pb.AccountBalance, 0, len(balances)),
+	}
+	for _, b := range balances {
+		res.TotalDebits += b.Debits
+		res.TotalCredits += b.Credits
+		res.Accounts = append(res.Accounts, &pb.AccountBalance{
+			Account: b.Account,
+			Debits:  b.Debits,
+			Credits: b.Credits,
+			Balance: b.Balance(),
+		})
+	}
+
+	return res, nil
+}
+
+func (h *PaymentHandler) AddPaymentMethod(ctx context.Context, req *pb.AddPaymentMethodRequest) (*pb.PaymentMethod, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId, authctx.RequireActivated); err != nil {
+		return nil, err
+	}
+
+	method := &domain.PaymentMethod{
+		UserID:   req.UserId,
+		Provider: req.Provider,
+		Token:    req.Token,
+		Brand:    req.Brand,
+		Last4:    req.Last4,
+		ExpMonth: req.ExpMonth,
+		ExpYear:  req.ExpYear,
+	}
+
+	if err := h.service.AddPaymentMethod(ctx, method); err != nil {
+		return nil, h.fail("AddPaymentMethod", err)
+	}
+
+	return paymentMethodToProto(method), nil
+}
+
+func (h *PaymentHandler) ListPaymentMethods(ctx context.Context, req *pb.ListPaymentMethodsRequest) (*pb.ListPaymentMethodsResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	methods, err := h.service.ListPaymentMethods(ctx, req.UserId)
+	if err != nil {
+		return nil, h.fail("ListPaymentMethods", err)
+	}
+
+	res := &pb.ListPaymentMethodsResponse{
+		PaymentMethods: make([]*pb.PaymentMethod, 0, len(methods)),
+	}
+	for i := range methods {
+		res.PaymentMethods = append(res.PaymentMethods, paymentMethodToProto(&methods[i]))
+	}
+
+	return res, nil
+}
+
+func (h *PaymentHandler) DeletePaymentMethod(ctx context.Context, req *pb.DeletePaymentMethodRequest) (*pb.DeletePaymentMethodResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	if err := h.service.DeletePaymentMethod(ctx, req.Id, req.UserId); err != nil {
+		return nil, h.fail("DeletePaymentMethod", err)
+	}
+
+	return &pb.DeletePaymentMethodResponse{Success: true}, nil
+}
+
+// IssueGiftCard is admin-only, see cmd/serve.go; the caller is recorded as
+// the issuer.
+func (h *PaymentHandler) IssueGiftCard(ctx context.Context, req *pb.IssueGiftCardRequest) (*pb.IssueGiftCardResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	card, code, err := h.service.IssueGiftCard(ctx, req.AdminId, req.Amount, req.ValidDays)
+	if err != nil {
+		return nil, h.fail("IssueGiftCard", err)
+	}
+
+	return &pb.IssueGiftCardResponse{GiftCard: giftCardToProto(card), Code: code}, nil
+}
+
+func (h *PaymentHandler) VoidGiftCard(ctx context.Context, req *pb.VoidGiftCardRequest) (*pb.GiftCard, error) {
+	if err := authctx.BindCaller(ctx, &req.AdminId); err != nil {
+		return nil, err
+	}
+
+	card, err := h.service.VoidGiftCard(ctx, req.Id)
+	if err != nil {
+		return nil, h.fail("VoidGiftCard", err)
+	}
+
+	return giftCardToProto(card), nil
+}
+
+func (h *PaymentHandler) ClaimGiftCard(ctx context.Context, req *pb.ClaimGiftCardRequest) (*pb.GiftCard, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId, authctx.RequireActivated); err != nil {
+		return nil, err
+	}
+
+	card, err := h.service.ClaimGiftCard(ctx, req.Code, req.UserId)
+	if err != nil {
+		return nil, h.fail("ClaimGiftCard", err)
+	}
+
+	return giftCardToProto(card), nil
+}
+
+func (h *PaymentHandler) ListGiftCards(ctx context.Context, req *pb.ListGiftCardsRequest) (*pb.ListGiftCardsResponse, error) {
+	if err := authctx.BindCaller(ctx, &req.UserId); err != nil {
+		return nil, err
+	}
+
+	cards, err := h.service.ListGiftCards(ctx, req.UserId)
+	if err != nil {
+		return nil, h.fail("ListGiftCards", err)
+	}
+
+	res := &pb.ListGiftCardsResponse{
+		GiftCards: make([]*pb.GiftCard, 0, len(cards)),
+	}
+	for i := range cards {
+		res.GiftCards = append(res.GiftCards, giftCardToProto(&cards[i]))
+	}
+
+	return res, nil
+}
+
+func (h *PaymentHandler) fail(method string, err error) error {
+	code := mapErrorCode(err)
+
+	h.logger.Error(
+		"payment request failed",
+		zap.String("method", method),
+		zap.String("status_code", code.String()),
+		zap.Error(err),
+	)
+
+	return status.Error(code, err.Error())
+}
+
+// paymentMethodToProto leaves the provider token out on purpose.
+func paymentMethodToProto(m *domain.PaymentMethod) *pb.PaymentMethod {
+	return &pb.PaymentMethod{
+		Id:        m.ID,
+		UserId:    m.UserID,
+		Provider:  m.Provider,
+		Brand:     m.Brand,
+		Last4:     m.Last4,
+		ExpMonth:  m.ExpMonth,
+		ExpYear:   m.ExpYear,
+		CreatedAt: m.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func giftCardToProto(c *domain.GiftCard) *pb.GiftCard {
+	return &pb.GiftCard{
+		Id:            c.ID,
+		Last4:         c.Last4,
+		InitialAmount: c.InitialAmount,
+		Balance:       c.Balance,
+		ExpiresAt:     c.ExpiresAt.Format(time.RFC3339),
+		ClaimedAt:     formatOptionalTime(c.ClaimedAt),
+		VoidedAt:      formatOptionalTime(c.VoidedAt),
+		CreatedAt:     c.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
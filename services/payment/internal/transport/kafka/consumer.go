@@ -2,74 +2,63 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 
-	"github.com/IBM/sarama"
 	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
 	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/kafka"
-	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	"go.uber.org/zap"
 )
 
+// GroupID is the consumer group the service reads with.
+const GroupID = "payment-service-group"
+
+// Topics are the topics GroupID subscribes to.
+var Topics = []string{"payment_events"}
+
 type Consumer struct {
 	service service.PaymentService
+	router  *kafka.Router
 	logger  *zap.Logger
 }
 
 func NewConsumer(service service.PaymentService, logger *zap.Logger) *Consumer {
-	return &Consumer{
+	c := &Consumer{
 		service: service,
 		logger:  logger,
 	}
-}
 
-func (c *Consumer) Start(ctx context.Context, brokers []string) {
-	consumerGroup := kafka.NewConsumerGroup(
-		brokers,
-		"payment-service-group",
-		[]string{"payment_events"},
-		c.processMessage,
-		c.logger,
+	c.router = kafka.NewRouter(logger, kafka.StrictPayloads()).Register(
+		kafka.On("InventoryReserved", c.processPayment),
+		kafka.On("OrderConfirmed", c.processPayment),
+		kafka.On("PaymentTimedOut", c.handlePaymentTimedOut),
+		kafka.On("ReturnApproved", c.refundReturn),
 	)
 
-	consumerGroup.Run(ctx)
+	return c
 }
 
-func (c *Consumer) processMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
-	mylogger.Info(
-		ctx,
+func (c *Consumer) Start(ctx context.Context, config kafka.Config, opts ...kafka.ConsumerOption) {
+	consumerGroup := kafka.NewConsumerGroup(
+		config,
+		GroupID,
+		Topics,
+		c.router.Route,
 		c.logger,
-		"Processing message",
-		zap.String("topic", msg.Topic),
+		opts...,
 	)
 
-	type EventWrapper struct {
-		Event   string          `json:"event"`
-		Payload json.RawMessage `json:"payload"`
-	}
-
-	var wrapper EventWrapper
-	if err := json.Unmarshal(msg.Value, &wrapper); err != nil {
-		mylogger.Error(ctx, c.logger, "Error unmarshalling wrapper", zap.Error(err))
-		return err
-	}
+	consumerGroup.Run(ctx)
+}
 
-	switch wrapper.Event {
-	case "InventoryReserved":
-		var event domain.InventoryReservedEvent
-		if err := json.Unmarshal(wrapper.Payload, &event); err != nil {
-			mylogger.Warn(ctx, c.logger, "Error unmarshalling event structure", zap.Error(err))
-			return err
-		}
+func (c *Consumer) processPayment(ctx context.Context, event *domain.InventoryReservedEvent) error {
+	return c.service.ProcessPayment(ctx, *event)
+}
 
-		if err := c.service.ProcessPayment(ctx, event); err != nil {
-			mylogger.Warn(ctx, c.logger, "Error processing payment", zap.Error(err))
-			return err
-		}
-	default:
-		mylogger.Warn(ctx, c.logger, "Ignored event type", zap.String("event_type", wrapper.Event))
-	}
+func (c *Consumer) handlePaymentTimedOut(ctx context.Context, event *domain.PaymentTimedOutEvent) error {
+	return c.service.HandlePaymentTimedOut(ctx, *event)
+}
 
-	return nil
+func (c *Consumer) refundReturn(ctx context.Context, event *generalDomain.ReturnApprovedEvent) error {
+	return c.service.RefundReturn(ctx, *event)
 }
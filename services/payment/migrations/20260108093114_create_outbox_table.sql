@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS outbox (
+    id BIGSERIAL PRIMARY KEY,
+    aggregate_type TEXT NOT NULL DEFAULT '',
+    aggregate_id TEXT NOT NULL DEFAULT '',
+    event_type TEXT NOT NULL DEFAULT '',
+    payload JSONB NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    published_at TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    topic VARCHAR(255) NOT NULL DEFAULT 'payment_events',
+    event_id UUID NOT NULL DEFAULT gen_random_uuid(),
+    occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    producer TEXT NOT NULL DEFAULT '',
+    correlation_id UUID,
+    causation_id UUID
+);
+
+-- The payment service wrote to outbox before this migration created it, so
+-- on such databases the table exists without the event metadata.
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS topic VARCHAR(255) NOT NULL DEFAULT 'payment_events',
+ADD COLUMN IF NOT EXISTS event_id UUID NOT NULL DEFAULT gen_random_uuid(),
+ADD COLUMN IF NOT EXISTS occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+ADD COLUMN IF NOT EXISTS producer TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS correlation_id UUID,
+ADD COLUMN IF NOT EXISTS causation_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished
+    ON outbox(published_at, created_at)
+    WHERE published_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_event_id ON outbox(event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_correlation_id ON outbox(correlation_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One payment per order, whatever its outcome. The old partial index only
+-- covered a status that is never written.
+DROP INDEX IF EXISTS idx_payments_order_id_unique;
+DROP INDEX IF EXISTS idx_payments_order_id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_order_id_key ON payments(order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_payments_order_id_key;
+--
+-- CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id);
+-- CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_order_id_unique ON payments(order_id) WHERE status = 'SUCCEEDED';
+-- +goose StatementEnd
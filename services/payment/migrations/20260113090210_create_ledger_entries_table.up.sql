@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Double-entry ledger. Every money movement is a set of rows sharing a
+-- transaction_id whose debits and credits sum to the same amount.
+CREATE TABLE IF NOT EXISTS ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id UUID NOT NULL,
+    payment_id BIGINT NOT NULL REFERENCES payments(id),
+    order_id BIGINT NOT NULL,
+    entry_type VARCHAR(16) NOT NULL CHECK (entry_type IN ('payment', 'refund', 'fee')),
+    account VARCHAR(32) NOT NULL,
+    direction VARCHAR(8) NOT NULL CHECK (direction IN ('debit', 'credit')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_transaction_id ON ledger_entries(transaction_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_payment_id ON ledger_entries(payment_id);
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_created_at ON ledger_entries(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS ledger_entries;
+-- +goose StatementEnd
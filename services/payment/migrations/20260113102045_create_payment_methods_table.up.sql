@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Saved cards are provider tokens plus what the user needs to recognise the
+-- card. Card numbers never reach this table.
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    token TEXT NOT NULL,
+    brand VARCHAR(32) NOT NULL DEFAULT '',
+    last4 VARCHAR(4) NOT NULL DEFAULT '',
+    exp_month INT NOT NULL,
+    exp_year INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_methods_user_id ON payment_methods(user_id) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payment_methods_provider_token_key
+    ON payment_methods(provider, token)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS payment_method_id BIGINT REFERENCES payment_methods(id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE payments DROP COLUMN IF EXISTS payment_method_id;
+-- DROP TABLE IF EXISTS payment_methods;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE payments
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Each storefront keeps its own books.
+ALTER TABLE ledger_entries
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+CREATE INDEX IF NOT EXISTS idx_ledger_entries_tenant_created_at
+    ON ledger_entries(tenant_id, created_at);
+
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE outbox DROP COLUMN tenant_id;
+--
+-- DROP INDEX IF EXISTS idx_ledger_entries_tenant_created_at;
+-- ALTER TABLE ledger_entries DROP COLUMN tenant_id;
+-- ALTER TABLE payments DROP COLUMN tenant_id;
+-- +goose StatementEnd
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Gift cards are store credit. The code is a bearer secret, so only its
+-- hash is kept; last4 is what the customer sees.
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id BIGSERIAL PRIMARY KEY,
+    code_hash TEXT NOT NULL,
+    last4 VARCHAR(4) NOT NULL,
+    initial_amount BIGINT NOT NULL CHECK (initial_amount > 0),
+    balance BIGINT NOT NULL CHECK (balance >= 0),
+    user_id BIGINT,
+    issued_by BIGINT NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    claimed_at TIMESTAMP,
+    voided_at TIMESTAMP,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_gift_cards_code_hash_key ON gift_cards(tenant_id, code_hash);
+CREATE INDEX IF NOT EXISTS idx_gift_cards_user_id ON gift_cards(tenant_id, user_id) WHERE user_id IS NOT NULL;
+
+-- The part of an order paid from a card. A card pays for an order once, so
+-- a redelivered payment finds the redemptions it already made.
+CREATE TABLE IF NOT EXISTS gift_card_redemptions (
+    id BIGSERIAL PRIMARY KEY,
+    gift_card_id BIGINT NOT NULL REFERENCES gift_cards(id),
+    order_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (gift_card_id, order_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_gift_card_redemptions_order_id ON gift_card_redemptions(tenant_id, order_id);
+
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS gift_card_amount BIGINT NOT NULL DEFAULT 0;
+
+-- Issuing and voiding a card moves money outside of any payment.
+ALTER TABLE ledger_entries ALTER COLUMN payment_id DROP NOT NULL;
+ALTER TABLE ledger_entries ALTER COLUMN order_id DROP NOT NULL;
+ALTER TABLE ledger_entries ADD COLUMN IF NOT EXISTS gift_card_id BIGINT REFERENCES gift_cards(id);
+ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS ledger_entries_entry_type_check;
+ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_entry_type_check
+    CHECK (entry_type IN ('payment', 'refund', 'fee', 'gift_card_issue', 'gift_card_redeem', 'gift_card_void'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE ledger_entries DROP CONSTRAINT IF EXISTS ledger_entries_entry_type_check;
+-- ALTER TABLE ledger_entries ADD CONSTRAINT ledger_entries_entry_type_check
+--     CHECK (entry_type IN ('payment', 'refund', 'fee'));
+-- ALTER TABLE ledger_entries DROP COLUMN IF EXISTS gift_card_id;
+-- ALTER TABLE payments DROP COLUMN IF EXISTS gift_card_amount;
+-- DROP TABLE IF EXISTS gift_card_redemptions;
+-- DROP TABLE IF EXISTS gift_cards;
+-- +goose StatementEnd
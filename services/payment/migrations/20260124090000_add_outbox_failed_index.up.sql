@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The admin status endpoints list the newest failed events; like pending
+-- ones they are few next to the published history.
+CREATE INDEX IF NOT EXISTS idx_outbox_failed
+    ON outbox(created_at, id)
+    WHERE status = 'failed';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_outbox_failed;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- processed_messages is the consumer inbox: the event_id of every message
+-- the Kafka consumer handled, so redeliveries are skipped. Rows only matter
+-- while Kafka can still redeliver and are purged after that.
+CREATE TABLE IF NOT EXISTS processed_messages (
+    event_id TEXT PRIMARY KEY,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_processed_messages_processed_at
+    ON processed_messages(processed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS processed_messages;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per component, touched by every replica while the component
+-- works. The row is shared, so the component counts as alive while any
+-- replica keeps it fresh.
+CREATE TABLE IF NOT EXISTS heartbeats (
+    component TEXT PRIMARY KEY,
+    beat_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS heartbeats;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Money sent back for approved returns. A return is refunded once, so a
+-- redelivered ReturnApproved finds the refund it already made.
+CREATE TABLE IF NOT EXISTS refunds (
+    id BIGSERIAL PRIMARY KEY,
+    payment_id BIGINT NOT NULL REFERENCES payments(id),
+    order_id BIGINT NOT NULL,
+    return_id BIGINT NOT NULL,
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_return_id_key ON refunds(tenant_id, return_id);
+CREATE INDEX IF NOT EXISTS idx_refunds_payment_id ON refunds(payment_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS refunds;
+-- +goose StatementEnd
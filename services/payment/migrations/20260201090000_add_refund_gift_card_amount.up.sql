@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A refund goes back the way the payment came in: gift_card_amount of it
+-- onto the gift cards that paid, the rest to the charged card. refunded
+-- tracks how much of a redemption was given back that way.
+ALTER TABLE refunds ADD COLUMN IF NOT EXISTS gift_card_amount BIGINT NOT NULL DEFAULT 0
+    CHECK (gift_card_amount >= 0 AND gift_card_amount <= amount);
+ALTER TABLE gift_card_redemptions ADD COLUMN IF NOT EXISTS refunded BIGINT NOT NULL DEFAULT 0
+    CHECK (refunded >= 0 AND refunded <= amount);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE gift_card_redemptions DROP COLUMN IF EXISTS refunded;
+ALTER TABLE refunds DROP COLUMN IF EXISTS gift_card_amount;
+-- +goose StatementEnd
@@ -0,0 +1,185 @@
+package tests
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+)
+
+// claimGiftCard issues a card worth amount and claims it for user 999, the
+// user pay charges.
+func (s *IntegrationTestSuite) claimGiftCard(amount int64) *domain.GiftCard {
+	_, code, err := s.PaymentService.IssueGiftCard(s.Ctx, 1, amount, 30)
+	s.Require().NoError(err)
+
+	card, err := s.PaymentService.ClaimGiftCard(s.Ctx, code, 999)
+	s.Require().NoError(err)
+
+	return card
+}
+
+func (s *IntegrationTestSuite) giftCardBalance(id int64) int64 {
+	cards, err := s.PaymentService.ListGiftCards(s.Ctx, 999)
+	s.Require().NoError(err)
+
+	for _, c := range cards {
+		if c.ID == id {
+			return c.Balance
+		}
+	}
+	s.FailNow("gift card not listed", "id %d", id)
+	return 0
+}
+
+func (s *IntegrationTestSuite) TestIssueGiftCard_BooksLiability() {
+	card, code, err := s.PaymentService.IssueGiftCard(s.Ctx, 1, 2500, 30)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(2500), card.Balance)
+	s.Require().Equal(domain.GiftCardLast4(code), card.Last4)
+
+	s.Require().Zero(s.countRows("SELECT COUNT(*) FROM gift_cards WHERE code_hash = $1", code), "Only the hash is stored")
+
+	sheet, err := s.PaymentService.GetBalanceSheet(s.Ctx, domain.DateRange{})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(-2500), balanceOf(sheet, domain.AccountGiftCardLiability))
+	s.requireBalancedTransactions()
+}
+
+func (s *IntegrationTestSuite) TestIssueGiftCard_RejectsBadInput() {
+	_, _, err := s.PaymentService.IssueGiftCard(s.Ctx, 1, 0, 30)
+	s.Require().ErrorIs(err, service.ErrInvalidGiftCard)
+
+	_, _, err = s.PaymentService.IssueGiftCard(s.Ctx, 1, 100, 0)
+	s.Require().ErrorIs(err, service.ErrInvalidGiftCard)
+}
+
+func (s *IntegrationTestSuite) TestClaimGiftCard() {
+	_, code, err := s.PaymentService.IssueGiftCard(s.Ctx, 1, 1000, 30)
+	s.Require().NoError(err)
+
+	card, err := s.PaymentService.ClaimGiftCard(s.Ctx, strings.ToLower(code), 999)
+	s.Require().NoError(err)
+	s.Require().NotNil(card.ClaimedAt)
+
+	_, err = s.PaymentService.ClaimGiftCard(s.Ctx, code, 999)
+	s.Require().NoError(err, "Claiming again is a no-op")
+
+	_, err = s.PaymentService.ClaimGiftCard(s.Ctx, code, 1000)
+	s.Require().ErrorIs(err, repository.ErrGiftCardNotFound)
+
+	_, err = s.PaymentService.ClaimGiftCard(s.Ctx, "AAAA-AAAA-AAAA-AAAA", 999)
+	s.Require().ErrorIs(err, repository.ErrGiftCardNotFound)
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_GiftCardCoversOrder() {
+	card := s.claimGiftCard(5000)
+
+	// Even order ids are declined by the simulated provider, so approval
+	// shows it was never asked.
+	s.pay(2, 3000)
+
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM payments WHERE order_id = $1 AND status = 'PAID' AND gift_card_amount = 3000", 2))
+	s.Require().Equal(int64(2000), s.giftCardBalance(card.ID))
+	s.Require().Zero(s.countRows("SELECT COUNT(*) FROM ledger_entries WHERE order_id = $1 AND entry_type = 'fee'", 2))
+	s.requireBalancedTransactions()
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_SplitsBetweenGiftCardAndProvider() {
+	card := s.claimGiftCard(1000)
+
+	s.pay(1, 5000)
+
+	s.Require().Zero(s.giftCardBalance(card.ID))
+	s.Require().Equal(1, s.countRows(
+		"SELECT COUNT(*) FROM ledger_entries WHERE order_id = $1 AND account = $2 AND direction = 'debit' AND amount = 4000",
+		1, domain.AccountCash,
+	))
+	s.Require().Equal(1, s.countRows(
+		"SELECT COUNT(*) FROM ledger_entries WHERE order_id = $1 AND account = $2 AND amount = 1000",
+		1, domain.AccountGiftCardLiability,
+	))
+	// 2.5% of the 4000 charged, not of the whole 5000.
+	s.Require().Equal(1, s.countRows(
+		"SELECT COUNT(*) FROM ledger_entries WHERE order_id = $1 AND account = $2 AND amount = 100",
+		1, domain.AccountProcessingFees,
+	))
+	s.requireBalancedTransactions()
+
+	sheet, err := s.PaymentService.GetBalanceSheet(s.Ctx, domain.DateRange{})
+	s.Require().NoError(err)
+	s.Require().Zero(balanceOf(sheet, domain.AccountGiftCardLiability), "The card is spent")
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_DeclineGivesGiftCardBack() {
+	card := s.claimGiftCard(1000)
+
+	s.pay(2, 5000)
+
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM payments WHERE order_id = $1 AND status = 'FAIL' AND gift_card_amount = 0", 2))
+	s.Require().Equal(int64(1000), s.giftCardBalance(card.ID))
+	s.Require().Zero(s.countRows("SELECT COUNT(*) FROM gift_card_redemptions"))
+	s.Require().Zero(s.countRows("SELECT COUNT(*) FROM ledger_entries WHERE order_id = $1", 2))
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_RedeliveryRedeemsOnce() {
+	card := s.claimGiftCard(1000)
+
+	s.pay(3, 600)
+	s.pay(3, 600)
+
+	s.Require().Equal(int64(400), s.giftCardBalance(card.ID))
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM gift_card_redemptions WHERE order_id = $1", 3))
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_ConcurrentRedeliveryHoldsOneCard() {
+	first := s.claimGiftCard(600)
+	second := s.claimGiftCard(1000)
+
+	event := domain.InventoryReservedEvent{OrderID: 3, UserID: 999, Amount: 600, ReservedAt: time.Now()}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = s.PaymentService.ProcessPayment(s.Ctx, event)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		s.Require().NoError(err)
+	}
+
+	s.Require().Zero(s.giftCardBalance(first.ID))
+	s.Require().Equal(int64(1000), s.giftCardBalance(second.ID), "A losing delivery never holds the next card")
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM gift_card_redemptions WHERE order_id = $1", 3))
+	s.requireBalancedTransactions()
+}
+
+func (s *IntegrationTestSuite) TestVoidGiftCard_ReversesUnspentBalance() {
+	card := s.claimGiftCard(1000)
+	s.pay(1, 300)
+
+	voided, err := s.PaymentService.VoidGiftCard(s.Ctx, card.ID)
+	s.Require().NoError(err)
+	s.Require().NotNil(voided.VoidedAt)
+
+	sheet, err := s.PaymentService.GetBalanceSheet(s.Ctx, domain.DateRange{})
+	s.Require().NoError(err)
+	s.Require().Zero(balanceOf(sheet, domain.AccountGiftCardLiability))
+	s.Require().Equal(int64(300), balanceOf(sheet, domain.AccountGiftCardsIssued))
+	s.requireBalancedTransactions()
+
+	// A voided card pays for nothing, so the provider is charged in full.
+	s.pay(5, 200)
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM payments WHERE order_id = $1 AND gift_card_amount = 0", 5))
+
+	_, err = s.PaymentService.VoidGiftCard(s.Ctx, card.ID)
+	s.Require().ErrorIs(err, repository.ErrGiftCardVoided)
+}
@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"go.uber.org/zap"
+)
+
+func (s *IntegrationTestSuite) TestHeartbeat_AliveUntilStale() {
+	repo := repository.NewHeartbeatRepository(s.DbPool, zap.NewNop())
+
+	alive, err := repo.Alive(s.Ctx, "consumer", time.Minute)
+	s.Require().NoError(err)
+	s.Require().False(alive, "A component that never beat is not alive")
+
+	s.Require().NoError(repo.Beat(s.Ctx, "consumer"))
+	s.Require().NoError(repo.Beat(s.Ctx, "consumer"), "Beating again updates the row")
+
+	alive, err = repo.Alive(s.Ctx, "consumer", time.Minute)
+	s.Require().NoError(err)
+	s.Require().True(alive)
+
+	_, err = s.DbPool.Exec(s.Ctx, `UPDATE heartbeats SET beat_at = NOW() - INTERVAL '2 minutes'`)
+	s.Require().NoError(err)
+
+	alive, err = repo.Alive(s.Ctx, "consumer", time.Minute)
+	s.Require().NoError(err)
+	s.Require().False(alive, "A beat older than the window is stale")
+}
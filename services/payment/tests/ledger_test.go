@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+)
+
+func (s *IntegrationTestSuite) pay(orderID, amount int64) {
+	err := s.PaymentService.ProcessPayment(s.Ctx, domain.InventoryReservedEvent{
+		OrderID:    orderID,
+		UserID:     999,
+		Amount:     amount,
+		ReservedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+}
+
+// requireBalancedTransactions asserts the double-entry invariant on
+// everything written to the ledger.
+func (s *IntegrationTestSuite) requireBalancedTransactions() {
+	s.Require().Equal(0, s.countRows(`
+		SELECT COUNT(*) FROM (
+			SELECT transaction_id
+			FROM ledger_entries
+			GROUP BY transaction_id
+			HAVING SUM(CASE WHEN direction = 'debit' THEN amount ELSE -amount END) <> 0
+		) unbalanced
+	`), "Every ledger transaction debits as much as it credits")
+}
+
+func balanceOf(sheet []domain.AccountBalance, account string) int64 {
+	for _, b := range sheet {
+		if b.Account == account {
+			return b.Balance()
+		}
+	}
+	return 0
+}
+
+func (s *IntegrationTestSuite) TestLedger_PaymentBooksPaymentAndFee() {
+	s.pay(1, 5350)
+
+	s.Require().Equal(4, s.countRows("SELECT COUNT(*) FROM ledger_entries WHERE order_id = $1", 1))
+	s.Require().Equal(1, s.countRows("SELECT COUNT(DISTINCT transaction_id) FROM ledger_entries WHERE order_id = $1", 1))
+	s.Require().Equal(1, s.countRows(
+		"SELECT COUNT(*) FROM ledger_entries WHERE entry_type = 'fee' AND account = $1 AND amount = $2",
+		domain.AccountProcessingFees, 133,
+	))
+	s.requireBalancedTransactions()
+}
+
+func (s *IntegrationTestSuite) TestLedger_FailedPaymentBooksNothing() {
+	s.pay(2, 5350)
+
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM payments WHERE order_id = $1", 2))
+	s.Require().Equal(0, s.countRows("SELECT COUNT(*) FROM ledger_entries"))
+}
+
+func (s *IntegrationTestSuite) TestLedger_RedeliveryBooksOnce() {
+	s.pay(5, 1000)
+	s.pay(5, 1000)
+
+	s.Require().Equal(4, s.countRows("SELECT COUNT(*) FROM ledger_entries WHERE order_id = $1", 5))
+}
+
+func (s *IntegrationTestSuite) TestGetBalanceSheet_Balances() {
+	s.pay(1, 5350)
+	s.pay(3, 1000)
+	s.pay(4, 700)
+	s.pay(7, 40)
+
+	s.requireBalancedTransactions()
+
+	sheet, err := s.PaymentService.GetBalanceSheet(s.Ctx, domain.DateRange{})
+	s.Require().NoError(err)
+
+	var debits, credits, total int64
+	for _, b := range sheet {
+		debits += b.Debits
+		credits += b.Credits
+		total += b.Balance()
+	}
+	s.Require().Equal(debits, credits)
+	s.Require().Zero(total)
+
+	// 5350 + 1000 + 40 captured, fees are 133 + 25 + 1.
+	s.Require().Equal(int64(-6390), balanceOf(sheet, domain.AccountRevenue))
+	s.Require().Equal(int64(159), balanceOf(sheet, domain.AccountProcessingFees))
+	s.Require().Equal(int64(6390-159), balanceOf(sheet, domain.AccountCash))
+}
+
+func (s *IntegrationTestSuite) TestGetBalanceSheet_RangeFilters() {
+	s.pay(1, 5350)
+
+	tomorrow := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	sheet, err := s.PaymentService.GetBalanceSheet(s.Ctx, domain.DateRange{From: tomorrow})
+	s.Require().NoError(err)
+	s.Require().Empty(sheet)
+}
+
+func (s *IntegrationTestSuite) TestValidateEntries_RejectsUnbalanced() {
+	payment := &domain.Payment{ID: 1, OrderID: 1, Amount: 500}
+
+	s.Require().NoError(domain.ValidateEntries(domain.PaymentEntries("tx-1", payment, 10)))
+	s.Require().NoError(domain.ValidateEntries(domain.RefundEntries("tx-2", payment, 200, nil)))
+
+	entries := domain.PaymentEntries("tx-3", payment, 0)
+	entries[1].Amount = 499
+	s.Require().ErrorIs(domain.ValidateEntries(entries), domain.ErrUnbalancedTransaction)
+
+	credits := []domain.GiftCardRedemption{{GiftCardID: 7, OrderID: 1, Amount: 100}}
+	s.Require().NoError(domain.ValidateEntries(domain.RefundEntries("tx-4", payment, 200, credits)))
+
+	entries = domain.RefundEntries("tx-5", payment, 0, nil)
+	s.Require().ErrorIs(domain.ValidateEntries(entries), domain.ErrUnbalancedTransaction)
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+)
+
+func (s *IntegrationTestSuite) addCard(userID int64, token string) *domain.PaymentMethod {
+	method := &domain.PaymentMethod{
+		UserID:   userID,
+		Provider: "stripe",
+		Token:    token,
+		Brand:    "visa",
+		Last4:    "4242",
+		ExpMonth: 12,
+		ExpYear:  int32(time.Now().Year() + 2),
+	}
+
+	s.Require().NoError(s.PaymentService.AddPaymentMethod(s.Ctx, method))
+	s.Require().NotZero(method.ID)
+
+	return method
+}
+
+func (s *IntegrationTestSuite) TestAddPaymentMethod_RejectsCardNumbers() {
+	err := s.PaymentService.AddPaymentMethod(s.Ctx, &domain.PaymentMethod{
+		UserID:   999,
+		Provider: "stripe",
+		Token:    "4242 4242 4242 4242",
+		ExpMonth: 12,
+		ExpYear:  int32(time.Now().Year() + 2),
+	})
+	s.Require().ErrorIs(err, service.ErrInvalidPaymentMethod)
+
+	s.Require().Equal(0, s.countRows("SELECT COUNT(*) FROM payment_methods"))
+}
+
+func (s *IntegrationTestSuite) TestAddPaymentMethod_RejectsExpired() {
+	err := s.PaymentService.AddPaymentMethod(s.Ctx, &domain.PaymentMethod{
+		UserID:   999,
+		Provider: "stripe",
+		Token:    "pm_expired",
+		ExpMonth: 1,
+		ExpYear:  int32(time.Now().Year() - 1),
+	})
+	s.Require().ErrorIs(err, service.ErrInvalidPaymentMethod)
+}
+
+func (s *IntegrationTestSuite) TestAddPaymentMethod_Duplicate() {
+	s.addCard(999, "pm_dup")
+
+	err := s.PaymentService.AddPaymentMethod(s.Ctx, &domain.PaymentMethod{
+		UserID:   999,
+		Provider: "stripe",
+		Token:    "pm_dup",
+		ExpMonth: 12,
+		ExpYear:  int32(time.Now().Year() + 2),
+	})
+	s.Require().ErrorIs(err, repository.ErrPaymentMethodExists)
+}
+
+func (s *IntegrationTestSuite) TestListAndDeletePaymentMethods() {
+	first := s.addCard(999, "pm_first")
+	second := s.addCard(999, "pm_second")
+	s.addCard(555, "pm_other_user")
+
+	methods, err := s.PaymentService.ListPaymentMethods(s.Ctx, 999)
+	s.Require().NoError(err)
+	s.Require().Len(methods, 2)
+
+	err = s.PaymentService.DeletePaymentMethod(s.Ctx, first.ID, 555)
+	s.Require().ErrorIs(err, repository.ErrPaymentMethodNotFound, "Other users cannot delete the method")
+
+	s.Require().NoError(s.PaymentService.DeletePaymentMethod(s.Ctx, first.ID, 999))
+
+	methods, err = s.PaymentService.ListPaymentMethods(s.Ctx, 999)
+	s.Require().NoError(err)
+	s.Require().Len(methods, 1)
+	s.Require().Equal(second.ID, methods[0].ID)
+
+	err = s.PaymentService.DeletePaymentMethod(s.Ctx, first.ID, 999)
+	s.Require().ErrorIs(err, repository.ErrPaymentMethodNotFound)
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_ChargesChosenMethod() {
+	method := s.addCard(999, "pm_charge")
+
+	err := s.PaymentService.ProcessPayment(s.Ctx, domain.InventoryReservedEvent{
+		OrderID:         11,
+		UserID:          999,
+		Amount:          1000,
+		ReservedAt:      time.Now(),
+		PaymentMethodID: &method.ID,
+	})
+	s.Require().NoError(err)
+
+	s.Require().Equal(1, s.countRows(
+		"SELECT COUNT(*) FROM payments WHERE order_id = $1 AND status = 'PAID' AND payment_method_id = $2",
+		11, method.ID,
+	))
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_DeclinesForeignMethod() {
+	method := s.addCard(555, "pm_foreign")
+
+	err := s.PaymentService.ProcessPayment(s.Ctx, domain.InventoryReservedEvent{
+		OrderID:         13,
+		UserID:          999,
+		Amount:          1000,
+		ReservedAt:      time.Now(),
+		PaymentMethodID: &method.ID,
+	})
+	s.Require().NoError(err, "A bad method fails the payment instead of redelivering it")
+
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM payments WHERE order_id = $1 AND status = 'FAIL'", 13))
+	s.Require().Equal(0, s.countRows("SELECT COUNT(*) FROM ledger_entries WHERE order_id = $1", 13))
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+)
+
+func (s *IntegrationTestSuite) countRows(query string, args ...any) int {
+	var n int
+	err := s.DbPool.QueryRow(s.Ctx, query, args...).Scan(&n)
+	s.Require().NoError(err)
+
+	return n
+}
+
+// countingProvider approves every charge and counts them.
+type countingProvider struct {
+	charges atomic.Int64
+}
+
+func (p *countingProvider) Charge(context.Context, domain.Charge) (bool, error) {
+	p.charges.Add(1)
+	// Gives concurrent deliveries time to race for the order.
+	time.Sleep(50 * time.Millisecond)
+	return true, nil
+}
+
+func (p *countingProvider) Refund(context.Context, domain.Refund) error {
+	return nil
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_Success() {
+	err := s.PaymentService.ProcessPayment(s.Ctx, domain.InventoryReservedEvent{
+		OrderID:    1,
+		UserID:     999,
+		Amount:     5350,
+		ReservedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM payments WHERE order_id = $1", 1))
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM outbox"))
+}
+
+func (s *IntegrationTestSuite) TestProcessPayment_ConcurrentRedelivery() {
+	event := domain.InventoryReservedEvent{
+		OrderID:    3,
+		UserID:     999,
+		Amount:     5350,
+		ReservedAt: time.Now(),
+	}
+
+	const deliveries = 10
+
+	provider := &countingProvider{}
+	paymentService := s.newPaymentService(service.WithProvider(provider))
+
+	var wg sync.WaitGroup
+	errs := make([]error, deliveries)
+	for i := range deliveries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = paymentService.ProcessPayment(s.Ctx, event)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		s.Require().NoError(err, "Duplicates are acknowledged, not failed")
+	}
+
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM payments WHERE order_id = $1", event.OrderID))
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM outbox"), "The result is emitted exactly once")
+	s.Require().EqualValues(1, provider.charges.Load(), "The customer is charged once")
+}
+
+func (s *IntegrationTestSuite) TestHandlePaymentTimedOut_BlocksLateCharge() {
+	err := s.PaymentService.HandlePaymentTimedOut(s.Ctx, domain.PaymentTimedOutEvent{
+		OrderID:    5,
+		UserID:     999,
+		Amount:     5350,
+		TimedOutAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	err = s.PaymentService.ProcessPayment(s.Ctx, domain.InventoryReservedEvent{
+		OrderID:    5,
+		UserID:     999,
+		Amount:     5350,
+		ReservedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM payments WHERE order_id = $1 AND status = 'TIMED_OUT'", 5))
+	s.Require().Equal(0, s.countRows("SELECT COUNT(*) FROM outbox"))
+}
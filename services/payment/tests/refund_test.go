@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/domain"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+)
+
+func (s *IntegrationTestSuite) refundReturn(returnID, orderID, amount int64) {
+	err := s.PaymentService.RefundReturn(s.Ctx, generalDomain.ReturnApprovedEvent{
+		ReturnID:   returnID,
+		OrderID:    orderID,
+		UserID:     999,
+		Amount:     amount,
+		ApprovedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+}
+
+func (s *IntegrationTestSuite) TestRefundReturn_BooksRefundOnce() {
+	s.pay(1, 5000)
+
+	s.refundReturn(10, 1, 2000)
+	// A redelivered approval finds the refund it already made.
+	s.refundReturn(10, 1, 2000)
+
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM refunds WHERE return_id = 10 AND amount = 2000"))
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM outbox WHERE payload->>'event' = 'RefundIssued'"))
+
+	sheet, err := s.PaymentService.GetBalanceSheet(s.Ctx, domain.DateRange{})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(2000), balanceOf(sheet, domain.AccountRefunds))
+	s.Require().Equal(int64(3000), balanceOf(sheet, domain.AccountCash))
+	s.requireBalancedTransactions()
+}
+
+func (s *IntegrationTestSuite) TestRefundReturn_NeverRefundsMoreThanWasPaid() {
+	s.pay(1, 5000)
+
+	s.refundReturn(10, 1, 4000)
+	s.refundReturn(11, 1, 4000)
+	s.refundReturn(12, 1, 4000)
+
+	s.Require().Equal(1000, s.countRows("SELECT COALESCE(SUM(amount), 0)::INT FROM refunds WHERE return_id = 11"))
+	s.Require().Equal(0, s.countRows("SELECT COUNT(*) FROM refunds WHERE return_id = 12"))
+	s.Require().Equal(5000, s.countRows("SELECT SUM(amount)::INT FROM refunds"))
+}
+
+func (s *IntegrationTestSuite) TestRefundReturn_SkipsOrdersThatWereNotPaid() {
+	// The simulated provider declines orders with even ids.
+	s.pay(2, 5000)
+
+	s.refundReturn(10, 2, 2000)
+	s.refundReturn(11, 404, 2000)
+
+	s.Require().Equal(0, s.countRows("SELECT COUNT(*) FROM refunds"))
+}
+
+func (s *IntegrationTestSuite) TestRefundReturn_SplitsBetweenCardAndGiftCard() {
+	card := s.claimGiftCard(1000)
+
+	// 1000 comes off the gift card and the provider charges 4000.
+	s.pay(1, 5000)
+	s.Require().Zero(s.giftCardBalance(card.ID))
+
+	s.refundReturn(10, 1, 3000)
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM refunds WHERE return_id = 10 AND amount = 3000 AND gift_card_amount = 0"))
+
+	// 2000 of the payment is left, but only 1000 of what the provider
+	// charged, so the other 1000 goes back onto the gift card.
+	s.refundReturn(11, 1, 2500)
+	s.Require().Equal(1, s.countRows("SELECT COUNT(*) FROM refunds WHERE return_id = 11 AND amount = 2000 AND gift_card_amount = 1000"))
+	s.Require().Equal(int64(1000), s.giftCardBalance(card.ID))
+	s.Require().Equal(1, s.countRows(
+		"SELECT COUNT(*) FROM ledger_entries WHERE gift_card_id = $1 AND entry_type = 'refund' AND account = $2 AND direction = 'credit' AND amount = 1000",
+		card.ID, domain.AccountGiftCardLiability,
+	))
+
+	s.refundReturn(12, 1, 500)
+	s.Require().Zero(s.countRows("SELECT COUNT(*) FROM refunds WHERE return_id = 12"))
+
+	sheet, err := s.PaymentService.GetBalanceSheet(s.Ctx, domain.DateRange{})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(5000), balanceOf(sheet, domain.AccountRefunds))
+	s.Require().Equal(int64(-1000), balanceOf(sheet, domain.AccountGiftCardLiability), "The card's credit is owed again")
+	s.requireBalancedTransactions()
+}
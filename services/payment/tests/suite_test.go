@@ -0,0 +1,62 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/sakashimaa/go-pet-project/payment/internal/repository"
+	"github.com/sakashimaa/go-pet-project/payment/internal/service"
+	outboxRepository "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/testsuite"
+	"github.com/stretchr/testify/suite"
+	"go.uber.org/zap"
+)
+
+// testFeeBasisPoints is 2.5%, so every captured payment also books a fee.
+const testFeeBasisPoints = 250
+
+type IntegrationTestSuite struct {
+	testsuite.BaseSuite
+
+	PaymentService service.PaymentService
+}
+
+func (s *IntegrationTestSuite) SetupSuite() {
+	s.BaseSuite.SetupInfrastructure("../migrations")
+}
+
+func (s *IntegrationTestSuite) TearDownSuite() {
+	s.BaseSuite.TearDownInfrastructure()
+}
+
+func (s *IntegrationTestSuite) SetupTest() {
+	s.BaseSuite.TruncateTable("ledger_entries")
+	s.BaseSuite.TruncateTable("refunds")
+	s.BaseSuite.TruncateTable("payments")
+	s.BaseSuite.TruncateTable("payment_methods")
+	s.BaseSuite.TruncateTable("gift_card_redemptions")
+	s.BaseSuite.TruncateTable("gift_cards")
+	s.BaseSuite.TruncateTable("outbox")
+	s.BaseSuite.TruncateTable("heartbeats")
+
+	s.PaymentService = s.newPaymentService()
+}
+
+// newPaymentService builds the service under test; opts come after the
+// suite's own.
+func (s *IntegrationTestSuite) newPaymentService(opts ...service.Option) service.PaymentService {
+	logger := zap.NewNop()
+	paymentRepo := repository.NewPaymentRepository(s.DbPool, logger)
+	paymentMethodRepo := repository.NewPaymentMethodRepository(s.DbPool, logger)
+	ledgerRepo := repository.NewLedgerRepository(s.DbPool, logger)
+	giftCardRepo := repository.NewGiftCardRepository(s.DbPool, logger)
+	refundRepo := repository.NewRefundRepository(s.DbPool, logger)
+	outboxRepo := outboxRepository.NewOutboxRepository(s.DbPool, logger, "payment-service")
+
+	opts = append([]service.Option{service.WithFeeBasisPoints(testFeeBasisPoints)}, opts...)
+
+	return service.NewPaymentService(s.DbPool, paymentRepo, paymentMethodRepo, ledgerRepo, giftCardRepo, refundRepo, outboxRepo, logger, opts...)
+}
+
+func TestIntegrationSuite(t *testing.T) {
+	suite.Run(t, new(IntegrationTestSuite))
+}
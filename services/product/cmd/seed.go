@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	outbox "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/seed"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+	"github.com/sakashimaa/go-pet-project/product/internal/service"
+	"go.uber.org/zap"
+)
+
+// seedCategory describes what a category's products look like. Prices are
+// in minor units and log-normal around Median.
+type seedCategory struct {
+	Name   string
+	Nouns  []string
+	Median float64
+	Sigma  float64
+}
+
+var (
+	seedCategories = seed.NewWeighted([]seedCategory{
+		{Name: "Electronics", Nouns: []string{"Headphones", "Speaker", "Monitor", "Keyboard", "Router"}, Median: 40000, Sigma: 0.9},
+		{Name: "Audio", Nouns: []string{"Turntable", "Amplifier", "Receiver", "Cassette Deck"}, Median: 30000, Sigma: 0.8},
+		{Name: "Books", Nouns: []string{"Novel", "Cookbook", "Atlas", "Guide"}, Median: 1500, Sigma: 0.5},
+		{Name: "Clothing", Nouns: []string{"Jacket", "Sweater", "Sneakers", "Scarf", "Jeans"}, Median: 4000, Sigma: 0.6},
+		{Name: "Home", Nouns: []string{"Lamp", "Kettle", "Blanket", "Chair", "Vase"}, Median: 5000, Sigma: 0.7},
+		{Name: "Toys", Nouns: []string{"Puzzle", "Robot", "Board Game", "Kite"}, Median: 2500, Sigma: 0.6},
+		{Name: "Sports", Nouns: []string{"Yoga Mat", "Dumbbell", "Bike Helmet", "Tent"}, Median: 6000, Sigma: 0.8},
+	}, []float64{25, 5, 20, 20, 15, 8, 7})
+
+	seedAdjectives = []string{"Classic", "Compact", "Deluxe", "Everyday", "Pro", "Urban", "Vintage", "Wireless"}
+	seedColors     = []string{"black", "white", "grey", "red", "blue", "green"}
+)
+
+const (
+	seedOutOfStockShare = 0.1
+	seedMeanStock       = 40
+)
+
+// seedProducts creates products through the product service, stocked in the
+// default warehouse.
+func seedProducts(ctx context.Context, opts seed.Options) error {
+	pool, err := db.NewPostgresDB(os.Getenv("DB_URL"))
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	logger := zap.NewNop()
+	productService := service.NewProductService(
+		repository.NewProductRepository(pool, logger),
+		repository.NewWarehouseRepository(pool, logger),
+		repository.NewCampaignRepository(pool, logger),
+		outbox.NewOutboxRepository(pool, logger, "product-service"),
+		pool,
+		logger,
+	)
+
+	return seed.Run(ctx, opts, "products", func(ctx context.Context, i int) error {
+		_, err := productService.Create(ctx, newSeedProduct(seed.ForRecord(opts.Seed, i), opts.Seed, i))
+		return err
+	})
+}
+
+func newSeedProduct(r *seed.Rand, seedValue uint64, i int) *domain.Product {
+	category := seedCategories.Pick(r)
+	noun := seed.Pick(r, category.Nouns)
+
+	var stock int64
+	if !r.Chance(seedOutOfStockShare) {
+		stock = int64(r.Geometric(seedMeanStock))
+	}
+
+	color := seed.Pick(r, seedColors)
+
+	return &domain.Product{
+		Name:          fmt.Sprintf("%s %s %d", seed.Pick(r, seedAdjectives), noun, 100+r.IntN(900)),
+		Description:   fmt.Sprintf("A %s %s, generated by seed %d.", color, noun, seedValue),
+		Price:         max(int64(r.LogNormal(category.Median, category.Sigma)), 100),
+		StockQuantity: stock,
+		Category:      category.Name,
+		SKU:           fmt.Sprintf("SEED-%d-%d", seedValue, i),
+		Attributes:    map[string]string{"color": color},
+	}
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/sakashimaa/go-pet-project/pkg/app"
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/pkg/config"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/debug"
+	"github.com/sakashimaa/go-pet-project/pkg/jobs"
+	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
+	"github.com/sakashimaa/go-pet-project/pkg/lock"
+	outbox "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	outboxWorker "github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/pkg/redis"
+	"github.com/sakashimaa/go-pet-project/pkg/retention"
+	"github.com/sakashimaa/go-pet-project/pkg/servicestatus"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/pkg/utils"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+	"github.com/sakashimaa/go-pet-project/product/internal/service"
+	"github.com/sakashimaa/go-pet-project/product/internal/transport/grpc"
+	productKafka "github.com/sakashimaa/go-pet-project/product/internal/transport/kafka"
+	"github.com/sakashimaa/go-pet-project/product/internal/worker"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	statusPb "github.com/sakashimaa/go-pet-project/proto/status"
+	googleGrpc "google.golang.org/grpc"
+)
+
+func serve(ctx context.Context) error {
+	tp, err := utils.InitTracer(ctx, "product-service")
+	if err != nil {
+		log.Fatalf("Error init tracer: %v", err)
+	}
+
+	pool, err := db.NewPostgresDB(os.Getenv("DB_URL"))
+	if err != nil {
+		log.Fatalf("Error creating new postgres DB: %v", err)
+	}
+
+	redisConfig, err := redis.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading redis config: %v", err)
+	}
+
+	rdb, err := redis.New(ctx, redisConfig)
+	if err != nil {
+		log.Fatalf("Error connecting to redis: %v", err)
+	}
+
+	cfg, err := config.LoadLoggerConfig()
+	if err != nil {
+		log.Fatalf("failed to load logger config: %v", err)
+	}
+
+	logger, logLevel, err := config.NewLeveledLogger(cfg)
+	if err != nil {
+		log.Fatalf("Error creating logger: %v", err)
+	}
+	defer func() {
+		if err := logger.Sync(); err != nil {
+			log.Fatalf("error syncing logger: %v", err)
+		}
+	}()
+
+	runner := app.NewRunner(logger)
+	runner.Add(app.Component{Name: "telemetry", Stop: tp.Shutdown})
+
+	reloadConfig, err := config.LoadReloadConfig()
+	if err != nil {
+		log.Fatalf("Error loading config reload settings: %v", err)
+	}
+
+	configWatcher := config.NewWatcher(reloadConfig, logger)
+	if err := configWatcher.Load(ctx); err != nil {
+		log.Fatalf("Error loading tunables: %v", err)
+	}
+	configWatcher.Subscribe(config.SetLogLevel(logLevel))
+	runner.Add(app.Component{Name: "config watcher", Start: app.Loop(configWatcher.Start)})
+
+	runner.Add(app.Component{Name: "postgres", Stop: app.Func(pool.Close)})
+
+	locker := lock.NewPostgres(pool, logger)
+	runner.Add(app.Component{Name: "redis", Stop: app.Closer(rdb.Close)})
+
+	logger.Info("product service started!")
+
+	allocationStrategy, err := domain.ParseAllocationStrategy(utils.ParseWithFallback("STOCK_ALLOCATION_STRATEGY", string(domain.AllocateMostStocked)))
+	if err != nil {
+		log.Fatalf("error parsing stock allocation strategy: %v", err)
+	}
+
+	productRepository := repository.NewProductRepository(pool, logger)
+	warehouseRepository := repository.NewWarehouseRepository(pool, logger)
+	campaignRepository := repository.NewCampaignRepository(pool, logger)
+	outboxRepository := outbox.NewOutboxRepository(pool, logger, "product-service")
+	productService := service.NewProductService(
+		productRepository,
+		warehouseRepository,
+		campaignRepository,
+		outboxRepository,
+		pool,
+		logger,
+		service.WithAllocationStrategy(allocationStrategy),
+	)
+	cachedProductService := service.NewCachedProductService(productService, rdb)
+
+	reg := prometheus.NewRegistry()
+
+	reg.MustRegister(collectors.NewGoCollector())
+	reg.MustRegister(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	if err := config.RegisterLogMetrics(reg); err != nil {
+		log.Fatalf("Error registering log metrics: %v", err)
+	}
+
+	if err := jobs.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering job metrics: %v", err)
+	}
+	if err := lock.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering lock metrics: %v", err)
+	}
+	if err := retention.RegisterMetrics(reg); err != nil {
+		log.Fatalf("Error registering retention metrics: %v", err)
+	}
+	if err := kafka2.RegisterConsumerMetrics(reg); err != nil {
+		log.Fatalf("Error registering consumer metrics: %v", err)
+	}
+
+	if err := service.RegisterCacheMetrics(reg); err != nil {
+		log.Fatalf("Error registering cache metrics: %v", err)
+	}
+
+	if err := outbox.RegisterMetrics(reg, pool, "product-service"); err != nil {
+		log.Fatalf("Error registering outbox metrics: %v", err)
+	}
+
+	debugConfig, err := debug.LoadFromEnv()
+	if err != nil {
+		log.Fatalf("Error loading debug config: %v", err)
+	}
+
+	adminMux := http.NewServeMux()
+	adminMux.Handle("/metrics", config.MetricsHandler(reg))
+	if err := debug.Mount(adminMux, debugConfig); err != nil {
+		log.Fatalf("Error mounting debug endpoints: %v", err)
+	}
+
+	log.Println("Metrics server is listening on 9092 📈")
+	runner.Add(app.HTTPServer("metrics server", &http.Server{
+		Addr:              ":9092",
+		Handler:           adminMux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}))
+
+	stockWatcher := service.NewStockWatcher(pool, productRepository, warehouseRepository, logger)
+	productHandler := grpc.NewProductHandler(cachedProductService, logger, grpc.WithStockWatcher(stockWatcher))
+
+	kafkaConfig, err := kafka2.LoadConfig("product-service")
+	if err != nil {
+		log.Fatalf("error loading kafka config: %v", err)
+	}
+
+	kafkaProducer, err := kafka2.NewProducer(kafkaConfig)
+	if err != nil {
+		log.Fatalf("error creating kafka producer: %v", err)
+	}
+	runner.Add(app.Component{Name: "kafka producer", Stop: app.Closer(kafkaProducer.Close)})
+
+	consumer := productKafka.NewConsumer(productService, logger)
+
+	messageFormat, err := kafka2.ParseMessageFormat(utils.ParseWithFallback("KAFKA_MESSAGE_FORMAT", "envelope"))
+	if err != nil {
+		log.Fatalf("error parsing kafka message format: %v", err)
+	}
+
+	encryptionConfig, err := kafka2.LoadEncryptionConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption config: %v", err)
+	}
+
+	keyring, err := encryptionConfig.Keyring()
+	if err != nil {
+		log.Fatalf("error loading kafka encryption keys: %v", err)
+	}
+
+	outboxOpts := []outboxWorker.Option{outboxWorker.WithMessageFormat(messageFormat)}
+	if utils.ParseWithFallback("OUTBOX_LEADER_ELECTION", "false") == "true" {
+		outboxOpts = append(outboxOpts, outboxWorker.WithLeaderElection(outboxWorker.NewLockElector(locker, "product-service", logger)))
+	}
+	if compacted := utils.ParseWithFallback("OUTBOX_COMPACT_EVENT_TYPES", ""); compacted != "" {
+		outboxOpts = append(outboxOpts, outboxWorker.WithCompaction(strings.Split(compacted, ",")...))
+	}
+	if keyring != nil {
+		outboxOpts = append(outboxOpts, outboxWorker.WithEncryption(keyring, encryptionConfig.Topics))
+	}
+
+	outboxProcessor := outboxWorker.NewOutboxProcessor(pool, outboxRepository, kafkaProducer, logger, outboxOpts...)
+	configWatcher.Subscribe(func(t config.Tunables) {
+		outboxProcessor.SetInterval(t.OutboxInterval)
+	})
+
+	runner.Add(app.Component{Name: "outbox processor", Start: app.Loop(outboxProcessor.Start)})
+
+	scheduler := jobs.New(logger, jobs.WithLocks(locker))
+	err = scheduler.Add(jobs.Job{
+		Name:       "copurchase_rebuild",
+		Schedule:   "@hourly",
+		Run:        worker.NewCopurchaseJob(productService, logger).Run,
+		RunOnStart: true,
+	})
+	if err != nil {
+		log.Fatalf("Error scheduling jobs: %v", err)
+	}
+	err = scheduler.Add(jobs.Job{
+		Name:       "campaign_activation",
+		Schedule:   jobs.Every(time.Minute),
+		Run:        worker.NewCampaignJob(cachedProductService, logger).Run,
+		RunOnStart: true,
+	})
+	if err != nil {
+		log.Fatalf("Error scheduling jobs: %v", err)
+	}
+	runner.Add(app.Component{Name: "job scheduler", Start: app.Loop(scheduler.Start)})
+
+	lis, err := net.Listen("tcp", ":50052")
+	if err != nil {
+		log.Fatalf("Error listening on :50052 %v", err)
+	}
+
+	// The gateway already restricts stock adjustments, campaigns, exports
+	// and stock watches to admins; checking again here keeps other callers
+	// inside the cluster from bypassing it.
+	isAdmin := func(ctx context.Context) error { return authctx.RequireRole(ctx, "admin") }
+
+	s := googleGrpc.NewServer(
+		googleGrpc.ChainUnaryInterceptor(
+			tenant.UnaryServerInterceptor(),
+			authctx.UnaryServerInterceptor(),
+			authctx.RequireUnary(
+				isAdmin,
+				pb.ProductService_AdjustStock_FullMethodName,
+				pb.ProductService_CreateCampaign_FullMethodName,
+				pb.ProductService_ListCampaigns_FullMethodName,
+			),
+		),
+		googleGrpc.ChainStreamInterceptor(
+			tenant.StreamServerInterceptor(),
+			authctx.RequireStream(
+				isAdmin,
+				pb.ProductService_ExportProducts_FullMethodName,
+				pb.ProductService_WatchStock_FullMethodName,
+			),
+		),
+	)
+	pb.RegisterProductServiceServer(s, productHandler)
+	statusPb.RegisterStatusServiceServer(s, servicestatus.New(
+		"product-service",
+		logger,
+		servicestatus.WithOutbox(pool),
+		servicestatus.WithConsumerGroups(kafkaConfig, servicestatus.ConsumerGroup{ID: productKafka.GroupID, Topics: productKafka.Topics}),
+	))
+
+	reflectionCfg, err := config.LoadReflectionConfig()
+	if err != nil {
+		log.Fatalf("Error loading reflection config: %v", err)
+	}
+	if config.RegisterReflection(s, reflectionCfg) {
+		logger.Info("gRPC reflection is enabled, do not expose this port publicly")
+	}
+
+	consumerConfig, err := kafka2.LoadConsumerConfig()
+	if err != nil {
+		log.Fatalf("error loading kafka consumer config: %v", err)
+	}
+
+	inbox := kafka2.NewPostgresInbox(pool, "processed_messages")
+	inboxRetention := retention.NewJob(logger, retention.Policy{
+		Name:      "processed_messages",
+		Retention: consumerConfig.InboxRetention,
+		Purge:     retention.DeleteBefore(pool, "processed_messages", "processed_at"),
+	})
+	err = scheduler.Add(jobs.Job{
+		Name:     "inbox_retention",
+		Schedule: "@hourly",
+		Run:      inboxRetention.Run,
+	})
+	if err != nil {
+		log.Fatalf("Error scheduling jobs: %v", err)
+	}
+
+	runner.Add(app.Component{
+		Name: "kafka consumer",
+		Start: app.Loop(func(ctx context.Context) {
+			consumer.Start(
+				ctx,
+				kafkaConfig,
+				kafka2.WithDecryption(keyring),
+				kafka2.WithConcurrency(consumerConfig.Concurrency),
+				kafka2.WithMiddleware(kafka2.Metrics(), kafka2.Logging(logger), kafka2.Deduplicate(inbox, logger)),
+			)
+		}),
+	})
+	runner.Add(app.Component{
+		Name: "grpc server",
+		Start: func(context.Context) error {
+			log.Println("gRPC server listening on 50052 🔥")
+			return s.Serve(lis)
+		},
+		Stop: app.Func(s.GracefulStop),
+	})
+	// Added after the gRPC server so it stops first: GracefulStop waits for
+	// open WatchStock streams, which only end once the watcher does.
+	runner.Add(app.Component{Name: "stock watcher", Start: app.Loop(stockWatcher.Start)})
+
+	httpApp := fiber.New()
+	httpApp.Get("/health", func(c *fiber.Ctx) error {
+		return c.SendString("Product Service is alive!")
+	})
+
+	port := utils.ParseWithFallback("PORT", ":3002")
+
+	runner.Add(app.Component{
+		Name: "http server",
+		Start: func(context.Context) error {
+			log.Println("HTTP Product service listening on port: " + port)
+			return httpApp.Listen(port)
+		},
+		Stop: httpApp.ShutdownWithContext,
+	})
+
+	return runner.Run(ctx)
+}
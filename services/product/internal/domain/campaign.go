@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrInvalidCampaign = errors.New("invalid campaign")
+
+type CampaignStatus string
+
+const (
+	CampaignScheduled CampaignStatus = "scheduled"
+	CampaignActive    CampaignStatus = "active"
+	CampaignEnded     CampaignStatus = "ended"
+)
+
+// Campaign discounts a set of products by a percentage from StartsAt until
+// EndsAt.
+type Campaign struct {
+	ID              int64
+	TenantID        string
+	Name            string  `validate:"required,min=3,max=100"`
+	DiscountPercent int32   `validate:"gte=1,lte=99"`
+	ProductIDs      []int64 `validate:"required,min=1,max=500,dive,gt=0"`
+	StartsAt        time.Time
+	EndsAt          time.Time
+	// Status is how far the activator has got with the campaign, which may
+	// lag behind its window by up to a run.
+	Status    CampaignStatus
+	CreatedAt time.Time
+}
+
+func (c *Campaign) Validate() error {
+	if err := validate.Struct(c); err != nil {
+		return err
+	}
+
+	if c.StartsAt.IsZero() || !c.EndsAt.After(c.StartsAt) {
+		return fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidCampaign)
+	}
+
+	return nil
+}
+
+// NextStatus is the status the campaign should have at now. A campaign whose
+// whole window passed while it was still scheduled goes straight to ended.
+func (c *Campaign) NextStatus(now time.Time) CampaignStatus {
+	switch {
+	case !now.Before(c.EndsAt):
+		return CampaignEnded
+	case !now.Before(c.StartsAt):
+		return CampaignActive
+	default:
+		return CampaignScheduled
+	}
+}
+
+// Promotion is the campaign discount a product is sold with right now.
+type Promotion struct {
+	CampaignID      int64     `json:"campaign_id"`
+	Name            string    `json:"name"`
+	DiscountPercent int32     `json:"discount_percent"`
+	EndsAt          time.Time `json:"ends_at"`
+}
+
+// Apply takes the discount off price, rounding in the customer's favour.
+func (p *Promotion) Apply(price int64) int64 {
+	return price * int64(100-p.DiscountPercent) / 100
+}
@@ -5,17 +5,86 @@ import "time"
 type OrderItemEvent struct {
 	ProductID int64 `json:"product_id"`
 	Quantity  int64 `json:"quantity"`
+	// PriceOverride is the unit price support set on a manual order. Nil
+	// charges the catalog price.
+	PriceOverride *PriceOverride `json:"price_override,omitempty"`
+}
+
+type PriceOverride struct {
+	Price int64 `json:"price"`
 }
 
 type OrderCreatedEvent struct {
 	OrderID int64            `json:"order_id"`
 	UserID  int64            `json:"user_id"`
 	Items   []OrderItemEvent `json:"items"`
+	// ShipTo is where the order goes, when known. The nearest allocation
+	// strategy falls back to warehouse priority without it.
+	ShipTo *Location `json:"ship_to,omitempty"`
+	// PaymentMethodID is opaque to product and handed on to payment.
+	PaymentMethodID *int64 `json:"payment_method_id,omitempty"`
+	// DeliveryFee is quoted by the order service and charged on top of the
+	// reserved items.
+	DeliveryFee int64 `json:"delivery_fee,omitempty"`
+	// Discount is taken off the reserved items of a manual order, down to
+	// nothing at most.
+	Discount int64 `json:"discount,omitempty"`
 }
 
 type InventoryReservedEvent struct {
-	OrderID    int64     `json:"order_id"`
-	UserID     int64     `json:"user_id"`
-	Amount     int64     `json:"amount"`
-	ReservedAt time.Time `json:"reserved_at"`
+	OrderID         int64     `json:"order_id"`
+	UserID          int64     `json:"user_id"`
+	Amount          int64     `json:"amount"`
+	ReservedAt      time.Time `json:"reserved_at"`
+	PaymentMethodID *int64    `json:"payment_method_id,omitempty"`
+}
+
+type ReservedItemEvent struct {
+	ProductID int64 `json:"product_id"`
+	Quantity  int64 `json:"quantity"`
+	Price     int64 `json:"price"`
+}
+
+type InventoryPartiallyReservedEvent struct {
+	OrderID          int64               `json:"order_id"`
+	UserID           int64               `json:"user_id"`
+	Amount           int64               `json:"amount"`
+	ReservedItems    []ReservedItemEvent `json:"reserved_items"`
+	UnavailableItems []OrderItemEvent    `json:"unavailable_items"`
+	ReservedAt       time.Time           `json:"reserved_at"`
+}
+
+// StockChangedEvent is stock taken off sale outside of an order.
+type StockChangedEvent struct {
+	ProductID     int64     `json:"product_id"`
+	Delta         int64     `json:"delta"`
+	StockQuantity int64     `json:"stock_quantity"`
+	Actor         string    `json:"actor"`
+	ChangedAt     time.Time `json:"changed_at"`
+}
+
+type StockAdjustedEvent struct {
+	ProductID     int64     `json:"product_id"`
+	WarehouseID   int64     `json:"warehouse_id"`
+	Delta         int64     `json:"delta"`
+	StockQuantity int64     `json:"stock_quantity"`
+	Reason        string    `json:"reason"`
+	Note          string    `json:"note,omitempty"`
+	Actor         string    `json:"actor"`
+	AdjustedAt    time.Time `json:"adjusted_at"`
+}
+
+type ProductPromotionStartedEvent struct {
+	CampaignID      int64     `json:"campaign_id"`
+	Name            string    `json:"name"`
+	ProductIDs      []int64   `json:"product_ids"`
+	DiscountPercent int32     `json:"discount_percent"`
+	StartsAt        time.Time `json:"starts_at"`
+	EndsAt          time.Time `json:"ends_at"`
+}
+
+type ProductPromotionEndedEvent struct {
+	CampaignID int64     `json:"campaign_id"`
+	ProductIDs []int64   `json:"product_ids"`
+	EndedAt    time.Time `json:"ended_at"`
 }
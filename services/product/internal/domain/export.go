@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ProductExportFilter selects the products of a catalog export.
+type ProductExportFilter struct {
+	// Category keeps only products in that category when set.
+	Category       string
+	IncludeDeleted bool
+}
+
+// PriceHistory summarises the prices a product had, the current one
+// included.
+type PriceHistory struct {
+	Min int64
+	Max int64
+	// Changes counts updates of the price since the product was created.
+	Changes int64
+	// ChangedAt is the time of the last change, zero while there is none.
+	ChangedAt time.Time
+}
+
+// ProductExportRow is one product as merchandisers see it.
+type ProductExportRow struct {
+	Product Product
+	// WarehouseStock is the stock per warehouse code; warehouses without
+	// the product are left out.
+	WarehouseStock map[string]int64
+	PriceHistory   PriceHistory
+}
+
+// CategoryPath splits a category into its levels, top first. Categories are
+// stored as one string, a nested one written as "Home > Kitchen" or
+// "Home/Kitchen".
+func CategoryPath(category string) []string {
+	parts := strings.FieldsFunc(category, func(r rune) bool {
+		return r == '>' || r == '/'
+	})
+
+	path := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			path = append(path, part)
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,28 @@
+package domain
+
+type ProductSort string
+
+const (
+	SortNewest    ProductSort = "newest"
+	SortPriceAsc  ProductSort = "price_asc"
+	SortPriceDesc ProductSort = "price_desc"
+	SortRating    ProductSort = "rating"
+	SortStockAsc  ProductSort = "stock_asc"
+)
+
+// ProductFilter describes a faceted catalog query. Zero values mean
+// "no restriction" for every facet.
+type ProductFilter struct {
+	Limit  int64
+	Offset int64
+	Search string
+
+	Categories  []string
+	PriceMin    int64
+	PriceMax    int64
+	InStockOnly bool
+	StockMax    int64
+	// Attributes matches products having any of the listed values for every key.
+	Attributes map[string][]string
+	Sort       ProductSort
+}
@@ -1,24 +1,84 @@
 package domain
 
 import (
+	"regexp"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 )
 
-var validate = validator.New()
+var (
+	validate = newValidator()
+	skuRegex = regexp.MustCompile(`^[A-Z0-9][A-Z0-9._-]{2,63}$`)
+)
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+
+	_ = v.RegisterValidation("sku", func(fl validator.FieldLevel) bool {
+		return skuRegex.MatchString(fl.Field().String())
+	})
+	_ = v.RegisterValidation("ean13", func(fl validator.FieldLevel) bool {
+		return IsValidEAN13(fl.Field().String())
+	})
+
+	return v
+}
+
+// IsValidEAN13 checks the length, digits and the check digit of an EAN-13 barcode.
+func IsValidEAN13(code string) bool {
+	if len(code) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+
+		digit := int(r - '0')
+		if i == 12 {
+			return (10-sum%10)%10 == digit
+		}
+
+		if i%2 == 1 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	return false
+}
 
 type Product struct {
-	ID            int64     `db:"id"`
-	Name          string    `db:"name" validate:"required,min=3,max=100"`
-	Description   string    `db:"description" validate:"max=1000"`
-	Price         int64     `db:"price" validate:"required,gt=0"`
-	StockQuantity int64     `db:"stock_quantity" validate:"gte=0"`
-	ImageUrl      string    `db:"image_url" validate:"omitempty,url"`
-	Category      string    `db:"category" validate:"required"`
-	CreatedAt     time.Time `db:"created_at"`
-	UpdatedAt     time.Time `db:"updated_at"`
-	DeletedAt     time.Time `db:"deleted_at" json:"-"`
+	ID            int64             `db:"id"`
+	Name          string            `db:"name" validate:"required,min=3,max=100"`
+	Description   string            `db:"description" validate:"max=1000"`
+	Price         int64             `db:"price" validate:"required,gt=0"`
+	StockQuantity int64             `db:"stock_quantity" validate:"gte=0"`
+	ImageUrl      string            `db:"image_url" validate:"omitempty,url"`
+	Category      string            `db:"category" validate:"required"`
+	SKU           string            `db:"sku" validate:"omitempty,sku"`
+	EAN           string            `db:"ean" validate:"omitempty,ean13"`
+	Attributes    map[string]string `db:"attributes"`
+	Rating        float64           `db:"rating"`
+	CreatedAt     time.Time         `db:"created_at"`
+	UpdatedAt     time.Time         `db:"updated_at"`
+	DeletedAt     time.Time         `db:"deleted_at" json:"-"`
+	// Promotion is the campaign the product is discounted by, nil at list
+	// price.
+	Promotion *Promotion `db:"-"`
+}
+
+// EffectivePrice is what the product sells for: Price, less the discount
+// of its promotion.
+func (p *Product) EffectivePrice() int64 {
+	if p.Promotion == nil {
+		return p.Price
+	}
+
+	return p.Promotion.Apply(p.Price)
 }
 
 type UpdateProductInput struct {
@@ -30,6 +90,28 @@ type UpdateProductInput struct {
 	Category      *string `json:"category"`
 }
 
+// ApplyTo copies the set fields of the update onto product.
+func (p *UpdateProductInput) ApplyTo(product *Product) {
+	if p.Name != nil {
+		product.Name = *p.Name
+	}
+	if p.Description != nil {
+		product.Description = *p.Description
+	}
+	if p.Price != nil {
+		product.Price = *p.Price
+	}
+	if p.StockQuantity != nil {
+		product.StockQuantity = *p.StockQuantity
+	}
+	if p.ImageUrl != nil {
+		product.ImageUrl = *p.ImageUrl
+	}
+	if p.Category != nil {
+		product.Category = *p.Category
+	}
+}
+
 func (p *Product) Validate() error {
 	return validate.Struct(p)
 }
@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type RevisionAction string
+
+const (
+	RevisionCreated       RevisionAction = "created"
+	RevisionUpdated       RevisionAction = "updated"
+	RevisionDeleted       RevisionAction = "deleted"
+	RevisionRestored      RevisionAction = "restored"
+	RevisionStockChanged  RevisionAction = "stock_changed"
+	RevisionStockAdjusted RevisionAction = "stock_adjusted"
+)
+
+// ActorSystem is recorded for changes nobody requested directly, such as
+// stock reserved for an order.
+const ActorSystem = "system"
+
+// FieldChange is one changed field; From is nil for created products.
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type Changes map[string]FieldChange
+
+type ProductRevision struct {
+	ID        int64
+	ProductID int64
+	Action    RevisionAction
+	Actor     string
+	Changes   Changes
+	CreatedAt time.Time
+}
+
+type actorKey struct{}
+
+// WithActor tags ctx with who is making the change, e.g. "user:42".
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+
+	return ActorSystem
+}
+
+// Diff lists the fields that differ between two versions of a product. A nil
+// before describes a new product.
+func Diff(before, after *Product) Changes {
+	var old Product
+	if before != nil {
+		old = *before
+	}
+
+	changes := Changes{}
+	add := func(field string, from, to any, changed bool) {
+		if !changed && before != nil {
+			return
+		}
+		if before == nil {
+			from = nil
+		}
+		changes[field] = FieldChange{From: from, To: to}
+	}
+
+	add("name", old.Name, after.Name, old.Name != after.Name)
+	add("description", old.Description, after.Description, old.Description != after.Description)
+	add("price", old.Price, after.Price, old.Price != after.Price)
+	add("stock_quantity", old.StockQuantity, after.StockQuantity, old.StockQuantity != after.StockQuantity)
+	add("image_url", old.ImageUrl, after.ImageUrl, old.ImageUrl != after.ImageUrl)
+	add("category", old.Category, after.Category, old.Category != after.Category)
+	add("sku", old.SKU, after.SKU, old.SKU != after.SKU)
+	add("ean", old.EAN, after.EAN, old.EAN != after.EAN)
+
+	return changes
+}
@@ -0,0 +1,97 @@
+package domain
+
+import "errors"
+
+var (
+	ErrInvalidStockAdjustment = errors.New("invalid stock adjustment")
+	ErrInvalidStockWatch      = errors.New("invalid stock watch")
+)
+
+// StockAdjustmentReason says why stock was changed by hand. Reservations and
+// cancellations change stock on their own and are not adjustments.
+type StockAdjustmentReason string
+
+const (
+	// AdjustRestock is a delivery from a supplier.
+	AdjustRestock StockAdjustmentReason = "restock"
+	// AdjustCustomerReturn is a unit sent back by a customer and fit to sell.
+	AdjustCustomerReturn StockAdjustmentReason = "customer_return"
+	// AdjustDamaged is a unit that can no longer be sold.
+	AdjustDamaged StockAdjustmentReason = "damaged"
+	// AdjustLost is a unit missing from the shelf.
+	AdjustLost StockAdjustmentReason = "lost"
+	// AdjustCorrection fixes a miscount in either direction, e.g. after a
+	// stocktake.
+	AdjustCorrection StockAdjustmentReason = "correction"
+)
+
+// StockAdjustment adds Delta units, or takes them when negative, to one
+// warehouse and the product total.
+type StockAdjustment struct {
+	ProductID int64
+	// WarehouseID is the default warehouse when zero.
+	WarehouseID int64
+	Delta       int64
+	Reason      StockAdjustmentReason
+	Note        string
+}
+
+const maxAdjustmentNote = 500
+
+// Validate checks that the reason fits the direction: stock only arrives
+// through restocks and returns and only leaves through damage and loss.
+func (a *StockAdjustment) Validate() error {
+	if a.ProductID <= 0 || a.WarehouseID < 0 || a.Delta == 0 || len(a.Note) > maxAdjustmentNote {
+		return ErrInvalidStockAdjustment
+	}
+
+	switch a.Reason {
+	case AdjustRestock, AdjustCustomerReturn:
+		if a.Delta < 0 {
+			return ErrInvalidStockAdjustment
+		}
+	case AdjustDamaged, AdjustLost:
+		if a.Delta > 0 {
+			return ErrInvalidStockAdjustment
+		}
+	case AdjustCorrection:
+	default:
+		return ErrInvalidStockAdjustment
+	}
+
+	return nil
+}
+
+// MaxWatchedProducts is how many products one stock watch may follow.
+const MaxWatchedProducts = 100
+
+// StockLevel is a product's stock as a whole and per warehouse.
+type StockLevel struct {
+	ProductID  int64
+	Total      int64
+	Warehouses []WarehouseStock
+}
+
+// WatchedProducts checks the products of a stock watch and drops repeats,
+// keeping the first occurrence of each.
+func WatchedProducts(ids []int64) ([]int64, error) {
+	if len(ids) == 0 || len(ids) > MaxWatchedProducts {
+		return nil, ErrInvalidStockWatch
+	}
+
+	seen := make(map[int64]struct{}, len(ids))
+	res := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, ErrInvalidStockWatch
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+
+		seen[id] = struct{}{}
+		res = append(res, id)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,137 @@
+package domain
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+var ErrUnknownAllocationStrategy = errors.New("unknown allocation strategy")
+
+// AllocationStrategy decides which warehouses a reservation draws from.
+type AllocationStrategy string
+
+const (
+	// AllocateNearest prefers the warehouses closest to the shipping
+	// location, falling back to priority when the location is unknown.
+	AllocateNearest AllocationStrategy = "nearest"
+	// AllocateMostStocked prefers the fullest warehouses, which keeps most
+	// orders in a single shipment.
+	AllocateMostStocked AllocationStrategy = "most_stocked"
+)
+
+func ParseAllocationStrategy(raw string) (AllocationStrategy, error) {
+	switch strategy := AllocationStrategy(raw); strategy {
+	case AllocateNearest, AllocateMostStocked:
+		return strategy, nil
+	default:
+		return "", ErrUnknownAllocationStrategy
+	}
+}
+
+type Location struct {
+	Latitude  float64 `json:"lat" validate:"gte=-90,lte=90"`
+	Longitude float64 `json:"lon" validate:"gte=-180,lte=180"`
+}
+
+const earthRadiusKm = 6371.0
+
+// DistanceKm is the great-circle distance between two locations.
+func (l Location) DistanceKm(other Location) float64 {
+	lat1 := l.Latitude * math.Pi / 180
+	lat2 := other.Latitude * math.Pi / 180
+	dLat := lat2 - lat1
+	dLon := (other.Longitude - l.Longitude) * math.Pi / 180
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+type Warehouse struct {
+	ID       int64     `db:"id"`
+	Code     string    `db:"code" validate:"required,max=32"`
+	Name     string    `db:"name" validate:"required,min=3,max=100"`
+	Location *Location `db:"-" validate:"omitempty"`
+	// Priority breaks ties between otherwise equal warehouses, higher first.
+	Priority  int32     `db:"priority"`
+	IsActive  bool      `db:"is_active"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (w *Warehouse) Validate() error {
+	return validate.Struct(w)
+}
+
+// WarehouseStock is the quantity of one product held in one warehouse.
+type WarehouseStock struct {
+	WarehouseID   int64
+	WarehouseCode string
+	Location      *Location
+	Priority      int32
+	Quantity      int64
+}
+
+// Allocation is the part of a reservation taken from one warehouse.
+type Allocation struct {
+	WarehouseID int64 `json:"warehouse_id"`
+	Quantity    int64 `json:"quantity"`
+}
+
+// Allocate splits quantity across warehouses in the order the strategy
+// prefers, draining each before moving on to the next. It reports false when
+// the warehouses together hold less than quantity.
+func Allocate(stock []WarehouseStock, quantity int64, strategy AllocationStrategy, shipTo *Location) ([]Allocation, bool) {
+	candidates := make([]WarehouseStock, 0, len(stock))
+	for _, s := range stock {
+		if s.Quantity > 0 {
+			candidates = append(candidates, s)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+
+		switch strategy {
+		case AllocateNearest:
+			if shipTo != nil && (a.Location == nil) != (b.Location == nil) {
+				return a.Location != nil
+			}
+			if shipTo != nil && a.Location != nil && b.Location != nil {
+				da, db := shipTo.DistanceKm(*a.Location), shipTo.DistanceKm(*b.Location)
+				if da != db {
+					return da < db
+				}
+			}
+		case AllocateMostStocked:
+			if a.Quantity != b.Quantity {
+				return a.Quantity > b.Quantity
+			}
+		}
+
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+
+		return a.WarehouseID < b.WarehouseID
+	})
+
+	var allocations []Allocation
+	remaining := quantity
+	for _, s := range candidates {
+		if remaining == 0 {
+			break
+		}
+
+		take := min(s.Quantity, remaining)
+		allocations = append(allocations, Allocation{WarehouseID: s.WarehouseID, Quantity: take})
+		remaining -= take
+	}
+
+	if remaining > 0 {
+		return nil, false
+	}
+
+	return allocations, true
+}
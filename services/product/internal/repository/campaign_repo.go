@@ -0,0 +1,327 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// CampaignRepository stores price campaigns. Whether a campaign discounts a
+// product is decided by its window alone; the status only records how far
+// the activator has got.
+type CampaignRepository interface {
+	Create(ctx context.Context, tx pgx.Tx, campaign *domain.Campaign) error
+	List(ctx context.Context, includeEnded bool, page query.Page) ([]domain.Campaign, error)
+	Promotions(ctx context.Context, productIDs []int64, at time.Time) (map[int64]domain.Promotion, error)
+	ListPromoted(ctx context.Context, at time.Time, limit int64) ([]domain.Product, error)
+	ListDue(ctx context.Context, at time.Time, limit int) ([]domain.Campaign, error)
+	SetStatus(ctx context.Context, tx pgx.Tx, id int64, from, to domain.CampaignStatus) (bool, error)
+}
+
+type campaignRepo struct {
+	pool   *pgxpool.Pool
+	tracer trace.Tracer
+	logger *zap.Logger
+}
+
+func NewCampaignRepository(pool *pgxpool.Pool, logger *zap.Logger) CampaignRepository {
+	return &campaignRepo{
+		pool:   pool,
+		logger: logger,
+		tracer: otel.Tracer("contract/campaign_repo"),
+	}
+}
+
+const campaignColumns = `c.id, c.tenant_id, c.name, c.discount_percent, c.starts_at, c.ends_at, c.status, c.created_at,
+	ARRAY(SELECT cp.product_id FROM campaign_products cp WHERE cp.campaign_id = c.id ORDER BY cp.product_id)`
+
+// Create stores campaign and its products, filling in its ID, status and
+// creation time.
+func (r *campaignRepo) Create(ctx context.Context, tx pgx.Tx, campaign *domain.Campaign) error {
+	ctx, span := r.tracer.Start(ctx, "CampaignRepository.Create")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("name", campaign.Name),
+		attribute.Int("products", len(campaign.ProductIDs)),
+	)
+
+	campaign.TenantID = tenant.FromContext(ctx)
+	campaign.Status = domain.CampaignScheduled
+
+	err := tx.QueryRow(ctx, `
+		INSERT INTO campaigns (tenant_id, name, discount_percent, starts_at, ends_at, status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at;
+	`,
+		campaign.TenantID,
+		campaign.Name,
+		campaign.DiscountPercent,
+		campaign.StartsAt,
+		campaign.EndsAt,
+		campaign.Status,
+	).Scan(&campaign.ID, &campaign.CreatedAt)
+	if err != nil {
+		span.RecordError(err)
+		mylogger.Error(ctx, r.logger, "Failed to create campaign", zap.String("name", campaign.Name), zap.Error(err))
+
+		return fmt.Errorf("error creating campaign: %w", err)
+	}
+
+	// Only live products of the campaign's tenant can be discounted; any
+	// other ID leaves the count short.
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO campaign_products (campaign_id, product_id)
+		SELECT $1, p.id
+		FROM products p
+		WHERE p.id = ANY($2) AND p.tenant_id = $3 AND p.deleted_at IS NULL
+		ON CONFLICT DO NOTHING;
+	`, campaign.ID, campaign.ProductIDs, campaign.TenantID)
+	if err != nil {
+		span.RecordError(err)
+		mylogger.Error(ctx, r.logger, "Failed to add campaign products", zap.Int64("campaign_id", campaign.ID), zap.Error(err))
+
+		return fmt.Errorf("error adding campaign products: %w", err)
+	}
+
+	if tag.RowsAffected() != int64(len(campaign.ProductIDs)) {
+		return ErrProductNotFound
+	}
+
+	return nil
+}
+
+// List returns the campaigns of the tenant on ctx, latest start first.
+// Ended campaigns are left out unless includeEnded is set.
+func (r *campaignRepo) List(ctx context.Context, includeEnded bool, page query.Page) ([]domain.Campaign, error) {
+	ctx, span := r.tracer.Start(ctx, "CampaignRepository.List")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Bool("include_ended", includeEnded),
+		attribute.Int64("limit", page.Limit),
+		attribute.Int64("offset", page.Offset),
+	)
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+campaignColumns+`
+		FROM campaigns c
+		WHERE c.tenant_id = $1 AND ($2 OR c.ends_at > NOW())
+		ORDER BY c.starts_at DESC, c.id DESC
+		LIMIT $3 OFFSET $4;
+	`, tenant.FromContext(ctx), includeEnded, page.Limit, page.Offset)
+	if err != nil {
+		span.RecordError(err)
+		mylogger.Error(ctx, r.logger, "Failed to list campaigns", zap.Error(err))
+
+		return nil, fmt.Errorf("error listing campaigns: %w", err)
+	}
+
+	return scanCampaigns(rows)
+}
+
+// Promotions returns the best discount each of productIDs has at, for the
+// tenant on ctx. Products without one are missing from the map.
+func (r *campaignRepo) Promotions(ctx context.Context, productIDs []int64, at time.Time) (map[int64]domain.Promotion, error) {
+	promotions := make(map[int64]domain.Promotion)
+	if len(productIDs) == 0 {
+		return promotions, nil
+	}
+
+	ctx, span := r.tracer.Start(ctx, "CampaignRepository.Promotions")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int("products", len(productIDs)))
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT DISTINCT ON (cp.product_id) cp.product_id, c.id, c.name, c.discount_percent, c.ends_at
+		FROM campaign_products cp
+		JOIN campaigns c ON c.id = cp.campaign_id
+		WHERE cp.product_id = ANY($1)
+			AND c.tenant_id = $2
+			AND c.starts_at <= $3 AND c.ends_at > $3
+		ORDER BY cp.product_id, c.discount_percent DESC, c.ends_at, c.id;
+	`, productIDs, tenant.FromContext(ctx), at)
+	if err != nil {
+		span.RecordError(err)
+		mylogger.Error(ctx, r.logger, "Failed to get promotions", zap.Error(err))
+
+		return nil, fmt.Errorf("error getting promotions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			productID int64
+			promotion domain.Promotion
+		)
+		if err := rows.Scan(&productID, &promotion.CampaignID, &promotion.Name, &promotion.DiscountPercent, &promotion.EndsAt); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("error scanning promotion: %w", err)
+		}
+
+		promotions[productID] = promotion
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return promotions, nil
+}
+
+// ListPromoted returns in-stock products discounted at at, the biggest
+// discounts first.
+func (r *campaignRepo) ListPromoted(ctx context.Context, at time.Time, limit int64) ([]domain.Product, error) {
+	if limit <= 0 {
+		return nil, ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "CampaignRepository.ListPromoted")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("limit", limit))
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT p.id, p.name, p.description, p.price, p.stock_quantity,
+		p.image_url, p.category, COALESCE(p.sku, ''), COALESCE(p.ean, ''), p.attributes, p.rating,
+		p.created_at, p.updated_at
+		FROM products p
+		JOIN LATERAL (
+			SELECT MAX(c.discount_percent) AS discount_percent, MIN(c.ends_at) AS ends_at
+			FROM campaign_products cp
+			JOIN campaigns c ON c.id = cp.campaign_id
+			WHERE cp.product_id = p.id
+				AND c.tenant_id = p.tenant_id
+				AND c.starts_at <= $1 AND c.ends_at > $1
+		) best ON best.discount_percent IS NOT NULL
+		WHERE p.tenant_id = $3
+			AND p.deleted_at IS NULL
+			AND p.stock_quantity > 0
+		ORDER BY best.discount_percent DESC, best.ends_at, p.id
+		LIMIT $2;
+	`, at, limit, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		mylogger.Error(ctx, r.logger, "Failed to list promoted products", zap.Error(err))
+
+		return nil, fmt.Errorf("error listing promoted products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]domain.Product, 0, limit)
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(
+			&p.ID,
+			&p.Name,
+			&p.Description,
+			&p.Price,
+			&p.StockQuantity,
+			&p.ImageUrl,
+			&p.Category,
+			&p.SKU,
+			&p.EAN,
+			&p.Attributes,
+			&p.Rating,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("error scanning promoted product: %w", err)
+		}
+
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
+
+// ListDue returns campaigns of every tenant whose status is behind their
+// window at at: scheduled ones that started and unended ones that ended.
+func (r *campaignRepo) ListDue(ctx context.Context, at time.Time, limit int) ([]domain.Campaign, error) {
+	ctx, span := r.tracer.Start(ctx, "CampaignRepository.ListDue")
+	defer span.End()
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+campaignColumns+`
+		FROM campaigns c
+		WHERE c.status <> 'ended'
+			AND ((c.status = 'scheduled' AND c.starts_at <= $1) OR c.ends_at <= $1)
+		ORDER BY c.starts_at, c.id
+		LIMIT $2;
+	`, at, limit)
+	if err != nil {
+		span.RecordError(err)
+		mylogger.Error(ctx, r.logger, "Failed to list due campaigns", zap.Error(err))
+
+		return nil, fmt.Errorf("error listing due campaigns: %w", err)
+	}
+
+	return scanCampaigns(rows)
+}
+
+// SetStatus moves a campaign from one status to another. It reports false
+// when the campaign was no longer in from, i.e. someone else moved it.
+func (r *campaignRepo) SetStatus(ctx context.Context, tx pgx.Tx, id int64, from, to domain.CampaignStatus) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "CampaignRepository.SetStatus")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("campaign_id", id),
+		attribute.String("from", string(from)),
+		attribute.String("to", string(to)),
+	)
+
+	tag, err := tx.Exec(ctx, `UPDATE campaigns SET status = $3 WHERE id = $1 AND status = $2;`, id, from, to)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("error updating campaign status: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func scanCampaigns(rows pgx.Rows) ([]domain.Campaign, error) {
+	defer rows.Close()
+
+	var campaigns []domain.Campaign
+	for rows.Next() {
+		var c domain.Campaign
+		if err := rows.Scan(
+			&c.ID,
+			&c.TenantID,
+			&c.Name,
+			&c.DiscountPercent,
+			&c.StartsAt,
+			&c.EndsAt,
+			&c.Status,
+			&c.CreatedAt,
+			&c.ProductIDs,
+		); err != nil {
+			return nil, fmt.Errorf("error scanning campaign: %w", err)
+		}
+
+		campaigns = append(campaigns, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return campaigns, nil
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ExportProducts returns the next page of products matching filter in id
+// order, after afterID; pass 0 to start. Stock per warehouse and the price
+// history summary are read alongside, so a page is one round trip.
+func (r *productRepo) ExportProducts(ctx context.Context, filter domain.ProductExportFilter, afterID int64, limit int) ([]domain.ProductExportRow, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.ExportProducts")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("after_id", afterID),
+		attribute.Int("limit", limit),
+	)
+
+	// The price of a product created before revisions were recorded only
+	// shows in the current price, hence the LEAST and GREATEST with it.
+	query := `
+		SELECT p.id, p.name, COALESCE(p.sku, ''), COALESCE(p.ean, ''), p.category,
+			p.price, p.stock_quantity, p.created_at, p.deleted_at,
+			COALESCE(ws.stock, '{}'::jsonb),
+			LEAST(p.price, COALESCE(ph.min_price, p.price)),
+			GREATEST(p.price, COALESCE(ph.max_price, p.price)),
+			COALESCE(ph.changes, 0),
+			ph.changed_at
+		FROM products p
+		LEFT JOIN LATERAL (
+			SELECT jsonb_object_agg(w.code, s.quantity) AS stock
+			FROM warehouse_stock s
+			JOIN warehouses w ON w.id = s.warehouse_id
+			WHERE s.product_id = p.id AND s.quantity > 0
+		) ws ON TRUE
+		LEFT JOIN LATERAL (
+			SELECT
+				MIN((r.changes->'price'->>'to')::bigint) AS min_price,
+				MAX((r.changes->'price'->>'to')::bigint) AS max_price,
+				COUNT(*) FILTER (WHERE r.action <> 'created') AS changes,
+				MAX(r.created_at) FILTER (WHERE r.action <> 'created') AS changed_at
+			FROM product_revisions r
+			WHERE r.product_id = p.id AND r.changes ? 'price'
+		) ph ON TRUE
+		WHERE p.tenant_id = $1
+			AND ($2 = '' OR p.category = $2)
+			AND ($3 OR p.deleted_at IS NULL)
+			AND p.id > $4
+		ORDER BY p.id
+		LIMIT $5;
+	`
+
+	rows, err := r.pool.Query(ctx, query,
+		tenant.FromContext(ctx),
+		filter.Category,
+		filter.IncludeDeleted,
+		afterID,
+		limit,
+	)
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("failed to query products for export: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]domain.ProductExportRow, 0, limit)
+	for rows.Next() {
+		var (
+			row       domain.ProductExportRow
+			deletedAt *time.Time
+			changedAt *time.Time
+		)
+		if err := rows.Scan(
+			&row.Product.ID,
+			&row.Product.Name,
+			&row.Product.SKU,
+			&row.Product.EAN,
+			&row.Product.Category,
+			&row.Product.Price,
+			&row.Product.StockQuantity,
+			&row.Product.CreatedAt,
+			&deletedAt,
+			&row.WarehouseStock,
+			&row.PriceHistory.Min,
+			&row.PriceHistory.Max,
+			&row.PriceHistory.Changes,
+			&changedAt,
+		); err != nil {
+			span.RecordError(err)
+
+			return nil, fmt.Errorf("failed to scan exported product: %w", err)
+		}
+
+		if deletedAt != nil {
+			row.Product.DeletedAt = *deletedAt
+		}
+		if changedAt != nil {
+			row.PriceHistory.ChangedAt = *changedAt
+		}
+
+		result = append(result, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// RecordAffinity adds the units of a paid order to the user's score for each
+// category they belong to. It reports false for an order that was already
+// counted.
+func (r *productRepo) RecordAffinity(ctx context.Context, orderID, userID int64, items []domain.OrderItemEvent) (bool, error) {
+	if orderID <= 0 || userID <= 0 {
+		return false, ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.RecordAffinity")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.Int64("user_id", userID),
+		attribute.Int("items", len(items)),
+	)
+
+	productIDs := make([]int64, 0, len(items))
+	quantities := make([]int64, 0, len(items))
+	for _, item := range items {
+		productIDs = append(productIDs, item.ProductID)
+		quantities = append(quantities, item.Quantity)
+	}
+
+	// The order is claimed and scored in one statement; a claim that
+	// conflicts returns no row, so nothing is scored.
+	query := `
+		WITH claimed AS (
+			INSERT INTO affinity_orders (tenant_id, order_id, user_id)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (tenant_id, order_id) DO NOTHING
+			RETURNING order_id
+		), scored AS (
+			INSERT INTO user_category_affinity (tenant_id, user_id, category, score)
+			SELECT $1, $3, p.category, SUM(i.quantity)
+			FROM claimed
+			CROSS JOIN unnest($4::bigint[], $5::bigint[]) AS i(product_id, quantity)
+			JOIN products p ON p.id = i.product_id AND p.tenant_id = $1
+			WHERE p.category IS NOT NULL AND p.category <> ''
+			GROUP BY p.category
+			ON CONFLICT (tenant_id, user_id, category)
+			DO UPDATE SET score = user_category_affinity.score + EXCLUDED.score, updated_at = NOW()
+		)
+		SELECT COUNT(*) FROM claimed;
+	`
+
+	var claimed int64
+	err := r.pool.QueryRow(ctx, query, tenant.FromContext(ctx), orderID, userID, productIDs, quantities).Scan(&claimed)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to record category affinity",
+			zap.Int64("order_id", orderID),
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+
+		return false, fmt.Errorf("failed to record category affinity: %w", err)
+	}
+
+	return claimed > 0, nil
+}
+
+// GetPersonalized ranks in-stock products by the user's affinity for their
+// category, then by rating, so users without purchases get the best rated
+// products.
+func (r *productRepo) GetPersonalized(ctx context.Context, userID, limit int64) ([]domain.Product, error) {
+	if userID <= 0 || limit <= 0 {
+		return nil, ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.GetPersonalized")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("user_id", userID),
+		attribute.Int64("limit", limit),
+	)
+
+	query := `
+		SELECT p.id, p.name, p.description, p.price, p.stock_quantity,
+		p.image_url, p.category, COALESCE(p.sku, ''), COALESCE(p.ean, ''), p.attributes, p.rating,
+		p.created_at, p.updated_at
+		FROM products p
+		LEFT JOIN user_category_affinity a
+			ON a.tenant_id = p.tenant_id AND a.user_id = $1 AND a.category = p.category
+		WHERE p.tenant_id = $3
+			AND p.deleted_at IS NULL
+			AND p.stock_quantity > 0
+		ORDER BY COALESCE(a.score, 0) DESC, p.rating DESC, p.created_at DESC, p.id DESC
+		LIMIT $2;
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, limit, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to get personalized products",
+			zap.Int64("user_id", userID),
+			zap.Error(err),
+		)
+
+		return nil, fmt.Errorf("failed to get personalized products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]domain.Product, 0, limit)
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(
+			&p.ID,
+			&p.Name,
+			&p.Description,
+			&p.Price,
+			&p.StockQuantity,
+			&p.ImageUrl,
+			&p.Category,
+			&p.SKU,
+			&p.EAN,
+			&p.Attributes,
+			&p.Rating,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("error scanning personalized product: %w", err)
+		}
+
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
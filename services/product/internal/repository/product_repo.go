@@ -2,15 +2,21 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository/sqlc"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -20,15 +26,28 @@ import (
 type ProductRepository interface {
 	Create(ctx context.Context, tx pgx.Tx, product *domain.Product) (int64, error)
 	GetByID(ctx context.Context, id int64) (*domain.Product, error)
-	List(ctx context.Context, limit, offset int64, search string) ([]domain.Product, int64, error)
+	GetBySKU(ctx context.Context, sku string) (*domain.Product, error)
+	List(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, int64, error)
 	DeleteByID(ctx context.Context, id int64) error
+	RestoreByID(ctx context.Context, id int64) error
+	ListDeleted(ctx context.Context, page query.Page) ([]domain.Product, int64, error)
 	Update(ctx context.Context, id int64, input *domain.UpdateProductInput) error
-	DecreaseStock(ctx context.Context, tx pgx.Tx, id, quantity int64) (int64, error)
-	IncreaseStock(ctx context.Context, tx pgx.Tx, id int64, quantity int32) error
+	DecreaseStock(ctx context.Context, tx pgx.Tx, id, quantity int64) (price, stock int64, err error)
+	IncreaseStock(ctx context.Context, tx pgx.Tx, id, quantity int64) error
+	AdjustStock(ctx context.Context, tx pgx.Tx, id int64, adjustment *domain.StockAdjustment) (int64, error)
+	RecordPurchases(ctx context.Context, tx pgx.Tx, orderID int64, productIDs []int64) error
+	RebuildCopurchases(ctx context.Context, tx pgx.Tx) (int64, error)
+	GetRelated(ctx context.Context, productID, limit int64) ([]domain.Product, error)
+	RecordAffinity(ctx context.Context, orderID, userID int64, items []domain.OrderItemEvent) (bool, error)
+	GetPersonalized(ctx context.Context, userID, limit int64) ([]domain.Product, error)
+	GetHistory(ctx context.Context, productID int64, page query.Page) ([]domain.ProductRevision, error)
+	ExportProducts(ctx context.Context, filter domain.ProductExportFilter, afterID int64, limit int) ([]domain.ProductExportRow, error)
 }
 
 type productRepo struct {
-	pool   *pgxpool.Pool
+	pool *pgxpool.Pool
+	// q holds the sqlc-generated queries from queries/*.sql.
+	q      *sqlc.Queries
 	tracer trace.Tracer
 	logger *zap.Logger
 }
@@ -36,12 +55,13 @@ type productRepo struct {
 func NewProductRepository(pool *pgxpool.Pool, logger *zap.Logger) ProductRepository {
 	return &productRepo{
 		pool:   pool,
+		q:      sqlc.New(pool),
 		logger: logger,
 		tracer: otel.Tracer("contract/product_repo"),
 	}
 }
 
-func (r *productRepo) IncreaseStock(ctx context.Context, tx pgx.Tx, id int64, quantity int32) error {
+func (r *productRepo) IncreaseStock(ctx context.Context, tx pgx.Tx, id, quantity int64) error {
 	if id <= 0 || quantity <= 0 {
 		return ErrInvalidInput
 	}
@@ -51,33 +71,35 @@ func (r *productRepo) IncreaseStock(ctx context.Context, tx pgx.Tx, id int64, qu
 
 	span.SetAttributes(
 		attribute.Int64("id", id),
-		attribute.Int("quantity", int(quantity)),
+		attribute.Int64("quantity", quantity),
 	)
 
 	query := `
 		UPDATE products
 		SET stock_quantity = stock_quantity + $1, updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND tenant_id = $3
+		RETURNING stock_quantity
 	`
-	commandTag, err := tx.Exec(ctx, query, quantity, id)
-	if err != nil {
+
+	var stock int64
+	if err := tx.QueryRow(ctx, query, quantity, id, tenant.FromContext(ctx)).Scan(&stock); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			mylogger.Warn(ctx, r.logger, "Product not found", zap.Int64("product_id", id))
+			return ErrProductNotFound
+		}
+
 		span.RecordError(err)
 		mylogger.Warn(ctx, r.logger, "Failed to update stock_quantity", zap.Error(err))
 
 		return err
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		mylogger.Warn(ctx, r.logger, "Product not found", zap.Int64("product_id", id))
-		return ErrProductNotFound
-	}
-
-	return nil
+	return r.recordRevision(ctx, tx, id, domain.RevisionStockChanged, stockChange(stock-quantity, stock))
 }
 
-func (r *productRepo) DecreaseStock(ctx context.Context, tx pgx.Tx, id, quantity int64) (int64, error) {
+func (r *productRepo) DecreaseStock(ctx context.Context, tx pgx.Tx, id, quantity int64) (price, stock int64, err error) {
 	if id <= 0 || quantity <= 0 {
-		return 0, ErrInvalidInput
+		return 0, 0, ErrInvalidInput
 	}
 
 	ctx, span := r.tracer.Start(ctx, "ProductRepository.DecreaseStock")
@@ -91,11 +113,10 @@ func (r *productRepo) DecreaseStock(ctx context.Context, tx pgx.Tx, id, quantity
 	productPriceQuery := `
 		SELECT price
 		FROM products
-		WHERE id = $1
+		WHERE id = $1 AND tenant_id = $2
 	`
 
-	var price int64
-	if err := tx.QueryRow(ctx, productPriceQuery, id).Scan(&price); err != nil {
+	if err := tx.QueryRow(ctx, productPriceQuery, id, tenant.FromContext(ctx)).Scan(&price); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			mylogger.Error(
 				ctx,
@@ -104,7 +125,7 @@ func (r *productRepo) DecreaseStock(ctx context.Context, tx pgx.Tx, id, quantity
 				zap.Int64("product_id", id),
 			)
 
-			return 0, ErrProductNotFound
+			return 0, 0, ErrProductNotFound
 		}
 		mylogger.Error(
 			ctx,
@@ -114,19 +135,24 @@ func (r *productRepo) DecreaseStock(ctx context.Context, tx pgx.Tx, id, quantity
 			zap.Error(err),
 		)
 
-		return 0, err
+		return 0, 0, err
 	}
 
 	query := `
 		UPDATE products
 		SET stock_quantity = stock_quantity - $2, updated_at = NOW()
 		WHERE id = $1
+			AND tenant_id = $3
 			AND stock_quantity >= $2
-			AND deleted_at IS NULL;
+			AND deleted_at IS NULL
+		RETURNING stock_quantity;
 	`
 
-	commandTag, err := tx.Exec(ctx, query, id, quantity)
-	if err != nil {
+	if err := tx.QueryRow(ctx, query, id, quantity, tenant.FromContext(ctx)).Scan(&stock); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, 0, ErrInsufficientStock
+		}
+
 		span.RecordError(err)
 
 		mylogger.Error(
@@ -137,14 +163,68 @@ func (r *productRepo) DecreaseStock(ctx context.Context, tx pgx.Tx, id, quantity
 			zap.Int64("quantity", quantity),
 		)
 
-		return 0, fmt.Errorf("error decreasing stock for product %d: %w", id, err)
+		return 0, 0, fmt.Errorf("error decreasing stock for product %d: %w", id, err)
+	}
+
+	if err := r.recordRevision(ctx, tx, id, domain.RevisionStockChanged, stockChange(stock+quantity, stock)); err != nil {
+		return 0, 0, err
+	}
+
+	return price, stock, nil
+}
+
+// AdjustStock applies a manual adjustment to the product total and records
+// it, with its reason, in the product history. It returns the new total.
+func (r *productRepo) AdjustStock(ctx context.Context, tx pgx.Tx, id int64, adjustment *domain.StockAdjustment) (int64, error) {
+	if id <= 0 || adjustment.Delta == 0 {
+		return 0, ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.AdjustStock")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("id", id),
+		attribute.Int64("delta", adjustment.Delta),
+		attribute.String("reason", string(adjustment.Reason)),
+	)
+
+	query := `
+		UPDATE products
+		SET stock_quantity = stock_quantity + $1, updated_at = NOW()
+		WHERE id = $2
+			AND tenant_id = $3
+			AND stock_quantity + $1 >= 0
+		RETURNING stock_quantity
+	`
+
+	var stock int64
+	if err := tx.QueryRow(ctx, query, adjustment.Delta, id, tenant.FromContext(ctx)).Scan(&stock); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			if adjustment.Delta < 0 {
+				return 0, ErrInsufficientStock
+			}
+
+			return 0, ErrProductNotFound
+		}
+
+		span.RecordError(err)
+		mylogger.Error(ctx, r.logger, "Failed to adjust stock_quantity", zap.Int64("product_id", id), zap.Error(err))
+
+		return 0, fmt.Errorf("error adjusting stock for product %d: %w", id, err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return 0, ErrInsufficientStock
+	changes := stockChange(stock-adjustment.Delta, stock)
+	changes["reason"] = domain.FieldChange{To: adjustment.Reason}
+	if adjustment.Note != "" {
+		changes["note"] = domain.FieldChange{To: adjustment.Note}
 	}
 
-	return price, nil
+	if err := r.recordRevision(ctx, tx, id, domain.RevisionStockAdjusted, changes); err != nil {
+		return 0, err
+	}
+
+	return stock, nil
 }
 
 func (r *productRepo) Update(ctx context.Context, id int64, input *domain.UpdateProductInput) error {
@@ -208,28 +288,42 @@ func (r *productRepo) Update(ctx context.Context, id int64, input *domain.Update
 	updates = append(updates, "updated_at = NOW()")
 
 	query += strings.Join(updates, ", ")
-	query += fmt.Sprintf("WHERE id = $%d AND deleted_at IS NULL", argId)
-	args = append(args, id)
+	query += fmt.Sprintf(" WHERE id = $%d AND tenant_id = $%d AND deleted_at IS NULL", argId, argId+1)
+	args = append(args, id, tenant.FromContext(ctx))
 
-	commandTag, err := r.pool.Exec(ctx, query, args...)
-	if err != nil {
-		span.RecordError(err)
+	return db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		row, err := r.q.WithTx(tx).GetProductForUpdate(ctx, sqlc.GetProductForUpdateParams{ID: id, TenantID: tenant.FromContext(ctx)})
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrProductNotFound
+			}
 
-		mylogger.Error(
-			ctx,
-			r.logger,
-			"Failed to Update product",
-			zap.Int64("id", id),
-		)
+			return fmt.Errorf("error locking product: %w", err)
+		}
 
-		return fmt.Errorf("error updating product: %w", err)
-	}
+		before, err := productFromRow(row)
+		if err != nil {
+			return err
+		}
 
-	if commandTag.RowsAffected() == 0 {
-		return ErrProductNotFound
-	}
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			span.RecordError(err)
+
+			mylogger.Error(
+				ctx,
+				r.logger,
+				"Failed to Update product",
+				zap.Int64("id", id),
+			)
+
+			return fmt.Errorf("error updating product: %w", err)
+		}
+
+		after := *before
+		input.ApplyTo(&after)
 
-	return nil
+		return r.recordRevision(ctx, tx, id, domain.RevisionUpdated, domain.Diff(before, &after))
+	})
 }
 
 func (r *productRepo) DeleteByID(ctx context.Context, id int64) error {
@@ -247,30 +341,123 @@ func (r *productRepo) DeleteByID(ctx context.Context, id int64) error {
 	query := `
 		UPDATE products
 		SET deleted_at = NOW()
-		WHERE id = $1 AND deleted_at IS NULL
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
 	`
 
-	commandTag, err := r.pool.Exec(ctx, query, id)
+	return db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		commandTag, err := tx.Exec(ctx, query, id, tenant.FromContext(ctx))
+		if err != nil {
+			span.RecordError(err)
+
+			mylogger.Error(
+				ctx,
+				r.logger,
+				"Error deleting product by id",
+				zap.Int64("id", id),
+				zap.Error(err),
+			)
+
+			return err
+		}
+
+		if commandTag.RowsAffected() == 0 {
+			return ErrProductNotFound
+		}
+
+		return r.recordRevision(ctx, tx, id, domain.RevisionDeleted, nil)
+	})
+}
+
+// RestoreByID undoes a soft delete. It fails with ErrProductAlreadyExists or
+// ErrSKUAlreadyExists when an active product took the name, SKU or EAN since.
+func (r *productRepo) RestoreByID(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.RestoreByID")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("id", id),
+	)
+
+	return db.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		affected, err := r.q.WithTx(tx).RestoreProduct(ctx, sqlc.RestoreProductParams{ID: id, TenantID: tenant.FromContext(ctx)})
+		if err != nil {
+			var pgError *pgconn.PgError
+			if errors.As(err, &pgError) && pgError.Code == "23505" {
+				mylogger.Warn(ctx, r.logger, "Restored product collides with an active one", zap.Int64("id", id), zap.String("constraint", pgError.ConstraintName))
 
+				if pgError.ConstraintName == "idx_products_sku" || pgError.ConstraintName == "idx_products_ean" {
+					return ErrSKUAlreadyExists
+				}
+
+				return ErrProductAlreadyExists
+			}
+
+			span.RecordError(err)
+
+			mylogger.Error(
+				ctx,
+				r.logger,
+				"Error restoring product by id",
+				zap.Int64("id", id),
+				zap.Error(err),
+			)
+
+			return fmt.Errorf("error restoring product: %w", err)
+		}
+
+		if affected == 0 {
+			return ErrProductNotFound
+		}
+
+		return r.recordRevision(ctx, tx, id, domain.RevisionRestored, nil)
+	})
+}
+
+func (r *productRepo) ListDeleted(ctx context.Context, page query.Page) ([]domain.Product, int64, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.ListDeleted")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("limit", page.Limit),
+		attribute.Int64("offset", page.Offset),
+	)
+
+	rows, err := r.q.ListDeletedProducts(ctx, sqlc.ListDeletedProductsParams{
+		Limit:    int32(page.Limit),
+		Offset:   int32(page.Offset),
+		TenantID: tenant.FromContext(ctx),
+	})
 	if err != nil {
 		span.RecordError(err)
 
 		mylogger.Error(
 			ctx,
 			r.logger,
-			"Error deleting product by id",
-			zap.Int64("id", id),
+			"Error listing deleted products",
 			zap.Error(err),
 		)
 
-		return err
+		return nil, 0, fmt.Errorf("error listing deleted products: %w", err)
 	}
 
-	if commandTag.RowsAffected() == 0 {
-		return ErrProductNotFound
+	products := make([]domain.Product, 0, len(rows))
+	var totalCount int64
+	for _, row := range rows {
+		p, err := productFromRow(row.Product)
+		if err != nil {
+			span.RecordError(err)
+			return nil, 0, err
+		}
+
+		products = append(products, *p)
+		totalCount = row.TotalCount
 	}
 
-	return nil
+	return products, totalCount, nil
 }
 
 func (r *productRepo) Create(ctx context.Context, tx pgx.Tx, product *domain.Product) (int64, error) {
@@ -281,9 +468,14 @@ func (r *productRepo) Create(ctx context.Context, tx pgx.Tx, product *domain.Pro
 		attribute.String("name", product.Name),
 	)
 
+	attributes := product.Attributes
+	if attributes == nil {
+		attributes = map[string]string{}
+	}
+
 	query := `
-		INSERT INTO products (name, description, price, stock_quantity, image_url, category)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO products (name, description, price, stock_quantity, image_url, category, sku, ean, attributes, tenant_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10)
 		RETURNING id;
 	`
 
@@ -296,10 +488,20 @@ func (r *productRepo) Create(ctx context.Context, tx pgx.Tx, product *domain.Pro
 		product.StockQuantity,
 		product.ImageUrl,
 		product.Category,
+		product.SKU,
+		product.EAN,
+		attributes,
+		tenant.FromContext(ctx),
 	).Scan(&product.ID)
 	if err != nil {
 		var pgError *pgconn.PgError
 		if errors.As(err, &pgError) {
+			if pgError.Code == "23505" && (pgError.ConstraintName == "idx_products_sku" || pgError.ConstraintName == "idx_products_ean") {
+				mylogger.Warn(ctx, r.logger, "Product sku already exists", zap.String("sku", product.SKU))
+
+				return 0, ErrSKUAlreadyExists
+			}
+
 			if pgError.Code == "23505" {
 				mylogger.Warn(ctx, r.logger, "Product already exists", zap.String("product_name", product.Name))
 
@@ -319,6 +521,10 @@ func (r *productRepo) Create(ctx context.Context, tx pgx.Tx, product *domain.Pro
 		return 0, fmt.Errorf("error creating product: %w", err)
 	}
 
+	if err := r.recordRevision(ctx, tx, product.ID, domain.RevisionCreated, domain.Diff(nil, product)); err != nil {
+		return 0, err
+	}
+
 	return product.ID, nil
 }
 
@@ -334,19 +540,8 @@ func (r *productRepo) GetByID(ctx context.Context, id int64) (*domain.Product, e
 		attribute.Int64("id", id),
 	)
 
-	query := `
-		SELECT id, name, description, price, stock_quantity,
-		image_url, category, created_at, updated_at
-		FROM products
-		WHERE id = $1 and deleted_at IS NULL;
-	`
-
-	var res domain.Product
-	if err := r.pool.QueryRow(ctx, query, id).
-		Scan(&res.ID, &res.Name, &res.Description, &res.Price,
-			&res.StockQuantity, &res.ImageUrl, &res.Category,
-			&res.CreatedAt, &res.UpdatedAt,
-		); err != nil {
+	row, err := r.q.GetProductByID(ctx, sqlc.GetProductByIDParams{ID: id, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrProductNotFound
 		}
@@ -364,42 +559,43 @@ func (r *productRepo) GetByID(ctx context.Context, id int64) (*domain.Product, e
 		return nil, fmt.Errorf("error getting product: %w", err)
 	}
 
-	return &res, nil
+	return productFromRow(row)
 }
 
-func (r *productRepo) List(ctx context.Context, limit, offset int64, search string) ([]domain.Product, int64, error) {
+func (r *productRepo) List(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, int64, error) {
 	ctx, span := r.tracer.Start(ctx, "ProductRepository.List")
 	defer span.End()
 
 	span.SetAttributes(
-		attribute.Int64("limit", limit),
-		attribute.Int64("offset", offset),
-		attribute.String("search", search),
+		attribute.Int64("limit", filter.Limit),
+		attribute.Int64("offset", filter.Offset),
+		attribute.String("search", filter.Search),
+		attribute.StringSlice("categories", filter.Categories),
+		attribute.String("sort", string(filter.Sort)),
 	)
 
-	products := make([]domain.Product, 0, limit)
+	products := make([]domain.Product, 0, filter.Limit)
 	var totalCount int64
 
-	baseQuery := `SELECT id, name, description, price, stock_quantity,
-		image_url, category, created_at, updated_at,
-		COUNT(*) OVER() as total_count,
-		FROM products
-		WHERE deleted_at IS NULL`
+	where, err := productListFilter(tenant.FromContext(ctx), filter)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	var args []interface{}
-	argId := 1
+	var b query.Builder
+	baseQuery := `SELECT id, name, description, price, stock_quantity,
+		image_url, category, COALESCE(sku, ''), COALESCE(ean, ''), attributes, rating,
+		created_at, updated_at,
+		COUNT(*) OVER() as total_count
+		FROM products` + b.Where(where)
 
-	if search != "" {
-		filter := fmt.Sprintf(" AND name ILIKE $%d", argId)
-		baseQuery += filter
-		args = append(args, "%"+search+"%")
-		argId++
+	orderBy, err := productSortFields.OrderBy([]query.Sort{productSort(filter.Sort)}, query.Sort{}, "id")
+	if err != nil {
+		return nil, 0, ErrInvalidInput
 	}
+	baseQuery += orderBy + b.Page(query.Page{Limit: filter.Limit, Offset: filter.Offset})
 
-	baseQuery += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d OFFSET $%d", argId, argId+1)
-	args = append(args, limit, offset)
-
-	rows, err := r.pool.Query(ctx, baseQuery, args...)
+	rows, err := r.pool.Query(ctx, baseQuery, b.Args()...)
 	if err != nil {
 		span.RecordError(err)
 
@@ -407,9 +603,9 @@ func (r *productRepo) List(ctx context.Context, limit, offset int64, search stri
 			ctx,
 			r.logger,
 			"Error getting products",
-			zap.String("search", search),
-			zap.Int64("limit", limit),
-			zap.Int64("offset", offset),
+			zap.String("search", filter.Search),
+			zap.Int64("limit", filter.Limit),
+			zap.Int64("offset", filter.Offset),
 			zap.Error(err),
 		)
 
@@ -427,6 +623,10 @@ func (r *productRepo) List(ctx context.Context, limit, offset int64, search stri
 			&p.StockQuantity,
 			&p.ImageUrl,
 			&p.Category,
+			&p.SKU,
+			&p.EAN,
+			&p.Attributes,
+			&p.Rating,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 			&totalCount,
@@ -460,3 +660,137 @@ func (r *productRepo) List(ctx context.Context, limit, offset int64, search stri
 
 	return products, totalCount, nil
 }
+
+func productListFilter(tenantID string, filter domain.ProductFilter) (query.Expr, error) {
+	conditions := []query.Expr{
+		query.Eq("tenant_id", tenantID),
+		query.Cond("deleted_at IS NULL"),
+		query.When(filter.Search != "", query.Or(
+			query.Contains("name", filter.Search),
+			query.Eq("sku", strings.ToUpper(strings.TrimSpace(filter.Search))),
+			query.Eq("ean", strings.ToUpper(strings.TrimSpace(filter.Search))),
+		)),
+		query.When(len(filter.Categories) > 0, query.In("category", filter.Categories)),
+		query.When(filter.PriceMin > 0, query.Gte("price", filter.PriceMin)),
+		query.When(filter.PriceMax > 0, query.Lte("price", filter.PriceMax)),
+		query.When(filter.InStockOnly, query.Cond("stock_quantity > 0")),
+		query.When(filter.StockMax > 0, query.Lte("stock_quantity", filter.StockMax)),
+	}
+
+	keys := make([]string, 0, len(filter.Attributes))
+	for key := range filter.Attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		values := filter.Attributes[key]
+
+		anyOf := make([]query.Expr, 0, len(values))
+		for _, value := range values {
+			document, err := json.Marshal(map[string]string{key: value})
+			if err != nil {
+				return nil, ErrInvalidInput
+			}
+
+			anyOf = append(anyOf, query.JSONContains("attributes", string(document)))
+		}
+
+		conditions = append(conditions, query.Or(anyOf...))
+	}
+
+	return query.And(conditions...), nil
+}
+
+var productSortFields = query.SortFields{
+	"created_at": "created_at",
+	"price":      "price",
+	"rating":     "rating",
+	"stock":      "stock_quantity",
+}
+
+// productSort maps a sort option onto the allowlisted fields; the id
+// tie-breaker added by OrderBy keeps pages stable.
+func productSort(sort domain.ProductSort) query.Sort {
+	switch sort {
+	case domain.SortPriceAsc:
+		return query.Sort{Field: "price"}
+	case domain.SortPriceDesc:
+		return query.Sort{Field: "price", Desc: true}
+	case domain.SortRating:
+		return query.Sort{Field: "rating", Desc: true}
+	case domain.SortStockAsc:
+		return query.Sort{Field: "stock"}
+	default:
+		return query.Sort{Field: "created_at", Desc: true}
+	}
+}
+
+func (r *productRepo) GetBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	if sku == "" {
+		return nil, ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.GetBySKU")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("sku", sku),
+	)
+
+	row, err := r.q.GetProductBySKU(ctx, sqlc.GetProductBySKUParams{Sku: &sku, TenantID: tenant.FromContext(ctx)})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrProductNotFound
+		}
+
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Error get by sku",
+			zap.String("sku", sku),
+			zap.Error(err),
+		)
+
+		return nil, fmt.Errorf("error getting product: %w", err)
+	}
+
+	return productFromRow(row)
+}
+
+func productFromRow(row sqlc.Product) (*domain.Product, error) {
+	p := &domain.Product{
+		ID:            row.ID,
+		Name:          row.Name,
+		Description:   row.Description,
+		Price:         row.Price,
+		StockQuantity: int64(row.StockQuantity),
+		ImageUrl:      deref(row.ImageUrl),
+		Category:      deref(row.Category),
+		SKU:           deref(row.Sku),
+		EAN:           deref(row.Ean),
+		Rating:        float64(row.Rating),
+		CreatedAt:     row.CreatedAt,
+		UpdatedAt:     row.UpdatedAt,
+	}
+
+	if row.DeletedAt != nil {
+		p.DeletedAt = *row.DeletedAt
+	}
+
+	if err := json.Unmarshal(row.Attributes, &p.Attributes); err != nil {
+		return nil, fmt.Errorf("error decoding product attributes: %w", err)
+	}
+
+	return p, nil
+}
+
+func deref[T any](v *T) T {
+	if v == nil {
+		return *new(T)
+	}
+
+	return *v
+}
@@ -0,0 +1,10 @@
+-- name: InsertProductRevision :exec
+INSERT INTO product_revisions (product_id, action, actor, changes)
+VALUES ($1, $2, $3, $4);
+
+-- name: ListProductRevisions :many
+SELECT id, product_id, action, actor, changes, created_at
+FROM product_revisions
+WHERE product_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
@@ -0,0 +1,30 @@
+-- name: GetProductByID :one
+SELECT id, name, description, price, stock_quantity, image_url, category,
+       created_at, updated_at, deleted_at, sku, ean, attributes, rating, tenant_id
+FROM products
+WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL;
+
+-- name: GetProductBySKU :one
+SELECT id, name, description, price, stock_quantity, image_url, category,
+       created_at, updated_at, deleted_at, sku, ean, attributes, rating, tenant_id
+FROM products
+WHERE sku = $1 AND tenant_id = $2 AND deleted_at IS NULL;
+
+-- name: RestoreProduct :execrows
+UPDATE products
+SET deleted_at = NULL, updated_at = NOW()
+WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL;
+
+-- name: ListDeletedProducts :many
+SELECT sqlc.embed(products), COUNT(*) OVER() AS total_count
+FROM products
+WHERE tenant_id = $3 AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT $1 OFFSET $2;
+
+-- name: GetProductForUpdate :one
+SELECT id, name, description, price, stock_quantity, image_url, category,
+       created_at, updated_at, deleted_at, sku, ean, attributes, rating, tenant_id
+FROM products
+WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL
+FOR UPDATE;
@@ -0,0 +1,58 @@
+-- name: CreateWarehouse :one
+INSERT INTO warehouses (code, name, latitude, longitude, priority)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, code, name, latitude, longitude, priority, is_active, created_at;
+
+-- name: ListWarehouses :many
+SELECT id, code, name, latitude, longitude, priority, is_active, created_at
+FROM warehouses
+ORDER BY priority DESC, id;
+
+-- name: GetDefaultWarehouse :one
+SELECT id, code, name, latitude, longitude, priority, is_active, created_at
+FROM warehouses
+WHERE is_active
+ORDER BY priority DESC, id
+LIMIT 1;
+
+-- name: LockProductStock :many
+SELECT ws.warehouse_id, w.code, w.latitude, w.longitude, w.priority, ws.quantity
+FROM warehouse_stock ws
+JOIN warehouses w ON w.id = ws.warehouse_id
+WHERE ws.product_id = $1 AND w.is_active
+ORDER BY ws.warehouse_id
+FOR UPDATE OF ws;
+
+-- name: ListProductStock :many
+SELECT ws.warehouse_id, w.code, w.latitude, w.longitude, w.priority, ws.quantity
+FROM warehouse_stock ws
+JOIN warehouses w ON w.id = ws.warehouse_id
+WHERE ws.product_id = $1
+ORDER BY w.priority DESC, ws.warehouse_id;
+
+-- name: AddWarehouseStock :exec
+INSERT INTO warehouse_stock (warehouse_id, product_id, quantity)
+VALUES (@warehouse_id, @product_id, @quantity)
+ON CONFLICT (warehouse_id, product_id)
+DO UPDATE SET quantity = warehouse_stock.quantity + EXCLUDED.quantity, updated_at = NOW();
+
+-- name: TakeWarehouseStock :execrows
+UPDATE warehouse_stock
+SET quantity = quantity - @quantity, updated_at = NOW()
+WHERE warehouse_id = @warehouse_id
+  AND product_id = @product_id
+  AND quantity >= @quantity;
+
+-- name: InsertStockAllocation :exec
+INSERT INTO stock_allocations (order_id, product_id, warehouse_id, quantity)
+VALUES ($1, $2, $3, $4);
+
+-- name: InsertReservation :execrows
+INSERT INTO reservations (order_id, product_id, quantity)
+VALUES ($1, $2, $3)
+ON CONFLICT (order_id, product_id) DO NOTHING;
+
+-- name: DeleteStockAllocations :many
+DELETE FROM stock_allocations
+WHERE order_id = $1 AND product_id = $2
+RETURNING warehouse_id, quantity;
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+func (r *productRepo) RecordPurchases(ctx context.Context, tx pgx.Tx, orderID int64, productIDs []int64) error {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.RecordPurchases")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.Int("items", len(productIDs)),
+	)
+
+	query := `
+		INSERT INTO product_purchases (order_id, product_id)
+		SELECT $1, unnest($2::bigint[])
+		ON CONFLICT (order_id, product_id) DO NOTHING;
+	`
+
+	if _, err := tx.Exec(ctx, query, orderID, productIDs); err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to record purchases",
+			zap.Int64("order_id", orderID),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("failed to record purchases: %w", err)
+	}
+
+	return nil
+}
+
+// RebuildCopurchases recomputes product pair scores from scratch. The table
+// is swapped inside one transaction so readers never see a half-built set.
+func (r *productRepo) RebuildCopurchases(ctx context.Context, tx pgx.Tx) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.RebuildCopurchases")
+	defer span.End()
+
+	if _, err := tx.Exec(ctx, `DELETE FROM product_copurchases;`); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to clear copurchases: %w", err)
+	}
+
+	query := `
+		INSERT INTO product_copurchases (product_id, related_id, score)
+		SELECT a.product_id, b.product_id, COUNT(*)
+		FROM product_purchases a
+		JOIN product_purchases b
+			ON a.order_id = b.order_id AND a.product_id <> b.product_id
+		GROUP BY a.product_id, b.product_id;
+	`
+
+	tag, err := tx.Exec(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to rebuild copurchases",
+			zap.Error(err),
+		)
+
+		return 0, fmt.Errorf("failed to rebuild copurchases: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// GetRelated ranks co-purchased products first and pads the result with
+// products from the same category.
+func (r *productRepo) GetRelated(ctx context.Context, productID, limit int64) ([]domain.Product, error) {
+	if productID <= 0 || limit <= 0 {
+		return nil, ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.GetRelated")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("product_id", productID),
+		attribute.Int64("limit", limit),
+	)
+
+	query := `
+		WITH target AS (
+			SELECT category FROM products WHERE id = $1 AND tenant_id = $3
+		)
+		SELECT p.id, p.name, p.description, p.price, p.stock_quantity,
+		p.image_url, p.category, COALESCE(p.sku, ''), COALESCE(p.ean, ''), p.attributes, p.rating,
+		p.created_at, p.updated_at
+		FROM products p
+		CROSS JOIN target t
+		LEFT JOIN product_copurchases c
+			ON c.product_id = $1 AND c.related_id = p.id
+		WHERE p.id <> $1
+			AND p.tenant_id = $3
+			AND p.deleted_at IS NULL
+			AND (c.score IS NOT NULL OR p.category = t.category)
+		ORDER BY COALESCE(c.score, 0) DESC, (p.category = t.category) DESC, p.created_at DESC, p.id DESC
+		LIMIT $2;
+	`
+
+	rows, err := r.pool.Query(ctx, query, productID, limit, tenant.FromContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to get related products",
+			zap.Int64("product_id", productID),
+			zap.Error(err),
+		)
+
+		return nil, fmt.Errorf("failed to get related products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]domain.Product, 0, limit)
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(
+			&p.ID,
+			&p.Name,
+			&p.Description,
+			&p.Price,
+			&p.StockQuantity,
+			&p.ImageUrl,
+			&p.Category,
+			&p.SKU,
+			&p.EAN,
+			&p.Attributes,
+			&p.Rating,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+		); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("error scanning related product: %w", err)
+		}
+
+		products = append(products, p)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("rows iteration error: %w", err)
+	}
+
+	return products, nil
+}
@@ -5,6 +5,11 @@ import "errors"
 var (
 	ErrProductAlreadyExists = errors.New("product already exists")
 	ErrInsufficientStock    = errors.New("insufficient stock")
+	ErrAlreadyReserved      = errors.New("order already reserved this product")
 	ErrProductNotFound      = errors.New("product not found")
 	ErrInvalidInput         = errors.New("invalid input")
+	ErrSKUAlreadyExists     = errors.New("product with this sku or ean already exists")
+
+	ErrWarehouseNotFound      = errors.New("warehouse not found")
+	ErrWarehouseAlreadyExists = errors.New("warehouse with this code already exists")
 )
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository/sqlc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// recordRevision appends to product_revisions in the mutation's own
+// transaction, so history never disagrees with the product row.
+func (r *productRepo) recordRevision(ctx context.Context, db sqlc.DBTX, productID int64, action domain.RevisionAction, changes domain.Changes) error {
+	if changes == nil {
+		changes = domain.Changes{}
+	}
+
+	payload, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("error encoding revision: %w", err)
+	}
+
+	if err := sqlc.New(db).InsertProductRevision(ctx, sqlc.InsertProductRevisionParams{
+		ProductID: productID,
+		Action:    string(action),
+		Actor:     domain.ActorFromContext(ctx),
+		Changes:   payload,
+	}); err != nil {
+		mylogger.Error(
+			ctx,
+			r.logger,
+			"Failed to record product revision",
+			zap.Int64("product_id", productID),
+			zap.String("action", string(action)),
+			zap.Error(err),
+		)
+
+		return fmt.Errorf("error recording revision: %w", err)
+	}
+
+	return nil
+}
+
+func stockChange(from, to int64) domain.Changes {
+	return domain.Changes{"stock_quantity": {From: from, To: to}}
+}
+
+func (r *productRepo) GetHistory(ctx context.Context, productID int64, page query.Page) ([]domain.ProductRevision, error) {
+	ctx, span := r.tracer.Start(ctx, "ProductRepository.GetHistory")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("product_id", productID),
+		attribute.Int64("limit", page.Limit),
+		attribute.Int64("offset", page.Offset),
+	)
+
+	rows, err := r.q.ListProductRevisions(ctx, sqlc.ListProductRevisionsParams{
+		ProductID: productID,
+		Limit:     int32(page.Limit),
+		Offset:    int32(page.Offset),
+	})
+	if err != nil {
+		span.RecordError(err)
+
+		return nil, fmt.Errorf("error listing product revisions: %w", err)
+	}
+
+	revisions := make([]domain.ProductRevision, 0, len(rows))
+	for _, row := range rows {
+		var changes domain.Changes
+		if err := json.Unmarshal(row.Changes, &changes); err != nil {
+			span.RecordError(err)
+
+			return nil, fmt.Errorf("error decoding revision %d: %w", row.ID, err)
+		}
+
+		revisions = append(revisions, domain.ProductRevision{
+			ID:        row.ID,
+			ProductID: row.ProductID,
+			Action:    domain.RevisionAction(row.Action),
+			Actor:     row.Actor,
+			Changes:   changes,
+			CreatedAt: row.CreatedAt,
+		})
+	}
+
+	return revisions, nil
+}
@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository/sqlc"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// WarehouseRepository keeps per-warehouse quantities. products.stock_quantity
+// stays the total, so callers change both in the same transaction.
+type WarehouseRepository interface {
+	Create(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error)
+	List(ctx context.Context) ([]domain.Warehouse, error)
+	Default(ctx context.Context, tx pgx.Tx) (*domain.Warehouse, error)
+	ProductStock(ctx context.Context, productID int64) ([]domain.WarehouseStock, error)
+	LockProductStock(ctx context.Context, tx pgx.Tx, productID int64) ([]domain.WarehouseStock, error)
+	AddStock(ctx context.Context, tx pgx.Tx, warehouseID, productID, quantity int64) error
+	TakeStock(ctx context.Context, tx pgx.Tx, warehouseID, productID, quantity int64) error
+	RecordReservation(ctx context.Context, tx pgx.Tx, orderID, productID, quantity int64) error
+	SaveAllocations(ctx context.Context, tx pgx.Tx, orderID, productID int64, allocations []domain.Allocation) error
+	ReleaseAllocations(ctx context.Context, tx pgx.Tx, orderID, productID int64) ([]domain.Allocation, error)
+}
+
+type warehouseRepo struct {
+	q      *sqlc.Queries
+	tracer trace.Tracer
+	logger *zap.Logger
+}
+
+func NewWarehouseRepository(pool *pgxpool.Pool, logger *zap.Logger) WarehouseRepository {
+	return &warehouseRepo{
+		q:      sqlc.New(pool),
+		logger: logger,
+		tracer: otel.Tracer("contract/warehouse_repo"),
+	}
+}
+
+func (r *warehouseRepo) Create(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error) {
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.Create")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("code", warehouse.Code),
+	)
+
+	params := sqlc.CreateWarehouseParams{
+		Code:     warehouse.Code,
+		Name:     warehouse.Name,
+		Priority: warehouse.Priority,
+	}
+	if warehouse.Location != nil {
+		params.Latitude = &warehouse.Location.Latitude
+		params.Longitude = &warehouse.Location.Longitude
+	}
+
+	row, err := r.q.CreateWarehouse(ctx, params)
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23505" {
+			return nil, ErrWarehouseAlreadyExists
+		}
+
+		span.RecordError(err)
+		mylogger.Error(ctx, r.logger, "Failed to create warehouse", zap.String("code", warehouse.Code), zap.Error(err))
+
+		return nil, fmt.Errorf("error creating warehouse: %w", err)
+	}
+
+	return warehouseFromRow(row), nil
+}
+
+func (r *warehouseRepo) List(ctx context.Context) ([]domain.Warehouse, error) {
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.List")
+	defer span.End()
+
+	rows, err := r.q.ListWarehouses(ctx)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error listing warehouses: %w", err)
+	}
+
+	warehouses := make([]domain.Warehouse, 0, len(rows))
+	for _, row := range rows {
+		warehouses = append(warehouses, *warehouseFromRow(row))
+	}
+
+	return warehouses, nil
+}
+
+// Default is the active warehouse with the highest priority. New stock and
+// returns of orders reserved before allocations were recorded land there.
+func (r *warehouseRepo) Default(ctx context.Context, tx pgx.Tx) (*domain.Warehouse, error) {
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.Default")
+	defer span.End()
+
+	row, err := r.q.WithTx(tx).GetDefaultWarehouse(ctx)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWarehouseNotFound
+		}
+
+		span.RecordError(err)
+		return nil, fmt.Errorf("error getting default warehouse: %w", err)
+	}
+
+	return warehouseFromRow(row), nil
+}
+
+func (r *warehouseRepo) ProductStock(ctx context.Context, productID int64) ([]domain.WarehouseStock, error) {
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.ProductStock")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("product_id", productID))
+
+	rows, err := r.q.ListProductStock(ctx, productID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error listing product stock: %w", err)
+	}
+
+	stock := make([]domain.WarehouseStock, 0, len(rows))
+	for _, row := range rows {
+		stock = append(stock, domain.WarehouseStock{
+			WarehouseID:   row.WarehouseID,
+			WarehouseCode: row.Code,
+			Location:      location(row.Latitude, row.Longitude),
+			Priority:      row.Priority,
+			Quantity:      row.Quantity,
+		})
+	}
+
+	return stock, nil
+}
+
+// LockProductStock returns the product's stock in active warehouses, locked
+// until tx ends so concurrent reservations allocate one after another.
+func (r *warehouseRepo) LockProductStock(ctx context.Context, tx pgx.Tx, productID int64) ([]domain.WarehouseStock, error) {
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.LockProductStock")
+	defer span.End()
+
+	span.SetAttributes(attribute.Int64("product_id", productID))
+
+	rows, err := r.q.WithTx(tx).LockProductStock(ctx, productID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error locking product stock: %w", err)
+	}
+
+	stock := make([]domain.WarehouseStock, 0, len(rows))
+	for _, row := range rows {
+		stock = append(stock, domain.WarehouseStock{
+			WarehouseID:   row.WarehouseID,
+			WarehouseCode: row.Code,
+			Location:      location(row.Latitude, row.Longitude),
+			Priority:      row.Priority,
+			Quantity:      row.Quantity,
+		})
+	}
+
+	return stock, nil
+}
+
+func (r *warehouseRepo) AddStock(ctx context.Context, tx pgx.Tx, warehouseID, productID, quantity int64) error {
+	if warehouseID <= 0 || productID <= 0 || quantity <= 0 {
+		return ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.AddStock")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("warehouse_id", warehouseID),
+		attribute.Int64("product_id", productID),
+		attribute.Int64("quantity", quantity),
+	)
+
+	err := r.q.WithTx(tx).AddWarehouseStock(ctx, sqlc.AddWarehouseStockParams{
+		WarehouseID: warehouseID,
+		ProductID:   productID,
+		Quantity:    quantity,
+	})
+	if err != nil {
+		var pgError *pgconn.PgError
+		if errors.As(err, &pgError) && pgError.Code == "23503" {
+			if pgError.ConstraintName == "warehouse_stock_product_id_fkey" {
+				return ErrProductNotFound
+			}
+
+			return ErrWarehouseNotFound
+		}
+
+		span.RecordError(err)
+		return fmt.Errorf("error adding warehouse stock: %w", err)
+	}
+
+	return nil
+}
+
+func (r *warehouseRepo) TakeStock(ctx context.Context, tx pgx.Tx, warehouseID, productID, quantity int64) error {
+	if warehouseID <= 0 || productID <= 0 || quantity <= 0 {
+		return ErrInvalidInput
+	}
+
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.TakeStock")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("warehouse_id", warehouseID),
+		attribute.Int64("product_id", productID),
+		attribute.Int64("quantity", quantity),
+	)
+
+	affected, err := r.q.WithTx(tx).TakeWarehouseStock(ctx, sqlc.TakeWarehouseStockParams{
+		Quantity:    quantity,
+		WarehouseID: warehouseID,
+		ProductID:   productID,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("error taking warehouse stock: %w", err)
+	}
+
+	if affected == 0 {
+		return ErrInsufficientStock
+	}
+
+	return nil
+}
+
+// RecordReservation claims the product for the order before its stock is
+// taken, failing with ErrAlreadyReserved when the order has claimed it
+// before. A concurrent claim waits on the unique key until the first one
+// commits or rolls back.
+func (r *warehouseRepo) RecordReservation(ctx context.Context, tx pgx.Tx, orderID, productID, quantity int64) error {
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.RecordReservation")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.Int64("product_id", productID),
+	)
+
+	inserted, err := r.q.WithTx(tx).InsertReservation(ctx, sqlc.InsertReservationParams{
+		OrderID:   orderID,
+		ProductID: productID,
+		Quantity:  quantity,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("error recording reservation: %w", err)
+	}
+
+	if inserted == 0 {
+		return ErrAlreadyReserved
+	}
+
+	return nil
+}
+
+func (r *warehouseRepo) SaveAllocations(ctx context.Context, tx pgx.Tx, orderID, productID int64, allocations []domain.Allocation) error {
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.SaveAllocations")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.Int64("product_id", productID),
+	)
+
+	q := r.q.WithTx(tx)
+	for _, allocation := range allocations {
+		if err := q.InsertStockAllocation(ctx, sqlc.InsertStockAllocationParams{
+			OrderID:     orderID,
+			ProductID:   productID,
+			WarehouseID: allocation.WarehouseID,
+			Quantity:    allocation.Quantity,
+		}); err != nil {
+			span.RecordError(err)
+			return fmt.Errorf("error saving stock allocation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ReleaseAllocations forgets and returns where an order's units came from.
+func (r *warehouseRepo) ReleaseAllocations(ctx context.Context, tx pgx.Tx, orderID, productID int64) ([]domain.Allocation, error) {
+	ctx, span := r.tracer.Start(ctx, "WarehouseRepository.ReleaseAllocations")
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.Int64("order_id", orderID),
+		attribute.Int64("product_id", productID),
+	)
+
+	rows, err := r.q.WithTx(tx).DeleteStockAllocations(ctx, sqlc.DeleteStockAllocationsParams{
+		OrderID:   orderID,
+		ProductID: productID,
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("error releasing stock allocations: %w", err)
+	}
+
+	allocations := make([]domain.Allocation, 0, len(rows))
+	for _, row := range rows {
+		allocations = append(allocations, domain.Allocation{
+			WarehouseID: row.WarehouseID,
+			Quantity:    row.Quantity,
+		})
+	}
+
+	return allocations, nil
+}
+
+func warehouseFromRow(row sqlc.Warehouse) *domain.Warehouse {
+	return &domain.Warehouse{
+		ID:        row.ID,
+		Code:      row.Code,
+		Name:      row.Name,
+		Location:  location(row.Latitude, row.Longitude),
+		Priority:  row.Priority,
+		IsActive:  row.IsActive,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+func location(latitude, longitude *float64) *domain.Location {
+	if latitude == nil || longitude == nil {
+		return nil
+	}
+
+	return &domain.Location{Latitude: *latitude, Longitude: *longitude}
+}
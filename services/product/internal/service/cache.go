@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sony/gobreaker"
+)
+
+const (
+	defaultCacheOpTimeout = 50 * time.Millisecond
+	cacheBreakerCooldown  = 10 * time.Second
+)
+
+var (
+	cacheAvailable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "product_cache_available",
+		Help: "1 while the product cache is in use, 0 while requests bypass it.",
+	})
+	cacheOperations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "product_cache_operations_total",
+		Help: "Product cache operations by type and result (hit, miss, error, bypassed).",
+	}, []string{"op", "result"})
+)
+
+// RegisterCacheMetrics exposes the product cache metrics on reg.
+func RegisterCacheMetrics(reg prometheus.Registerer) error {
+	if err := reg.Register(cacheAvailable); err != nil {
+		return err
+	}
+
+	return reg.Register(cacheOperations)
+}
+
+// cache guards Redis with short timeouts and a circuit breaker. Once Redis
+// keeps failing, calls skip it entirely until the breaker lets a probe
+// through, so reads fall straight back to Postgres instead of waiting on a
+// timeout every time.
+//
+// Invalidations are skipped too while the breaker is open; the TTL bounds how
+// stale an entry can be when Redis comes back.
+//
+// Keys are prefixed with the tenant on ctx, so storefronts never read each
+// other's entries.
+type cache struct {
+	client  redis.UniversalClient
+	cb      *gobreaker.CircuitBreaker
+	timeout time.Duration
+}
+
+func newCache(client redis.UniversalClient, timeout time.Duration) *cache {
+	cacheAvailable.Set(1)
+
+	return &cache{
+		client:  client,
+		timeout: timeout,
+		cb: gobreaker.NewCircuitBreaker(gobreaker.Settings{
+			Name:        "product-cache",
+			MaxRequests: 1,
+			Timeout:     cacheBreakerCooldown,
+			ReadyToTrip: func(counts gobreaker.Counts) bool {
+				return counts.ConsecutiveFailures >= 5
+			},
+			IsSuccessful: func(err error) bool {
+				return err == nil || errors.Is(err, redis.Nil)
+			},
+			OnStateChange: func(_ string, _, to gobreaker.State) {
+				if to == gobreaker.StateOpen {
+					cacheAvailable.Set(0)
+				} else {
+					cacheAvailable.Set(1)
+				}
+			},
+		}),
+	}
+}
+
+func (c *cache) get(ctx context.Context, key string) ([]byte, bool) {
+	res, err := c.cb.Execute(func() (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+
+		return c.client.Get(ctx, scopedKey(ctx, key)).Bytes()
+	})
+
+	switch {
+	case err == nil:
+		cacheOperations.WithLabelValues("get", "hit").Inc()
+		return res.([]byte), true
+	case errors.Is(err, redis.Nil):
+		cacheOperations.WithLabelValues("get", "miss").Inc()
+	default:
+		cacheOperations.WithLabelValues("get", resultOf(err)).Inc()
+	}
+
+	return nil, false
+}
+
+func (c *cache) set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	_, err := c.cb.Execute(func() (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+
+		return nil, c.client.Set(ctx, scopedKey(ctx, key), value, ttl).Err()
+	})
+	if err != nil {
+		cacheOperations.WithLabelValues("set", resultOf(err)).Inc()
+	}
+}
+
+func (c *cache) del(ctx context.Context, keys ...string) {
+	_, err := c.cb.Execute(func() (any, error) {
+		ctx, cancel := context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+
+		scoped := make([]string, len(keys))
+		for i, key := range keys {
+			scoped[i] = scopedKey(ctx, key)
+		}
+
+		return nil, c.client.Del(ctx, scoped...).Err()
+	})
+	if err != nil {
+		cacheOperations.WithLabelValues("del", resultOf(err)).Inc()
+	}
+}
+
+func scopedKey(ctx context.Context, key string) string {
+	return tenant.FromContext(ctx) + ":" + key
+}
+
+func resultOf(err error) string {
+	if errors.Is(err, gobreaker.ErrOpenState) || errors.Is(err, gobreaker.ErrTooManyRequests) {
+		return "bypassed"
+	}
+
+	return "error"
+}
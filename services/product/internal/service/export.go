@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"go.uber.org/zap"
+)
+
+// exportPageSize products are read and sent at a time, so memory stays flat
+// however large the catalog is.
+const exportPageSize = 500
+
+// ExportProducts hands send one page of products at a time in id order.
+// An empty catalog sends nothing.
+func (s *productService) ExportProducts(ctx context.Context, filter domain.ProductExportFilter, send func([]domain.ProductExportRow) error) error {
+	var (
+		afterID  int64
+		exported int
+	)
+	for {
+		rows, err := s.productRepo.ExportProducts(ctx, filter, afterID, exportPageSize)
+		if err != nil {
+			return err
+		}
+
+		if len(rows) > 0 {
+			if err := send(rows); err != nil {
+				return err
+			}
+		}
+
+		exported += len(rows)
+		if len(rows) < exportPageSize {
+			break
+		}
+
+		afterID = rows[len(rows)-1].Product.ID
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Products exported",
+		zap.String("category", filter.Category),
+		zap.Bool("include_deleted", filter.IncludeDeleted),
+		zap.Int("products", exported),
+	)
+
+	return nil
+}
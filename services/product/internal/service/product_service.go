@@ -5,14 +5,17 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
 	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
 	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
 	"github.com/sakashimaa/go-pet-project/product/internal/domain"
 	"github.com/sakashimaa/go-pet-project/product/internal/repository"
 	"go.uber.org/zap"
@@ -21,145 +24,227 @@ import (
 type ProductService interface {
 	Create(ctx context.Context, product *domain.Product) (int64, error)
 	FindByID(ctx context.Context, id int64) (*domain.Product, error)
-	List(ctx context.Context, limit, offset int64, search string) ([]domain.Product, int64, error)
-	DecreaseStock(ctx context.Context, id, quantity int64) (string, error)
+	FindBySKU(ctx context.Context, sku string) (*domain.Product, error)
+	List(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, int64, error)
+	DecreaseStock(ctx context.Context, id, quantity int64) (int64, error)
 	Delete(ctx context.Context, id int64) error
+	Restore(ctx context.Context, id int64) (*domain.Product, error)
+	ListDeleted(ctx context.Context, page query.Page) ([]domain.Product, int64, error)
+	GetHistory(ctx context.Context, productID int64, page query.Page) ([]domain.ProductRevision, error)
 	ReserveProduct(ctx context.Context, event *domain.OrderCreatedEvent) error
 	ReturnStock(ctx context.Context, event *generalDomain.OrderCancelledEvent) error
+	RestockReturn(ctx context.Context, event *generalDomain.ReturnApprovedEvent) error
+	GetRelated(ctx context.Context, productID, limit int64) ([]domain.Product, error)
+	RebuildCopurchases(ctx context.Context) (int64, error)
+	RecordAffinity(ctx context.Context, event *generalDomain.OrderPaidEvent) error
+	GetPersonalized(ctx context.Context, userID, limit int64) ([]domain.Product, error)
+	CreateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error)
+	ListCampaigns(ctx context.Context, includeEnded bool, page query.Page) ([]domain.Campaign, error)
+	ListPromoted(ctx context.Context, limit int64) ([]domain.Product, error)
+	ActivateCampaigns(ctx context.Context) ([]domain.Campaign, error)
+	CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error)
+	ListWarehouses(ctx context.Context) ([]domain.Warehouse, error)
+	GetProductStock(ctx context.Context, productID int64) ([]domain.WarehouseStock, error)
+	TransferStock(ctx context.Context, productID, fromWarehouseID, toWarehouseID, quantity int64) error
+	AdjustStock(ctx context.Context, adjustment *domain.StockAdjustment) (int64, error)
+	ExportProducts(ctx context.Context, filter domain.ProductExportFilter, send func([]domain.ProductExportRow) error) error
 }
 
+const (
+	defaultRelatedLimit = 8
+	maxRelatedLimit     = 50
+
+	defaultPersonalizedLimit = 12
+	maxPersonalizedLimit     = 50
+)
+
 type productService struct {
-	productRepo repository.ProductRepository
-	outboxRepo  worker.OutboxRepository
-	pool        *pgxpool.Pool
-	logger      *zap.Logger
+	productRepo   repository.ProductRepository
+	warehouseRepo repository.WarehouseRepository
+	campaignRepo  repository.CampaignRepository
+	outboxRepo    worker.OutboxRepository
+	pool          *pgxpool.Pool
+	logger        *zap.Logger
+	strategy      domain.AllocationStrategy
+}
+
+type Option func(*productService)
+
+// WithAllocationStrategy selects how reservations are split across
+// warehouses. The default is domain.AllocateMostStocked.
+func WithAllocationStrategy(strategy domain.AllocationStrategy) Option {
+	return func(s *productService) {
+		s.strategy = strategy
+	}
 }
 
 func NewProductService(
 	productRepo repository.ProductRepository,
+	warehouseRepo repository.WarehouseRepository,
+	campaignRepo repository.CampaignRepository,
 	outboxRepo worker.OutboxRepository,
 	pool *pgxpool.Pool,
 	logger *zap.Logger,
+	opts ...Option,
 ) ProductService {
-	return &productService{
-		productRepo: productRepo,
-		outboxRepo:  outboxRepo,
-		pool:        pool,
-		logger:      logger,
+	s := &productService{
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+		campaignRepo:  campaignRepo,
+		outboxRepo:    outboxRepo,
+		pool:          pool,
+		logger:        logger,
+		strategy:      domain.AllocateMostStocked,
 	}
-}
-
-func (s *productService) ReturnStock(ctx context.Context, event *generalDomain.OrderCancelledEvent) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Failed to begin transaction",
-			zap.Error(err),
-		)
 
-		return fmt.Errorf("failed to begin transaction: %w", err)
+	for _, opt := range opts {
+		opt(s)
 	}
-	defer func() {
-		shutdownCtx := context.WithoutCancel(ctx)
-		if err := tx.Rollback(shutdownCtx); err != nil {
-			mylogger.Warn(shutdownCtx, s.logger, "Failed to rollback transaction", zap.Error(err))
-		}
-	}()
 
-	for _, item := range event.Items {
-		if err := s.productRepo.IncreaseStock(ctx, tx, item.ProductID, item.Quantity); err != nil {
-			mylogger.Warn(ctx,
-				s.logger,
-				"Failed to increase stock",
-				zap.Int64("product_id", item.ProductID),
-				zap.Int32("quantity", item.Quantity),
-			)
+	return s
+}
 
-			return err
-		}
-	}
+func (s *productService) ReturnStock(ctx context.Context, event *generalDomain.OrderCancelledEvent) error {
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		for _, item := range event.Items {
+			if err := s.returnStock(ctx, tx, event.OrderID, item.ProductID, int64(item.Quantity)); err != nil {
+				mylogger.Warn(ctx,
+					s.logger,
+					"Failed to increase stock",
+					zap.Int64("product_id", item.ProductID),
+					zap.Int32("quantity", item.Quantity),
+				)
 
-	if err := tx.Commit(ctx); err != nil {
-		mylogger.Error(ctx, s.logger, "Failed to commit transaction", zap.Error(err))
-		return err
-	}
+				return err
+			}
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func (s *productService) ReserveProduct(ctx context.Context, event *domain.OrderCreatedEvent) error {
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Failed to begin transaction",
-			zap.Error(err),
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var (
+			total       int64
+			reserved    []domain.ReservedItemEvent
+			unavailable []domain.OrderItemEvent
 		)
 
-		return err
-	}
-	defer func() {
-		cleanupCtx := context.WithoutCancel(ctx)
-		err := tx.Rollback(cleanupCtx)
+		// Items are charged the price they sell for when the order is
+		// reserved.
+		productIDs := make([]int64, 0, len(event.Items))
+		for _, item := range event.Items {
+			productIDs = append(productIDs, item.ProductID)
+		}
 
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			mylogger.Warn(
-				ctx,
-				s.logger,
-				"Error rolling back transaction",
-				zap.Error(err),
-				zap.String("method_name", "Register"),
-				zap.String("service", "auth_service"),
-			)
+		promotions, err := s.campaignRepo.Promotions(ctx, productIDs, time.Now())
+		if err != nil {
+			return err
 		}
-	}()
 
-	var total int64
-	for _, item := range event.Items {
-		price, err := s.productRepo.DecreaseStock(ctx, tx, item.ProductID, item.Quantity)
-		total += price * item.Quantity
+		for _, item := range event.Items {
+			price, _, err := s.takeStock(ctx, tx, event.OrderID, item.ProductID, item.Quantity, event.ShipTo)
+			if err != nil {
+				if errors.Is(err, repository.ErrInsufficientStock) {
+					mylogger.Warn(ctx, s.logger, "Insufficient stock", zap.Int64("product_id", item.ProductID))
 
-		if err != nil {
-			if errors.Is(err, repository.ErrInsufficientStock) {
-				mylogger.Warn(ctx, s.logger, "Insufficient stock", zap.Int64("product_id", item.ProductID))
+					unavailable = append(unavailable, item)
+					continue
+				}
+
+				mylogger.Warn(ctx, s.logger, "Error processing order created", zap.Error(err))
 				return err
 			}
 
-			mylogger.Warn(ctx, s.logger, "Error processing order created", zap.Error(err))
+			if item.PriceOverride != nil {
+				price = item.PriceOverride.Price
+			} else if promotion, ok := promotions[item.ProductID]; ok {
+				price = promotion.Apply(price)
+			}
+
+			total += price * item.Quantity
+			reserved = append(reserved, domain.ReservedItemEvent{
+				ProductID: item.ProductID,
+				Quantity:  item.Quantity,
+				Price:     price,
+			})
+		}
+
+		if len(reserved) == 0 {
+			return repository.ErrInsufficientStock
+		}
+
+		total -= min(event.Discount, total)
+		total += event.DeliveryFee
+
+		purchased := make([]int64, 0, len(reserved))
+		for _, item := range reserved {
+			purchased = append(purchased, item.ProductID)
+		}
+
+		if err := s.productRepo.RecordPurchases(ctx, tx, event.OrderID, purchased); err != nil {
 			return err
 		}
-	}
 
-	successEvent := domain.InventoryReservedEvent{
-		OrderID:    event.OrderID,
-		UserID:     event.UserID,
-		Amount:     total,
-		ReservedAt: time.Now(),
-	}
+		var (
+			topic     = "payment_events"
+			eventType = "InventoryReserved"
+			payload   any
+		)
 
-	payloadMap := map[string]any{
-		"event":   "InventoryReserved",
-		"payload": successEvent,
-	}
-	payloadBytes, _ := json.Marshal(payloadMap)
+		if len(unavailable) == 0 {
+			payload = domain.InventoryReservedEvent{
+				OrderID:         event.OrderID,
+				UserID:          event.UserID,
+				Amount:          total,
+				ReservedAt:      time.Now(),
+				PaymentMethodID: event.PaymentMethodID,
+			}
+		} else {
+			// The order service asks the user whether to wait for the missing
+			// items or drop them, so payment is not triggered yet.
+			topic = "order_events"
+			eventType = "InventoryPartiallyReserved"
+			payload = domain.InventoryPartiallyReservedEvent{
+				OrderID:          event.OrderID,
+				UserID:           event.UserID,
+				Amount:           total,
+				ReservedItems:    reserved,
+				UnavailableItems: unavailable,
+				ReservedAt:       time.Now(),
+			}
+		}
 
-	outboxEvent := &outboxDomain.OutboxEvent{
-		Topic:         "payment_events",
-		AggregateType: "Inventory",
-		AggregateID:   fmt.Sprintf("%d", event.OrderID),
-		EventType:     "InventoryReserved",
-		Payload:       payloadBytes,
-	}
+		payloadMap := map[string]any{
+			"event":   eventType,
+			"payload": payload,
+		}
+		payloadBytes, _ := json.Marshal(payloadMap)
+
+		outboxEvent := &outboxDomain.OutboxEvent{
+			Topic:         topic,
+			AggregateType: "Inventory",
+			AggregateID:   fmt.Sprintf("%d", event.OrderID),
+			EventType:     eventType,
+			Payload:       payloadBytes,
+		}
 
-	if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
-		return fmt.Errorf("failed to save outbox event: %w", err)
-	}
+		if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
+			return fmt.Errorf("failed to save outbox event: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrAlreadyReserved) {
+			// The first delivery took the stock and queued the reply in the
+			// same transaction, so there is nothing left to do.
+			mylogger.Warn(ctx, s.logger, "Order already reserved, skipping redelivery", zap.Int64("order_id", event.OrderID))
+			return nil
+		}
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("failed to commit: %w", err)
+		return err
 	}
 
 	mylogger.Info(ctx, s.logger, "Product reserved successfully", zap.Int64("order_id", event.OrderID))
@@ -181,59 +266,113 @@ func (s *productService) Delete(ctx context.Context, id int64) error {
 	return nil
 }
 
-func (s *productService) DecreaseStock(ctx context.Context, id, quantity int64) (string, error) {
-	tx, err := s.pool.Begin(ctx)
+func (s *productService) Restore(ctx context.Context, id int64) (*domain.Product, error) {
+	if err := s.productRepo.RestoreByID(ctx, id); err != nil {
+		mylogger.Warn(ctx, s.logger, "error restoring product", zap.Int64("product_id", id), zap.Error(err))
+		return nil, err
+	}
+
+	mylogger.Info(ctx, s.logger, "Product restored", zap.Int64("product_id", id))
+
+	product, err := s.productRepo.GetByID(ctx, id)
 	if err != nil {
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Failed to begin transaction",
-			zap.Error(err),
-		)
+		return nil, err
+	}
 
-		return "", err
+	if err := s.applyPromotion(ctx, product); err != nil {
+		return nil, err
 	}
-	defer func() {
-		shutdownCtx := context.WithoutCancel(ctx)
-		if err := tx.Rollback(shutdownCtx); err != nil {
-			mylogger.Warn(
-				shutdownCtx,
-				s.logger,
-				"Failed to rollback transaction",
-				zap.Error(err),
-			)
+
+	return product, nil
+}
+
+const (
+	defaultDeletedLimit = 20
+	maxDeletedLimit     = 100
+)
+
+func (s *productService) ListDeleted(ctx context.Context, page query.Page) ([]domain.Product, int64, error) {
+	return s.productRepo.ListDeleted(ctx, page.Clamp(defaultDeletedLimit, maxDeletedLimit))
+}
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// GetHistory returns the product's revisions, newest first. It works for
+// soft-deleted products too, since that is when history matters most.
+func (s *productService) GetHistory(ctx context.Context, productID int64, page query.Page) ([]domain.ProductRevision, error) {
+	if productID <= 0 {
+		return nil, repository.ErrInvalidInput
+	}
+
+	return s.productRepo.GetHistory(ctx, productID, page.Clamp(defaultHistoryLimit, maxHistoryLimit))
+}
+
+// DecreaseStock takes units off sale outside of an order, e.g. sold in a
+// shop. Stock, history and a StockChanged event are committed together. It
+// returns the total left.
+func (s *productService) DecreaseStock(ctx context.Context, id, quantity int64) (int64, error) {
+	if id <= 0 || quantity <= 0 {
+		return 0, repository.ErrInvalidInput
+	}
+
+	var stock int64
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		if _, stock, err = s.takeStock(ctx, tx, 0, id, quantity, nil); err != nil {
+			return err
 		}
-	}()
 
-	_, err = s.productRepo.DecreaseStock(ctx, tx, id, quantity)
+		payloadBytes, err := json.Marshal(map[string]any{
+			"event": "StockChanged",
+			"payload": domain.StockChangedEvent{
+				ProductID:     id,
+				Delta:         -quantity,
+				StockQuantity: stock,
+				Actor:         domain.ActorFromContext(ctx),
+				ChangedAt:     time.Now(),
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("event payload marshal error: %w", err)
+		}
+
+		if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, &outboxDomain.OutboxEvent{
+			Topic:         "product_events",
+			AggregateType: "Product",
+			AggregateID:   fmt.Sprintf("%d", id),
+			EventType:     "StockChanged",
+			Payload:       payloadBytes,
+		}); err != nil {
+			return fmt.Errorf("failed to save outbox event: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
 		if errors.Is(err, repository.ErrInsufficientStock) {
-			s.logger.Warn("insufficient stock",
+			mylogger.Warn(
+				ctx,
+				s.logger,
+				"insufficient stock",
 				zap.Int64("product_id", id),
 				zap.Int64("quantity", quantity),
 			)
-			return "", err
+			return 0, err
 		}
 
-		s.logger.Error("error decreasing stock", zap.Error(err))
-		return "", err
-	}
-
-	if err := tx.Commit(ctx); err != nil {
-		mylogger.Warn(
-			ctx,
-			s.logger,
-			"Failed to commit transaction",
-			zap.Error(err),
-		)
-
-		return "", err
+		mylogger.Error(ctx, s.logger, "error decreasing stock", zap.Int64("product_id", id), zap.Error(err))
+		return 0, err
 	}
 
-	return "success", nil
+	return stock, nil
 }
 
 func (s *productService) Create(ctx context.Context, product *domain.Product) (int64, error) {
+	product.SKU = normalizeSKU(product.SKU)
+
 	if err := product.Validate(); err != nil {
 		mylogger.Warn(
 			ctx,
@@ -245,99 +384,201 @@ func (s *productService) Create(ctx context.Context, product *domain.Product) (i
 		return 0, err
 	}
 
-	tx, err := s.pool.Begin(ctx)
-	if err != nil {
-		mylogger.Error(ctx, s.logger, "Error starting transaction", zap.Error(err))
-		return 0, err
-	}
+	var id int64
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		id, err = s.productRepo.Create(ctx, tx, product)
+		if err != nil {
+			mylogger.Error(ctx, s.logger, "create error", zap.Error(err))
+			return fmt.Errorf("error creating product: %w", err)
+		}
 
-	defer func() {
-		cleanupCtx := context.WithoutCancel(ctx)
-		err := tx.Rollback(cleanupCtx)
+		if product.StockQuantity > 0 {
+			warehouse, err := s.warehouseRepo.Default(ctx, tx)
+			if err != nil {
+				return fmt.Errorf("error finding warehouse for initial stock: %w", err)
+			}
 
-		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			mylogger.Warn(
+			if err := s.warehouseRepo.AddStock(ctx, tx, warehouse.ID, id, product.StockQuantity); err != nil {
+				return fmt.Errorf("error stocking product: %w", err)
+			}
+		}
+
+		eventPayload := map[string]interface{}{
+			"product_id": id,
+			"event":      "ProductCreated",
+		}
+
+		payloadBytes, err := json.Marshal(eventPayload)
+		if err != nil {
+			return fmt.Errorf("event payload marshal error: %w", err)
+		}
+
+		outboxEvent := &outboxDomain.OutboxEvent{
+			AggregateType: "Product",
+			AggregateID:   fmt.Sprintf("%d", id),
+			EventType:     "ProductCreated",
+			Payload:       payloadBytes,
+			Topic:         "product_events",
+		}
+
+		if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
+			mylogger.Error(
 				ctx,
 				s.logger,
-				"Error rolling back transaction",
+				"Error saving outbox event",
 				zap.Error(err),
-				zap.String("method_name", "Register"),
-				zap.String("service", "auth_service"),
 			)
-		}
-	}()
 
-	id, err := s.productRepo.Create(ctx, tx, product)
+			return fmt.Errorf("failed to save outbox event: %w", err)
+		}
 
+		return nil
+	})
 	if err != nil {
-		mylogger.Error(ctx, s.logger, "create error", zap.Error(err))
-		return 0, fmt.Errorf("error creating product: %w", err)
+		return 0, err
 	}
 
-	eventPayload := map[string]interface{}{
-		"product_id": id,
-		"event":      "ProductCreated",
-	}
+	return id, nil
+}
 
-	payloadBytes, err := json.Marshal(eventPayload)
+func (s *productService) FindByID(ctx context.Context, id int64) (*domain.Product, error) {
+	res, err := s.productRepo.GetByID(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("event payload marshal error: %w", err)
-	}
+		if errors.Is(err, repository.ErrProductNotFound) {
+			s.logger.Warn("product not found", zap.Int64("product_id", id))
+			return nil, err
+		}
 
-	outboxEvent := &outboxDomain.OutboxEvent{
-		AggregateType: "Product",
-		AggregateID:   fmt.Sprintf("%d", id),
-		EventType:     "ProductCreated",
-		Payload:       payloadBytes,
-		Topic:         "product_events",
+		s.logger.Error("error getting product", zap.Error(err))
+		return nil, fmt.Errorf("error getting product by id: %w", err)
 	}
 
-	if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, outboxEvent); err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error saving outbox event",
-			zap.Error(err),
-		)
-
-		return 0, fmt.Errorf("failed to save outbox event: %w", err)
+	if err := s.applyPromotion(ctx, res); err != nil {
+		return nil, err
 	}
 
-	if err := tx.Commit(ctx); err != nil {
-		mylogger.Error(
-			ctx,
-			s.logger,
-			"Error commiting transaction",
-			zap.Error(err),
-		)
-
-		return 0, fmt.Errorf("failed to commit transaction: %w", err)
-	}
-
-	return id, nil
+	return res, nil
 }
 
-func (s *productService) FindByID(ctx context.Context, id int64) (*domain.Product, error) {
-	res, err := s.productRepo.GetByID(ctx, id)
+func (s *productService) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	res, err := s.productRepo.GetBySKU(ctx, normalizeSKU(sku))
 	if err != nil {
 		if errors.Is(err, repository.ErrProductNotFound) {
-			s.logger.Warn("product not found", zap.Int64("product_id", id))
+			mylogger.Warn(ctx, s.logger, "product not found", zap.String("sku", sku))
 			return nil, err
 		}
 
-		s.logger.Error("error getting product", zap.Error(err))
-		return nil, fmt.Errorf("error getting product by id: %w", err)
+		mylogger.Error(ctx, s.logger, "error getting product by sku", zap.Error(err))
+		return nil, fmt.Errorf("error getting product by sku: %w", err)
+	}
+
+	if err := s.applyPromotion(ctx, res); err != nil {
+		return nil, err
 	}
 
 	return res, nil
 }
 
-func (s *productService) List(ctx context.Context, limit, offset int64, search string) ([]domain.Product, int64, error) {
-	list, quantity, err := s.productRepo.List(ctx, limit, offset, search)
+// normalizeSKU keeps SKUs case-insensitive for warehouse integrations.
+func normalizeSKU(sku string) string {
+	return strings.ToUpper(strings.TrimSpace(sku))
+}
+
+func (s *productService) List(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, int64, error) {
+	if filter.PriceMin > 0 && filter.PriceMax > 0 && filter.PriceMin > filter.PriceMax {
+		return nil, 0, repository.ErrInvalidInput
+	}
+
+	list, quantity, err := s.productRepo.List(ctx, filter)
 	if err != nil {
 		s.logger.Error("list error", zap.Error(err))
 		return nil, 0, fmt.Errorf("error listing products: %w", err)
 	}
 
+	if err := s.applyPromotions(ctx, list); err != nil {
+		return nil, 0, err
+	}
+
 	return list, quantity, nil
 }
+
+func (s *productService) GetRelated(ctx context.Context, productID, limit int64) ([]domain.Product, error) {
+	if limit <= 0 {
+		limit = defaultRelatedLimit
+	}
+	if limit > maxRelatedLimit {
+		limit = maxRelatedLimit
+	}
+
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	related, err := s.productRepo.GetRelated(ctx, productID, limit)
+	if err != nil {
+		mylogger.Error(ctx, s.logger, "Failed to get related products", zap.Int64("product_id", productID), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.applyPromotions(ctx, related); err != nil {
+		return nil, err
+	}
+
+	return related, nil
+}
+
+// RecordAffinity counts the items of a paid order towards the categories
+// its user buys from. A redelivered event is counted once.
+func (s *productService) RecordAffinity(ctx context.Context, event *generalDomain.OrderPaidEvent) error {
+	items := make([]domain.OrderItemEvent, 0, len(event.Items))
+	for _, item := range event.Items {
+		items = append(items, domain.OrderItemEvent{
+			ProductID: item.ProductID,
+			Quantity:  int64(item.Quantity),
+		})
+	}
+
+	recorded, err := s.productRepo.RecordAffinity(ctx, event.OrderID, event.UserID, items)
+	if err != nil {
+		return err
+	}
+
+	if !recorded {
+		mylogger.Info(ctx, s.logger, "Order already counted towards affinity, skipping", zap.Int64("order_id", event.OrderID))
+	}
+
+	return nil
+}
+
+func (s *productService) GetPersonalized(ctx context.Context, userID, limit int64) ([]domain.Product, error) {
+	if limit <= 0 {
+		limit = defaultPersonalizedLimit
+	}
+	if limit > maxPersonalizedLimit {
+		limit = maxPersonalizedLimit
+	}
+
+	products, err := s.productRepo.GetPersonalized(ctx, userID, limit)
+	if err != nil {
+		mylogger.Error(ctx, s.logger, "Failed to get personalized products", zap.Int64("user_id", userID), zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.applyPromotions(ctx, products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (s *productService) RebuildCopurchases(ctx context.Context) (int64, error) {
+	var pairs int64
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		pairs, err = s.productRepo.RebuildCopurchases(ctx, tx)
+		return err
+	})
+
+	return pairs, err
+}
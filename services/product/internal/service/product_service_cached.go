@@ -8,23 +8,41 @@ import (
 
 	"github.com/redis/go-redis/v9"
 	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
 	"github.com/sakashimaa/go-pet-project/product/internal/domain"
 )
 
 type cachedProductService struct {
-	next        ProductService
-	redisClient *redis.Client
-	cacheTTL    time.Duration
+	next     ProductService
+	cache    *cache
+	cacheTTL time.Duration
 }
 
-func NewCachedProductService(next ProductService, redisClient *redis.Client) ProductService {
-	return &cachedProductService{
-		next:        next,
-		redisClient: redisClient,
-		cacheTTL:    time.Minute * 10,
+type CacheOption func(*cachedProductService)
+
+// WithCacheTimeout bounds each Redis call. Keep it well below the RPC
+// deadline: a slow cache must never cost more than skipping it.
+func WithCacheTimeout(timeout time.Duration) CacheOption {
+	return func(s *cachedProductService) {
+		s.cache.timeout = timeout
 	}
 }
 
+func NewCachedProductService(next ProductService, redisClient redis.UniversalClient, opts ...CacheOption) ProductService {
+	s := &cachedProductService{
+		next:     next,
+		cache:    newCache(redisClient, defaultCacheOpTimeout),
+		cacheTTL: time.Minute * 10,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
 func (s *cachedProductService) ReserveProduct(ctx context.Context, event *domain.OrderCreatedEvent) error {
 	return s.next.ReserveProduct(ctx, event)
 }
@@ -37,10 +55,28 @@ func (s *cachedProductService) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 
-	s.redisClient.Del(ctx, key)
+	s.cache.del(ctx, key)
 	return nil
 }
 
+func (s *cachedProductService) Restore(ctx context.Context, id int64) (*domain.Product, error) {
+	product, err := s.next.Restore(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.cache.del(ctx, fmt.Sprintf("product:%d", id))
+	return product, nil
+}
+
+func (s *cachedProductService) ListDeleted(ctx context.Context, page query.Page) ([]domain.Product, int64, error) {
+	return s.next.ListDeleted(ctx, page)
+}
+
+func (s *cachedProductService) GetHistory(ctx context.Context, productID int64, page query.Page) ([]domain.ProductRevision, error) {
+	return s.next.GetHistory(ctx, productID, page)
+}
+
 func (s *cachedProductService) Create(ctx context.Context, product *domain.Product) (int64, error) {
 	id, err := s.next.Create(ctx, product)
 	if err != nil {
@@ -49,7 +85,7 @@ func (s *cachedProductService) Create(ctx context.Context, product *domain.Produ
 
 	product.ID = id
 	if data, err := json.Marshal(product); err == nil {
-		s.redisClient.Set(ctx, fmt.Sprintf("product:%d", product.ID), data, s.cacheTTL)
+		s.cache.set(ctx, fmt.Sprintf("product:%d", product.ID), data, s.cacheTTL)
 	}
 
 	return id, nil
@@ -58,10 +94,9 @@ func (s *cachedProductService) Create(ctx context.Context, product *domain.Produ
 func (s *cachedProductService) FindByID(ctx context.Context, id int64) (*domain.Product, error) {
 	key := fmt.Sprintf("product:%d", id)
 
-	val, err := s.redisClient.Get(ctx, key).Result()
-	if err == nil {
+	if val, ok := s.cache.get(ctx, key); ok {
 		var product domain.Product
-		if err := json.Unmarshal([]byte(val), &product); err != nil {
+		if err := json.Unmarshal(val, &product); err == nil {
 			return &product, nil
 		}
 	}
@@ -71,28 +106,161 @@ func (s *cachedProductService) FindByID(ctx context.Context, id int64) (*domain.
 		return nil, err
 	}
 
-	if data, err := json.Marshal(product); err != nil {
-		s.redisClient.Set(ctx, key, data, s.cacheTTL)
+	if data, err := json.Marshal(product); err == nil {
+		s.cache.set(ctx, key, data, s.cacheTTL)
 	}
 
 	return product, nil
 }
 
-func (s *cachedProductService) List(ctx context.Context, limit, offset int64, search string) ([]domain.Product, int64, error) {
-	return s.next.List(ctx, limit, offset, search)
+func (s *cachedProductService) FindBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	return s.next.FindBySKU(ctx, sku)
 }
 
-func (s *cachedProductService) DecreaseStock(ctx context.Context, id, quantity int64) (string, error) {
+func (s *cachedProductService) List(ctx context.Context, filter domain.ProductFilter) ([]domain.Product, int64, error) {
+	return s.next.List(ctx, filter)
+}
+
+// DecreaseStock drops the cached product once the new stock is committed,
+// so readers never see the old quantity after the call returns.
+func (s *cachedProductService) DecreaseStock(ctx context.Context, id, quantity int64) (int64, error) {
 	res, err := s.next.DecreaseStock(ctx, id, quantity)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
 	key := fmt.Sprintf("product:%d", id)
-	s.redisClient.Del(ctx, key)
+	s.cache.del(ctx, key)
 	return res, nil
 }
 
 func (s *cachedProductService) ReturnStock(ctx context.Context, event *generalDomain.OrderCancelledEvent) error {
 	return s.next.ReturnStock(ctx, event)
 }
+
+func (s *cachedProductService) RestockReturn(ctx context.Context, event *generalDomain.ReturnApprovedEvent) error {
+	if err := s.next.RestockReturn(ctx, event); err != nil {
+		return err
+	}
+
+	for _, item := range event.Items {
+		s.cache.del(ctx, fmt.Sprintf("product:%d", item.ProductID))
+	}
+	return nil
+}
+
+func (s *cachedProductService) GetRelated(ctx context.Context, productID, limit int64) ([]domain.Product, error) {
+	key := fmt.Sprintf("product:%d:related:%d", productID, limit)
+
+	if val, ok := s.cache.get(ctx, key); ok {
+		var related []domain.Product
+		if err := json.Unmarshal(val, &related); err == nil {
+			return related, nil
+		}
+	}
+
+	related, err := s.next.GetRelated(ctx, productID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(related); err == nil {
+		s.cache.set(ctx, key, data, s.cacheTTL)
+	}
+
+	return related, nil
+}
+
+func (s *cachedProductService) RebuildCopurchases(ctx context.Context) (int64, error) {
+	return s.next.RebuildCopurchases(ctx)
+}
+
+func (s *cachedProductService) RecordAffinity(ctx context.Context, event *generalDomain.OrderPaidEvent) error {
+	return s.next.RecordAffinity(ctx, event)
+}
+
+// GetPersonalized is cached like GetRelated, so a purchase shows in the
+// user's ranking once the entry expires.
+func (s *cachedProductService) GetPersonalized(ctx context.Context, userID, limit int64) ([]domain.Product, error) {
+	key := fmt.Sprintf("user:%d:personalized:%d", userID, limit)
+
+	if val, ok := s.cache.get(ctx, key); ok {
+		var products []domain.Product
+		if err := json.Unmarshal(val, &products); err == nil {
+			return products, nil
+		}
+	}
+
+	products, err := s.next.GetPersonalized(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if data, err := json.Marshal(products); err == nil {
+		s.cache.set(ctx, key, data, s.cacheTTL)
+	}
+
+	return products, nil
+}
+
+func (s *cachedProductService) CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error) {
+	return s.next.CreateWarehouse(ctx, warehouse)
+}
+
+func (s *cachedProductService) ListWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	return s.next.ListWarehouses(ctx)
+}
+
+func (s *cachedProductService) GetProductStock(ctx context.Context, productID int64) ([]domain.WarehouseStock, error) {
+	return s.next.GetProductStock(ctx, productID)
+}
+
+// TransferStock leaves the cached product untouched: the total does not change.
+func (s *cachedProductService) TransferStock(ctx context.Context, productID, fromWarehouseID, toWarehouseID, quantity int64) error {
+	return s.next.TransferStock(ctx, productID, fromWarehouseID, toWarehouseID, quantity)
+}
+
+func (s *cachedProductService) AdjustStock(ctx context.Context, adjustment *domain.StockAdjustment) (int64, error) {
+	stock, err := s.next.AdjustStock(ctx, adjustment)
+	if err != nil {
+		return 0, err
+	}
+
+	s.cache.del(ctx, fmt.Sprintf("product:%d", adjustment.ProductID))
+	return stock, nil
+}
+
+// ExportProducts reads past the cache: an export has to show the catalog as
+// it is now.
+func (s *cachedProductService) ExportProducts(ctx context.Context, filter domain.ProductExportFilter, send func([]domain.ProductExportRow) error) error {
+	return s.next.ExportProducts(ctx, filter, send)
+}
+
+func (s *cachedProductService) CreateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error) {
+	return s.next.CreateCampaign(ctx, campaign)
+}
+
+func (s *cachedProductService) ListCampaigns(ctx context.Context, includeEnded bool, page query.Page) ([]domain.Campaign, error) {
+	return s.next.ListCampaigns(ctx, includeEnded, page)
+}
+
+func (s *cachedProductService) ListPromoted(ctx context.Context, limit int64) ([]domain.Product, error) {
+	return s.next.ListPromoted(ctx, limit)
+}
+
+// ActivateCampaigns drops the cached products of every campaign that started
+// or ended, so their price changes within one activator run.
+func (s *cachedProductService) ActivateCampaigns(ctx context.Context) ([]domain.Campaign, error) {
+	moved, err := s.next.ActivateCampaigns(ctx)
+
+	for _, campaign := range moved {
+		keys := make([]string, 0, len(campaign.ProductIDs))
+		for _, id := range campaign.ProductIDs {
+			keys = append(keys, fmt.Sprintf("product:%d", id))
+		}
+
+		s.cache.del(tenant.WithID(ctx, campaign.TenantID), keys...)
+	}
+
+	return moved, err
+}
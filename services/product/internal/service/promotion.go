@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultCampaignLimit = 20
+	maxCampaignLimit     = 100
+
+	defaultPromotedLimit = 12
+	maxPromotedLimit     = 50
+
+	// activateBatchSize bounds the campaigns one activator run moves on.
+	activateBatchSize = 100
+)
+
+// CreateCampaign schedules a campaign. It discounts its products from
+// StartsAt whether or not the activator has run by then.
+func (s *productService) CreateCampaign(ctx context.Context, campaign *domain.Campaign) (*domain.Campaign, error) {
+	slices.Sort(campaign.ProductIDs)
+	campaign.ProductIDs = slices.Compact(campaign.ProductIDs)
+
+	if err := campaign.Validate(); err != nil {
+		return nil, err
+	}
+
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		return s.campaignRepo.Create(ctx, tx, campaign)
+	})
+	if err != nil {
+		mylogger.Warn(ctx, s.logger, "Failed to create campaign", zap.String("name", campaign.Name), zap.Error(err))
+		return nil, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Campaign created",
+		zap.Int64("campaign_id", campaign.ID),
+		zap.Time("starts_at", campaign.StartsAt),
+		zap.Time("ends_at", campaign.EndsAt),
+	)
+
+	return campaign, nil
+}
+
+func (s *productService) ListCampaigns(ctx context.Context, includeEnded bool, page query.Page) ([]domain.Campaign, error) {
+	return s.campaignRepo.List(ctx, includeEnded, page.Clamp(defaultCampaignLimit, maxCampaignLimit))
+}
+
+// ListPromoted returns in-stock products on promotion right now, the
+// biggest discounts first.
+func (s *productService) ListPromoted(ctx context.Context, limit int64) ([]domain.Product, error) {
+	if limit <= 0 {
+		limit = defaultPromotedLimit
+	}
+	if limit > maxPromotedLimit {
+		limit = maxPromotedLimit
+	}
+
+	products, err := s.campaignRepo.ListPromoted(ctx, time.Now(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyPromotions(ctx, products); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+// ActivateCampaigns catches the status of every tenant's campaigns up with
+// their windows and emits ProductPromotionStarted and ProductPromotionEnded
+// for the ones that moved, which it returns. A campaign whose whole window
+// passed before a run only emits ProductPromotionEnded.
+func (s *productService) ActivateCampaigns(ctx context.Context) ([]domain.Campaign, error) {
+	now := time.Now()
+
+	due, err := s.campaignRepo.ListDue(ctx, now, activateBatchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		moved []domain.Campaign
+		errs  []error
+	)
+	for _, campaign := range due {
+		// The events belong to the campaign's tenant.
+		campaignCtx := tenant.WithID(ctx, campaign.TenantID)
+
+		ok, err := s.moveCampaign(campaignCtx, &campaign, campaign.NextStatus(now), now)
+		if err != nil {
+			mylogger.Warn(ctx, s.logger, "Failed to move campaign", zap.Int64("campaign_id", campaign.ID), zap.Error(err))
+			errs = append(errs, err)
+			continue
+		}
+
+		if ok {
+			moved = append(moved, campaign)
+		}
+	}
+
+	return moved, errors.Join(errs...)
+}
+
+func (s *productService) moveCampaign(ctx context.Context, campaign *domain.Campaign, to domain.CampaignStatus, now time.Time) (bool, error) {
+	var moved bool
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		ok, err := s.campaignRepo.SetStatus(ctx, tx, campaign.ID, campaign.Status, to)
+		if err != nil || !ok {
+			return err
+		}
+
+		var (
+			eventType string
+			payload   any
+		)
+		switch to {
+		case domain.CampaignActive:
+			eventType = "ProductPromotionStarted"
+			payload = domain.ProductPromotionStartedEvent{
+				CampaignID:      campaign.ID,
+				Name:            campaign.Name,
+				ProductIDs:      campaign.ProductIDs,
+				DiscountPercent: campaign.DiscountPercent,
+				StartsAt:        campaign.StartsAt,
+				EndsAt:          campaign.EndsAt,
+			}
+		case domain.CampaignEnded:
+			eventType = "ProductPromotionEnded"
+			payload = domain.ProductPromotionEndedEvent{
+				CampaignID: campaign.ID,
+				ProductIDs: campaign.ProductIDs,
+				EndedAt:    now,
+			}
+		default:
+			return fmt.Errorf("campaign %d cannot move to %s", campaign.ID, to)
+		}
+
+		payloadBytes, err := json.Marshal(map[string]any{
+			"event":   eventType,
+			"payload": payload,
+		})
+		if err != nil {
+			return fmt.Errorf("event payload marshal error: %w", err)
+		}
+
+		if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, &outboxDomain.OutboxEvent{
+			Topic:         "product_events",
+			AggregateType: "Campaign",
+			AggregateID:   fmt.Sprintf("%d", campaign.ID),
+			EventType:     eventType,
+			Payload:       payloadBytes,
+		}); err != nil {
+			return fmt.Errorf("failed to save outbox event: %w", err)
+		}
+
+		campaign.Status = to
+		moved = true
+
+		return nil
+	})
+
+	return moved, err
+}
+
+func (s *productService) applyPromotion(ctx context.Context, product *domain.Product) error {
+	products := []domain.Product{*product}
+	if err := s.applyPromotions(ctx, products); err != nil {
+		return err
+	}
+
+	product.Promotion = products[0].Promotion
+	return nil
+}
+
+// applyPromotions sets the promotion each of products is sold with now.
+func (s *productService) applyPromotions(ctx context.Context, products []domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	promotions, err := s.campaignRepo.Promotions(ctx, ids, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for i := range products {
+		if promotion, ok := promotions[products[i].ID]; ok {
+			products[i].Promotion = &promotion
+		}
+	}
+
+	return nil
+}
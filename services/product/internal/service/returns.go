@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+	"go.uber.org/zap"
+)
+
+// RestockReturn puts the items of an approved return back in the default
+// warehouse as customer_return adjustments, so they appear in the product
+// history and as StockAdjusted events like a return booked by hand. Products
+// deleted since the order are skipped.
+func (s *productService) RestockReturn(ctx context.Context, event *generalDomain.ReturnApprovedEvent) error {
+	note := fmt.Sprintf("Return #%d of order #%d", event.ReturnID, event.OrderID)
+	actor := domain.ActorFromContext(ctx)
+
+	return db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		for _, item := range event.Items {
+			adjustment := &domain.StockAdjustment{
+				ProductID: item.ProductID,
+				Delta:     int64(item.Quantity),
+				Reason:    domain.AdjustCustomerReturn,
+				Note:      note,
+			}
+			if err := adjustment.Validate(); err != nil {
+				mylogger.Warn(ctx, s.logger, "Invalid returned item", zap.Int64("return_id", event.ReturnID), zap.Error(err))
+				continue
+			}
+
+			if _, err := s.productRepo.GetByID(ctx, item.ProductID); err != nil {
+				if errors.Is(err, repository.ErrProductNotFound) {
+					mylogger.Warn(ctx, s.logger, "Returned product no longer exists", zap.Int64("product_id", item.ProductID))
+					continue
+				}
+				return err
+			}
+
+			if _, _, err := s.adjustStock(ctx, tx, adjustment, actor); err != nil {
+				return err
+			}
+		}
+
+		mylogger.Info(ctx, s.logger, "Return restocked", zap.Int64("return_id", event.ReturnID), zap.Int64("order_id", event.OrderID))
+
+		return nil
+	})
+}
@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+	"go.uber.org/zap"
+)
+
+var (
+	ErrStockWatchStopped = errors.New("stock watcher stopped")
+	// ErrStockWatchLagging ends a watch that fell so far behind that updates
+	// would have been dropped. The client reconnects and starts from a fresh
+	// snapshot.
+	ErrStockWatchLagging = errors.New("stock watch fell behind")
+)
+
+const (
+	// stockChannel is notified by the notify_stock_changes triggers.
+	stockChannel = "stock_changes"
+	// stockWatchBuffer updates may queue for one watch before it is ended as
+	// lagging.
+	stockWatchBuffer = 64
+	// stockListenRetryDelay is the wait before reconnecting a listener that
+	// lost its connection.
+	stockListenRetryDelay = 2 * time.Second
+)
+
+// stockChange is the payload of a stock_changes notification.
+type stockChange struct {
+	TenantID  string `json:"tenant_id"`
+	ProductID int64  `json:"product_id"`
+}
+
+type stockWatch struct {
+	updates chan domain.StockLevel
+	lagging chan struct{}
+	once    sync.Once
+}
+
+// StockWatcher streams stock levels to watches as they change. One Postgres
+// connection LISTENs for every change and each is fanned out to the watches
+// following that product, so watches cost no connections of their own.
+type StockWatcher struct {
+	pool          *pgxpool.Pool
+	productRepo   repository.ProductRepository
+	warehouseRepo repository.WarehouseRepository
+	logger        *zap.Logger
+
+	mu      sync.Mutex
+	watches map[stockChange]map[*stockWatch]struct{}
+	done    chan struct{}
+}
+
+func NewStockWatcher(
+	pool *pgxpool.Pool,
+	productRepo repository.ProductRepository,
+	warehouseRepo repository.WarehouseRepository,
+	logger *zap.Logger,
+) *StockWatcher {
+	return &StockWatcher{
+		pool:          pool,
+		productRepo:   productRepo,
+		warehouseRepo: warehouseRepo,
+		logger:        logger,
+		watches:       make(map[stockChange]map[*stockWatch]struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start listens for stock changes until ctx is cancelled, reconnecting when
+// the connection drops. Watches still open when it returns end with
+// ErrStockWatchStopped.
+func (w *StockWatcher) Start(ctx context.Context) {
+	defer close(w.done)
+
+	for {
+		err := w.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		mylogger.Error(ctx, w.logger, "Stock listener failed, reconnecting", zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(stockListenRetryDelay):
+		}
+	}
+}
+
+func (w *StockWatcher) listen(ctx context.Context) error {
+	poolConn, err := w.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The connection keeps listening for as long as the watcher runs, so it
+	// is taken out of the pool rather than borrowed.
+	conn := poolConn.Hijack()
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+stockChannel); err != nil {
+		return err
+	}
+
+	// Changes made while there was no listener were missed, so every watched
+	// product is sent again.
+	for _, change := range w.watched() {
+		w.publish(ctx, change)
+	}
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var change stockChange
+		if err := json.Unmarshal([]byte(n.Payload), &change); err != nil {
+			mylogger.Warn(ctx, w.logger, "Invalid stock change", zap.String("payload", n.Payload), zap.Error(err))
+			continue
+		}
+
+		w.publish(ctx, change)
+	}
+}
+
+func (w *StockWatcher) watched() []stockChange {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	res := make([]stockChange, 0, len(w.watches))
+	for change := range w.watches {
+		res = append(res, change)
+	}
+
+	return res
+}
+
+// publish loads the product's stock once and queues it for every watch
+// following the product.
+func (w *StockWatcher) publish(ctx context.Context, change stockChange) {
+	w.mu.Lock()
+	watching := len(w.watches[change]) > 0
+	w.mu.Unlock()
+
+	if !watching {
+		return
+	}
+
+	level, err := w.load(tenant.WithID(ctx, change.TenantID), change.ProductID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrProductNotFound) {
+			mylogger.Error(
+				ctx,
+				w.logger,
+				"Failed to load stock level",
+				zap.String("tenant_id", change.TenantID),
+				zap.Int64("product_id", change.ProductID),
+				zap.Error(err),
+			)
+		}
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for watch := range w.watches[change] {
+		select {
+		case watch.updates <- level:
+		default:
+			w.dropLocked(watch)
+		}
+	}
+}
+
+func (w *StockWatcher) load(ctx context.Context, productID int64) (domain.StockLevel, error) {
+	product, err := w.productRepo.GetByID(ctx, productID)
+	if err != nil {
+		return domain.StockLevel{}, err
+	}
+
+	warehouses, err := w.warehouseRepo.ProductStock(ctx, productID)
+	if err != nil {
+		return domain.StockLevel{}, err
+	}
+
+	return domain.StockLevel{
+		ProductID:  productID,
+		Total:      product.StockQuantity,
+		Warehouses: warehouses,
+	}, nil
+}
+
+// dropLocked ends a watch that cannot keep up. w.mu must be held.
+func (w *StockWatcher) dropLocked(watch *stockWatch) {
+	for change, set := range w.watches {
+		delete(set, watch)
+		if len(set) == 0 {
+			delete(w.watches, change)
+		}
+	}
+
+	watch.once.Do(func() { close(watch.lagging) })
+}
+
+func (w *StockWatcher) subscribe(tenantID string, productIDs []int64) *stockWatch {
+	watch := &stockWatch{
+		updates: make(chan domain.StockLevel, stockWatchBuffer),
+		lagging: make(chan struct{}),
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range productIDs {
+		change := stockChange{TenantID: tenantID, ProductID: id}
+		if w.watches[change] == nil {
+			w.watches[change] = make(map[*stockWatch]struct{})
+		}
+		w.watches[change][watch] = struct{}{}
+	}
+
+	return watch
+}
+
+func (w *StockWatcher) unsubscribe(tenantID string, productIDs []int64, watch *stockWatch) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, id := range productIDs {
+		change := stockChange{TenantID: tenantID, ProductID: id}
+		delete(w.watches[change], watch)
+		if len(w.watches[change]) == 0 {
+			delete(w.watches, change)
+		}
+	}
+}
+
+// Watch sends the current stock of each product, then every change to it
+// until ctx is done. Updates carry the whole level rather than a delta, so a
+// client only ever needs the last one it received.
+func (w *StockWatcher) Watch(ctx context.Context, productIDs []int64, send func(domain.StockLevel) error) error {
+	productIDs, err := domain.WatchedProducts(productIDs)
+	if err != nil {
+		return err
+	}
+
+	// Subscribing before reading the snapshot means a change committed in
+	// between is sent after it rather than lost.
+	tenantID := tenant.FromContext(ctx)
+	watch := w.subscribe(tenantID, productIDs)
+	defer w.unsubscribe(tenantID, productIDs, watch)
+
+	for _, id := range productIDs {
+		level, err := w.load(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := send(level); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-w.done:
+			return ErrStockWatchStopped
+		case <-watch.lagging:
+			mylogger.Warn(ctx, w.logger, "Stock watch fell behind", zap.Int64s("product_ids", productIDs))
+			return ErrStockWatchLagging
+		case level := <-watch.updates:
+			if err := send(level); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+	"go.uber.org/zap"
+)
+
+// takeStock allocates quantity across warehouses and takes it from them and
+// from the product total, returning the unit price and the total left. With
+// a non-zero orderID
+// the allocation is remembered so a cancellation can undo it.
+//
+// It runs in a savepoint: on ErrInsufficientStock nothing is taken and the
+// caller can keep using tx for the order's other items.
+func (s *productService) takeStock(ctx context.Context, tx pgx.Tx, orderID, productID, quantity int64, shipTo *domain.Location) (price, stock int64, err error) {
+	var allocations []domain.Allocation
+	err = db.WithTx(ctx, tx, func(sp pgx.Tx) error {
+		if orderID != 0 {
+			// Claimed first, so a redelivered order stops here rather than
+			// after taking the stock. An unavailable product rolls the claim
+			// back with the savepoint.
+			if err := s.warehouseRepo.RecordReservation(ctx, sp, orderID, productID, quantity); err != nil {
+				return err
+			}
+		}
+
+		levels, err := s.warehouseRepo.LockProductStock(ctx, sp, productID)
+		if err != nil {
+			return err
+		}
+
+		var ok bool
+		allocations, ok = domain.Allocate(levels, quantity, s.strategy, shipTo)
+		if !ok {
+			return repository.ErrInsufficientStock
+		}
+
+		price, stock, err = s.productRepo.DecreaseStock(ctx, sp, productID, quantity)
+		if err != nil {
+			return err
+		}
+
+		for _, allocation := range allocations {
+			if err := s.warehouseRepo.TakeStock(ctx, sp, allocation.WarehouseID, productID, allocation.Quantity); err != nil {
+				return err
+			}
+		}
+
+		if orderID != 0 {
+			return s.warehouseRepo.SaveAllocations(ctx, sp, orderID, productID, allocations)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Stock allocated",
+		zap.Int64("order_id", orderID),
+		zap.Int64("product_id", productID),
+		zap.Any("allocations", allocations),
+	)
+
+	return price, stock, nil
+}
+
+// returnStock puts an order's units back into the warehouses they came from.
+// Orders reserved before allocations were recorded return to the default
+// warehouse.
+func (s *productService) returnStock(ctx context.Context, tx pgx.Tx, orderID, productID, quantity int64) error {
+	allocations, err := s.warehouseRepo.ReleaseAllocations(ctx, tx, orderID, productID)
+	if err != nil {
+		return err
+	}
+
+	if len(allocations) == 0 {
+		warehouse, err := s.warehouseRepo.Default(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		allocations = []domain.Allocation{{WarehouseID: warehouse.ID, Quantity: quantity}}
+	}
+
+	var total int64
+	for _, allocation := range allocations {
+		if err := s.warehouseRepo.AddStock(ctx, tx, allocation.WarehouseID, productID, allocation.Quantity); err != nil {
+			return err
+		}
+		total += allocation.Quantity
+	}
+
+	return s.productRepo.IncreaseStock(ctx, tx, productID, total)
+}
+
+func (s *productService) CreateWarehouse(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error) {
+	if err := warehouse.Validate(); err != nil {
+		mylogger.Warn(ctx, s.logger, "Validation error", zap.Error(err))
+		return nil, err
+	}
+
+	created, err := s.warehouseRepo.Create(ctx, warehouse)
+	if err != nil {
+		return nil, err
+	}
+
+	mylogger.Info(ctx, s.logger, "Warehouse created", zap.Int64("warehouse_id", created.ID), zap.String("code", created.Code))
+
+	return created, nil
+}
+
+func (s *productService) ListWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	return s.warehouseRepo.List(ctx)
+}
+
+func (s *productService) GetProductStock(ctx context.Context, productID int64) ([]domain.WarehouseStock, error) {
+	if _, err := s.productRepo.GetByID(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	return s.warehouseRepo.ProductStock(ctx, productID)
+}
+
+// TransferStock moves units between warehouses. The product total does not
+// change.
+func (s *productService) TransferStock(ctx context.Context, productID, fromWarehouseID, toWarehouseID, quantity int64) error {
+	if quantity <= 0 || fromWarehouseID == toWarehouseID {
+		return repository.ErrInvalidInput
+	}
+
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		if err := s.warehouseRepo.TakeStock(ctx, tx, fromWarehouseID, productID, quantity); err != nil {
+			return err
+		}
+
+		return s.warehouseRepo.AddStock(ctx, tx, toWarehouseID, productID, quantity)
+	})
+	if err != nil {
+		return err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Stock transferred",
+		zap.Int64("product_id", productID),
+		zap.Int64("from_warehouse_id", fromWarehouseID),
+		zap.Int64("to_warehouse_id", toWarehouseID),
+		zap.Int64("quantity", quantity),
+	)
+
+	return nil
+}
+
+// AdjustStock changes stock by hand, e.g. for deliveries, breakage or a
+// stocktake. The warehouse and the product total move together, the change
+// is kept in the product history and a StockAdjusted event is published. It
+// returns the new product total.
+func (s *productService) AdjustStock(ctx context.Context, adjustment *domain.StockAdjustment) (int64, error) {
+	if err := adjustment.Validate(); err != nil {
+		mylogger.Warn(ctx, s.logger, "Validation error", zap.Error(err))
+		return 0, err
+	}
+
+	if _, err := s.productRepo.GetByID(ctx, adjustment.ProductID); err != nil {
+		return 0, err
+	}
+
+	var (
+		warehouseID int64
+		stock       int64
+		actor       = domain.ActorFromContext(ctx)
+	)
+	err := db.WithTx(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		warehouseID, stock, err = s.adjustStock(ctx, tx, adjustment, actor)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	mylogger.Info(
+		ctx,
+		s.logger,
+		"Stock adjusted",
+		zap.Int64("product_id", adjustment.ProductID),
+		zap.Int64("warehouse_id", warehouseID),
+		zap.Int64("delta", adjustment.Delta),
+		zap.Int64("stock_quantity", stock),
+		zap.String("reason", string(adjustment.Reason)),
+		zap.String("actor", actor),
+	)
+
+	return stock, nil
+}
+
+// adjustStock applies a validated adjustment in tx and publishes it. It
+// returns the warehouse the stock moved in and the new product total.
+func (s *productService) adjustStock(ctx context.Context, tx pgx.Tx, adjustment *domain.StockAdjustment, actor string) (warehouseID, stock int64, err error) {
+	warehouseID = adjustment.WarehouseID
+	if warehouseID == 0 {
+		warehouse, err := s.warehouseRepo.Default(ctx, tx)
+		if err != nil {
+			return 0, 0, fmt.Errorf("error finding warehouse for adjustment: %w", err)
+		}
+		warehouseID = warehouse.ID
+	}
+
+	if adjustment.Delta > 0 {
+		err = s.warehouseRepo.AddStock(ctx, tx, warehouseID, adjustment.ProductID, adjustment.Delta)
+	} else {
+		err = s.warehouseRepo.TakeStock(ctx, tx, warehouseID, adjustment.ProductID, -adjustment.Delta)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	stock, err = s.productRepo.AdjustStock(ctx, tx, adjustment.ProductID, adjustment)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	payloadBytes, err := json.Marshal(map[string]any{
+		"event": "StockAdjusted",
+		"payload": domain.StockAdjustedEvent{
+			ProductID:     adjustment.ProductID,
+			WarehouseID:   warehouseID,
+			Delta:         adjustment.Delta,
+			StockQuantity: stock,
+			Reason:        string(adjustment.Reason),
+			Note:          adjustment.Note,
+			Actor:         actor,
+			AdjustedAt:    time.Now(),
+		},
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("event payload marshal error: %w", err)
+	}
+
+	if err := s.outboxRepo.SaveOutboxEvent(ctx, tx, &outboxDomain.OutboxEvent{
+		Topic:         "product_events",
+		AggregateType: "Product",
+		AggregateID:   fmt.Sprintf("%d", adjustment.ProductID),
+		EventType:     "StockAdjusted",
+		Payload:       payloadBytes,
+	}); err != nil {
+		return 0, 0, fmt.Errorf("failed to save outbox event: %w", err)
+	}
+
+	return warehouseID, stock, nil
+}
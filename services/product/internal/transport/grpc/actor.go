@@ -0,0 +1,20 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/sakashimaa/go-pet-project/pkg/authctx"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+)
+
+// withActor attributes the mutations done under ctx to the forwarding user.
+// Calls without one, such as internal jobs, stay attributed to the system.
+func withActor(ctx context.Context) context.Context {
+	userID, ok := authctx.UserIDFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	return domain.WithActor(ctx, "user:"+strconv.FormatInt(userID, 10))
+}
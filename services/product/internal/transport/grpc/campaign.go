@@ -0,0 +1,130 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+func (h *ProductHandler) CreateCampaign(ctx context.Context, req *pb.CreateCampaignRequest) (*pb.Campaign, error) {
+	campaign, err := campaignFromProto(req)
+	if err == nil {
+		campaign, err = h.service.CreateCampaign(ctx, campaign)
+	}
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"create campaign failed",
+			zap.String("method", "CreateCampaign"),
+			zap.String("name", req.Name),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	return campaignToProto(campaign), nil
+}
+
+func (h *ProductHandler) ListCampaigns(ctx context.Context, req *pb.ListCampaignsRequest) (*pb.ListCampaignsResponse, error) {
+	list, err := h.service.ListCampaigns(ctx, req.IncludeEnded, query.Page{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"list campaigns failed",
+			zap.String("method", "ListCampaigns"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	campaigns := make([]*pb.Campaign, 0, len(list))
+	for i := range list {
+		campaigns = append(campaigns, campaignToProto(&list[i]))
+	}
+
+	return &pb.ListCampaignsResponse{Campaigns: campaigns}, nil
+}
+
+func (h *ProductHandler) ListPromotedProducts(ctx context.Context, req *pb.ListPromotedProductsRequest) (*pb.ListProductsResponse, error) {
+	promoted, err := h.service.ListPromoted(ctx, req.Limit)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"list promoted products failed",
+			zap.String("method", "ListPromotedProducts"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	products := make([]*pb.Product, 0, len(promoted))
+	for i := range promoted {
+		products = append(products, productToProto(&promoted[i]))
+	}
+
+	return &pb.ListProductsResponse{
+		Products:   products,
+		TotalCount: int64(len(products)),
+	}, nil
+}
+
+func campaignFromProto(req *pb.CreateCampaignRequest) (*domain.Campaign, error) {
+	startsAt, err := time.Parse(time.RFC3339, req.StartsAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: starts_at: %v", domain.ErrInvalidCampaign, err)
+	}
+
+	endsAt, err := time.Parse(time.RFC3339, req.EndsAt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: ends_at: %v", domain.ErrInvalidCampaign, err)
+	}
+
+	return &domain.Campaign{
+		Name:            req.Name,
+		DiscountPercent: req.DiscountPercent,
+		ProductIDs:      req.ProductIds,
+		StartsAt:        startsAt,
+		EndsAt:          endsAt,
+	}, nil
+}
+
+func campaignToProto(c *domain.Campaign) *pb.Campaign {
+	return &pb.Campaign{
+		Id:              c.ID,
+		Name:            c.Name,
+		DiscountPercent: c.DiscountPercent,
+		StartsAt:        c.StartsAt.Format(time.RFC3339),
+		EndsAt:          c.EndsAt.Format(time.RFC3339),
+		Status:          string(c.Status),
+		ProductIds:      c.ProductIDs,
+		CreatedAt:       c.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+func promotionToProto(p *domain.Promotion) *pb.ProductPromotion {
+	if p == nil {
+		return nil
+	}
+
+	return &pb.ProductPromotion{
+		CampaignId:      p.CampaignID,
+		Name:            p.Name,
+		DiscountPercent: p.DiscountPercent,
+		EndsAt:          p.EndsAt.Format(time.RFC3339),
+	}
+}
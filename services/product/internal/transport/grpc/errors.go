@@ -3,16 +3,29 @@ package grpc
 import (
 	"errors"
 
+	"github.com/go-playground/validator/v10"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
 	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+	"github.com/sakashimaa/go-pet-project/product/internal/service"
 	"google.golang.org/grpc/codes"
 )
 
 func mapErrorCode(err error) codes.Code {
 	switch {
-	case errors.Is(err, repository.ErrProductNotFound):
+	case errors.Is(err, repository.ErrProductNotFound), errors.Is(err, repository.ErrWarehouseNotFound):
 		return codes.NotFound
 	case errors.Is(err, repository.ErrInsufficientStock):
 		return codes.FailedPrecondition
+	case errors.Is(err, repository.ErrSKUAlreadyExists), errors.Is(err, repository.ErrProductAlreadyExists),
+		errors.Is(err, repository.ErrWarehouseAlreadyExists):
+		return codes.AlreadyExists
+	case errors.Is(err, repository.ErrInvalidInput), errors.Is(err, domain.ErrInvalidStockAdjustment),
+		errors.Is(err, domain.ErrInvalidCampaign), errors.Is(err, domain.ErrInvalidStockWatch):
+		return codes.InvalidArgument
+	case errors.Is(err, service.ErrStockWatchStopped), errors.Is(err, service.ErrStockWatchLagging):
+		return codes.Unavailable
+	case errors.As(err, new(validator.ValidationErrors)):
+		return codes.InvalidArgument
 	default:
 		return codes.Internal
 	}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+func (h *ProductHandler) ExportProducts(req *pb.ExportProductsRequest, stream pb.ProductService_ExportProductsServer) error {
+	filter := domain.ProductExportFilter{
+		Category:       req.Category,
+		IncludeDeleted: req.IncludeDeleted,
+	}
+
+	err := h.service.ExportProducts(stream.Context(), filter, func(rows []domain.ProductExportRow) error {
+		chunk := &pb.ExportProductsChunk{Products: make([]*pb.ExportedProduct, 0, len(rows))}
+		for i := range rows {
+			chunk.Products = append(chunk.Products, exportedProductToProto(&rows[i]))
+		}
+
+		return stream.Send(chunk)
+	})
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"export products failed",
+			zap.String("method", "ExportProducts"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return status.Error(code, err.Error())
+	}
+
+	return nil
+}
+
+func exportedProductToProto(row *domain.ProductExportRow) *pb.ExportedProduct {
+	p := &row.Product
+
+	changedAt := ""
+	if !row.PriceHistory.ChangedAt.IsZero() {
+		changedAt = row.PriceHistory.ChangedAt.Format(time.RFC3339)
+	}
+
+	return &pb.ExportedProduct{
+		Id:             p.ID,
+		Sku:            p.SKU,
+		Ean:            p.EAN,
+		Name:           p.Name,
+		Category:       p.Category,
+		CategoryPath:   domain.CategoryPath(p.Category),
+		Price:          p.Price,
+		StockQuantity:  p.StockQuantity,
+		WarehouseStock: row.WarehouseStock,
+		MinPrice:       row.PriceHistory.Min,
+		MaxPrice:       row.PriceHistory.Max,
+		PriceChanges:   row.PriceHistory.Changes,
+		PriceChangedAt: changedAt,
+		CreatedAt:      p.CreatedAt.Format(time.RFC3339),
+		DeletedAt:      formatDeletedAt(p.DeletedAt),
+	}
+}
@@ -2,25 +2,46 @@ package grpc
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
+	"github.com/sakashimaa/go-pet-project/pkg/query"
 	"github.com/sakashimaa/go-pet-project/product/internal/domain"
 	"github.com/sakashimaa/go-pet-project/product/internal/service"
 	pb "github.com/sakashimaa/go-pet-project/proto/product"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 type ProductHandler struct {
 	pb.UnimplementedProductServiceServer
-	service service.ProductService
-	logger  *zap.Logger
+	service      service.ProductService
+	logger       *zap.Logger
+	stockWatcher *service.StockWatcher
 }
 
-func NewProductHandler(service service.ProductService, logger *zap.Logger) *ProductHandler {
-	return &ProductHandler{service: service, logger: logger}
+type Option func(*ProductHandler)
+
+// WithStockWatcher serves WatchStock. Without it the method is unimplemented.
+func WithStockWatcher(watcher *service.StockWatcher) Option {
+	return func(h *ProductHandler) {
+		h.stockWatcher = watcher
+	}
+}
+
+func NewProductHandler(service service.ProductService, logger *zap.Logger, opts ...Option) *ProductHandler {
+	h := &ProductHandler{service: service, logger: logger}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 func (h *ProductHandler) DeleteProduct(ctx context.Context, req *pb.DeleteProductRequest) (*pb.DeleteProductResponse, error) {
+	ctx = withActor(ctx)
+
 	err := h.service.Delete(ctx, req.Id)
 	if err != nil {
 		code := mapErrorCode(err)
@@ -41,8 +62,95 @@ func (h *ProductHandler) DeleteProduct(ctx context.Context, req *pb.DeleteProduc
 	}, nil
 }
 
+func (h *ProductHandler) RestoreProduct(ctx context.Context, req *pb.RestoreProductRequest) (*pb.GetProductResponse, error) {
+	ctx = withActor(ctx)
+
+	res, err := h.service.Restore(ctx, req.Id)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"restore product failed",
+			zap.String("method", "RestoreProduct"),
+			zap.Int64("product_id", req.Id),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	return &pb.GetProductResponse{
+		Product: productToProto(res),
+	}, nil
+}
+
+func (h *ProductHandler) ListDeletedProducts(ctx context.Context, req *pb.ListDeletedProductsRequest) (*pb.ListProductsResponse, error) {
+	list, total, err := h.service.ListDeleted(ctx, query.Page{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"list deleted products failed",
+			zap.String("method", "ListDeletedProducts"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	products := make([]*pb.Product, 0, len(list))
+	for i := range list {
+		products = append(products, productToProto(&list[i]))
+	}
+
+	return &pb.ListProductsResponse{
+		Products:   products,
+		TotalCount: total,
+	}, nil
+}
+
+func (h *ProductHandler) GetProductHistory(ctx context.Context, req *pb.GetProductHistoryRequest) (*pb.GetProductHistoryResponse, error) {
+	history, err := h.service.GetHistory(ctx, req.ProductId, query.Page{Limit: req.Limit, Offset: req.Offset})
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"get product history failed",
+			zap.String("method", "GetProductHistory"),
+			zap.Int64("product_id", req.ProductId),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	revisions := make([]*pb.ProductRevision, 0, len(history))
+	for _, revision := range history {
+		changes, err := json.Marshal(revision.Changes)
+		if err != nil {
+			return nil, status.Error(codes.Internal, codes.Internal.String())
+		}
+
+		revisions = append(revisions, &pb.ProductRevision{
+			Id:        revision.ID,
+			ProductId: revision.ProductID,
+			Action:    string(revision.Action),
+			Actor:     revision.Actor,
+			Changes:   string(changes),
+			CreatedAt: revision.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	return &pb.GetProductHistoryResponse{Revisions: revisions}, nil
+}
+
 func (h *ProductHandler) DecreaseStock(ctx context.Context, req *pb.DecreaseStockRequest) (*pb.DecreaseStockResponse, error) {
-	message, err := h.service.DecreaseStock(ctx, req.ProductId, req.Quantity)
+	ctx = withActor(ctx)
+
+	stock, err := h.service.DecreaseStock(ctx, req.ProductId, req.Quantity)
 	if err != nil {
 		code := mapErrorCode(err)
 
@@ -59,13 +167,14 @@ func (h *ProductHandler) DecreaseStock(ctx context.Context, req *pb.DecreaseStoc
 	}
 
 	return &pb.DecreaseStockResponse{
-		Message: message,
-		Success: true,
+		Message:       "success",
+		Success:       true,
+		StockQuantity: stock,
 	}, nil
 }
 
 func (h *ProductHandler) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
-	list, quantity, err := h.service.List(ctx, req.Limit, req.Offset, req.Search)
+	list, quantity, err := h.service.List(ctx, productFilterFromProto(req))
 	if err != nil {
 		code := mapErrorCode(err)
 
@@ -84,18 +193,8 @@ func (h *ProductHandler) ListProducts(ctx context.Context, req *pb.ListProductsR
 
 	responseList := make([]*pb.Product, 0, len(list))
 
-	for _, p := range list {
-		protoProduct := &pb.Product{
-			Id:            p.ID,
-			Name:          p.Name,
-			Description:   p.Description,
-			Price:         p.Price,
-			StockQuantity: p.StockQuantity,
-			ImageUrl:      p.ImageUrl,
-			Category:      p.Category,
-		}
-
-		responseList = append(responseList, protoProduct)
+	for i := range list {
+		responseList = append(responseList, productToProto(&list[i]))
 	}
 
 	return &pb.ListProductsResponse{
@@ -120,28 +219,158 @@ func (h *ProductHandler) GetProduct(ctx context.Context, req *pb.GetProductReque
 		return nil, status.Error(code, code.String())
 	}
 
-	productProto := &pb.Product{
-		Id:            res.ID,
-		Name:          res.Name,
-		Description:   res.Description,
-		Price:         res.Price,
-		StockQuantity: res.StockQuantity,
-		ImageUrl:      res.ImageUrl,
-		Category:      res.Category,
+	return &pb.GetProductResponse{
+		Product: productToProto(res),
+	}, nil
+}
+
+func (h *ProductHandler) GetProductBySKU(ctx context.Context, req *pb.GetProductBySKURequest) (*pb.GetProductResponse, error) {
+	res, err := h.service.FindBySKU(ctx, req.Sku)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"get product by sku failed",
+			zap.String("method", "GetProductBySKU"),
+			zap.String("sku", req.Sku),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
 	}
 
 	return &pb.GetProductResponse{
-		Product: productProto,
+		Product: productToProto(res),
+	}, nil
+}
+
+func (h *ProductHandler) GetRelatedProducts(ctx context.Context, req *pb.GetRelatedProductsRequest) (*pb.GetRelatedProductsResponse, error) {
+	related, err := h.service.GetRelated(ctx, req.ProductId, req.Limit)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"get related products failed",
+			zap.String("method", "GetRelatedProducts"),
+			zap.Int64("product_id", req.ProductId),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	products := make([]*pb.Product, 0, len(related))
+	for i := range related {
+		products = append(products, productToProto(&related[i]))
+	}
+
+	return &pb.GetRelatedProductsResponse{
+		Products: products,
 	}, nil
 }
 
+func (h *ProductHandler) GetPersonalizedProducts(ctx context.Context, req *pb.GetPersonalizedProductsRequest) (*pb.GetPersonalizedProductsResponse, error) {
+	personalized, err := h.service.GetPersonalized(ctx, req.UserId, req.Limit)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"get personalized products failed",
+			zap.String("method", "GetPersonalizedProducts"),
+			zap.Int64("user_id", req.UserId),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	products := make([]*pb.Product, 0, len(personalized))
+	for i := range personalized {
+		products = append(products, productToProto(&personalized[i]))
+	}
+
+	return &pb.GetPersonalizedProductsResponse{
+		Products: products,
+	}, nil
+}
+
+func productToProto(p *domain.Product) *pb.Product {
+	return &pb.Product{
+		Id:             p.ID,
+		Name:           p.Name,
+		Description:    p.Description,
+		Price:          p.Price,
+		StockQuantity:  p.StockQuantity,
+		ImageUrl:       p.ImageUrl,
+		Category:       p.Category,
+		Sku:            p.SKU,
+		Ean:            p.EAN,
+		Attributes:     p.Attributes,
+		Rating:         p.Rating,
+		DeletedAt:      formatDeletedAt(p.DeletedAt),
+		EffectivePrice: p.EffectivePrice(),
+		Promotion:      promotionToProto(p.Promotion),
+	}
+}
+
+func formatDeletedAt(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+func productFilterFromProto(req *pb.ListProductsRequest) domain.ProductFilter {
+	filter := domain.ProductFilter{
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+		Search:      req.Search,
+		Categories:  req.Categories,
+		PriceMin:    req.PriceMin,
+		PriceMax:    req.PriceMax,
+		InStockOnly: req.InStockOnly,
+		StockMax:    req.StockMax,
+	}
+
+	if len(req.Attributes) > 0 {
+		filter.Attributes = make(map[string][]string, len(req.Attributes))
+		for _, attr := range req.Attributes {
+			filter.Attributes[attr.Key] = append(filter.Attributes[attr.Key], attr.Values...)
+		}
+	}
+
+	switch req.Sort {
+	case pb.ProductSort_PRODUCT_SORT_PRICE_ASC:
+		filter.Sort = domain.SortPriceAsc
+	case pb.ProductSort_PRODUCT_SORT_PRICE_DESC:
+		filter.Sort = domain.SortPriceDesc
+	case pb.ProductSort_PRODUCT_SORT_RATING:
+		filter.Sort = domain.SortRating
+	case pb.ProductSort_PRODUCT_SORT_STOCK_ASC:
+		filter.Sort = domain.SortStockAsc
+	default:
+		filter.Sort = domain.SortNewest
+	}
+
+	return filter
+}
+
 func (h *ProductHandler) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.CreateProductResponse, error) {
+	ctx = withActor(ctx)
+
 	product := domain.Product{
 		Name:          req.Name,
 		Description:   req.Description,
 		Price:         req.Price,
 		StockQuantity: req.StockQuantity,
 		Category:      req.Category,
+		Attributes:    req.Attributes,
+		SKU:           req.Sku,
+		EAN:           req.Ean,
 	}
 
 	res, err := h.service.Create(ctx, &product)
@@ -0,0 +1,51 @@
+package grpc
+
+import (
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (h *ProductHandler) WatchStock(req *pb.WatchStockRequest, stream pb.ProductService_WatchStockServer) error {
+	if h.stockWatcher == nil {
+		return status.Error(codes.Unimplemented, "stock watching is not enabled")
+	}
+
+	err := h.stockWatcher.Watch(stream.Context(), req.ProductIds, func(level domain.StockLevel) error {
+		return stream.Send(stockUpdateToProto(&level))
+	})
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Warn(
+			"watch stock failed",
+			zap.String("method", "WatchStock"),
+			zap.Int64s("product_ids", req.ProductIds),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return status.Error(code, err.Error())
+	}
+
+	return nil
+}
+
+func stockUpdateToProto(level *domain.StockLevel) *pb.StockUpdate {
+	warehouses := make([]*pb.WarehouseStock, 0, len(level.Warehouses))
+	for _, s := range level.Warehouses {
+		warehouses = append(warehouses, &pb.WarehouseStock{
+			WarehouseId:   s.WarehouseID,
+			WarehouseCode: s.WarehouseCode,
+			Quantity:      s.Quantity,
+		})
+	}
+
+	return &pb.StockUpdate{
+		ProductId:     level.ProductID,
+		StockQuantity: level.Total,
+		Warehouses:    warehouses,
+	}
+}
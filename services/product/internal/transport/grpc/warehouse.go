@@ -0,0 +1,157 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/product"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/status"
+)
+
+func (h *ProductHandler) CreateWarehouse(ctx context.Context, req *pb.CreateWarehouseRequest) (*pb.Warehouse, error) {
+	warehouse := &domain.Warehouse{
+		Code:     req.Code,
+		Name:     req.Name,
+		Priority: req.Priority,
+	}
+	if req.HasLocation {
+		warehouse.Location = &domain.Location{Latitude: req.Latitude, Longitude: req.Longitude}
+	}
+
+	res, err := h.service.CreateWarehouse(ctx, warehouse)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"create warehouse failed",
+			zap.String("method", "CreateWarehouse"),
+			zap.String("code", req.Code),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	return warehouseToProto(res), nil
+}
+
+func (h *ProductHandler) ListWarehouses(ctx context.Context, _ *pb.ListWarehousesRequest) (*pb.ListWarehousesResponse, error) {
+	list, err := h.service.ListWarehouses(ctx)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"list warehouses failed",
+			zap.String("method", "ListWarehouses"),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	warehouses := make([]*pb.Warehouse, 0, len(list))
+	for i := range list {
+		warehouses = append(warehouses, warehouseToProto(&list[i]))
+	}
+
+	return &pb.ListWarehousesResponse{Warehouses: warehouses}, nil
+}
+
+func (h *ProductHandler) GetProductStock(ctx context.Context, req *pb.GetProductStockRequest) (*pb.GetProductStockResponse, error) {
+	list, err := h.service.GetProductStock(ctx, req.ProductId)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"get product stock failed",
+			zap.String("method", "GetProductStock"),
+			zap.Int64("product_id", req.ProductId),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	stock := make([]*pb.WarehouseStock, 0, len(list))
+	for _, s := range list {
+		stock = append(stock, &pb.WarehouseStock{
+			WarehouseId:   s.WarehouseID,
+			WarehouseCode: s.WarehouseCode,
+			Quantity:      s.Quantity,
+		})
+	}
+
+	return &pb.GetProductStockResponse{Stock: stock}, nil
+}
+
+func (h *ProductHandler) TransferStock(ctx context.Context, req *pb.TransferStockRequest) (*pb.TransferStockResponse, error) {
+	err := h.service.TransferStock(ctx, req.ProductId, req.FromWarehouseId, req.ToWarehouseId, req.Quantity)
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"transfer stock failed",
+			zap.String("method", "TransferStock"),
+			zap.Int64("product_id", req.ProductId),
+			zap.Int64("from_warehouse_id", req.FromWarehouseId),
+			zap.Int64("to_warehouse_id", req.ToWarehouseId),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	return &pb.TransferStockResponse{Success: true}, nil
+}
+
+func (h *ProductHandler) AdjustStock(ctx context.Context, req *pb.AdjustStockRequest) (*pb.AdjustStockResponse, error) {
+	ctx = withActor(ctx)
+
+	stock, err := h.service.AdjustStock(ctx, &domain.StockAdjustment{
+		ProductID:   req.ProductId,
+		WarehouseID: req.WarehouseId,
+		Delta:       req.Delta,
+		Reason:      domain.StockAdjustmentReason(req.Reason),
+		Note:        req.Note,
+	})
+	if err != nil {
+		code := mapErrorCode(err)
+
+		h.logger.Error(
+			"adjust stock failed",
+			zap.String("method", "AdjustStock"),
+			zap.Int64("product_id", req.ProductId),
+			zap.Int64("delta", req.Delta),
+			zap.String("reason", req.Reason),
+			zap.String("status_code", code.String()),
+			zap.Error(err),
+		)
+
+		return nil, status.Error(code, code.String())
+	}
+
+	return &pb.AdjustStockResponse{StockQuantity: stock}, nil
+}
+
+func warehouseToProto(w *domain.Warehouse) *pb.Warehouse {
+	res := &pb.Warehouse{
+		Id:       w.ID,
+		Code:     w.Code,
+		Name:     w.Name,
+		Priority: w.Priority,
+		IsActive: w.IsActive,
+	}
+
+	if w.Location != nil {
+		res.HasLocation = true
+		res.Latitude = w.Location.Latitude
+		res.Longitude = w.Location.Longitude
+	}
+
+	return res
+}
@@ -2,86 +2,49 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 
-	"github.com/IBM/sarama"
-	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
 	"github.com/sakashimaa/go-pet-project/pkg/kafka"
-	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
-	"github.com/sakashimaa/go-pet-project/product/internal/domain"
 	"github.com/sakashimaa/go-pet-project/product/internal/service"
 	"go.uber.org/zap"
 )
 
+// GroupID is the consumer group the service reads with.
+const GroupID = "product-service-group"
+
+// Topics are the topics GroupID subscribes to.
+var Topics = []string{"product_events", "order_events"}
+
 type Consumer struct {
 	service service.ProductService
+	router  *kafka.Router
 	logger  *zap.Logger
 }
 
 func NewConsumer(service service.ProductService, logger *zap.Logger) *Consumer {
-	return &Consumer{
+	c := &Consumer{
 		service: service,
 		logger:  logger,
 	}
-}
 
-func (c *Consumer) Start(ctx context.Context, brokers []string) {
-	consumerGroup := kafka.NewConsumerGroup(
-		brokers,
-		"product-service-group",
-		[]string{"product_events", "order_events"},
-		c.processMessage,
-		c.logger,
+	c.router = kafka.NewRouter(logger, kafka.StrictPayloads()).Register(
+		kafka.On("OrderCreated", service.ReserveProduct),
+		kafka.On("OrderCancelled", service.ReturnStock),
+		kafka.On("ReturnApproved", service.RestockReturn),
+		kafka.On("OrderPaid", service.RecordAffinity),
 	)
 
-	consumerGroup.Run(ctx)
+	return c
 }
 
-func (c *Consumer) processMessage(ctx context.Context, msg *sarama.ConsumerMessage) error {
-	mylogger.Info(
-		ctx,
+func (c *Consumer) Start(ctx context.Context, config kafka.Config, opts ...kafka.ConsumerOption) {
+	consumerGroup := kafka.NewConsumerGroup(
+		config,
+		GroupID,
+		Topics,
+		c.router.Route,
 		c.logger,
-		"Processing message",
-		zap.String("topic", msg.Topic),
+		opts...,
 	)
 
-	type EventWrapper struct {
-		Event   string          `json:"event"`
-		Payload json.RawMessage `json:"payload"`
-	}
-
-	var wrapper EventWrapper
-	if err := json.Unmarshal(msg.Value, &wrapper); err != nil {
-		mylogger.Error(ctx, c.logger, "Error unmarshalling wrapper", zap.Error(err))
-		return err
-	}
-
-	switch wrapper.Event {
-	case "OrderCreated":
-		var event domain.OrderCreatedEvent
-		if err := json.Unmarshal(wrapper.Payload, &event); err != nil {
-			mylogger.Warn(ctx, c.logger, "Error unmarshalling event structure", zap.Error(err))
-			return err
-		}
-
-		if err := c.service.ReserveProduct(ctx, &event); err != nil {
-			mylogger.Warn(ctx, c.logger, "Error processing order created", zap.Error(err))
-			return err
-		}
-	case "OrderCancelled":
-		var event outboxDomain.OrderCancelledEvent
-		if err := json.Unmarshal(wrapper.Payload, &event); err != nil {
-			mylogger.Warn(ctx, c.logger, "Error unmarshalling event structure", zap.Error(err))
-			return err
-		}
-
-		if err := c.service.ReturnStock(ctx, &event); err != nil {
-			mylogger.Warn(ctx, c.logger, "Error processing return stock", zap.Error(err))
-			return err
-		}
-	default:
-		mylogger.Warn(ctx, c.logger, "Ignored event type", zap.String("event_type", wrapper.Event))
-	}
-
-	return nil
+	consumerGroup.Run(ctx)
 }
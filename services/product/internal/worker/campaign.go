@@ -0,0 +1,45 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"go.uber.org/zap"
+)
+
+type CampaignActivator interface {
+	ActivateCampaigns(ctx context.Context) ([]domain.Campaign, error)
+}
+
+// CampaignJob starts and ends price campaigns. Prices follow the campaign
+// window on their own; the job announces the change and drops stale cache
+// entries, so it runs often enough for neither to lag noticeably.
+type CampaignJob struct {
+	activator CampaignActivator
+	logger    *zap.Logger
+}
+
+func NewCampaignJob(activator CampaignActivator, logger *zap.Logger) *CampaignJob {
+	return &CampaignJob{
+		activator: activator,
+		logger:    logger,
+	}
+}
+
+func (j *CampaignJob) Run(ctx context.Context) error {
+	moved, err := j.activator.ActivateCampaigns(ctx)
+
+	for _, campaign := range moved {
+		mylogger.Info(
+			ctx,
+			j.logger,
+			"Campaign status changed",
+			zap.Int64("campaign_id", campaign.ID),
+			zap.String("tenant_id", campaign.TenantID),
+			zap.String("status", string(campaign.Status)),
+		)
+	}
+
+	return err
+}
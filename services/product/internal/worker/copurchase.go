@@ -0,0 +1,37 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/sakashimaa/go-pet-project/pkg/mylogger"
+	"go.uber.org/zap"
+)
+
+type CopurchaseRebuilder interface {
+	RebuildCopurchases(ctx context.Context) (int64, error)
+}
+
+// CopurchaseJob recomputes "bought together" scores used by
+// GetRelatedProducts. Results are only as fresh as the last run.
+type CopurchaseJob struct {
+	rebuilder CopurchaseRebuilder
+	logger    *zap.Logger
+}
+
+func NewCopurchaseJob(rebuilder CopurchaseRebuilder, logger *zap.Logger) *CopurchaseJob {
+	return &CopurchaseJob{
+		rebuilder: rebuilder,
+		logger:    logger,
+	}
+}
+
+func (j *CopurchaseJob) Run(ctx context.Context) error {
+	pairs, err := j.rebuilder.RebuildCopurchases(ctx)
+	if err != nil {
+		return err
+	}
+
+	mylogger.Info(ctx, j.logger, "Copurchases rebuilt", zap.Int64("pairs", pairs))
+
+	return nil
+}
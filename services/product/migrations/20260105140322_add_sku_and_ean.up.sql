@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN sku VARCHAR(64),
+ADD COLUMN ean VARCHAR(13);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku ON products(sku) WHERE sku IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_ean ON products(ean) WHERE ean IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_products_ean;
+-- DROP INDEX IF EXISTS idx_products_sku;
+--
+-- ALTER TABLE products
+-- DROP COLUMN ean,
+-- DROP COLUMN sku;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}'::jsonb,
+ADD COLUMN rating REAL NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_products_category_price
+    ON products(category, price) INCLUDE (stock_quantity)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_created_at
+    ON products(created_at DESC)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_price
+    ON products(price)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_rating
+    ON products(rating DESC)
+    WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_products_attributes
+    ON products USING GIN (attributes jsonb_path_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_products_attributes;
+-- DROP INDEX IF EXISTS idx_products_rating;
+-- DROP INDEX IF EXISTS idx_products_price;
+-- DROP INDEX IF EXISTS idx_products_created_at;
+-- DROP INDEX IF EXISTS idx_products_category_price;
+--
+-- ALTER TABLE products
+-- DROP COLUMN rating,
+-- DROP COLUMN attributes;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS product_purchases (
+    order_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    purchased_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (order_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_purchases_product
+    ON product_purchases(product_id);
+
+CREATE TABLE IF NOT EXISTS product_copurchases (
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    related_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    score BIGINT NOT NULL,
+    computed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, related_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_copurchases_score
+    ON product_copurchases(product_id, score DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS product_copurchases;
+-- DROP TABLE IF EXISTS product_purchases;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS event_id UUID NOT NULL DEFAULT gen_random_uuid(),
+ADD COLUMN IF NOT EXISTS occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+ADD COLUMN IF NOT EXISTS producer TEXT NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS correlation_id UUID,
+ADD COLUMN IF NOT EXISTS causation_id UUID;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_outbox_event_id ON outbox(event_id);
+CREATE INDEX IF NOT EXISTS idx_outbox_correlation_id ON outbox(correlation_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_outbox_correlation_id;
+-- DROP INDEX IF EXISTS idx_outbox_event_id;
+--
+-- ALTER TABLE outbox
+-- DROP COLUMN causation_id,
+-- DROP COLUMN correlation_id,
+-- DROP COLUMN producer,
+-- DROP COLUMN occurred_at,
+-- DROP COLUMN event_id;
+-- +goose StatementEnd
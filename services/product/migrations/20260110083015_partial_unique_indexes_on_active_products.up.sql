@@ -0,0 +1,30 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+DROP CONSTRAINT IF EXISTS products_name_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_name_active
+    ON products(name)
+    WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_products_sku;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku
+    ON products(sku)
+    WHERE sku IS NOT NULL AND deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_products_ean;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_ean
+    ON products(ean)
+    WHERE ean IS NOT NULL AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_products_deleted_at
+    ON products(deleted_at DESC)
+    WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP INDEX IF EXISTS idx_products_deleted_at;
+-- DROP INDEX IF EXISTS idx_products_name_active;
+-- ALTER TABLE products ADD CONSTRAINT products_name_key UNIQUE(name);
+-- +goose StatementEnd
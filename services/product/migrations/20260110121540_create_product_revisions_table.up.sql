@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    actor TEXT NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_revisions_product
+    ON product_revisions(product_id, created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS product_revisions;
+-- +goose StatementEnd
@@ -0,0 +1,56 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS warehouses (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    latitude DOUBLE PRECISION,
+    longitude DOUBLE PRECISION,
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS warehouse_stock (
+    warehouse_id BIGINT NOT NULL REFERENCES warehouses(id),
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    quantity BIGINT NOT NULL DEFAULT 0 CHECK (quantity >= 0),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (warehouse_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_warehouse_stock_product ON warehouse_stock(product_id);
+
+-- stock_allocations remembers which warehouses an order's units came from,
+-- so cancellations put them back where they were taken.
+CREATE TABLE IF NOT EXISTS stock_allocations (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    warehouse_id BIGINT NOT NULL REFERENCES warehouses(id),
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stock_allocations_order ON stock_allocations(order_id, product_id);
+
+-- Existing stock moves into a default warehouse; products.stock_quantity
+-- stays as the total across warehouses.
+INSERT INTO warehouses (code, name, priority)
+VALUES ('MAIN', 'Main warehouse', 100)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO warehouse_stock (warehouse_id, product_id, quantity)
+SELECT w.id, p.id, p.stock_quantity
+FROM products p
+CROSS JOIN warehouses w
+WHERE w.code = 'MAIN' AND p.stock_quantity > 0
+ON CONFLICT (warehouse_id, product_id) DO NOTHING;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS stock_allocations;
+-- DROP TABLE IF EXISTS warehouse_stock;
+-- DROP TABLE IF EXISTS warehouses;
+-- +goose StatementEnd
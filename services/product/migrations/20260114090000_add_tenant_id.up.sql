@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+
+-- Names, SKUs and EANs are unique per storefront. The index names stay the
+-- same because the repository maps unique violations by constraint name.
+DROP INDEX IF EXISTS idx_products_name_active;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_name_active
+    ON products(tenant_id, name)
+    WHERE deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_products_sku;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_sku
+    ON products(tenant_id, sku)
+    WHERE sku IS NOT NULL AND deleted_at IS NULL;
+
+DROP INDEX IF EXISTS idx_products_ean;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_products_ean
+    ON products(tenant_id, ean)
+    WHERE ean IS NOT NULL AND deleted_at IS NULL;
+
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE outbox DROP COLUMN tenant_id;
+--
+-- DROP INDEX IF EXISTS idx_products_ean;
+-- CREATE UNIQUE INDEX idx_products_ean ON products(ean) WHERE ean IS NOT NULL AND deleted_at IS NULL;
+-- DROP INDEX IF EXISTS idx_products_sku;
+-- CREATE UNIQUE INDEX idx_products_sku ON products(sku) WHERE sku IS NOT NULL AND deleted_at IS NULL;
+-- DROP INDEX IF EXISTS idx_products_name_active;
+-- CREATE UNIQUE INDEX idx_products_name_active ON products(name) WHERE deleted_at IS NULL;
+--
+-- ALTER TABLE products DROP COLUMN tenant_id;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- reservations records each product an order took stock of. The unique key
+-- makes a redelivered OrderCreated conflict instead of taking the stock a
+-- second time. Rows stay after the stock is returned, so a redelivery of a
+-- cancelled order is still recognised.
+CREATE TABLE IF NOT EXISTS reservations (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL,
+    product_id BIGINT NOT NULL,
+    quantity BIGINT NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CONSTRAINT reservations_order_product_key UNIQUE (order_id, product_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS reservations;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- user_category_affinity counts the units a user paid for per category, for
+-- GetPersonalizedProducts. It is built from OrderPaid events.
+CREATE TABLE IF NOT EXISTS user_category_affinity (
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    user_id BIGINT NOT NULL,
+    category TEXT NOT NULL,
+    score BIGINT NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, user_id, category)
+);
+
+-- affinity_orders records the orders already counted, so a redelivered
+-- OrderPaid does not count them twice.
+CREATE TABLE IF NOT EXISTS affinity_orders (
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    order_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, order_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS affinity_orders;
+-- DROP TABLE IF EXISTS user_category_affinity;
+-- +goose StatementEnd
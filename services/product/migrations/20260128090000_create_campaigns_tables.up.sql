@@ -0,0 +1,39 @@
+-- +goose Up
+-- +goose StatementBegin
+-- campaigns discount a set of products by a percentage for a time window.
+-- Prices are discounted at read time from the window alone; status only
+-- tracks which ProductPromotionStarted/Ended events were emitted.
+CREATE TABLE IF NOT EXISTS campaigns (
+    id BIGSERIAL PRIMARY KEY,
+    tenant_id TEXT NOT NULL DEFAULT 'default',
+    name TEXT NOT NULL,
+    discount_percent INT NOT NULL CHECK (discount_percent BETWEEN 1 AND 99),
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status TEXT NOT NULL DEFAULT 'scheduled',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_window
+    ON campaigns(tenant_id, starts_at, ends_at);
+
+CREATE INDEX IF NOT EXISTS idx_campaigns_pending
+    ON campaigns(starts_at, ends_at)
+    WHERE status <> 'ended';
+
+CREATE TABLE IF NOT EXISTS campaign_products (
+    campaign_id BIGINT NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    PRIMARY KEY (campaign_id, product_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_campaign_products_product
+    ON campaign_products(product_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TABLE IF EXISTS campaign_products;
+-- DROP TABLE IF EXISTS campaigns;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Where an event came from and where it went, for tracing a flow across
+-- services: the trace it was saved in and the Kafka partition and offset it
+-- was published at.
+ALTER TABLE outbox
+ADD COLUMN IF NOT EXISTS trace_id TEXT,
+ADD COLUMN IF NOT EXISTS kafka_partition INT,
+ADD COLUMN IF NOT EXISTS kafka_offset BIGINT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- ALTER TABLE outbox
+-- DROP COLUMN kafka_offset,
+-- DROP COLUMN kafka_partition,
+-- DROP COLUMN trace_id;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Every change to a product's stock notifies stock_changes with the product,
+-- for the WatchStock streams. Postgres delivers notifications on commit and
+-- folds identical ones, so a transaction that touches several warehouses of
+-- a product notifies it once, and a rolled back one not at all.
+CREATE OR REPLACE FUNCTION notify_product_stock() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('stock_changes', json_build_object('tenant_id', NEW.tenant_id, 'product_id', NEW.id)::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_warehouse_stock() RETURNS trigger AS $$
+DECLARE
+    changed_product_id BIGINT;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_product_id := OLD.product_id;
+    ELSE
+        changed_product_id := NEW.product_id;
+    END IF;
+
+    PERFORM pg_notify('stock_changes', json_build_object('tenant_id', p.tenant_id, 'product_id', p.id)::text)
+    FROM products p
+    WHERE p.id = changed_product_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS products_stock_notify ON products;
+CREATE TRIGGER products_stock_notify
+    AFTER INSERT OR UPDATE OF stock_quantity ON products
+    FOR EACH ROW EXECUTE FUNCTION notify_product_stock();
+
+DROP TRIGGER IF EXISTS warehouse_stock_notify ON warehouse_stock;
+CREATE TRIGGER warehouse_stock_notify
+    AFTER INSERT OR UPDATE OF quantity OR DELETE ON warehouse_stock
+    FOR EACH ROW EXECUTE FUNCTION notify_warehouse_stock();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- DROP TRIGGER IF EXISTS warehouse_stock_notify ON warehouse_stock;
+-- DROP TRIGGER IF EXISTS products_stock_notify ON products;
+-- DROP FUNCTION IF EXISTS notify_warehouse_stock();
+-- DROP FUNCTION IF EXISTS notify_product_stock();
+-- +goose StatementEnd
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) TestAdjustStock_RestockAndDamage() {
+	east := s.createWarehouse("EAST", nil)
+
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Rega Planar 3", Price: 50000, StockQuantity: 5, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	ctx := domain.WithActor(s.Ctx, "user:7")
+
+	stock, err := s.ProductService.AdjustStock(ctx, &domain.StockAdjustment{
+		ProductID: id, WarehouseID: east.ID, Delta: 4, Reason: domain.AdjustRestock,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(9), stock)
+
+	stock, err = s.ProductService.AdjustStock(ctx, &domain.StockAdjustment{
+		ProductID: id, Delta: -2, Reason: domain.AdjustDamaged, Note: "dropped by the courier",
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(7), stock)
+
+	s.Require().Equal(map[string]int64{"MAIN": 3, "EAST": 4}, s.stockByWarehouse(id))
+
+	product, err := s.ProductService.FindByID(s.Ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(7), product.StockQuantity)
+
+	history, err := s.ProductService.GetHistory(s.Ctx, id, query.Page{})
+	s.Require().NoError(err)
+	s.Require().Equal(domain.RevisionStockAdjusted, history[0].Action)
+	s.Require().Equal("user:7", history[0].Actor)
+	s.Require().Equal("damaged", history[0].Changes["reason"].To)
+
+	var events int
+	err = s.DbPool.QueryRow(
+		s.Ctx,
+		"SELECT COUNT(*) FROM outbox WHERE aggregate_id = $1 AND event_type = 'StockAdjusted'",
+		fmt.Sprintf("%d", id),
+	).Scan(&events)
+	s.Require().NoError(err)
+	s.Require().Equal(2, events)
+}
+
+func (s *IntegrationTestSuite) TestAdjustStock_CannotGoBelowZero() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Dual 1219", Price: 25000, StockQuantity: 2, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.AdjustStock(s.Ctx, &domain.StockAdjustment{
+		ProductID: id, Delta: -3, Reason: domain.AdjustLost,
+	})
+	s.Require().ErrorIs(err, repository.ErrInsufficientStock)
+
+	s.Require().Equal(map[string]int64{"MAIN": 2}, s.stockByWarehouse(id))
+}
+
+func (s *IntegrationTestSuite) TestAdjustStock_ReasonMustMatchDirection() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Technics SL-1200", Price: 60000, StockQuantity: 2, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.AdjustStock(s.Ctx, &domain.StockAdjustment{
+		ProductID: id, Delta: -1, Reason: domain.AdjustRestock,
+	})
+	s.Require().ErrorIs(err, domain.ErrInvalidStockAdjustment)
+
+	_, err = s.ProductService.AdjustStock(s.Ctx, &domain.StockAdjustment{
+		ProductID: id, Delta: 1, Reason: "gift",
+	})
+	s.Require().ErrorIs(err, domain.ErrInvalidStockAdjustment)
+
+	_, err = s.ProductService.AdjustStock(s.Ctx, &domain.StockAdjustment{
+		ProductID: 999999, Delta: 1, Reason: domain.AdjustCorrection,
+	})
+	s.Require().ErrorIs(err, repository.ErrProductNotFound)
+}
@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/service"
+)
+
+func (s *IntegrationTestSuite) TestCachedFindByID_FillsCacheOnMiss() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Marantz 2270", Price: 60000, StockQuantity: 2, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	key := fmt.Sprintf("default:product:%d", id)
+	s.Require().Zero(s.RedisInternalClient.Exists(s.Ctx, key).Val())
+
+	_, err = s.CachedProductService.FindByID(s.Ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), s.RedisInternalClient.Exists(s.Ctx, key).Val())
+
+	// A cached entry is served without going to Postgres.
+	_, err = s.DbPool.Exec(s.Ctx, "UPDATE products SET name = 'Changed behind the cache' WHERE id = $1", id)
+	s.Require().NoError(err)
+
+	cached, err := s.CachedProductService.FindByID(s.Ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal("Marantz 2270", cached.Name)
+}
+
+func (s *IntegrationTestSuite) TestCachedFindByID_BypassesUnavailableRedis() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "McIntosh MC275", Price: 80000, StockQuantity: 1, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	down := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})
+	defer down.Close()
+
+	calls := &countingHook{}
+	down.AddHook(calls)
+
+	cached := service.NewCachedProductService(s.ProductService, down, service.WithCacheTimeout(20*time.Millisecond))
+
+	for range 10 {
+		product, err := cached.FindByID(s.Ctx, id)
+		s.Require().NoError(err)
+		s.Require().Equal(id, product.ID)
+	}
+
+	// With the breaker open, lookups no longer touch Redis at all.
+	before := calls.n.Load()
+	_, err = cached.FindByID(s.Ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal(before, calls.n.Load())
+}
+
+type countingHook struct {
+	n atomic.Int64
+}
+
+func (h *countingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h *countingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		h.n.Add(1)
+		return next(ctx, cmd)
+	}
+}
+
+func (h *countingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
@@ -0,0 +1,167 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) createCampaign(name string, discount int32, startsAt, endsAt time.Time, productIDs ...int64) *domain.Campaign {
+	campaign, err := s.ProductService.CreateCampaign(s.Ctx, &domain.Campaign{
+		Name:            name,
+		DiscountPercent: discount,
+		StartsAt:        startsAt,
+		EndsAt:          endsAt,
+		ProductIDs:      productIDs,
+	})
+	s.Require().NoError(err)
+
+	return campaign
+}
+
+func (s *IntegrationTestSuite) TestCampaign_DiscountsWithinWindow() {
+	lamp := s.createRelatedFixture("Desk Lamp", "Home")
+	rug := s.createRelatedFixture("Wool Rug", "Home")
+	now := time.Now()
+
+	s.createCampaign("Spring Sale", 20, now.Add(-time.Hour), now.Add(time.Hour), lamp)
+	s.createCampaign("Rug Week", 30, now.Add(time.Hour), now.Add(2*time.Hour), rug)
+
+	product, err := s.ProductService.FindByID(s.Ctx, lamp)
+	s.Require().NoError(err)
+	s.Require().NotNil(product.Promotion)
+	s.Require().Equal(int32(20), product.Promotion.DiscountPercent)
+	s.Require().Equal(int64(800), product.EffectivePrice())
+
+	product, err = s.ProductService.FindByID(s.Ctx, rug)
+	s.Require().NoError(err)
+	s.Require().Nil(product.Promotion, "a scheduled campaign must not discount yet")
+	s.Require().Equal(int64(1000), product.EffectivePrice())
+}
+
+func (s *IntegrationTestSuite) TestCampaign_BestDiscountWins() {
+	lamp := s.createRelatedFixture("Desk Lamp", "Home")
+	s.createRelatedFixture("Wool Rug", "Home")
+	now := time.Now()
+
+	s.createCampaign("Spring Sale", 10, now.Add(-time.Hour), now.Add(time.Hour), lamp)
+	best := s.createCampaign("Flash Sale", 25, now.Add(-time.Minute), now.Add(time.Minute), lamp)
+
+	promoted, err := s.ProductService.ListPromoted(s.Ctx, 10)
+	s.Require().NoError(err)
+	s.Require().Len(promoted, 1)
+	s.Require().Equal(lamp, promoted[0].ID)
+	s.Require().Equal(best.ID, promoted[0].Promotion.CampaignID)
+	s.Require().Equal(int64(750), promoted[0].EffectivePrice())
+}
+
+func (s *IntegrationTestSuite) TestCampaign_ReservationChargesPromotion() {
+	lamp := s.createRelatedFixture("Desk Lamp", "Home")
+	now := time.Now()
+
+	s.createCampaign("Spring Sale", 20, now.Add(-time.Hour), now.Add(time.Hour), lamp)
+
+	err := s.ProductService.ReserveProduct(s.Ctx, &domain.OrderCreatedEvent{
+		OrderID: 901,
+		UserID:  1,
+		Items:   []domain.OrderItemEvent{{ProductID: lamp, Quantity: 2}},
+	})
+	s.Require().NoError(err)
+
+	var payload []byte
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT payload FROM outbox WHERE aggregate_id = '901' AND event_type = 'InventoryReserved'").
+		Scan(&payload)
+	s.Require().NoError(err)
+
+	var envelope struct {
+		Payload domain.InventoryReservedEvent `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &envelope))
+	s.Require().Equal(int64(2*800), envelope.Payload.Amount)
+}
+
+func (s *IntegrationTestSuite) TestActivateCampaigns_EmitsEventsOnce() {
+	lamp := s.createRelatedFixture("Desk Lamp", "Home")
+	rug := s.createRelatedFixture("Wool Rug", "Home")
+	now := time.Now()
+
+	running := s.createCampaign("Spring Sale", 20, now.Add(-time.Hour), now.Add(time.Hour), lamp)
+	missed := s.createCampaign("Last Week", 15, now.Add(-2*time.Hour), now.Add(-time.Hour), rug)
+	s.createCampaign("Next Week", 15, now.Add(time.Hour), now.Add(2*time.Hour), rug)
+
+	// Cached before the campaign starts, the product must not keep its old
+	// price.
+	_, err := s.CachedProductService.FindByID(s.Ctx, lamp)
+	s.Require().NoError(err)
+
+	moved, err := s.CachedProductService.ActivateCampaigns(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().Len(moved, 2)
+
+	statuses := map[int64]domain.CampaignStatus{}
+	for _, c := range moved {
+		statuses[c.ID] = c.Status
+	}
+	s.Require().Equal(domain.CampaignActive, statuses[running.ID])
+	s.Require().Equal(domain.CampaignEnded, statuses[missed.ID])
+
+	cached, err := s.RedisInternalClient.Exists(s.Ctx, fmt.Sprintf("default:product:%d", lamp)).Result()
+	s.Require().NoError(err)
+	s.Require().Zero(cached)
+
+	moved, err = s.ProductService.ActivateCampaigns(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().Empty(moved)
+
+	var started, ended int
+	err = s.DbPool.QueryRow(s.Ctx, `
+		SELECT COUNT(*) FILTER (WHERE event_type = 'ProductPromotionStarted'),
+			COUNT(*) FILTER (WHERE event_type = 'ProductPromotionEnded')
+		FROM outbox
+		WHERE aggregate_type = 'Campaign'
+	`).Scan(&started, &ended)
+	s.Require().NoError(err)
+	s.Require().Equal(1, started)
+	s.Require().Equal(1, ended)
+}
+
+func (s *IntegrationTestSuite) TestCreateCampaign_Invalid() {
+	lamp := s.createRelatedFixture("Desk Lamp", "Home")
+	now := time.Now()
+
+	_, err := s.ProductService.CreateCampaign(s.Ctx, &domain.Campaign{
+		Name:            "Backwards",
+		DiscountPercent: 10,
+		StartsAt:        now.Add(time.Hour),
+		EndsAt:          now,
+		ProductIDs:      []int64{lamp},
+	})
+	s.Require().ErrorIs(err, domain.ErrInvalidCampaign)
+
+	_, err = s.ProductService.CreateCampaign(s.Ctx, &domain.Campaign{
+		Name:            "Everything Free",
+		DiscountPercent: 100,
+		StartsAt:        now,
+		EndsAt:          now.Add(time.Hour),
+		ProductIDs:      []int64{lamp},
+	})
+	s.Require().ErrorAs(err, new(validator.ValidationErrors))
+
+	_, err = s.ProductService.CreateCampaign(s.Ctx, &domain.Campaign{
+		Name:            "Ghost Sale",
+		DiscountPercent: 10,
+		StartsAt:        now,
+		EndsAt:          now.Add(time.Hour),
+		ProductIDs:      []int64{lamp, lamp + 1000},
+	})
+	s.Require().ErrorIs(err, repository.ErrProductNotFound)
+
+	campaigns, err := s.ProductService.ListCampaigns(s.Ctx, true, query.Page{})
+	s.Require().NoError(err)
+	s.Require().Empty(campaigns)
+}
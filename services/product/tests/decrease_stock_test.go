@@ -2,7 +2,10 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"sync"
+	"time"
 
 	"github.com/sakashimaa/go-pet-project/product/internal/domain"
 	"github.com/sakashimaa/go-pet-project/product/internal/repository"
@@ -22,9 +25,9 @@ func (s *IntegrationTestSuite) TestDecreaseStock_Success() {
 	s.Require().NoError(err)
 	s.Require().NotZero(id)
 
-	status, err := s.ProductService.DecreaseStock(s.Ctx, id, 5)
+	stock, err := s.ProductService.DecreaseStock(s.Ctx, id, 5)
 	s.Require().NoError(err)
-	s.Require().Equal(status, "success")
+	s.Require().Zero(stock)
 
 	productQuery := `
 		SELECT stock_quantity
@@ -40,9 +43,11 @@ func (s *IntegrationTestSuite) TestDecreaseStock_Success() {
 }
 
 func (s *IntegrationTestSuite) TestDecreaseStockInvalidInput_Failure() {
-	status, err := s.ProductService.DecreaseStock(s.Ctx, 999, 999)
+	_, err := s.ProductService.DecreaseStock(s.Ctx, 999, 999)
 	s.Require().Error(err)
-	s.Require().Empty(status)
+
+	_, err = s.ProductService.DecreaseStock(s.Ctx, 1, 0)
+	s.Require().ErrorIs(err, repository.ErrInvalidInput)
 
 	product := &domain.Product{
 		Name:          "A Great Chaos Vinyl",
@@ -57,14 +62,13 @@ func (s *IntegrationTestSuite) TestDecreaseStockInvalidInput_Failure() {
 	s.Require().NoError(err)
 	s.Require().NotZero(id)
 
-	status, err = s.ProductService.DecreaseStock(s.Ctx, id, 100)
+	_, err = s.ProductService.DecreaseStock(s.Ctx, id, 100)
 	s.Require().Error(err)
 	s.Require().ErrorIs(err, repository.ErrInsufficientStock)
-	s.Require().Equal("", status)
 
-	status, err = s.ProductService.DecreaseStock(s.Ctx, id, 5)
+	stock, err := s.ProductService.DecreaseStock(s.Ctx, id, 3)
 	s.Require().NoError(err)
-	s.Require().NotEmpty(status)
+	s.Require().Equal(product.StockQuantity-3, stock)
 
 	var quantity int64
 	stockQuantityQuery := `
@@ -75,7 +79,7 @@ func (s *IntegrationTestSuite) TestDecreaseStockInvalidInput_Failure() {
 	err = s.DbPool.QueryRow(s.Ctx, stockQuantityQuery, id).
 		Scan(&quantity)
 	s.Require().NoError(err)
-	s.Require().Equal(quantity, product.StockQuantity-5)
+	s.Require().Equal(quantity, product.StockQuantity-3)
 }
 
 func (s *IntegrationTestSuite) TestDecreaseStockConcurrentRaceCondition() {
@@ -141,8 +145,65 @@ func (s *IntegrationTestSuite) TestDecreaseStockContextCancelled() {
 	s.Require().NoError(err)
 	s.Require().NotZero(id)
 
-	status, err := s.ProductService.DecreaseStock(ctx, id, 5)
-	s.Require().Empty(status)
+	_, err = s.ProductService.DecreaseStock(ctx, id, 5)
 	s.Require().Error(err)
 	s.Require().ErrorIs(err, context.Canceled)
 }
+
+func (s *IntegrationTestSuite) TestDecreaseStock_PublishesStockChanged() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Item A", Price: 100, StockQuantity: 10})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.DecreaseStock(s.Ctx, id, 4)
+	s.Require().NoError(err)
+
+	var payload []byte
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT payload FROM outbox WHERE aggregate_id = $1 AND event_type = 'StockChanged'", fmt.Sprintf("%d", id)).
+		Scan(&payload)
+	s.Require().NoError(err)
+
+	var envelope struct {
+		Payload domain.StockChangedEvent `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &envelope))
+	s.Require().Equal(int64(-4), envelope.Payload.Delta)
+	s.Require().Equal(int64(6), envelope.Payload.StockQuantity)
+}
+
+func (s *IntegrationTestSuite) TestDecreaseStock_FailureWritesNothing() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Item A", Price: 100, StockQuantity: 2})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.DecreaseStock(s.Ctx, id, 3)
+	s.Require().ErrorIs(err, repository.ErrInsufficientStock)
+
+	var events int
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM outbox WHERE event_type = 'StockChanged'").Scan(&events)
+	s.Require().NoError(err)
+	s.Require().Zero(events)
+}
+
+// Every call, failed or not, has to hand its connection back; a leaked
+// transaction would exhaust the pool long before the loop ends.
+func (s *IntegrationTestSuite) TestDecreaseStock_ReleasesConnections() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Item A", Price: 100, StockQuantity: 100})
+	s.Require().NoError(err)
+
+	calls := int(s.DbPool.Config().MaxConns) * 3
+	for i := 0; i < calls; i++ {
+		ctx, cancel := context.WithTimeout(s.Ctx, 2*time.Second)
+		_, err := s.ProductService.DecreaseStock(ctx, id, 1)
+		cancel()
+		s.Require().NoError(err, "call %d", i)
+
+		_, err = s.ProductService.DecreaseStock(s.Ctx, id, 1_000)
+		s.Require().ErrorIs(err, repository.ErrInsufficientStock)
+	}
+
+	s.Require().Zero(s.DbPool.Stat().AcquiredConns())
+
+	var stock int64
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT stock_quantity FROM products WHERE id = $1", id).Scan(&stock)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(100-calls), stock)
+}
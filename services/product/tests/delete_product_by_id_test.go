@@ -51,7 +51,7 @@ func (s *IntegrationTestSuite) TestDeleteProductByIDCached() {
 	s.Require().NoError(err)
 	s.Require().NotZero(id)
 
-	val, err := s.RedisInternalClient.Get(s.Ctx, fmt.Sprintf("product:%d", id)).Result()
+	val, err := s.RedisInternalClient.Get(s.Ctx, fmt.Sprintf("default:product:%d", id)).Result()
 	s.Require().NoError(err)
 	s.Require().NotEmpty(val)
 
@@ -70,7 +70,7 @@ func (s *IntegrationTestSuite) TestDeleteProductByIDCached() {
 	s.Require().NoError(err)
 	s.Require().NotNil(deletedAt)
 
-	val, err = s.RedisInternalClient.Get(s.Ctx, fmt.Sprintf("product:%d", id)).Result()
+	val, err = s.RedisInternalClient.Get(s.Ctx, fmt.Sprintf("default:product:%d", id)).Result()
 	s.Require().Error(err)
 	s.Require().Empty(val)
 }
@@ -0,0 +1,85 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/pkg/lock"
+	"go.uber.org/zap"
+)
+
+func (s *IntegrationTestSuite) assertExclusive(first, second *lock.Locker, ttl time.Duration) {
+	held, err := first.Acquire(s.Ctx, "lock-test", ttl)
+	s.Require().NoError(err)
+
+	_, err = second.Acquire(s.Ctx, "lock-test", ttl)
+	s.Require().ErrorIs(err, lock.ErrNotAcquired, "only one holder at a time")
+
+	other, err := second.Acquire(s.Ctx, "lock-test-other", ttl)
+	s.Require().NoError(err, "names lock independently")
+	other.Release(s.Ctx)
+
+	held.Release(s.Ctx)
+	held.Release(s.Ctx)
+
+	taken, err := second.Acquire(s.Ctx, "lock-test", ttl)
+	s.Require().NoError(err, "another holder takes over after release")
+	taken.Release(s.Ctx)
+}
+
+func (s *IntegrationTestSuite) TestLock_PostgresExcludesOtherHolders() {
+	s.assertExclusive(
+		lock.NewPostgres(s.DbPool, zap.NewNop()),
+		lock.NewPostgres(s.DbPool, zap.NewNop()),
+		time.Second,
+	)
+}
+
+func (s *IntegrationTestSuite) TestLock_RedisExcludesOtherHolders() {
+	s.assertExclusive(
+		lock.NewRedis(s.RedisInternalClient, zap.NewNop()),
+		lock.NewRedis(s.RedisInternalClient, zap.NewNop()),
+		time.Second,
+	)
+}
+
+func (s *IntegrationTestSuite) TestLock_RedisRenewsPastTTL() {
+	locker := lock.NewRedis(s.RedisInternalClient, zap.NewNop())
+
+	held, err := locker.Acquire(s.Ctx, "lock-renew-test", 300*time.Millisecond)
+	s.Require().NoError(err)
+	defer held.Release(s.Ctx)
+
+	time.Sleep(time.Second)
+
+	_, err = locker.Acquire(s.Ctx, "lock-renew-test", 300*time.Millisecond)
+	s.Require().ErrorIs(err, lock.ErrNotAcquired, "renewal keeps the key alive")
+
+	select {
+	case <-held.Lost():
+		s.Fail("a renewed lock is not lost")
+	default:
+	}
+}
+
+func (s *IntegrationTestSuite) TestLock_RedisReportsLostLock() {
+	locker := lock.NewRedis(s.RedisInternalClient, zap.NewNop())
+
+	held, err := locker.Acquire(s.Ctx, "lock-lost-test", 300*time.Millisecond)
+	s.Require().NoError(err)
+	defer held.Release(s.Ctx)
+
+	// Someone else takes the lock over, as if it had expired.
+	s.Require().NoError(s.RedisInternalClient.Set(s.Ctx, "lock:lock-lost-test", "someone-else", time.Minute).Err())
+	defer s.RedisInternalClient.Del(s.Ctx, "lock:lock-lost-test")
+
+	select {
+	case <-held.Lost():
+	case <-time.After(2 * time.Second):
+		s.Fail("the holder should notice the lock is gone")
+	}
+
+	held.Release(s.Ctx)
+	owner, err := s.RedisInternalClient.Get(s.Ctx, "lock:lock-lost-test").Result()
+	s.Require().NoError(err)
+	s.Require().Equal("someone-else", owner, "release leaves the new holder's lock alone")
+}
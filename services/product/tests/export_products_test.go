@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+	"go.uber.org/zap"
+)
+
+func (s *IntegrationTestSuite) exportProducts(filter domain.ProductExportFilter) ([]domain.ProductExportRow, int) {
+	var (
+		rows   []domain.ProductExportRow
+		chunks int
+	)
+	err := s.ProductService.ExportProducts(s.Ctx, filter, func(page []domain.ProductExportRow) error {
+		rows = append(rows, page...)
+		chunks++
+		return nil
+	})
+	s.Require().NoError(err)
+
+	return rows, chunks
+}
+
+func (s *IntegrationTestSuite) TestExportProducts_IncludesStockAndPriceHistory() {
+	east := s.createWarehouse("EAST", nil)
+
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Pro-Ject Debut", Price: 40000, StockQuantity: 5, Category: "Audio > Turntables",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.AdjustStock(s.Ctx, &domain.StockAdjustment{
+		ProductID: id, WarehouseID: east.ID, Delta: 2, Reason: domain.AdjustRestock,
+	})
+	s.Require().NoError(err)
+
+	repo := repository.NewProductRepository(s.DbPool, zap.NewNop())
+	for _, price := range []int64{35000, 45000} {
+		s.Require().NoError(repo.Update(s.Ctx, id, &domain.UpdateProductInput{Price: &price}))
+	}
+
+	rows, _ := s.exportProducts(domain.ProductExportFilter{})
+	s.Require().Len(rows, 1)
+
+	row := rows[0]
+	s.Require().Equal(id, row.Product.ID)
+	s.Require().Equal(int64(45000), row.Product.Price)
+	s.Require().Equal(int64(7), row.Product.StockQuantity)
+	s.Require().Equal(map[string]int64{"MAIN": 5, "EAST": 2}, row.WarehouseStock)
+	s.Require().Equal([]string{"Audio", "Turntables"}, domain.CategoryPath(row.Product.Category))
+
+	s.Require().Equal(int64(35000), row.PriceHistory.Min)
+	s.Require().Equal(int64(45000), row.PriceHistory.Max)
+	s.Require().Equal(int64(2), row.PriceHistory.Changes)
+	s.Require().False(row.PriceHistory.ChangedAt.IsZero())
+}
+
+func (s *IntegrationTestSuite) TestExportProducts_FiltersAndSkipsDeleted() {
+	keep, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Rega Planar 1", Price: 30000, Category: "Audio"})
+	s.Require().NoError(err)
+	deleted, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Rega Planar 2", Price: 35000, Category: "Audio"})
+	s.Require().NoError(err)
+	_, err = s.ProductService.Create(s.Ctx, &domain.Product{Name: "Kindle Paperwhite", Price: 15000, Category: "Books"})
+	s.Require().NoError(err)
+	s.Require().NoError(s.ProductService.Delete(s.Ctx, deleted))
+
+	rows, _ := s.exportProducts(domain.ProductExportFilter{Category: "Audio"})
+	s.Require().Len(rows, 1)
+	s.Require().Equal(keep, rows[0].Product.ID)
+	s.Require().Empty(rows[0].WarehouseStock)
+	s.Require().Zero(rows[0].PriceHistory.Changes)
+	s.Require().True(rows[0].PriceHistory.ChangedAt.IsZero())
+
+	rows, _ = s.exportProducts(domain.ProductExportFilter{Category: "Audio", IncludeDeleted: true})
+	s.Require().Len(rows, 2)
+	s.Require().Equal(deleted, rows[1].Product.ID)
+	s.Require().False(rows[1].Product.DeletedAt.IsZero())
+}
+
+func (s *IntegrationTestSuite) TestExportProducts_PagesThroughLargeCatalogs() {
+	_, err := s.DbPool.Exec(s.Ctx, `
+		INSERT INTO products (name, description, price, stock_quantity, image_url, category)
+		SELECT 'Export product ' || i, '', 1000 + i, i, '', 'Bulk'
+		FROM generate_series(1, 1203) AS i
+	`)
+	s.Require().NoError(err)
+
+	rows, chunks := s.exportProducts(domain.ProductExportFilter{})
+	s.Require().Len(rows, 1203)
+	s.Require().Equal(3, chunks)
+
+	for i := 1; i < len(rows); i++ {
+		s.Require().Less(rows[i-1].Product.ID, rows[i].Product.ID)
+	}
+}
@@ -33,7 +33,7 @@ func (s *IntegrationTestSuite) TestFindById_Success() {
 	s.Require().Equal(created.ImageUrl, product.ImageUrl)
 	s.Require().Equal(created.Category, product.Category)
 
-	val, err := s.RedisInternalClient.Get(s.Ctx, fmt.Sprintf("product:%d", id)).Result()
+	val, err := s.RedisInternalClient.Get(s.Ctx, fmt.Sprintf("default:product:%d", id)).Result()
 	s.Require().NoError(err)
 	s.Require().NotEmpty(val)
 
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"github.com/go-playground/validator/v10"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) TestFindBySKU_Success() {
+	product := &domain.Product{
+		Name:          "Tascam Cassette Deck",
+		Price:         45000,
+		StockQuantity: 3,
+		Category:      "Audio",
+		SKU:           "tsc-202mk7",
+		EAN:           "4006381333931",
+	}
+
+	id, err := s.ProductService.Create(s.Ctx, product)
+	s.Require().NoError(err)
+
+	found, err := s.ProductService.FindBySKU(s.Ctx, "TSC-202MK7")
+	s.Require().NoError(err)
+	s.Require().Equal(id, found.ID)
+	s.Require().Equal("TSC-202MK7", found.SKU, "SKU should be stored normalized")
+	s.Require().Equal(product.EAN, found.EAN)
+
+	list, total, err := s.ProductService.List(s.Ctx, domain.ProductFilter{Limit: 10, Search: "4006381333931"})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), total, "Search should match the barcode")
+	s.Require().Equal(id, list[0].ID)
+}
+
+func (s *IntegrationTestSuite) TestFindBySKU_Failure() {
+	_, err := s.ProductService.FindBySKU(s.Ctx, "MISSING-SKU")
+	s.Require().ErrorIs(err, repository.ErrProductNotFound)
+
+	_, err = s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "First", Price: 10, Category: "Audio", SKU: "DUP-001",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Second", Price: 10, Category: "Audio", SKU: "dup-001",
+	})
+	s.Require().ErrorIs(err, repository.ErrSKUAlreadyExists)
+
+	_, err = s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Bad barcode", Price: 10, Category: "Audio", EAN: "4006381333932",
+	})
+	s.Require().ErrorAs(err, new(validator.ValidationErrors), "EAN check digit must be validated")
+}
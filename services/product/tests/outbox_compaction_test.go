@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
+	repository2 "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"go.uber.org/zap"
+)
+
+func (s *IntegrationTestSuite) TestOutboxCompaction_PublishesLatestPerAggregate() {
+	// The background processor has no compaction and would publish them all.
+	s.workerCancel()
+
+	outboxRepo := repository2.NewOutboxRepository(s.DbPool, zap.NewNop(), "product-service")
+
+	events := []struct {
+		productID int64
+		eventType string
+	}{
+		{5, "StockChanged"},
+		{5, "ProductCreated"},
+		{5, "StockChanged"},
+		{6, "StockChanged"},
+		{5, "StockChanged"},
+		{5, "ProductCreated"},
+	}
+	err := db.WithTx(s.Ctx, s.DbPool, func(tx pgx.Tx) error {
+		for i, e := range events {
+			err := outboxRepo.SaveOutboxEvent(s.Ctx, tx, &outboxDomain.OutboxEvent{
+				AggregateType: "Product",
+				AggregateID:   fmt.Sprintf("%d", e.productID),
+				EventType:     e.eventType,
+				Payload:       []byte(fmt.Sprintf(`{"event":%q,"payload":{"product_id":%d,"seq":%d}}`, e.eventType, e.productID, i)),
+				Topic:         "product_events",
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	s.Require().NoError(err)
+
+	processor := worker.NewOutboxProcessor(s.DbPool, outboxRepo, s.TestProducer, zap.NewNop(), worker.WithCompaction("StockChanged"))
+	s.Require().NoError(processor.ProcessBatch(s.Ctx))
+
+	rows, err := s.DbPool.Query(s.Ctx, `
+		SELECT id, aggregate_id, event_type, superseded_by, published_at IS NOT NULL
+		FROM outbox
+		ORDER BY id
+	`)
+	s.Require().NoError(err)
+	defer rows.Close()
+
+	type row struct {
+		id           int64
+		aggregateID  string
+		eventType    string
+		supersededBy *int64
+		done         bool
+	}
+	var got []row
+	for rows.Next() {
+		var r row
+		s.Require().NoError(rows.Scan(&r.id, &r.aggregateID, &r.eventType, &r.supersededBy, &r.done))
+		got = append(got, r)
+	}
+	s.Require().NoError(rows.Err())
+	s.Require().Len(got, len(events))
+
+	latest := got[4].id
+	for i, r := range got {
+		s.Require().True(r.done, "event %d is left pending", i)
+
+		switch i {
+		case 0, 2:
+			s.Require().NotNil(r.supersededBy, "event %d should be superseded", i)
+			s.Require().Equal(latest, *r.supersededBy)
+		default:
+			s.Require().Nil(r.supersededBy, "event %d should be published", i)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/pkg/lock"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"go.uber.org/zap"
+)
+
+func (s *IntegrationTestSuite) TestOutboxLeaderElection_SingleLeaderAndFailover() {
+	first := worker.NewLockElector(lock.NewPostgres(s.DbPool, zap.NewNop()), "leader-test", zap.NewNop())
+	second := worker.NewLockElector(lock.NewPostgres(s.DbPool, zap.NewNop()), "leader-test", zap.NewNop())
+
+	leading, err := first.Lead(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().True(leading)
+
+	leading, err = second.Lead(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().False(leading, "only one replica may lead")
+
+	leading, err = first.Lead(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().True(leading, "the leader keeps leading")
+
+	first.Resign(s.Ctx)
+
+	leading, err = second.Lead(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().True(leading, "another replica takes over after resignation")
+
+	second.Resign(s.Ctx)
+}
+
+func (s *IntegrationTestSuite) TestOutboxLeaderElection_IndependentNames() {
+	product := worker.NewLockElector(lock.NewPostgres(s.DbPool, zap.NewNop()), "product-service", zap.NewNop())
+	order := worker.NewLockElector(lock.NewPostgres(s.DbPool, zap.NewNop()), "order-service", zap.NewNop())
+	defer product.Resign(s.Ctx)
+	defer order.Resign(s.Ctx)
+
+	leading, err := product.Lead(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().True(leading)
+
+	leading, err = order.Lead(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().True(leading)
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/sakashimaa/go-pet-project/pkg/db"
+	outboxDomain "github.com/sakashimaa/go-pet-project/pkg/outbox/domain"
+	repository2 "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
+	"github.com/sakashimaa/go-pet-project/pkg/outbox/worker"
+	"github.com/sakashimaa/go-pet-project/pkg/testsuite"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"go.uber.org/zap"
+)
+
+const (
+	perfCatalogSize = 1_000
+
+	// Budgets for one call against a local Postgres. They leave room for a
+	// busy CI machine; PERF_BUDGET_SCALE stretches them further.
+	listProductsBudget = 30 * time.Millisecond
+	outboxBatchBudget  = 150 * time.Millisecond
+
+	outboxBatchRuns = 50
+	// Matches the outbox processor's default batch size.
+	outboxBatchSize = 50
+
+	// outboxHistorySize rows of published and failed events sit next to the
+	// large backlog, as after a long outage; PERF_OUTBOX_BACKLOG raises the
+	// backlog, e.g. to 1000000.
+	outboxHistorySize    = 200_000
+	defaultOutboxBacklog = 200_000
+	// A poll may get this much slower from a small to a large backlog.
+	outboxBacklogSlowdown = 2.0
+)
+
+var perfCategories = []string{"Audio", "Books", "Clothing", "Electronics", "Home"}
+
+func (s *IntegrationTestSuite) TestPerf_ListProducts() {
+	for i := range perfCatalogSize {
+		_, err := s.ProductService.Create(s.Ctx, &domain.Product{
+			Name:          fmt.Sprintf("Perf Product %04d", i),
+			Description:   "Seeded for the list latency budget",
+			Category:      perfCategories[i%len(perfCategories)],
+			Price:         int64(1_000 + i*37%50_000),
+			StockQuantity: int64(i % 7),
+			Attributes:    map[string]string{"color": []string{"black", "white", "red"}[i%3]},
+		})
+		s.Require().NoError(err)
+	}
+
+	filters := []struct {
+		name   string
+		filter domain.ProductFilter
+	}{
+		{"FirstPage", domain.ProductFilter{Limit: 20}},
+		{"DeepPage", domain.ProductFilter{Limit: 20, Offset: perfCatalogSize - 40}},
+		{"Category", domain.ProductFilter{Limit: 20, Categories: []string{"Audio", "Home"}}},
+		{"PriceRangeInStock", domain.ProductFilter{Limit: 20, PriceMin: 5_000, PriceMax: 20_000, InStockOnly: true}},
+		{"Search", domain.ProductFilter{Limit: 20, Search: "Product 04"}},
+		{"Attributes", domain.ProductFilter{Limit: 20, Attributes: map[string][]string{"color": {"red"}}}},
+		{"SortedByPrice", domain.ProductFilter{Limit: 20, Sort: domain.SortPriceDesc}},
+	}
+
+	for _, tc := range filters {
+		list := func() error {
+			_, _, err := s.ProductService.List(s.Ctx, tc.filter)
+			return err
+		}
+
+		s.RequireP95("ListProducts/"+tc.name, listProductsBudget, 200, list)
+		s.Benchmark("ListProducts/"+tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				if err := list(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func (s *IntegrationTestSuite) TestPerf_OutboxBatch() {
+	// The background processor would race the measured batches.
+	s.workerCancel()
+
+	outboxRepo := repository2.NewOutboxRepository(s.DbPool, zap.NewNop(), "product-service")
+
+	// Enough for the warm-up and measured batches, each a full one.
+	s.Require().NoError(s.fillOutbox(outboxRepo, (outboxBatchRuns+outboxBatchRuns/10)*outboxBatchSize))
+
+	s.RequireP95("OutboxBatch", outboxBatchBudget, outboxBatchRuns, func() error {
+		return s.OutboxProcessor.ProcessBatch(s.Ctx)
+	})
+
+	var unpublished int
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT count(*) FROM outbox WHERE published_at IS NULL").Scan(&unpublished)
+	s.Require().NoError(err)
+	s.Require().Zero(unpublished, "every measured run should have published a full batch")
+
+	s.Benchmark("OutboxBatch", func(b *testing.B) {
+		for b.Loop() {
+			b.StopTimer()
+			if err := s.fillOutbox(outboxRepo, outboxBatchSize); err != nil {
+				b.Fatal(err)
+			}
+			b.StartTimer()
+
+			if err := s.OutboxProcessor.ProcessBatch(s.Ctx); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestPerf_OutboxLargeBacklog checks that a batch costs about the same with
+// a handful of pending events as with a large backlog behind it.
+func (s *IntegrationTestSuite) TestPerf_OutboxLargeBacklog() {
+	if testing.Short() {
+		s.T().Skip("filling a large outbox takes a while")
+	}
+
+	s.workerCancel()
+
+	backlog := defaultOutboxBacklog
+	if n, err := strconv.Atoi(os.Getenv("PERF_OUTBOX_BACKLOG")); err == nil && n > 0 {
+		backlog = n
+	}
+
+	runs := outboxBatchRuns + outboxBatchRuns/10
+
+	s.Require().NoError(s.bulkFillOutbox(outboxDomain.StatusPublished, outboxHistorySize))
+	s.Require().NoError(s.bulkFillOutbox(outboxDomain.StatusFailed, outboxHistorySize))
+	s.Require().NoError(s.bulkFillOutbox(outboxDomain.StatusPending, runs*outboxBatchSize))
+	_, err := s.DbPool.Exec(s.Ctx, "ANALYZE outbox")
+	s.Require().NoError(err)
+
+	batch := func() error {
+		return s.OutboxProcessor.ProcessBatch(s.Ctx)
+	}
+
+	small, err := testsuite.Measure(outboxBatchRuns/10, outboxBatchRuns, batch)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.bulkFillOutbox(outboxDomain.StatusPending, backlog))
+	_, err = s.DbPool.Exec(s.Ctx, "ANALYZE outbox")
+	s.Require().NoError(err)
+
+	large, err := testsuite.Measure(outboxBatchRuns/10, outboxBatchRuns, batch)
+	s.Require().NoError(err)
+
+	smallP95, largeP95 := small.Percentile(95), large.Percentile(95)
+	s.T().Logf("OutboxBatch p95: %s with an empty backlog, %s with %d pending", smallP95, largeP95, backlog)
+
+	limit := time.Duration(float64(outboxBatchBudget) * testsuite.BudgetScale())
+	s.Require().LessOrEqual(largeP95, limit, "p95 latency %s is over the %s budget", largeP95, limit)
+	s.Require().LessOrEqual(
+		float64(largeP95),
+		float64(smallP95)*outboxBacklogSlowdown+float64(5*time.Millisecond),
+		"a large backlog slowed batches from %s to %s", smallP95, largeP95,
+	)
+
+	s.Benchmark("OutboxBatch/LargeBacklog", func(b *testing.B) {
+		for b.Loop() {
+			if err := batch(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// bulkFillOutbox inserts events rows in one statement, with status and the
+// columns that go with it set.
+func (s *IntegrationTestSuite) bulkFillOutbox(status string, events int) error {
+	_, err := s.DbPool.Exec(s.Ctx, `
+		INSERT INTO outbox (aggregate_type, aggregate_id, event_type, payload, topic, status, published_at, attempts)
+		SELECT 'Product', g::text, 'ProductCreated',
+			jsonb_build_object('event', 'ProductCreated', 'payload', jsonb_build_object('product_id', g)),
+			'product_events',
+			$1,
+			CASE WHEN $1 = 'published' THEN NOW() END,
+			CASE WHEN $1 = 'failed' THEN $3::int ELSE 0 END
+		FROM generate_series(1, $2::int) AS g
+	`, status, events, repository2.MaxAttempts)
+
+	return err
+}
+
+// fillOutbox returns its error rather than failing the test, as benchmarks
+// call it from their own goroutine.
+func (s *IntegrationTestSuite) fillOutbox(outboxRepo worker.OutboxRepository, events int) error {
+	return db.WithTx(s.Ctx, s.DbPool, func(tx pgx.Tx) error {
+		for i := range events {
+			err := outboxRepo.SaveOutboxEvent(s.Ctx, tx, &outboxDomain.OutboxEvent{
+				AggregateType: "Product",
+				AggregateID:   fmt.Sprintf("%d", i),
+				EventType:     "ProductCreated",
+				Payload:       []byte(fmt.Sprintf(`{"product_id":%d,"event":"ProductCreated"}`, i)),
+				Topic:         "product_events",
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
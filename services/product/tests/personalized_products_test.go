@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"fmt"
+	"time"
+
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) payOrder(orderID, userID int64, items map[int64]int32) {
+	event := &generalDomain.OrderPaidEvent{OrderID: orderID, UserID: userID, PaidAt: time.Now()}
+	for productID, quantity := range items {
+		event.Items = append(event.Items, generalDomain.OrderItem{OrderID: orderID, ProductID: productID, Quantity: quantity})
+	}
+
+	s.Require().NoError(s.ProductService.RecordAffinity(s.Ctx, event))
+}
+
+func (s *IntegrationTestSuite) TestGetPersonalized_RanksBoughtCategoriesFirst() {
+	headphones := s.createRelatedFixture("Wireless Headphones", "Audio")
+	speaker := s.createRelatedFixture("Bookshelf Speaker", "Audio")
+	novel := s.createRelatedFixture("Paperback Novel", "Books")
+	kettle := s.createRelatedFixture("Electric Kettle", "Kitchen")
+
+	s.payOrder(1, 42, map[int64]int32{novel: 1})
+	s.payOrder(2, 42, map[int64]int32{headphones: 2})
+
+	personalized, err := s.CachedProductService.GetPersonalized(s.Ctx, 42, 10)
+	s.Require().NoError(err)
+	s.Require().Len(personalized, 4)
+	s.Require().ElementsMatch([]int64{headphones, speaker}, []int64{personalized[0].ID, personalized[1].ID})
+	s.Require().Equal(novel, personalized[2].ID)
+	s.Require().Equal(kettle, personalized[3].ID)
+
+	cached, err := s.RedisInternalClient.Exists(s.Ctx, "default:user:42:personalized:10").Result()
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), cached)
+}
+
+func (s *IntegrationTestSuite) TestRecordAffinity_RedeliveryCountedOnce() {
+	novel := s.createRelatedFixture("Paperback Novel", "Books")
+	s.createRelatedFixture("Electric Kettle", "Kitchen")
+
+	s.payOrder(1, 42, map[int64]int32{novel: 3})
+	s.payOrder(1, 42, map[int64]int32{novel: 3})
+
+	var score int64
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT score FROM user_category_affinity WHERE user_id = 42 AND category = 'Books'").Scan(&score)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(3), score)
+}
+
+func (s *IntegrationTestSuite) TestGetPersonalized_WithoutHistory() {
+	for i := range 3 {
+		s.createRelatedFixture(fmt.Sprintf("Product %d", i), "General")
+	}
+
+	personalized, err := s.ProductService.GetPersonalized(s.Ctx, 7, 0)
+	s.Require().NoError(err)
+	s.Require().Len(personalized, 3)
+
+	_, err = s.ProductService.GetPersonalized(s.Ctx, 0, 5)
+	s.Require().ErrorIs(err, repository.ErrInvalidInput)
+}
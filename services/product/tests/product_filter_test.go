@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+)
+
+func (s *IntegrationTestSuite) seedFacetProducts() {
+	products := []domain.Product{
+		{
+			Name:          "Studio Headphones",
+			Price:         20000,
+			StockQuantity: 4,
+			Category:      "Audio",
+			Attributes:    map[string]string{"color": "black", "wireless": "yes"},
+		},
+		{
+			Name:          "Travel Headphones",
+			Price:         12000,
+			StockQuantity: 0,
+			Category:      "Audio",
+			Attributes:    map[string]string{"color": "white", "wireless": "yes"},
+		},
+		{
+			Name:          "Desk Speaker",
+			Price:         8000,
+			StockQuantity: 7,
+			Category:      "Audio",
+			Attributes:    map[string]string{"color": "red", "wireless": "no"},
+		},
+		{
+			Name:          "Paperback Novel",
+			Price:         1500,
+			StockQuantity: 20,
+			Category:      "Books",
+		},
+	}
+
+	for i := range products {
+		id, err := s.ProductService.Create(s.Ctx, &products[i])
+		s.Require().NoError(err)
+		s.Require().NotZero(id)
+	}
+}
+
+func (s *IntegrationTestSuite) TestProductList_Facets() {
+	s.seedFacetProducts()
+
+	list, total, err := s.ProductService.List(s.Ctx, domain.ProductFilter{
+		Limit:       10,
+		Categories:  []string{"Audio"},
+		PriceMin:    5000,
+		PriceMax:    25000,
+		InStockOnly: true,
+		Attributes:  map[string][]string{"color": {"black", "red"}},
+		Sort:        domain.SortPriceAsc,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(2), total)
+	s.Require().Len(list, 2)
+	s.Require().Equal("Desk Speaker", list[0].Name)
+	s.Require().Equal("Studio Headphones", list[1].Name)
+	s.Require().Equal("red", list[0].Attributes["color"])
+}
+
+func (s *IntegrationTestSuite) TestProductList_SortAndPagination() {
+	s.seedFacetProducts()
+
+	list, total, err := s.ProductService.List(s.Ctx, domain.ProductFilter{
+		Limit:  2,
+		Offset: 1,
+		Sort:   domain.SortPriceDesc,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(4), total)
+	s.Require().Len(list, 2)
+	s.Require().Equal("Travel Headphones", list[0].Name)
+	s.Require().Equal("Desk Speaker", list[1].Name)
+}
+
+func (s *IntegrationTestSuite) TestProductList_InvalidPriceRange() {
+	_, _, err := s.ProductService.List(s.Ctx, domain.ProductFilter{
+		Limit:    10,
+		PriceMin: 500,
+		PriceMax: 100,
+	})
+	s.Require().Error(err)
+}
+
+func (s *IntegrationTestSuite) TestProductList_LowStock() {
+	s.seedFacetProducts()
+
+	list, total, err := s.ProductService.List(s.Ctx, domain.ProductFilter{
+		Limit:       10,
+		InStockOnly: true,
+		StockMax:    7,
+		Sort:        domain.SortStockAsc,
+	})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(2), total)
+	s.Require().Equal("Studio Headphones", list[0].Name)
+	s.Require().Equal("Desk Speaker", list[1].Name)
+}
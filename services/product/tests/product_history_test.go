@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) TestProductHistory_RecordsMutations() {
+	ctx := domain.WithActor(s.Ctx, "user:42")
+
+	id, err := s.ProductService.Create(ctx, &domain.Product{
+		Name: "Technics SL-1200", Price: 70000, StockQuantity: 10, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.DecreaseStock(ctx, id, 3)
+	s.Require().NoError(err)
+	s.Require().NoError(s.ProductService.Delete(ctx, id))
+	_, err = s.ProductService.Restore(ctx, id)
+	s.Require().NoError(err)
+
+	history, err := s.ProductService.GetHistory(s.Ctx, id, query.Page{})
+	s.Require().NoError(err)
+	s.Require().Len(history, 4)
+
+	actions := make([]domain.RevisionAction, 0, len(history))
+	for _, revision := range history {
+		s.Require().Equal("user:42", revision.Actor)
+		actions = append(actions, revision.Action)
+	}
+	s.Require().Equal([]domain.RevisionAction{
+		domain.RevisionRestored,
+		domain.RevisionDeleted,
+		domain.RevisionStockChanged,
+		domain.RevisionCreated,
+	}, actions)
+
+	stock := history[2].Changes["stock_quantity"]
+	s.Require().EqualValues(10, stock.From)
+	s.Require().EqualValues(7, stock.To)
+
+	created := history[3].Changes["name"]
+	s.Require().Nil(created.From)
+	s.Require().Equal("Technics SL-1200", created.To)
+}
+
+func (s *IntegrationTestSuite) TestProductHistory_DefaultsToSystemActor() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Thorens TD 124", Price: 50000, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	history, err := s.ProductService.GetHistory(s.Ctx, id, query.Page{})
+	s.Require().NoError(err)
+	s.Require().Len(history, 1)
+	s.Require().Equal(domain.ActorSystem, history[0].Actor)
+}
+
+func (s *IntegrationTestSuite) TestProductHistory_InvalidID() {
+	_, err := s.ProductService.GetHistory(s.Ctx, 0, query.Page{})
+	s.Require().ErrorIs(err, repository.ErrInvalidInput)
+}
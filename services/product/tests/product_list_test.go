@@ -32,7 +32,7 @@ func (s *IntegrationTestSuite) TestProductList_Success() {
 		s.Require().NotZero(id)
 	}
 
-	productsList, ttl, err := s.CachedProductService.List(s.Ctx, 10, 0, "")
+	productsList, ttl, err := s.CachedProductService.List(s.Ctx, domain.ProductFilter{Limit: 10})
 	s.Require().NoError(err)
 	s.Require().Equal(int(ttl), len(productsDataSet))
 	s.Require().Equal(len(productsDataSet), len(productsList))
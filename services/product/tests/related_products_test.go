@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"fmt"
+
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) createRelatedFixture(name, category string) int64 {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name:          name,
+		Price:         1000,
+		StockQuantity: 100,
+		Category:      category,
+	})
+	s.Require().NoError(err)
+
+	return id
+}
+
+func (s *IntegrationTestSuite) TestGetRelated_CopurchasedFirst() {
+	camera := s.createRelatedFixture("Mirrorless Camera", "Photo")
+	lens := s.createRelatedFixture("Prime Lens", "Photo")
+	tripod := s.createRelatedFixture("Travel Tripod", "Photo")
+	card := s.createRelatedFixture("Memory Card", "Storage")
+	s.createRelatedFixture("Garden Hose", "Garden")
+
+	orders := [][]int64{
+		{camera, card},
+		{camera, card, lens},
+		{camera, card},
+	}
+	for i, items := range orders {
+		event := &domain.OrderCreatedEvent{OrderID: int64(i + 1), UserID: 1}
+		for _, productID := range items {
+			event.Items = append(event.Items, domain.OrderItemEvent{ProductID: productID, Quantity: 1})
+		}
+
+		s.Require().NoError(s.ProductService.ReserveProduct(s.Ctx, event))
+	}
+
+	pairs, err := s.ProductService.RebuildCopurchases(s.Ctx)
+	s.Require().NoError(err)
+	s.Require().NotZero(pairs)
+
+	related, err := s.CachedProductService.GetRelated(s.Ctx, camera, 10)
+	s.Require().NoError(err)
+	s.Require().Len(related, 3)
+	s.Require().Equal(card, related[0].ID)
+	s.Require().Equal(lens, related[1].ID)
+	s.Require().Equal(tripod, related[2].ID)
+
+	cached, err := s.RedisInternalClient.Exists(s.Ctx, fmt.Sprintf("default:product:%d:related:10", camera)).Result()
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), cached)
+}
+
+func (s *IntegrationTestSuite) TestGetRelated_SameCategoryFallback() {
+	first := s.createRelatedFixture("Oak Desk", "Furniture")
+	second := s.createRelatedFixture("Office Chair", "Furniture")
+	s.createRelatedFixture("Coffee Beans", "Grocery")
+
+	related, err := s.ProductService.GetRelated(s.Ctx, first, 0)
+	s.Require().NoError(err)
+	s.Require().Len(related, 1)
+	s.Require().Equal(second, related[0].ID)
+}
+
+func (s *IntegrationTestSuite) TestGetRelated_NotFound() {
+	_, err := s.ProductService.GetRelated(s.Ctx, 999999, 5)
+	s.Require().ErrorIs(err, repository.ErrProductNotFound)
+}
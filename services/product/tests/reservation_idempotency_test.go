@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"sync"
+
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+)
+
+func (s *IntegrationTestSuite) stockOf(productID int64) int64 {
+	var stock int64
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT stock_quantity FROM products WHERE id = $1", productID).Scan(&stock)
+	s.Require().NoError(err)
+
+	return stock
+}
+
+func (s *IntegrationTestSuite) countReplies(orderID string) int {
+	var count int
+	err := s.DbPool.QueryRow(s.Ctx, "SELECT COUNT(*) FROM outbox WHERE aggregate_id = $1 AND aggregate_type = 'Inventory'", orderID).
+		Scan(&count)
+	s.Require().NoError(err)
+
+	return count
+}
+
+func (s *IntegrationTestSuite) TestReserveProduct_RedeliveryTakesStockOnce() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Item A", Price: 100, StockQuantity: 10})
+	s.Require().NoError(err)
+
+	event := &domain.OrderCreatedEvent{
+		OrderID: 701,
+		UserID:  1,
+		Items:   []domain.OrderItemEvent{{ProductID: id, Quantity: 3}},
+	}
+
+	s.Require().NoError(s.ProductService.ReserveProduct(s.Ctx, event))
+	s.Require().NoError(s.ProductService.ReserveProduct(s.Ctx, event), "A redelivery is acknowledged")
+
+	s.Require().Equal(int64(7), s.stockOf(id))
+	s.Require().Equal(1, s.countReplies("701"))
+}
+
+func (s *IntegrationTestSuite) TestReserveProduct_ConcurrentRedeliveryTakesStockOnce() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Item A", Price: 100, StockQuantity: 50})
+	s.Require().NoError(err)
+
+	event := &domain.OrderCreatedEvent{
+		OrderID: 702,
+		UserID:  1,
+		Items:   []domain.OrderItemEvent{{ProductID: id, Quantity: 4}},
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.ProductService.ReserveProduct(s.Ctx, event)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		s.Require().NoError(err)
+	}
+	s.Require().Equal(int64(46), s.stockOf(id))
+	s.Require().Equal(1, s.countReplies("702"))
+}
+
+func (s *IntegrationTestSuite) TestReserveProduct_PartialRedeliveryTakesNothingMore() {
+	idA, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Item A", Price: 100, StockQuantity: 10})
+	s.Require().NoError(err)
+	idB, err := s.ProductService.Create(s.Ctx, &domain.Product{Name: "Item B", Price: 100, StockQuantity: 1})
+	s.Require().NoError(err)
+
+	event := &domain.OrderCreatedEvent{
+		OrderID: 703,
+		UserID:  1,
+		Items: []domain.OrderItemEvent{
+			{ProductID: idB, Quantity: 5},
+			{ProductID: idA, Quantity: 2},
+		},
+	}
+	s.Require().NoError(s.ProductService.ReserveProduct(s.Ctx, event))
+
+	// Item B is restocked before the redelivery; the order still only gets
+	// what the first delivery reserved.
+	_, err = s.DbPool.Exec(s.Ctx, "UPDATE products SET stock_quantity = 10 WHERE id = $1", idB)
+	s.Require().NoError(err)
+	_, err = s.DbPool.Exec(s.Ctx, "UPDATE warehouse_stock SET quantity = 10 WHERE product_id = $1", idB)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.ProductService.ReserveProduct(s.Ctx, event))
+
+	s.Require().Equal(int64(8), s.stockOf(idA))
+	s.Require().Equal(int64(10), s.stockOf(idB))
+	s.Require().Equal(1, s.countReplies("703"))
+}
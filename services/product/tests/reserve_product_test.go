@@ -3,6 +3,7 @@ package tests
 import (
 	"context"
 	"crypto/rand"
+	"encoding/json"
 	"fmt"
 	"math/big"
 	"time"
@@ -100,7 +101,7 @@ func (s *IntegrationTestSuite) TestReserveProduct_InsufficientStock_Fail() {
 	s.Require().Equal(int64(2), stockQuantity, "Stock should not change on failure")
 }
 
-func (s *IntegrationTestSuite) TestReserveProduct_Atomicity_Rollback() {
+func (s *IntegrationTestSuite) TestReserveProduct_PartialReservation() {
 	prodA := &domain.Product{Name: "Item A", Price: 10, StockQuantity: 100}
 	idA, _ := s.ProductService.Create(s.Ctx, prodA)
 
@@ -116,13 +117,56 @@ func (s *IntegrationTestSuite) TestReserveProduct_Atomicity_Rollback() {
 		},
 	})
 
-	s.Require().Error(err)
+	s.Require().NoError(err)
 
-	var stockA int64
+	var stockA, stockB int64
 	err = s.DbPool.QueryRow(s.Ctx, "SELECT stock_quantity FROM products WHERE id = $1", idA).
 		Scan(&stockA)
 	s.Require().NoError(err)
-	s.Require().Equal(int64(100), stockA)
+	s.Require().Equal(int64(95), stockA, "Available item should be reserved")
+
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT stock_quantity FROM products WHERE id = $1", idB).
+		Scan(&stockB)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), stockB, "Unavailable item should keep its stock")
+
+	var eventType string
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT event_type FROM outbox WHERE aggregate_id = '888' AND aggregate_type = 'Inventory'").
+		Scan(&eventType)
+	s.Require().NoError(err)
+	s.Require().Equal("InventoryPartiallyReserved", eventType)
+}
+
+func (s *IntegrationTestSuite) TestReserveProduct_ChargesManualOrderPrices() {
+	prodA := &domain.Product{Name: "Item A", Price: 1000, StockQuantity: 10}
+	idA, _ := s.ProductService.Create(s.Ctx, prodA)
+
+	prodB := &domain.Product{Name: "Item B", Price: 500, StockQuantity: 10}
+	idB, _ := s.ProductService.Create(s.Ctx, prodB)
+
+	err := s.ProductService.ReserveProduct(s.Ctx, &domain.OrderCreatedEvent{
+		OrderID: 889,
+		UserID:  1,
+		Items: []domain.OrderItemEvent{
+			{ProductID: idA, Quantity: 2, PriceOverride: &domain.PriceOverride{Price: 800}},
+			{ProductID: idB, Quantity: 1},
+		},
+		DeliveryFee: 300,
+		Discount:    100,
+	})
+	s.Require().NoError(err)
+
+	var payload []byte
+	err = s.DbPool.QueryRow(s.Ctx, "SELECT payload FROM outbox WHERE aggregate_id = '889' AND event_type = 'InventoryReserved'").
+		Scan(&payload)
+	s.Require().NoError(err)
+
+	var envelope struct {
+		Payload domain.InventoryReservedEvent `json:"payload"`
+	}
+	s.Require().NoError(json.Unmarshal(payload, &envelope))
+	// 2 x 800 overridden, 500 at the catalog price, less 100, plus delivery.
+	s.Require().Equal(int64(2*800+500-100+300), envelope.Payload.Amount)
 }
 
 func (s *IntegrationTestSuite) TestReserveProduct_CancelledContext() {
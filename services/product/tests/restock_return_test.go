@@ -0,0 +1,42 @@
+package tests
+
+import (
+	"time"
+
+	generalDomain "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+)
+
+func (s *IntegrationTestSuite) TestRestockReturn_AddsItemsToDefaultWarehouse() {
+	s.createWarehouse("EAST", nil)
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Marantz 2270", Price: 80000, StockQuantity: 2, Category: "Audio",
+	})
+	s.Require().NoError(err)
+	gone, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Sansui AU-717", Price: 60000, StockQuantity: 1, Category: "Audio",
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.ProductService.Delete(s.Ctx, gone))
+
+	err = s.ProductService.RestockReturn(s.Ctx, &generalDomain.ReturnApprovedEvent{
+		ReturnID: 7,
+		OrderID:  42,
+		UserID:   999,
+		Items: []generalDomain.OrderItem{
+			{ProductID: id, Quantity: 3},
+			{ProductID: gone, Quantity: 1},
+		},
+		Amount:     240000,
+		ApprovedAt: time.Now(),
+	})
+	s.Require().NoError(err)
+
+	s.Require().Equal(map[string]int64{"MAIN": 5}, s.stockByWarehouse(id))
+
+	history, err := s.ProductService.GetHistory(s.Ctx, id, query.Page{})
+	s.Require().NoError(err)
+	s.Require().Equal(domain.RevisionStockAdjusted, history[0].Action)
+	s.Require().Equal(string(domain.AdjustCustomerReturn), history[0].Changes["reason"].To)
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"errors"
+
+	"github.com/sakashimaa/go-pet-project/pkg/query"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) TestRestoreProduct_Success() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Revox B77", Price: 90000, StockQuantity: 1, Category: "Audio", SKU: "RVX-B77",
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.ProductService.Delete(s.Ctx, id))
+
+	deleted, total, err := s.ProductService.ListDeleted(s.Ctx, query.Page{})
+	s.Require().NoError(err)
+	s.Require().Equal(int64(1), total)
+	s.Require().Equal(id, deleted[0].ID)
+	s.Require().False(deleted[0].DeletedAt.IsZero())
+
+	restored, err := s.ProductService.Restore(s.Ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal("Revox B77", restored.Name)
+
+	_, err = s.ProductService.FindByID(s.Ctx, id)
+	s.Require().NoError(err)
+
+	_, total, err = s.ProductService.ListDeleted(s.Ctx, query.Page{})
+	s.Require().NoError(err)
+	s.Require().Zero(total)
+}
+
+func (s *IntegrationTestSuite) TestRestoreProduct_NotDeleted() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Nakamichi Dragon", Price: 120000, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.Restore(s.Ctx, id)
+	s.Require().ErrorIs(err, repository.ErrProductNotFound)
+}
+
+func (s *IntegrationTestSuite) TestSoftDeletedProduct_FreesNameAndSKU() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Studer A80", Price: 150000, Category: "Audio", SKU: "STD-A80",
+	})
+	s.Require().NoError(err)
+	s.Require().NoError(s.ProductService.Delete(s.Ctx, id))
+
+	_, err = s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Studer A80", Price: 160000, Category: "Audio", SKU: "STD-A80",
+	})
+	s.Require().NoError(err, "deleted rows must not block the name or sku")
+
+	_, err = s.ProductService.Restore(s.Ctx, id)
+	s.Require().True(
+		errors.Is(err, repository.ErrProductAlreadyExists) || errors.Is(err, repository.ErrSKUAlreadyExists),
+		"restore must not shadow the active product, got %v", err,
+	)
+}
@@ -0,0 +1,93 @@
+package tests
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sakashimaa/go-pet-project/pkg/eventmeta"
+	"github.com/sakashimaa/go-pet-project/pkg/servicestatus"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	pb "github.com/sakashimaa/go-pet-project/proto/status"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *IntegrationTestSuite) TestServiceStatus_ReportsOutboxBacklog() {
+	// Pending events would otherwise be published under the test.
+	s.workerCancel()
+
+	s.Require().NoError(s.bulkFillOutbox("pending", 3))
+	s.Require().NoError(s.bulkFillOutbox("failed", 2))
+	s.Require().NoError(s.bulkFillOutbox("published", 4))
+
+	server := servicestatus.New("product-service", zap.NewNop(), servicestatus.WithOutbox(s.DbPool))
+
+	res, err := server.GetStatus(s.Ctx, &pb.GetStatusRequest{FailedLimit: 1})
+	s.Require().NoError(err)
+	s.Require().Equal("product-service", res.Service)
+	s.Require().Empty(res.ConsumerGroups)
+
+	outbox := res.Outbox
+	s.Require().NotNil(outbox)
+	s.Require().Empty(outbox.Error)
+	s.Require().Len(outbox.Topics, 1)
+	s.Require().Equal("product_events", outbox.Topics[0].Topic)
+	s.Require().EqualValues(3, outbox.Topics[0].Pending)
+	s.Require().EqualValues(2, outbox.Topics[0].Failed)
+	s.Require().GreaterOrEqual(outbox.Topics[0].OldestPendingAgeSeconds, 0.0)
+
+	s.Require().Len(outbox.RecentFailures, 1)
+	s.Require().Equal("ProductCreated", outbox.RecentFailures[0].EventType)
+}
+
+func (s *IntegrationTestSuite) TestServiceStatus_EmptyOutbox() {
+	s.workerCancel()
+
+	server := servicestatus.New("product-service", zap.NewNop(), servicestatus.WithOutbox(s.DbPool))
+
+	res, err := server.GetStatus(s.Ctx, &pb.GetStatusRequest{})
+	s.Require().NoError(err)
+	s.Require().NotNil(res.Outbox)
+	s.Require().Empty(res.Outbox.Error)
+	s.Require().Empty(res.Outbox.Topics)
+	s.Require().Empty(res.Outbox.RecentFailures)
+}
+
+func (s *IntegrationTestSuite) TestServiceStatus_TraceEvents() {
+	id := s.createRelatedFixture("Desk Lamp", "Home")
+
+	// The reservation handles an OrderCreated event, so it joins that flow.
+	correlationID := uuid.NewString()
+	ctx := eventmeta.WithIncoming(s.Ctx, eventmeta.Metadata{EventID: correlationID, CorrelationID: correlationID})
+
+	err := s.ProductService.ReserveProduct(ctx, &domain.OrderCreatedEvent{
+		OrderID: 4242,
+		UserID:  1,
+		Items:   []domain.OrderItemEvent{{ProductID: id, Quantity: 1}},
+	})
+	s.Require().NoError(err)
+
+	server := servicestatus.New("product-service", zap.NewNop(), servicestatus.WithOutbox(s.DbPool))
+	req := &pb.TraceEventsRequest{AggregateType: "Order", AggregateId: "4242", CorrelationIds: []string{correlationID}}
+
+	var event *pb.TracedEvent
+	s.Require().Eventually(func() bool {
+		res, err := server.TraceEvents(s.Ctx, req)
+		if err != nil || len(res.Events) != 1 {
+			return false
+		}
+
+		event = res.Events[0]
+		return event.Status == "published"
+	}, 10*time.Second, 100*time.Millisecond)
+
+	s.Require().Equal("InventoryReserved", event.EventType)
+	s.Require().Equal(correlationID, event.CorrelationId)
+	s.Require().Equal(correlationID, event.CausationId)
+	s.Require().NotEmpty(event.PublishedAt)
+	s.Require().GreaterOrEqual(event.Offset, int64(0), "the published offset should be recorded")
+
+	_, err = server.TraceEvents(s.Ctx, &pb.TraceEventsRequest{CorrelationIds: []string{"not-a-uuid"}})
+	s.Require().Equal(codes.InvalidArgument, status.Code(err))
+}
@@ -5,8 +5,6 @@ import (
 	"testing"
 	"time"
 
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/redis/go-redis/v9"
 	kafka2 "github.com/sakashimaa/go-pet-project/pkg/kafka"
 	repository2 "github.com/sakashimaa/go-pet-project/pkg/outbox/repository"
@@ -62,19 +60,31 @@ func (s *IntegrationTestSuite) TearDownSuite() {
 
 func (s *IntegrationTestSuite) SetupTest() {
 	s.BaseSuite.TruncateTable("products")
+	s.BaseSuite.TruncateTable("product_revisions")
+	s.BaseSuite.TruncateTable("stock_allocations")
+	s.BaseSuite.TruncateTable("reservations")
+	s.BaseSuite.TruncateTable("user_category_affinity")
+	s.BaseSuite.TruncateTable("affinity_orders")
+	s.BaseSuite.TruncateTable("campaigns")
+
+	// Keep the migration-seeded default warehouse, drop the ones tests add.
+	_, err := s.DbPool.Exec(s.Ctx, "DELETE FROM warehouses WHERE code <> 'MAIN'")
+	s.Require().NoError(err)
 	s.BaseSuite.TruncateTable("outbox")
 
-	err := s.RedisInternalClient.FlushAll(s.Ctx).Err()
+	err = s.RedisInternalClient.FlushAll(s.Ctx).Err()
 	s.Require().NoError(err)
 
 	logger := zap.NewNop()
 	productRepo := repository.NewProductRepository(s.DbPool, logger)
-	outboxRepo := repository2.NewOutboxRepository(s.DbPool, logger)
+	warehouseRepo := repository.NewWarehouseRepository(s.DbPool, logger)
+	campaignRepo := repository.NewCampaignRepository(s.DbPool, logger)
+	outboxRepo := repository2.NewOutboxRepository(s.DbPool, logger, "product-service")
 
-	s.TestProducer, err = kafka2.NewProducer(s.KafkaBrokers)
+	s.TestProducer, err = kafka2.NewProducer(kafka2.Config{Brokers: s.KafkaBrokers})
 	s.Require().NoError(err, "failed to create kafka producer")
 
-	s.ProductService = service.NewProductService(productRepo, outboxRepo, s.DbPool, logger)
+	s.ProductService = service.NewProductService(productRepo, warehouseRepo, campaignRepo, outboxRepo, s.DbPool, logger)
 	s.CachedProductService = service.NewCachedProductService(s.ProductService, s.RedisInternalClient)
 	s.OutboxProcessor = worker.NewOutboxProcessor(s.DbPool, outboxRepo, s.TestProducer, logger)
 
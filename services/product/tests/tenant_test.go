@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"github.com/sakashimaa/go-pet-project/pkg/tenant"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) TestTenant_ProductsIsolated() {
+	shopA := tenant.WithID(s.Ctx, "shop-a")
+	shopB := tenant.WithID(s.Ctx, "shop-b")
+
+	id, err := s.ProductService.Create(shopA, &domain.Product{
+		Name: "Tascam Cassette Deck", Price: 45000, StockQuantity: 3, Category: "Audio", SKU: "TSC-202MK7",
+	})
+	s.Require().NoError(err)
+
+	_, err = s.ProductService.FindByID(shopB, id)
+	s.Require().ErrorIs(err, repository.ErrProductNotFound)
+
+	_, err = s.ProductService.FindBySKU(shopB, "TSC-202MK7")
+	s.Require().ErrorIs(err, repository.ErrProductNotFound)
+
+	list, total, err := s.ProductService.List(shopB, domain.ProductFilter{Limit: 10})
+	s.Require().NoError(err)
+	s.Require().Zero(total)
+	s.Require().Empty(list)
+
+	found, err := s.ProductService.FindByID(shopA, id)
+	s.Require().NoError(err)
+	s.Require().Equal("Tascam Cassette Deck", found.Name)
+}
+
+func (s *IntegrationTestSuite) TestTenant_UniquenessPerTenant() {
+	product := &domain.Product{
+		Name: "Tascam Cassette Deck", Price: 45000, StockQuantity: 3, Category: "Audio", SKU: "TSC-202MK7",
+	}
+
+	idA, err := s.ProductService.Create(tenant.WithID(s.Ctx, "shop-a"), product)
+	s.Require().NoError(err)
+
+	idB, err := s.ProductService.Create(tenant.WithID(s.Ctx, "shop-b"), product)
+	s.Require().NoError(err, "Another storefront may reuse the same name and SKU")
+	s.Require().NotEqual(idA, idB)
+
+	var tenantID string
+	err = s.DbPool.QueryRow(s.Ctx, `SELECT tenant_id FROM products WHERE id = $1`, idB).Scan(&tenantID)
+	s.Require().NoError(err)
+	s.Require().Equal("shop-b", tenantID)
+}
@@ -0,0 +1,142 @@
+package tests
+
+import (
+	domain2 "github.com/sakashimaa/go-pet-project/pkg/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+)
+
+func (s *IntegrationTestSuite) createWarehouse(code string, location *domain.Location) *domain.Warehouse {
+	warehouse, err := s.ProductService.CreateWarehouse(s.Ctx, &domain.Warehouse{
+		Code: code, Name: "Warehouse " + code, Location: location,
+	})
+	s.Require().NoError(err)
+
+	return warehouse
+}
+
+func (s *IntegrationTestSuite) stockByWarehouse(productID int64) map[string]int64 {
+	stock, err := s.ProductService.GetProductStock(s.Ctx, productID)
+	s.Require().NoError(err)
+
+	res := make(map[string]int64, len(stock))
+	for _, st := range stock {
+		res[st.WarehouseCode] = st.Quantity
+	}
+
+	return res
+}
+
+func (s *IntegrationTestSuite) TestWarehouse_InitialStockGoesToDefault() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Garrard 301", Price: 40000, StockQuantity: 7, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	s.Require().Equal(map[string]int64{"MAIN": 7}, s.stockByWarehouse(id))
+}
+
+func (s *IntegrationTestSuite) TestWarehouse_CreateDuplicateCode() {
+	s.createWarehouse("EAST", nil)
+
+	_, err := s.ProductService.CreateWarehouse(s.Ctx, &domain.Warehouse{Code: "EAST", Name: "Another east"})
+	s.Require().ErrorIs(err, repository.ErrWarehouseAlreadyExists)
+}
+
+func (s *IntegrationTestSuite) TestWarehouse_TransferStock() {
+	east := s.createWarehouse("EAST", nil)
+
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Lenco L75", Price: 20000, StockQuantity: 10, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	mainWarehouse := s.defaultWarehouse()
+	s.Require().NoError(s.ProductService.TransferStock(s.Ctx, id, mainWarehouse.ID, east.ID, 4))
+	s.Require().Equal(map[string]int64{"MAIN": 6, "EAST": 4}, s.stockByWarehouse(id))
+
+	product, err := s.ProductService.FindByID(s.Ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(10), product.StockQuantity, "transfers keep the total")
+
+	err = s.ProductService.TransferStock(s.Ctx, id, east.ID, mainWarehouse.ID, 5)
+	s.Require().ErrorIs(err, repository.ErrInsufficientStock)
+
+	err = s.ProductService.TransferStock(s.Ctx, id, east.ID, east.ID, 1)
+	s.Require().ErrorIs(err, repository.ErrInvalidInput)
+}
+
+func (s *IntegrationTestSuite) TestWarehouse_ReserveSplitsAndCancelReturns() {
+	east := s.createWarehouse("EAST", nil)
+
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Thorens TD 150", Price: 30000, StockQuantity: 10, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	mainWarehouse := s.defaultWarehouse()
+	s.Require().NoError(s.ProductService.TransferStock(s.Ctx, id, mainWarehouse.ID, east.ID, 4))
+
+	err = s.ProductService.ReserveProduct(s.Ctx, &domain.OrderCreatedEvent{
+		OrderID: 501,
+		UserID:  1,
+		Items:   []domain.OrderItemEvent{{ProductID: id, Quantity: 8}},
+	})
+	s.Require().NoError(err)
+
+	// most_stocked drains MAIN (6) before touching EAST.
+	s.Require().Equal(map[string]int64{"MAIN": 0, "EAST": 2}, s.stockByWarehouse(id))
+
+	err = s.ProductService.ReturnStock(s.Ctx, &domain2.OrderCancelledEvent{
+		OrderID: 501,
+		Items:   []domain2.OrderItem{{OrderID: 501, ProductID: id, Quantity: 8}},
+	})
+	s.Require().NoError(err)
+
+	s.Require().Equal(map[string]int64{"MAIN": 6, "EAST": 4}, s.stockByWarehouse(id))
+
+	product, err := s.ProductService.FindByID(s.Ctx, id)
+	s.Require().NoError(err)
+	s.Require().Equal(int64(10), product.StockQuantity)
+}
+
+func (s *IntegrationTestSuite) TestWarehouse_AllocateNearest() {
+	moscow := domain.Location{Latitude: 55.75, Longitude: 37.62}
+	kazan := domain.Location{Latitude: 55.79, Longitude: 49.12}
+	spb := domain.Location{Latitude: 59.94, Longitude: 30.31}
+
+	stock := []domain.WarehouseStock{
+		{WarehouseID: 1, Location: &moscow, Quantity: 10, Priority: 100},
+		{WarehouseID: 2, Location: &kazan, Quantity: 3},
+		{WarehouseID: 3, Location: nil, Quantity: 50},
+	}
+
+	allocations, ok := domain.Allocate(stock, 5, domain.AllocateNearest, &kazan)
+	s.Require().True(ok)
+	s.Require().Equal([]domain.Allocation{{WarehouseID: 2, Quantity: 3}, {WarehouseID: 1, Quantity: 2}}, allocations)
+
+	allocations, ok = domain.Allocate(stock, 5, domain.AllocateNearest, nil)
+	s.Require().True(ok)
+	s.Require().Equal([]domain.Allocation{{WarehouseID: 1, Quantity: 5}}, allocations, "priority decides without a location")
+
+	allocations, ok = domain.Allocate(stock, 5, domain.AllocateMostStocked, &spb)
+	s.Require().True(ok)
+	s.Require().Equal([]domain.Allocation{{WarehouseID: 3, Quantity: 5}}, allocations)
+
+	_, ok = domain.Allocate(stock, 64, domain.AllocateMostStocked, nil)
+	s.Require().False(ok)
+}
+
+func (s *IntegrationTestSuite) defaultWarehouse() domain.Warehouse {
+	warehouses, err := s.ProductService.ListWarehouses(s.Ctx)
+	s.Require().NoError(err)
+
+	for _, w := range warehouses {
+		if w.Code == "MAIN" {
+			return w
+		}
+	}
+
+	s.FailNow("default warehouse is missing")
+	return domain.Warehouse{}
+}
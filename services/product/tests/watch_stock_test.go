@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"context"
+	"time"
+
+	"github.com/sakashimaa/go-pet-project/product/internal/domain"
+	"github.com/sakashimaa/go-pet-project/product/internal/repository"
+	"github.com/sakashimaa/go-pet-project/product/internal/service"
+	"go.uber.org/zap"
+)
+
+type stockWatchRun struct {
+	levels chan domain.StockLevel
+	done   chan error
+}
+
+// startStockWatcher runs a watcher until the test ends or stop is called.
+func (s *IntegrationTestSuite) startStockWatcher() (watcher *service.StockWatcher, stop func()) {
+	logger := zap.NewNop()
+	watcher = service.NewStockWatcher(
+		s.DbPool,
+		repository.NewProductRepository(s.DbPool, logger),
+		repository.NewWarehouseRepository(s.DbPool, logger),
+		logger,
+	)
+
+	ctx, cancel := context.WithCancel(s.Ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		watcher.Start(ctx)
+	}()
+
+	stop = func() {
+		cancel()
+		<-stopped
+	}
+	s.T().Cleanup(stop)
+
+	return watcher, stop
+}
+
+func (s *IntegrationTestSuite) watchStock(watcher *service.StockWatcher, productIDs ...int64) *stockWatchRun {
+	ctx, cancel := context.WithCancel(s.Ctx)
+	run := &stockWatchRun{
+		levels: make(chan domain.StockLevel, 100),
+		done:   make(chan error, 1),
+	}
+	s.T().Cleanup(cancel)
+
+	go func() {
+		run.done <- watcher.Watch(ctx, productIDs, func(level domain.StockLevel) error {
+			run.levels <- level
+			return nil
+		})
+	}()
+
+	return run
+}
+
+// awaitLevel reads levels until one matches. Reconnects resend levels, so
+// repeats of earlier ones are skipped rather than failed.
+func (s *IntegrationTestSuite) awaitLevel(run *stockWatchRun, match func(domain.StockLevel) bool) domain.StockLevel {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case level := <-run.levels:
+			if match(level) {
+				return level
+			}
+		case err := <-run.done:
+			s.FailNow("watch ended early", "%v", err)
+		case <-timeout:
+			s.FailNow("no matching stock update")
+		}
+	}
+}
+
+func (s *IntegrationTestSuite) awaitStock(run *stockWatchRun, productID, total int64) domain.StockLevel {
+	return s.awaitLevel(run, func(level domain.StockLevel) bool {
+		return level.ProductID == productID && level.Total == total
+	})
+}
+
+func levelByWarehouse(level domain.StockLevel) map[string]int64 {
+	res := make(map[string]int64, len(level.Warehouses))
+	for _, st := range level.Warehouses {
+		res[st.WarehouseCode] = st.Quantity
+	}
+
+	return res
+}
+
+func (s *IntegrationTestSuite) TestWatchStock_SendsSnapshotThenChanges() {
+	east := s.createWarehouse("EAST", nil)
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Technics SL-1200", Price: 90000, StockQuantity: 5, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	watcher, _ := s.startStockWatcher()
+	run := s.watchStock(watcher, id)
+
+	snapshot := s.awaitStock(run, id, 5)
+	s.Require().Equal(map[string]int64{"MAIN": 5}, levelByWarehouse(snapshot))
+	mainID := snapshot.Warehouses[0].WarehouseID
+
+	_, err = s.ProductService.AdjustStock(s.Ctx, &domain.StockAdjustment{
+		ProductID: id, WarehouseID: east.ID, Delta: 4, Reason: domain.AdjustRestock,
+	})
+	s.Require().NoError(err)
+
+	update := s.awaitStock(run, id, 9)
+	s.Require().Equal(map[string]int64{"MAIN": 5, "EAST": 4}, levelByWarehouse(update))
+
+	// Transfers keep the total but move units between warehouses.
+	err = s.ProductService.TransferStock(s.Ctx, id, east.ID, mainID, 4)
+	s.Require().NoError(err)
+
+	transferred := s.awaitLevel(run, func(level domain.StockLevel) bool {
+		return levelByWarehouse(level)["MAIN"] == 9
+	})
+	s.Require().Equal(int64(9), transferred.Total)
+}
+
+func (s *IntegrationTestSuite) TestWatchStock_OnlySendsWatchedProducts() {
+	watched, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Pro-Ject Debut", Price: 40000, StockQuantity: 3, Category: "Audio",
+	})
+	s.Require().NoError(err)
+	other, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Audio-Technica LP60", Price: 15000, StockQuantity: 3, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	watcher, _ := s.startStockWatcher()
+	run := s.watchStock(watcher, watched, watched)
+	s.awaitStock(run, watched, 3)
+
+	_, err = s.ProductService.AdjustStock(s.Ctx, &domain.StockAdjustment{
+		ProductID: other, Delta: 10, Reason: domain.AdjustRestock,
+	})
+	s.Require().NoError(err)
+	_, err = s.ProductService.AdjustStock(s.Ctx, &domain.StockAdjustment{
+		ProductID: watched, Delta: -1, Reason: domain.AdjustDamaged,
+	})
+	s.Require().NoError(err)
+
+	s.awaitLevel(run, func(level domain.StockLevel) bool {
+		s.Require().Equal(watched, level.ProductID)
+		return level.Total == 2
+	})
+}
+
+func (s *IntegrationTestSuite) TestWatchStock_RejectsInvalidWatches() {
+	watcher, _ := s.startStockWatcher()
+	send := func(domain.StockLevel) error { return nil }
+
+	tooMany := make([]int64, domain.MaxWatchedProducts+1)
+	for i := range tooMany {
+		tooMany[i] = int64(i + 1)
+	}
+
+	cases := map[string][]int64{
+		"no products":      nil,
+		"too many":         tooMany,
+		"invalid products": {1, -1},
+	}
+	for name, ids := range cases {
+		err := watcher.Watch(s.Ctx, ids, send)
+		s.Require().ErrorIs(err, domain.ErrInvalidStockWatch, name)
+	}
+
+	err := watcher.Watch(s.Ctx, []int64{999999}, send)
+	s.Require().ErrorIs(err, repository.ErrProductNotFound)
+}
+
+func (s *IntegrationTestSuite) TestWatchStock_EndsWhenWatcherStops() {
+	id, err := s.ProductService.Create(s.Ctx, &domain.Product{
+		Name: "Thorens TD 124", Price: 120000, StockQuantity: 1, Category: "Audio",
+	})
+	s.Require().NoError(err)
+
+	watcher, stop := s.startStockWatcher()
+	run := s.watchStock(watcher, id)
+	s.awaitStock(run, id, 1)
+
+	stop()
+
+	select {
+	case err := <-run.done:
+		s.Require().ErrorIs(err, service.ErrStockWatchStopped)
+	case <-time.After(10 * time.Second):
+		s.FailNow("watch did not end")
+	}
+}